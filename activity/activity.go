@@ -0,0 +1,219 @@
+// Package activity compiles reports of activity of addresses in a range of blocks: incoming and outgoing
+// transfers of ethers and ERC-20 tokens, gas spent and counterparties. Blocks with receipts are fetched
+// with blockrange package (client.Client uses batch requests for receipts), token transfers are found
+// by filtering Transfer logs.
+//
+// Ether transfers made by contracts (internal transactions) aren't included, as they aren't visible
+// without tracing.
+package activity
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+
+	eth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/monetha/go-ethereum"
+	"github.com/monetha/go-ethereum/blockrange"
+)
+
+// transferEventTopic is the topic of ERC-20 (and ERC-721) Transfer(address,address,uint256) event.
+var transferEventTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+// LogFilterer filters logs (e.g. backend.Backend).
+type LogFilterer interface {
+	FilterLogs(ctx context.Context, query eth.FilterQuery) ([]types.Log, error)
+}
+
+// Transfer is the transfer of ethers or tokens.
+type Transfer struct {
+	BlockNumber *big.Int
+	TxHash      common.Hash
+	Token       *common.Address // nil for ethers
+	From        common.Address
+	To          common.Address
+	Amount      *big.Int
+}
+
+// Counterparty is the address which sent transfers to or received transfers from the reported address.
+type Counterparty struct {
+	Address  common.Address
+	Incoming int // number of transfers from the counterparty
+	Outgoing int // number of transfers to the counterparty
+}
+
+// AddressReport is the activity of the address.
+type AddressReport struct {
+	Address  common.Address
+	Incoming []*Transfer // ordered by block number
+	Outgoing []*Transfer // ordered by block number
+	// Transactions is the number of transactions sent by the address, including failed ones.
+	Transactions int
+	// GasUsed is the gas used by transactions sent by the address.
+	GasUsed *big.Int
+	// GasSpent is the fee in wei paid for transactions sent by the address.
+	GasSpent *big.Int
+	// Counterparties are ordered by the total number of transfers, descending.
+	Counterparties []*Counterparty
+}
+
+// Report is the activity of addresses in the range of blocks.
+type Report struct {
+	FromBlock *big.Int
+	ToBlock   *big.Int
+	Addresses map[common.Address]*AddressReport
+}
+
+// Reporter compiles activity reports.
+type Reporter struct {
+	blocks blockrange.BlockReader
+	logs   LogFilterer
+}
+
+// New creates an instance of Reporter.
+func New(blocks blockrange.BlockReader, logs LogFilterer) *Reporter {
+	return &Reporter{blocks: blocks, logs: logs}
+}
+
+// Report compiles the report of activity of addresses in the range of blocks [from, to].
+// Options are used to fetch blocks (see blockrange.ForEachBlock).
+func (r *Reporter) Report(ctx context.Context, addresses []common.Address, from, to *big.Int, opts ...blockrange.Option) (*Report, error) {
+	if from == nil || to == nil {
+		return nil, fmt.Errorf("activity: range bounds must be set")
+	}
+
+	rep := &Report{
+		FromBlock: new(big.Int).Set(from),
+		ToBlock:   new(big.Int).Set(to),
+		Addresses: make(map[common.Address]*AddressReport, len(addresses)),
+	}
+	for _, a := range addresses {
+		rep.Addresses[a] = &AddressReport{Address: a, GasUsed: new(big.Int), GasSpent: new(big.Int)}
+	}
+	if len(addresses) == 0 {
+		return rep, nil
+	}
+
+	var transfers []*Transfer
+	err := blockrange.ForEachTransaction(ctx, r.blocks, from, to, func(ctx context.Context, b *ethereum.Block, tx *ethereum.Transaction) error {
+		if sender, ok := rep.Addresses[tx.From]; ok {
+			sender.Transactions++
+			if tx.GasUsed != nil {
+				sender.GasUsed.Add(sender.GasUsed, tx.GasUsed)
+				if tx.GasPrice != nil {
+					sender.GasSpent.Add(sender.GasSpent, new(big.Int).Mul(tx.GasUsed, tx.GasPrice))
+				}
+			}
+		}
+
+		failed := tx.Status != nil && *tx.Status == ethereum.TransactionFailed
+		if tx.To == nil || tx.Value == nil || tx.Value.Sign() == 0 || failed {
+			return nil
+		}
+		transfers = append(transfers, &Transfer{
+			BlockNumber: b.Number,
+			TxHash:      tx.Hash,
+			From:        tx.From,
+			To:          *tx.To,
+			Amount:      tx.Value,
+		})
+		return nil
+	}, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("activity: %v", err)
+	}
+
+	tokenTransfers, err := r.tokenTransfers(ctx, addresses, from, to)
+	if err != nil {
+		return nil, err
+	}
+	transfers = append(transfers, tokenTransfers...)
+	sort.SliceStable(transfers, func(i, j int) bool { return transfers[i].BlockNumber.Cmp(transfers[j].BlockNumber) < 0 })
+
+	counterparties := make(map[common.Address]map[common.Address]*Counterparty, len(addresses))
+	counterparty := func(a *AddressReport, address common.Address) *Counterparty {
+		cps, ok := counterparties[a.Address]
+		if !ok {
+			cps = make(map[common.Address]*Counterparty)
+			counterparties[a.Address] = cps
+		}
+		cp, ok := cps[address]
+		if !ok {
+			cp = &Counterparty{Address: address}
+			cps[address] = cp
+			a.Counterparties = append(a.Counterparties, cp)
+		}
+		return cp
+	}
+
+	for _, t := range transfers {
+		if a, ok := rep.Addresses[t.From]; ok {
+			a.Outgoing = append(a.Outgoing, t)
+			counterparty(a, t.To).Outgoing++
+		}
+		if a, ok := rep.Addresses[t.To]; ok {
+			a.Incoming = append(a.Incoming, t)
+			counterparty(a, t.From).Incoming++
+		}
+	}
+
+	for _, a := range rep.Addresses {
+		cps := a.Counterparties
+		sort.SliceStable(cps, func(i, j int) bool {
+			return cps[i].Incoming+cps[i].Outgoing > cps[j].Incoming+cps[j].Outgoing
+		})
+	}
+
+	return rep, nil
+}
+
+// tokenTransfers returns ERC-20 transfers from or to the addresses.
+func (r *Reporter) tokenTransfers(ctx context.Context, addresses []common.Address, from, to *big.Int) ([]*Transfer, error) {
+	topics := make([]common.Hash, len(addresses))
+	for i, a := range addresses {
+		topics[i] = a.Hash()
+	}
+
+	queries := [][][]common.Hash{
+		{{transferEventTopic}, topics},      // outgoing
+		{{transferEventTopic}, nil, topics}, // incoming
+	}
+
+	seen := make(map[string]bool)
+	var res []*Transfer
+	for _, q := range queries {
+		logs, err := r.logs.FilterLogs(ctx, eth.FilterQuery{FromBlock: from, ToBlock: to, Topics: q})
+		if err != nil {
+			return nil, fmt.Errorf("activity: filtering Transfer logs: %v", err)
+		}
+
+		for _, l := range logs {
+			// ERC-721 Transfer has the same signature, but the token id is indexed
+			if len(l.Topics) != 3 || len(l.Data) != 32 || l.Removed {
+				continue
+			}
+			// transfers between reported addresses are returned by both queries
+			key := fmt.Sprintf("%x:%v", l.TxHash, l.Index)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			token := l.Address
+			res = append(res, &Transfer{
+				BlockNumber: new(big.Int).SetUint64(l.BlockNumber),
+				TxHash:      l.TxHash,
+				Token:       &token,
+				From:        common.BytesToAddress(l.Topics[1].Bytes()),
+				To:          common.BytesToAddress(l.Topics[2].Bytes()),
+				Amount:      new(big.Int).SetBytes(l.Data),
+			})
+		}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,127 @@
+package activity
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	eth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/monetha/go-ethereum"
+)
+
+type blocksMock map[int64]*ethereum.Block
+
+func (m blocksMock) BlockByNumber(ctx context.Context, number *big.Int) (*ethereum.Block, error) {
+	if b, ok := m[number.Int64()]; ok {
+		return b, nil
+	}
+	return &ethereum.Block{Number: number}, nil
+}
+
+type logsMock []types.Log
+
+func (m logsMock) FilterLogs(ctx context.Context, query eth.FilterQuery) ([]types.Log, error) {
+	var res []types.Log
+	for _, l := range m {
+		if matchTopics(l.Topics, query.Topics) {
+			res = append(res, l)
+		}
+	}
+	return res, nil
+}
+
+func matchTopics(topics []common.Hash, query [][]common.Hash) bool {
+	for i, alternatives := range query {
+		if len(alternatives) == 0 {
+			continue
+		}
+		if i >= len(topics) {
+			return false
+		}
+		match := false
+		for _, t := range alternatives {
+			match = match || t == topics[i]
+		}
+		if !match {
+			return false
+		}
+	}
+	return true
+}
+
+func TestReporter_Report(t *testing.T) {
+	alice := common.HexToAddress("0xa")
+	bob := common.HexToAddress("0xb")
+	carol := common.HexToAddress("0xc")
+	token := common.HexToAddress("0x7")
+	failed := ethereum.TransactionFailed
+
+	tx := func(hash byte, from, to common.Address, value, gasUsed int64) *ethereum.Transaction {
+		return &ethereum.Transaction{
+			Hash:     common.Hash{hash},
+			From:     from,
+			To:       &to,
+			Value:    big.NewInt(value),
+			GasUsed:  big.NewInt(gasUsed),
+			GasPrice: big.NewInt(2),
+		}
+	}
+	failedTx := tx(3, alice, carol, 5, 30)
+	failedTx.Status = &failed
+
+	blocks := blocksMock{
+		1: {Number: big.NewInt(1), Transactions: ethereum.Transactions{tx(1, alice, bob, 10, 21000)}},
+		2: {Number: big.NewInt(2), Transactions: ethereum.Transactions{tx(2, carol, alice, 7, 21000), failedTx}},
+	}
+	logs := logsMock{
+		{
+			Address:     token,
+			Topics:      []common.Hash{transferEventTopic, alice.Hash(), bob.Hash()},
+			Data:        common.LeftPadBytes(big.NewInt(100).Bytes(), 32),
+			BlockNumber: 3,
+			TxHash:      common.Hash{4},
+		},
+		{
+			// ERC-721 transfer
+			Address:     token,
+			Topics:      []common.Hash{transferEventTopic, carol.Hash(), alice.Hash(), {1}},
+			BlockNumber: 3,
+			TxHash:      common.Hash{5},
+		},
+	}
+
+	rep, err := New(blocks, logs).Report(context.Background(), []common.Address{alice, bob}, big.NewInt(1), big.NewInt(3))
+	if err != nil {
+		t.Fatalf("Report() error = %v", err)
+	}
+
+	a := rep.Addresses[alice]
+	if len(a.Outgoing) != 2 || a.Outgoing[0].Amount.Int64() != 10 || a.Outgoing[1].Token == nil || *a.Outgoing[1].Token != token {
+		t.Errorf("expected outgoing ether and token transfers of alice, but got %v", a.Outgoing)
+	}
+	if len(a.Incoming) != 1 || a.Incoming[0].From != carol {
+		t.Errorf("expected incoming transfer of alice from carol, but got %v", a.Incoming)
+	}
+	if a.Transactions != 2 {
+		t.Errorf("expected 2 transactions of alice, but got %v", a.Transactions)
+	}
+	if a.GasUsed.Int64() != 21030 {
+		t.Errorf("expected gas used 21030, but got %v", a.GasUsed)
+	}
+	if a.GasSpent.Int64() != 42060 {
+		t.Errorf("expected gas spent 42060, but got %v", a.GasSpent)
+	}
+	if len(a.Counterparties) != 2 || a.Counterparties[0].Address != bob || a.Counterparties[0].Outgoing != 2 {
+		t.Errorf("expected bob to be the top counterparty of alice, but got %v", a.Counterparties)
+	}
+
+	b := rep.Addresses[bob]
+	if len(b.Incoming) != 2 || len(b.Outgoing) != 0 {
+		t.Errorf("expected 2 incoming transfers of bob, but got %v incoming and %v outgoing", len(b.Incoming), len(b.Outgoing))
+	}
+	if b.Transactions != 0 || b.GasSpent.Sign() != 0 {
+		t.Errorf("expected no transactions of bob, but got %v", b.Transactions)
+	}
+}
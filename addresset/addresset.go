@@ -0,0 +1,126 @@
+// Package addresset provides Set, the immutable set of addresses for matching every transaction of every block
+// against a large watch list (e.g. millions of deposit addresses of an exchange), where map lookups become
+// a bottleneck. Most checked addresses aren't in the set, so lookups are answered by a compact bloom filter,
+// and only addresses which pass it are looked up in the open addressing hash table.
+package addresset
+
+import (
+	"encoding/binary"
+	"math/bits"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// bloomBitsPerAddress is the size of the bloom filter per address, 2 bits are set per address, so about 1.4%
+// of addresses not in the set pass the filter.
+const bloomBitsPerAddress = 16
+
+// Set is the immutable set of addresses, it's safe for concurrent use.
+type Set struct {
+	bloom      []uint64
+	bloomShift uint     // 64 - log2(number of bloom bits)
+	table      []uint32 // index+1 of the address in addrs, 0 means the slot is empty
+	tableShift uint     // 64 - log2(len(table))
+	addrs      []common.Address
+}
+
+// New creates the set of addresses, duplicates are ignored.
+func New(addresses []common.Address) *Set {
+	s := &Set{}
+	if len(addresses) == 0 {
+		return s
+	}
+
+	tableBits := log2Ceil(2 * len(addresses)) // load factor is at most 0.5
+	s.table = make([]uint32, 1<<tableBits)
+	s.tableShift = 64 - tableBits
+	bloomBits := log2Ceil(bloomBitsPerAddress * len(addresses))
+	if bloomBits < 6 {
+		bloomBits = 6
+	}
+	s.bloom = make([]uint64, 1<<(bloomBits-6))
+	s.bloomShift = 64 - bloomBits
+
+	s.addrs = make([]common.Address, 0, len(addresses))
+	mask := uint64(len(s.table) - 1)
+	for _, addr := range addresses {
+		h := hash(addr)
+		i := h >> s.tableShift
+		for ; s.table[i] != 0; i = (i + 1) & mask {
+			if s.addrs[s.table[i]-1] == addr {
+				break
+			}
+		}
+		if s.table[i] != 0 {
+			continue // duplicate
+		}
+
+		s.addrs = append(s.addrs, addr)
+		s.table[i] = uint32(len(s.addrs))
+		b1, b2 := s.bloomBits(h)
+		s.bloom[b1>>6] |= 1 << (b1 & 63)
+		s.bloom[b2>>6] |= 1 << (b2 & 63)
+	}
+	return s
+}
+
+// Contains tells whether the address is in the set.
+func (s *Set) Contains(addr common.Address) bool {
+	if len(s.addrs) == 0 {
+		return false
+	}
+
+	h := hash(addr)
+	b1, b2 := s.bloomBits(h)
+	if s.bloom[b1>>6]&(1<<(b1&63)) == 0 || s.bloom[b2>>6]&(1<<(b2&63)) == 0 {
+		return false
+	}
+
+	mask := uint64(len(s.table) - 1)
+	for i := h >> s.tableShift; s.table[i] != 0; i = (i + 1) & mask {
+		if s.addrs[s.table[i]-1] == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// ContainsAny tells whether any of the addresses (nil ones are skipped) is in the set.
+func (s *Set) ContainsAny(addrs ...*common.Address) bool {
+	for _, addr := range addrs {
+		if addr != nil && s.Contains(*addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Len returns the number of addresses in the set.
+func (s *Set) Len() int {
+	return len(s.addrs)
+}
+
+// Addresses returns addresses of the set in the order they were added.
+func (s *Set) Addresses() []common.Address {
+	return append([]common.Address(nil), s.addrs...)
+}
+
+// bloomBits returns positions of bits of the bloom filter set for the address with the hash.
+func (s *Set) bloomBits(h uint64) (uint64, uint64) {
+	return h >> s.bloomShift, (h * 0xc2b2ae3d27d4eb4f) >> s.bloomShift
+}
+
+// hash mixes all bytes of the address, since addresses which aren't derived from public keys (e.g. vanity ones)
+// may share prefixes or suffixes.
+func hash(addr common.Address) uint64 {
+	h := binary.LittleEndian.Uint64(addr[0:8]) ^
+		bits.RotateLeft64(binary.LittleEndian.Uint64(addr[8:16]), 21) ^
+		bits.RotateLeft64(uint64(binary.LittleEndian.Uint32(addr[16:20])), 42)
+	h *= 0x9e3779b97f4a7c15
+	return h ^ h>>32
+}
+
+// log2Ceil returns the smallest k such that 2^k >= n.
+func log2Ceil(n int) uint {
+	return uint(bits.Len(uint(n - 1)))
+}
@@ -0,0 +1,115 @@
+package addresset
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func randomAddresses(r *rand.Rand, n int) []common.Address {
+	addrs := make([]common.Address, n)
+	for i := range addrs {
+		r.Read(addrs[i][:])
+	}
+	return addrs
+}
+
+func TestSet(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	members := randomAddresses(r, 10000)
+	// addresses sharing prefixes and suffixes, zero address and duplicates
+	for i := 0; i < 100; i++ {
+		members = append(members, common.Address{19: byte(i)}, common.Address{0xff, byte(i)})
+	}
+	members = append(members, common.Address{}, members[0], members[1])
+
+	s := New(members)
+
+	expected := make(map[common.Address]bool)
+	for _, addr := range members {
+		expected[addr] = true
+	}
+	if s.Len() != len(expected) {
+		t.Errorf("expected %v addresses, but got %v", len(expected), s.Len())
+	}
+	for _, addr := range members {
+		if !s.Contains(addr) {
+			t.Errorf("expected %v to be in the set", addr.Hex())
+		}
+	}
+	for _, addr := range randomAddresses(r, 10000) {
+		if s.Contains(addr) != expected[addr] {
+			t.Errorf("Contains(%v): expected %v, but got %v", addr.Hex(), expected[addr], !expected[addr])
+		}
+	}
+
+	if !s.ContainsAny(nil, &members[5]) {
+		t.Errorf("expected ContainsAny to find the member")
+	}
+	if s.ContainsAny(nil) {
+		t.Errorf("expected ContainsAny to skip nil addresses")
+	}
+}
+
+func TestSet_Empty(t *testing.T) {
+	s := New(nil)
+	if s.Len() != 0 || s.Contains(common.Address{}) {
+		t.Errorf("expected empty set")
+	}
+}
+
+const benchAddresses = 1000000
+
+var benchFound bool
+
+func benchmarkLookups(b *testing.B, contains func(common.Address) bool, members []common.Address, hitRate int) {
+	r := rand.New(rand.NewSource(2))
+	lookups := randomAddresses(r, 4096)
+	for i := 0; i < len(lookups)*hitRate/100; i++ {
+		lookups[i] = members[r.Intn(len(members))]
+	}
+	r.Shuffle(len(lookups), func(i, j int) { lookups[i], lookups[j] = lookups[j], lookups[i] })
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		benchFound = contains(lookups[i%len(lookups)])
+	}
+	b.StopTimer()
+}
+
+func BenchmarkSet_Contains(b *testing.B) {
+	members := randomAddresses(rand.New(rand.NewSource(1)), benchAddresses)
+	s := New(members)
+
+	b.Run("miss", func(b *testing.B) { benchmarkLookups(b, s.Contains, members, 0) })
+	b.Run("1% hit", func(b *testing.B) { benchmarkLookups(b, s.Contains, members, 1) })
+	b.Run("hit", func(b *testing.B) { benchmarkLookups(b, s.Contains, members, 100) })
+}
+
+func BenchmarkMap_Contains(b *testing.B) {
+	members := randomAddresses(rand.New(rand.NewSource(1)), benchAddresses)
+	m := make(map[common.Address]struct{}, len(members))
+	for _, addr := range members {
+		m[addr] = struct{}{}
+	}
+	contains := func(addr common.Address) bool {
+		_, ok := m[addr]
+		return ok
+	}
+
+	b.Run("miss", func(b *testing.B) { benchmarkLookups(b, contains, members, 0) })
+	b.Run("1% hit", func(b *testing.B) { benchmarkLookups(b, contains, members, 1) })
+	b.Run("hit", func(b *testing.B) { benchmarkLookups(b, contains, members, 100) })
+}
+
+func BenchmarkNew(b *testing.B) {
+	members := randomAddresses(rand.New(rand.NewSource(1)), benchAddresses)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		New(members)
+	}
+}
@@ -0,0 +1,238 @@
+// Package allowance monitors ERC-20 allowances granted to spenders by managed accounts and tops them up
+// with approve transactions when they drop below a threshold, e.g. for relayers spending tokens of hot wallets.
+package allowance
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/big"
+	"sync"
+	"time"
+
+	eth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/monetha/go-ethereum"
+)
+
+const allowanceABI = `[{"constant":true,"inputs":[{"name":"_owner","type":"address"},{"name":"_spender","type":"address"}],"name":"allowance","outputs":[{"name":"","type":"uint256"}],"payable":false,"stateMutability":"view","type":"function"}]`
+
+var parsedAllowanceABI = ethereum.MustParseABI(allowanceABI)
+
+// MaxAmount is the maximum uint256 value, which is approved when Rule.TopUp is nil.
+var MaxAmount = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+// DefaultInterval is used when Config.Interval is zero.
+const DefaultInterval = time.Minute
+
+// DefaultPendingTimeout is used when Config.PendingTimeout is zero.
+const DefaultPendingTimeout = 10 * time.Minute
+
+// Get returns the amount of ERC-20 `token` which `spender` is allowed to spend on behalf of `owner`.
+func Get(ctx context.Context, caller bind.ContractCaller, token, owner, spender common.Address) (*big.Int, error) {
+	contract := bind.NewBoundContract(token, parsedAllowanceABI, caller, nil, nil)
+
+	var allowance *big.Int
+	if err := contract.Call(&bind.CallOpts{Context: ctx}, &allowance, "allowance", owner, spender); err != nil {
+		return nil, fmt.Errorf("allowance: allowance: %v", err)
+	}
+	return allowance, nil
+}
+
+// Rule is the allowance to monitor.
+type Rule struct {
+	Token   common.Address
+	Spender common.Address
+	// Owner is the session of the managed account which grants the allowance, it sends approve transactions.
+	Owner *ethereum.Session
+	// Threshold is the allowance below which it's low.
+	Threshold *big.Int
+	// TopUp is the allowance approved when it's low, MaxAmount when nil. Tokens which require the allowance
+	// to be reset to zero before it's changed (e.g. USDT) can't be topped up while it isn't spent entirely.
+	TopUp *big.Int
+}
+
+func (r *Rule) topUp() *big.Int {
+	if r.TopUp == nil {
+		return MaxAmount
+	}
+	return r.TopUp
+}
+
+// Status is the result of checking the allowance.
+type Status struct {
+	Rule *Rule
+	// Allowance is the current allowance, nil when it couldn't be retrieved.
+	Allowance *big.Int
+	// Low indicates that the allowance is below the threshold.
+	Low bool
+	// Tx is the approve transaction sent to top up the allowance, or the one which is still pending.
+	Tx *types.Transaction
+	// Err is the error of retrieving the allowance or sending approve transaction.
+	Err error
+}
+
+// Config configures Watcher.
+type Config struct {
+	// Interval is the interval of checking allowances, DefaultInterval is used when it's zero.
+	Interval time.Duration
+	// AutoTopUp indicates that approve transaction must be sent when the allowance is low. Only one approve
+	// transaction per rule is pending at a time.
+	AutoTopUp bool
+	// PendingTimeout is the duration after which the pending approve transaction which isn't known by the node
+	// anymore (dropped or replaced) is forgotten, so that the allowance is topped up again. DefaultPendingTimeout
+	// is used when it's zero.
+	PendingTimeout time.Duration
+	// OnLow is called (from the goroutine of Watcher) with the status of every low allowance or failed check
+	// (optional).
+	OnLow func(*Status)
+}
+
+// Watcher periodically checks allowances of the rules.
+type Watcher struct {
+	rules []*Rule
+	cfg   Config
+
+	mu      sync.Mutex
+	pending map[*Rule]*pendingTx // approve transactions which aren't mined yet
+
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+// New creates an instance of Watcher. Allowances aren't checked in background until Start is called,
+// Check can be used instead to check them on demand.
+func New(rules []*Rule, cfg *Config) *Watcher {
+	w := &Watcher{
+		rules:   rules,
+		pending: make(map[*Rule]*pendingTx),
+		closed:  make(chan struct{}),
+	}
+	if cfg != nil {
+		w.cfg = *cfg
+	}
+	if w.cfg.Interval == 0 {
+		w.cfg.Interval = DefaultInterval
+	}
+	if w.cfg.PendingTimeout == 0 {
+		w.cfg.PendingTimeout = DefaultPendingTimeout
+	}
+	return w
+}
+
+// Start checks allowances every Config.Interval in background until Close is called.
+func (w *Watcher) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancelOnClose(cancel)
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+
+		for {
+			for _, st := range w.Check(ctx) {
+				if ctx.Err() != nil {
+					return // closed
+				}
+				if st.Err != nil {
+					log.Printf("allowance: token %v, spender %v: %v", st.Rule.Token.Hex(), st.Rule.Spender.Hex(), st.Err)
+				}
+				if (st.Low || st.Err != nil) && w.cfg.OnLow != nil {
+					w.cfg.OnLow(st)
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(w.cfg.Interval):
+			}
+		}
+	}()
+}
+
+// Check checks allowances of all rules once and tops up low ones when Config.AutoTopUp is set.
+func (w *Watcher) Check(ctx context.Context) []*Status {
+	statuses := make([]*Status, 0, len(w.rules))
+	for _, r := range w.rules {
+		statuses = append(statuses, w.check(ctx, r))
+	}
+	return statuses
+}
+
+func (w *Watcher) check(ctx context.Context, r *Rule) *Status {
+	st := &Status{Rule: r}
+
+	st.Allowance, st.Err = Get(ctx, r.Owner.Backend, r.Token, r.Owner.TransactOpts.From, r.Spender)
+	if st.Err != nil {
+		return st
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	st.Low = st.Allowance.Cmp(r.Threshold) < 0
+	if !st.Low {
+		delete(w.pending, r)
+		return st
+	}
+
+	if p := w.pending[r]; p != nil {
+		if _, err := r.Owner.Backend.TransactionReceipt(ctx, p.tx.Hash()); err != nil && !w.dropped(ctx, r, p) {
+			st.Tx = p.tx // approve transaction isn't mined yet (or its receipt can't be retrieved)
+			return st
+		}
+		delete(w.pending, r) // approve transaction is mined (or dropped), but the allowance is low again (or it failed)
+	}
+
+	if !w.cfg.AutoTopUp {
+		return st
+	}
+
+	st.Tx, st.Err = r.Owner.ApproveToken(ctx, r.Token, r.Spender, r.topUp())
+	if st.Err != nil {
+		st.Err = fmt.Errorf("allowance: approve: %v", st.Err)
+		return st
+	}
+	w.pending[r] = &pendingTx{tx: st.Tx, sent: time.Now()}
+	r.Owner.Log("Allowance topped up", "token", r.Token.Hex(), "spender", r.Spender.Hex(), "allowance", st.Allowance, "tx_hash", st.Tx.Hash().Hex())
+	return st
+}
+
+type pendingTx struct {
+	tx   *types.Transaction
+	sent time.Time
+}
+
+// dropped tells whether the approve transaction isn't known by the node after Config.PendingTimeout, i.e. it was
+// evicted from the transaction pool or replaced by another transaction with the same nonce.
+func (w *Watcher) dropped(ctx context.Context, r *Rule, p *pendingTx) bool {
+	if time.Since(p.sent) < w.cfg.PendingTimeout {
+		return false
+	}
+	_, _, err := r.Owner.Backend.TransactionByHash(ctx, p.tx.Hash())
+	return err == eth.NotFound
+}
+
+// Close stops checking allowances in background.
+func (w *Watcher) Close() error {
+	w.closeOnce.Do(func() {
+		close(w.closed)
+
+		w.wg.Wait()
+	})
+	return nil
+}
+
+func (w *Watcher) cancelOnClose(cancel context.CancelFunc) {
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		defer cancel()
+
+		<-w.closed
+	}()
+}
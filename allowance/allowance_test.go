@@ -0,0 +1,185 @@
+package allowance
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	eth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/monetha/go-ethereum"
+	"github.com/monetha/go-ethereum/backend"
+)
+
+// tokenBackend returns the allowance set and records sent transactions, which are never mined.
+type tokenBackend struct {
+	backend.Backend
+
+	mu        sync.Mutex
+	allowance *big.Int
+	dropped   bool // sent transactions aren't known by the node
+	sent      []*types.Transaction
+}
+
+func (b *tokenBackend) CallContract(ctx context.Context, call eth.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return common.LeftPadBytes(b.allowance.Bytes(), 32), nil
+}
+
+func (b *tokenBackend) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return 0, nil
+}
+
+func (b *tokenBackend) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(1), nil
+}
+
+func (b *tokenBackend) EstimateGas(ctx context.Context, call eth.CallMsg) (uint64, error) {
+	return 50000, nil
+}
+
+func (b *tokenBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.sent = append(b.sent, tx)
+	return nil
+}
+
+func (b *tokenBackend) TransactionByHash(ctx context.Context, txHash common.Hash) (*types.Transaction, bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.dropped {
+		return nil, false, eth.NotFound
+	}
+	return nil, true, nil
+}
+
+func (b *tokenBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	return nil, eth.NotFound
+}
+
+func (b *tokenBackend) sentCount() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.sent)
+}
+
+func newRule(b *tokenBackend) *Rule {
+	owner := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	return &Rule{
+		Token:   common.HexToAddress("0x2222222222222222222222222222222222222222"),
+		Spender: common.HexToAddress("0x3333333333333333333333333333333333333333"),
+		Owner: &ethereum.Session{
+			Eth: ethereum.New(b, nil),
+			TransactOpts: bind.TransactOpts{
+				From: owner,
+				Signer: func(signer types.Signer, address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+					return tx, nil
+				},
+			},
+		},
+		Threshold: big.NewInt(100),
+		TopUp:     big.NewInt(1000),
+	}
+}
+
+func TestGet(t *testing.T) {
+	b := &tokenBackend{allowance: big.NewInt(42)}
+	allowance, err := Get(context.Background(), b, common.Address{}, common.Address{}, common.Address{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if allowance.Int64() != 42 {
+		t.Errorf("expected allowance 42, but got %v", allowance)
+	}
+}
+
+func TestWatcher_Check(t *testing.T) {
+	tests := []struct {
+		name      string
+		allowance int64
+		autoTopUp bool
+		low       bool
+		sent      int
+	}{
+		{"enough allowance", 100, true, false, 0},
+		{"low allowance", 99, false, true, 0},
+		{"low allowance topped up", 99, true, true, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &tokenBackend{allowance: big.NewInt(tt.allowance)}
+			w := New([]*Rule{newRule(b)}, &Config{AutoTopUp: tt.autoTopUp})
+			defer w.Close()
+
+			st := w.Check(context.Background())[0]
+			if st.Err != nil {
+				t.Fatalf("unexpected error: %v", st.Err)
+			}
+			if st.Low != tt.low {
+				t.Errorf("expected low %v, but got %v", tt.low, st.Low)
+			}
+			if n := b.sentCount(); n != tt.sent {
+				t.Errorf("expected %v approve transactions, but got %v", tt.sent, n)
+			}
+		})
+	}
+}
+
+func TestWatcher_Check_Pending(t *testing.T) {
+	b := &tokenBackend{allowance: big.NewInt(0)}
+	w := New([]*Rule{newRule(b)}, &Config{AutoTopUp: true})
+	defer w.Close()
+
+	first := w.Check(context.Background())[0]
+	second := w.Check(context.Background())[0]
+	if n := b.sentCount(); n != 1 {
+		t.Fatalf("expected one approve transaction while it's pending, but got %v", n)
+	}
+	if second.Tx != first.Tx {
+		t.Errorf("expected pending approve transaction to be reported")
+	}
+
+	expectedInput := hexutil.MustDecode("0x095ea7b3" +
+		"0000000000000000000000003333333333333333333333333333333333333333" +
+		"00000000000000000000000000000000000000000000000000000000000003e8")
+	if !bytes.Equal(expectedInput, first.Tx.Data()) {
+		t.Errorf("expected input %x, but got %x", expectedInput, first.Tx.Data())
+	}
+	if *first.Tx.To() != first.Rule.Token {
+		t.Errorf("expected transaction to token %v, but got %v", first.Rule.Token.Hex(), first.Tx.To().Hex())
+	}
+}
+
+func TestWatcher_Check_PendingTimeout(t *testing.T) {
+	tests := []struct {
+		name    string
+		dropped bool
+		sent    int
+	}{
+		{"pending", false, 1},
+		{"dropped", true, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &tokenBackend{allowance: big.NewInt(0), dropped: tt.dropped}
+			w := New([]*Rule{newRule(b)}, &Config{AutoTopUp: true, PendingTimeout: time.Nanosecond})
+			defer w.Close()
+
+			w.Check(context.Background())
+			w.Check(context.Background())
+			if n := b.sentCount(); n != tt.sent {
+				t.Errorf("expected %v approve transactions, but got %v", tt.sent, n)
+			}
+		})
+	}
+}
@@ -0,0 +1,328 @@
+// Package approvals implements two-phase approval of high-value transfers: transactions above the threshold
+// are queued as pending intents and sent only after they are explicitly approved, optionally by other parties.
+package approvals
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/monetha/go-ethereum"
+	"github.com/monetha/go-ethereum/eip712"
+)
+
+// Status is the status of the intent.
+type Status string
+
+const (
+	// StatusPending means the intent waits for approvals.
+	StatusPending Status = "pending"
+	// StatusSending means the transaction of the intent is being sent. The intent stays in this status when the
+	// result of sending wasn't saved (e.g. the process crashed), it isn't sent again, as it may have been sent already.
+	StatusSending Status = "sending"
+	// StatusSent means the transaction of the intent was sent.
+	StatusSent Status = "sent"
+	// StatusFailed means the transaction of the intent was approved, but failed to be sent.
+	StatusFailed Status = "failed"
+	// StatusRejected means the intent was rejected.
+	StatusRejected Status = "rejected"
+)
+
+var (
+	// ErrNotPending is returned when approving or rejecting intent which isn't pending.
+	ErrNotPending = errors.New("approvals: intent is not pending")
+	// ErrNotApprover is returned when the account isn't allowed to approve intents.
+	ErrNotApprover = errors.New("approvals: account is not an approver")
+	// ErrNoApprovers is returned by NewQueue when Config.Approvers is empty.
+	ErrNoApprovers = errors.New("approvals: no approvers")
+)
+
+// intentTypeHash is the EIP-712 type hash of the intent.
+var intentTypeHash = crypto.Keccak256Hash([]byte("Intent(string id,address to,uint256 value,bytes data,uint256 gasLimit)"))
+
+// Intent is the transaction waiting for approvals.
+type Intent struct {
+	ID string `json:"id"`
+	// Queue is the account which sends the transaction of the intent.
+	Queue common.Address `json:"queue"`
+	// ChainID is the ID of the chain the transaction is sent to.
+	ChainID   *hexutil.Big     `json:"chainId,omitempty"`
+	To        common.Address   `json:"to"`
+	Value     *hexutil.Big     `json:"value"`
+	Data      hexutil.Bytes    `json:"data,omitempty"`
+	GasLimit  hexutil.Uint64   `json:"gasLimit,omitempty"`
+	CreatedAt time.Time        `json:"createdAt"`
+	Status    Status           `json:"status"`
+	Approvals []common.Address `json:"approvals,omitempty"`
+	TxHash    *common.Hash     `json:"txHash,omitempty"`
+	Error     string           `json:"error,omitempty"`
+}
+
+// Hash returns the EIP-712 hash of the intent which approvers sign with SignApproval. The hash is bound to the
+// queue account and the chain ID, so the approval can't be replayed to another queue or chain.
+func (i *Intent) Hash() common.Hash {
+	structHash := crypto.Keccak256Hash(
+		intentTypeHash[:],
+		crypto.Keccak256([]byte(i.ID)),
+		i.To.Hash().Bytes(),
+		eip712.Word(i.Value.ToInt()),
+		crypto.Keccak256(i.Data),
+		eip712.Word(new(big.Int).SetUint64(uint64(i.GasLimit))),
+	)
+	return i.domain().Hash(structHash)
+}
+
+func (i *Intent) domain() eip712.Domain {
+	return eip712.Domain{
+		Name:              "Approvals",
+		Version:           "1",
+		ChainID:           i.ChainID.ToInt(),
+		VerifyingContract: i.Queue,
+	}
+}
+
+// SignApproval signs approval of the intent with the approver key (see Queue.ApproveSigned).
+func (i *Intent) SignApproval(key *ethereum.Key) ([]byte, error) {
+	return eip712.Sign(i.Hash(), key)
+}
+
+func (i *Intent) tx() ethereum.PreparedTx {
+	return ethereum.PreparedTx{
+		To:       i.To,
+		Value:    new(big.Int).Set(i.Value.ToInt()),
+		Data:     i.Data,
+		GasLimit: uint64(i.GasLimit),
+	}
+}
+
+func (i *Intent) clone() *Intent {
+	c := *i
+	if i.Value != nil {
+		c.Value = (*hexutil.Big)(new(big.Int).Set(i.Value.ToInt()))
+	}
+	if i.ChainID != nil {
+		c.ChainID = (*hexutil.Big)(new(big.Int).Set(i.ChainID.ToInt()))
+	}
+	c.Data = append(hexutil.Bytes(nil), i.Data...)
+	c.Approvals = append([]common.Address(nil), i.Approvals...)
+	if i.TxHash != nil {
+		h := *i.TxHash
+		c.TxHash = &h
+	}
+	return &c
+}
+
+// Config contains parameters of Queue.
+type Config struct {
+	// Threshold is the value (in wei) from which transactions require approval. All transactions require
+	// approval when it's nil.
+	Threshold *big.Int
+	// Approvers is the list of accounts allowed to approve intents, at least one approver is required.
+	Approvers []common.Address
+	// RequiredApprovals is the number of distinct approvals required to send the transaction (1 when it's zero).
+	RequiredApprovals int
+	// ChainID is the ID of the chain the transactions are sent to, it's a part of the hash signed by approvers.
+	ChainID *big.Int
+}
+
+// Queue sends transactions from the session account, queuing high-value ones until they are approved.
+type Queue struct {
+	session *ethereum.Session
+	store   Store
+	cfg     Config
+	mu      sync.Mutex
+}
+
+// NewQueue creates an instance of Queue. ErrNoApprovers is returned when no approvers are configured.
+func NewQueue(session *ethereum.Session, store Store, cfg Config) (*Queue, error) {
+	if len(cfg.Approvers) == 0 {
+		return nil, ErrNoApprovers
+	}
+	if cfg.RequiredApprovals <= 0 {
+		cfg.RequiredApprovals = 1
+	}
+	return &Queue{session: session, store: store, cfg: cfg}, nil
+}
+
+// Submit sends the transaction right away when its value is below the threshold, otherwise it saves pending intent
+// and returns it without sending.
+func (q *Queue) Submit(ctx context.Context, tx ethereum.PreparedTx) (*Intent, error) {
+	value := tx.Value
+	if value == nil {
+		value = new(big.Int)
+	}
+
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+	intent := &Intent{
+		ID:        id,
+		Queue:     q.session.TransactOpts.From,
+		To:        tx.To,
+		Value:     (*hexutil.Big)(new(big.Int).Set(value)),
+		Data:      tx.Data,
+		GasLimit:  hexutil.Uint64(tx.GasLimit),
+		CreatedAt: time.Now(),
+		Status:    StatusPending,
+	}
+	if q.cfg.ChainID != nil {
+		intent.ChainID = (*hexutil.Big)(new(big.Int).Set(q.cfg.ChainID))
+	}
+
+	if q.cfg.Threshold != nil && value.Cmp(q.cfg.Threshold) < 0 {
+		if err := q.send(ctx, intent); err != nil {
+			return nil, err
+		}
+		return intent, q.saveResult(intent)
+	}
+
+	q.session.Log("Transaction requires approval", "intent", intent.ID, "to", tx.To.Hex(), "value", value)
+	if err := q.store.Save(intent); err != nil {
+		return nil, err
+	}
+	return intent, nil
+}
+
+// Approve records approval of the intent by the approver and sends the transaction once the intent has enough
+// approvals. The approver must be authenticated by the caller, see ApproveSigned otherwise.
+func (q *Queue) Approve(ctx context.Context, id string, approver common.Address) (*Intent, error) {
+	if !q.isApprover(approver) {
+		return nil, ErrNotApprover
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	intent, err := q.store.Load(id)
+	if err != nil {
+		return nil, err
+	}
+	if intent.Status != StatusPending {
+		return nil, ErrNotPending
+	}
+
+	for _, a := range intent.Approvals {
+		if a == approver {
+			return intent, nil
+		}
+	}
+	intent.Approvals = append(intent.Approvals, approver)
+	q.session.Log("Intent approved", "intent", intent.ID, "approver", approver.Hex())
+
+	if len(intent.Approvals) >= q.cfg.RequiredApprovals {
+		if err := q.send(ctx, intent); err != nil {
+			return nil, err
+		}
+		return intent, q.saveResult(intent)
+	}
+
+	if err := q.store.Save(intent); err != nil {
+		return nil, err
+	}
+	return intent, nil
+}
+
+// ApproveSigned approves the intent by the account which signed it with Intent.SignApproval.
+func (q *Queue) ApproveSigned(ctx context.Context, id string, sig []byte) (*Intent, error) {
+	intent, err := q.store.Load(id)
+	if err != nil {
+		return nil, err
+	}
+	approver, err := eip712.Recover(intent.Hash(), sig)
+	if err != nil {
+		return nil, err
+	}
+	return q.Approve(ctx, id, approver)
+}
+
+// Reject rejects the pending intent, so it's never sent.
+func (q *Queue) Reject(ctx context.Context, id string) (*Intent, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	intent, err := q.store.Load(id)
+	if err != nil {
+		return nil, err
+	}
+	if intent.Status != StatusPending {
+		return nil, ErrNotPending
+	}
+
+	intent.Status = StatusRejected
+	if err := q.store.Save(intent); err != nil {
+		return nil, err
+	}
+	return intent, nil
+}
+
+// Pending returns intents waiting for approvals.
+func (q *Queue) Pending() ([]*Intent, error) {
+	intents, err := q.store.List()
+	if err != nil {
+		return nil, err
+	}
+	res := intents[:0]
+	for _, intent := range intents {
+		if intent.Status == StatusPending {
+			res = append(res, intent)
+		}
+	}
+	return res, nil
+}
+
+// send saves intent with StatusSending before sending its transaction, so the transaction isn't sent twice when
+// the result of sending isn't saved. The result is set to intent, it must be saved with saveResult.
+func (q *Queue) send(ctx context.Context, intent *Intent) error {
+	intent.Status = StatusSending
+	if err := q.store.Save(intent); err != nil {
+		return err
+	}
+
+	res := q.session.SendBatch(ctx, []ethereum.PreparedTx{intent.tx()})[0]
+	if res.Err != nil {
+		intent.Status = StatusFailed
+		intent.Error = res.Err.Error()
+		return nil
+	}
+	hash := res.Tx.Hash()
+	intent.Status = StatusSent
+	intent.TxHash = &hash
+	return nil
+}
+
+// saveResult saves intent after sending, the error of sending is returned if there was one.
+func (q *Queue) saveResult(intent *Intent) error {
+	if err := q.store.Save(intent); err != nil {
+		return err
+	}
+	if intent.Status == StatusFailed {
+		return fmt.Errorf("approvals: sending intent %v: %v", intent.ID, intent.Error)
+	}
+	return nil
+}
+
+func (q *Queue) isApprover(account common.Address) bool {
+	for _, a := range q.cfg.Approvers {
+		if a == account {
+			return true
+		}
+	}
+	return false
+}
+
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("approvals: %v", err)
+	}
+	return hex.EncodeToString(b), nil
+}
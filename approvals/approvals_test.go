@@ -0,0 +1,258 @@
+package approvals
+
+import (
+	"context"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"reflect"
+	"testing"
+
+	eth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/monetha/go-ethereum"
+	"github.com/monetha/go-ethereum/backend"
+)
+
+type sendingBackend struct {
+	backend.Backend
+	sent []*types.Transaction
+}
+
+func (b *sendingBackend) EstimateGas(ctx context.Context, call eth.CallMsg) (uint64, error) {
+	return 21000, nil
+}
+
+func (b *sendingBackend) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return uint64(len(b.sent)), nil
+}
+
+func (b *sendingBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	b.sent = append(b.sent, tx)
+	return nil
+}
+
+func newTestSession(b backend.Backend) *ethereum.Session {
+	return &ethereum.Session{
+		Eth: ethereum.NewEth(b),
+		TransactOpts: bind.TransactOpts{
+			GasPrice: big.NewInt(1),
+			Signer: func(signer types.Signer, address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+				return tx, nil
+			},
+		},
+	}
+}
+
+func TestQueue(t *testing.T) {
+	approver1 := common.HexToAddress("0x01")
+	approver2 := common.HexToAddress("0x02")
+	to := common.HexToAddress("0x1234")
+
+	b := &sendingBackend{}
+	q, err := NewQueue(newTestSession(b), NewMemoryStore(), Config{
+		Threshold:         big.NewInt(1000),
+		Approvers:         []common.Address{approver1, approver2},
+		RequiredApprovals: 2,
+	})
+	if err != nil {
+		t.Fatalf("NewQueue: %v", err)
+	}
+	ctx := context.Background()
+
+	small, err := q.Submit(ctx, ethereum.PreparedTx{To: to, Value: big.NewInt(999)})
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	if small.Status != StatusSent || len(b.sent) != 1 {
+		t.Errorf("expected transaction below threshold to be sent right away, but got status %v", small.Status)
+	}
+
+	large, err := q.Submit(ctx, ethereum.PreparedTx{To: to, Value: big.NewInt(1000)})
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	if large.Status != StatusPending || len(b.sent) != 1 {
+		t.Errorf("expected transaction above threshold to be pending, but got status %v", large.Status)
+	}
+
+	if _, err := q.Approve(ctx, large.ID, common.HexToAddress("0x03")); err != ErrNotApprover {
+		t.Errorf("expected ErrNotApprover, but got %v", err)
+	}
+
+	for i, approver := range []common.Address{approver1, approver1, approver2} {
+		intent, err := q.Approve(ctx, large.ID, approver)
+		if err != nil {
+			t.Fatalf("Approve: %v", err)
+		}
+		expected := StatusPending
+		if i == 2 {
+			expected = StatusSent
+		}
+		if intent.Status != expected {
+			t.Errorf("approval %v: expected status %v, but got %v", i, expected, intent.Status)
+		}
+	}
+
+	if len(b.sent) != 2 || b.sent[1].Value().Int64() != 1000 {
+		t.Errorf("expected approved transaction to be sent")
+	}
+	if _, err := q.Reject(ctx, large.ID); err != ErrNotPending {
+		t.Errorf("expected ErrNotPending, but got %v", err)
+	}
+}
+
+func TestQueue_Reject(t *testing.T) {
+	q, err := NewQueue(newTestSession(&sendingBackend{}), NewMemoryStore(), Config{Approvers: []common.Address{common.HexToAddress("0x01")}})
+	if err != nil {
+		t.Fatalf("NewQueue: %v", err)
+	}
+	ctx := context.Background()
+
+	intent, err := q.Submit(ctx, ethereum.PreparedTx{To: common.HexToAddress("0x1234")})
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	if _, err := q.Reject(ctx, intent.ID); err != nil {
+		t.Fatalf("Reject: %v", err)
+	}
+	if _, err := q.Approve(ctx, intent.ID, common.HexToAddress("0x01")); err != ErrNotPending {
+		t.Errorf("expected ErrNotPending, but got %v", err)
+	}
+
+	pending, err := q.Pending()
+	if err != nil {
+		t.Fatalf("Pending: %v", err)
+	}
+	if len(pending) != 0 {
+		t.Errorf("expected no pending intents, but got %v", len(pending))
+	}
+}
+
+func TestNewQueue_NoApprovers(t *testing.T) {
+	if _, err := NewQueue(newTestSession(&sendingBackend{}), NewMemoryStore(), Config{}); err != ErrNoApprovers {
+		t.Errorf("expected ErrNoApprovers, but got %v", err)
+	}
+}
+
+// statusStore records statuses of the saved intents.
+type statusStore struct {
+	*MemoryStore
+	statuses []Status
+}
+
+func (s *statusStore) Save(intent *Intent) error {
+	s.statuses = append(s.statuses, intent.Status)
+	return s.MemoryStore.Save(intent)
+}
+
+func TestQueue_savesSendingStatus(t *testing.T) {
+	s := &statusStore{MemoryStore: NewMemoryStore()}
+	q, err := NewQueue(newTestSession(&sendingBackend{}), s, Config{Threshold: big.NewInt(1000), Approvers: []common.Address{common.HexToAddress("0x01")}})
+	if err != nil {
+		t.Fatalf("NewQueue: %v", err)
+	}
+
+	if _, err := q.Submit(context.Background(), ethereum.PreparedTx{To: common.HexToAddress("0x1234")}); err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	expected := []Status{StatusSending, StatusSent}
+	if !reflect.DeepEqual(s.statuses, expected) {
+		t.Errorf("expected saved statuses %v, but got %v", expected, s.statuses)
+	}
+}
+
+func TestQueue_ApproveSigned(t *testing.T) {
+	key, err := ethereum.NewKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	session := newTestSession(&sendingBackend{})
+	session.TransactOpts.From = common.HexToAddress("0xaa")
+	q, err := NewQueue(session, NewMemoryStore(), Config{Approvers: []common.Address{key.Address}, ChainID: big.NewInt(1)})
+	if err != nil {
+		t.Fatalf("NewQueue: %v", err)
+	}
+	ctx := context.Background()
+
+	intent, err := q.Submit(ctx, ethereum.PreparedTx{To: common.HexToAddress("0x1234"), Value: big.NewInt(5)})
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	if intent.Queue != session.TransactOpts.From || intent.ChainID.ToInt().Int64() != 1 {
+		t.Errorf("expected intent of queue %v on chain 1, but got %v on chain %v", session.TransactOpts.From.Hex(),
+			intent.Queue.Hex(), intent.ChainID)
+	}
+
+	otherQueue := intent.clone()
+	otherQueue.Queue = common.HexToAddress("0xbb")
+	otherChain := intent.clone()
+	otherChain.ChainID = (*hexutil.Big)(big.NewInt(2))
+	for _, other := range []*Intent{otherQueue, otherChain} {
+		if other.Hash() == intent.Hash() {
+			t.Errorf("expected hash of intent to depend on the queue and the chain ID")
+		}
+	}
+
+	sig, err := otherChain.SignApproval(key)
+	if err != nil {
+		t.Fatalf("SignApproval: %v", err)
+	}
+	if _, err := q.ApproveSigned(ctx, intent.ID, sig); err != ErrNotApprover {
+		t.Errorf("expected approval signed for another chain to fail with ErrNotApprover, but got %v", err)
+	}
+
+	sig, err = intent.SignApproval(key)
+	if err != nil {
+		t.Fatalf("SignApproval: %v", err)
+	}
+	approved, err := q.ApproveSigned(ctx, intent.ID, sig)
+	if err != nil {
+		t.Fatalf("ApproveSigned: %v", err)
+	}
+	if approved.Status != StatusSent || approved.Approvals[0] != key.Address {
+		t.Errorf("expected intent to be approved by %v and sent, but got %+v", key.Address.Hex(), approved)
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "approvals")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+
+	q, err := NewQueue(newTestSession(&sendingBackend{}), s, Config{Approvers: []common.Address{common.HexToAddress("0x01")}})
+	if err != nil {
+		t.Fatalf("NewQueue: %v", err)
+	}
+	intent, err := q.Submit(context.Background(), ethereum.PreparedTx{To: common.HexToAddress("0x1234"), Value: big.NewInt(5), Data: []byte{1, 2}})
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+
+	// reopen the store as another process would do
+	s, err = NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	loaded, err := s.Load(intent.ID)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if loaded.Value.ToInt().Int64() != 5 || len(loaded.Data) != 2 || loaded.Status != StatusPending {
+		t.Errorf("expected intent to be loaded unchanged, but got %+v", loaded)
+	}
+
+	if _, err := s.Load("unknown"); err != ErrNotFound {
+		t.Errorf("expected ErrNotFound, but got %v", err)
+	}
+}
@@ -0,0 +1,154 @@
+package approvals
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// ErrNotFound is returned when intent with the given ID doesn't exist.
+var ErrNotFound = errors.New("approvals: intent not found")
+
+// Store persists intents.
+type Store interface {
+	Save(intent *Intent) error
+	Load(id string) (*Intent, error)
+	List() ([]*Intent, error)
+}
+
+// MemoryStore keeps intents in memory. It's useful for tests.
+type MemoryStore struct {
+	mu      sync.RWMutex
+	intents map[string]*Intent
+}
+
+// NewMemoryStore creates an instance of MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{intents: make(map[string]*Intent)}
+}
+
+// Save implements Store interface.
+func (s *MemoryStore) Save(intent *Intent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.intents[intent.ID] = intent.clone()
+	return nil
+}
+
+// Load implements Store interface.
+func (s *MemoryStore) Load(id string) (*Intent, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	intent, ok := s.intents[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return intent.clone(), nil
+}
+
+// List implements Store interface, intents are ordered by creation time.
+func (s *MemoryStore) List() ([]*Intent, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	res := make([]*Intent, 0, len(s.intents))
+	for _, intent := range s.intents {
+		res = append(res, intent.clone())
+	}
+	sortIntents(res)
+	return res, nil
+}
+
+// FileStore keeps every intent in a JSON file in the directory.
+type FileStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewFileStore creates an instance of FileStore, the directory is created if it doesn't exist.
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("approvals: %v", err)
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+// Save implements Store interface. Intent is written to a temporary file first, so it's never left half-written.
+func (s *FileStore) Save(intent *Intent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, err := json.MarshalIndent(intent, "", "  ")
+	if err != nil {
+		return fmt.Errorf("approvals: %v", err)
+	}
+
+	path := s.path(intent.ID)
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, b, 0600); err != nil {
+		return fmt.Errorf("approvals: %v", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("approvals: %v", err)
+	}
+	return nil
+}
+
+// Load implements Store interface.
+func (s *FileStore) Load(id string) (*Intent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.load(s.path(id))
+}
+
+// List implements Store interface, intents are ordered by creation time.
+func (s *FileStore) List() ([]*Intent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	paths, err := filepath.Glob(filepath.Join(s.dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("approvals: %v", err)
+	}
+
+	res := make([]*Intent, 0, len(paths))
+	for _, path := range paths {
+		intent, err := s.load(path)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, intent)
+	}
+	sortIntents(res)
+	return res, nil
+}
+
+func (s *FileStore) load(path string) (*Intent, error) {
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("approvals: %v", err)
+	}
+
+	intent := new(Intent)
+	if err := json.Unmarshal(b, intent); err != nil {
+		return nil, fmt.Errorf("approvals: %v: %v", filepath.Base(path), err)
+	}
+	return intent, nil
+}
+
+func (s *FileStore) path(id string) string {
+	return filepath.Join(s.dir, filepath.Base(id)+".json")
+}
+
+func sortIntents(intents []*Intent) {
+	sort.Slice(intents, func(i, j int) bool {
+		return intents[i].CreatedAt.Before(intents[j].CreatedAt)
+	})
+}
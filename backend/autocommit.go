@@ -0,0 +1,36 @@
+package backend
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// SimulatedBackend is a backend with the pending block which needs to be committed explicitly
+// (e.g. SimulatedBackendExt).
+type SimulatedBackend interface {
+	Backend
+	Commit()
+	Rollback()
+}
+
+// AutoCommitBackend commits the pending block of the simulated backend after every successfully sent transaction,
+// so that transactions get mined like in the real network.
+type AutoCommitBackend struct {
+	SimulatedBackend
+}
+
+// NewAutoCommitBackend wraps simulated backend and returns new instance of AutoCommitBackend.
+func NewAutoCommitBackend(sim SimulatedBackend) *AutoCommitBackend {
+	return &AutoCommitBackend{SimulatedBackend: sim}
+}
+
+// SendTransaction sends the transaction and commits the pending block.
+func (b *AutoCommitBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	if err := b.SimulatedBackend.SendTransaction(ctx, tx); err != nil {
+		return err
+	}
+
+	b.Commit()
+	return nil
+}
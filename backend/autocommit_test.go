@@ -0,0 +1,42 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+type commitCounter struct {
+	*backendMock
+	commits int
+}
+
+func (c *commitCounter) Commit()   { c.commits++ }
+func (c *commitCounter) Rollback() {}
+
+func TestAutoCommitBackend_SendTransaction(t *testing.T) {
+	sendErr := errors.New("SendTransaction failed")
+	var returnErr error
+	sim := &commitCounter{backendMock: &backendMock{SendTransactionFunc: func(ctx context.Context, tx *types.Transaction) error {
+		return returnErr
+	}}}
+	b := NewAutoCommitBackend(sim)
+
+	tx := types.NewTransaction(0, handledAddress, nil, 21000, nil, nil)
+	if err := b.SendTransaction(context.TODO(), tx); err != nil {
+		t.Fatalf("SendTransaction: %v", err)
+	}
+	if sim.commits != 1 {
+		t.Errorf("expected 1 commit after successful transaction, but got %v", sim.commits)
+	}
+
+	returnErr = sendErr
+	if err := b.SendTransaction(context.TODO(), tx); err != sendErr {
+		t.Errorf("expected error %v, but got %v", sendErr, err)
+	}
+	if sim.commits != 1 {
+		t.Errorf("expected no commit after failed transaction, but got %v commits", sim.commits)
+	}
+}
@@ -2,12 +2,15 @@ package backend
 
 import (
 	"context"
+	"errors"
 	"math/big"
+	"sync"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/monetha/go-ethereum/metrics"
 )
 
 // Backend contains all methods required for the backend operations.
@@ -17,16 +20,40 @@ type Backend interface {
 	BalanceAt(ctx context.Context, address common.Address, blockNum *big.Int) (*big.Int, error)
 }
 
+// ErrNoHeaders is returned by HeaderByNumber of backends wrapping the backend which can't read headers.
+var ErrNoHeaders = errors.New("backend can't read headers")
+
+// make sure backends can be used to wait for contract deployment
+var (
+	_ bind.DeployBackend = Backend(nil)
+	_ bind.DeployBackend = &HandleNonceBackend{}
+	_ bind.DeployBackend = &GasPriceBackend{}
+	_ bind.DeployBackend = &DryRunBackend{}
+	_ bind.DeployBackend = &MultiBackend{}
+	_ bind.DeployBackend = &PinnedBackend{}
+	_ bind.DeployBackend = &TracingBackend{}
+	_ bind.DeployBackend = &ChaosBackend{}
+	_ bind.DeployBackend = &ReceiptCacheBackend{}
+)
+
 // HandleNonceBackend internally handles nonce of the given addresses. It still calls PendingNonceAt of
 // inner backend, but returns PendingNonceAt as a maximum of pending nonce in block-chain and internally stored nonce.
 // It increments nonce for the given addresses after each successfully sent transaction (transaction may eventually
 // fail in block-cain).
-// Implementation is not thread-safe and should be used within one goroutine because otherwise invocations of
-// PendingNonceAt and SendTransaction should be done atomically to have sequence of nonce without gaps (so that
-// nonce would be equal to number of transactions sent).
+// Implementation should be used within one goroutine because otherwise invocations of PendingNonceAt and
+// SendTransaction should be done atomically to have sequence of nonce without gaps (so that nonce would be equal
+// to number of transactions sent). Nonces may be exported and imported concurrently (see Export and Import).
+//
+// Sent transactions of the handled addresses are tracked until they are mined (see InFlight).
 type HandleNonceBackend struct {
-	inner        Backend
+	inner Backend
+
+	nonceMu      sync.Mutex // guards nonces, so they can be exported and imported concurrently with sending
 	addressNonce map[common.Address]uint64
+
+	mu       sync.Mutex // guards in-flight transactions only
+	inFlight map[common.Address][]*InFlightTx
+	metrics  metrics.Collector
 }
 
 // NewHandleNonceBackend wraps backend and returns new instance of HandleNonceBackend.
@@ -70,6 +97,9 @@ func (b *HandleNonceBackend) PendingNonceAt(ctx context.Context, account common.
 		return
 	}
 
+	b.nonceMu.Lock()
+	defer b.nonceMu.Unlock()
+
 	innerNonce, shouldHandle := b.addressNonce[account]
 	if !shouldHandle {
 		return
@@ -99,9 +129,13 @@ func (b *HandleNonceBackend) EstimateGas(ctx context.Context, call ethereum.Call
 	return b.inner.EstimateGas(ctx, call)
 }
 
-// SendTransaction injects the transaction into the pending pool for execution.
+// SendTransaction injects the transaction into the pending pool for execution. The transaction which is already
+// known to the node is considered to be sent (see IsAlreadyKnown), so the nonce is still incremented.
 func (b *HandleNonceBackend) SendTransaction(ctx context.Context, tx *types.Transaction) (err error) {
 	err = b.inner.SendTransaction(ctx, tx)
+	if IsAlreadyKnown(err) {
+		err = nil
+	}
 	if err != nil {
 		return
 	}
@@ -117,20 +151,28 @@ func (b *HandleNonceBackend) incrementNonce(tx *types.Transaction) {
 		return // invalid sender
 	}
 
+	nonce := tx.Nonce() + 1
+	b.nonceMu.Lock()
 	innerNonce, shouldHandle := b.addressNonce[from]
+	if shouldHandle && nonce > innerNonce {
+		b.addressNonce[from] = nonce
+	}
+	b.nonceMu.Unlock()
+
 	if !shouldHandle {
 		return
 	}
 
-	nonce := tx.Nonce() + 1
-	if nonce > innerNonce {
-		b.addressNonce[from] = nonce
-	}
+	b.addInFlight(from, tx)
 }
 
 // TransactionReceipt returns the receipt of a transaction by transaction hash.
 func (b *HandleNonceBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
-	return b.inner.TransactionReceipt(ctx, txHash)
+	r, err := b.inner.TransactionReceipt(ctx, txHash)
+	if err == nil {
+		b.removeMined(txHash)
+	}
+	return r, err
 }
 
 // BalanceAt returns the balance of the account of given address.
@@ -158,6 +200,43 @@ func (b *HandleNonceBackend) TransactionByHash(ctx context.Context, txHash commo
 	return b.inner.TransactionByHash(ctx, txHash)
 }
 
+// NonceSnapshot is the state of nonces handled by HandleNonceBackend. It can be marshaled to JSON to carry
+// the state across process boundaries.
+type NonceSnapshot map[common.Address]uint64
+
+// Export returns the copy of nonces of the handled addresses.
+func (b *HandleNonceBackend) Export() NonceSnapshot {
+	b.nonceMu.Lock()
+	defer b.nonceMu.Unlock()
+
+	s := make(NonceSnapshot, len(b.addressNonce))
+	for address, nonce := range b.addressNonce {
+		s[address] = nonce
+	}
+	return s
+}
+
+// Import replaces nonces of the addresses with the ones from the snapshot. Addresses of the snapshot which
+// weren't handled become handled. Nonces of other handled addresses aren't changed.
+func (b *HandleNonceBackend) Import(s NonceSnapshot) {
+	b.nonceMu.Lock()
+	defer b.nonceMu.Unlock()
+
+	for address, nonce := range s {
+		b.addressNonce[address] = nonce
+	}
+}
+
+// AsHandleNonceBackend returns HandleNonceBackend created by NewHandleNonceBackend, which may be wrapped
+// to keep Commit and Rollback methods of the simulated backend.
+func AsHandleNonceBackend(b Backend) (*HandleNonceBackend, bool) {
+	if sb, ok := b.(*simBackend); ok {
+		b = sb.b
+	}
+	hb, ok := b.(*HandleNonceBackend)
+	return hb, ok
+}
+
 type commiterRollbacker interface {
 	Commit()
 	Rollback()
@@ -223,3 +302,32 @@ func (b *simBackend) Commit() {
 func (b *simBackend) Rollback() {
 	b.cr.Rollback()
 }
+
+// HeaderByNumber returns the block header with the given number (the latest one, if number is nil).
+// The inner backend must implement HeaderByNumber method, otherwise ErrNoHeaders is returned.
+func (b *HandleNonceBackend) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return headerByNumber(ctx, b.inner, number)
+}
+
+// ChainID returns chain ID of the inner backend, which must implement ChainID method, otherwise ErrNoChainID
+// is returned.
+func (b *HandleNonceBackend) ChainID(ctx context.Context) (*big.Int, error) {
+	return ChainID(ctx, b.inner)
+}
+
+func (b *simBackend) ChainID(ctx context.Context) (*big.Int, error) {
+	return ChainID(ctx, b.b)
+}
+
+func (b *simBackend) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return headerByNumber(ctx, b.b, number)
+}
+
+// headerByNumber returns the block header of the backend, if it implements HeaderByNumber method.
+func headerByNumber(ctx context.Context, b Backend, number *big.Int) (*types.Header, error) {
+	hr, ok := b.(headerReader)
+	if !ok {
+		return nil, ErrNoHeaders
+	}
+	return hr.HeaderByNumber(ctx, number)
+}
@@ -3,8 +3,11 @@ package backend
 import (
 	"context"
 	"crypto/ecdsa"
+	"encoding/json"
 	"errors"
 	"math/big"
+	"reflect"
+	"sync"
 	"testing"
 
 	"github.com/ethereum/go-ethereum"
@@ -12,6 +15,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/monetha/go-ethereum/metrics"
 )
 
 var (
@@ -190,6 +194,175 @@ func TestHandleNonceBackend_SendTransaction(t *testing.T) {
 	})
 }
 
+func TestHandleNonceBackend_ExportImport(t *testing.T) {
+	inner := &backendMock{
+		PendingNonceAtFunc: func(ctx context.Context, account common.Address) (uint64, error) {
+			return 3, nil
+		},
+		SendTransactionFunc: func(ctx context.Context, tx *types.Transaction) error {
+			return nil
+		},
+	}
+	ctx := context.TODO()
+
+	src, ok := AsHandleNonceBackend(NewHandleNonceBackend(inner, []common.Address{handledAddress}))
+	if !ok {
+		t.Fatal("expected HandleNonceBackend")
+	}
+	if err := src.SendTransaction(ctx, createTx(handledAddressKey, 7, nonHandledAddress)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	snapshot := src.Export()
+	if expected := (NonceSnapshot{handledAddress: 8}); !reflect.DeepEqual(snapshot, expected) {
+		t.Fatalf("expected snapshot %v, but got %v", expected, snapshot)
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var restored NonceSnapshot
+	if err := json.Unmarshal(data, &restored); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	dst, _ := AsHandleNonceBackend(NewHandleNonceBackend(inner, nil))
+	dst.Import(restored)
+
+	nonce, err := dst.PendingNonceAt(ctx, handledAddress)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if nonce != 8 {
+		t.Errorf("expected nonce %v, but got %v", 8, nonce)
+	}
+
+	snapshot[handledAddress] = 100
+	if nonce := src.Export()[handledAddress]; nonce != 8 {
+		t.Errorf("expected exported snapshot to be a copy, but nonce changed to %v", nonce)
+	}
+}
+
+func TestHandleNonceBackend_ExportImport_Concurrent(t *testing.T) {
+	inner := &backendMock{
+		PendingNonceAtFunc: func(ctx context.Context, account common.Address) (uint64, error) {
+			return 3, nil
+		},
+	}
+	b, _ := AsHandleNonceBackend(NewHandleNonceBackend(inner, []common.Address{handledAddress}))
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			if _, err := b.PendingNonceAt(context.TODO(), handledAddress); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			b.Import(b.Export())
+		}
+	}()
+	wg.Wait()
+
+	if nonce := b.Export()[handledAddress]; nonce != 3 {
+		t.Errorf("expected nonce %v, but got %v", 3, nonce)
+	}
+}
+
+func TestHandleNonceBackend_SendTransaction_AlreadyKnown(t *testing.T) {
+	inner := &backendMock{
+		PendingNonceAtFunc: func(ctx context.Context, account common.Address) (uint64, error) {
+			return 0, nil
+		},
+		SendTransactionFunc: func(ctx context.Context, tx *types.Transaction) error {
+			return errors.New("already known")
+		},
+	}
+	ctx := context.TODO()
+
+	b := NewHandleNonceBackend(inner, []common.Address{handledAddress})
+	if err := b.SendTransaction(ctx, createTx(handledAddressKey, 0, nonHandledAddress)); err != nil {
+		t.Fatalf("expected already known transaction to be sent, but got error: %v", err)
+	}
+
+	nonce, err := b.PendingNonceAt(ctx, handledAddress)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if nonce != 1 {
+		t.Errorf("expected nonce %v, but got %v", 1, nonce)
+	}
+}
+
+type inFlightMetrics struct {
+	metrics.Collector
+	counts []int
+}
+
+func (m *inFlightMetrics) InFlightTxs(address string, count int) {
+	m.counts = append(m.counts, count)
+}
+
+func TestHandleNonceBackend_InFlight(t *testing.T) {
+	inner := &backendMock{
+		PendingNonceAtFunc: func(ctx context.Context, account common.Address) (uint64, error) {
+			return 7, nil
+		},
+		SendTransactionFunc: func(ctx context.Context, tx *types.Transaction) error {
+			return nil
+		},
+		TransactionReceiptFunc: func(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+			return &types.Receipt{TxHash: txHash}, nil
+		},
+	}
+	ctx := context.TODO()
+
+	b, _ := AsHandleNonceBackend(NewHandleNonceBackend(inner, []common.Address{handledAddress}))
+	m := &inFlightMetrics{Collector: metrics.Nop}
+	b.SetMetrics(m)
+
+	tx7 := createTx(handledAddressKey, 7, nonHandledAddress)
+	tx8 := createTx(handledAddressKey, 8, nonHandledAddress)
+	tx8Replacement := createTx(handledAddressKey, 8, handledAddress)
+	for _, tx := range []*types.Transaction{tx7, tx8, tx8Replacement, createTx(nonHandledAddressKey, 0, handledAddress)} {
+		if err := b.SendTransaction(ctx, tx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if depth := b.QueueDepth(); depth != 2 {
+		t.Fatalf("expected queue depth 2, but got %v", depth)
+	}
+	txs := b.InFlight()[handledAddress]
+	if len(txs) != 2 {
+		t.Fatalf("expected 2 in-flight transactions, but got %v", len(txs))
+	}
+	if txs[0].Nonce != 7 || txs[0].Hash != tx7.Hash() || txs[0].Attempts != 1 {
+		t.Errorf("unexpected first in-flight transaction %+v", txs[0])
+	}
+	if txs[1].Nonce != 8 || txs[1].Hash != tx8Replacement.Hash() || txs[1].Attempts != 2 {
+		t.Errorf("unexpected second in-flight transaction %+v", txs[1])
+	}
+
+	// receipt of the replaced transaction means the nonce is used
+	if _, err := b.TransactionReceipt(ctx, tx8.Hash()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if depth := b.QueueDepth(); depth != 0 {
+		t.Errorf("expected empty queue, but got depth %v", depth)
+	}
+
+	if expected := []int{1, 2, 0}; !reflect.DeepEqual(m.counts, expected) {
+		t.Errorf("expected reported counts %v, but got %v", expected, m.counts)
+	}
+}
+
 func createTx(key *ecdsa.PrivateKey, nonce uint64, to common.Address) *types.Transaction {
 	opts := bind.NewKeyedTransactor(key)
 	opts.Value = big.NewInt(1000000000000000000)
@@ -262,3 +435,39 @@ func (m *backendMock) SubscribeFilterLogs(ctx context.Context, query ethereum.Fi
 func (m *backendMock) TransactionByHash(ctx context.Context, txHash common.Hash) (tx *types.Transaction, isPending bool, err error) {
 	return nil, false, nil
 }
+
+func TestDecorators_HeaderByNumber(t *testing.T) {
+	decorators := []struct {
+		name string
+		wrap func(Backend) Backend
+	}{
+		{"HandleNonceBackend", func(b Backend) Backend { return NewHandleNonceBackend(b, nil) }},
+		{"ChainIDGuardBackend", func(b Backend) Backend { return NewChainIDGuardBackend(b, big.NewInt(1), nil) }},
+		{"ChaosBackend", func(b Backend) Backend { return NewChaosBackend(b, ChaosConfig{}) }},
+		{"DedupBackend", NewDedupBackend},
+		{"DryRunBackend", func(b Backend) Backend { return NewDryRunBackend(b, nil) }},
+		{"GasPriceBackend", func(b Backend) Backend { return NewGasPriceBackend(b, func() *big.Int { return nil }) }},
+		{"PrivateTxBackend", func(b Backend) Backend { return NewPrivateTxBackend(b, nil) }},
+		{"TracingBackend", func(b Backend) Backend { return NewTracingBackend(b, &recordingTracer{}) }},
+	}
+
+	for _, d := range decorators {
+		t.Run(d.name, func(t *testing.T) {
+			hr, ok := d.wrap(&blockNumberMock{head: 10}).(headerReader)
+			if !ok {
+				t.Fatalf("expected backend to implement HeaderByNumber")
+			}
+			h, err := hr.HeaderByNumber(context.Background(), nil)
+			if err != nil {
+				t.Fatalf("HeaderByNumber: %v", err)
+			}
+			if h.Number.Int64() != 10 {
+				t.Errorf("expected header of the inner backend, but got block %v", h.Number)
+			}
+
+			if _, err := d.wrap(&backendMock{}).(headerReader).HeaderByNumber(context.Background(), nil); err != ErrNoHeaders {
+				t.Errorf("expected ErrNoHeaders, but got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ErrNoChainID is returned by ChainID of backends wrapping the backend which doesn't provide chain ID.
+var ErrNoChainID = errors.New("backend doesn't provide chain ID")
+
+// ChainIDMismatchError is returned when chain ID of the backend or the transaction differs from the expected one.
+type ChainIDMismatchError struct {
+	Expected *big.Int
+	Actual   *big.Int
+	Tx       bool // whether Actual is chain ID of the transaction, rather than of the backend
+}
+
+func (e *ChainIDMismatchError) Error() string {
+	if e.Tx {
+		return fmt.Sprintf("chain ID mismatch: expected %v, but transaction has %v", e.Expected, e.Actual)
+	}
+	return fmt.Sprintf("chain ID mismatch: expected %v, but backend has %v", e.Expected, e.Actual)
+}
+
+// ChainIDFunc returns chain ID of the network.
+type ChainIDFunc func(ctx context.Context) (*big.Int, error)
+
+// ChainIDGuardBackend refuses to send transactions unless chain ID of the backend matches the expected one,
+// which prevents accidental sends to the wrong network (e.g. mainnet sends with testnet configuration).
+// Chain ID of the backend is checked before the first transaction is sent. All other calls are passed
+// to the inner backend.
+type ChainIDGuardBackend struct {
+	Backend
+	expected *big.Int
+	chainID  ChainIDFunc
+	mu       sync.Mutex
+	checked  bool
+}
+
+// NewChainIDGuardBackend wraps backend and returns new instance of ChainIDGuardBackend. If chainID function is nil,
+// the inner backend must have ChainID method (like ethclient.Client and backends of this package).
+// Network ID isn't used instead, as it differs from chain ID on some networks.
+func NewChainIDGuardBackend(inner Backend, expected *big.Int, chainID ChainIDFunc) *ChainIDGuardBackend {
+	if chainID == nil {
+		chainID = func(ctx context.Context) (*big.Int, error) {
+			return ChainID(ctx, inner)
+		}
+	}
+	return &ChainIDGuardBackend{Backend: inner, expected: expected, chainID: chainID}
+}
+
+// CheckChainID checks that chain ID of the backend matches the expected one.
+func (b *ChainIDGuardBackend) CheckChainID(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.checked {
+		return nil
+	}
+
+	chainID, err := b.chainID(ctx)
+	if err == ErrNoChainID {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("getting chain ID: %v", err)
+	}
+	if chainID.Cmp(b.expected) != 0 {
+		return &ChainIDMismatchError{Expected: b.expected, Actual: chainID}
+	}
+
+	b.checked = true
+	return nil
+}
+
+// SendTransaction sends the transaction if chain ID of the backend and the transaction (if it's replay-protected)
+// matches the expected one.
+func (b *ChainIDGuardBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	if err := b.CheckChainID(ctx); err != nil {
+		return err
+	}
+	if tx.Protected() && tx.ChainId().Cmp(b.expected) != 0 {
+		return &ChainIDMismatchError{Expected: b.expected, Actual: tx.ChainId(), Tx: true}
+	}
+
+	return b.Backend.SendTransaction(ctx, tx)
+}
+
+// HeaderByNumber returns the block header with the given number (the latest one, if number is nil).
+// The inner backend must implement HeaderByNumber method, otherwise ErrNoHeaders is returned.
+func (b *ChainIDGuardBackend) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return headerByNumber(ctx, b.Backend, number)
+}
+
+// ChainID returns chain ID of the inner backend.
+func (b *ChainIDGuardBackend) ChainID(ctx context.Context) (*big.Int, error) {
+	return ChainID(ctx, b.Backend)
+}
+
+// ChainID returns chain ID of the backend, which must implement ChainID method (like ethclient.Client and backends
+// of this package), otherwise ErrNoChainID is returned.
+func ChainID(ctx context.Context, b bind.ContractTransactor) (*big.Int, error) {
+	r, ok := b.(chainIDReader)
+	if !ok {
+		return nil, ErrNoChainID
+	}
+	return r.ChainID(ctx)
+}
+
+type chainIDReader interface {
+	ChainID(ctx context.Context) (*big.Int, error)
+}
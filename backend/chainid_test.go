@@ -0,0 +1,100 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestChainIDGuardBackend_SendTransaction(t *testing.T) {
+	tx := types.NewTransaction(0, nonHandledAddress, big.NewInt(1), 21000, big.NewInt(1), nil)
+
+	tests := []struct {
+		name      string
+		chainID   ChainIDFunc
+		txChainID *big.Int
+		sent      bool
+		mismatch  bool
+	}{
+		{
+			name:    "matching chain ID",
+			chainID: func(ctx context.Context) (*big.Int, error) { return big.NewInt(3), nil },
+			sent:    true,
+		},
+		{
+			name:     "backend on another chain",
+			chainID:  func(ctx context.Context) (*big.Int, error) { return big.NewInt(1), nil },
+			mismatch: true,
+		},
+		{
+			name:    "chain ID error",
+			chainID: func(ctx context.Context) (*big.Int, error) { return nil, errors.New("no connection") },
+		},
+		{
+			name:      "transaction signed for another chain",
+			chainID:   func(ctx context.Context) (*big.Int, error) { return big.NewInt(3), nil },
+			txChainID: big.NewInt(1),
+			mismatch:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sent := false
+			inner := &backendMock{SendTransactionFunc: func(ctx context.Context, tx *types.Transaction) error {
+				sent = true
+				return nil
+			}}
+			b := NewChainIDGuardBackend(inner, big.NewInt(3), tt.chainID)
+
+			signedTx := tx
+			if tt.txChainID != nil {
+				var err error
+				signedTx, err = types.SignTx(tx, types.NewEIP155Signer(tt.txChainID), handledAddressKey)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := b.SendTransaction(context.TODO(), signedTx)
+			if tt.sent && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !tt.sent && err == nil {
+				t.Errorf("expected error")
+			}
+			if sent != tt.sent {
+				t.Errorf("expected transaction sent %v, but got %v", tt.sent, sent)
+			}
+			if _, ok := err.(*ChainIDMismatchError); ok != tt.mismatch {
+				t.Errorf("expected chain ID mismatch error %v, but got %v", tt.mismatch, err)
+			}
+		})
+	}
+}
+
+type chainIDBackend struct {
+	backendMock
+	chainID *big.Int
+}
+
+func (b *chainIDBackend) ChainID(ctx context.Context) (*big.Int, error) {
+	return b.chainID, nil
+}
+
+func TestNewChainIDGuardBackend_decorated(t *testing.T) {
+	inner := NewTracingBackend(NewHandleNonceBackend(&chainIDBackend{chainID: big.NewInt(1)}, nil), &recordingTracer{})
+
+	err := NewChainIDGuardBackend(inner, big.NewInt(3), nil).CheckChainID(context.Background())
+	if e, ok := err.(*ChainIDMismatchError); !ok || e.Actual.Int64() != 1 || e.Expected.Int64() != 3 {
+		t.Errorf("expected chain ID mismatch of the decorated backend, but got %v", err)
+	}
+
+	err = NewChainIDGuardBackend(NewHandleNonceBackend(&backendMock{}, nil), big.NewInt(3), nil).CheckChainID(context.Background())
+	if err != ErrNoChainID {
+		t.Errorf("expected ErrNoChainID, but got %v", err)
+	}
+}
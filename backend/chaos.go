@@ -0,0 +1,258 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ErrInjectedFault is the default error returned by ChaosBackend for injected failures.
+var ErrInjectedFault = errors.New("backend: injected fault")
+
+// ChaosConfig configures faults injected by ChaosBackend. Rates are probabilities in range [0, 1], zero values
+// disable the fault.
+type ChaosConfig struct {
+	// Seed of the random generator, calls of the backend from one goroutine inject the same faults for the same seed.
+	Seed int64
+	// MaxLatency is the upper bound of random latency added to every call.
+	MaxLatency time.Duration
+	// TimeoutRate is the probability of the call hanging until Timeout elapses (or the context is done)
+	// and failing with context.DeadlineExceeded.
+	TimeoutRate float64
+	Timeout     time.Duration
+	// ErrorRate is the probability of the call failing with Error (ErrInjectedFault if nil)
+	// without calling the inner backend.
+	ErrorRate float64
+	Error     error
+	// WrongNonceRate is the probability of PendingNonceAt returning the nonce lower by one than the actual one,
+	// as lagging nodes behind a load balancer do.
+	WrongNonceRate float64
+	// DuplicateReceiptRate is the probability of TransactionReceipt returning the receipt returned by
+	// the previous call instead of the requested one, as providers with stale caches do.
+	DuplicateReceiptRate float64
+}
+
+// ChaosBackend injects faults into calls of the inner backend to test how consumers behave against flaky
+// RPC providers. It's intended for tests only.
+type ChaosBackend struct {
+	Backend
+	cfg ChaosConfig
+
+	mu          sync.Mutex
+	rnd         *rand.Rand
+	lastReceipt *types.Receipt
+}
+
+// NewChaosBackend wraps backend and returns new instance of ChaosBackend.
+func NewChaosBackend(inner Backend, cfg ChaosConfig) Backend {
+	if cfg.Error == nil {
+		cfg.Error = ErrInjectedFault
+	}
+	b := &ChaosBackend{Backend: inner, cfg: cfg, rnd: rand.New(rand.NewSource(cfg.Seed))}
+
+	if cr, ok := inner.(commiterRollbacker); ok {
+		return &simBackend{
+			b:  b,
+			cr: cr,
+		}
+	}
+
+	return b
+}
+
+func (b *ChaosBackend) chance(rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.rnd.Float64() < rate
+}
+
+func (b *ChaosBackend) latency() time.Duration {
+	if b.cfg.MaxLatency <= 0 {
+		return 0
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return time.Duration(b.rnd.Int63n(int64(b.cfg.MaxLatency) + 1))
+}
+
+// fault delays the call and returns the injected error, if any.
+func (b *ChaosBackend) fault(ctx context.Context) error {
+	if err := sleep(ctx, b.latency()); err != nil {
+		return err
+	}
+
+	if b.chance(b.cfg.TimeoutRate) {
+		if err := sleep(ctx, b.cfg.Timeout); err != nil {
+			return err
+		}
+		return context.DeadlineExceeded
+	}
+
+	if b.chance(b.cfg.ErrorRate) {
+		return b.cfg.Error
+	}
+
+	return nil
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// CodeAt returns the code of the given account.
+func (b *ChaosBackend) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	if err := b.fault(ctx); err != nil {
+		return nil, err
+	}
+	return b.Backend.CodeAt(ctx, contract, blockNumber)
+}
+
+// CallContract executes an Ethereum contract call with the specified data as the input.
+func (b *ChaosBackend) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	if err := b.fault(ctx); err != nil {
+		return nil, err
+	}
+	return b.Backend.CallContract(ctx, call, blockNumber)
+}
+
+// PendingCodeAt returns the code of the given account in the pending state.
+func (b *ChaosBackend) PendingCodeAt(ctx context.Context, account common.Address) ([]byte, error) {
+	if err := b.fault(ctx); err != nil {
+		return nil, err
+	}
+	return b.Backend.PendingCodeAt(ctx, account)
+}
+
+// PendingNonceAt retrieves the current pending nonce associated with an account. It may return the nonce
+// lower by one than the actual one.
+func (b *ChaosBackend) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	if err := b.fault(ctx); err != nil {
+		return 0, err
+	}
+
+	nonce, err := b.Backend.PendingNonceAt(ctx, account)
+	if err == nil && nonce > 0 && b.chance(b.cfg.WrongNonceRate) {
+		nonce--
+	}
+	return nonce, err
+}
+
+// SuggestGasPrice retrieves the currently suggested gas price.
+func (b *ChaosBackend) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	if err := b.fault(ctx); err != nil {
+		return nil, err
+	}
+	return b.Backend.SuggestGasPrice(ctx)
+}
+
+// EstimateGas tries to estimate the gas needed to execute a specific transaction.
+func (b *ChaosBackend) EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error) {
+	if err := b.fault(ctx); err != nil {
+		return 0, err
+	}
+	return b.Backend.EstimateGas(ctx, call)
+}
+
+// SendTransaction injects the transaction into the pending pool for execution.
+func (b *ChaosBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	if err := b.fault(ctx); err != nil {
+		return err
+	}
+	return b.Backend.SendTransaction(ctx, tx)
+}
+
+// TransactionReceipt returns the receipt of a transaction by transaction hash. It may return the receipt
+// returned by the previous call instead.
+func (b *ChaosBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	if err := b.fault(ctx); err != nil {
+		return nil, err
+	}
+
+	if b.chance(b.cfg.DuplicateReceiptRate) {
+		b.mu.Lock()
+		r := b.lastReceipt
+		b.mu.Unlock()
+		if r != nil {
+			return r, nil
+		}
+	}
+
+	r, err := b.Backend.TransactionReceipt(ctx, txHash)
+	if err == nil && r != nil {
+		b.mu.Lock()
+		b.lastReceipt = r
+		b.mu.Unlock()
+	}
+	return r, err
+}
+
+// BalanceAt returns the balance of the account of given address.
+func (b *ChaosBackend) BalanceAt(ctx context.Context, address common.Address, blockNum *big.Int) (*big.Int, error) {
+	if err := b.fault(ctx); err != nil {
+		return nil, err
+	}
+	return b.Backend.BalanceAt(ctx, address, blockNum)
+}
+
+// FilterLogs executes a log filter operation.
+func (b *ChaosBackend) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	if err := b.fault(ctx); err != nil {
+		return nil, err
+	}
+	return b.Backend.FilterLogs(ctx, query)
+}
+
+// SubscribeFilterLogs creates a background log filtering operation.
+func (b *ChaosBackend) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	if err := b.fault(ctx); err != nil {
+		return nil, err
+	}
+	return b.Backend.SubscribeFilterLogs(ctx, query, ch)
+}
+
+// TransactionByHash returns the transaction with the given hash.
+func (b *ChaosBackend) TransactionByHash(ctx context.Context, txHash common.Hash) (*types.Transaction, bool, error) {
+	if err := b.fault(ctx); err != nil {
+		return nil, false, err
+	}
+	return b.Backend.TransactionByHash(ctx, txHash)
+}
+
+// HeaderByNumber returns the block header with the given number (the latest one, if number is nil).
+// The inner backend must implement HeaderByNumber method, otherwise ErrNoHeaders is returned.
+func (b *ChaosBackend) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	if err := b.fault(ctx); err != nil {
+		return nil, err
+	}
+	return headerByNumber(ctx, b.Backend, number)
+}
+
+// ChainID returns chain ID of the inner backend, which must implement ChainID method, otherwise ErrNoChainID
+// is returned.
+func (b *ChaosBackend) ChainID(ctx context.Context) (*big.Int, error) {
+	if err := b.fault(ctx); err != nil {
+		return nil, err
+	}
+	return ChainID(ctx, b.Backend)
+}
@@ -0,0 +1,103 @@
+package backend
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+type chaosMock struct {
+	Backend
+	calls int
+}
+
+func (m *chaosMock) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	m.calls++
+	return big.NewInt(1), nil
+}
+
+func (m *chaosMock) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return 10, nil
+}
+
+func (m *chaosMock) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	return &types.Receipt{TxHash: txHash}, nil
+}
+
+func TestChaosBackend_Errors(t *testing.T) {
+	m := &chaosMock{}
+	b := NewChaosBackend(m, ChaosConfig{Seed: 1, ErrorRate: 0.5})
+
+	const calls = 1000
+	var failed int
+	for i := 0; i < calls; i++ {
+		if _, err := b.SuggestGasPrice(context.Background()); err != nil {
+			if err != ErrInjectedFault {
+				t.Fatalf("expected error %v, but got %v", ErrInjectedFault, err)
+			}
+			failed++
+		}
+	}
+
+	if failed < calls/4 || failed > calls*3/4 {
+		t.Errorf("expected about %v failed calls, but got %v", calls/2, failed)
+	}
+	if m.calls != calls-failed {
+		t.Errorf("expected %v calls of inner backend, but got %v", calls-failed, m.calls)
+	}
+}
+
+func TestChaosBackend_NoFaults(t *testing.T) {
+	b := NewChaosBackend(&chaosMock{}, ChaosConfig{})
+
+	for i := 0; i < 100; i++ {
+		if _, err := b.SuggestGasPrice(context.Background()); err != nil {
+			t.Fatalf("expected no error, but got %v", err)
+		}
+		if nonce, _ := b.PendingNonceAt(context.Background(), common.Address{}); nonce != 10 {
+			t.Fatalf("expected nonce 10, but got %v", nonce)
+		}
+	}
+}
+
+func TestChaosBackend_Timeout(t *testing.T) {
+	b := NewChaosBackend(&chaosMock{}, ChaosConfig{TimeoutRate: 1, Timeout: time.Millisecond})
+
+	if _, err := b.SuggestGasPrice(context.Background()); err != context.DeadlineExceeded {
+		t.Errorf("expected error %v, but got %v", context.DeadlineExceeded, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	b = NewChaosBackend(&chaosMock{}, ChaosConfig{TimeoutRate: 1, Timeout: time.Hour})
+	if _, err := b.SuggestGasPrice(ctx); err != context.Canceled {
+		t.Errorf("expected error %v, but got %v", context.Canceled, err)
+	}
+}
+
+func TestChaosBackend_WrongNonce(t *testing.T) {
+	b := NewChaosBackend(&chaosMock{}, ChaosConfig{WrongNonceRate: 1})
+
+	if nonce, _ := b.PendingNonceAt(context.Background(), common.Address{}); nonce != 9 {
+		t.Errorf("expected nonce 9, but got %v", nonce)
+	}
+}
+
+func TestChaosBackend_DuplicateReceipt(t *testing.T) {
+	b := NewChaosBackend(&chaosMock{}, ChaosConfig{DuplicateReceiptRate: 1})
+
+	first := common.Hash{1}
+	r, _ := b.TransactionReceipt(context.Background(), first)
+	if r.TxHash != first {
+		t.Fatalf("expected receipt of %v, but got %v", first.Hex(), r.TxHash.Hex())
+	}
+
+	r, _ = b.TransactionReceipt(context.Background(), common.Hash{2})
+	if r.TxHash != first {
+		t.Errorf("expected duplicate receipt of %v, but got %v", first.Hex(), r.TxHash.Hex())
+	}
+}
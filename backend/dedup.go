@@ -0,0 +1,214 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// DedupBackend collapses concurrent identical reads (same method and arguments) into one call of the inner backend,
+// all callers get the result of that call. It reduces the number of requests to the node under bursty load, e.g. when
+// many goroutines ask for the gas price or the code of the same contract at the same time.
+// The shared call is made with the context of the first caller, so its cancellation fails the call for all waiting
+// callers. Sending of transactions and subscriptions are passed to the inner backend.
+type DedupBackend struct {
+	Backend
+	mu      sync.Mutex
+	calls   map[string]*dedupCall
+	deduped int
+}
+
+type dedupCall struct {
+	wg  sync.WaitGroup
+	val interface{}
+	err error
+}
+
+// NewDedupBackend wraps backend and returns new instance of DedupBackend.
+func NewDedupBackend(inner Backend) Backend {
+	b := &DedupBackend{Backend: inner, calls: make(map[string]*dedupCall)}
+
+	if cr, ok := inner.(commiterRollbacker); ok {
+		return &simBackend{
+			b:  b,
+			cr: cr,
+		}
+	}
+
+	return b
+}
+
+// do calls fn only once for all concurrent callers with the same key.
+func (b *DedupBackend) do(key string, fn func() (interface{}, error)) (interface{}, error) {
+	b.mu.Lock()
+	if c, ok := b.calls[key]; ok {
+		b.deduped++
+		b.mu.Unlock()
+		c.wg.Wait()
+		return c.val, c.err
+	}
+	c := new(dedupCall)
+	c.wg.Add(1)
+	b.calls[key] = c
+	b.mu.Unlock()
+
+	c.val, c.err = fn()
+	c.wg.Done()
+
+	b.mu.Lock()
+	delete(b.calls, key)
+	b.mu.Unlock()
+
+	return c.val, c.err
+}
+
+// Deduplicated returns the number of reads which got the result of the call made for another caller, i.e. the number
+// of requests saved.
+func (b *DedupBackend) Deduplicated() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.deduped
+}
+
+func blockKey(blockNumber *big.Int) string {
+	if blockNumber == nil {
+		return "latest"
+	}
+	return blockNumber.String()
+}
+
+func callKey(call ethereum.CallMsg) string {
+	to := "nil"
+	if call.To != nil {
+		to = call.To.Hex()
+	}
+	return fmt.Sprintf("%v:%v:%v:%v:%v:%x", call.From.Hex(), to, call.Gas, call.GasPrice, call.Value, call.Data)
+}
+
+func copyBytes(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+	return append([]byte(nil), b...)
+}
+
+func copyInt(i *big.Int) *big.Int {
+	if i == nil {
+		return nil
+	}
+	return new(big.Int).Set(i)
+}
+
+// CodeAt returns the code of the given account.
+func (b *DedupBackend) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	v, err := b.do("CodeAt:"+contract.Hex()+":"+blockKey(blockNumber), func() (interface{}, error) {
+		return b.Backend.CodeAt(ctx, contract, blockNumber)
+	})
+	code, _ := v.([]byte)
+	return copyBytes(code), err
+}
+
+// CallContract executes an Ethereum contract call with the specified data as the input.
+func (b *DedupBackend) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	v, err := b.do("CallContract:"+callKey(call)+":"+blockKey(blockNumber), func() (interface{}, error) {
+		return b.Backend.CallContract(ctx, call, blockNumber)
+	})
+	res, _ := v.([]byte)
+	return copyBytes(res), err
+}
+
+// PendingCodeAt returns the code of the given account in the pending state.
+func (b *DedupBackend) PendingCodeAt(ctx context.Context, account common.Address) ([]byte, error) {
+	v, err := b.do("PendingCodeAt:"+account.Hex(), func() (interface{}, error) {
+		return b.Backend.PendingCodeAt(ctx, account)
+	})
+	code, _ := v.([]byte)
+	return copyBytes(code), err
+}
+
+// PendingNonceAt retrieves the current pending nonce associated with an account.
+func (b *DedupBackend) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	v, err := b.do("PendingNonceAt:"+account.Hex(), func() (interface{}, error) {
+		return b.Backend.PendingNonceAt(ctx, account)
+	})
+	nonce, _ := v.(uint64)
+	return nonce, err
+}
+
+// SuggestGasPrice retrieves the currently suggested gas price.
+func (b *DedupBackend) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	v, err := b.do("SuggestGasPrice", func() (interface{}, error) {
+		return b.Backend.SuggestGasPrice(ctx)
+	})
+	gasPrice, _ := v.(*big.Int)
+	return copyInt(gasPrice), err
+}
+
+// EstimateGas tries to estimate the gas needed to execute a specific transaction.
+func (b *DedupBackend) EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error) {
+	v, err := b.do("EstimateGas:"+callKey(call), func() (interface{}, error) {
+		return b.Backend.EstimateGas(ctx, call)
+	})
+	gas, _ := v.(uint64)
+	return gas, err
+}
+
+// TransactionReceipt returns the receipt of a transaction by transaction hash.
+func (b *DedupBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	v, err := b.do("TransactionReceipt:"+txHash.Hex(), func() (interface{}, error) {
+		return b.Backend.TransactionReceipt(ctx, txHash)
+	})
+	r, _ := v.(*types.Receipt)
+	return r, err
+}
+
+// TransactionByHash returns the transaction with the given hash.
+func (b *DedupBackend) TransactionByHash(ctx context.Context, txHash common.Hash) (*types.Transaction, bool, error) {
+	type result struct {
+		tx        *types.Transaction
+		isPending bool
+	}
+	v, err := b.do("TransactionByHash:"+txHash.Hex(), func() (interface{}, error) {
+		tx, isPending, err := b.Backend.TransactionByHash(ctx, txHash)
+		return result{tx, isPending}, err
+	})
+	r, _ := v.(result)
+	return r.tx, r.isPending, err
+}
+
+// BalanceAt returns the balance of the account of given address.
+func (b *DedupBackend) BalanceAt(ctx context.Context, address common.Address, blockNum *big.Int) (*big.Int, error) {
+	v, err := b.do("BalanceAt:"+address.Hex()+":"+blockKey(blockNum), func() (interface{}, error) {
+		return b.Backend.BalanceAt(ctx, address, blockNum)
+	})
+	balance, _ := v.(*big.Int)
+	return copyInt(balance), err
+}
+
+// HeaderByNumber returns the block header with the given number (the latest one, if number is nil).
+// The inner backend must implement HeaderByNumber method, otherwise ErrNoHeaders is returned.
+func (b *DedupBackend) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	v, err := b.do("HeaderByNumber:"+blockKey(number), func() (interface{}, error) {
+		return headerByNumber(ctx, b.Backend, number)
+	})
+	h, _ := v.(*types.Header)
+	if h == nil {
+		return nil, err
+	}
+	return types.CopyHeader(h), err
+}
+
+// ChainID returns chain ID of the inner backend, which must implement ChainID method, otherwise ErrNoChainID
+// is returned.
+func (b *DedupBackend) ChainID(ctx context.Context) (*big.Int, error) {
+	v, err := b.do("ChainID", func() (interface{}, error) {
+		return ChainID(ctx, b.Backend)
+	})
+	chainID, _ := v.(*big.Int)
+	return copyInt(chainID), err
+}
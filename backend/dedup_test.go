@@ -0,0 +1,58 @@
+package backend
+
+import (
+	"context"
+	"math/big"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+type slowGasPriceMock struct {
+	Backend
+	calls   int32
+	release chan struct{}
+}
+
+func (m *slowGasPriceMock) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	atomic.AddInt32(&m.calls, 1)
+	<-m.release
+	return big.NewInt(42), nil
+}
+
+func TestDedupBackend_SuggestGasPrice(t *testing.T) {
+	m := &slowGasPriceMock{release: make(chan struct{})}
+	b := NewDedupBackend(m).(*DedupBackend)
+
+	const callers = 10
+	prices := make([]*big.Int, callers)
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			prices[i], _ = b.SuggestGasPrice(context.Background())
+		}(i)
+	}
+
+	// wait until all other callers join the first call
+	for b.Deduplicated() != callers-1 {
+		runtime.Gosched()
+	}
+	close(m.release)
+	wg.Wait()
+
+	if calls := atomic.LoadInt32(&m.calls); calls != 1 {
+		t.Fatalf("expected 1 call, but got %v", calls)
+	}
+	for i, p := range prices {
+		if p == nil || p.Int64() != 42 {
+			t.Errorf("caller %v: expected gas price 42, but got %v", i, p)
+		}
+	}
+	prices[0].SetInt64(0)
+	if prices[1].Int64() != 42 {
+		t.Errorf("expected callers to get copies of the result")
+	}
+}
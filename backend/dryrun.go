@@ -0,0 +1,120 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/monetha/go-ethereum/log"
+)
+
+// DryRunBackend doesn't send transactions, instead it simulates them against the pending state of the inner backend
+// and logs the would-be transactions (hash, gas, cost). Receipts of simulated transactions are synthesized,
+// so that code waiting for transactions works unchanged. All other calls are passed to the inner backend.
+type DryRunBackend struct {
+	Backend
+	lf       log.Fun
+	mu       sync.RWMutex
+	receipts map[common.Hash]*types.Receipt
+	txs      map[common.Hash]*types.Transaction
+}
+
+// NewDryRunBackend wraps backend and returns new instance of DryRunBackend.
+func NewDryRunBackend(inner Backend, lf log.Fun) *DryRunBackend {
+	return &DryRunBackend{
+		Backend:  inner,
+		lf:       lf,
+		receipts: make(map[common.Hash]*types.Receipt),
+		txs:      make(map[common.Hash]*types.Transaction),
+	}
+}
+
+// SendTransaction simulates the transaction and logs it instead of sending. It returns error if the transaction
+// would fail.
+func (b *DryRunBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	from, err := types.Sender(types.NewEIP155Signer(tx.ChainId()), tx)
+	if err != nil {
+		return fmt.Errorf("dry run: invalid sender: %v", err)
+	}
+
+	msg := ethereum.CallMsg{From: from, To: tx.To(), Gas: tx.Gas(), GasPrice: tx.GasPrice(), Value: tx.Value(), Data: tx.Data()}
+	gasUsed, err := b.Backend.EstimateGas(ctx, msg)
+	if err != nil {
+		return fmt.Errorf("dry run: transaction would fail: %v", err)
+	}
+
+	cost := new(big.Int).Mul(new(big.Int).SetUint64(tx.Gas()), tx.GasPrice())
+	cost.Add(cost, tx.Value())
+
+	to := "contract creation"
+	if tx.To() != nil {
+		to = tx.To().Hex()
+	}
+	b.log("Dry run: transaction not sent", "hash", tx.Hash().Hex(), "from", from.Hex(), "to", to, "nonce", tx.Nonce(),
+		"gas_limit", tx.Gas(), "estimated_gas", gasUsed, "gas_price", tx.GasPrice(), "max_cost", cost)
+
+	r := &types.Receipt{
+		Status:            types.ReceiptStatusSuccessful,
+		CumulativeGasUsed: gasUsed,
+		GasUsed:           gasUsed,
+		TxHash:            tx.Hash(),
+		Logs:              []*types.Log{},
+	}
+	if tx.To() == nil {
+		r.ContractAddress = crypto.CreateAddress(from, tx.Nonce())
+	}
+
+	b.mu.Lock()
+	b.receipts[tx.Hash()] = r
+	b.txs[tx.Hash()] = tx
+	b.mu.Unlock()
+
+	return nil
+}
+
+// TransactionReceipt returns the synthesized receipt of simulated transaction, or the receipt from the inner backend.
+func (b *DryRunBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	b.mu.RLock()
+	r, ok := b.receipts[txHash]
+	b.mu.RUnlock()
+	if ok {
+		return r, nil
+	}
+
+	return b.Backend.TransactionReceipt(ctx, txHash)
+}
+
+// TransactionByHash returns simulated transaction (as mined), or the transaction from the inner backend.
+func (b *DryRunBackend) TransactionByHash(ctx context.Context, txHash common.Hash) (tx *types.Transaction, isPending bool, err error) {
+	b.mu.RLock()
+	tx, ok := b.txs[txHash]
+	b.mu.RUnlock()
+	if ok {
+		return tx, false, nil
+	}
+
+	return b.Backend.TransactionByHash(ctx, txHash)
+}
+
+// ChainID returns chain ID of the inner backend, which must implement ChainID method, otherwise ErrNoChainID
+// is returned.
+func (b *DryRunBackend) ChainID(ctx context.Context) (*big.Int, error) {
+	return ChainID(ctx, b.Backend)
+}
+
+// HeaderByNumber returns the block header with the given number (the latest one, if number is nil).
+// The inner backend must implement HeaderByNumber method, otherwise ErrNoHeaders is returned.
+func (b *DryRunBackend) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return headerByNumber(ctx, b.Backend, number)
+}
+
+func (b *DryRunBackend) log(msg string, ctx ...interface{}) {
+	if b.lf != nil {
+		b.lf(msg, ctx...)
+	}
+}
@@ -0,0 +1,85 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestDryRunBackend_SendTransaction(t *testing.T) {
+	estimateErr := errors.New("execution reverted")
+
+	newTx := func(nonce uint64) *types.Transaction {
+		tx, err := types.SignTx(types.NewTransaction(nonce, nonHandledAddress, big.NewInt(1), 21000, big.NewInt(1), nil), types.HomesteadSigner{}, handledAddressKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tx
+	}
+
+	t.Run("simulates transaction and synthesizes receipt", func(t *testing.T) {
+		var logged []string
+		inner := &backendMock{
+			EstimateGasFunc: func(ctx context.Context, call ethereum.CallMsg) (uint64, error) {
+				if call.From != handledAddress {
+					t.Errorf("expected sender %v, but got %v", handledAddress.Hex(), call.From.Hex())
+				}
+				return 21000, nil
+			},
+			SendTransactionFunc: func(ctx context.Context, tx *types.Transaction) error {
+				t.Errorf("transaction must not be sent")
+				return nil
+			},
+		}
+		b := NewDryRunBackend(inner, func(msg string, ctx ...interface{}) { logged = append(logged, msg) })
+
+		tx := newTx(0)
+		if err := b.SendTransaction(context.TODO(), tx); err != nil {
+			t.Fatalf("SendTransaction: %v", err)
+		}
+		if len(logged) != 1 {
+			t.Errorf("expected transaction to be logged")
+		}
+
+		r, err := b.TransactionReceipt(context.TODO(), tx.Hash())
+		if err != nil {
+			t.Fatalf("TransactionReceipt: %v", err)
+		}
+		if r.Status != types.ReceiptStatusSuccessful || r.GasUsed != 21000 || r.TxHash != tx.Hash() {
+			t.Errorf("unexpected receipt: %+v", r)
+		}
+
+		rtx, isPending, err := b.TransactionByHash(context.TODO(), tx.Hash())
+		if err != nil {
+			t.Fatalf("TransactionByHash: %v", err)
+		}
+		if isPending || rtx.Hash() != tx.Hash() {
+			t.Errorf("expected mined transaction %v, but got %v (pending: %v)", tx.Hash().Hex(), rtx.Hash().Hex(), isPending)
+		}
+	})
+
+	t.Run("returns error for failing transaction", func(t *testing.T) {
+		inner := &backendMock{
+			EstimateGasFunc: func(ctx context.Context, call ethereum.CallMsg) (uint64, error) {
+				return 0, estimateErr
+			},
+			TransactionReceiptFunc: func(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+				return nil, ethereum.NotFound
+			},
+		}
+		b := NewDryRunBackend(inner, nil)
+
+		tx := newTx(1)
+		if err := b.SendTransaction(context.TODO(), tx); err == nil {
+			t.Errorf("expected error")
+		}
+		if _, err := b.TransactionReceipt(context.TODO(), tx.Hash()); err != ethereum.NotFound {
+			t.Errorf("expected no receipt, but got error %v", err)
+		}
+	})
+}
@@ -0,0 +1,55 @@
+package backend
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// GasPriceBackend returns gas price from the given function instead of the inner backend, so that contract
+// bindings and transfers with nil gas price use it at the time of sending the transaction.
+// All other calls are passed to the inner backend.
+type GasPriceBackend struct {
+	Backend
+	gasPrice func(ctx context.Context) (*big.Int, error)
+}
+
+// NewGasPriceBackend wraps backend and returns new instance of GasPriceBackend.
+func NewGasPriceBackend(inner Backend, gasPrice func() *big.Int) Backend {
+	return NewGasPriceContextBackend(inner, func(context.Context) (*big.Int, error) {
+		return gasPrice(), nil
+	})
+}
+
+// NewGasPriceContextBackend works like NewGasPriceBackend, but the gas price function may fail or
+// make remote calls (e.g. gas strategy based on fee history).
+func NewGasPriceContextBackend(inner Backend, gasPrice func(ctx context.Context) (*big.Int, error)) Backend {
+	b := &GasPriceBackend{Backend: inner, gasPrice: gasPrice}
+
+	if cr, ok := inner.(commiterRollbacker); ok {
+		return &simBackend{
+			b:  b,
+			cr: cr,
+		}
+	}
+
+	return b
+}
+
+// SuggestGasPrice returns the gas price of the gas price function.
+func (b *GasPriceBackend) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return b.gasPrice(ctx)
+}
+
+// ChainID returns chain ID of the inner backend, which must implement ChainID method, otherwise ErrNoChainID
+// is returned.
+func (b *GasPriceBackend) ChainID(ctx context.Context) (*big.Int, error) {
+	return ChainID(ctx, b.Backend)
+}
+
+// HeaderByNumber returns the block header with the given number (the latest one, if number is nil).
+// The inner backend must implement HeaderByNumber method, otherwise ErrNoHeaders is returned.
+func (b *GasPriceBackend) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return headerByNumber(ctx, b.Backend, number)
+}
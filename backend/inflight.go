@@ -0,0 +1,140 @@
+package backend
+
+import (
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/monetha/go-ethereum/metrics"
+)
+
+// InFlightTx is the transaction sent by HandleNonceBackend which isn't known to be mined yet. Transactions
+// replacing each other with the same nonce are tracked as one in-flight transaction.
+type InFlightTx struct {
+	Nonce    uint64
+	Hash     common.Hash // of the last attempt
+	GasPrice *big.Int    // of the last attempt
+	SentAt   time.Time   // of the first attempt
+	Attempts int
+
+	hashes []common.Hash // of all attempts
+}
+
+// Age returns the duration since the first attempt was sent.
+func (tx InFlightTx) Age() time.Duration {
+	return time.Since(tx.SentAt)
+}
+
+// SetMetrics sets the collector which receives the number of in-flight transactions of every handled address.
+func (b *HandleNonceBackend) SetMetrics(c metrics.Collector) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.metrics = c
+}
+
+// InFlight returns in-flight transactions of handled addresses ordered by nonce. Transaction stops being in-flight
+// when its receipt (or receipt of a transaction with the same or higher nonce) is retrieved with
+// TransactionReceipt. Unlike other methods, it's safe to call it from another goroutine.
+func (b *HandleNonceBackend) InFlight() map[common.Address][]InFlightTx {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	res := make(map[common.Address][]InFlightTx, len(b.inFlight))
+	for address, txs := range b.inFlight {
+		copied := make([]InFlightTx, len(txs))
+		for i, tx := range txs {
+			copied[i] = *tx
+			copied[i].GasPrice = new(big.Int).Set(tx.GasPrice)
+			copied[i].hashes = nil
+		}
+		res[address] = copied
+	}
+	return res
+}
+
+// QueueDepth returns the number of in-flight transactions of all handled addresses. It's safe to call it from
+// another goroutine.
+func (b *HandleNonceBackend) QueueDepth() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	depth := 0
+	for _, txs := range b.inFlight {
+		depth += len(txs)
+	}
+	return depth
+}
+
+// addInFlight tracks the sent transaction of the handled address.
+func (b *HandleNonceBackend) addInFlight(from common.Address, tx *types.Transaction) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.inFlight == nil {
+		b.inFlight = make(map[common.Address][]*InFlightTx)
+	}
+
+	txs := b.inFlight[from]
+	for _, ift := range txs {
+		if ift.Nonce == tx.Nonce() {
+			// replacement
+			ift.Hash = tx.Hash()
+			ift.GasPrice = tx.GasPrice()
+			ift.Attempts++
+			ift.hashes = append(ift.hashes, tx.Hash())
+			return
+		}
+	}
+
+	txs = append(txs, &InFlightTx{
+		Nonce:    tx.Nonce(),
+		Hash:     tx.Hash(),
+		GasPrice: tx.GasPrice(),
+		SentAt:   time.Now(),
+		Attempts: 1,
+		hashes:   []common.Hash{tx.Hash()},
+	})
+	sort.Slice(txs, func(i, j int) bool { return txs[i].Nonce < txs[j].Nonce })
+	b.inFlight[from] = txs
+	b.reportInFlight(from)
+}
+
+// removeMined stops tracking the mined transaction and transactions of the same address with lower nonces.
+func (b *HandleNonceBackend) removeMined(txHash common.Hash) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for address, txs := range b.inFlight {
+		for i, ift := range txs {
+			if !containsHash(ift.hashes, txHash) {
+				continue
+			}
+
+			txs = txs[i+1:]
+			if len(txs) == 0 {
+				delete(b.inFlight, address)
+			} else {
+				b.inFlight[address] = txs
+			}
+			b.reportInFlight(address)
+			return
+		}
+	}
+}
+
+func (b *HandleNonceBackend) reportInFlight(address common.Address) {
+	if b.metrics != nil {
+		b.metrics.InFlightTxs(address.Hex(), len(b.inFlight[address]))
+	}
+}
+
+func containsHash(hashes []common.Hash, hash common.Hash) bool {
+	for _, h := range hashes {
+		if h == hash {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,406 @@
+package backend
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/monetha/go-ethereum/health"
+)
+
+// Endpoint is the backend of one RPC provider used by MultiBackend.
+type Endpoint struct {
+	Name    string
+	Backend Backend
+}
+
+// MultiConfig contains parameters of MultiBackend.
+type MultiConfig struct {
+	// Alpha is the weight of the latest response time in the moving average of response times (0.2 by default).
+	Alpha float64
+	// Hysteresis is the fraction by which score of other endpoint must be better than the score of the current
+	// endpoint to switch to it, which avoids flapping (0.2 by default).
+	Hysteresis float64
+	// LagPenalty is added to the score for every block the endpoint is behind the highest known head
+	// (1 second by default).
+	LagPenalty time.Duration
+	// ErrorPenalty is added to the score for every consecutive error of the endpoint (5 seconds by default).
+	ErrorPenalty time.Duration
+}
+
+// MultiBackend routes every read to the best of several endpoints, scored by moving average of response times,
+// consecutive errors and lag behind the highest head seen across endpoints. Failed reads are retried on the other
+// endpoints. Transactions are sent to the best endpoint only. It's safe for concurrent use.
+type MultiBackend struct {
+	cfg       MultiConfig
+	mu        sync.RWMutex
+	endpoints []*endpointState
+	current   int
+}
+
+type endpointState struct {
+	Endpoint
+	latency     float64 // moving average of response times in nanoseconds
+	errorStreak int
+	head        uint64
+	headKnown   bool // endpoints which can't read headers aren't scored by lag
+	lag         uint64
+	lastSuccess time.Time
+}
+
+// EndpointStatus holds the score parameters of the endpoint.
+type EndpointStatus struct {
+	Name        string
+	Latency     time.Duration
+	ErrorStreak int
+	Lag         uint64
+	Current     bool
+}
+
+// NewMultiBackend creates an instance of MultiBackend. The first endpoint is used until other endpoints are scored.
+func NewMultiBackend(endpoints []Endpoint, cfg *MultiConfig) *MultiBackend {
+	if len(endpoints) == 0 {
+		panic("at least one endpoint is required")
+	}
+
+	var c MultiConfig
+	if cfg != nil {
+		c = *cfg
+	}
+	if c.Alpha <= 0 || c.Alpha > 1 {
+		c.Alpha = 0.2
+	}
+	if c.Hysteresis <= 0 {
+		c.Hysteresis = 0.2
+	}
+	if c.LagPenalty <= 0 {
+		c.LagPenalty = time.Second
+	}
+	if c.ErrorPenalty <= 0 {
+		c.ErrorPenalty = 5 * time.Second
+	}
+
+	b := &MultiBackend{cfg: c}
+	for _, e := range endpoints {
+		b.endpoints = append(b.endpoints, &endpointState{Endpoint: e})
+	}
+	return b
+}
+
+// Status returns the score parameters of the endpoints.
+func (b *MultiBackend) Status() []EndpointStatus {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	res := make([]EndpointStatus, len(b.endpoints))
+	for i, e := range b.endpoints {
+		res[i] = EndpointStatus{
+			Name:        e.Name,
+			Latency:     time.Duration(e.latency),
+			ErrorStreak: e.errorStreak,
+			Lag:         e.lag,
+			Current:     i == b.current,
+		}
+	}
+	return res
+}
+
+// UpdateHeads requests the latest header of every endpoint (which must implement HeaderByNumber method,
+// like ethclient.Client) and updates lags behind the highest head. Endpoints which can't read headers have
+// no lag, so they are scored by response time and errors only.
+func (b *MultiBackend) UpdateHeads(ctx context.Context) {
+	heads := make([]uint64, len(b.endpoints))
+	ok := make([]bool, len(b.endpoints))
+
+	var wg sync.WaitGroup
+	for i, e := range b.endpoints {
+		hr, isReader := e.Backend.(headerReader)
+		if !isReader {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, hr headerReader) {
+			defer wg.Done()
+			start := time.Now()
+			h, err := hr.HeaderByNumber(ctx, nil)
+			if ctx.Err() != nil || err == ErrNoHeaders {
+				return
+			}
+			b.record(i, time.Since(start), err)
+			if err == nil && h != nil && h.Number != nil {
+				heads[i], ok[i] = h.Number.Uint64(), true
+			}
+		}(i, hr)
+	}
+	wg.Wait()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var highest uint64
+	for i, e := range b.endpoints {
+		if ok[i] {
+			e.head, e.headKnown = heads[i], true
+		}
+		if e.headKnown && e.head > highest {
+			highest = e.head
+		}
+	}
+	for _, e := range b.endpoints {
+		e.lag = 0
+		if e.headKnown {
+			e.lag = highest - e.head
+		}
+	}
+	b.selectBest()
+}
+
+// Run updates heads of the endpoints with the given interval until the context is done.
+func (b *MultiBackend) Run(ctx context.Context, interval time.Duration) {
+	for {
+		b.UpdateHeads(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+func (b *MultiBackend) score(e *endpointState) float64 {
+	return e.latency +
+		float64(e.errorStreak)*float64(b.cfg.ErrorPenalty) +
+		float64(e.lag)*float64(b.cfg.LagPenalty)
+}
+
+// selectBest switches to the best endpoint if it's better than the current one by more than hysteresis.
+// Endpoints without measured response time aren't selected. It must be called with the lock held.
+func (b *MultiBackend) selectBest() {
+	best := b.current
+	for i, e := range b.endpoints {
+		if e.latency == 0 {
+			continue
+		}
+		if b.score(e) < b.score(b.endpoints[best]) {
+			best = i
+		}
+	}
+	if best != b.current && b.score(b.endpoints[best]) < b.score(b.endpoints[b.current])*(1-b.cfg.Hysteresis) {
+		b.current = best
+	}
+}
+
+// record updates the score of the endpoint with the result of the call. NotFound and errors of methods which
+// the endpoint doesn't support (ErrNoHeaders, ErrNoChainID) are valid responses, not failures of the endpoint.
+func (b *MultiBackend) record(i int, d time.Duration, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	e := b.endpoints[i]
+	if err != nil && err != ethereum.NotFound && err != ErrNoHeaders && err != ErrNoChainID {
+		e.errorStreak++
+	} else {
+		e.errorStreak = 0
+		e.lastSuccess = time.Now()
+		if e.latency == 0 {
+			e.latency = float64(d)
+		} else {
+			e.latency = b.cfg.Alpha*float64(d) + (1-b.cfg.Alpha)*e.latency
+		}
+	}
+	b.selectBest()
+}
+
+// Healthy implements health.Healthier interface. MultiBackend is unhealthy when the last call of every endpoint
+// failed.
+func (b *MultiBackend) Healthy(ctx context.Context) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	var lastSuccess time.Time
+	for _, e := range b.endpoints {
+		if e.errorStreak == 0 {
+			return nil
+		}
+		if e.lastSuccess.After(lastSuccess) {
+			lastSuccess = e.lastSuccess
+		}
+	}
+	return &health.UnhealthyError{Component: "multibackend", Reason: "all endpoints are failing", Status: health.Status{LastSuccess: lastSuccess}}
+}
+
+// order returns indexes of endpoints to try: the current one first, then the others.
+func (b *MultiBackend) order() []int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	res := make([]int, 0, len(b.endpoints))
+	res = append(res, b.current)
+	for i := range b.endpoints {
+		if i != b.current {
+			res = append(res, i)
+		}
+	}
+	return res
+}
+
+// read calls fn with the best endpoint, retrying on the others if it fails.
+func (b *MultiBackend) read(ctx context.Context, fn func(Backend) error) error {
+	return b.readAt(ctx, nil, fn)
+}
+
+// readAt works like read, but NotFound is retried on the other endpoints when the block number is specified
+// (e.g. reads of PinnedBackend), since the endpoint may lag behind the block.
+func (b *MultiBackend) readAt(ctx context.Context, blockNumber *big.Int, fn func(Backend) error) (err error) {
+	retryNotFound := blockNumber != nil && blockNumber.Sign() >= 0
+	for _, i := range b.order() {
+		start := time.Now()
+		err = fn(b.endpoints[i].Backend)
+		if ctx.Err() != nil {
+			return // not the endpoint's fault
+		}
+		b.record(i, time.Since(start), err)
+		if err == nil || (err == ethereum.NotFound && !retryNotFound) {
+			return
+		}
+	}
+	return
+}
+
+// CodeAt returns the code of the given account.
+func (b *MultiBackend) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) (code []byte, err error) {
+	err = b.readAt(ctx, blockNumber, func(be Backend) (err error) {
+		code, err = be.CodeAt(ctx, contract, blockNumber)
+		return
+	})
+	return
+}
+
+// CallContract executes an Ethereum contract call with the specified data as the input.
+func (b *MultiBackend) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) (res []byte, err error) {
+	err = b.readAt(ctx, blockNumber, func(be Backend) (err error) {
+		res, err = be.CallContract(ctx, call, blockNumber)
+		return
+	})
+	return
+}
+
+// PendingCodeAt returns the code of the given account in the pending state.
+func (b *MultiBackend) PendingCodeAt(ctx context.Context, account common.Address) (code []byte, err error) {
+	err = b.read(ctx, func(be Backend) (err error) {
+		code, err = be.PendingCodeAt(ctx, account)
+		return
+	})
+	return
+}
+
+// PendingNonceAt retrieves the current pending nonce associated with an account.
+func (b *MultiBackend) PendingNonceAt(ctx context.Context, account common.Address) (nonce uint64, err error) {
+	err = b.read(ctx, func(be Backend) (err error) {
+		nonce, err = be.PendingNonceAt(ctx, account)
+		return
+	})
+	return
+}
+
+// SuggestGasPrice retrieves the currently suggested gas price.
+func (b *MultiBackend) SuggestGasPrice(ctx context.Context) (gasPrice *big.Int, err error) {
+	err = b.read(ctx, func(be Backend) (err error) {
+		gasPrice, err = be.SuggestGasPrice(ctx)
+		return
+	})
+	return
+}
+
+// EstimateGas tries to estimate the gas needed to execute a specific transaction.
+func (b *MultiBackend) EstimateGas(ctx context.Context, call ethereum.CallMsg) (gas uint64, err error) {
+	err = b.read(ctx, func(be Backend) (err error) {
+		gas, err = be.EstimateGas(ctx, call)
+		return
+	})
+	return
+}
+
+// SendTransaction sends the transaction to the best endpoint. It isn't retried on other endpoints, because
+// the transaction may have reached the network despite the error. The transaction which is already known to
+// the endpoint is considered to be sent (see IsAlreadyKnown).
+func (b *MultiBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	i := b.order()[0]
+	start := time.Now()
+	err := b.endpoints[i].Backend.SendTransaction(ctx, tx)
+	if IsAlreadyKnown(err) {
+		err = nil
+	}
+	if ctx.Err() == nil {
+		b.record(i, time.Since(start), err)
+	}
+	return err
+}
+
+// FilterLogs executes a log filter operation.
+func (b *MultiBackend) FilterLogs(ctx context.Context, query ethereum.FilterQuery) (logs []types.Log, err error) {
+	err = b.read(ctx, func(be Backend) (err error) {
+		logs, err = be.FilterLogs(ctx, query)
+		return
+	})
+	return
+}
+
+// SubscribeFilterLogs creates a background log filtering operation on the best endpoint.
+func (b *MultiBackend) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (sub ethereum.Subscription, err error) {
+	err = b.read(ctx, func(be Backend) (err error) {
+		sub, err = be.SubscribeFilterLogs(ctx, query, ch)
+		return
+	})
+	return
+}
+
+// TransactionByHash returns the transaction with the given hash.
+func (b *MultiBackend) TransactionByHash(ctx context.Context, txHash common.Hash) (tx *types.Transaction, isPending bool, err error) {
+	err = b.read(ctx, func(be Backend) (err error) {
+		tx, isPending, err = be.TransactionByHash(ctx, txHash)
+		return
+	})
+	return
+}
+
+// TransactionReceipt returns the receipt of a transaction by transaction hash.
+func (b *MultiBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (r *types.Receipt, err error) {
+	err = b.read(ctx, func(be Backend) (err error) {
+		r, err = be.TransactionReceipt(ctx, txHash)
+		return
+	})
+	return
+}
+
+// HeaderByNumber returns the block header with the given number (the latest one, if number is nil)
+// from the endpoints implementing HeaderByNumber method.
+func (b *MultiBackend) HeaderByNumber(ctx context.Context, number *big.Int) (h *types.Header, err error) {
+	err = b.readAt(ctx, number, func(be Backend) (err error) {
+		h, err = headerByNumber(ctx, be, number)
+		return
+	})
+	return
+}
+
+// ChainID returns chain ID from the endpoints implementing ChainID method.
+func (b *MultiBackend) ChainID(ctx context.Context) (chainID *big.Int, err error) {
+	err = b.read(ctx, func(be Backend) (err error) {
+		chainID, err = ChainID(ctx, be)
+		return
+	})
+	return
+}
+
+// BalanceAt returns the balance of the account of given address.
+func (b *MultiBackend) BalanceAt(ctx context.Context, address common.Address, blockNum *big.Int) (balance *big.Int, err error) {
+	err = b.readAt(ctx, blockNum, func(be Backend) (err error) {
+		balance, err = be.BalanceAt(ctx, address, blockNum)
+		return
+	})
+	return
+}
@@ -0,0 +1,157 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/monetha/go-ethereum/health"
+)
+
+type endpointMock struct {
+	Backend
+	balance *big.Int
+	err     error
+	head    int64
+	calls   int
+}
+
+func (m *endpointMock) BalanceAt(ctx context.Context, address common.Address, blockNum *big.Int) (*big.Int, error) {
+	m.calls++
+	return m.balance, m.err
+}
+
+func (m *endpointMock) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return &types.Header{Number: big.NewInt(m.head)}, nil
+}
+
+func TestMultiBackend_Failover(t *testing.T) {
+	e1 := &endpointMock{err: errors.New("unavailable")}
+	e2 := &endpointMock{balance: big.NewInt(42)}
+	b := NewMultiBackend([]Endpoint{{"e1", e1}, {"e2", e2}}, nil)
+
+	balance, err := b.BalanceAt(context.Background(), common.Address{}, nil)
+	if err != nil {
+		t.Fatalf("BalanceAt: %v", err)
+	}
+	if balance.Int64() != 42 {
+		t.Errorf("expected balance 42, but got %v", balance)
+	}
+
+	st := b.Status()
+	if st[0].ErrorStreak != 1 || !st[1].Current {
+		t.Errorf("expected failed endpoint to be penalized and second endpoint to be current, but got %+v", st)
+	}
+
+	if _, err := b.BalanceAt(context.Background(), common.Address{}, nil); err != nil {
+		t.Fatalf("BalanceAt: %v", err)
+	}
+	if e1.calls != 1 || e2.calls != 2 {
+		t.Errorf("expected reads to go to the current endpoint, but got %v and %v calls", e1.calls, e2.calls)
+	}
+}
+
+func TestMultiBackend_Hysteresis(t *testing.T) {
+	b := NewMultiBackend([]Endpoint{{"e1", &endpointMock{}}, {"e2", &endpointMock{}}}, &MultiConfig{Alpha: 1, Hysteresis: 0.2})
+
+	b.record(0, 100*time.Millisecond, nil)
+	b.record(1, 90*time.Millisecond, nil)
+	if b.Status()[1].Current {
+		t.Errorf("expected no switch when score is better by less than hysteresis")
+	}
+
+	b.record(1, 70*time.Millisecond, nil)
+	if !b.Status()[1].Current {
+		t.Errorf("expected switch when score is better by more than hysteresis")
+	}
+}
+
+func TestMultiBackend_UpdateHeads(t *testing.T) {
+	e1 := &endpointMock{head: 90}
+	e2 := &endpointMock{head: 100}
+	b := NewMultiBackend([]Endpoint{{"e1", e1}, {"e2", e2}}, &MultiConfig{LagPenalty: time.Second})
+
+	b.UpdateHeads(context.Background())
+
+	st := b.Status()
+	if st[0].Lag != 10 || st[1].Lag != 0 {
+		t.Errorf("expected lags 10 and 0, but got %v and %v", st[0].Lag, st[1].Lag)
+	}
+	if !st[1].Current {
+		t.Errorf("expected endpoint at the highest head to be current")
+	}
+}
+
+// noHeadersMock is the endpoint which can't read headers.
+type noHeadersMock struct {
+	Backend
+}
+
+func TestMultiBackend_UpdateHeads_NoHeaders(t *testing.T) {
+	e1 := &endpointMock{head: 100}
+	e2 := &noHeadersMock{}
+	e3 := NewTracingBackend(&noHeadersMock{}, &recordingTracer{})
+	b := NewMultiBackend([]Endpoint{{"e1", e1}, {"e2", e2}, {"e3", e3}}, &MultiConfig{LagPenalty: time.Second})
+
+	b.UpdateHeads(context.Background())
+
+	for _, st := range b.Status()[1:] {
+		if st.Lag != 0 || st.ErrorStreak != 0 {
+			t.Errorf("%v: expected endpoint without headers not to be penalized, but got %+v", st.Name, st)
+		}
+	}
+}
+
+// pendingTxMock is the endpoint which doesn't have the receipt of the pending transaction.
+type pendingTxMock struct {
+	Backend
+}
+
+func (m *pendingTxMock) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	return nil, ethereum.NotFound
+}
+
+func TestMultiBackend_TransactionReceipt_NotFound(t *testing.T) {
+	b := NewMultiBackend([]Endpoint{{"e1", &pendingTxMock{}}, {"e2", &pendingTxMock{}}}, nil)
+
+	for i := 0; i < 3; i++ {
+		if _, err := b.TransactionReceipt(context.Background(), common.Hash{}); err != ethereum.NotFound {
+			t.Fatalf("expected NotFound, but got %v", err)
+		}
+	}
+	for _, st := range b.Status() {
+		if st.ErrorStreak != 0 {
+			t.Errorf("expected NotFound not to be counted as error, but got %+v", st)
+		}
+	}
+}
+
+func TestMultiBackend_Healthy(t *testing.T) {
+	e1 := &endpointMock{err: errors.New("unavailable")}
+	e2 := &endpointMock{err: errors.New("unavailable")}
+	b := NewMultiBackend([]Endpoint{{"e1", e1}, {"e2", e2}}, nil)
+
+	if err := b.Healthy(context.Background()); err != nil {
+		t.Errorf("expected endpoints to be healthy before calls, but got %v", err)
+	}
+
+	if _, err := b.BalanceAt(context.Background(), common.Address{}, nil); err == nil {
+		t.Fatalf("expected error")
+	}
+	if _, ok := b.Healthy(context.Background()).(*health.UnhealthyError); !ok {
+		t.Errorf("expected *health.UnhealthyError when all endpoints fail")
+	}
+
+	e2.err = nil
+	if _, err := b.BalanceAt(context.Background(), common.Address{}, nil); err != nil {
+		t.Fatalf("BalanceAt: %v", err)
+	}
+	if err := b.Healthy(context.Background()); err != nil {
+		t.Errorf("expected backend to be healthy when an endpoint recovered, but got %v", err)
+	}
+}
@@ -0,0 +1,95 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/monetha/go-ethereum/blocktag"
+)
+
+// PinnedBackend issues all reads of the latest state (nil block number or ethereum.LatestBlockNumber) at the pinned
+// block number, so that several calls
+// (e.g. reading balances and contract state) see the same state even when the inner backend balances
+// the reads across endpoints at different heights (like MultiBackend).
+// Calls of the pending state and sending of transactions are passed to the inner backend.
+type PinnedBackend struct {
+	Backend
+	blockNumber *big.Int
+}
+
+// NewPinnedBackend wraps backend and returns new instance of PinnedBackend pinned to the given block number.
+func NewPinnedBackend(inner Backend, blockNumber *big.Int) *PinnedBackend {
+	return &PinnedBackend{Backend: inner, blockNumber: new(big.Int).Set(blockNumber)}
+}
+
+// PinLatest resolves the latest block number of the backend once (it must implement HeaderByNumber method,
+// like ethclient.Client or MultiBackend) and returns PinnedBackend pinned to it.
+func PinLatest(ctx context.Context, inner Backend) (*PinnedBackend, error) {
+	hr, ok := inner.(headerReader)
+	if !ok {
+		return nil, ErrNoHeaders
+	}
+
+	h, err := hr.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest header: %v", err)
+	}
+
+	return NewPinnedBackend(inner, h.Number), nil
+}
+
+// BlockNumber returns the pinned block number.
+func (b *PinnedBackend) BlockNumber() *big.Int {
+	return new(big.Int).Set(b.blockNumber)
+}
+
+// pin returns the pinned block number instead of the latest block (nil or ethereum.LatestBlockNumber).
+func (b *PinnedBackend) pin(blockNumber *big.Int) *big.Int {
+	if blockNumber == nil || blocktag.Is(blockNumber, blocktag.Latest) {
+		return b.blockNumber
+	}
+	return blockNumber
+}
+
+// CodeAt returns the code of the given account at the pinned block, if block number isn't specified.
+func (b *PinnedBackend) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	return b.Backend.CodeAt(ctx, contract, b.pin(blockNumber))
+}
+
+// CallContract executes an Ethereum contract call at the pinned block, if block number isn't specified.
+func (b *PinnedBackend) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	return b.Backend.CallContract(ctx, call, b.pin(blockNumber))
+}
+
+// BalanceAt returns the balance of the account at the pinned block, if block number isn't specified.
+func (b *PinnedBackend) BalanceAt(ctx context.Context, address common.Address, blockNum *big.Int) (*big.Int, error) {
+	return b.Backend.BalanceAt(ctx, address, b.pin(blockNum))
+}
+
+// FilterLogs executes a log filter operation up to the pinned block, if the last block of the query isn't specified.
+func (b *PinnedBackend) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	if query.BlockHash == nil && (query.ToBlock == nil || blocktag.Is(query.ToBlock, blocktag.Latest)) {
+		query.ToBlock = b.BlockNumber()
+	}
+	return b.Backend.FilterLogs(ctx, query)
+}
+
+// ChainID returns chain ID of the inner backend, which must implement ChainID method, otherwise ErrNoChainID
+// is returned.
+func (b *PinnedBackend) ChainID(ctx context.Context) (*big.Int, error) {
+	return ChainID(ctx, b.Backend)
+}
+
+// HeaderByNumber returns the header of the pinned block, if block number isn't specified.
+// The inner backend must implement HeaderByNumber method, otherwise ErrNoHeaders is returned.
+func (b *PinnedBackend) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return headerByNumber(ctx, b.Backend, b.pin(number))
+}
+
+type headerReader interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
@@ -0,0 +1,132 @@
+package backend
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+type blockNumberMock struct {
+	Backend
+	head      int64
+	blockNums []*big.Int
+	queryTo   *big.Int
+}
+
+func (m *blockNumberMock) BalanceAt(ctx context.Context, address common.Address, blockNum *big.Int) (*big.Int, error) {
+	m.blockNums = append(m.blockNums, blockNum)
+	return new(big.Int), nil
+}
+
+func (m *blockNumberMock) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	m.queryTo = query.ToBlock
+	return nil, nil
+}
+
+func (m *blockNumberMock) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	if number == nil {
+		number = big.NewInt(m.head)
+	}
+	return &types.Header{Number: number}, nil
+}
+
+func TestPinLatest(t *testing.T) {
+	ctx := context.Background()
+	m := &blockNumberMock{head: 100}
+
+	b, err := PinLatest(ctx, m)
+	if err != nil {
+		t.Fatalf("PinLatest: %v", err)
+	}
+	if b.BlockNumber().Int64() != 100 {
+		t.Errorf("expected pinned block 100, but got %v", b.BlockNumber())
+	}
+
+	m.head = 101
+
+	if _, err := b.BalanceAt(ctx, common.Address{}, nil); err != nil {
+		t.Fatalf("BalanceAt: %v", err)
+	}
+	if _, err := b.BalanceAt(ctx, common.Address{}, big.NewInt(50)); err != nil {
+		t.Fatalf("BalanceAt: %v", err)
+	}
+	if _, err := b.BalanceAt(ctx, common.Address{}, big.NewInt(-2)); err != nil {
+		t.Fatalf("BalanceAt: %v", err)
+	}
+	if m.blockNums[0].Int64() != 100 || m.blockNums[1].Int64() != 50 || m.blockNums[2].Int64() != 100 {
+		t.Errorf("expected reads at blocks 100, 50 and 100, but got %v", m.blockNums)
+	}
+
+	if _, err := b.FilterLogs(ctx, ethereum.FilterQuery{}); err != nil {
+		t.Fatalf("FilterLogs: %v", err)
+	}
+	if m.queryTo == nil || m.queryTo.Int64() != 100 {
+		t.Errorf("expected logs to be filtered up to block 100, but got %v", m.queryTo)
+	}
+
+	h, err := b.HeaderByNumber(ctx, nil)
+	if err != nil {
+		t.Fatalf("HeaderByNumber: %v", err)
+	}
+	if h.Number.Int64() != 100 {
+		t.Errorf("expected header of block 100, but got %v", h.Number)
+	}
+}
+
+func TestPinLatest_MultiBackend(t *testing.T) {
+	ctx := context.Background()
+	e1 := &blockNumberMock{head: 100}
+	e2 := &blockNumberMock{head: 105}
+	mb := NewMultiBackend([]Endpoint{{"e1", e1}, {"e2", e2}}, nil)
+
+	b, err := PinLatest(ctx, mb)
+	if err != nil {
+		t.Fatalf("PinLatest: %v", err)
+	}
+
+	// make the second endpoint current
+	mb.mu.Lock()
+	mb.current = 1
+	mb.mu.Unlock()
+
+	if _, err := b.BalanceAt(ctx, common.Address{}, nil); err != nil {
+		t.Fatalf("BalanceAt: %v", err)
+	}
+	if len(e2.blockNums) != 1 || e2.blockNums[0].Int64() != 100 {
+		t.Errorf("expected read at pinned block 100 on the other endpoint, but got %v", e2.blockNums)
+	}
+}
+
+// laggingMock is the endpoint which hasn't reached the block yet.
+type laggingMock struct {
+	Backend
+	head int64
+}
+
+func (m *laggingMock) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	if number == nil {
+		number = big.NewInt(m.head)
+	}
+	if number.Int64() > m.head {
+		return nil, ethereum.NotFound
+	}
+	return &types.Header{Number: number}, nil
+}
+
+func TestPinnedBackend_MultiBackend_NotFound(t *testing.T) {
+	e1 := &laggingMock{head: 99}
+	e2 := &laggingMock{head: 100}
+	b := NewPinnedBackend(NewMultiBackend([]Endpoint{{"e1", e1}, {"e2", e2}}, nil), big.NewInt(100))
+
+	h, err := b.HeaderByNumber(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("HeaderByNumber: %v", err)
+	}
+	if h.Number.Int64() != 100 {
+		t.Errorf("expected header of block 100 from the endpoint which reached it, but got %v", h.Number)
+	}
+}
@@ -0,0 +1,163 @@
+package backend
+
+import (
+	"context"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// DefaultReceiptCacheSize is the number of receipts kept by ReceiptCacheBackend when size isn't specified.
+const DefaultReceiptCacheSize = 1024
+
+// ReceiptCacheBackend caches receipts of final transactions, so repeated TransactionReceipt calls (e.g. by
+// pollers and report generators) don't hit the node. Receipt is cached only when its block has at least `finality`
+// blocks on top of it, receipts of recent blocks are requested from the node every time, because they may be removed
+// by chain reorganization. Receipts don't contain block number, so the block number of the logs is used, or the
+// latest block number at the time the receipt was first seen, if there are no logs. The inner backend must
+// implement HeaderByNumber method unless finality is 0 or HeadReader is given. When the cache is full, the oldest
+// receipt is evicted.
+type ReceiptCacheBackend struct {
+	Backend
+	finality uint64
+	size     int
+	heads    HeadReader // nil if the latest header is requested from the inner backend
+
+	mu       sync.Mutex
+	receipts map[common.Hash]*types.Receipt
+	order    []common.Hash          // oldest first
+	seen     map[common.Hash]uint64 // block number of receipts which aren't final yet
+}
+
+// HeadReader returns the number of the latest block, it's implemented by headtracker.Tracker.
+type HeadReader interface {
+	BlockNumber(ctx context.Context) (*big.Int, error)
+}
+
+// NewReceiptCacheBackend wraps backend and returns new instance of ReceiptCacheBackend, which keeps up to
+// `size` receipts (DefaultReceiptCacheSize if size is 0).
+func NewReceiptCacheBackend(inner Backend, finality uint64, size int) Backend {
+	return NewHeadsReceiptCacheBackend(inner, finality, size, nil)
+}
+
+// NewHeadsReceiptCacheBackend works like NewReceiptCacheBackend, but the latest block number is taken from
+// the head reader (e.g. headtracker.Tracker shared by other components) instead of requesting the latest header.
+func NewHeadsReceiptCacheBackend(inner Backend, finality uint64, size int, heads HeadReader) Backend {
+	if size <= 0 {
+		size = DefaultReceiptCacheSize
+	}
+	b := &ReceiptCacheBackend{
+		Backend:  inner,
+		finality: finality,
+		size:     size,
+		heads:    heads,
+		receipts: make(map[common.Hash]*types.Receipt),
+		seen:     make(map[common.Hash]uint64),
+	}
+
+	if cr, ok := inner.(commiterRollbacker); ok {
+		return &simBackend{
+			b:  b,
+			cr: cr,
+		}
+	}
+
+	return b
+}
+
+// TransactionReceipt returns the receipt of a transaction by transaction hash, cached one if the transaction is final.
+func (b *ReceiptCacheBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	b.mu.Lock()
+	cached, ok := b.receipts[txHash]
+	b.mu.Unlock()
+	if ok {
+		r := *cached
+		return &r, nil
+	}
+
+	r, err := b.Backend.TransactionReceipt(ctx, txHash)
+	if err != nil || r == nil {
+		return r, err
+	}
+
+	if b.isFinal(ctx, txHash, r) {
+		cached := *r
+		b.add(txHash, &cached)
+	}
+	return r, nil
+}
+
+// ChainID returns chain ID of the inner backend, which must implement ChainID method, otherwise ErrNoChainID
+// is returned.
+func (b *ReceiptCacheBackend) ChainID(ctx context.Context) (*big.Int, error) {
+	return ChainID(ctx, b.Backend)
+}
+
+// HeaderByNumber returns the block header with the given number (the latest one, if number is nil).
+// The inner backend must implement HeaderByNumber method, otherwise ErrNoHeaders is returned.
+func (b *ReceiptCacheBackend) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return headerByNumber(ctx, b.Backend, number)
+}
+
+// isFinal tells whether the block of the receipt has at least `finality` blocks on top of it.
+func (b *ReceiptCacheBackend) isFinal(ctx context.Context, txHash common.Hash, r *types.Receipt) bool {
+	if b.finality == 0 {
+		return true
+	}
+
+	head, err := b.headNumber(ctx)
+	if err != nil {
+		return false
+	}
+	headNumber := head.Uint64()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	blockNumber, ok := b.seen[txHash]
+	if len(r.Logs) > 0 {
+		blockNumber, ok = r.Logs[0].BlockNumber, true
+	}
+	if !ok {
+		if len(b.seen) >= b.size {
+			b.seen = make(map[common.Hash]uint64) // receipts which are polled will be seen again
+		}
+		b.seen[txHash] = headNumber
+		return false
+	}
+
+	if headNumber < blockNumber+b.finality {
+		return false
+	}
+	delete(b.seen, txHash)
+	return true
+}
+
+// headNumber returns the number of the latest block.
+func (b *ReceiptCacheBackend) headNumber(ctx context.Context) (*big.Int, error) {
+	if b.heads != nil {
+		return b.heads.BlockNumber(ctx)
+	}
+	head, err := b.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return head.Number, nil
+}
+
+func (b *ReceiptCacheBackend) add(txHash common.Hash, r *types.Receipt) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.receipts[txHash]; ok {
+		return
+	}
+	if len(b.order) >= b.size {
+		delete(b.receipts, b.order[0])
+		b.order = b.order[1:]
+	}
+	b.receipts[txHash] = r
+	b.order = append(b.order, txHash)
+}
@@ -0,0 +1,122 @@
+package backend
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+type receiptsMock struct {
+	Backend
+	head     int64
+	receipts map[common.Hash]*types.Receipt
+	calls    int
+}
+
+func (m *receiptsMock) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	m.calls++
+	r, ok := m.receipts[txHash]
+	if !ok {
+		return nil, ethereum.NotFound
+	}
+	return r, nil
+}
+
+func (m *receiptsMock) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return &types.Header{Number: big.NewInt(m.head)}, nil
+}
+
+func TestReceiptCacheBackend_TransactionReceipt(t *testing.T) {
+	logsTx := common.HexToHash("0x01")
+	noLogsTx := common.HexToHash("0x02")
+	missingTx := common.HexToHash("0x03")
+	m := &receiptsMock{
+		head: 100,
+		receipts: map[common.Hash]*types.Receipt{
+			logsTx:   {TxHash: logsTx, Logs: []*types.Log{{BlockNumber: 88}}},
+			noLogsTx: {TxHash: noLogsTx},
+		},
+	}
+	b := NewReceiptCacheBackend(m, 12, 0)
+	ctx := context.Background()
+
+	tests := []struct {
+		name          string
+		head          int64
+		txHash        common.Hash
+		expectedCalls int
+	}{
+		{"final receipt from the node", 100, logsTx, 1},
+		{"final receipt from the cache", 100, logsTx, 1},
+		{"receipt without logs seen first", 100, noLogsTx, 2},
+		{"receipt without logs isn't final yet", 111, noLogsTx, 3},
+		{"receipt without logs becomes final", 112, noLogsTx, 4},
+		{"receipt without logs from the cache", 112, noLogsTx, 4},
+		{"missing receipt isn't cached", 112, missingTx, 5},
+		{"missing receipt is requested again", 112, missingTx, 6},
+	}
+
+	for _, tt := range tests {
+		m.head = tt.head
+		r, err := b.TransactionReceipt(ctx, tt.txHash)
+		if tt.txHash == missingTx {
+			if err != ethereum.NotFound {
+				t.Errorf("%v: expected error %v, but got %v", tt.name, ethereum.NotFound, err)
+			}
+		} else if err != nil || r.TxHash != tt.txHash {
+			t.Errorf("%v: expected receipt of %v, but got %v (%v)", tt.name, tt.txHash.Hex(), r, err)
+		}
+		if m.calls != tt.expectedCalls {
+			t.Errorf("%v: expected %v calls of the node, but got %v", tt.name, tt.expectedCalls, m.calls)
+		}
+	}
+}
+
+func TestReceiptCacheBackend_Evict(t *testing.T) {
+	m := &receiptsMock{receipts: make(map[common.Hash]*types.Receipt)}
+	for i := int64(1); i <= 3; i++ {
+		h := common.BigToHash(big.NewInt(i))
+		m.receipts[h] = &types.Receipt{TxHash: h}
+	}
+	b := NewReceiptCacheBackend(m, 0, 2).(*ReceiptCacheBackend)
+	ctx := context.Background()
+
+	for i := int64(1); i <= 3; i++ {
+		b.TransactionReceipt(ctx, common.BigToHash(big.NewInt(i)))
+	}
+
+	if _, ok := b.receipts[common.BigToHash(big.NewInt(1))]; ok {
+		t.Errorf("expected the oldest receipt to be evicted")
+	}
+	if len(b.receipts) != 2 || len(b.order) != 2 {
+		t.Errorf("expected 2 cached receipts, but got %v", len(b.receipts))
+	}
+}
+
+type constHeads int64
+
+func (h constHeads) BlockNumber(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(int64(h)), nil
+}
+
+func TestReceiptCacheBackend_Heads(t *testing.T) {
+	txHash := common.HexToHash("0x01")
+	m := &receiptsMock{
+		head:     0, // the latest header isn't used
+		receipts: map[common.Hash]*types.Receipt{txHash: {TxHash: txHash, Logs: []*types.Log{{BlockNumber: 88}}}},
+	}
+	b := NewHeadsReceiptCacheBackend(m, 12, 0, constHeads(100))
+
+	for i := 0; i < 2; i++ {
+		if _, err := b.TransactionReceipt(context.Background(), txHash); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if m.calls != 1 {
+		t.Errorf("expected receipt to be cached, but the node is called %v times", m.calls)
+	}
+}
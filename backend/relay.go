@@ -0,0 +1,191 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// Relay submits signed transactions to a private transaction relay (e.g. Flashbots), so that transactions don't
+// appear in the public transaction pool and can't be frontrun.
+type Relay struct {
+	url        string
+	signingKey *ecdsa.PrivateKey
+	// HTTPClient is used to send requests to the relay (http.DefaultClient by default).
+	HTTPClient *http.Client
+}
+
+// NewRelay creates an instance of Relay. If signing key is not nil, payloads are signed with it and signature is sent
+// in X-Flashbots-Signature header (the key identifies the sender, it doesn't need to hold any funds).
+func NewRelay(url string, signingKey *ecdsa.PrivateKey) *Relay {
+	return &Relay{url: url, signingKey: signingKey, HTTPClient: http.DefaultClient}
+}
+
+// SendPrivateTransaction sends the transaction using eth_sendPrivateTransaction. The relay stops trying to include
+// the transaction after maxBlockNumber (nil means relay's default).
+func (r *Relay) SendPrivateTransaction(ctx context.Context, tx *types.Transaction, maxBlockNumber *big.Int) error {
+	rawTx, err := rlp.EncodeToBytes(tx)
+	if err != nil {
+		return fmt.Errorf("relay: encoding tx: %v", err)
+	}
+
+	params := map[string]interface{}{
+		"tx": hexutil.Bytes(rawTx),
+	}
+	if maxBlockNumber != nil {
+		params["maxBlockNumber"] = (*hexutil.Big)(maxBlockNumber)
+	}
+
+	return r.call(ctx, nil, "eth_sendPrivateTransaction", params)
+}
+
+// SendBundle sends the transactions as a bundle which must be included in the given block atomically and in order,
+// using eth_sendBundle. It returns the bundle hash.
+func (r *Relay) SendBundle(ctx context.Context, txs []*types.Transaction, blockNumber *big.Int) (common.Hash, error) {
+	rawTxs := make([]hexutil.Bytes, len(txs))
+	for i, tx := range txs {
+		rawTx, err := rlp.EncodeToBytes(tx)
+		if err != nil {
+			return common.Hash{}, fmt.Errorf("relay: encoding tx %v: %v", i, err)
+		}
+		rawTxs[i] = rawTx
+	}
+
+	params := map[string]interface{}{
+		"txs":         rawTxs,
+		"blockNumber": (*hexutil.Big)(blockNumber),
+	}
+
+	var res struct {
+		BundleHash common.Hash `json:"bundleHash"`
+	}
+	if err := r.call(ctx, &res, "eth_sendBundle", params); err != nil {
+		return common.Hash{}, err
+	}
+	return res.BundleHash, nil
+}
+
+type relayRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      int           `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+type relayResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (r *Relay) call(ctx context.Context, result interface{}, method string, params ...interface{}) error {
+	body, err := json.Marshal(relayRequest{JSONRPC: "2.0", ID: 1, Method: method, Params: params})
+	if err != nil {
+		return fmt.Errorf("relay: %v: %v", method, err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, r.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("relay: %v: %v", method, err)
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+
+	if r.signingKey != nil {
+		signature, err := r.sign(body)
+		if err != nil {
+			return fmt.Errorf("relay: %v: signing payload: %v", method, err)
+		}
+		req.Header.Set("X-Flashbots-Signature", signature)
+	}
+
+	httpClient := r.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("relay: %v: %v", method, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("relay: %v: %v", method, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("relay: %v: unexpected status %v: %s", method, resp.Status, respBody)
+	}
+
+	var res relayResponse
+	if err := json.Unmarshal(respBody, &res); err != nil {
+		return fmt.Errorf("relay: %v: %v", method, err)
+	}
+	if res.Error != nil {
+		return fmt.Errorf("relay: %v: %v (code %v)", method, res.Error.Message, res.Error.Code)
+	}
+	if result != nil {
+		if len(res.Result) == 0 {
+			return fmt.Errorf("relay: %v: %v", method, errors.New("empty result"))
+		}
+		if err := json.Unmarshal(res.Result, result); err != nil {
+			return fmt.Errorf("relay: %v: %v", method, err)
+		}
+	}
+	return nil
+}
+
+// sign returns the value of X-Flashbots-Signature header: address of the signing key and the signature
+// of hex-encoded Keccak-256 hash of the body in Ethereum signed message format.
+func (r *Relay) sign(body []byte) (string, error) {
+	msg := crypto.Keccak256Hash(body).Hex()
+	hash := crypto.Keccak256([]byte(fmt.Sprintf("\x19Ethereum Signed Message:\n%d%s", len(msg), msg)))
+	sig, err := crypto.Sign(hash, r.signingKey)
+	if err != nil {
+		return "", err
+	}
+	return crypto.PubkeyToAddress(r.signingKey.PublicKey).Hex() + ":" + hexutil.Encode(sig), nil
+}
+
+// PrivateTxBackend sends transactions via the private relay instead of the inner backend.
+// All other calls are passed to the inner backend.
+type PrivateTxBackend struct {
+	Backend
+	relay *Relay
+}
+
+// NewPrivateTxBackend wraps backend and returns new instance of PrivateTxBackend.
+func NewPrivateTxBackend(inner Backend, relay *Relay) *PrivateTxBackend {
+	return &PrivateTxBackend{Backend: inner, relay: relay}
+}
+
+// ChainID returns chain ID of the inner backend, which must implement ChainID method, otherwise ErrNoChainID
+// is returned.
+func (b *PrivateTxBackend) ChainID(ctx context.Context) (*big.Int, error) {
+	return ChainID(ctx, b.Backend)
+}
+
+// HeaderByNumber returns the block header with the given number (the latest one, if number is nil).
+// The inner backend must implement HeaderByNumber method, otherwise ErrNoHeaders is returned.
+func (b *PrivateTxBackend) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return headerByNumber(ctx, b.Backend, number)
+}
+
+// SendTransaction sends the transaction via the private relay.
+func (b *PrivateTxBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	return b.relay.SendPrivateTransaction(ctx, tx, nil)
+}
@@ -0,0 +1,97 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestRelay_SendPrivateTransaction(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	signerAddress := crypto.PubkeyToAddress(key.PublicKey)
+
+	var (
+		gotMethod    string
+		gotSignature string
+		gotParams    []map[string]interface{}
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		var req struct {
+			Method string                   `json:"method"`
+			Params []map[string]interface{} `json:"params"`
+		}
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Errorf("failed to unmarshal request: %v", err)
+		}
+		gotMethod = req.Method
+		gotParams = req.Params
+		gotSignature = r.Header.Get("X-Flashbots-Signature")
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x"}`))
+	}))
+	defer srv.Close()
+
+	tx := types.NewTransaction(0, common.Address{}, big.NewInt(1), 21000, big.NewInt(1), nil)
+	b := NewPrivateTxBackend(nil, NewRelay(srv.URL, key))
+	if err := b.SendTransaction(context.Background(), tx); err != nil {
+		t.Fatalf("SendTransaction: %v", err)
+	}
+
+	if gotMethod != "eth_sendPrivateTransaction" {
+		t.Errorf("expected method eth_sendPrivateTransaction, but got %v", gotMethod)
+	}
+	if len(gotParams) != 1 || gotParams[0]["tx"] == nil {
+		t.Errorf("expected tx param, but got %v", gotParams)
+	}
+	if !strings.HasPrefix(gotSignature, signerAddress.Hex()+":0x") {
+		t.Errorf("expected signature of %v, but got %v", signerAddress.Hex(), gotSignature)
+	}
+}
+
+func TestRelay_SendBundle(t *testing.T) {
+	bundleHash := common.HexToHash("0x1234")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Flashbots-Signature") != "" {
+			t.Errorf("expected no signature when signing key isn't set")
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      1,
+			"result":  map[string]interface{}{"bundleHash": bundleHash},
+		})
+	}))
+	defer srv.Close()
+
+	txs := []*types.Transaction{
+		types.NewTransaction(0, common.Address{}, big.NewInt(1), 21000, big.NewInt(1), nil),
+		types.NewTransaction(1, common.Address{}, big.NewInt(1), 21000, big.NewInt(1), nil),
+	}
+	hash, err := NewRelay(srv.URL, nil).SendBundle(context.Background(), txs, big.NewInt(100))
+	if err != nil {
+		t.Fatalf("SendBundle: %v", err)
+	}
+	if hash != bundleHash {
+		t.Errorf("expected bundle hash %v, but got %v", bundleHash.Hex(), hash.Hex())
+	}
+}
+
+func TestRelay_Error(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"bundle rejected"}}`))
+	}))
+	defer srv.Close()
+
+	_, err := NewRelay(srv.URL, nil).SendBundle(context.Background(), nil, big.NewInt(100))
+	if err == nil || !strings.Contains(err.Error(), "bundle rejected") {
+		t.Errorf("expected relay error, but got %v", err)
+	}
+}
@@ -4,6 +4,7 @@ package backend
 
 import (
 	"context"
+	"fmt"
 	"math/big"
 	"sync"
 
@@ -12,6 +13,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/monetha/go-ethereum/blocktag"
 )
 
 // make sure SimulatedBackendExt implements Backend
@@ -32,14 +34,21 @@ type SimulatedBackendExt struct {
 	txs sync.Map
 }
 
-// CodeAt returns the code associated with a certain account in the blockchain.
+// CodeAt returns the code associated with a certain account in the blockchain, or in the pending state
+// for the pending block tag.
 func (b *SimulatedBackendExt) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
-	return b.b.CodeAt(ctx, contract, blockNumber)
+	if blocktag.Is(blockNumber, blocktag.Pending) {
+		return b.b.PendingCodeAt(ctx, contract)
+	}
+	return b.b.CodeAt(ctx, contract, resolveBlockTag(blockNumber))
 }
 
-// CallContract executes a contract call.
+// CallContract executes a contract call, against the pending state for the pending block tag.
 func (b *SimulatedBackendExt) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
-	return b.b.CallContract(ctx, call, blockNumber)
+	if blocktag.Is(blockNumber, blocktag.Pending) {
+		return b.b.PendingCallContract(ctx, call)
+	}
+	return b.b.CallContract(ctx, call, resolveBlockTag(blockNumber))
 }
 
 // PendingCodeAt returns the code associated with an account in the pending state.
@@ -66,9 +75,11 @@ func (b *SimulatedBackendExt) EstimateGas(ctx context.Context, call ethereum.Cal
 }
 
 // SendTransaction updates the pending block to include the given transaction.
-// It panics if the transaction is invalid.
-func (b *SimulatedBackendExt) SendTransaction(ctx context.Context, tx *types.Transaction) error {
-	err := b.b.SendTransaction(ctx, tx)
+// It returns an error if the transaction is invalid (the simulated backend panics in this case).
+func (b *SimulatedBackendExt) SendTransaction(ctx context.Context, tx *types.Transaction) (err error) {
+	defer recoverPanic("SendTransaction", &err)
+
+	err = b.b.SendTransaction(ctx, tx)
 	if err == nil {
 		b.txs.Store(tx.Hash(), tx)
 	}
@@ -76,14 +87,25 @@ func (b *SimulatedBackendExt) SendTransaction(ctx context.Context, tx *types.Tra
 	return err
 }
 
+// recoverPanic converts the panic of the simulated backend to the error.
+func recoverPanic(method string, err *error) {
+	if r := recover(); r != nil {
+		*err = fmt.Errorf("simulated backend %v: %v", method, r)
+	}
+}
+
 // TransactionReceipt returns the receipt of a transaction.
 func (b *SimulatedBackendExt) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
 	return b.b.TransactionReceipt(ctx, txHash)
 }
 
-// BalanceAt returns the wei balance of a certain account in the blockchain.
+// BalanceAt returns the wei balance of a certain account in the blockchain. The pending block tag isn't supported,
+// as `backends.SimulatedBackend` can't read balances of the pending state.
 func (b *SimulatedBackendExt) BalanceAt(ctx context.Context, address common.Address, blockNum *big.Int) (*big.Int, error) {
-	return b.b.BalanceAt(ctx, address, blockNum)
+	if blocktag.Is(blockNum, blocktag.Pending) {
+		return nil, fmt.Errorf("simulated backend BalanceAt: pending block tag is not supported")
+	}
+	return b.b.BalanceAt(ctx, address, resolveBlockTag(blockNum))
 }
 
 // FilterLogs executes a log filter operation, blocking during execution and
@@ -125,3 +147,16 @@ func (b *SimulatedBackendExt) TransactionByHash(ctx context.Context, txHash comm
 
 	return
 }
+
+// resolveBlockTag converts block tags (negative block numbers, see ethereum.LatestBlockNumber) to block numbers
+// understood by `backends.SimulatedBackend`. Every block of the simulated chain is final, so "latest", "safe" and
+// "finalized" refer to the latest block. The pending block tag must be handled by the caller.
+func resolveBlockTag(blockNum *big.Int) *big.Int {
+	if blockNum == nil || blockNum.Sign() >= 0 {
+		return blockNum
+	}
+	if blocktag.Is(blockNum, blocktag.Earliest) {
+		return new(big.Int)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+// +build !js
+
+package backend
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/monetha/go-ethereum/blocktag"
+)
+
+func TestSimulatedBackendExt_SendTransaction_Invalid(t *testing.T) {
+	b := NewSimulatedBackendExtended(core.GenesisAlloc{}, 10000000)
+
+	// unsigned transaction
+	tx := types.NewTransaction(0, common.Address{}, big.NewInt(1), 21000, big.NewInt(1), nil)
+	if err := b.SendTransaction(context.Background(), tx); err == nil {
+		t.Errorf("expected error, but got nil")
+	}
+}
+
+func TestSimulatedBackendExt_BalanceAt_Pending(t *testing.T) {
+	b := NewSimulatedBackendExtended(core.GenesisAlloc{}, 10000000)
+
+	if _, err := b.BalanceAt(context.Background(), common.Address{}, big.NewInt(blocktag.Pending)); err == nil {
+		t.Errorf("expected error for pending block tag, but got nil")
+	}
+}
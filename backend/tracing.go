@@ -0,0 +1,180 @@
+package backend
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/monetha/go-ethereum/tracing"
+)
+
+// TracingBackend starts a span for every call of the inner backend, with block number, transaction hash and address
+// attributes where applicable. The context holding the span is passed to the inner backend, so spans of decorated
+// backends and RPC clients become children of it.
+type TracingBackend struct {
+	Backend
+	tracer tracing.Tracer
+}
+
+// NewTracingBackend wraps backend and returns new instance of TracingBackend.
+func NewTracingBackend(inner Backend, tracer tracing.Tracer) Backend {
+	b := &TracingBackend{Backend: inner, tracer: tracer}
+
+	if cr, ok := inner.(commiterRollbacker); ok {
+		return &simBackend{
+			b:  b,
+			cr: cr,
+		}
+	}
+
+	return b
+}
+
+func (b *TracingBackend) start(ctx context.Context, method string) (context.Context, tracing.Span) {
+	ctx, span := b.tracer.Start(ctx, "Backend."+method)
+	span.SetAttribute(tracing.AttrRPCMethod, method)
+	return ctx, span
+}
+
+func setBlockNumber(span tracing.Span, blockNumber *big.Int) {
+	if blockNumber != nil {
+		span.SetAttribute(tracing.AttrBlockNumber, blockNumber)
+	}
+}
+
+// CodeAt returns the code of the given account.
+func (b *TracingBackend) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) (code []byte, err error) {
+	ctx, span := b.start(ctx, "CodeAt")
+	span.SetAttribute(tracing.AttrAddress, contract.Hex())
+	setBlockNumber(span, blockNumber)
+	defer func() { span.End(err) }()
+
+	return b.Backend.CodeAt(ctx, contract, blockNumber)
+}
+
+// CallContract executes an Ethereum contract call with the specified data as the input.
+func (b *TracingBackend) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) (res []byte, err error) {
+	ctx, span := b.start(ctx, "CallContract")
+	if call.To != nil {
+		span.SetAttribute(tracing.AttrAddress, call.To.Hex())
+	}
+	setBlockNumber(span, blockNumber)
+	defer func() { span.End(err) }()
+
+	return b.Backend.CallContract(ctx, call, blockNumber)
+}
+
+// PendingCodeAt returns the code of the given account in the pending state.
+func (b *TracingBackend) PendingCodeAt(ctx context.Context, account common.Address) (code []byte, err error) {
+	ctx, span := b.start(ctx, "PendingCodeAt")
+	span.SetAttribute(tracing.AttrAddress, account.Hex())
+	defer func() { span.End(err) }()
+
+	return b.Backend.PendingCodeAt(ctx, account)
+}
+
+// PendingNonceAt retrieves the current pending nonce associated with an account.
+func (b *TracingBackend) PendingNonceAt(ctx context.Context, account common.Address) (nonce uint64, err error) {
+	ctx, span := b.start(ctx, "PendingNonceAt")
+	span.SetAttribute(tracing.AttrAddress, account.Hex())
+	defer func() { span.End(err) }()
+
+	return b.Backend.PendingNonceAt(ctx, account)
+}
+
+// SuggestGasPrice retrieves the currently suggested gas price.
+func (b *TracingBackend) SuggestGasPrice(ctx context.Context) (gasPrice *big.Int, err error) {
+	ctx, span := b.start(ctx, "SuggestGasPrice")
+	defer func() { span.End(err) }()
+
+	return b.Backend.SuggestGasPrice(ctx)
+}
+
+// EstimateGas tries to estimate the gas needed to execute a specific transaction.
+func (b *TracingBackend) EstimateGas(ctx context.Context, call ethereum.CallMsg) (gas uint64, err error) {
+	ctx, span := b.start(ctx, "EstimateGas")
+	if call.To != nil {
+		span.SetAttribute(tracing.AttrAddress, call.To.Hex())
+	}
+	defer func() { span.End(err) }()
+
+	return b.Backend.EstimateGas(ctx, call)
+}
+
+// SendTransaction injects the transaction into the pending pool for execution.
+func (b *TracingBackend) SendTransaction(ctx context.Context, tx *types.Transaction) (err error) {
+	ctx, span := b.start(ctx, "SendTransaction")
+	span.SetAttribute(tracing.AttrTxHash, tx.Hash().Hex())
+	defer func() { span.End(err) }()
+
+	return b.Backend.SendTransaction(ctx, tx)
+}
+
+// TransactionReceipt returns the receipt of a transaction by transaction hash.
+func (b *TracingBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (r *types.Receipt, err error) {
+	ctx, span := b.start(ctx, "TransactionReceipt")
+	span.SetAttribute(tracing.AttrTxHash, txHash.Hex())
+	defer func() {
+		if err == ethereum.NotFound {
+			span.End(nil) // not mined yet
+			return
+		}
+		span.End(err)
+	}()
+
+	return b.Backend.TransactionReceipt(ctx, txHash)
+}
+
+// TransactionByHash returns the transaction with the given hash.
+func (b *TracingBackend) TransactionByHash(ctx context.Context, txHash common.Hash) (tx *types.Transaction, isPending bool, err error) {
+	ctx, span := b.start(ctx, "TransactionByHash")
+	span.SetAttribute(tracing.AttrTxHash, txHash.Hex())
+	defer func() { span.End(err) }()
+
+	return b.Backend.TransactionByHash(ctx, txHash)
+}
+
+// BalanceAt returns the balance of the account of given address.
+func (b *TracingBackend) BalanceAt(ctx context.Context, address common.Address, blockNum *big.Int) (balance *big.Int, err error) {
+	ctx, span := b.start(ctx, "BalanceAt")
+	span.SetAttribute(tracing.AttrAddress, address.Hex())
+	setBlockNumber(span, blockNum)
+	defer func() { span.End(err) }()
+
+	return b.Backend.BalanceAt(ctx, address, blockNum)
+}
+
+// FilterLogs executes a log filter operation.
+func (b *TracingBackend) FilterLogs(ctx context.Context, query ethereum.FilterQuery) (logs []types.Log, err error) {
+	ctx, span := b.start(ctx, "FilterLogs")
+	if query.FromBlock != nil {
+		span.SetAttribute("eth.from_block", query.FromBlock)
+	}
+	if query.ToBlock != nil {
+		span.SetAttribute("eth.to_block", query.ToBlock)
+	}
+	defer func() { span.End(err) }()
+
+	return b.Backend.FilterLogs(ctx, query)
+}
+
+// HeaderByNumber returns the block header with the given number (the latest one, if number is nil).
+// The inner backend must implement HeaderByNumber method, otherwise ErrNoHeaders is returned.
+func (b *TracingBackend) HeaderByNumber(ctx context.Context, number *big.Int) (h *types.Header, err error) {
+	ctx, span := b.start(ctx, "HeaderByNumber")
+	setBlockNumber(span, number)
+	defer func() { span.End(err) }()
+
+	return headerByNumber(ctx, b.Backend, number)
+}
+
+// ChainID returns chain ID of the inner backend, which must implement ChainID method, otherwise ErrNoChainID
+// is returned.
+func (b *TracingBackend) ChainID(ctx context.Context) (chainID *big.Int, err error) {
+	ctx, span := b.start(ctx, "ChainID")
+	defer func() { span.End(err) }()
+
+	return ChainID(ctx, b.Backend)
+}
@@ -0,0 +1,70 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/monetha/go-ethereum/tracing"
+)
+
+type recordedSpan struct {
+	name  string
+	attrs map[string]interface{}
+	err   error
+	ended bool
+}
+
+func (s *recordedSpan) SetAttribute(key string, value interface{}) { s.attrs[key] = value }
+func (s *recordedSpan) End(err error)                              { s.err, s.ended = err, true }
+
+type recordingTracer struct {
+	spans []*recordedSpan
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string) (context.Context, tracing.Span) {
+	s := &recordedSpan{name: name, attrs: make(map[string]interface{})}
+	t.spans = append(t.spans, s)
+	return ctx, s
+}
+
+func TestTracingBackend(t *testing.T) {
+	tr := &recordingTracer{}
+	address := common.HexToAddress("0x1")
+	errFailed := errors.New("failed")
+
+	b := NewTracingBackend(&endpointMock{balance: big.NewInt(1)}, tr)
+	if _, err := b.BalanceAt(context.Background(), address, big.NewInt(100)); err != nil {
+		t.Fatalf("BalanceAt: %v", err)
+	}
+
+	b = NewTracingBackend(&endpointMock{err: errFailed}, tr)
+	if _, err := b.BalanceAt(context.Background(), address, nil); err != errFailed {
+		t.Fatalf("expected error %v, but got %v", errFailed, err)
+	}
+
+	if len(tr.spans) != 2 {
+		t.Fatalf("expected 2 spans, but got %v", len(tr.spans))
+	}
+
+	s := tr.spans[0]
+	if s.name != "Backend.BalanceAt" || !s.ended || s.err != nil {
+		t.Errorf("unexpected span %+v", s)
+	}
+	if s.attrs[tracing.AttrAddress] != address.Hex() {
+		t.Errorf("expected address attribute %v, but got %v", address.Hex(), s.attrs[tracing.AttrAddress])
+	}
+	if bn, _ := s.attrs[tracing.AttrBlockNumber].(*big.Int); bn == nil || bn.Int64() != 100 {
+		t.Errorf("expected block number attribute 100, but got %v", s.attrs[tracing.AttrBlockNumber])
+	}
+
+	s = tr.spans[1]
+	if _, ok := s.attrs[tracing.AttrBlockNumber]; ok {
+		t.Errorf("expected no block number attribute for the latest block")
+	}
+	if s.err != errFailed {
+		t.Errorf("expected span to end with error %v, but got %v", errFailed, s.err)
+	}
+}
@@ -0,0 +1,68 @@
+package backend
+
+import "strings"
+
+// Messages of errors returned by nodes (geth, Parity/OpenEthereum, Nethermind, Besu) when the transaction is
+// already in the pool. Messages are matched as whole words (see errorContains).
+var alreadyKnownMessages = []string{
+	"already known",
+	"known transaction",
+	"alreadyknown",
+	"already imported",
+	"already_exists",
+}
+
+// Messages of errors returned by nodes when the transaction with the same nonce is in the pool and the gas price
+// of the new transaction isn't high enough to replace it.
+var replacementUnderpricedMessages = []string{
+	"replacement transaction underpriced",
+	"another transaction with same nonce",
+	"replacement_underpriced",
+	"replacementnotallowed",
+}
+
+// IsAlreadyKnown tells whether SendTransaction failed because the node already has the transaction in the pool.
+// It's effectively a success: the transaction was sent before (e.g. by the retried call which timed out).
+func IsAlreadyKnown(err error) bool {
+	return errorContains(err, alreadyKnownMessages)
+}
+
+// IsReplacementUnderpriced tells whether SendTransaction failed because another transaction with the same nonce
+// is in the pool and the gas price isn't bumped enough to replace it. The nonce is used by the pending
+// transaction, and sending can be retried with higher gas price.
+func IsReplacementUnderpriced(err error) bool {
+	return errorContains(err, replacementUnderpricedMessages)
+}
+
+func errorContains(err error, messages []string) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, m := range messages {
+		if containsWord(msg, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsWord tells whether s contains substr which isn't a part of another word, so that "known transaction"
+// doesn't match "unknown transaction".
+func containsWord(s, substr string) bool {
+	for i := 0; ; {
+		j := strings.Index(s[i:], substr)
+		if j < 0 {
+			return false
+		}
+		start, end := i+j, i+j+len(substr)
+		if (start == 0 || !isWordChar(s[start-1])) && (end == len(s) || !isWordChar(s[end])) {
+			return true
+		}
+		i = start + 1
+	}
+}
+
+func isWordChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '_'
+}
@@ -0,0 +1,36 @@
+package backend
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTxErrors(t *testing.T) {
+	tests := []struct {
+		err                    error
+		alreadyKnown           bool
+		replacementUnderpriced bool
+	}{
+		{nil, false, false},
+		{errors.New("already known"), true, false},
+		{errors.New("known transaction: 6a8c1f0c1e02fbb6a7b8e0bb5e3f1d7a51df0d2f13c0aa8b3e0ad2a4b8d8e1d2"), true, false},
+		{errors.New("Transaction with the same hash was already imported."), true, false},
+		{errors.New("AlreadyKnown"), true, false},
+		{errors.New("failed to send transaction: already known"), true, false},
+		{errors.New("unknown transaction"), false, false},
+		{errors.New("replacement transaction underpriced"), false, true},
+		{errors.New("Transaction gas price is too low. There is another transaction with same nonce in the queue."), false, true},
+		{errors.New("REPLACEMENT_UNDERPRICED"), false, true},
+		{errors.New("nonce too low"), false, false},
+		{errors.New("insufficient funds for gas * price + value"), false, false},
+	}
+
+	for _, tt := range tests {
+		if known := IsAlreadyKnown(tt.err); known != tt.alreadyKnown {
+			t.Errorf("%v: expected IsAlreadyKnown %v, but got %v", tt.err, tt.alreadyKnown, known)
+		}
+		if underpriced := IsReplacementUnderpriced(tt.err); underpriced != tt.replacementUnderpriced {
+			t.Errorf("%v: expected IsReplacementUnderpriced %v, but got %v", tt.err, tt.replacementUnderpriced, underpriced)
+		}
+	}
+}
@@ -0,0 +1,522 @@
+// Package backfill processes a historical range of blocks fast: the range is split into partitions fetched
+// concurrently by workers of one or multiple processes, which lease partitions in a shared store, so a partition
+// of a crashed process is taken over when its lease expires.
+package backfill
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/monetha/go-ethereum"
+	"github.com/monetha/go-ethereum/metrics"
+	"github.com/monetha/go-ethereum/progress"
+)
+
+const (
+	// DefaultPartitionSize is used when Config.PartitionSize is zero.
+	DefaultPartitionSize = 10000
+	// DefaultWorkers is used when Config.Workers is zero.
+	DefaultWorkers = 4
+	// DefaultLeaseDuration is used when Config.LeaseDuration is zero.
+	DefaultLeaseDuration = time.Minute
+	// DefaultProgressLogInterval is used when Config.ProgressLogInterval is zero.
+	DefaultProgressLogInterval = time.Minute
+)
+
+// blocksBuffer is the number of blocks every worker fetches ahead of delivery.
+const blocksBuffer = 64
+
+// progressInterval is the interval of measuring progress.
+const progressInterval = 5 * time.Second
+
+// retryDelay is the delay before retrying failed RPC call.
+const retryDelay = 4 * time.Second
+
+// BlockReader retrieves blocks, it's implemented by client.Client.
+type BlockReader interface {
+	BlockByNumber(ctx context.Context, number *big.Int) (*ethereum.Block, error)
+}
+
+// Config contains parameters of Coordinator.
+type Config struct {
+	// From and To are the first and the last blocks of the range.
+	From, To uint64
+	// PartitionSize is the number of blocks in a partition (DefaultPartitionSize when it's zero). All processes
+	// sharing the store must use the same range and partition size.
+	PartitionSize uint64
+	// Workers is the number of partitions fetched concurrently by the process (DefaultWorkers when it's zero).
+	Workers int
+	// Owner identifies the process in leases (host name and process ID when it's empty).
+	Owner string
+	// LeaseDuration is the time a partition stays leased without renewal (DefaultLeaseDuration when it's zero).
+	// Leases are renewed and progress is saved three times per the duration.
+	LeaseDuration time.Duration
+	// Store keeps partitions shared by processes (NewMemoryStore when it's nil).
+	Store Store
+	// Metrics receives delivered blocks and RPC errors. Metrics are discarded when nil.
+	Metrics metrics.Collector
+	// ProgressLogInterval is the interval of logging progress (see Coordinator.Progress) until the range is
+	// processed (DefaultProgressLogInterval when it's zero). Progress isn't logged when it's negative.
+	ProgressLogInterval time.Duration
+}
+
+// Coordinator delivers blocks of the range. Blocks of every partition are delivered in order, partitions are
+// delivered in the order they're acquired, so when one process backfills the range, all blocks are delivered in
+// order. Progress is saved periodically, so after restart blocks delivered since the last save are delivered again.
+type Coordinator struct {
+	C         <-chan *ethereum.Block // The channel on which the blocks are delivered, it's closed when all are.
+	reader    BlockReader
+	cfg       Config
+	m         metrics.Collector
+	acquireMu sync.Mutex // serializes acquisition of partitions, so they're queued in order
+	queue     chan *run
+	mu        sync.Mutex
+	active    map[int]*run // acquired partitions which aren't delivered yet
+	saveMu    sync.Mutex   // serializes saving of partitions, so older progress never overwrites newer one
+	meter     *progress.Meter
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+// run is the acquired partition.
+type run struct {
+	p      Partition // Next is the next block to deliver, guarded by Coordinator.mu
+	blocks chan *ethereum.Block
+	ctx    context.Context // canceled when the lease is lost
+	cancel context.CancelFunc
+}
+
+// New creates an instance of Coordinator and starts delivery of blocks. Partitions are created in the store
+// unless another process has already created them.
+func New(reader BlockReader, cfg *Config) (*Coordinator, error) {
+	if cfg == nil {
+		return nil, errors.New("backfill: config is nil")
+	}
+	c := &Coordinator{
+		reader: reader,
+		cfg:    *cfg,
+		m:      cfg.Metrics,
+		active: make(map[int]*run),
+		closed: make(chan struct{}),
+		meter:  progress.NewMeter(0),
+	}
+	if c.cfg.From > c.cfg.To {
+		return nil, fmt.Errorf("backfill: invalid range %v-%v", c.cfg.From, c.cfg.To)
+	}
+	if c.cfg.PartitionSize == 0 {
+		c.cfg.PartitionSize = DefaultPartitionSize
+	}
+	if c.cfg.Workers <= 0 {
+		c.cfg.Workers = DefaultWorkers
+	}
+	if c.cfg.Owner == "" {
+		host, _ := os.Hostname()
+		c.cfg.Owner = fmt.Sprintf("%v-%v", host, os.Getpid())
+	}
+	if c.cfg.LeaseDuration <= 0 {
+		c.cfg.LeaseDuration = DefaultLeaseDuration
+	}
+	if c.cfg.Store == nil {
+		c.cfg.Store = NewMemoryStore()
+	}
+	if c.m == nil {
+		c.m = metrics.Nop
+	}
+	if c.cfg.ProgressLogInterval == 0 {
+		c.cfg.ProgressLogInterval = DefaultProgressLogInterval
+	}
+
+	if err := c.cfg.Store.Init(split(c.cfg.From, c.cfg.To, c.cfg.PartitionSize)); err != nil {
+		return nil, err
+	}
+
+	c.measureProgress()
+
+	ch := make(chan *ethereum.Block)
+	c.C = ch
+	c.queue = make(chan *run, c.cfg.Workers)
+	c.runAsync(ch)
+	return c, nil
+}
+
+// Blocks returns the channel on which the blocks are delivered.
+func (c *Coordinator) Blocks() <-chan *ethereum.Block {
+	return c.C
+}
+
+// Partitions returns all partitions of the range with their progress and leases.
+func (c *Coordinator) Partitions() ([]Partition, error) {
+	return c.cfg.Store.List()
+}
+
+// Progress returns the progress of processing the range by all processes, as if partitions were processed in
+// order: Current is the next block to process, Head follows the last block of the range, so Behind is the number
+// of blocks left. It's measured every 5 seconds.
+func (c *Coordinator) Progress() progress.Progress {
+	return c.meter.Progress()
+}
+
+// Close implements io.Closer interface. It waits until the delivery of blocks stops, saves progress and releases
+// leases of partitions which aren't delivered, so other processes take them over immediately.
+func (c *Coordinator) Close() error {
+	c.closeOnce.Do(func() {
+		close(c.closed)
+		c.wg.Wait()
+
+		c.saveMu.Lock()
+		defer c.saveMu.Unlock()
+		for id, r := range c.active {
+			p := r.p
+			p.LeaseUntil = time.Time{}
+			if err := c.cfg.Store.Update(p); err != nil && err != ErrLeaseLost {
+				log.Printf("backfill: releasing %v: %v", p, err)
+			}
+			delete(c.active, id)
+		}
+	})
+	return nil
+}
+
+func (c *Coordinator) runAsync(blocks chan *ethereum.Block) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancelOnClose(cancel)
+
+	var workers sync.WaitGroup
+	for i := 0; i < c.cfg.Workers; i++ {
+		workers.Add(1)
+		c.wg.Add(1)
+		go func() {
+			defer c.wg.Done()
+			defer workers.Done()
+			c.work(ctx)
+		}()
+	}
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		workers.Wait()
+		close(c.queue)
+	}()
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		defer close(blocks)
+		for r := range c.queue {
+			if !c.deliver(ctx, r, blocks) {
+				return
+			}
+		}
+	}()
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		c.renewLeases(ctx)
+	}()
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		c.reportProgress(ctx)
+	}()
+}
+
+// work acquires partitions and fetches their blocks until no partitions are left.
+func (c *Coordinator) work(ctx context.Context) {
+	for {
+		r, err := c.acquire(ctx)
+		switch {
+		case err == nil:
+			c.fetch(r)
+			continue
+		case err == ErrDone || ctx.Err() != nil:
+			return
+		case err == ErrNoPartition:
+			if !c.othersLeased() {
+				return // the rest is fetched by workers of this process
+			}
+		default:
+			log.Printf("backfill: Acquire: %v", err)
+		}
+
+		// wait for leases of other processes to expire
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(c.renewInterval()):
+		}
+	}
+}
+
+// acquire leases the next partition and queues it for delivery.
+func (c *Coordinator) acquire(ctx context.Context) (*run, error) {
+	c.acquireMu.Lock()
+	defer c.acquireMu.Unlock()
+
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	p, err := c.cfg.Store.Acquire(c.cfg.Owner, time.Now().Add(c.cfg.LeaseDuration))
+	if err != nil {
+		return nil, err
+	}
+	if p.Next < p.From {
+		p.Next = p.From
+	}
+
+	rctx, cancel := context.WithCancel(ctx)
+	r := &run{p: p, blocks: make(chan *ethereum.Block, blocksBuffer), ctx: rctx, cancel: cancel}
+	c.mu.Lock()
+	c.active[p.ID] = r
+	c.mu.Unlock()
+
+	select {
+	case c.queue <- r:
+		return r, nil
+	case <-ctx.Done():
+		cancel()
+		return nil, ctx.Err()
+	}
+}
+
+// othersLeased tells whether some partitions which aren't done are leased by other processes.
+func (c *Coordinator) othersLeased() bool {
+	ps, err := c.cfg.Store.List()
+	if err != nil {
+		log.Printf("backfill: List: %v", err)
+		return true
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, p := range ps {
+		if _, ok := c.active[p.ID]; !ok && !p.Done() {
+			return true
+		}
+	}
+	return false
+}
+
+// fetch retrieves blocks of the partition which aren't delivered yet.
+func (c *Coordinator) fetch(r *run) {
+	defer close(r.blocks)
+
+	c.mu.Lock()
+	n, to := r.p.Next, r.p.To
+	c.mu.Unlock()
+
+	for n <= to {
+		b, err := c.reader.BlockByNumber(r.ctx, new(big.Int).SetUint64(n))
+		if err != nil {
+			if r.ctx.Err() != nil {
+				return
+			}
+			log.Printf("backfill: BlockByNumber(%v): %v", n, err)
+			c.m.RPCError("backfill", "eth_getBlockByNumber")
+			select {
+			case <-r.ctx.Done():
+				return
+			case <-time.After(retryDelay):
+			}
+			continue
+		}
+
+		select {
+		case r.blocks <- b:
+			n++
+		case <-r.ctx.Done():
+			return
+		}
+	}
+}
+
+// deliver sends fetched blocks of the partition to the channel, it returns false when the coordinator is closed.
+func (c *Coordinator) deliver(ctx context.Context, r *run, blocks chan<- *ethereum.Block) bool {
+	for {
+		var b *ethereum.Block
+		var ok bool
+		select {
+		case b, ok = <-r.blocks:
+		case <-r.ctx.Done():
+			return ctx.Err() == nil // the lease is lost, another process delivers the rest
+		}
+		if !ok {
+			c.finish(r)
+			return ctx.Err() == nil
+		}
+
+		select {
+		case blocks <- b:
+		case <-r.ctx.Done():
+			return ctx.Err() == nil
+		}
+
+		c.mu.Lock()
+		r.p.Next = b.Number.Uint64() + 1
+		c.mu.Unlock()
+		c.m.BlockDelivered(b.Number)
+	}
+}
+
+// finish saves the partition when all its blocks are delivered.
+func (c *Coordinator) finish(r *run) {
+	c.saveMu.Lock()
+	defer c.saveMu.Unlock()
+
+	c.mu.Lock()
+	p := r.p
+	done := p.Done()
+	if done {
+		delete(c.active, p.ID)
+	}
+	c.mu.Unlock()
+	if !done {
+		return
+	}
+
+	r.cancel()
+	if err := c.cfg.Store.Update(p); err != nil {
+		log.Printf("backfill: saving %v: %v", p, err)
+	}
+}
+
+// renewLeases periodically extends leases of acquired partitions and saves their progress.
+func (c *Coordinator) renewLeases(ctx context.Context) {
+	ticker := time.NewTicker(c.renewInterval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		c.mu.Lock()
+		runs := make([]*run, 0, len(c.active))
+		for _, r := range c.active {
+			runs = append(runs, r)
+		}
+		c.mu.Unlock()
+
+		for _, r := range runs {
+			c.renew(r)
+		}
+	}
+}
+
+// renew extends the lease of the partition and saves its progress.
+func (c *Coordinator) renew(r *run) {
+	c.saveMu.Lock()
+	defer c.saveMu.Unlock()
+
+	c.mu.Lock()
+	if c.active[r.p.ID] != r {
+		c.mu.Unlock()
+		return // delivered meanwhile
+	}
+	p := r.p
+	c.mu.Unlock()
+	p.LeaseUntil = time.Now().Add(c.cfg.LeaseDuration)
+
+	switch err := c.cfg.Store.Update(p); err {
+	case nil:
+		c.mu.Lock()
+		r.p.LeaseUntil = p.LeaseUntil
+		c.mu.Unlock()
+	case ErrLeaseLost:
+		log.Printf("backfill: lease of %v is lost", p)
+		c.mu.Lock()
+		delete(c.active, p.ID)
+		c.mu.Unlock()
+		r.cancel()
+	default:
+		log.Printf("backfill: renewing lease of %v: %v", p, err)
+	}
+}
+
+// reportProgress periodically measures and logs progress of processing the range.
+func (c *Coordinator) reportProgress(ctx context.Context) {
+	ticker := time.NewTicker(progressInterval)
+	defer ticker.Stop()
+
+	logged := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if !c.measureProgress() {
+			return // the range is processed
+		}
+		if interval := c.cfg.ProgressLogInterval; interval > 0 && time.Since(logged) >= interval {
+			logged = time.Now()
+			log.Printf("backfill: %v", c.meter.Progress())
+		}
+	}
+}
+
+// measureProgress updates the progress with the number of processed blocks, it returns false when all are.
+func (c *Coordinator) measureProgress() bool {
+	ps, err := c.cfg.Store.List()
+	if err != nil {
+		log.Printf("backfill: List: %v", err)
+		return true
+	}
+
+	var processed uint64
+	c.mu.Lock()
+	for _, p := range ps {
+		if r, ok := c.active[p.ID]; ok {
+			p = r.p // the store is updated only periodically
+		}
+		if p.Next > p.From {
+			processed += p.Next - p.From
+		}
+	}
+	c.mu.Unlock()
+
+	// blocks before the range are considered processed, so Current doesn't underflow when the range starts at 0
+	c.meter.Update(c.cfg.From+processed, c.cfg.To+1)
+	return processed < c.cfg.To-c.cfg.From+1
+}
+
+func (c *Coordinator) renewInterval() time.Duration {
+	return c.cfg.LeaseDuration / 3
+}
+
+func (c *Coordinator) cancelOnClose(cancel context.CancelFunc) {
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		defer cancel()
+
+		<-c.closed
+	}()
+}
+
+// split divides the range into partitions of the size.
+func split(from, to, size uint64) []Partition {
+	var ps []Partition
+	for start := from; ; start += size {
+		end := to
+		if to-start >= size {
+			end = start + size - 1
+		}
+		ps = append(ps, Partition{ID: len(ps), From: start, To: end, Next: start})
+		if end == to {
+			return ps
+		}
+	}
+}
@@ -0,0 +1,268 @@
+package backfill
+
+import (
+	"context"
+	"math/big"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/monetha/go-ethereum"
+)
+
+type blockReaderFunc func(ctx context.Context, number *big.Int) (*ethereum.Block, error)
+
+func (f blockReaderFunc) BlockByNumber(ctx context.Context, number *big.Int) (*ethereum.Block, error) {
+	return f(ctx, number)
+}
+
+var chain = blockReaderFunc(func(ctx context.Context, number *big.Int) (*ethereum.Block, error) {
+	return &ethereum.Block{Number: new(big.Int).Set(number)}, nil
+})
+
+func collect(t *testing.T, c *Coordinator) []uint64 {
+	var res []uint64
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case b, ok := <-c.Blocks():
+			if !ok {
+				return res
+			}
+			res = append(res, b.Number.Uint64())
+		case <-timeout:
+			t.Fatalf("timeout, delivered %v blocks", len(res))
+		}
+	}
+}
+
+func numbers(from, to uint64) []uint64 {
+	var res []uint64
+	for n := from; n <= to; n++ {
+		res = append(res, n)
+	}
+	return res
+}
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		from, to, size uint64
+		expected       [][2]uint64
+	}{
+		{0, 0, 10, [][2]uint64{{0, 0}}},
+		{0, 9, 10, [][2]uint64{{0, 9}}},
+		{0, 10, 10, [][2]uint64{{0, 9}, {10, 10}}},
+		{5, 24, 10, [][2]uint64{{5, 14}, {15, 24}}},
+		{^uint64(0) - 5, ^uint64(0), 4, [][2]uint64{{^uint64(0) - 5, ^uint64(0) - 2}, {^uint64(0) - 1, ^uint64(0)}}},
+	}
+	for _, tt := range tests {
+		var got [][2]uint64
+		for i, p := range split(tt.from, tt.to, tt.size) {
+			if p.ID != i || p.Next != p.From {
+				t.Errorf("unexpected partition %v", p)
+			}
+			got = append(got, [2]uint64{p.From, p.To})
+		}
+		if !reflect.DeepEqual(tt.expected, got) {
+			t.Errorf("%v-%v by %v: expected %v, but got %v", tt.from, tt.to, tt.size, tt.expected, got)
+		}
+	}
+}
+
+func TestCoordinator_OrderedDelivery(t *testing.T) {
+	// blocks of later partitions are fetched faster
+	reader := blockReaderFunc(func(ctx context.Context, number *big.Int) (*ethereum.Block, error) {
+		if number.Uint64() < 10 {
+			time.Sleep(time.Millisecond)
+		}
+		return chain(ctx, number)
+	})
+	store := NewMemoryStore()
+	c, err := New(reader, &Config{From: 0, To: 99, PartitionSize: 10, Workers: 3, Store: store})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if got := collect(t, c); !reflect.DeepEqual(numbers(0, 99), got) {
+		t.Errorf("expected blocks 0-99 in order, but got %v", got)
+	}
+
+	ps, err := c.Partitions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ps) != 10 {
+		t.Fatalf("expected 10 partitions, but got %v", len(ps))
+	}
+	for _, p := range ps {
+		if !p.Done() {
+			t.Errorf("expected %v to be done", p)
+		}
+	}
+}
+
+func TestCoordinator_Resume(t *testing.T) {
+	store := NewMemoryStore()
+	ps := split(0, 29, 10)
+	ps[0].Next = 10 // done
+	ps[1].Next = 15
+	if err := store.Init(ps); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := New(chain, &Config{From: 0, To: 29, PartitionSize: 10, Workers: 2, Store: store})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if p := c.Progress(); p.Current != 15 || p.Head != 30 || p.Behind != 15 {
+		t.Errorf("expected 15 of 30 blocks to be processed, but got %v", p)
+	}
+	if got := collect(t, c); !reflect.DeepEqual(numbers(15, 29), got) {
+		t.Errorf("expected blocks 15-29, but got %v", got)
+	}
+	if c.measureProgress() {
+		t.Error("expected the range to be processed")
+	}
+	if p := c.Progress(); p.Behind != 0 || p.ETA != 0 {
+		t.Errorf("expected the range to be processed, but got %v", p)
+	}
+
+	if _, err := New(chain, &Config{From: 0, To: 39, PartitionSize: 10, Store: store}); err == nil {
+		t.Error("expected error for different range")
+	}
+}
+
+func TestCoordinator_SharedStore(t *testing.T) {
+	store := NewMemoryStore()
+	cfg := Config{From: 100, To: 299, PartitionSize: 20, Workers: 2, LeaseDuration: 300 * time.Millisecond, Store: store}
+
+	var mu sync.Mutex
+	delivered := make(map[uint64]int)
+	var wg sync.WaitGroup
+	for _, owner := range []string{"a", "b"} {
+		cfg := cfg
+		cfg.Owner = owner
+		c, err := New(chain, &cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer c.Close()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var prev uint64
+			for b := range c.Blocks() {
+				n := b.Number.Uint64()
+				if n <= prev {
+					t.Errorf("block %v delivered after %v", n, prev)
+				}
+				prev = n
+				mu.Lock()
+				delivered[n]++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	for n := cfg.From; n <= cfg.To; n++ {
+		if delivered[n] != 1 {
+			t.Errorf("expected block %v to be delivered once, but got %v times", n, delivered[n])
+		}
+	}
+}
+
+func TestCoordinator_LeaseTakeover(t *testing.T) {
+	store := NewMemoryStore()
+	if err := store.Init(split(0, 19, 10)); err != nil {
+		t.Fatal(err)
+	}
+	// a crashed process leased the first partition and processed some blocks
+	p, err := store.Acquire("crashed", time.Now().Add(200*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Next = 4
+	if err := store.Update(p); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := New(chain, &Config{From: 0, To: 19, PartitionSize: 10, LeaseDuration: 60 * time.Millisecond, Store: store})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	expected := append(numbers(10, 19), numbers(4, 9)...)
+	if got := collect(t, c); !reflect.DeepEqual(expected, got) {
+		t.Errorf("expected blocks %v, but got %v", expected, got)
+	}
+}
+
+func TestCoordinator_Close(t *testing.T) {
+	store := NewMemoryStore()
+	c, err := New(chain, &Config{From: 0, To: 99, PartitionSize: 50, Owner: "a", Store: store})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		<-c.Blocks()
+	}
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// progress is saved and leases are released
+	ps, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ps[0].Next < 10 || ps[0].Done() {
+		t.Errorf("expected progress of delivered blocks to be saved, but got %v", ps[0])
+	}
+	if _, err := store.Acquire("b", time.Now().Add(time.Minute)); err != nil {
+		t.Errorf("expected partition to be released, but got %v", err)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	s := NewMemoryStore()
+	if err := s.Init(split(0, 19, 10)); err != nil {
+		t.Fatal(err)
+	}
+
+	until := time.Now().Add(time.Minute)
+	p0, err := s.Acquire("a", until)
+	if err != nil || p0.ID != 0 || p0.Owner != "a" {
+		t.Fatalf("expected partition 0 leased by a, but got %v (%v)", p0, err)
+	}
+	p1, err := s.Acquire("b", until)
+	if err != nil || p1.ID != 1 {
+		t.Fatalf("expected partition 1, but got %v (%v)", p1, err)
+	}
+	if _, err := s.Acquire("c", until); err != ErrNoPartition {
+		t.Errorf("expected ErrNoPartition, but got %v", err)
+	}
+
+	p1.Owner = "a"
+	if err := s.Update(p1); err != ErrLeaseLost {
+		t.Errorf("expected ErrLeaseLost, but got %v", err)
+	}
+
+	p0.Next, p1.Next = 10, 20
+	p1.Owner = "b"
+	if err := s.Update(p0); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Update(p1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Acquire("c", until); err != ErrDone {
+		t.Errorf("expected ErrDone, but got %v", err)
+	}
+}
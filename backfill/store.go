@@ -0,0 +1,136 @@
+package backfill
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNoPartition is returned by Store.Acquire when all partitions which aren't done are leased by others.
+	ErrNoPartition = errors.New("backfill: no partition available")
+	// ErrDone is returned by Store.Acquire when all partitions are done.
+	ErrDone = errors.New("backfill: all partitions are done")
+	// ErrLeaseLost is returned by Store.Update when the partition is leased by other owner, e.g. after the lease
+	// expired.
+	ErrLeaseLost = errors.New("backfill: lease lost")
+)
+
+// Partition is a part of the block range processed by one worker at a time.
+type Partition struct {
+	ID         int       `json:"id"`   // index of the partition in the range
+	From       uint64    `json:"from"` // the first block
+	To         uint64    `json:"to"`   // the last block
+	Next       uint64    `json:"next"` // the next block to process, the partition is done when it's after To
+	Owner      string    `json:"owner,omitempty"`
+	LeaseUntil time.Time `json:"leaseUntil,omitempty"`
+}
+
+// Done tells whether all blocks of the partition are processed.
+func (p Partition) Done() bool {
+	return p.Next > p.To
+}
+
+// Leased tells whether the partition is leased at the time.
+func (p Partition) Leased(now time.Time) bool {
+	return p.Owner != "" && now.Before(p.LeaseUntil)
+}
+
+func (p Partition) String() string {
+	return fmt.Sprintf("partition %v (blocks %v-%v, next %v)", p.ID, p.From, p.To, p.Next)
+}
+
+// Store keeps partitions and their leases, so workers of multiple processes share the range. Acquire and Update
+// must be atomic across the processes (e.g. transactions of a database).
+type Store interface {
+	// Init creates the partitions unless they're already created (e.g. by other process), it fails when existing
+	// partitions cover different range.
+	Init(partitions []Partition) error
+	// Acquire leases the first partition which isn't done and isn't leased by others (its lease expired) to the
+	// owner until the time. It returns ErrNoPartition or ErrDone when there is no such partition.
+	Acquire(owner string, until time.Time) (Partition, error)
+	// Update saves progress and lease of the partition, it returns ErrLeaseLost when the partition is leased by
+	// other owner.
+	Update(p Partition) error
+	// List returns all partitions ordered by ID.
+	List() ([]Partition, error)
+}
+
+// MemoryStore keeps partitions in memory. It's useful for tests and for backfilling within one process.
+type MemoryStore struct {
+	mu         sync.Mutex
+	partitions []Partition
+}
+
+// NewMemoryStore creates an instance of MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+// Init implements Store interface.
+func (s *MemoryStore) Init(partitions []Partition) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.partitions == nil {
+		s.partitions = append([]Partition{}, partitions...)
+		return nil
+	}
+	return checkRange(s.partitions, partitions)
+}
+
+// Acquire implements Store interface.
+func (s *MemoryStore) Acquire(owner string, until time.Time) (Partition, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	done := true
+	for i := range s.partitions {
+		p := &s.partitions[i]
+		if p.Done() {
+			continue
+		}
+		done = false
+		if !p.Leased(now) {
+			p.Owner, p.LeaseUntil = owner, until
+			return *p, nil
+		}
+	}
+	if done {
+		return Partition{}, ErrDone
+	}
+	return Partition{}, ErrNoPartition
+}
+
+// Update implements Store interface.
+func (s *MemoryStore) Update(p Partition) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if p.ID < 0 || p.ID >= len(s.partitions) {
+		return fmt.Errorf("backfill: unknown partition %v", p.ID)
+	}
+	if s.partitions[p.ID].Owner != p.Owner {
+		return ErrLeaseLost
+	}
+	s.partitions[p.ID] = p
+	return nil
+}
+
+// List implements Store interface.
+func (s *MemoryStore) List() ([]Partition, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Partition{}, s.partitions...), nil
+}
+
+// checkRange checks that existing partitions cover the same blocks as the new ones.
+func checkRange(existing, partitions []Partition) error {
+	same := len(existing) == len(partitions)
+	for i := 0; same && i < len(existing); i++ {
+		same = existing[i].From == partitions[i].From && existing[i].To == partitions[i].To
+	}
+	if !same {
+		return errors.New("backfill: store has partitions of different range")
+	}
+	return nil
+}
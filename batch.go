@@ -0,0 +1,99 @@
+package ethereum
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/monetha/go-ethereum/backend"
+)
+
+// PreparedTx describes transaction of the batch.
+type PreparedTx struct {
+	To       common.Address
+	Value    *big.Int // nil = 0
+	Data     []byte
+	GasLimit uint64   // 0 = estimate
+	GasPrice *big.Int // nil = gas price of the session
+}
+
+// BatchResult holds result of sending transaction of the batch. Either Tx or Err is set.
+type BatchResult struct {
+	Tx  *types.Transaction
+	Err error
+}
+
+// SendBatch sends transactions from the session account with consecutive nonces, starting from the pending nonce.
+// Transactions which fail validation (gas estimation) are skipped before nonces are assigned, and the nonce
+// of a transaction which failed to be sent is reused by the next one, so no nonce gaps are created (unless the nonce
+// is used by another pending transaction, see backend.IsReplacementUnderpriced).
+// It returns results in the order of transactions.
+// Like HandleNonceBackend, it should be used within one goroutine for the account.
+func (s *Session) SendBatch(ctx context.Context, txs []PreparedTx) []BatchResult {
+	results := make([]BatchResult, len(txs))
+	ctx = s.ctxOrDefault(ctx)
+
+	from := s.TransactOpts.From
+
+	// validate transactions before assigning nonces
+	gasLimits := make([]uint64, len(txs))
+	for i, tx := range txs {
+		gasLimits[i] = tx.GasLimit
+		if gasLimits[i] != 0 {
+			continue
+		}
+
+		to := tx.To
+		gasLimit, err := s.Backend.EstimateGas(ctx, ethereum.CallMsg{From: from, To: &to, Value: tx.Value, Data: tx.Data})
+		if err != nil {
+			results[i].Err = fmt.Errorf("failed to estimate gas needed: %v", err)
+			continue
+		}
+		gasLimits[i] = gasLimit
+	}
+
+	nonce, err := s.Backend.PendingNonceAt(ctx, from)
+	if err != nil {
+		err = fmt.Errorf("failed to retrieve account nonce: %v", err)
+		for i := range results {
+			if results[i].Err == nil {
+				results[i].Err = err
+			}
+		}
+		return results
+	}
+
+	tr := s.transferer()
+	for i, tx := range txs {
+		if results[i].Err != nil {
+			continue
+		}
+
+		opts := s.WithContext(ctx).TransactOpts
+		opts.Nonce = new(big.Int).SetUint64(nonce)
+		opts.Value = copyBigInt(tx.Value)
+		opts.GasLimit = gasLimits[i]
+		if tx.GasPrice != nil {
+			opts.GasPrice = copyBigInt(tx.GasPrice)
+		}
+
+		s.Log("Sending batch transaction", "index", i, "nonce", nonce, "to", tx.To.Hex())
+
+		signedTx, err := tr.Transfer(&opts, tx.To, tx.Data)
+		if err != nil {
+			results[i].Err = err
+			if backend.IsReplacementUnderpriced(err) {
+				nonce++ // nonce is used by another pending transaction
+			}
+			continue // otherwise nonce isn't used, so the next transaction takes it
+		}
+
+		results[i].Tx = signedTx
+		nonce++
+	}
+
+	return results
+}
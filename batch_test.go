@@ -0,0 +1,71 @@
+package ethereum
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/monetha/go-ethereum/backend"
+)
+
+func TestSession_SendBatch(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	from := crypto.PubkeyToAddress(key.PublicKey)
+
+	sim := backend.NewSimulatedBackendExtended(core.GenesisAlloc{from: {Balance: ether}}, 10000000)
+	sim.Commit()
+
+	e := New(sim, nil)
+	e.SuggestedGasPrice = big.NewInt(1)
+	s := e.NewSession(key)
+
+	key2, _ := crypto.GenerateKey()
+	to := crypto.PubkeyToAddress(key2.PublicKey)
+
+	amount := new(big.Int).Div(ether, big.NewInt(10))
+	results := s.SendBatch(context.TODO(), []PreparedTx{
+		{To: to, Value: amount},
+		{To: to, Value: new(big.Int).Mul(ether, big.NewInt(2))}, // fails gas estimation, not enough funds
+		{To: to, Value: amount},
+	})
+	sim.Commit()
+
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, but got %v", len(results))
+	}
+	if results[1].Err == nil {
+		t.Errorf("expected second transaction to fail")
+	}
+
+	for i, expectedNonce := range map[int]uint64{0: 0, 2: 1} {
+		r := results[i]
+		if r.Err != nil {
+			t.Fatalf("transaction %v: unexpected error: %v", i, r.Err)
+		}
+		if r.Tx.Nonce() != expectedNonce {
+			t.Errorf("transaction %v: expected nonce %v, but got %v", i, expectedNonce, r.Tx.Nonce())
+		}
+	}
+
+	toBalance, err := sim.BalanceAt(context.TODO(), to, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := new(big.Int).Mul(amount, big.NewInt(2)); expected.Cmp(toBalance) != 0 {
+		t.Errorf("expected balance %v, but got %v", expected, toBalance)
+	}
+}
+
+func TestSession_SendBatch_NilContext(t *testing.T) {
+	b := &ctxBackend{}
+	type ctxKey struct{}
+	sessionCtx := context.WithValue(context.Background(), ctxKey{}, "session")
+	s := (&Session{Eth: NewEth(b)}).WithContext(sessionCtx)
+
+	s.SendBatch(nil, []PreparedTx{{Value: big.NewInt(1)}})
+	if b.ctx != sessionCtx {
+		t.Errorf("expected default context of the session to be used")
+	}
+}
@@ -20,17 +20,44 @@ const (
 	TransactionSuccessful = TransactionStatus(1)
 )
 
+// TransactionType is the EIP-2718 type of transaction.
+type TransactionType uint8
+
+const (
+	// LegacyTxType is the type of legacy (pre EIP-2718) transactions.
+	LegacyTxType = TransactionType(0)
+
+	// AccessListTxType is the type of EIP-2930 transactions.
+	AccessListTxType = TransactionType(1)
+
+	// DynamicFeeTxType is the type of EIP-1559 transactions.
+	DynamicFeeTxType = TransactionType(2)
+
+	// BlobTxType is the type of EIP-4844 (blob-carrying) transactions.
+	BlobTxType = TransactionType(3)
+)
+
 // Block holds information about Ethereum block.
 type Block struct {
-	Difficulty   *big.Int
-	ExtraData    []byte
-	GasLimit     *big.Int
-	GasUsed      *big.Int
-	Hash         common.Hash
-	Miner        common.Address
-	Number       *big.Int
-	Timestamp    uint64
-	Transactions Transactions
+	Difficulty    *big.Int
+	ExtraData     []byte
+	GasLimit      *big.Int
+	GasUsed       *big.Int
+	Hash          common.Hash
+	ParentHash    common.Hash
+	Miner         common.Address
+	Number        *big.Int
+	Timestamp     uint64
+	Transactions  Transactions
+	UncleHashes   []common.Hash
+	Uncles        []*Uncle    // nil unless uncle headers were requested
+	BlobGasUsed   *big.Int    // nil for blocks before Cancun
+	ExcessBlobGas *big.Int    // nil for blocks before Cancun
+	Withdrawals   Withdrawals // nil for blocks before Shanghai
+	LogsBloom     types.Bloom
+	// ReceiptsSkipped means receipts of transactions weren't retrieved, since the logs bloom shows the block
+	// can't contain logs the client is interested in (see client.Client.SetLogFilters).
+	ReceiptsSkipped bool
 }
 
 func (b *Block) String() string {
@@ -40,20 +67,53 @@ ExtraData:      %s
 GasLimit:	    %v
 GasUsed:	    %v
 Hash:           %x
+ParentHash:     %x
 Miner:          %x
 Timestamp:      %v
 Transactions:
 %v
+UncleHashes:    %x
+Uncles:
+%v
+BlobGasUsed:    %v
+ExcessBlobGas:  %v
+Withdrawals:
+%v
 }
-`, b.Number, b.Difficulty, b.ExtraData, b.GasLimit, b.GasUsed, b.Hash[:], b.Miner[:], b.Timestamp, b.Transactions)
+`, b.Number, b.Difficulty, b.ExtraData, b.GasLimit, b.GasUsed, b.Hash[:], b.ParentHash[:], b.Miner[:], b.Timestamp, b.Transactions, b.UncleHashes, b.Uncles,
+		b.BlobGasUsed, b.ExcessBlobGas, b.Withdrawals)
 	return str
 }
 
+// Withdrawals slice type.
+type Withdrawals []*Withdrawal
+
+// Withdrawal holds information about validator withdrawal (EIP-4895). Withdrawals credit
+// address balances without any transaction.
+type Withdrawal struct {
+	Index          uint64
+	ValidatorIndex uint64
+	Address        common.Address
+	Amount         uint64 // in Gwei
+}
+
+var gwei = big.NewInt(1000000000)
+
+// AmountWei returns the withdrawn amount in wei.
+func (w *Withdrawal) AmountWei() *big.Int {
+	return new(big.Int).Mul(new(big.Int).SetUint64(w.Amount), gwei)
+}
+
+func (w *Withdrawal) String() string {
+	return fmt.Sprintf("	Withdrawal(#%v): validator %v, address %x, amount %v Gwei\n", w.Index, w.ValidatorIndex, w.Address[:], w.Amount)
+}
+
 // Transactions slice type.
 type Transactions []*Transaction
 
 // Transaction holds information about Ethereum transaction.
 type Transaction struct {
+	Type             TransactionType
 	BlockNumber      *big.Int
 	From             common.Address
 	GasLimit         *big.Int
@@ -68,6 +128,11 @@ type Transaction struct {
 	ContractAddress  *common.Address
 	Status           *TransactionStatus
 	Logs             []*types.Log
+
+	BlobVersionedHashes []common.Hash // blob transactions only
+	MaxFeePerBlobGas    *big.Int      // blob transactions only
+	BlobGasUsed         *big.Int      // blob transactions only
+	BlobGasPrice        *big.Int      // blob transactions only
 }
 
 func (t *Transaction) String() string {
@@ -92,6 +157,7 @@ func (t *Transaction) String() string {
 
 	return fmt.Sprintf(`
 	TX(%s)
+	Type:            %v
 	BlockNumber:     %#v
 	TxIndex:         %v
 	Contract:        %v
@@ -106,8 +172,13 @@ func (t *Transaction) String() string {
 	Value:           %#v
 	Status:          %v
 	Logs:            %v
+	BlobHashes:      %x
+	MaxFeePerBlobGas: %#v
+	BlobGasUsed:     %#v
+	BlobGasPrice:    %#v
 `,
 		t.Hash.String(),
+		t.Type,
 		t.BlockNumber,
 		t.TransactionIndex,
 		t.To == nil,
@@ -122,6 +193,10 @@ func (t *Transaction) String() string {
 		t.Value,
 		status,
 		logsToString("	", t.Logs),
+		t.BlobVersionedHashes,
+		t.MaxFeePerBlobGas,
+		t.BlobGasUsed,
+		t.BlobGasPrice,
 	)
 }
 
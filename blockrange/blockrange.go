@@ -0,0 +1,163 @@
+// Package blockrange walks ranges of blocks, fetching them concurrently and processing them in order.
+package blockrange
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/monetha/go-ethereum"
+)
+
+// BlockReader reads blocks by number (e.g. client.Client).
+type BlockReader interface {
+	BlockByNumber(ctx context.Context, number *big.Int) (*ethereum.Block, error)
+}
+
+// Progress describes progress of walking the range.
+type Progress struct {
+	Number    *big.Int // number of the last processed block
+	Processed uint64   // number of processed blocks
+	Total     uint64   // number of blocks in the range
+}
+
+type config struct {
+	concurrency int
+	retries     int
+	retryDelay  time.Duration
+	progress    func(Progress)
+}
+
+// Option configures walking the range.
+type Option func(*config)
+
+// WithConcurrency sets the number of blocks fetched concurrently (4 by default).
+func WithConcurrency(n int) Option {
+	return func(c *config) {
+		if n > 0 {
+			c.concurrency = n
+		}
+	}
+}
+
+// WithRetries sets the number of retries of failed block requests and the delay between them
+// (3 retries with 1 second delay by default).
+func WithRetries(retries int, delay time.Duration) Option {
+	return func(c *config) {
+		if retries >= 0 {
+			c.retries = retries
+		}
+		c.retryDelay = delay
+	}
+}
+
+// WithProgress sets the function which is called after each processed block.
+func WithProgress(fn func(Progress)) Option {
+	return func(c *config) {
+		c.progress = fn
+	}
+}
+
+// ForEachBlock calls fn for every block in range [from, to] in the order of block numbers. Blocks are fetched
+// concurrently. It stops at the first error returned by fn or the first block which couldn't be fetched.
+func ForEachBlock(ctx context.Context, r BlockReader, from, to *big.Int, fn func(ctx context.Context, b *ethereum.Block) error, opts ...Option) error {
+	if from == nil || to == nil {
+		return fmt.Errorf("blockrange: range bounds must be set")
+	}
+	if from.Cmp(to) > 0 {
+		return nil
+	}
+
+	cfg := config{
+		concurrency: 4,
+		retries:     3,
+		retryDelay:  time.Second,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		block *ethereum.Block
+		err   error
+	}
+
+	// pending holds results in the order of block numbers, its capacity limits the number of blocks in flight
+	pending := make(chan chan result, cfg.concurrency)
+	go func() {
+		defer close(pending)
+		for n := new(big.Int).Set(from); n.Cmp(to) <= 0; n = new(big.Int).Add(n, big.NewInt(1)) {
+			ch := make(chan result, 1)
+			select {
+			case pending <- ch:
+			case <-ctx.Done():
+				return
+			}
+
+			go func(number *big.Int) {
+				b, err := fetchBlock(ctx, r, number, &cfg)
+				ch <- result{block: b, err: err}
+			}(n)
+		}
+	}()
+
+	total := new(big.Int).Sub(to, from).Uint64() + 1
+	var processed uint64
+	for ch := range pending {
+		var res result
+		select {
+		case res = <-ch:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if res.err != nil {
+			return res.err
+		}
+
+		if err := fn(ctx, res.block); err != nil {
+			return err
+		}
+
+		processed++
+		if cfg.progress != nil {
+			cfg.progress(Progress{Number: res.block.Number, Processed: processed, Total: total})
+		}
+	}
+
+	return ctx.Err()
+}
+
+// ForEachTransaction calls fn for every transaction of blocks in range [from, to] in the order of block numbers
+// and transaction indexes. See ForEachBlock.
+func ForEachTransaction(ctx context.Context, r BlockReader, from, to *big.Int, fn func(ctx context.Context, b *ethereum.Block, tx *ethereum.Transaction) error, opts ...Option) error {
+	return ForEachBlock(ctx, r, from, to, func(ctx context.Context, b *ethereum.Block) error {
+		for _, tx := range b.Transactions {
+			if err := fn(ctx, b, tx); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, opts...)
+}
+
+func fetchBlock(ctx context.Context, r BlockReader, number *big.Int, cfg *config) (*ethereum.Block, error) {
+	for attempt := 0; ; attempt++ {
+		b, err := r.BlockByNumber(ctx, number)
+		if err == nil {
+			return b, nil
+		}
+		if attempt >= cfg.retries || ctx.Err() != nil {
+			return nil, fmt.Errorf("blockrange: BlockByNumber(%v): %v", number, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(cfg.retryDelay):
+		}
+	}
+}
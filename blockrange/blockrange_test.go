@@ -0,0 +1,98 @@
+package blockrange
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/monetha/go-ethereum"
+)
+
+type blockReaderMock struct {
+	mu       sync.Mutex
+	failures map[int64]int // number of failures before block is returned
+}
+
+func (m *blockReaderMock) BlockByNumber(ctx context.Context, number *big.Int) (*ethereum.Block, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.failures[number.Int64()] > 0 {
+		m.failures[number.Int64()]--
+		return nil, errors.New("temporary failure")
+	}
+	return &ethereum.Block{
+		Number:       new(big.Int).Set(number),
+		Transactions: ethereum.Transactions{{}, {}},
+	}, nil
+}
+
+func TestForEachBlock(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures map[int64]int
+		retries  int
+		wantErr  bool
+	}{
+		{"no failures", nil, 0, false},
+		{"retried failures", map[int64]int{12: 2}, 2, false},
+		{"too many failures", map[int64]int{12: 2}, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &blockReaderMock{failures: tt.failures}
+
+			var numbers []int64
+			var last Progress
+			err := ForEachBlock(context.Background(), r, big.NewInt(10), big.NewInt(20), func(ctx context.Context, b *ethereum.Block) error {
+				numbers = append(numbers, b.Number.Int64())
+				return nil
+			}, WithConcurrency(3), WithRetries(tt.retries, time.Millisecond), WithProgress(func(p Progress) { last = p }))
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error, but got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ForEachBlock: %v", err)
+			}
+
+			for i, n := range numbers {
+				if n != int64(10+i) {
+					t.Fatalf("expected blocks in order, but got %v", numbers)
+				}
+			}
+			if len(numbers) != 11 {
+				t.Errorf("expected 11 blocks, but got %v", len(numbers))
+			}
+			if last.Processed != 11 || last.Total != 11 || last.Number.Int64() != 20 {
+				t.Errorf("expected final progress 11/11 at block 20, but got %+v", last)
+			}
+		})
+	}
+}
+
+func TestForEachTransaction_StopsOnError(t *testing.T) {
+	stopErr := errors.New("stop")
+
+	count := 0
+	err := ForEachTransaction(context.Background(), &blockReaderMock{}, big.NewInt(0), big.NewInt(100), func(ctx context.Context, b *ethereum.Block, tx *ethereum.Transaction) error {
+		count++
+		if count == 5 {
+			return stopErr
+		}
+		return nil
+	})
+
+	if err != stopErr {
+		t.Errorf("expected stop error, but got %v", err)
+	}
+	if count != 5 {
+		t.Errorf("expected 5 transactions processed, but got %v", count)
+	}
+}
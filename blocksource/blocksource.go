@@ -2,13 +2,21 @@ package blocksource
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
 	"math/big"
 	"sync"
 	"time"
 
 	"github.com/monetha/go-ethereum"
+	"github.com/monetha/go-ethereum/addresset"
 	"github.com/monetha/go-ethereum/client"
+	"github.com/monetha/go-ethereum/cursor"
+	"github.com/monetha/go-ethereum/events"
+	"github.com/monetha/go-ethereum/health"
+	"github.com/monetha/go-ethereum/metrics"
+	"github.com/monetha/go-ethereum/progress"
 )
 
 // Config contains parameters of BlockSource.
@@ -20,15 +28,96 @@ type Config struct {
 	// Confirmations number indicates that the block must be delivered only when it has
 	// the specified number of confirmations (number of blocks mined since delivered block).
 	Confirmations uint
+	// Uncles indicates that headers of uncle blocks (ommers) must be retrieved for every delivered block.
+	Uncles bool
+	// Metrics receives delivered blocks, lag behind the chain head and RPC errors. Metrics are discarded when nil.
+	Metrics metrics.Collector
+	// HealthTimeout is the maximum duration since the last successful RPC call after which BlockSource
+	// is considered unhealthy. One minute is used when it's zero.
+	HealthTimeout time.Duration
+	// MaxLag is the maximum number of blocks (in addition to Confirmations) BlockSource may fall behind the
+	// chain head to be considered healthy. Lag isn't checked when it's zero.
+	MaxLag uint64
+	// ProbeCapabilities indicates that capabilities of the node must be probed at start, so that optimal requests
+	// are used (e.g. eth_getBlockReceipts to get receipts of delivered blocks).
+	ProbeCapabilities bool
+	// WaitForSync indicates that blocks must not be delivered while the node reports it is syncing, because
+	// its data may be stale. BlockSource is unhealthy meanwhile.
+	WaitForSync bool
+	// MinPeers is the minimum number of peers the node must have for blocks to be delivered. Number of peers
+	// isn't checked when it's zero.
+	MinPeers uint64
+	// BlockCache is used to get blocks, so it's filled for other clients sharing it (optional). It's reset when
+	// chain reorganization is detected, i.e. parent hash of the block doesn't match the hash of the previous one.
+	BlockCache *client.BlockCache
+	// Cursor is the position up to which blocks were processed before restart (optional). Delivery resumes
+	// from the block following the cursor (StartBlock is ignored), and chain reorganization is detected when
+	// the parent hash of the first delivered block doesn't match the hash of the cursor.
+	Cursor *cursor.Cursor
+	// Heads provides the latest block number (e.g. headtracker.Tracker shared by other components), otherwise
+	// it's requested from the node with eth_blockNumber (optional).
+	Heads HeadReader
+	// Finality decides whether the block is final (e.g. finality.Checker), blocks are delivered only when they're
+	// final, in addition to Confirmations (optional).
+	Finality FinalityChecker
+	// LogFilters describe logs the consumer is interested in (optional). Receipts of the block aren't retrieved
+	// when its logs bloom shows it can't contain logs matching any of them (see client.Client.SetLogFilters).
+	LogFilters []client.LogFilter
+	// Addresses are watched addresses (optional). When set, delivered blocks include only transactions sent from
+	// or to any of them, or creating contracts at them. Blocks without such transactions are delivered too,
+	// so that processing progress (e.g. the cursor) advances.
+	Addresses *addresset.Set
+	// Events receives NewBlock event for every delivered block and ReorgDetected event when chain reorganization
+	// is detected (optional).
+	Events *events.Bus
+	// ProgressLogInterval is the interval of logging progress (see BlockSource.Progress) while BlockSource is more
+	// than one block behind the chain head (DefaultProgressLogInterval when it's zero). Progress isn't logged when
+	// it's negative.
+	ProgressLogInterval time.Duration
+	// Provisional indicates that blocks must be delivered on Updates as soon as they're at the chain head, with
+	// Provisional status, and again with Confirmed status when they have Confirmations (and are final), or with
+	// Retracted status when they're replaced by chain reorganization. Blocks before the chain head at start are
+	// delivered as confirmed only. C isn't used in this mode.
+	Provisional bool
 }
 
+// HeadReader returns the number of the latest block, it's implemented by headtracker.Tracker and client.Client.
+type HeadReader interface {
+	BlockNumber(ctx context.Context) (*big.Int, error)
+}
+
+// FinalityChecker tells whether the block is final, it's implemented by finality.Checker.
+type FinalityChecker interface {
+	IsFinal(ctx context.Context, block *big.Int) (bool, error)
+}
+
+// DefaultHealthTimeout is used when Config.HealthTimeout is zero.
+const DefaultHealthTimeout = time.Minute
+
+// DefaultProgressLogInterval is used when Config.ProgressLogInterval is zero.
+const DefaultProgressLogInterval = time.Minute
+
+// capabilitiesProbeTimeout limits the time of probing node capabilities.
+const capabilitiesProbeTimeout = 30 * time.Second
+
+// nodeCheckInterval is the interval of checking sync status and peer count of the node.
+const nodeCheckInterval = 10 * time.Second
+
 // BlockSource holds a channel that delivers blocks from Ethereum channel.
 type BlockSource struct {
 	C         <-chan *ethereum.Block // The channel on which the blocks are delivered.
 	client    *client.Client
+	cfg       Config
+	mu        sync.RWMutex
+	status    health.Status
+	nodeIssue string // why the node isn't ready to deliver blocks, empty if it's ready
+	nodeCheck time.Time
+	meter     *progress.Meter
+	updates   <-chan *Update
 	wg        sync.WaitGroup
 	closeOnce sync.Once
 	closed    chan struct{}
+	ownClient bool // client is closed by Close
 }
 
 // New returns a new BlockSource containing a channel that will deliver the blocks from Ethereum network.
@@ -38,19 +127,66 @@ func New(rawurl string, cfg *Config) (*BlockSource, error) {
 		return nil, err
 	}
 
-	ch := make(chan *ethereum.Block)
-	bs := &BlockSource{
-		C:      ch,
-		client: cl,
-		closed: make(chan struct{}),
+	return newBlockSource(cl, cfg, true), nil
+}
+
+// NewWithClient returns a new BlockSource like New, but it uses the given client, so that one RPC connection
+// can be shared with other components. The client isn't closed by Close. Capabilities, block cache and log filters
+// of the config are set to the client.
+func NewWithClient(cl *client.Client, cfg *Config) (*BlockSource, error) {
+	if cl == nil {
+		return nil, errors.New("blocksource: client is nil")
 	}
+	return newBlockSource(cl, cfg, false), nil
+}
 
+func newBlockSource(cl *client.Client, cfg *Config, ownClient bool) *BlockSource {
 	if cfg == nil {
 		cfg = &Config{}
 	}
-	bs.runAsync(cfg, ch)
 
-	return bs, nil
+	if cfg.ProbeCapabilities {
+		ctx, cancel := context.WithTimeout(context.Background(), capabilitiesProbeTimeout)
+		caps, err := cl.Capabilities(ctx)
+		cancel()
+		if err != nil {
+			log.Printf("Capabilities: %v", err)
+		} else {
+			cl.SetCapabilities(caps)
+		}
+	}
+
+	if cfg.BlockCache != nil {
+		cl.SetBlockCache(cfg.BlockCache)
+	}
+	if len(cfg.LogFilters) > 0 {
+		cl.SetLogFilters(cfg.LogFilters...)
+	}
+
+	ch := make(chan *ethereum.Block)
+	bs := &BlockSource{
+		C:         ch,
+		client:    cl,
+		cfg:       *cfg,
+		closed:    make(chan struct{}),
+		ownClient: ownClient,
+		meter:     progress.NewMeter(0),
+	}
+	if bs.cfg.HealthTimeout == 0 {
+		bs.cfg.HealthTimeout = DefaultHealthTimeout
+	}
+	if bs.cfg.ProgressLogInterval == 0 {
+		bs.cfg.ProgressLogInterval = DefaultProgressLogInterval
+	}
+	var prov *provisional
+	if bs.cfg.Provisional {
+		updates := make(chan *Update)
+		bs.updates = updates
+		prov = newProvisional(updates)
+	}
+	bs.runAsync(&bs.cfg, ch, prov)
+
+	return bs
 }
 
 // Blocks returns the channel on which the blocks are delivered.
@@ -58,37 +194,117 @@ func (bs *BlockSource) Blocks() <-chan *ethereum.Block {
 	return bs.C
 }
 
-// Close implements io.Closer interface.
+// Updates returns the channel on which the blocks are delivered when Config.Provisional is set, otherwise nil.
+func (bs *BlockSource) Updates() <-chan *Update {
+	return bs.updates
+}
+
+// Status returns the time of the last successful RPC call and the lag behind the chain head.
+func (bs *BlockSource) Status() health.Status {
+	bs.mu.RLock()
+	defer bs.mu.RUnlock()
+	return bs.status
+}
+
+// Progress returns the progress of catching up with the chain head less Confirmations. It's known after the first
+// block is delivered; with zero Confirmations the head is retrieved for it at most once per 10 seconds.
+func (bs *BlockSource) Progress() progress.Progress {
+	return bs.meter.Progress()
+}
+
+// Healthy implements health.Healthier interface.
+func (bs *BlockSource) Healthy(ctx context.Context) error {
+	select {
+	case <-bs.closed:
+		return &health.UnhealthyError{Component: "blocksource", Reason: "closed", Status: bs.Status()}
+	default:
+	}
+
+	st := bs.Status()
+	if issue := bs.nodeIssueStatus(); issue != "" {
+		return &health.UnhealthyError{Component: "blocksource", Reason: issue, Status: st}
+	}
+	if time.Since(st.LastSuccess) > bs.cfg.HealthTimeout {
+		return &health.UnhealthyError{Component: "blocksource", Reason: "no successful RPC calls", Status: st}
+	}
+	if bs.cfg.MaxLag > 0 && st.Lag > uint64(bs.cfg.Confirmations)+bs.cfg.MaxLag {
+		return &health.UnhealthyError{Component: "blocksource", Reason: "too far behind the chain head", Status: st}
+	}
+
+	return nil
+}
+
+// Close implements io.Closer interface. It waits until the delivery of blocks stops and closes the client dialed
+// by New, the client passed to NewWithClient isn't closed.
 func (bs *BlockSource) Close() (err error) {
 	bs.closeOnce.Do(func() {
 		close(bs.closed)
 
 		bs.wg.Wait()
 
-		err = bs.client.Close()
+		if bs.ownClient {
+			err = bs.client.Close()
+		}
 	})
 
 	return
 }
 
-func (bs *BlockSource) runAsync(cfg *Config, blocks chan *ethereum.Block) {
+func (bs *BlockSource) runAsync(cfg *Config, blocks chan *ethereum.Block, prov *provisional) {
 	ctx, cancel := context.WithCancel(context.Background())
 	bs.cancelOnClose(cancel)
 
+	var senders sync.WaitGroup
+	if prov != nil {
+		senders.Add(1)
+		bs.wg.Add(1)
+		go func() {
+			defer bs.wg.Done()
+			defer senders.Done()
+			bs.followHead(ctx, cfg, prov)
+		}()
+
+		bs.wg.Add(1)
+		go func() {
+			defer bs.wg.Done()
+			senders.Wait()
+			close(prov.updates)
+		}()
+	}
+
+	senders.Add(1)
 	bs.wg.Add(1)
 	go func() {
 		defer bs.wg.Done()
+		defer senders.Done()
 		defer close(blocks) // close blocks when closed
 
 		one := big.NewInt(1)
 
 		var recentBlkNumber *big.Int
 		var currBlkNumber *big.Int
+		var prevBlk *ethereum.Block // the last delivered block
 		if cfg.StartBlock != nil {
 			currBlkNumber = new(big.Int).Set(cfg.StartBlock) // copy start block number
 		}
+		if c := cfg.Cursor; c != nil && !c.IsZero() {
+			currBlkNumber = c.ResumeBlock()
+			if c.LogIndex == cursor.EndOfBlock {
+				prevBlk = &ethereum.Block{Number: new(big.Int).SetUint64(c.BlockNumber), Hash: c.BlockHash}
+			}
+		}
 		confirmations := big.NewInt(int64(cfg.Confirmations))
+		heads := cfg.Heads
+		if heads == nil {
+			heads = bs.client
+		}
+		m := cfg.Metrics
+		if m == nil {
+			m = metrics.Nop
+		}
 
+		var headCheck time.Time
+		progressLog := time.Now()
 		delayBeforeIteration := false
 		for {
 			if delayBeforeIteration {
@@ -102,12 +318,17 @@ func (bs *BlockSource) runAsync(cfg *Config, blocks chan *ethereum.Block) {
 
 			if needToGetMostRecentBlockNumber(currBlkNumber, recentBlkNumber, confirmations) {
 				var err error
-				recentBlkNumber, err = bs.client.BlockNumber(ctx)
+				recentBlkNumber, err = heads.BlockNumber(ctx)
 				if err != nil {
 					log.Printf("BlockNumber: %v", err)
+					m.RPCError("blocksource", "eth_blockNumber")
 					delayBeforeIteration = true
 					continue
 				}
+				bs.rpcSucceeded()
+				if cfg.Heads != nil && cfg.BlockCache != nil {
+					cfg.BlockCache.SetHead(recentBlkNumber) // the client doesn't see the head
+				}
 				if needToGetMostRecentBlockNumber(currBlkNumber, recentBlkNumber, confirmations) {
 					delayBeforeIteration = true
 					continue
@@ -118,29 +339,197 @@ func (bs *BlockSource) runAsync(cfg *Config, blocks chan *ethereum.Block) {
 				currBlkNumber = new(big.Int).Sub(recentBlkNumber, confirmations)
 			}
 
-			b, err := bs.client.BlockByNumber(ctx, currBlkNumber)
+			if !bs.nodeReady(ctx, cfg, m) {
+				delayBeforeIteration = true
+				continue
+			}
+
+			if cfg.Finality != nil {
+				final, err := cfg.Finality.IsFinal(ctx, currBlkNumber)
+				if err != nil {
+					log.Printf("IsFinal: %v", err)
+				}
+				if !final {
+					delayBeforeIteration = true
+					continue
+				}
+			}
+
+			var b *ethereum.Block
+			var err error
+			if cfg.Uncles {
+				b, err = bs.client.BlockByNumberWithUncles(ctx, currBlkNumber)
+			} else {
+				b, err = bs.client.BlockByNumber(ctx, currBlkNumber)
+			}
 			if err != nil {
 				if err != ethereum.ErrNotFound { // when block isn't found it's ok, we just need to wait more
 					log.Printf("BlockByNumber: %v", err)
+					m.RPCError("blocksource", "eth_getBlockByNumber")
+				} else {
+					bs.rpcSucceeded()
+					if confirmations.Sign() == 0 {
+						m.HeadLag(0) // block isn't mined yet, so the delivered one is the head
+						bs.setLag(0)
+					}
 				}
 				delayBeforeIteration = true
 				continue
 			} else {
+				bs.rpcSucceeded()
+
+				if isReorg(prevBlk, b) {
+					log.Printf("Chain reorganization detected at block %v", b.Number)
+					if cfg.BlockCache != nil {
+						cfg.BlockCache.Reset()
+					}
+					cfg.Events.Publish(events.ReorgDetected{Number: b.Number, Hash: b.Hash, PrevHash: prevBlk.Hash})
+				}
+				prevBlk = b
+
 				// increment currBlkNumber
 				currBlkNumber = new(big.Int).Add(b.Number, one)
 
 				// deliver new block
-				select {
-				case <-ctx.Done():
-					return
-				case blocks <- b:
+				delivered := b
+				if cfg.Addresses != nil {
+					delivered = filterTransactions(b, cfg.Addresses)
+				}
+				if prov != nil {
+					if !prov.confirm(ctx, delivered) {
+						return
+					}
+				} else {
+					select {
+					case <-ctx.Done():
+						return
+					case blocks <- delivered:
+					}
+				}
+
+				m.BlockDelivered(b.Number)
+				cfg.Events.Publish(events.NewBlock{Number: b.Number, Hash: b.Hash})
+				if confirmations.Sign() == 0 && time.Since(headCheck) >= nodeCheckInterval {
+					// the head isn't needed for delivery, but for the lag and progress
+					headCheck = time.Now()
+					if n, err := heads.BlockNumber(ctx); err != nil {
+						log.Printf("BlockNumber: %v", err)
+						m.RPCError("blocksource", "eth_blockNumber")
+					} else {
+						recentBlkNumber = n
+					}
+				}
+				if recentBlkNumber != nil && recentBlkNumber.Cmp(b.Number) >= 0 {
+					lag := new(big.Int).Sub(recentBlkNumber, b.Number).Uint64()
+					m.HeadLag(lag)
+					bs.setLag(lag)
+				}
+				if recentBlkNumber != nil && recentBlkNumber.Cmp(confirmations) >= 0 {
+					head := new(big.Int).Sub(recentBlkNumber, confirmations)
+					bs.meter.Update(b.Number.Uint64(), head.Uint64())
+					if interval := cfg.ProgressLogInterval; interval > 0 && time.Since(progressLog) >= interval {
+						progressLog = time.Now()
+						if p := bs.meter.Progress(); p.Behind > 1 {
+							log.Printf("Catching up with the chain head: %v", p)
+						}
+					}
 				}
 			}
 		}
 	}()
 }
 
+// nodeReady checks (at most once per nodeCheckInterval) that the node isn't syncing and has enough peers
+// when it's required by the config.
+func (bs *BlockSource) nodeReady(ctx context.Context, cfg *Config, m metrics.Collector) bool {
+	if !cfg.WaitForSync && cfg.MinPeers == 0 {
+		return true
+	}
+
+	bs.mu.RLock()
+	issue, checked := bs.nodeIssue, bs.nodeCheck
+	bs.mu.RUnlock()
+	if issue == "" && time.Since(checked) < nodeCheckInterval {
+		return true
+	}
+
+	issue = ""
+	if cfg.WaitForSync {
+		progress, err := bs.client.SyncProgress(ctx)
+		if err != nil {
+			log.Printf("SyncProgress: %v", err)
+			m.RPCError("blocksource", "eth_syncing")
+			return false
+		}
+		if progress != nil {
+			issue = fmt.Sprintf("node is syncing (block %v of %v)", progress.CurrentBlock, progress.HighestBlock)
+		}
+	}
+	if issue == "" && cfg.MinPeers > 0 {
+		peers, err := bs.client.PeerCount(ctx)
+		if err != nil {
+			log.Printf("PeerCount: %v", err)
+			m.RPCError("blocksource", "net_peerCount")
+			return false
+		}
+		if peers < cfg.MinPeers {
+			issue = fmt.Sprintf("node has %v peers, at least %v required", peers, cfg.MinPeers)
+		}
+	}
+
+	bs.mu.Lock()
+	bs.nodeIssue = issue
+	bs.nodeCheck = time.Now()
+	bs.mu.Unlock()
+
+	if issue != "" {
+		log.Printf("Not delivering blocks: %v", issue)
+	}
+	return issue == ""
+}
+
+func (bs *BlockSource) nodeIssueStatus() string {
+	bs.mu.RLock()
+	defer bs.mu.RUnlock()
+	return bs.nodeIssue
+}
+
+func (bs *BlockSource) rpcSucceeded() {
+	bs.mu.Lock()
+	bs.status.LastSuccess = time.Now()
+	bs.mu.Unlock()
+}
+
+func (bs *BlockSource) setLag(lag uint64) {
+	bs.mu.Lock()
+	bs.status.Lag = lag
+	bs.mu.Unlock()
+}
+
+// filterTransactions returns a copy of the block with transactions touching any of the addresses, the block
+// itself isn't modified, since it may be shared by the block cache.
+func filterTransactions(b *ethereum.Block, addrs *addresset.Set) *ethereum.Block {
+	fb := *b
+	fb.Transactions = make(ethereum.Transactions, 0)
+	for _, tx := range b.Transactions {
+		if addrs.ContainsAny(&tx.From, tx.To, tx.ContractAddress) {
+			fb.Transactions = append(fb.Transactions, tx)
+		}
+	}
+	return &fb
+}
+
+// isReorg tells whether the block doesn't follow the previous one, though its number is the next one.
+func isReorg(prev, b *ethereum.Block) bool {
+	return prev != nil &&
+		new(big.Int).Add(prev.Number, big.NewInt(1)).Cmp(b.Number) == 0 &&
+		b.ParentHash != prev.Hash
+}
+
 func needToGetMostRecentBlockNumber(currentBlockNumber, recentBlockNumber, confirmations *big.Int) bool {
+	if currentBlockNumber == nil && recentBlockNumber == nil {
+		return true // the head is the start block
+	}
 	// confirmations > 0
 	return confirmations.Sign() == 1 &&
 		(recentBlockNumber == nil ||
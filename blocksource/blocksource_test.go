@@ -0,0 +1,61 @@
+package blocksource
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/monetha/go-ethereum"
+	"github.com/monetha/go-ethereum/addresset"
+)
+
+func TestFilterTransactions(t *testing.T) {
+	var (
+		watched  = common.HexToAddress("0x01")
+		other    = common.HexToAddress("0x02")
+		contract = common.HexToAddress("0x03")
+	)
+	b := &ethereum.Block{
+		Number: big.NewInt(1),
+		Transactions: ethereum.Transactions{
+			{Hash: common.HexToHash("0x10"), From: watched, To: &other},
+			{Hash: common.HexToHash("0x11"), From: other, To: &other},
+			{Hash: common.HexToHash("0x12"), From: other, To: &watched},
+			{Hash: common.HexToHash("0x13"), From: other, ContractAddress: &contract},
+		},
+	}
+
+	fb := filterTransactions(b, addresset.New([]common.Address{watched, contract}))
+	if len(fb.Transactions) != 3 {
+		t.Fatalf("expected 3 transactions, but got %v", len(fb.Transactions))
+	}
+	for i, hash := range []string{"0x10", "0x12", "0x13"} {
+		if fb.Transactions[i].Hash != common.HexToHash(hash) {
+			t.Errorf("expected transaction %v at position %v, but got %v", hash, i, fb.Transactions[i].Hash.Hex())
+		}
+	}
+	if len(b.Transactions) != 4 {
+		t.Errorf("expected original block to be unchanged, but got %v transactions", len(b.Transactions))
+	}
+}
+
+func TestNeedToGetMostRecentBlockNumber(t *testing.T) {
+	tests := []struct {
+		name                   string
+		current, recent, confs *big.Int
+		expected               bool
+	}{
+		{"no start block", nil, nil, big.NewInt(0), true},
+		{"no start block with confirmations", nil, nil, big.NewInt(2), true},
+		{"start block at head", nil, big.NewInt(10), big.NewInt(0), false},
+		{"start block", big.NewInt(5), nil, big.NewInt(0), false},
+		{"head unknown", big.NewInt(5), nil, big.NewInt(2), true},
+		{"confirmed", big.NewInt(5), big.NewInt(7), big.NewInt(2), false},
+		{"not confirmed", big.NewInt(6), big.NewInt(7), big.NewInt(2), true},
+	}
+	for _, tt := range tests {
+		if got := needToGetMostRecentBlockNumber(tt.current, tt.recent, tt.confs); got != tt.expected {
+			t.Errorf("%v: expected %v, but got %v", tt.name, tt.expected, got)
+		}
+	}
+}
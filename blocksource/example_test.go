@@ -5,6 +5,8 @@ import (
 	"math/big"
 
 	"github.com/monetha/go-ethereum/blocksource"
+	"github.com/monetha/go-ethereum/client"
+	"github.com/monetha/go-ethereum/gasestimator"
 )
 
 func ExampleNew() {
@@ -23,3 +25,32 @@ func ExampleNew() {
 		}
 	}
 }
+
+func ExampleNewWithClient() {
+	cl, err := client.Dial("https://mainnet.infura.io/")
+	if err != nil {
+		panic(err)
+	}
+	defer cl.Close()
+
+	// the connection is shared with the gas price estimator
+	estimator, err := gasestimator.NewGasPriceEstimatorWithClient(cl.RPC())
+	if err != nil {
+		panic(err)
+	}
+	defer estimator.Close()
+
+	source, err := blocksource.NewWithClient(cl, &blocksource.Config{Confirmations: 1})
+	if err != nil {
+		panic(err)
+	}
+
+	i := 0
+	for b := range source.Blocks() {
+		i++
+		fmt.Printf("New block arrived: %v, gas price: %v\n", b.Number, estimator.SuggestGasPrice())
+		if i == 10 {
+			source.Close()
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package blocksource
+
+import (
+	"context"
+	"log"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/monetha/go-ethereum"
+	"github.com/monetha/go-ethereum/metrics"
+)
+
+// BlockStatus tells how the block is delivered in Provisional mode.
+type BlockStatus int
+
+const (
+	// Provisional block is at the chain head, it may be replaced by chain reorganization.
+	Provisional BlockStatus = iota
+	// Confirmed block has Confirmations (and is final when Finality is set), as blocks delivered on C.
+	Confirmed
+	// Retracted block was delivered provisionally, but it was replaced by chain reorganization.
+	Retracted
+)
+
+func (s BlockStatus) String() string {
+	switch s {
+	case Provisional:
+		return "provisional"
+	case Confirmed:
+		return "confirmed"
+	case Retracted:
+		return "retracted"
+	}
+	return "unknown"
+}
+
+// Update is the block delivered in Provisional mode.
+type Update struct {
+	Block  *ethereum.Block
+	Status BlockStatus
+}
+
+// provisional delivers blocks at the chain head before they're confirmed (see Config.Provisional). Updates are
+// sent under the lock, so the consumer gets them in order: retractions of replaced blocks before their
+// replacements, confirmation after the provisional delivery.
+type provisional struct {
+	mu        sync.Mutex
+	updates   chan<- *Update
+	blocks    map[uint64]*ethereum.Block // delivered provisionally, not confirmed yet
+	confirmed *big.Int                   // number of the last confirmed block, nil when none
+	rewind    *big.Int                   // the block head following must continue from, nil when not needed
+}
+
+func newProvisional(updates chan<- *Update) *provisional {
+	return &provisional{
+		updates: updates,
+		blocks:  make(map[uint64]*ethereum.Block),
+	}
+}
+
+// deliver delivers the block at the head provisionally, unless it's confirmed already. When the block doesn't
+// follow the previous provisional one, the previous one is retracted instead, and the block preceding it must be
+// delivered next. It returns false when the context is done.
+func (p *provisional) deliver(ctx context.Context, b *ethereum.Block) (next *big.Int, ok bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	n := b.Number.Uint64()
+	next = new(big.Int).Add(b.Number, big.NewInt(1))
+	if p.confirmed != nil && b.Number.Cmp(p.confirmed) <= 0 {
+		return next, true
+	}
+	if prev, found := p.blocks[n-1]; found && b.ParentHash != prev.Hash {
+		delete(p.blocks, n-1)
+		return prev.Number, p.send(ctx, &Update{Block: prev, Status: Retracted})
+	}
+
+	p.blocks[n] = b
+	return next, p.send(ctx, &Update{Block: b, Status: Provisional})
+}
+
+// confirm delivers the confirmed block, provisional blocks it replaces are retracted.
+func (p *provisional) confirm(ctx context.Context, b *ethereum.Block) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	n := b.Number.Uint64()
+	if pb, found := p.blocks[n]; found && pb.Hash != b.Hash {
+		// provisional descendants of the replaced block are replaced too
+		if !p.retractFrom(ctx, n) {
+			return false
+		}
+		p.rewind = new(big.Int).Add(b.Number, big.NewInt(1))
+	}
+	delete(p.blocks, n)
+	if next, found := p.blocks[n+1]; found && next.ParentHash != b.Hash {
+		if !p.retractFrom(ctx, n+1) {
+			return false
+		}
+		p.rewind = new(big.Int).Add(b.Number, big.NewInt(1))
+	}
+	p.confirmed = b.Number
+
+	return p.send(ctx, &Update{Block: b, Status: Confirmed})
+}
+
+// retractFrom retracts provisional blocks starting from the number, the latest first.
+func (p *provisional) retractFrom(ctx context.Context, n uint64) bool {
+	var numbers []uint64
+	for number := range p.blocks {
+		if number >= n {
+			numbers = append(numbers, number)
+		}
+	}
+	sort.Slice(numbers, func(i, j int) bool { return numbers[i] > numbers[j] })
+
+	for _, number := range numbers {
+		b := p.blocks[number]
+		delete(p.blocks, number)
+		if !p.send(ctx, &Update{Block: b, Status: Retracted}) {
+			return false
+		}
+	}
+	return true
+}
+
+// nextBlock returns the block head following continues from: the given one, unless blocks were retracted on
+// confirmation or the given one is confirmed already.
+func (p *provisional) nextBlock(next *big.Int) *big.Int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.rewind != nil {
+		if next == nil || p.rewind.Cmp(next) < 0 {
+			next = p.rewind
+		}
+		p.rewind = nil
+	}
+	if p.confirmed != nil && next != nil && next.Cmp(p.confirmed) <= 0 {
+		next = new(big.Int).Add(p.confirmed, big.NewInt(1))
+	}
+	return next
+}
+
+func (p *provisional) send(ctx context.Context, u *Update) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case p.updates <- u:
+		return true
+	}
+}
+
+// followHead delivers blocks at the chain head provisionally.
+func (bs *BlockSource) followHead(ctx context.Context, cfg *Config, p *provisional) {
+	heads := cfg.Heads
+	if heads == nil {
+		heads = bs.client
+	}
+	m := cfg.Metrics
+	if m == nil {
+		m = metrics.Nop
+	}
+
+	var next *big.Int // the next block to deliver, nil until the head is known
+	var delay time.Duration
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+			delay = 4 * time.Second
+		}
+
+		head, err := heads.BlockNumber(ctx)
+		if err != nil {
+			log.Printf("BlockNumber: %v", err)
+			m.RPCError("blocksource", "eth_blockNumber")
+			continue
+		}
+		if next == nil {
+			next = head // start at the head, blocks before it are delivered as confirmed only
+		}
+
+		for next = p.nextBlock(next); next.Cmp(head) <= 0; next = p.nextBlock(next) {
+			var b *ethereum.Block
+			if cfg.Uncles {
+				b, err = bs.client.BlockByNumberWithUncles(ctx, next)
+			} else {
+				b, err = bs.client.BlockByNumber(ctx, next)
+			}
+			if err != nil {
+				if err != ethereum.ErrNotFound {
+					log.Printf("BlockByNumber: %v", err)
+					m.RPCError("blocksource", "eth_getBlockByNumber")
+				}
+				break
+			}
+
+			if cfg.Addresses != nil {
+				b = filterTransactions(b, cfg.Addresses)
+			}
+			var ok bool
+			if next, ok = p.deliver(ctx, b); !ok {
+				return
+			}
+		}
+	}
+}
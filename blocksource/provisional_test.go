@@ -0,0 +1,144 @@
+package blocksource
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/monetha/go-ethereum"
+)
+
+// chainBlock returns the block of the fork, fork of the parent is given when it differs.
+func chainBlock(number int64, fork string, parentFork ...string) *ethereum.Block {
+	pf := fork
+	if len(parentFork) > 0 {
+		pf = parentFork[0]
+	}
+	return &ethereum.Block{
+		Number:     big.NewInt(number),
+		Hash:       common.BytesToHash([]byte(fmt.Sprintf("%v%v", number, fork))),
+		ParentHash: common.BytesToHash([]byte(fmt.Sprintf("%v%v", number-1, pf))),
+	}
+}
+
+func receiveUpdates(updates chan *Update) []string {
+	var res []string
+	for {
+		select {
+		case u := <-updates:
+			res = append(res, fmt.Sprintf("%v %v", u.Status, u.Block.Number))
+		default:
+			return res
+		}
+	}
+}
+
+func TestProvisional_Reorg(t *testing.T) {
+	ctx := context.Background()
+	updates := make(chan *Update, 100)
+	p := newProvisional(updates)
+
+	blocks := []*ethereum.Block{
+		chainBlock(10, "a"),
+		chainBlock(11, "a"),
+		chainBlock(12, "a"),
+		chainBlock(13, "b"), // replaces 12
+		chainBlock(12, "b", "a"),
+		chainBlock(13, "b"),
+	}
+	var nexts []int64
+	for _, b := range blocks {
+		next, ok := p.deliver(ctx, b)
+		if !ok {
+			t.Fatal("expected delivery")
+		}
+		nexts = append(nexts, next.Int64())
+	}
+	if !p.confirm(ctx, chainBlock(10, "a")) {
+		t.Fatal("expected confirmation")
+	}
+	// stale block at the head is ignored
+	if next, _ := p.deliver(ctx, chainBlock(10, "c")); next.Int64() != 11 {
+		t.Errorf("expected next block 11, but got %v", next)
+	}
+
+	if expected := []int64{11, 12, 13, 12, 13, 14}; !reflect.DeepEqual(expected, nexts) {
+		t.Errorf("expected next blocks %v, but got %v", expected, nexts)
+	}
+	expected := []string{
+		"provisional 10",
+		"provisional 11",
+		"provisional 12",
+		"retracted 12",
+		"provisional 12",
+		"provisional 13",
+		"confirmed 10",
+	}
+	if got := receiveUpdates(updates); !reflect.DeepEqual(expected, got) {
+		t.Errorf("expected updates %v, but got %v", expected, got)
+	}
+}
+
+func TestProvisional_ConfirmReplaced(t *testing.T) {
+	ctx := context.Background()
+	updates := make(chan *Update, 100)
+	p := newProvisional(updates)
+
+	for _, b := range []*ethereum.Block{chainBlock(10, "a"), chainBlock(11, "a"), chainBlock(12, "a")} {
+		if _, ok := p.deliver(ctx, b); !ok {
+			t.Fatal("expected delivery")
+		}
+	}
+	if !p.confirm(ctx, chainBlock(10, "a")) || !p.confirm(ctx, chainBlock(11, "b", "a")) {
+		t.Fatal("expected confirmation")
+	}
+
+	expected := []string{
+		"provisional 10",
+		"provisional 11",
+		"provisional 12",
+		"confirmed 10",
+		"retracted 12",
+		"retracted 11",
+		"confirmed 11",
+	}
+	if got := receiveUpdates(updates); !reflect.DeepEqual(expected, got) {
+		t.Errorf("expected updates %v, but got %v", expected, got)
+	}
+	if next := p.nextBlock(big.NewInt(13)); next.Int64() != 12 {
+		t.Errorf("expected head following to continue from block 12, but got %v", next)
+	}
+	if next := p.nextBlock(big.NewInt(13)); next.Int64() != 13 {
+		t.Errorf("expected head following to continue from block 13, but got %v", next)
+	}
+	if next := p.nextBlock(big.NewInt(5)); next.Int64() != 12 {
+		t.Errorf("expected head following to skip confirmed blocks, but got %v", next)
+	}
+
+	// the confirmed block doesn't match the provisional descendant
+	if _, ok := p.deliver(ctx, chainBlock(12, "c", "a")); !ok {
+		t.Fatal("expected delivery")
+	}
+	if !p.confirm(ctx, chainBlock(12, "b")) {
+		t.Fatal("expected confirmation")
+	}
+	expected = []string{"provisional 12", "retracted 12", "confirmed 12"}
+	if got := receiveUpdates(updates); !reflect.DeepEqual(expected, got) {
+		t.Errorf("expected updates %v, but got %v", expected, got)
+	}
+}
+
+func TestProvisional_Closed(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	p := newProvisional(make(chan *Update))
+	if _, ok := p.deliver(ctx, chainBlock(1, "a")); ok {
+		t.Error("expected delivery to stop when the context is done")
+	}
+	if p.confirm(ctx, chainBlock(1, "a")) {
+		t.Error("expected confirmation to stop when the context is done")
+	}
+}
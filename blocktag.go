@@ -0,0 +1,29 @@
+package ethereum
+
+import (
+	"math/big"
+
+	"github.com/monetha/go-ethereum/blocktag"
+)
+
+// Block tags can be used wherever a block number is expected (e.g. client.Client.BlockByNumber or
+// BalanceAt of backend.Backend) to refer to a block by its position relative to the chain head
+// instead of by its number. Tags are represented by negative numbers, so they never clash with real block numbers.
+var (
+	// PendingBlockNumber refers to the pending block (the block currently being mined).
+	PendingBlockNumber = big.NewInt(blocktag.Pending)
+	// LatestBlockNumber refers to the most recent block of the canonical chain.
+	LatestBlockNumber = big.NewInt(blocktag.Latest)
+	// FinalizedBlockNumber refers to the most recent block accepted as finalized by the consensus layer.
+	FinalizedBlockNumber = big.NewInt(blocktag.Finalized)
+	// SafeBlockNumber refers to the most recent block considered safe from re-orgs by the consensus layer.
+	SafeBlockNumber = big.NewInt(blocktag.Safe)
+	// EarliestBlockNumber refers to the genesis block (or the earliest block available on pruned nodes).
+	EarliestBlockNumber = big.NewInt(blocktag.Earliest)
+)
+
+// BlockNumberTag returns the name of the block tag used in JSON-RPC requests ("pending", "latest",
+// "finalized", "safe" or "earliest"). It returns false if number is not a block tag.
+func BlockNumberTag(number *big.Int) (tag string, ok bool) {
+	return blocktag.Name(number)
+}
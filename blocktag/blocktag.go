@@ -0,0 +1,38 @@
+// Package blocktag defines block tags, the negative block numbers which refer to a block by its position relative
+// to the chain head (see ethereum.LatestBlockNumber etc.). It has no dependencies, so packages which can't import
+// the root package (e.g. backend and gasestimator) use the same values.
+package blocktag
+
+import "math/big"
+
+// Block tags, see ethereum.PendingBlockNumber etc. for the meaning.
+const (
+	Pending   int64 = -1
+	Latest    int64 = -2
+	Finalized int64 = -3
+	Safe      int64 = -4
+	Earliest  int64 = -5
+)
+
+var names = map[int64]string{
+	Pending:   "pending",
+	Latest:    "latest",
+	Finalized: "finalized",
+	Safe:      "safe",
+	Earliest:  "earliest",
+}
+
+// Name returns the name of the block tag used in JSON-RPC requests ("pending", "latest", "finalized", "safe"
+// or "earliest"). It returns false if number is not a block tag.
+func Name(number *big.Int) (name string, ok bool) {
+	if number == nil || number.Sign() >= 0 || !number.IsInt64() {
+		return "", false
+	}
+	name, ok = names[number.Int64()]
+	return
+}
+
+// Is tells whether number is the block tag.
+func Is(number *big.Int, tag int64) bool {
+	return number != nil && number.IsInt64() && number.Int64() == tag
+}
@@ -0,0 +1,132 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// DefaultBatchSize is the maximum number of requests in a single batch used when the size isn't set
+// (see SetBatchSize).
+const DefaultBatchSize = 500
+
+// BatchError is returned by BatchCaller.Call when some requests failed even when retried individually,
+// other requests of the batch succeeded. Errors are also set to BatchElem.Error of failed requests.
+type BatchError struct {
+	Total  int
+	Errors map[int]error // by index of the request
+}
+
+func (e *BatchError) Error() string {
+	indexes := make([]int, 0, len(e.Errors))
+	for i := range e.Errors {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+
+	msgs := make([]string, 0, 3)
+	for _, i := range indexes {
+		if len(msgs) == cap(msgs) {
+			msgs = append(msgs, "...")
+			break
+		}
+		msgs = append(msgs, fmt.Sprintf("request %d: %v", i, e.Errors[i]))
+	}
+	return fmt.Sprintf("%d of %d batch requests failed: %v", len(e.Errors), e.Total, strings.Join(msgs, ", "))
+}
+
+// BatchCaller sends RPC requests in batches of limited size, since providers limit the size of batches differently.
+// When the provider rejects the batch as too large (see isBatchLimitError), it's split in halves and retried,
+// smaller batches are used for subsequent calls. Other failures of the whole batch (e.g. the node is unavailable)
+// are returned without changing the size. Requests which failed in a successful batch are retried individually
+// once. It's safe for concurrent use.
+type BatchCaller struct {
+	c    *Client
+	size int64 // accessed atomically
+}
+
+// NewBatchCaller creates an instance of BatchCaller which sends batches of at most `size` requests
+// (DefaultBatchSize if size is 0).
+func NewBatchCaller(c *Client, size int) *BatchCaller {
+	if size <= 0 {
+		size = DefaultBatchSize
+	}
+	return &BatchCaller{c: c, size: int64(size)}
+}
+
+// Size returns the current maximum number of requests in a batch.
+func (b *BatchCaller) Size() int {
+	return int(atomic.LoadInt64(&b.size))
+}
+
+// Call sends the requests. It returns *BatchError when some of requests failed, or the error of the request
+// which failed even when sent alone (e.g. the node is unavailable).
+func (b *BatchCaller) Call(ctx context.Context, reqs []rpc.BatchElem) error {
+	for offset := 0; offset < len(reqs); {
+		end := offset + b.Size()
+		if end > len(reqs) {
+			end = len(reqs)
+		}
+
+		if err := b.c.batchCall(ctx, reqs[offset:end]); err != nil {
+			if ctx.Err() != nil || end-offset == 1 || !isBatchLimitError(err) {
+				return err
+			}
+			b.shrink((end - offset) / 2)
+			continue
+		}
+		offset = end
+	}
+
+	var failed map[int]error
+	for i := range reqs {
+		req := &reqs[i]
+		if req.Error == nil {
+			continue
+		}
+		req.Error = b.c.call(ctx, req.Result, req.Method, req.Args...)
+		if req.Error != nil {
+			if failed == nil {
+				failed = make(map[int]error)
+			}
+			failed[i] = req.Error
+		}
+	}
+	if failed != nil {
+		return &BatchError{Total: len(reqs), Errors: failed}
+	}
+	return nil
+}
+
+// Messages of errors returned by providers (or by rpc.Client for their responses) when the batch is too large.
+var batchLimitMessages = []string{
+	"too large", // e.g. HTTP 413 Request Entity Too Large, "batch too large"
+	"batch limit",
+	"batch size",
+	"cannot unmarshal object into go value of type []", // single error object instead of responses of the batch
+}
+
+// isBatchLimitError tells whether the whole batch failed because of its size.
+func isBatchLimitError(err error) bool {
+	msg := strings.ToLower(err.Error())
+	for _, m := range batchLimitMessages {
+		if strings.Contains(msg, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// shrink decreases the size of batches to the given one, unless it's already smaller.
+func (b *BatchCaller) shrink(size int) {
+	for {
+		curr := atomic.LoadInt64(&b.size)
+		if int64(size) >= curr || atomic.CompareAndSwapInt64(&b.size, curr, int64(size)) {
+			return
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+type rpcRequest struct {
+	ID     json.RawMessage   `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+type rpcResponse struct {
+	Version string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   interface{}     `json:"error,omitempty"`
+}
+
+// rpcServer serves JSON-RPC requests over HTTP with the handler, batches larger than maxBatch are rejected.
+type rpcServer struct {
+	*httptest.Server
+	maxBatch int
+	handle   func(req *rpcRequest) (interface{}, error)
+	failures int // number of next requests failing with HTTP 503
+
+	mu      sync.Mutex
+	batches []int // sizes of received batches, 0 for single requests
+}
+
+func newRPCServer(maxBatch int, handle func(req *rpcRequest) (interface{}, error)) *rpcServer {
+	s := &rpcServer{maxBatch: maxBatch, handle: handle}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+func (s *rpcServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+
+	s.mu.Lock()
+	unavailable := s.failures > 0
+	if unavailable {
+		s.failures--
+	}
+	s.mu.Unlock()
+	if unavailable {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	respond := func(req *rpcRequest) rpcResponse {
+		res, err := s.handle(req)
+		if err != nil {
+			return rpcResponse{Version: "2.0", ID: req.ID, Error: map[string]interface{}{"code": -32000, "message": err.Error()}}
+		}
+		return rpcResponse{Version: "2.0", ID: req.ID, Result: res}
+	}
+
+	var reqs []*rpcRequest
+	if err := json.Unmarshal(body, &reqs); err != nil {
+		var req rpcRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.record(0)
+		json.NewEncoder(w).Encode(respond(&req))
+		return
+	}
+
+	s.record(len(reqs))
+	if s.maxBatch > 0 && len(reqs) > s.maxBatch {
+		http.Error(w, "batch too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	resps := make([]rpcResponse, len(reqs))
+	for i, req := range reqs {
+		resps[i] = respond(req)
+	}
+	json.NewEncoder(w).Encode(resps)
+}
+
+func (s *rpcServer) record(size int) {
+	s.mu.Lock()
+	s.batches = append(s.batches, size)
+	s.mu.Unlock()
+}
+
+func (s *rpcServer) client(t *testing.T) *Client {
+	c, err := rpc.DialHTTP(s.URL)
+	if err != nil {
+		t.Fatalf("DialHTTP: %v", err)
+	}
+	return NewClient(c)
+}
+
+// balanceHandler returns the balance equal to the last byte of the account.
+func balanceHandler(req *rpcRequest) (interface{}, error) {
+	var account common.Address
+	if err := json.Unmarshal(req.Params[0], &account); err != nil {
+		return nil, err
+	}
+	return hexutil.EncodeBig(big.NewInt(int64(account[common.AddressLength-1]))), nil
+}
+
+func testAccounts(n int) []common.Address {
+	accounts := make([]common.Address, n)
+	for i := range accounts {
+		accounts[i] = common.BigToAddress(big.NewInt(int64(i)))
+	}
+	return accounts
+}
+
+func TestBatchCaller_Call(t *testing.T) {
+	tests := []struct {
+		name      string
+		size      int
+		maxBatch  int
+		accounts  int
+		batches   []int
+		finalSize int
+	}{
+		{"single batch", 10, 0, 5, []int{5}, 10},
+		{"chunks", 2, 0, 5, []int{2, 2, 1}, 2},
+		{"split rejected batches", 8, 3, 8, []int{8, 4, 2, 2, 2, 2}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newRPCServer(tt.maxBatch, balanceHandler)
+			defer srv.Close()
+			c := srv.client(t)
+			c.SetBatchSize(tt.size)
+
+			balances, err := c.BalancesAt(context.Background(), testAccounts(tt.accounts), nil)
+			if err != nil {
+				t.Fatalf("BalancesAt: %v", err)
+			}
+			for i, balance := range balances {
+				if balance.Int64() != int64(i) {
+					t.Errorf("expected balance %v of account %v, but got %v", i, i, balance)
+				}
+			}
+			if fmt.Sprint(srv.batches) != fmt.Sprint(tt.batches) {
+				t.Errorf("expected batches %v, but got %v", tt.batches, srv.batches)
+			}
+			if size := c.batcher.Size(); size != tt.finalSize {
+				t.Errorf("expected batch size %v, but got %v", tt.finalSize, size)
+			}
+		})
+	}
+}
+
+func TestBatchCaller_Call_Unavailable(t *testing.T) {
+	srv := newRPCServer(0, balanceHandler)
+	defer srv.Close()
+	srv.failures = 1
+	c := srv.client(t)
+	c.SetBatchSize(4)
+
+	if _, err := c.BalancesAt(context.Background(), testAccounts(4), nil); err == nil {
+		t.Fatalf("expected error when the node is unavailable")
+	}
+	if _, err := c.BalancesAt(context.Background(), testAccounts(4), nil); err != nil {
+		t.Fatalf("BalancesAt: %v", err)
+	}
+	if size := c.batcher.Size(); size != 4 {
+		t.Errorf("expected batch size 4 to be kept after the failure, but got %v", size)
+	}
+}
+
+func TestBatchCaller_RetryFailedRequests(t *testing.T) {
+	var mu sync.Mutex
+	attempts := make(map[string]int)
+	srv := newRPCServer(0, func(req *rpcRequest) (interface{}, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		account := string(req.Params[0])
+		attempts[account]++
+		switch {
+		case account == `"0x0000000000000000000000000000000000000001"` && attempts[account] == 1:
+			return nil, fmt.Errorf("rate limited")
+		case account == `"0x0000000000000000000000000000000000000002"`:
+			return nil, fmt.Errorf("internal error")
+		}
+		return balanceHandler(req)
+	})
+	defer srv.Close()
+
+	results := make([]hexutil.Big, 3)
+	reqs := make([]rpc.BatchElem, 3)
+	for i, account := range testAccounts(3) {
+		reqs[i] = rpc.BatchElem{Method: "eth_getBalance", Args: []interface{}{account, "latest"}, Result: &results[i]}
+	}
+
+	err := NewBatchCaller(srv.client(t), 0).Call(context.Background(), reqs)
+	batchErr, ok := err.(*BatchError)
+	if !ok {
+		t.Fatalf("expected *BatchError, but got %v", err)
+	}
+	if len(batchErr.Errors) != 1 || batchErr.Errors[2] == nil || batchErr.Total != 3 {
+		t.Errorf("expected request 2 to fail, but got %v", batchErr)
+	}
+	if reqs[1].Error != nil || results[1].ToInt().Int64() != 1 {
+		t.Errorf("expected request 1 to succeed when retried, but got %v", reqs[1].Error)
+	}
+	if reqs[2].Error == nil {
+		t.Errorf("expected error of request 2")
+	}
+	if fmt.Sprint(srv.batches) != "[3 0 0]" {
+		t.Errorf("expected a batch and two retries, but got %v", srv.batches)
+	}
+}
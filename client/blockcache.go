@@ -0,0 +1,140 @@
+package client
+
+import (
+	"container/list"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/monetha/go-ethereum"
+)
+
+// DefaultBlockCacheSize is the number of blocks kept by BlockCache when size isn't specified.
+const DefaultBlockCacheSize = 128
+
+// BlockCache is LRU cache of full blocks keyed by hash and by number, which can be shared by clients of several
+// consumers in one process (see Client.SetBlockCache and blocksource.Config). Block is cached by number only when it
+// has at least `confirmations` blocks on top of it, so recent blocks, which may be replaced by chain reorganization,
+// are fetched every time. The cache is reset by BlockSource when it detects reorganization.
+// Cached blocks are shared and must not be modified. It's safe for concurrent use.
+type BlockCache struct {
+	size          int
+	confirmations uint64
+
+	mu       sync.Mutex
+	lru      *list.List // of *ethereum.Block, most recently used first
+	byHash   map[common.Hash]*list.Element
+	byNumber map[string]*list.Element
+	head     *big.Int // the latest known block number
+}
+
+// NewBlockCache creates an instance of BlockCache which keeps up to `size` blocks (DefaultBlockCacheSize if size is 0)
+// having at least `confirmations` blocks on top of them.
+func NewBlockCache(size int, confirmations uint) *BlockCache {
+	if size <= 0 {
+		size = DefaultBlockCacheSize
+	}
+	return &BlockCache{
+		size:          size,
+		confirmations: uint64(confirmations),
+		lru:           list.New(),
+		byHash:        make(map[common.Hash]*list.Element),
+		byNumber:      make(map[string]*list.Element),
+	}
+}
+
+// Get returns the block with the given hash.
+func (bc *BlockCache) Get(hash common.Hash) (*ethereum.Block, bool) {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	return bc.get(bc.byHash[hash])
+}
+
+// GetByNumber returns the block with the given number.
+func (bc *BlockCache) GetByNumber(number *big.Int) (*ethereum.Block, bool) {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	return bc.get(bc.byNumber[number.String()])
+}
+
+func (bc *BlockCache) get(e *list.Element) (*ethereum.Block, bool) {
+	if e == nil {
+		return nil, false
+	}
+	bc.lru.MoveToFront(e)
+	return e.Value.(*ethereum.Block), true
+}
+
+// Add adds the block, if it has enough confirmations given the latest known block number. The least recently used
+// block is evicted when the cache is full.
+func (bc *BlockCache) Add(b *ethereum.Block) {
+	if b == nil || b.Number == nil {
+		return
+	}
+
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+
+	if bc.confirmations > 0 {
+		if bc.head == nil {
+			return
+		}
+		confirmed := new(big.Int).Add(b.Number, new(big.Int).SetUint64(bc.confirmations))
+		if confirmed.Cmp(bc.head) > 0 {
+			return
+		}
+	}
+
+	numberKey := b.Number.String()
+	if e, ok := bc.byNumber[numberKey]; ok {
+		bc.remove(e)
+	}
+	if e, ok := bc.byHash[b.Hash]; ok {
+		bc.remove(e)
+	}
+
+	e := bc.lru.PushFront(b)
+	bc.byHash[b.Hash] = e
+	bc.byNumber[numberKey] = e
+
+	if bc.lru.Len() > bc.size {
+		bc.remove(bc.lru.Back())
+	}
+}
+
+// SetHead sets the latest known block number, it's called by clients each time they see the latest block.
+func (bc *BlockCache) SetHead(number *big.Int) {
+	if number == nil {
+		return
+	}
+
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	if bc.head == nil || number.Cmp(bc.head) > 0 {
+		bc.head = new(big.Int).Set(number)
+	}
+}
+
+// Reset removes all blocks, e.g. when chain reorganization is detected.
+func (bc *BlockCache) Reset() {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+
+	bc.lru.Init()
+	bc.byHash = make(map[common.Hash]*list.Element)
+	bc.byNumber = make(map[string]*list.Element)
+	bc.head = nil
+}
+
+// Len returns the number of cached blocks.
+func (bc *BlockCache) Len() int {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	return bc.lru.Len()
+}
+
+func (bc *BlockCache) remove(e *list.Element) {
+	b := bc.lru.Remove(e).(*ethereum.Block)
+	delete(bc.byHash, b.Hash)
+	delete(bc.byNumber, b.Number.String())
+}
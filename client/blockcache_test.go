@@ -0,0 +1,63 @@
+package client
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/monetha/go-ethereum"
+)
+
+func testBlock(number int64) *ethereum.Block {
+	return &ethereum.Block{Number: big.NewInt(number), Hash: common.BigToHash(big.NewInt(number))}
+}
+
+func TestBlockCache(t *testing.T) {
+	bc := NewBlockCache(2, 3)
+
+	bc.Add(testBlock(1))
+	if bc.Len() != 0 {
+		t.Fatalf("expected block not to be cached while the head is unknown")
+	}
+
+	bc.SetHead(big.NewInt(5))
+	for _, n := range []int64{1, 2, 3} {
+		bc.Add(testBlock(n))
+	}
+	if _, ok := bc.GetByNumber(big.NewInt(3)); ok {
+		t.Errorf("expected block 3 without enough confirmations not to be cached")
+	}
+
+	if b, ok := bc.GetByNumber(big.NewInt(1)); !ok || b.Number.Int64() != 1 {
+		t.Errorf("expected block 1 by number, but got %v", b)
+	}
+	if b, ok := bc.Get(common.BigToHash(big.NewInt(2))); !ok || b.Number.Int64() != 2 {
+		t.Errorf("expected block 2 by hash, but got %v", b)
+	}
+
+	// block 1 is the least recently used one
+	bc.SetHead(big.NewInt(10))
+	bc.Add(testBlock(4))
+	if _, ok := bc.GetByNumber(big.NewInt(1)); ok {
+		t.Errorf("expected block 1 to be evicted")
+	}
+	if bc.Len() != 2 {
+		t.Errorf("expected 2 cached blocks, but got %v", bc.Len())
+	}
+
+	// block replaced after reorganization
+	replaced := testBlock(4)
+	replaced.Hash = common.HexToHash("0x44")
+	bc.Add(replaced)
+	if b, _ := bc.GetByNumber(big.NewInt(4)); b == nil || b.Hash != replaced.Hash {
+		t.Errorf("expected replaced block 4, but got %v", b)
+	}
+	if _, ok := bc.Get(common.BigToHash(big.NewInt(4))); ok {
+		t.Errorf("expected old block 4 not to be found by hash")
+	}
+
+	bc.Reset()
+	if bc.Len() != 0 {
+		t.Errorf("expected empty cache after reset, but got %v blocks", bc.Len())
+	}
+}
@@ -0,0 +1,67 @@
+package client
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// LogFilter describes logs the user of the client is interested in, like eth.FilterQuery: logs emitted by any
+// of Addresses (any contract when empty) and matching Topics (alternatives at each position, empty list matches
+// any topic).
+type LogFilter struct {
+	Addresses []common.Address
+	Topics    [][]common.Hash
+}
+
+// MayMatch tells whether the block with the logs bloom may contain logs matching the filter. Bloom has false
+// positives, so true means the logs must be retrieved to know for sure, but false means there are no such logs.
+func (f LogFilter) MayMatch(bloom types.Bloom) bool {
+	if len(f.Addresses) > 0 {
+		var included bool
+		for _, addr := range f.Addresses {
+			if types.BloomLookup(bloom, addr) {
+				included = true
+				break
+			}
+		}
+		if !included {
+			return false
+		}
+	}
+
+	for _, sub := range f.Topics {
+		included := len(sub) == 0 // empty rule set == wildcard
+		for _, topic := range sub {
+			if types.BloomLookup(bloom, topic) {
+				included = true
+				break
+			}
+		}
+		if !included {
+			return false
+		}
+	}
+	return true
+}
+
+// SetLogFilters sets filters of logs the user of the client is interested in. Receipts of the block aren't retrieved
+// by BlockByNumber and BlockByNumberWithUncles when the logs bloom of the block header shows it can't contain logs
+// matching any of the filters, which saves RPC calls when watched contracts emit events rarely. Transactions of such
+// blocks have no gas used, status, contract address and logs, and Block.ReceiptsSkipped is true. All receipts are
+// retrieved when no filters are set. It must be called before the client is used by several goroutines.
+func (c *Client) SetLogFilters(filters ...LogFilter) {
+	c.logFilters = filters
+}
+
+// skipReceipts tells whether receipts of the block with the logs bloom aren't needed.
+func (c *Client) skipReceipts(bloom types.Bloom) bool {
+	if len(c.logFilters) == 0 {
+		return false
+	}
+	for _, f := range c.logFilters {
+		if f.MayMatch(bloom) {
+			return false
+		}
+	}
+	return true
+}
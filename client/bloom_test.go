@@ -0,0 +1,60 @@
+package client
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestLogFilter_MayMatch(t *testing.T) {
+	var (
+		token    = common.HexToAddress("0x8d12A197cB00D4747a1fe03395095ce2A5CC6819")
+		other    = common.HexToAddress("0xdAC17F958D2ee523a2206206994597C13D831ec7")
+		transfer = common.HexToHash("0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef")
+		approval = common.HexToHash("0x8c5be1e5ebec7d5bd14f71427d1e84f3dd0314c0f7b2291e5b200ac8c7c3b925")
+	)
+
+	var bloom types.Bloom
+	bloom.Add(new(big.Int).SetBytes(token.Bytes()))
+	bloom.Add(new(big.Int).SetBytes(transfer.Bytes()))
+
+	tests := []struct {
+		name     string
+		filter   LogFilter
+		expected bool
+	}{
+		{"empty filter", LogFilter{}, true},
+		{"address", LogFilter{Addresses: []common.Address{token}}, true},
+		{"other address", LogFilter{Addresses: []common.Address{other}}, false},
+		{"any of addresses", LogFilter{Addresses: []common.Address{other, token}}, true},
+		{"topic", LogFilter{Topics: [][]common.Hash{{transfer}}}, true},
+		{"other topic", LogFilter{Topics: [][]common.Hash{{approval}}}, false},
+		{"wildcard topic", LogFilter{Topics: [][]common.Hash{nil, {transfer}}}, true},
+		{"address and other topic", LogFilter{Addresses: []common.Address{token}, Topics: [][]common.Hash{{approval}}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.MayMatch(bloom); got != tt.expected {
+				t.Errorf("expected %v, but got %v", tt.expected, got)
+			}
+		})
+	}
+
+	t.Run("client filters", func(t *testing.T) {
+		c := NewClient(nil)
+		if c.skipReceipts(bloom) {
+			t.Errorf("expected receipts to be retrieved without filters")
+		}
+		c.SetLogFilters(LogFilter{Addresses: []common.Address{other}})
+		if !c.skipReceipts(bloom) {
+			t.Errorf("expected receipts to be skipped when no filter matches")
+		}
+		c.SetLogFilters(LogFilter{Addresses: []common.Address{other}}, LogFilter{Topics: [][]common.Hash{{transfer}}})
+		if c.skipReceipts(bloom) {
+			t.Errorf("expected receipts to be retrieved when any filter matches")
+		}
+	})
+}
@@ -0,0 +1,119 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Capability is the optional feature of the node.
+type Capability string
+
+const (
+	// TxPoolCapability means txpool_* methods are available.
+	TxPoolCapability Capability = "txpool"
+	// TraceCapability means trace_* methods (OpenEthereum/Erigon style) are available.
+	TraceCapability Capability = "trace"
+	// DebugCapability means debug_trace* methods (Geth style) are available.
+	DebugCapability Capability = "debug"
+	// FeeHistoryCapability means eth_feeHistory method is available.
+	FeeHistoryCapability Capability = "feeHistory"
+	// BlockReceiptsCapability means eth_getBlockReceipts method is available.
+	BlockReceiptsCapability Capability = "blockReceipts"
+	// SubscriptionsCapability means eth_subscribe is supported by the connection (e.g. WebSocket).
+	SubscriptionsCapability Capability = "subscriptions"
+	// ArchiveCapability means the state of historical blocks is available.
+	ArchiveCapability Capability = "archive"
+)
+
+// Capabilities is the set of capabilities supported by the node.
+type Capabilities map[Capability]bool
+
+// Has returns true when the capability is supported.
+func (c Capabilities) Has(capability Capability) bool {
+	return c[capability]
+}
+
+func (c Capabilities) String() string {
+	var supported []string
+	for capability, ok := range c {
+		if ok {
+			supported = append(supported, string(capability))
+		}
+	}
+	sort.Strings(supported)
+	return "[" + strings.Join(supported, " ") + "]"
+}
+
+type rpcProber interface {
+	CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error
+	EthSubscribe(ctx context.Context, channel interface{}, args ...interface{}) (*rpc.ClientSubscription, error)
+}
+
+// Capabilities probes the node for supported features. Capability is considered unsupported when the probe request
+// fails for any reason (tracing capabilities only when the method isn't found, as tracing of the probed genesis
+// block may be refused), so the result of probing a node with connectivity problems is meaningless. It returns
+// error only when the context is done.
+func (c *Client) Capabilities(ctx context.Context) (Capabilities, error) {
+	return probeCapabilities(ctx, c.c)
+}
+
+// SetCapabilities sets capabilities of the node which the client uses to pick optimal requests
+// (e.g. eth_getBlockReceipts to get receipts of the block). It must be called before the client is used
+// by several goroutines.
+func (c *Client) SetCapabilities(caps Capabilities) {
+	c.caps = caps
+}
+
+func probeCapabilities(ctx context.Context, p rpcProber) (Capabilities, error) {
+	probes := []struct {
+		capability Capability
+		method     string
+		args       []interface{}
+		// anyResponse means the method is supported when it responds with an error other than "method not found",
+		// since the cheap request fails for other reasons (e.g. geth refuses to trace the genesis block)
+		anyResponse bool
+	}{
+		{TxPoolCapability, "txpool_status", nil, false},
+		{TraceCapability, "trace_block", []interface{}{"0x0"}, true},
+		{DebugCapability, "debug_traceBlockByNumber", []interface{}{"0x0"}, true},
+		{FeeHistoryCapability, "eth_feeHistory", []interface{}{"0x1", "latest", []float64{}}, false},
+		{BlockReceiptsCapability, "eth_getBlockReceipts", []interface{}{"latest"}, false},
+		{ArchiveCapability, "eth_getBalance", []interface{}{common.Address{}, "0x1"}, false},
+	}
+
+	caps := make(Capabilities, len(probes)+1)
+	for _, probe := range probes {
+		var raw json.RawMessage
+		err := p.CallContext(ctx, &raw, probe.method, probe.args...)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		caps[probe.capability] = err == nil || (probe.anyResponse && methodExists(err))
+	}
+
+	sub, err := p.EthSubscribe(ctx, make(chan json.RawMessage), "newHeads")
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if err == nil {
+		sub.Unsubscribe()
+	}
+	caps[SubscriptionsCapability] = err == nil
+
+	return caps, nil
+}
+
+// methodNotFoundCode is the JSON-RPC error code of the request of the method which doesn't exist.
+const methodNotFoundCode = -32601
+
+// methodExists tells whether the error is returned by the method itself, i.e. the node responded with the error
+// other than "method not found". Errors which aren't JSON-RPC errors (e.g. connection errors) don't tell anything.
+func methodExists(err error) bool {
+	rpcErr, ok := err.(rpc.Error)
+	return ok && rpcErr.ErrorCode() != methodNotFoundCode && !strings.Contains(rpcErr.Error(), "does not exist")
+}
@@ -0,0 +1,96 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+type proberMock struct {
+	supported map[string]bool
+	errors    map[string]error // errors of supported methods
+}
+
+// rpcError is the JSON-RPC error returned by the node.
+type rpcError struct {
+	code int
+	msg  string
+}
+
+func (e rpcError) Error() string  { return e.msg }
+func (e rpcError) ErrorCode() int { return e.code }
+
+func (p *proberMock) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	if err := p.errors[method]; err != nil {
+		return err
+	}
+	if p.supported[method] {
+		return nil
+	}
+	return rpcError{methodNotFoundCode, "the method " + method + " does not exist/is not available"}
+}
+
+func (p *proberMock) EthSubscribe(ctx context.Context, channel interface{}, args ...interface{}) (*rpc.ClientSubscription, error) {
+	return nil, errors.New("notifications not supported")
+}
+
+func TestProbeCapabilities(t *testing.T) {
+	p := &proberMock{supported: map[string]bool{
+		"txpool_status":        true,
+		"eth_feeHistory":       true,
+		"eth_getBlockReceipts": true,
+	}}
+
+	caps, err := probeCapabilities(context.Background(), p)
+	if err != nil {
+		t.Fatalf("probeCapabilities: %v", err)
+	}
+
+	expected := map[Capability]bool{
+		TxPoolCapability:        true,
+		TraceCapability:         false,
+		DebugCapability:         false,
+		FeeHistoryCapability:    true,
+		BlockReceiptsCapability: true,
+		SubscriptionsCapability: false,
+		ArchiveCapability:       false,
+	}
+	for capability, supported := range expected {
+		if caps.Has(capability) != supported {
+			t.Errorf("expected %v to be supported: %v, but got %v", capability, supported, caps.Has(capability))
+		}
+	}
+	if s := caps.String(); s != "[blockReceipts feeHistory txpool]" {
+		t.Errorf("unexpected string representation %v", s)
+	}
+}
+
+func TestProbeCapabilities_MethodErrors(t *testing.T) {
+	p := &proberMock{errors: map[string]error{
+		"debug_traceBlockByNumber": rpcError{-32000, "genesis is not traceable"},
+		"trace_block":              errors.New("connection refused"),
+		"eth_getBalance":           rpcError{-32000, "missing trie node"},
+	}}
+
+	caps, err := probeCapabilities(context.Background(), p)
+	if err != nil {
+		t.Fatalf("probeCapabilities: %v", err)
+	}
+	if !caps.Has(DebugCapability) {
+		t.Errorf("expected debug methods to be supported when the node refuses to trace genesis")
+	}
+	if caps.Has(TraceCapability) || caps.Has(ArchiveCapability) {
+		t.Errorf("expected trace and archive capabilities not to be supported, but got %v", caps)
+	}
+}
+
+func TestProbeCapabilities_ContextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := probeCapabilities(ctx, &proberMock{}); err != context.Canceled {
+		t.Errorf("expected context.Canceled, but got %v", err)
+	}
+}
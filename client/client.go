@@ -6,17 +6,30 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"net/http"
+	"strings"
 
+	eth "github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/monetha/go-ethereum"
+	"github.com/monetha/go-ethereum/tracing"
 )
 
 // Client defines typed wrappers for the Ethereum RPC API.
 type Client struct {
-	c *rpc.Client
+	c             *rpc.Client
+	caps          Capabilities
+	tracer        tracing.Tracer
+	lenient       bool
+	missingFields MissingFieldsFunc
+	blockCache    *BlockCache
+	logFilters    []LogFilter
+	httpURL       string       // endpoint of StreamBlockByNumber
+	httpClient    *http.Client // client of StreamBlockByNumber
+	batcher       *BatchCaller
 }
 
 // Close implements io.Closer interface
@@ -31,99 +44,350 @@ func Dial(rawurl string) (*Client, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &Client{c}, nil
+	cl := NewClient(c)
+	if strings.HasPrefix(rawurl, "http://") || strings.HasPrefix(rawurl, "https://") {
+		cl.SetHTTPEndpoint(rawurl, nil)
+	}
+	return cl, nil
+}
+
+// NewClient creates a client that uses the given RPC client (e.g. created by rpc.DialHTTPWithClient with
+// vcr.Transport to replay recorded responses in tests).
+func NewClient(c *rpc.Client) *Client {
+	cl := &Client{c: c, tracer: tracing.Nop}
+	cl.batcher = NewBatchCaller(cl, DefaultBatchSize)
+	return cl
+}
+
+// SetBatchSize sets the maximum number of requests in a single batch (e.g. receipts of the block), since providers
+// limit the size of batches (DefaultBatchSize is used by default). The size is decreased automatically when
+// the provider rejects batches. It must be called before the client is used by several goroutines.
+func (c *Client) SetBatchSize(size int) {
+	c.batcher = NewBatchCaller(c, size)
+}
+
+// batch sends requests with BatchCaller of the client.
+func (c *Client) batch(ctx context.Context, reqs []rpc.BatchElem) error {
+	b := c.batcher
+	if b == nil {
+		b = NewBatchCaller(c, DefaultBatchSize)
+	}
+	return b.Call(ctx, reqs)
+}
+
+// RPC returns the underlying RPC client, so that the connection can be shared with other components
+// (e.g. gasestimator.NewGasPriceEstimatorWithClient).
+func (c *Client) RPC() *rpc.Client {
+	return c.c
+}
+
+// SetTracer sets the tracer which is used to start a span for every RPC request (and for every batch of requests).
+// It must be called before the client is used by several goroutines.
+func (c *Client) SetTracer(tracer tracing.Tracer) {
+	c.tracer = tracer
+}
+
+// SetBlockCache sets the cache used by BlockByNumber and BlockByNumberWithUncles, which can be shared with
+// other clients. It must be called before the client is used by several goroutines.
+func (c *Client) SetBlockCache(bc *BlockCache) {
+	c.blockCache = bc
+}
+
+func (c *Client) call(ctx context.Context, result interface{}, method string, args ...interface{}) (err error) {
+	ctx, span := c.startSpan(ctx, method)
+	defer func() { span.End(err) }()
+
+	return c.c.CallContext(ctx, result, method, args...)
+}
+
+func (c *Client) batchCall(ctx context.Context, reqs []rpc.BatchElem) (err error) {
+	method := "batch"
+	if len(reqs) > 0 {
+		method = reqs[0].Method
+	}
+	ctx, span := c.startSpan(ctx, method)
+	span.SetAttribute(tracing.AttrBatchSize, len(reqs))
+	defer func() { span.End(err) }()
+
+	return c.c.BatchCallContext(ctx, reqs)
+}
+
+func (c *Client) startSpan(ctx context.Context, method string) (context.Context, tracing.Span) {
+	tracer := c.tracer
+	if tracer == nil {
+		tracer = tracing.Nop
+	}
+	ctx, span := tracer.Start(ctx, method)
+	span.SetAttribute(tracing.AttrRPCMethod, method)
+	if bn, ok := blockNumberFromContext(ctx); ok {
+		span.SetAttribute(tracing.AttrBlockNumber, bn)
+	}
+	return ctx, span
+}
+
+type blockNumberKey struct{}
+
+// withBlockNumber returns the context holding the block number, which is set as the attribute of spans of RPC requests
+// made with the context. Block tags (negative numbers) are ignored.
+func withBlockNumber(ctx context.Context, number *big.Int) context.Context {
+	if number == nil || number.Sign() < 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, blockNumberKey{}, number)
+}
+
+func blockNumberFromContext(ctx context.Context) (*big.Int, bool) {
+	number, ok := ctx.Value(blockNumberKey{}).(*big.Int)
+	return number, ok
 }
 
 // BlockNumber returns the number of most recent block.
 func (c *Client) BlockNumber(ctx context.Context) (*big.Int, error) {
 	var number hexutil.Big
-	err := c.c.CallContext(ctx, &number, "eth_blockNumber")
+	err := c.call(ctx, &number, "eth_blockNumber")
 	if err != nil {
 		return nil, fmt.Errorf("eth_blockNumber: %v", err)
 	}
 
+	if c.blockCache != nil {
+		c.blockCache.SetHead((*big.Int)(&number))
+	}
 	return (*big.Int)(&number), nil
 }
 
+// ChainID returns the chain ID used for transaction replay protection.
+func (c *Client) ChainID(ctx context.Context) (*big.Int, error) {
+	var chainID hexutil.Big
+	err := c.call(ctx, &chainID, "eth_chainId")
+	if err != nil {
+		return nil, fmt.Errorf("eth_chainId: %v", err)
+	}
+
+	return (*big.Int)(&chainID), nil
+}
+
+// SyncProgress retrieves the current progress of the sync algorithm. If there's no sync currently running,
+// it returns nil.
+func (c *Client) SyncProgress(ctx context.Context) (*eth.SyncProgress, error) {
+	var raw json.RawMessage
+	if err := c.call(ctx, &raw, "eth_syncing"); err != nil {
+		return nil, fmt.Errorf("eth_syncing: %v", err)
+	}
+
+	progress, err := decodeSyncProgress(raw)
+	if err != nil {
+		return nil, fmt.Errorf("eth_syncing: %v", err)
+	}
+	return progress, nil
+}
+
+func decodeSyncProgress(raw json.RawMessage) (*eth.SyncProgress, error) {
+	var syncing bool
+	if err := json.Unmarshal(raw, &syncing); err == nil {
+		return nil, nil // not syncing (always false if not syncing)
+	}
+
+	var progress struct {
+		StartingBlock hexutil.Uint64 `json:"startingBlock"`
+		CurrentBlock  hexutil.Uint64 `json:"currentBlock"`
+		HighestBlock  hexutil.Uint64 `json:"highestBlock"`
+		PulledStates  hexutil.Uint64 `json:"pulledStates"`
+		KnownStates   hexutil.Uint64 `json:"knownStates"`
+	}
+	if err := json.Unmarshal(raw, &progress); err != nil {
+		return nil, err
+	}
+
+	return &eth.SyncProgress{
+		StartingBlock: uint64(progress.StartingBlock),
+		CurrentBlock:  uint64(progress.CurrentBlock),
+		HighestBlock:  uint64(progress.HighestBlock),
+		PulledStates:  uint64(progress.PulledStates),
+		KnownStates:   uint64(progress.KnownStates),
+	}, nil
+}
+
+// PeerCount returns the number of peers connected to the node.
+func (c *Client) PeerCount(ctx context.Context) (uint64, error) {
+	var count hexutil.Uint64
+	if err := c.call(ctx, &count, "net_peerCount"); err != nil {
+		return 0, fmt.Errorf("net_peerCount: %v", err)
+	}
+	return uint64(count), nil
+}
+
 // BlockByNumber returns a block from the current canonical chain. If number is nil, the
-// latest known block is returned.
+// latest known block is returned. Block tags (e.g. ethereum.FinalizedBlockNumber) are supported.
+// Confirmed blocks are returned from the block cache, if it's set (see SetBlockCache).
 func (c *Client) BlockByNumber(ctx context.Context, number *big.Int) (*ethereum.Block, error) {
-	return c.getBlock(ctx, "eth_getBlockByNumber", toBlockNumArg(number), true)
+	return c.cachedBlock(ctx, number, false)
+}
+
+// BlockByNumberWithUncles works like BlockByNumber, but additionally retrieves headers of uncle blocks (ommers)
+// with estimated uncle miner rewards.
+func (c *Client) BlockByNumberWithUncles(ctx context.Context, number *big.Int) (*ethereum.Block, error) {
+	return c.cachedBlock(ctx, number, true)
+}
+
+// cachedBlock returns the block from the block cache, or retrieves it and adds it to the cache.
+func (c *Client) cachedBlock(ctx context.Context, number *big.Int, withUncles bool) (*ethereum.Block, error) {
+	bc := c.blockCache
+	_, isTag := ethereum.BlockNumberTag(number)
+	cacheable := bc != nil && number != nil && !isTag
+
+	if cacheable {
+		if b, ok := bc.GetByNumber(number); ok && (!withUncles || b.Uncles != nil || len(b.UncleHashes) == 0) &&
+			(!b.ReceiptsSkipped || c.skipReceipts(b.LogsBloom)) {
+			return b, nil
+		}
+	}
+
+	b, err := c.getBlock(withBlockNumber(ctx, number), withUncles, "eth_getBlockByNumber", toBlockNumArg(number), true)
+	if err != nil || bc == nil {
+		return b, err
+	}
+
+	if number == nil {
+		bc.SetHead(b.Number)
+	}
+	if cacheable || number == nil {
+		bc.Add(b)
+	}
+	return b, nil
 }
 
-func (c *Client) getBlock(ctx context.Context, method string, args ...interface{}) (*ethereum.Block, error) {
+// UncleByBlockNumberAndIndex returns the uncle block (ommer) with the given index of the block with the given number.
+// If number is nil, the latest known block is used. Reward of the uncle miner is estimated only when number is
+// an actual block number (not nil or block tag).
+func (c *Client) UncleByBlockNumberAndIndex(ctx context.Context, number *big.Int, index uint) (*ethereum.Uncle, error) {
 	var raw json.RawMessage
-	err := c.c.CallContext(ctx, &raw, method, args...)
+	err := c.call(withBlockNumber(ctx, number), &raw, "eth_getUncleByBlockNumberAndIndex", toBlockNumArg(number), hexutil.Uint(index))
 	if err != nil {
-		return nil, err
-	} else if len(raw) == 0 {
+		return nil, fmt.Errorf("eth_getUncleByBlockNumberAndIndex: %v", err)
+	} else if len(raw) == 0 || string(raw) == "null" {
 		return nil, ethereum.ErrNotFound
 	}
-	var header *types.Header
-	if err := json.Unmarshal(raw, &header); err != nil {
+
+	var u rpcUncle
+	if err := json.Unmarshal(raw, &u); err != nil {
 		return nil, err
 	}
-	var body rpcBlock
-	if err := json.Unmarshal(raw, &body); err != nil {
-		return nil, err
+
+	var blockNumber *big.Int
+	if _, isTag := ethereum.BlockNumberTag(number); number != nil && !isTag {
+		blockNumber = number
+	}
+
+	return u.toUncle(blockNumber), nil
+}
+
+// BalanceAt returns the wei balance of the given account at the given block. If number is nil, the
+// latest known block is used. Block tags (e.g. ethereum.FinalizedBlockNumber) are supported.
+func (c *Client) BalanceAt(ctx context.Context, account common.Address, number *big.Int) (*big.Int, error) {
+	var result hexutil.Big
+	err := c.call(withBlockNumber(ctx, number), &result, "eth_getBalance", account, toBlockNumArg(number))
+	if err != nil {
+		return nil, fmt.Errorf("eth_getBalance: %v", err)
+	}
+
+	return (*big.Int)(&result), nil
+}
+
+// TransactionReceipts returns receipts of the given transactions using batch requests. Receipt is nil when
+// transaction isn't mined yet.
+func (c *Client) TransactionReceipts(ctx context.Context, txHashes []common.Hash) ([]*types.Receipt, error) {
+	receipts := make([]*types.Receipt, len(txHashes))
+	if err := c.batchReceipts(ctx, txHashes, func(i int) interface{} { return &receipts[i] }); err != nil {
+		return nil, fmt.Errorf("getting transaction receipts: %v", err)
 	}
+	return receipts, nil
+}
 
-	txs := body.Transactions
-	btxs := make(ethereum.Transactions, 0, len(txs))
-	for _, tx := range txs {
-		btx := &ethereum.Transaction{
-			BlockNumber:      tx.BlockNumber,
-			From:             tx.From,
-			GasLimit:         tx.GasLimit,
-			GasPrice:         tx.GasPrice,
-			Hash:             tx.Hash,
-			Input:            tx.Input,
-			Nonce:            tx.Nonce,
-			To:               tx.To,
-			TransactionIndex: tx.TransactionIndex,
-			Value:            tx.Value,
+// batchReceipts requests receipts of the transactions using batch requests, which are split by the BatchCaller
+// of the client. The receipt of i-th transaction is decoded into result(i).
+func (c *Client) batchReceipts(ctx context.Context, txHashes []common.Hash, result func(i int) interface{}) error {
+	reqs := make([]rpc.BatchElem, len(txHashes))
+	for i, hash := range txHashes {
+		reqs[i] = rpc.BatchElem{
+			Method: "eth_getTransactionReceipt",
+			Args:   []interface{}{hash},
+			Result: result(i),
 		}
-		btxs = append(btxs, btx)
 	}
+	return c.batch(ctx, reqs)
+}
 
-	// Load transaction receipts
-	txLen := len(btxs)
-	if txLen > 0 {
-		receipts := make([]*rpcReceipt, txLen)
-
-		chunkOffset := 0 // offset of first element in current chunk
-		for _, chunkTxs := range chunkTransactions(btxs, 500) {
-			chunkLen := len(chunkTxs)
-
-			reqs := make([]rpc.BatchElem, chunkLen)
-			for i := range chunkTxs {
-				globalIdx := chunkOffset + i
-
-				reqs[i] = rpc.BatchElem{
-					Method: "eth_getTransactionReceipt",
-					Args:   []interface{}{btxs[globalIdx].Hash},
-					Result: &receipts[globalIdx],
-				}
-			}
+// BalancesAt returns wei balances of the given accounts at the given block using batch requests. If number is nil,
+// the latest known block is used. Block tags (e.g. ethereum.FinalizedBlockNumber) are supported.
+func (c *Client) BalancesAt(ctx context.Context, accounts []common.Address, number *big.Int) ([]*big.Int, error) {
+	results := make([]hexutil.Big, len(accounts))
+	reqs := make([]rpc.BatchElem, len(accounts))
+	for i, account := range accounts {
+		reqs[i] = rpc.BatchElem{
+			Method: "eth_getBalance",
+			Args:   []interface{}{account, toBlockNumArg(number)},
+			Result: &results[i],
+		}
+	}
 
-			// batch call
-			if err := c.c.BatchCallContext(ctx, reqs); err != nil {
-				return nil, fmt.Errorf("getting transaction receipts (offset: %d, len: %d): %v", chunkOffset, chunkLen, err)
-			}
+	if err := c.batch(withBlockNumber(ctx, number), reqs); err != nil {
+		return nil, fmt.Errorf("getting balances: %v", err)
+	}
 
-			// response validation
-			for i, req := range reqs {
-				globalIdx := chunkOffset + i
+	balances := make([]*big.Int, len(accounts))
+	for i := range results {
+		balances[i] = (*big.Int)(&results[i])
+	}
+	return balances, nil
+}
 
-				if req.Error != nil {
-					return nil, fmt.Errorf("request error for transaction %d of block %v: %v", globalIdx, header.Number, req.Error)
-				}
-				if receipts[globalIdx] == nil {
-					return nil, fmt.Errorf("got null receipt for transaction %d of block %v", globalIdx, header.Number)
-				}
-			}
+// TraceTransactions returns traces of the given transactions produced by debug_traceTransaction with the given
+// tracer (e.g. "callTracer", the default struct logger is used when it's empty) using batch requests.
+// It requires DebugCapability.
+func (c *Client) TraceTransactions(ctx context.Context, txHashes []common.Hash, tracer string) ([]json.RawMessage, error) {
+	config := map[string]interface{}{}
+	if tracer != "" {
+		config["tracer"] = tracer
+	}
 
-			chunkOffset += chunkLen
+	traces := make([]json.RawMessage, len(txHashes))
+	reqs := make([]rpc.BatchElem, len(txHashes))
+	for i, hash := range txHashes {
+		reqs[i] = rpc.BatchElem{
+			Method: "debug_traceTransaction",
+			Args:   []interface{}{hash, config},
+			Result: &traces[i],
+		}
+	}
+
+	if err := c.batch(ctx, reqs); err != nil {
+		return nil, fmt.Errorf("tracing transactions: %v", err)
+	}
+	return traces, nil
+}
+
+func (c *Client) getBlock(ctx context.Context, withUncles bool, method string, args ...interface{}) (*ethereum.Block, error) {
+	var raw json.RawMessage
+	err := c.call(ctx, &raw, method, args...)
+	if err != nil {
+		return nil, err
+	} else if len(raw) == 0 || string(raw) == "null" {
+		return nil, ethereum.ErrNotFound
+	}
+	block, err := c.decodeBlock(raw)
+	if err != nil {
+		return nil, err
+	}
+	ctx = withBlockNumber(ctx, block.Number)
+	btxs := block.Transactions
+
+	// Load transaction receipts, unless the block can't contain logs the user is interested in
+	txLen := len(btxs)
+	block.ReceiptsSkipped = txLen > 0 && c.skipReceipts(block.LogsBloom)
+	if txLen > 0 && !block.ReceiptsSkipped {
+		receipts, err := c.getReceipts(ctx, block.Hash, block.Number, btxs)
+		if err != nil {
+			return nil, err
 		}
 
 		// assigning receipt values to transaction fields
@@ -136,50 +400,189 @@ func (c *Client) getBlock(ctx context.Context, method string, args ...interface{
 			if rcpt.ContractAddress != nil {
 				btxs[i].ContractAddress = rcpt.ContractAddress
 			}
+			btxs[i].BlobGasUsed = rcpt.BlobGasUsed
+			btxs[i].BlobGasPrice = rcpt.BlobGasPrice
 		}
 	}
 
-	block := &ethereum.Block{
-		Difficulty:   header.Difficulty,
-		ExtraData:    header.Extra,
-		GasLimit:     new(big.Int).SetUint64(header.GasLimit),
-		GasUsed:      new(big.Int).SetUint64(header.GasUsed),
-		Hash:         body.Hash,
-		Miner:        header.Coinbase,
-		Number:       header.Number,
-		Timestamp:    header.Time,
-		Transactions: btxs,
+	if withUncles {
+		uncles, err := c.getUncles(ctx, block.Hash, block.Number, len(block.UncleHashes))
+		if err != nil {
+			return nil, err
+		}
+		block.Uncles = uncles
 	}
 
 	return block, nil
 }
 
-func chunkTransactions(txs ethereum.Transactions, chunkSize int) (chunks []ethereum.Transactions) {
-	if chunkSize <= 0 {
-		panic("chunk size must be positive number")
+// getReceipts returns receipts of the block transactions, using eth_getBlockReceipts when the node supports it.
+func (c *Client) getReceipts(ctx context.Context, blockHash common.Hash, blockNumber *big.Int, btxs ethereum.Transactions) ([]*rpcReceipt, error) {
+	txLen := len(btxs)
+
+	if c.caps.Has(BlockReceiptsCapability) {
+		var receipts []*rpcReceipt
+		if err := c.call(ctx, &receipts, "eth_getBlockReceipts", blockHash); err != nil {
+			return nil, fmt.Errorf("getting receipts of block %v: %v", blockNumber, err)
+		}
+		if len(receipts) != txLen {
+			return nil, fmt.Errorf("got %d receipts for %d transactions of block %v", len(receipts), txLen, blockNumber)
+		}
+		for i, rcpt := range receipts {
+			if rcpt == nil {
+				return nil, fmt.Errorf("got null receipt for transaction %d of block %v", i, blockNumber)
+			}
+		}
+		return receipts, nil
+	}
+
+	receipts := make([]*rpcReceipt, txLen)
+	txHashes := make([]common.Hash, txLen)
+	for i, tx := range btxs {
+		txHashes[i] = tx.Hash
 	}
 
-	txsLen := len(txs)
-	for i := 0; i < txsLen; i += chunkSize {
-		end := i + chunkSize
+	if err := c.batchReceipts(ctx, txHashes, func(i int) interface{} { return &receipts[i] }); err != nil {
+		return nil, fmt.Errorf("getting receipts of block %v: %v", blockNumber, err)
+	}
+	for i, rcpt := range receipts {
+		if rcpt == nil {
+			return nil, fmt.Errorf("got null receipt for transaction %d of block %v", i, blockNumber)
+		}
+	}
+
+	return receipts, nil
+}
 
-		if end > txsLen {
-			end = txsLen
+func (c *Client) getUncles(ctx context.Context, blockHash common.Hash, blockNumber *big.Int, count int) ([]*ethereum.Uncle, error) {
+	if count == 0 {
+		return []*ethereum.Uncle{}, nil
+	}
+
+	rpcUncles := make([]*rpcUncle, count)
+	reqs := make([]rpc.BatchElem, count)
+	for i := range reqs {
+		reqs[i] = rpc.BatchElem{
+			Method: "eth_getUncleByBlockHashAndIndex",
+			Args:   []interface{}{blockHash, hexutil.Uint(i)},
+			Result: &rpcUncles[i],
 		}
+	}
 
-		chunks = append(chunks, txs[i:end])
+	if err := c.batch(ctx, reqs); err != nil {
+		return nil, fmt.Errorf("getting uncles of block %v: %v", blockNumber, err)
+	}
+
+	uncles := make([]*ethereum.Uncle, count)
+	for i := range reqs {
+		if rpcUncles[i] == nil {
+			return nil, fmt.Errorf("got null uncle %d of block %v", i, blockNumber)
+		}
+		uncles[i] = rpcUncles[i].toUncle(blockNumber)
 	}
 
-	return
+	return uncles, nil
 }
 
 type rpcBlock struct {
-	Hash         common.Hash      `json:"hash"`
-	Transactions []rpcTransaction `json:"transactions"`
-	UncleHashes  []common.Hash    `json:"uncles"`
+	Number        *hexutil.Big         `json:"number"`
+	Hash          common.Hash          `json:"hash"`
+	ParentHash    *common.Hash         `json:"parentHash"`
+	Miner         *common.Address      `json:"miner"`
+	Difficulty    *hexutil.Big         `json:"difficulty"`
+	ExtraData     *hexutil.Bytes       `json:"extraData"`
+	GasLimit      *hexutil.Uint64      `json:"gasLimit"`
+	GasUsed       *hexutil.Uint64      `json:"gasUsed"`
+	Timestamp     *hexutil.Uint64      `json:"timestamp"`
+	LogsBloom     *types.Bloom         `json:"logsBloom"`
+	Transactions  []rpcTransactionJSON `json:"transactions"`
+	UncleHashes   []common.Hash        `json:"uncles"`
+	BlobGasUsed   *hexutil.Big         `json:"blobGasUsed"`
+	ExcessBlobGas *hexutil.Big         `json:"excessBlobGas"`
+	Withdrawals   []rpcWithdrawal      `json:"withdrawals"`
+}
+
+type rpcWithdrawal struct {
+	Index          uint64
+	ValidatorIndex uint64
+	Address        common.Address
+	Amount         uint64
+}
+
+func (w *rpcWithdrawal) UnmarshalJSON(input []byte) error {
+	type withdrawal struct {
+		Index          *hexutil.Uint64 `json:"index"`
+		ValidatorIndex *hexutil.Uint64 `json:"validatorIndex"`
+		Address        *common.Address `json:"address"`
+		Amount         *hexutil.Uint64 `json:"amount"`
+	}
+	var dec withdrawal
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+
+	if dec.Index == nil {
+		return errors.New("missing required field 'index'")
+	}
+	w.Index = uint64(*dec.Index)
+
+	if dec.ValidatorIndex == nil {
+		return errors.New("missing required field 'validatorIndex'")
+	}
+	w.ValidatorIndex = uint64(*dec.ValidatorIndex)
+
+	if dec.Address == nil {
+		return errors.New("missing required field 'address'")
+	}
+	w.Address = *dec.Address
+
+	if dec.Amount == nil {
+		return errors.New("missing required field 'amount'")
+	}
+	w.Amount = uint64(*dec.Amount)
+
+	return nil
+}
+
+type rpcUncle struct {
+	Hash   common.Hash
+	Header *types.Header
+}
+
+func (u *rpcUncle) UnmarshalJSON(input []byte) error {
+	var dec struct {
+		Hash *common.Hash `json:"hash"`
+	}
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	if dec.Hash == nil {
+		return errors.New("missing required field 'hash'")
+	}
+	u.Hash = *dec.Hash
+
+	return json.Unmarshal(input, &u.Header)
+}
+
+func (u *rpcUncle) toUncle(blockNumber *big.Int) *ethereum.Uncle {
+	h := u.Header
+	uncle := &ethereum.Uncle{
+		Difficulty: h.Difficulty,
+		GasLimit:   new(big.Int).SetUint64(h.GasLimit),
+		GasUsed:    new(big.Int).SetUint64(h.GasUsed),
+		Hash:       u.Hash,
+		Miner:      h.Coinbase,
+		Number:     h.Number,
+		Timestamp:  h.Time,
+	}
+	if blockNumber != nil {
+		uncle.Reward = ethereum.EstimateUncleReward(h.Number, blockNumber)
+	}
+	return uncle
 }
 
 type rpcTransaction struct {
+	Type             ethereum.TransactionType
 	BlockNumber      *big.Int
 	From             common.Address
 	GasLimit         *big.Int
@@ -193,94 +596,179 @@ type rpcTransaction struct {
 	V                *big.Int
 	R                *big.Int
 	S                *big.Int
+
+	BlobVersionedHashes []common.Hash
+	MaxFeePerBlobGas    *big.Int
+}
+
+func (t *rpcTransaction) toTransaction() *ethereum.Transaction {
+	tx := new(ethereum.Transaction)
+	t.copyTo(tx)
+	return tx
+}
+
+func (t *rpcTransaction) copyTo(tx *ethereum.Transaction) {
+	*tx = ethereum.Transaction{
+		Type:             t.Type,
+		BlockNumber:      t.BlockNumber,
+		From:             t.From,
+		GasLimit:         t.GasLimit,
+		GasPrice:         t.GasPrice,
+		Hash:             t.Hash,
+		Input:            t.Input,
+		Nonce:            t.Nonce,
+		To:               t.To,
+		TransactionIndex: t.TransactionIndex,
+		Value:            t.Value,
+
+		BlobVersionedHashes: t.BlobVersionedHashes,
+		MaxFeePerBlobGas:    t.MaxFeePerBlobGas,
+	}
 }
 
 func (t *rpcTransaction) UnmarshalJSON(input []byte) error {
-	type tx struct {
-		BlockNumber      *hexutil.Big    `json:"blockNumber"`
-		From             *common.Address `json:"from"`
-		GasLimit         *hexutil.Big    `json:"gas"`
-		GasPrice         *hexutil.Big    `json:"gasPrice"`
-		Hash             *common.Hash    `json:"hash"`
-		Input            hexutil.Bytes   `json:"input"`
-		Nonce            *hexutil.Uint64 `json:"nonce"`
-		To               *common.Address `json:"to"`
-		TransactionIndex *hexutil.Uint64 `json:"transactionIndex"`
-		Value            *hexutil.Big    `json:"value"`
-		V                *hexutil.Big    `json:"v"`
-		R                *hexutil.Big    `json:"r"`
-		S                *hexutil.Big    `json:"s"`
-	}
-	var dec tx
+	_, err := t.decode(input, false)
+	return err
+}
+
+// rpcTransactionJSON is the JSON encoding of the transaction, it's decoded as a part of the block, so that
+// the block JSON is parsed only once.
+type rpcTransactionJSON struct {
+	Type             *hexutil.Uint64 `json:"type"`
+	BlockNumber      *hexutil.Big    `json:"blockNumber"`
+	From             *common.Address `json:"from"`
+	GasLimit         *hexutil.Big    `json:"gas"`
+	GasPrice         *hexutil.Big    `json:"gasPrice"`
+	Hash             *common.Hash    `json:"hash"`
+	Input            hexutil.Bytes   `json:"input"`
+	Nonce            *hexutil.Uint64 `json:"nonce"`
+	To               *common.Address `json:"to"`
+	TransactionIndex *hexutil.Uint64 `json:"transactionIndex"`
+	Value            *hexutil.Big    `json:"value"`
+	V                *hexutil.Big    `json:"v"`
+	R                *hexutil.Big    `json:"r"`
+	S                *hexutil.Big    `json:"s"`
+
+	BlobVersionedHashes []common.Hash `json:"blobVersionedHashes"`
+	MaxFeePerBlobGas    *hexutil.Big  `json:"maxFeePerBlobGas"`
+}
+
+// decode decodes the transaction. In lenient mode missing fields (except hash) are filled with zero values
+// and returned instead of failing.
+func (t *rpcTransaction) decode(input []byte, lenient bool) (missing []string, err error) {
+	var dec rpcTransactionJSON
 	if err := json.Unmarshal(input, &dec); err != nil {
-		return err
+		return nil, err
 	}
+	return t.fromJSON(&dec, lenient)
+}
+
+// fromJSON converts the decoded transaction, see decode.
+func (t *rpcTransaction) fromJSON(dec *rpcTransactionJSON, lenient bool) (missing []string, err error) {
 
-	if dec.BlockNumber == nil {
-		return errors.New("missing required field 'blockNumber'")
+	// require returns error when the field is missing in strict mode, in lenient mode it remembers the field
+	require := func(present bool, name string) error {
+		if present {
+			return nil
+		}
+		if lenient {
+			missing = append(missing, name)
+			return nil
+		}
+		return fmt.Errorf("missing required field '%v'", name)
+	}
+	if dec.Type != nil {
+		t.Type = ethereum.TransactionType(*dec.Type)
 	}
-	t.BlockNumber = (*big.Int)(dec.BlockNumber)
 
-	if dec.From == nil {
-		return errors.New("missing required field 'from'")
+	if err := require(dec.BlockNumber != nil, "blockNumber"); err != nil {
+		return nil, err
+	}
+	if dec.BlockNumber != nil {
+		t.BlockNumber = (*big.Int)(dec.BlockNumber)
 	}
-	t.From = *dec.From
 
-	if dec.GasLimit == nil {
-		return errors.New("missing required field 'gas'")
+	if err := require(dec.From != nil, "from"); err != nil {
+		return nil, err
+	}
+	if dec.From != nil {
+		t.From = *dec.From
 	}
-	t.GasLimit = (*big.Int)(dec.GasLimit)
 
-	if dec.GasPrice == nil {
-		return errors.New("missing required field 'gasPrice'")
+	if err := require(dec.GasLimit != nil, "gas"); err != nil {
+		return nil, err
+	}
+	t.GasLimit = bigOrZero(dec.GasLimit)
+
+	if err := require(dec.GasPrice != nil, "gasPrice"); err != nil {
+		return nil, err
 	}
-	t.GasPrice = (*big.Int)(dec.GasPrice)
+	t.GasPrice = bigOrZero(dec.GasPrice)
 
 	if dec.Hash == nil {
-		return errors.New("missing required field 'hash'")
+		return nil, errors.New("missing required field 'hash'")
 	}
 	t.Hash = *dec.Hash
 
-	if dec.Input == nil {
-		return errors.New("missing required field 'input'")
+	if err := require(dec.Input != nil, "input"); err != nil {
+		return nil, err
 	}
 	t.Input = dec.Input
+	if t.Input == nil {
+		t.Input = []byte{}
+	}
 
-	if dec.Nonce == nil {
-		return errors.New("missing required field 'nonce'")
+	if err := require(dec.Nonce != nil, "nonce"); err != nil {
+		return nil, err
+	}
+	if dec.Nonce != nil {
+		t.Nonce = uint64(*dec.Nonce)
 	}
-	t.Nonce = uint64(*dec.Nonce)
 
 	if dec.To != nil {
 		t.To = dec.To
 	}
 
-	if dec.TransactionIndex == nil {
-		return errors.New("missing required field 'transactionIndex'")
+	if err := require(dec.TransactionIndex != nil, "transactionIndex"); err != nil {
+		return nil, err
+	}
+	if dec.TransactionIndex != nil {
+		t.TransactionIndex = uint64(*dec.TransactionIndex)
 	}
-	t.TransactionIndex = uint64(*dec.TransactionIndex)
 
-	if dec.Value == nil {
-		return errors.New("missing required field 'value'")
+	if err := require(dec.Value != nil, "value"); err != nil {
+		return nil, err
 	}
-	t.Value = (*big.Int)(dec.Value)
+	t.Value = bigOrZero(dec.Value)
 
-	if dec.V == nil {
-		return errors.New("missing required field 'v'")
+	if err := require(dec.V != nil, "v"); err != nil {
+		return nil, err
 	}
-	t.V = (*big.Int)(dec.V)
+	t.V = bigOrZero(dec.V)
 
-	if dec.R == nil {
-		return errors.New("missing required field 'r'")
+	if err := require(dec.R != nil, "r"); err != nil {
+		return nil, err
 	}
-	t.R = (*big.Int)(dec.R)
+	t.R = bigOrZero(dec.R)
 
-	if dec.S == nil {
-		return errors.New("missing required field 's'")
+	if err := require(dec.S != nil, "s"); err != nil {
+		return nil, err
 	}
-	t.S = (*big.Int)(dec.S)
+	t.S = bigOrZero(dec.S)
 
-	return nil
+	if t.Type == ethereum.BlobTxType {
+		if err := require(dec.BlobVersionedHashes != nil, "blobVersionedHashes"); err != nil {
+			return nil, err
+		}
+		t.BlobVersionedHashes = dec.BlobVersionedHashes
+
+		if err := require(dec.MaxFeePerBlobGas != nil, "maxFeePerBlobGas"); err != nil {
+			return nil, err
+		}
+		t.MaxFeePerBlobGas = bigOrZero(dec.MaxFeePerBlobGas)
+	}
+
+	return missing, nil
 }
 
 type rpcReceipt struct {
@@ -288,6 +776,8 @@ type rpcReceipt struct {
 	ContractAddress *common.Address
 	GasUsed         *big.Int
 	Logs            []*types.Log
+	BlobGasUsed     *big.Int
+	BlobGasPrice    *big.Int
 }
 
 func (r *rpcReceipt) UnmarshalJSON(input []byte) error {
@@ -296,6 +786,8 @@ func (r *rpcReceipt) UnmarshalJSON(input []byte) error {
 		ContractAddress *common.Address `json:"contractAddress"`
 		GasUsed         *hexutil.Big    `json:"gasUsed"`
 		Logs            []*types.Log    `json:"logs"`
+		BlobGasUsed     *hexutil.Big    `json:"blobGasUsed"`
+		BlobGasPrice    *hexutil.Big    `json:"blobGasPrice"`
 	}
 	var dec Receipt
 	if err := json.Unmarshal(input, &dec); err != nil {
@@ -316,6 +808,8 @@ func (r *rpcReceipt) UnmarshalJSON(input []byte) error {
 
 	r.GasUsed = (*big.Int)(dec.GasUsed)
 	r.Logs = dec.Logs
+	r.BlobGasUsed = (*big.Int)(dec.BlobGasUsed)
+	r.BlobGasPrice = (*big.Int)(dec.BlobGasPrice)
 
 	return nil
 }
@@ -324,5 +818,8 @@ func toBlockNumArg(number *big.Int) string {
 	if number == nil {
 		return "latest"
 	}
+	if tag, ok := ethereum.BlockNumberTag(number); ok {
+		return tag
+	}
 	return hexutil.EncodeBig(number)
 }
@@ -0,0 +1,218 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/monetha/go-ethereum"
+	"github.com/monetha/go-ethereum/tracing"
+)
+
+func TestToBlockNumArg(t *testing.T) {
+	testCases := []struct {
+		number   *big.Int
+		expected string
+	}{
+		{nil, "latest"},
+		{big.NewInt(0), "0x0"},
+		{big.NewInt(4760755), "0x48a4b3"},
+		{ethereum.PendingBlockNumber, "pending"},
+		{ethereum.LatestBlockNumber, "latest"},
+		{ethereum.FinalizedBlockNumber, "finalized"},
+		{ethereum.SafeBlockNumber, "safe"},
+		{ethereum.EarliestBlockNumber, "earliest"},
+	}
+
+	for _, tc := range testCases {
+		if arg := toBlockNumArg(tc.number); arg != tc.expected {
+			t.Errorf("toBlockNumArg(%v): expected %v, but got %v", tc.number, tc.expected, arg)
+		}
+	}
+}
+
+func TestRPCUncle_UnmarshalJSON(t *testing.T) {
+	raw := `{
+		"difficulty": "0x2d6a9f5e2b9d",
+		"extraData": "0x6e616e6f706f6f6c2e6f7267",
+		"gasLimit": "0x7a121d",
+		"gasUsed": "0x79ef5a",
+		"hash": "0x0b4a4d2b95e3d8a4ff7a7d1b13b9ee9de1f0e83d0d16a8f0b0b0a6cc3bc2d39a",
+		"logsBloom": "0x` + strings.Repeat("00", 256) + `",
+		"miner": "0x52bc44d5378309ee2abf1539bf71de1b7d7be3b5",
+		"mixHash": "0x5b5acbf4bf305f948bd7be176047b20623e1417f75597341a059729165b92397",
+		"nonce": "0xbde3aa4e7f4d0f5b",
+		"number": "0x6f0a6a",
+		"parentHash": "0x3e8dc5d4e4c8a4a5ff9b0f8e1c6f1e9a8fd1bb2c2b0e94f6b5dba48e69d9ff19",
+		"receiptsRoot": "0x56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421",
+		"sha3Uncles": "0x1dcc4de8dec75d7aab85b567b6ccd41ad312451b948a7413f0a142fd40d49347",
+		"stateRoot": "0x7d1e1b7a2a1ec6a14fde7a8c6e0c6a1cb73d2a0d25d7e2d2c1e3b9a1c8c5e0f1",
+		"timestamp": "0x5ab0ec39",
+		"transactionsRoot": "0x56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421",
+		"uncles": []
+	}`
+
+	var u rpcUncle
+	if err := json.Unmarshal([]byte(raw), &u); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	uncle := u.toUncle(big.NewInt(7277164))
+	if uncle.Hash != common.HexToHash("0x0b4a4d2b95e3d8a4ff7a7d1b13b9ee9de1f0e83d0d16a8f0b0b0a6cc3bc2d39a") {
+		t.Errorf("unexpected uncle hash: %v", uncle.Hash.Hex())
+	}
+	if uncle.Miner != common.HexToAddress("0x52bc44d5378309ee2abf1539bf71de1b7d7be3b5") {
+		t.Errorf("unexpected uncle miner: %v", uncle.Miner.Hex())
+	}
+	if uncle.Number.Int64() != 7277162 {
+		t.Errorf("expected uncle number 7277162, but got %v", uncle.Number)
+	}
+	if expected := big.NewInt(2250000000000000000); uncle.Reward.Cmp(expected) != 0 {
+		t.Errorf("expected uncle reward %v, but got %v", expected, uncle.Reward)
+	}
+}
+
+func TestRPCBlock_Withdrawals(t *testing.T) {
+	t.Run("pre-Shanghai block has no withdrawals", func(t *testing.T) {
+		var body rpcBlock
+		if err := json.Unmarshal([]byte(`{"hash":"0x0b4a4d2b95e3d8a4ff7a7d1b13b9ee9de1f0e83d0d16a8f0b0b0a6cc3bc2d39a","transactions":[],"uncles":[]}`), &body); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if body.Withdrawals != nil {
+			t.Errorf("expected nil withdrawals, but got %v", body.Withdrawals)
+		}
+	})
+
+	t.Run("withdrawals are parsed", func(t *testing.T) {
+		var body rpcBlock
+		err := json.Unmarshal([]byte(`{
+			"hash": "0x0b4a4d2b95e3d8a4ff7a7d1b13b9ee9de1f0e83d0d16a8f0b0b0a6cc3bc2d39a",
+			"transactions": [],
+			"uncles": [],
+			"withdrawals": [{"index":"0x1b6f2f1","validatorIndex":"0x5d2a4","address":"0xb9d7934878b5fb9610b3fe8a5e441e8fad7e293f","amount":"0xf2e3a1"}]
+		}`), &body)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(body.Withdrawals) != 1 {
+			t.Fatalf("expected 1 withdrawal, but got %v", len(body.Withdrawals))
+		}
+
+		w := body.Withdrawals[0]
+		if w.Index != 0x1b6f2f1 || w.ValidatorIndex != 0x5d2a4 || w.Amount != 0xf2e3a1 {
+			t.Errorf("unexpected withdrawal: %+v", w)
+		}
+		if w.Address != common.HexToAddress("0xb9d7934878b5fb9610b3fe8a5e441e8fad7e293f") {
+			t.Errorf("unexpected withdrawal address: %v", w.Address.Hex())
+		}
+	})
+
+	t.Run("missing field", func(t *testing.T) {
+		var w rpcWithdrawal
+		if err := json.Unmarshal([]byte(`{"index":"0x1","validatorIndex":"0x2","address":"0xb9d7934878b5fb9610b3fe8a5e441e8fad7e293f"}`), &w); err == nil {
+			t.Error("expected error")
+		}
+	})
+}
+
+func TestDecodeSyncProgress(t *testing.T) {
+	progress, err := decodeSyncProgress(json.RawMessage(`false`))
+	if err != nil || progress != nil {
+		t.Errorf("expected nil progress when not syncing, but got %v, %v", progress, err)
+	}
+
+	progress, err = decodeSyncProgress(json.RawMessage(`{"startingBlock":"0x1","currentBlock":"0x64","highestBlock":"0xc8"}`))
+	if err != nil {
+		t.Fatalf("decodeSyncProgress: %v", err)
+	}
+	if progress.StartingBlock != 1 || progress.CurrentBlock != 100 || progress.HighestBlock != 200 {
+		t.Errorf("unexpected progress %+v", progress)
+	}
+
+	if _, err := decodeSyncProgress(json.RawMessage(`"syncing"`)); err == nil {
+		t.Errorf("expected error for invalid response")
+	}
+}
+
+type recordedSpan struct {
+	attrs map[string]interface{}
+}
+
+func (s *recordedSpan) SetAttribute(key string, value interface{}) { s.attrs[key] = value }
+func (s *recordedSpan) End(err error)                              {}
+
+type recordingTracer struct {
+	names []string
+	spans []*recordedSpan
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string) (context.Context, tracing.Span) {
+	s := &recordedSpan{attrs: make(map[string]interface{})}
+	t.names = append(t.names, name)
+	t.spans = append(t.spans, s)
+	return ctx, s
+}
+
+func TestClient_startSpan(t *testing.T) {
+	tr := &recordingTracer{}
+	c := &Client{}
+	c.SetTracer(tr)
+
+	ctx := context.Background()
+	c.startSpan(withBlockNumber(ctx, big.NewInt(10)), "eth_getBlockByNumber")
+	c.startSpan(withBlockNumber(ctx, ethereum.LatestBlockNumber), "eth_getBalance")
+
+	if len(tr.spans) != 2 || tr.names[0] != "eth_getBlockByNumber" {
+		t.Fatalf("unexpected spans %v", tr.names)
+	}
+	if m := tr.spans[0].attrs[tracing.AttrRPCMethod]; m != "eth_getBlockByNumber" {
+		t.Errorf("expected method attribute eth_getBlockByNumber, but got %v", m)
+	}
+	if bn, _ := tr.spans[0].attrs[tracing.AttrBlockNumber].(*big.Int); bn == nil || bn.Int64() != 10 {
+		t.Errorf("expected block number attribute 10, but got %v", tr.spans[0].attrs[tracing.AttrBlockNumber])
+	}
+	if _, ok := tr.spans[1].attrs[tracing.AttrBlockNumber]; ok {
+		t.Errorf("expected no block number attribute for block tag")
+	}
+}
+
+func TestClient_batchCall(t *testing.T) {
+	srv := newRPCServer(0, balanceHandler)
+	defer srv.Close()
+	c := srv.client(t)
+	tr := &recordingTracer{}
+	c.SetTracer(tr)
+
+	accounts := testAccounts(3)
+	balances := make([]hexutil.Big, len(accounts))
+	reqs := make([]rpc.BatchElem, len(accounts))
+	for i, account := range accounts {
+		reqs[i] = rpc.BatchElem{Method: "eth_getBalance", Args: []interface{}{account, "latest"}, Result: &balances[i]}
+	}
+	if err := c.batchCall(context.Background(), reqs); err != nil {
+		t.Fatalf("batchCall: %v", err)
+	}
+
+	for i, req := range reqs {
+		if req.Error != nil {
+			t.Fatalf("request %v: %v", i, req.Error)
+		}
+		if balances[i].ToInt().Int64() != int64(i) {
+			t.Errorf("expected balance %v, but got %v", i, balances[i].ToInt())
+		}
+	}
+	if len(srv.batches) != 1 || srv.batches[0] != 3 {
+		t.Errorf("expected a batch of 3 requests, but got %v", srv.batches)
+	}
+	if len(tr.spans) != 1 || tr.names[0] != "eth_getBalance" {
+		t.Fatalf("expected one span, but got %v", tr.names)
+	}
+	if size := tr.spans[0].attrs[tracing.AttrBatchSize]; size != 3 {
+		t.Errorf("expected batch size attribute 3, but got %v", size)
+	}
+}
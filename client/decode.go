@@ -0,0 +1,119 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/monetha/go-ethereum"
+)
+
+// maxPooledTransactions limits the capacity of buffers of decoded transactions returned to the pool, so that
+// a rare huge block doesn't pin the memory.
+const maxPooledTransactions = 16384
+
+// transactionsPool keeps buffers of decoded transactions, since blocks are decoded one after another
+// during backfill.
+var transactionsPool = sync.Pool{
+	New: func() interface{} { return new([]rpcTransactionJSON) },
+}
+
+// decodeBlock decodes the block JSON in a single pass: header fields, body and transactions are decoded
+// together, transactions are allocated at once. Receipts and uncles aren't loaded.
+func (c *Client) decodeBlock(raw json.RawMessage) (*ethereum.Block, error) {
+	buf := transactionsPool.Get().(*[]rpcTransactionJSON)
+	body := rpcBlock{Transactions: (*buf)[:0]}
+	defer func() {
+		// decoding into the slice reuses elements of the backing array without zeroing them
+		for i := range body.Transactions {
+			body.Transactions[i] = rpcTransactionJSON{}
+		}
+		if cap(body.Transactions) <= maxPooledTransactions {
+			*buf = body.Transactions[:0]
+			transactionsPool.Put(buf)
+		}
+	}()
+
+	if err := json.Unmarshal(raw, &body); err != nil {
+		return nil, err
+	}
+	if err := body.validate(); err != nil {
+		return nil, err
+	}
+	number := (*big.Int)(body.Number)
+
+	txs, err := c.decodeTransactions(number, body.Transactions)
+	if err != nil {
+		return nil, err
+	}
+	btxs := make(ethereum.Transactions, len(txs))
+	slab := make([]ethereum.Transaction, len(txs))
+	for i := range txs {
+		txs[i].copyTo(&slab[i])
+		btxs[i] = &slab[i]
+	}
+
+	block := body.toBlock()
+	block.Transactions = btxs
+	return block, nil
+}
+
+// toBlock returns the block without transactions, the block must be validated.
+func (b *rpcBlock) toBlock() *ethereum.Block {
+	block := &ethereum.Block{
+		Difficulty:  (*big.Int)(b.Difficulty),
+		ExtraData:   *b.ExtraData,
+		GasLimit:    new(big.Int).SetUint64(uint64(*b.GasLimit)),
+		GasUsed:     new(big.Int).SetUint64(uint64(*b.GasUsed)),
+		Hash:        b.Hash,
+		ParentHash:  *b.ParentHash,
+		Miner:       *b.Miner,
+		Number:      (*big.Int)(b.Number),
+		Timestamp:   uint64(*b.Timestamp),
+		UncleHashes: b.UncleHashes,
+
+		BlobGasUsed:   (*big.Int)(b.BlobGasUsed),
+		ExcessBlobGas: (*big.Int)(b.ExcessBlobGas),
+
+		LogsBloom: *b.LogsBloom,
+	}
+
+	if b.Withdrawals != nil {
+		block.Withdrawals = make(ethereum.Withdrawals, len(b.Withdrawals))
+		for i, w := range b.Withdrawals {
+			block.Withdrawals[i] = &ethereum.Withdrawal{
+				Index:          w.Index,
+				ValidatorIndex: w.ValidatorIndex,
+				Address:        w.Address,
+				Amount:         w.Amount,
+			}
+		}
+	}
+
+	return block
+}
+
+// validate checks that header fields of the block are present.
+func (b *rpcBlock) validate() error {
+	required := []struct {
+		present bool
+		name    string
+	}{
+		{b.Number != nil, "number"},
+		{b.ParentHash != nil, "parentHash"},
+		{b.Miner != nil, "miner"},
+		{b.Difficulty != nil, "difficulty"},
+		{b.ExtraData != nil, "extraData"},
+		{b.GasLimit != nil, "gasLimit"},
+		{b.GasUsed != nil, "gasUsed"},
+		{b.Timestamp != nil, "timestamp"},
+		{b.LogsBloom != nil, "logsBloom"},
+	}
+	for _, f := range required {
+		if !f.present {
+			return fmt.Errorf("missing required field '%v' for Header", f.name)
+		}
+	}
+	return nil
+}
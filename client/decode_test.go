@@ -0,0 +1,100 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// blockJSON returns JSON of the block with the given number of transactions.
+func blockJSON(txCount int) json.RawMessage {
+	txs := make([]string, txCount)
+	for i := range txs {
+		txs[i] = fmt.Sprintf(`{
+			"blockHash": "0x0b4a4d2b95e3d8a4ff7a7d1b13b9ee9de1f0e83d0d16a8f0b0b0a6cc3bc2d39a",
+			"blockNumber": "0x6f0a6a",
+			"from": "0xdeaddeaddeaddeaddeaddeaddeaddeaddead0001",
+			"gas": "0x5208",
+			"gasPrice": "0x4a817c800",
+			"hash": "0x%064x",
+			"input": "0xa9059cbb000000000000000000000000b9d7934878b5fb9610b3fe8a5e441e8fad7e293f0000000000000000000000000000000000000000000000000de0b6b3a7640000",
+			"nonce": "0x%x",
+			"to": "0xb9d7934878b5fb9610b3fe8a5e441e8fad7e293f",
+			"transactionIndex": "0x%x",
+			"value": "0xde0b6b3a7640000",
+			"v": "0x25",
+			"r": "0x1b5e176d927f8e9ab405058b2d2457392da3e20f328b16ddabcebc33eaac5fea",
+			"s": "0x4ba69724e8f69de52f0125ad8b3c5c2cef33019bac3249e2c0a2192766d1721c"
+		}`, i+1, i, i)
+	}
+
+	return json.RawMessage(`{
+		"difficulty": "0x2d6a9f5e2b9d",
+		"extraData": "0x6e616e6f706f6f6c2e6f7267",
+		"gasLimit": "0x7a121d",
+		"gasUsed": "0x79ef5a",
+		"hash": "0x0b4a4d2b95e3d8a4ff7a7d1b13b9ee9de1f0e83d0d16a8f0b0b0a6cc3bc2d39a",
+		"logsBloom": "0x` + strings.Repeat("00", 256) + `",
+		"miner": "0x52bc44d5378309ee2abf1539bf71de1b7d7be3b5",
+		"mixHash": "0x5b5acbf4bf305f948bd7be176047b20623e1417f75597341a059729165b92397",
+		"nonce": "0xbde3aa4e7f4d0f5b",
+		"number": "0x6f0a6a",
+		"parentHash": "0x3e8dc5d4e4c8a4a5ff9b0f8e1c6f1e9a8fd1bb2c2b0e94f6b5dba48e69d9ff19",
+		"receiptsRoot": "0x56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421",
+		"sha3Uncles": "0x1dcc4de8dec75d7aab85b567b6ccd41ad312451b948a7413f0a142fd40d49347",
+		"stateRoot": "0x7d1e1b7a2a1ec6a14fde7a8c6e0c6a1cb73d2a0d25d7e2d2c1e3b9a1c8c5e0f1",
+		"timestamp": "0x5ab0ec39",
+		"transactionsRoot": "0x56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421",
+		"transactions": [` + strings.Join(txs, ",") + `],
+		"uncles": []
+	}`)
+}
+
+func TestClient_decodeBlock(t *testing.T) {
+	c := &Client{}
+
+	// decode twice to check that pooled buffers don't leak values between blocks
+	for _, count := range []int{3, 2} {
+		b, err := c.decodeBlock(blockJSON(count))
+		if err != nil {
+			t.Fatalf("decodeBlock: %v", err)
+		}
+
+		if b.Number.Int64() != 0x6f0a6a || b.GasLimit.Int64() != 0x7a121d || b.Timestamp != 0x5ab0ec39 {
+			t.Errorf("unexpected block header: %v", b)
+		}
+		if b.Miner != common.HexToAddress("0x52bc44d5378309ee2abf1539bf71de1b7d7be3b5") {
+			t.Errorf("unexpected miner: %v", b.Miner.Hex())
+		}
+		if len(b.Transactions) != count {
+			t.Fatalf("expected %v transactions, but got %v", count, len(b.Transactions))
+		}
+		for i, tx := range b.Transactions {
+			if tx.Hash != common.BigToHash(big.NewInt(int64(i+1))) || tx.Nonce != uint64(i) || tx.TransactionIndex != uint64(i) {
+				t.Errorf("unexpected transaction %v: %v", i, tx)
+			}
+		}
+	}
+
+	if _, err := c.decodeBlock(json.RawMessage(`{"hash":"0x0b4a4d2b95e3d8a4ff7a7d1b13b9ee9de1f0e83d0d16a8f0b0b0a6cc3bc2d39a","transactions":[]}`)); err == nil {
+		t.Error("expected error for missing header fields")
+	}
+}
+
+func BenchmarkClient_decodeBlock(b *testing.B) {
+	c := &Client{}
+	raw := blockJSON(5000)
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(raw)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.decodeBlock(raw); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package client
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// MissingFieldsFunc is called in lenient decoding mode with the names of JSON fields absent in the transaction
+// of the block.
+type MissingFieldsFunc func(blockNumber *big.Int, txHash common.Hash, fields []string)
+
+// SetLenientDecoding turns on lenient decoding of block transactions, which is needed for non-standard nodes
+// (e.g. some L2 chains and sidechains omit fields of system transactions). Missing fields are filled with
+// zero values (block number and transaction index are taken from the block), and reported to the given
+// function (it can be nil), instead of failing the whole block. Transaction hash is required in any mode.
+// It must be called before the client is used by several goroutines.
+func (c *Client) SetLenientDecoding(missingFields MissingFieldsFunc) {
+	c.lenient = true
+	c.missingFields = missingFields
+}
+
+func (c *Client) decodeTransactions(blockNumber *big.Int, decs []rpcTransactionJSON) ([]rpcTransaction, error) {
+	txs := make([]rpcTransaction, len(decs))
+	for i := range decs {
+		if err := c.decodeTransaction(blockNumber, i, &decs[i], &txs[i]); err != nil {
+			return nil, err
+		}
+	}
+	return txs, nil
+}
+
+// decodeTransaction converts the decoded transaction with the index i of the block.
+func (c *Client) decodeTransaction(blockNumber *big.Int, i int, dec *rpcTransactionJSON, tx *rpcTransaction) error {
+	missing, err := tx.fromJSON(dec, c.lenient)
+	if err != nil {
+		return fmt.Errorf("transaction %d of block %v: %v", i, blockNumber, err)
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	for _, field := range missing {
+		switch field {
+		case "blockNumber":
+			tx.BlockNumber = blockNumber
+		case "transactionIndex":
+			tx.TransactionIndex = uint64(i)
+		}
+	}
+	if c.missingFields != nil {
+		c.missingFields(blockNumber, tx.Hash, missing)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package client
+
+import (
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestClient_decodeTransactions(t *testing.T) {
+	systemTx := json.RawMessage(`{
+		"hash": "0x0b4a4d2b95e3d8a4ff7a7d1b13b9ee9de1f0e83d0d16a8f0b0b0a6cc3bc2d39a",
+		"from": "0xdeaddeaddeaddeaddeaddeaddeaddeaddead0001",
+		"to": "0x4200000000000000000000000000000000000015",
+		"gas": "0xf4240",
+		"input": "0x",
+		"nonce": "0x1",
+		"value": "0x0"
+	}`)
+	blockNumber := big.NewInt(100)
+	decode := func(raw json.RawMessage) []rpcTransactionJSON {
+		var dec rpcTransactionJSON
+		if err := json.Unmarshal(raw, &dec); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return []rpcTransactionJSON{dec}
+	}
+
+	t.Run("strict", func(t *testing.T) {
+		c := &Client{}
+		if _, err := c.decodeTransactions(blockNumber, decode(systemTx)); err == nil {
+			t.Error("expected error")
+		}
+	})
+
+	t.Run("lenient", func(t *testing.T) {
+		c := &Client{}
+		var reported []string
+		c.SetLenientDecoding(func(bn *big.Int, txHash common.Hash, fields []string) {
+			reported = fields
+		})
+
+		txs, err := c.decodeTransactions(blockNumber, decode(systemTx))
+		if err != nil {
+			t.Fatalf("decodeTransactions: %v", err)
+		}
+
+		expected := []string{"blockNumber", "gasPrice", "transactionIndex", "v", "r", "s"}
+		if !reflect.DeepEqual(reported, expected) {
+			t.Errorf("expected missing fields %v, but got %v", expected, reported)
+		}
+
+		tx := txs[0]
+		if tx.BlockNumber.Cmp(blockNumber) != 0 || tx.TransactionIndex != 0 {
+			t.Errorf("expected block number and index to be taken from the block, but got %v and %v", tx.BlockNumber, tx.TransactionIndex)
+		}
+		if tx.GasPrice == nil || tx.GasPrice.Sign() != 0 || tx.V == nil || tx.R == nil || tx.S == nil {
+			t.Errorf("expected zero values of missing fields, but got %+v", tx)
+		}
+	})
+
+	t.Run("hash is required", func(t *testing.T) {
+		c := &Client{}
+		c.SetLenientDecoding(nil)
+		if _, err := c.decodeTransactions(blockNumber, decode(json.RawMessage(`{"from":"0xdeaddeaddeaddeaddeaddeaddeaddeaddead0001"}`))); err == nil {
+			t.Error("expected error")
+		}
+	})
+}
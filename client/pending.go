@@ -0,0 +1,137 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/monetha/go-ethereum"
+)
+
+// PendingBlock returns the pending block with transactions. Receipts aren't loaded, so gas used, logs and status
+// of transactions are empty. Fields which nodes don't fill for the pending block (e.g. hash and miner) are zero.
+func (c *Client) PendingBlock(ctx context.Context) (*ethereum.Block, error) {
+	var raw json.RawMessage
+	if err := c.call(ctx, &raw, "eth_getBlockByNumber", "pending", true); err != nil {
+		return nil, fmt.Errorf("eth_getBlockByNumber: %v", err)
+	} else if len(raw) == 0 || string(raw) == "null" {
+		return nil, ethereum.ErrNotFound
+	}
+
+	block, err := decodePendingBlock(raw)
+	if err != nil {
+		return nil, fmt.Errorf("eth_getBlockByNumber: %v", err)
+	}
+	return block, nil
+}
+
+func decodePendingBlock(raw json.RawMessage) (*ethereum.Block, error) {
+	var dec struct {
+		Number       *hexutil.Big      `json:"number"`
+		ParentHash   common.Hash       `json:"parentHash"`
+		Difficulty   *hexutil.Big      `json:"difficulty"`
+		ExtraData    hexutil.Bytes     `json:"extraData"`
+		GasLimit     *hexutil.Big      `json:"gasLimit"`
+		GasUsed      *hexutil.Big      `json:"gasUsed"`
+		Timestamp    *hexutil.Uint64   `json:"timestamp"`
+		Transactions []json.RawMessage `json:"transactions"`
+	}
+	if err := json.Unmarshal(raw, &dec); err != nil {
+		return nil, err
+	}
+	if dec.Number == nil {
+		return nil, errors.New("missing required field 'number'")
+	}
+	number := (*big.Int)(dec.Number)
+
+	block := &ethereum.Block{
+		Difficulty:   bigOrZero(dec.Difficulty),
+		ExtraData:    dec.ExtraData,
+		GasLimit:     bigOrZero(dec.GasLimit),
+		GasUsed:      bigOrZero(dec.GasUsed),
+		Number:       number,
+		ParentHash:   dec.ParentHash,
+		Transactions: make(ethereum.Transactions, 0, len(dec.Transactions)),
+	}
+	if dec.Timestamp != nil {
+		block.Timestamp = uint64(*dec.Timestamp)
+	}
+
+	for i, raw := range dec.Transactions {
+		var tx rpcTransaction
+		// pending transactions may have no block number and index
+		if _, err := tx.decode(raw, true); err != nil {
+			return nil, fmt.Errorf("pending transaction %d: %v", i, err)
+		}
+		tx.BlockNumber = number
+		tx.TransactionIndex = uint64(i)
+		block.Transactions = append(block.Transactions, tx.toTransaction())
+	}
+
+	return block, nil
+}
+
+// PendingTransactionsFrom returns transactions of the account which aren't mined yet, ordered by nonce.
+// When the node supports txpool methods (see TxPoolCapability), both pending and queued (waiting for a nonce gap
+// to be filled) transactions of the transaction pool are returned, otherwise transactions of the pending block.
+// Block number of returned transactions is nil.
+func (c *Client) PendingTransactionsFrom(ctx context.Context, account common.Address) (ethereum.Transactions, error) {
+	var raws []json.RawMessage
+	if c.caps.Has(TxPoolCapability) {
+		var content struct {
+			Pending map[string]json.RawMessage `json:"pending"`
+			Queued  map[string]json.RawMessage `json:"queued"`
+		}
+		if err := c.call(ctx, &content, "txpool_contentFrom", account); err != nil {
+			return nil, fmt.Errorf("txpool_contentFrom: %v", err)
+		}
+		for _, raw := range content.Pending {
+			raws = append(raws, raw)
+		}
+		for _, raw := range content.Queued {
+			raws = append(raws, raw)
+		}
+	} else {
+		var block struct {
+			Transactions []json.RawMessage `json:"transactions"`
+		}
+		if err := c.call(ctx, &block, "eth_getBlockByNumber", "pending", true); err != nil {
+			return nil, fmt.Errorf("eth_getBlockByNumber: %v", err)
+		}
+		raws = block.Transactions
+	}
+
+	return transactionsFrom(raws, account)
+}
+
+// transactionsFrom decodes transactions and returns the ones sent from the account ordered by nonce.
+func transactionsFrom(raws []json.RawMessage, account common.Address) (ethereum.Transactions, error) {
+	var txs ethereum.Transactions
+	for _, raw := range raws {
+		var tx rpcTransaction
+		if _, err := tx.decode(raw, true); err != nil {
+			return nil, fmt.Errorf("pending transaction: %v", err)
+		}
+		if tx.From != account {
+			continue
+		}
+		tx.BlockNumber = nil
+		tx.TransactionIndex = 0
+		txs = append(txs, tx.toTransaction())
+	}
+
+	sort.Slice(txs, func(i, j int) bool { return txs[i].Nonce < txs[j].Nonce })
+	return txs, nil
+}
+
+func bigOrZero(b *hexutil.Big) *big.Int {
+	if b == nil {
+		return new(big.Int)
+	}
+	return (*big.Int)(b)
+}
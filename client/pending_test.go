@@ -0,0 +1,82 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const pendingTxJSON = `{
+	"blockHash": null,
+	"blockNumber": null,
+	"from": "%s",
+	"gas": "0x5208",
+	"gasPrice": "0x3b9aca00",
+	"hash": "%s",
+	"input": "0x",
+	"nonce": "%s",
+	"to": "0x52bc44d5378309ee2abf1539bf71de1b7d7be3b5",
+	"transactionIndex": null,
+	"value": "0x1",
+	"v": "0x1c",
+	"r": "0x1",
+	"s": "0x1"
+}`
+
+func pendingTx(from, hash, nonce string) json.RawMessage {
+	return json.RawMessage(fmt.Sprintf(pendingTxJSON, from, hash, nonce))
+}
+
+func TestDecodePendingBlock(t *testing.T) {
+	from := "0xb9d7934878b5fb9610b3fe8a5e441e8fad7e293f"
+	raw := `{
+		"hash": null,
+		"miner": null,
+		"nonce": null,
+		"number": "0x64",
+		"difficulty": "0x1",
+		"extraData": "0x",
+		"gasLimit": "0x7a1200",
+		"gasUsed": "0x5208",
+		"timestamp": "0x5c9b5f2a",
+		"transactions": [` + string(pendingTx(from, "0x0b4a4d2b95e3d8a4ff7a7d1b13b9ee9de1f0e83d0d16a8f0b0b0a6cc3bc2d39a", "0x1")) + `]
+	}`
+
+	block, err := decodePendingBlock(json.RawMessage(raw))
+	if err != nil {
+		t.Fatalf("decodePendingBlock: %v", err)
+	}
+	if block.Number.Int64() != 100 || len(block.Transactions) != 1 {
+		t.Fatalf("unexpected block %v", block)
+	}
+	if tx := block.Transactions[0]; tx.BlockNumber.Int64() != 100 || tx.From != common.HexToAddress(from) || tx.Nonce != 1 {
+		t.Errorf("unexpected transaction %v", tx)
+	}
+}
+
+func TestTransactionsFrom(t *testing.T) {
+	account := common.HexToAddress("0xb9d7934878b5fb9610b3fe8a5e441e8fad7e293f")
+	other := "0x52bc44d5378309ee2abf1539bf71de1b7d7be3b5"
+
+	raws := []json.RawMessage{
+		pendingTx(account.Hex(), common.BytesToHash([]byte{0x01}).Hex(), "0x5"),
+		pendingTx(other, common.BytesToHash([]byte{0x02}).Hex(), "0x1"),
+		pendingTx(account.Hex(), common.BytesToHash([]byte{0x03}).Hex(), "0x3"),
+	}
+
+	txs, err := transactionsFrom(raws, account)
+	if err != nil {
+		t.Fatalf("transactionsFrom: %v", err)
+	}
+	if len(txs) != 2 {
+		t.Fatalf("expected 2 transactions, but got %v", len(txs))
+	}
+	if txs[0].Nonce != 3 || txs[1].Nonce != 5 {
+		t.Errorf("expected transactions ordered by nonce, but got nonces %v and %v", txs[0].Nonce, txs[1].Nonce)
+	}
+	if txs[0].BlockNumber != nil {
+		t.Errorf("expected nil block number, but got %v", txs[0].BlockNumber)
+	}
+}
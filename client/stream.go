@@ -0,0 +1,204 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+
+	"github.com/monetha/go-ethereum"
+)
+
+// SetHTTPEndpoint sets the HTTP endpoint of the node used by StreamBlockByNumber (the endpoint dialed by Dial is used
+// when it's HTTP). If httpClient is nil, http.DefaultClient is used. It must be called before the client is used by
+// several goroutines.
+func (c *Client) SetHTTPEndpoint(url string, httpClient *http.Client) {
+	c.httpURL = url
+	c.httpClient = httpClient
+}
+
+// StreamBlockByNumber works like BlockByNumber, but transactions are decoded one by one while the response is read
+// and passed to fn instead of being collected, so peak memory doesn't grow with the size of the block (e.g. blocks
+// with thousands of transactions during backfill). The returned block has no transactions and receipts aren't
+// retrieved (see TransactionReceipts). Error returned by fn stops decoding and is returned. It requires the HTTP
+// endpoint (see SetHTTPEndpoint), since RPC client buffers whole responses.
+func (c *Client) StreamBlockByNumber(ctx context.Context, number *big.Int, fn func(tx *ethereum.Transaction) error) (b *ethereum.Block, err error) {
+	if c.httpURL == "" {
+		return nil, errors.New("StreamBlockByNumber: HTTP endpoint isn't set")
+	}
+
+	ctx, span := c.startSpan(withBlockNumber(ctx, number), "eth_getBlockByNumber")
+	defer func() { span.End(err) }()
+
+	body, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "eth_getBlockByNumber",
+		"params":  []interface{}{toBlockNumArg(number), true},
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, c.httpURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+
+	hc := c.httpClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("eth_getBlockByNumber: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("eth_getBlockByNumber: %v", resp.Status)
+	}
+
+	return c.decodeResponseStream(json.NewDecoder(resp.Body), fn)
+}
+
+// decodeResponseStream decodes JSON-RPC response with the block.
+func (c *Client) decodeResponseStream(dec *json.Decoder, fn func(tx *ethereum.Transaction) error) (*ethereum.Block, error) {
+	if err := expectDelim(dec, '{'); err != nil {
+		return nil, err
+	}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		switch key {
+		case "result":
+			tok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			switch tok {
+			case nil:
+				return nil, ethereum.ErrNotFound
+			case json.Delim('{'):
+				return c.decodeBlockStream(dec, fn)
+			default:
+				return nil, fmt.Errorf("eth_getBlockByNumber: unexpected result %v", tok)
+			}
+		case "error":
+			var rpcErr struct {
+				Code    int    `json:"code"`
+				Message string `json:"message"`
+			}
+			if err := dec.Decode(&rpcErr); err != nil {
+				return nil, err
+			}
+			return nil, fmt.Errorf("eth_getBlockByNumber: %v (code %v)", rpcErr.Message, rpcErr.Code)
+		default:
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return nil, errors.New("eth_getBlockByNumber: missing result")
+}
+
+// decodeBlockStream decodes the block object following its opening brace, transactions are passed to fn.
+// In lenient mode missing block number of transactions is taken from the block only when it precedes
+// transactions (e.g. geth sorts fields by name).
+func (c *Client) decodeBlockStream(dec *json.Decoder, fn func(tx *ethereum.Transaction) error) (*ethereum.Block, error) {
+	var body rpcBlock
+	fields := map[string]interface{}{
+		"number":        &body.Number,
+		"hash":          &body.Hash,
+		"parentHash":    &body.ParentHash,
+		"miner":         &body.Miner,
+		"difficulty":    &body.Difficulty,
+		"extraData":     &body.ExtraData,
+		"gasLimit":      &body.GasLimit,
+		"gasUsed":       &body.GasUsed,
+		"timestamp":     &body.Timestamp,
+		"logsBloom":     &body.LogsBloom,
+		"uncles":        &body.UncleHashes,
+		"blobGasUsed":   &body.BlobGasUsed,
+		"excessBlobGas": &body.ExcessBlobGas,
+		"withdrawals":   &body.Withdrawals,
+	}
+
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, _ := tok.(string)
+
+		if key == "transactions" {
+			if err := c.decodeTransactionsStream(dec, (*big.Int)(body.Number), fn); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		field, ok := fields[key]
+		if !ok {
+			var skip json.RawMessage
+			field = &skip
+		}
+		if err := dec.Decode(field); err != nil {
+			return nil, fmt.Errorf("block field '%v': %v", key, err)
+		}
+	}
+	if err := expectDelim(dec, '}'); err != nil {
+		return nil, err
+	}
+
+	if err := body.validate(); err != nil {
+		return nil, err
+	}
+	return body.toBlock(), nil
+}
+
+func (c *Client) decodeTransactionsStream(dec *json.Decoder, blockNumber *big.Int, fn func(tx *ethereum.Transaction) error) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok == nil {
+		return nil
+	}
+	if tok != json.Delim('[') {
+		return fmt.Errorf("unexpected transactions %v", tok)
+	}
+
+	for i := 0; dec.More(); i++ {
+		var txJSON rpcTransactionJSON
+		if err := dec.Decode(&txJSON); err != nil {
+			return fmt.Errorf("transaction %d of block %v: %v", i, blockNumber, err)
+		}
+		var tx rpcTransaction
+		if err := c.decodeTransaction(blockNumber, i, &txJSON, &tx); err != nil {
+			return err
+		}
+		if err := fn(tx.toTransaction()); err != nil {
+			return err
+		}
+	}
+	return expectDelim(dec, ']')
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != delim {
+		return fmt.Errorf("expected %v, but got %v", delim, tok)
+	}
+	return nil
+}
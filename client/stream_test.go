@@ -0,0 +1,92 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/monetha/go-ethereum"
+)
+
+func TestClient_StreamBlockByNumber(t *testing.T) {
+	var response string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req, _ := ioutil.ReadAll(r.Body)
+		if !strings.Contains(string(req), `"params":["0x6f0a6a",true]`) {
+			t.Errorf("unexpected request: %s", req)
+		}
+		fmt.Fprint(w, response)
+	}))
+	defer srv.Close()
+
+	c := NewClient(nil)
+	c.SetHTTPEndpoint(srv.URL, nil)
+	number := big.NewInt(0x6f0a6a)
+
+	t.Run("block", func(t *testing.T) {
+		response = `{"jsonrpc":"2.0","id":1,"result":` + string(blockJSON(3)) + `}`
+
+		var txs ethereum.Transactions
+		b, err := c.StreamBlockByNumber(context.Background(), number, func(tx *ethereum.Transaction) error {
+			txs = append(txs, tx)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("StreamBlockByNumber: %v", err)
+		}
+		if b.Number.Cmp(number) != 0 || b.GasUsed.Int64() != 0x79ef5a {
+			t.Errorf("unexpected block header: %v", b)
+		}
+		if len(b.Transactions) != 0 {
+			t.Errorf("expected no transactions in the block, but got %v", len(b.Transactions))
+		}
+		if len(txs) != 3 {
+			t.Fatalf("expected 3 transactions, but got %v", len(txs))
+		}
+		for i, tx := range txs {
+			if tx.TransactionIndex != uint64(i) || tx.BlockNumber.Cmp(number) != 0 {
+				t.Errorf("unexpected transaction %v: %v", i, tx)
+			}
+		}
+	})
+
+	t.Run("callback error", func(t *testing.T) {
+		response = `{"jsonrpc":"2.0","id":1,"result":` + string(blockJSON(3)) + `}`
+
+		stop := errors.New("stop")
+		calls := 0
+		_, err := c.StreamBlockByNumber(context.Background(), number, func(tx *ethereum.Transaction) error {
+			calls++
+			return stop
+		})
+		if err != stop || calls != 1 {
+			t.Errorf("expected error %v after 1 call, but got %v after %v calls", stop, err, calls)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		response = `{"jsonrpc":"2.0","id":1,"result":null}`
+		if _, err := c.StreamBlockByNumber(context.Background(), number, nil); err != ethereum.ErrNotFound {
+			t.Errorf("expected error %v, but got %v", ethereum.ErrNotFound, err)
+		}
+	})
+
+	t.Run("RPC error", func(t *testing.T) {
+		response = `{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"header not found"}}`
+		if _, err := c.StreamBlockByNumber(context.Background(), number, nil); err == nil || !strings.Contains(err.Error(), "header not found") {
+			t.Errorf("expected RPC error, but got %v", err)
+		}
+	})
+
+	t.Run("no endpoint", func(t *testing.T) {
+		if _, err := NewClient(nil).StreamBlockByNumber(context.Background(), number, nil); err == nil {
+			t.Error("expected error")
+		}
+	})
+}
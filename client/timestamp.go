@@ -0,0 +1,157 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/monetha/go-ethereum"
+)
+
+// blockTimeWindow is the number of recent blocks average block time is computed over by EstimateBlockAt.
+const blockTimeWindow = 1000
+
+// blockTimestampFunc returns the timestamp of the block with the given number.
+type blockTimestampFunc func(ctx context.Context, number uint64) (uint64, error)
+
+// BlockNumberByTimestamp returns the number of the block whose timestamp is the closest to t (the earlier block
+// when two blocks are equally close). It binary-searches block headers, so it makes about log2(chain height)
+// requests.
+func (c *Client) BlockNumberByTimestamp(ctx context.Context, t time.Time) (*big.Int, error) {
+	latest, latestTime, err := c.latestBlockTime(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := closestBlock(ctx, c.blockTimestamp, uint64(t.Unix()), latest, latestTime)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetUint64(n), nil
+}
+
+// BlockByTimestamp returns the block whose timestamp is the closest to t (see BlockNumberByTimestamp).
+func (c *Client) BlockByTimestamp(ctx context.Context, t time.Time) (*ethereum.Block, error) {
+	number, err := c.BlockNumberByTimestamp(ctx, t)
+	if err != nil {
+		return nil, err
+	}
+	return c.BlockByNumber(ctx, number)
+}
+
+// EstimateBlockAt estimates the number of the block which will be mined at t, based on the average block time
+// of recent blocks. The number of the closest existing block is returned when t isn't in the future.
+func (c *Client) EstimateBlockAt(ctx context.Context, t time.Time) (*big.Int, error) {
+	latest, latestTime, err := c.latestBlockTime(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	target := uint64(t.Unix())
+	if target <= latestTime {
+		n, err := closestBlock(ctx, c.blockTimestamp, target, latest, latestTime)
+		if err != nil {
+			return nil, err
+		}
+		return new(big.Int).SetUint64(n), nil
+	}
+
+	window := uint64(blockTimeWindow)
+	if window > latest {
+		window = latest
+	}
+	if window == 0 {
+		return nil, errors.New("estimating block time: chain has only genesis block")
+	}
+	pastTime, err := c.blockTimestamp(ctx, latest-window)
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetUint64(estimateBlock(latest, latestTime, target, latestTime-pastTime, window)), nil
+}
+
+func (c *Client) latestBlockTime(ctx context.Context) (number, timestamp uint64, err error) {
+	latest, err := c.BlockNumber(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	number = latest.Uint64()
+	timestamp, err = c.blockTimestamp(ctx, number)
+	return
+}
+
+func (c *Client) blockTimestamp(ctx context.Context, number uint64) (uint64, error) {
+	var raw json.RawMessage
+	err := c.call(withBlockNumber(ctx, new(big.Int).SetUint64(number)), &raw, "eth_getBlockByNumber", hexutil.Uint64(number), false)
+	if err != nil {
+		return 0, fmt.Errorf("eth_getBlockByNumber: %v", err)
+	} else if len(raw) == 0 || string(raw) == "null" {
+		return 0, ethereum.ErrNotFound
+	}
+
+	var header struct {
+		Timestamp *hexutil.Uint64 `json:"timestamp"`
+	}
+	if err := json.Unmarshal(raw, &header); err != nil {
+		return 0, err
+	}
+	if header.Timestamp == nil {
+		return 0, errors.New("missing required field 'timestamp'")
+	}
+	return uint64(*header.Timestamp), nil
+}
+
+// closestBlock returns the number of the block in range [0, latest] whose timestamp is the closest to target.
+func closestBlock(ctx context.Context, timestamp blockTimestampFunc, target, latest, latestTime uint64) (uint64, error) {
+	if target >= latestTime {
+		return latest, nil
+	}
+
+	loTime, err := timestamp(ctx, 0)
+	if err != nil {
+		return 0, err
+	}
+	if target <= loTime {
+		return 0, nil
+	}
+
+	// find the last block with timestamp <= target, the next block is the first one with timestamp > target
+	lo, hi, hiTime := uint64(0), latest, latestTime
+
+	for hi-lo > 1 {
+		mid := lo + (hi-lo)/2
+		midTime, err := timestamp(ctx, mid)
+		if err != nil {
+			return 0, err
+		}
+		if midTime <= target {
+			lo, loTime = mid, midTime
+		} else {
+			hi, hiTime = mid, midTime
+		}
+	}
+
+	if hiTime-target < target-loTime {
+		return hi, nil
+	}
+	return lo, nil
+}
+
+// estimateBlock extrapolates the number of the block mined at target time from the latest block, given that
+// `blocks` recent blocks were mined in `duration` seconds.
+func estimateBlock(latest, latestTime, target, duration, blocks uint64) uint64 {
+	if duration == 0 {
+		return latest
+	}
+	ahead := new(big.Int).SetUint64(target - latestTime)
+	ahead.Mul(ahead, new(big.Int).SetUint64(blocks))
+	// round to the nearest block
+	ahead.Add(ahead, new(big.Int).SetUint64(duration/2))
+	ahead.Div(ahead, new(big.Int).SetUint64(duration))
+	return latest + ahead.Uint64()
+}
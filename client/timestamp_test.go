@@ -0,0 +1,71 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestClosestBlock(t *testing.T) {
+	// block n is mined at 1000 + 12*n, block 5 is late
+	times := []uint64{1000, 1012, 1024, 1036, 1048, 1070, 1072, 1084}
+	calls := 0
+	timestamp := func(ctx context.Context, number uint64) (uint64, error) {
+		calls++
+		if number >= uint64(len(times)) {
+			return 0, errors.New("unknown block")
+		}
+		return times[number], nil
+	}
+	latest := uint64(len(times) - 1)
+
+	tests := []struct {
+		target   uint64
+		expected uint64
+	}{
+		{900, 0},
+		{1000, 0},
+		{1005, 0},
+		{1006, 0}, // equally close, the earlier block
+		{1007, 1},
+		{1036, 3},
+		{1058, 4},
+		{1060, 5},
+		{1071, 5},
+		{1084, 7},
+		{2000, 7},
+	}
+
+	for _, tt := range tests {
+		calls = 0
+		n, err := closestBlock(context.Background(), timestamp, tt.target, latest, times[latest])
+		if err != nil {
+			t.Fatalf("closestBlock(%v): unexpected error: %v", tt.target, err)
+		}
+		if n != tt.expected {
+			t.Errorf("closestBlock(%v): expected block %v, but got %v", tt.target, tt.expected, n)
+		}
+		if calls > 4 {
+			t.Errorf("closestBlock(%v): expected at most 4 requests, but got %v", tt.target, calls)
+		}
+	}
+}
+
+func TestEstimateBlock(t *testing.T) {
+	tests := []struct {
+		latest, latestTime, target, duration, blocks uint64
+		expected                                     uint64
+	}{
+		{100, 1000, 1120, 1200, 100, 110},
+		{100, 1000, 1005, 1200, 100, 100},
+		{100, 1000, 1006, 1200, 100, 101},
+		{100, 1000, 1100, 0, 100, 100},
+		{0, 0, 13, 2, 1, 7},
+	}
+
+	for _, tt := range tests {
+		if n := estimateBlock(tt.latest, tt.latestTime, tt.target, tt.duration, tt.blocks); n != tt.expected {
+			t.Errorf("estimateBlock(%+v): expected %v, but got %v", tt, tt.expected, n)
+		}
+	}
+}
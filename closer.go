@@ -0,0 +1,40 @@
+package ethereum
+
+import (
+	"io"
+	"strings"
+)
+
+// Closers closes components of an assembled stack. Components own only what they create: BlockSource and
+// gas price estimators close clients they dialed themselves, but not the clients passed to them (e.g. with
+// blocksource.NewWithClient), so the shared client must be added to Closers before the components using it.
+type Closers []io.Closer
+
+// Close implements io.Closer interface. Components are closed in reverse order, so every component is closed
+// before the ones it depends on. All components are closed even when some of them fail.
+func (c Closers) Close() error {
+	var errs CloseErrors
+	for i := len(c) - 1; i >= 0; i-- {
+		if c[i] == nil {
+			continue
+		}
+		if err := c[i].Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// CloseErrors is returned by Closers.Close when some components fail to close.
+type CloseErrors []error
+
+func (e CloseErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return "close: " + strings.Join(msgs, "; ")
+}
@@ -0,0 +1,55 @@
+package ethereum
+
+import (
+	"errors"
+	"io"
+	"testing"
+)
+
+type closerFunc func() error
+
+func (f closerFunc) Close() error { return f() }
+
+func TestClosers_Close(t *testing.T) {
+	var closed []int
+	closer := func(i int, err error) io.Closer {
+		return closerFunc(func() error {
+			closed = append(closed, i)
+			return err
+		})
+	}
+
+	tests := []struct {
+		name    string
+		closers Closers
+		order   []int
+		errMsg  string
+	}{
+		{"empty", nil, nil, ""},
+		{"reverse order", Closers{closer(1, nil), nil, closer(2, nil), closer(3, nil)}, []int{3, 2, 1}, ""},
+		{"errors", Closers{closer(1, errors.New("a")), closer(2, nil), closer(3, errors.New("b"))}, []int{3, 2, 1}, "close: b; a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			closed = nil
+
+			err := tt.closers.Close()
+			switch {
+			case tt.errMsg == "" && err != nil:
+				t.Errorf("expected no error, but got %v", err)
+			case tt.errMsg != "" && (err == nil || err.Error() != tt.errMsg):
+				t.Errorf("expected error %q, but got %v", tt.errMsg, err)
+			}
+			if len(closed) != len(tt.order) {
+				t.Fatalf("expected closed %v, but got %v", tt.order, closed)
+			}
+			for i := range closed {
+				if closed[i] != tt.order[i] {
+					t.Errorf("expected closed %v, but got %v", tt.order, closed)
+					break
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,374 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+type tmplData struct {
+	Package string
+	Type    string
+	ABI     string
+	Events  []*tmplEvent
+}
+
+type tmplEvent struct {
+	Name    string // Go name of event
+	RawName string // name of event in ABI
+	Fields  []*tmplField
+	Indexed []*tmplField
+}
+
+type tmplField struct {
+	Name   string // Go name of field
+	Param  string // name of parameter in generated methods
+	GoType string
+}
+
+// generate generates typed event bindings for the given contract ABI.
+func generate(abiJSON []byte, pkg, typ string) ([]byte, error) {
+	parsed, err := abi.JSON(bytes.NewReader(abiJSON))
+	if err != nil {
+		return nil, fmt.Errorf("parsing ABI: %v", err)
+	}
+
+	// strip white space from ABI to embed it into generated code
+	var compacted bytes.Buffer
+	if err := json.Compact(&compacted, abiJSON); err != nil {
+		return nil, err
+	}
+
+	data := &tmplData{
+		Package: pkg,
+		Type:    capitalise(typ),
+		ABI:     compacted.String(),
+	}
+
+	names := make([]string, 0, len(parsed.Events))
+	for name := range parsed.Events {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		ev := parsed.Events[name]
+		if ev.Anonymous {
+			continue // anonymous events can't be filtered by name
+		}
+
+		te := &tmplEvent{
+			Name:    capitalise(ev.Name),
+			RawName: name,
+		}
+		for i, arg := range ev.Inputs {
+			argName := arg.Name
+			if argName == "" {
+				argName = fmt.Sprintf("arg%d", i)
+			}
+
+			goType, err := bindType(arg.Type, arg.Indexed)
+			if err != nil {
+				return nil, fmt.Errorf("event %v, argument %v: %v", ev.Name, argName, err)
+			}
+
+			f := &tmplField{
+				Name:   capitalise(argName),
+				Param:  decapitalise(argName),
+				GoType: goType,
+			}
+			te.Fields = append(te.Fields, f)
+			if arg.Indexed {
+				te.Indexed = append(te.Indexed, f)
+			}
+		}
+		data.Events = append(data.Events, te)
+	}
+
+	var buf bytes.Buffer
+	if err := eventsTemplate.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+
+	code, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %v\n%s", err, buf.Bytes())
+	}
+	return code, nil
+}
+
+// bindType returns Go type of the event argument. Indexed arguments of dynamic types are stored as hash of
+// their value, so they're represented by common.Hash.
+func bindType(t abi.Type, indexed bool) (string, error) {
+	if indexed {
+		switch t.T {
+		case abi.StringTy, abi.BytesTy, abi.SliceTy, abi.ArrayTy:
+			return "common.Hash", nil
+		}
+	}
+
+	switch t.T {
+	case abi.FunctionTy, abi.FixedPointTy:
+		return "", fmt.Errorf("unsupported type %v", t)
+	}
+	if t.Type == nil {
+		return "", fmt.Errorf("unsupported type %v", t)
+	}
+
+	return t.Type.String(), nil
+}
+
+// capitalise makes a camel-case string which starts with an upper case character (the same way as abigen does).
+func capitalise(input string) string {
+	for len(input) > 0 && input[0] == '_' {
+		input = input[1:]
+	}
+	if len(input) == 0 {
+		return ""
+	}
+	return toCamelCase(strings.ToUpper(input[:1]) + input[1:])
+}
+
+// decapitalise makes a camel-case string which starts with a lower case character.
+func decapitalise(input string) string {
+	input = capitalise(input)
+	if len(input) == 0 {
+		return ""
+	}
+	return strings.ToLower(input[:1]) + input[1:]
+}
+
+// toCamelCase converts an under-score string to a camel-case string.
+func toCamelCase(input string) string {
+	parts := strings.Split(input, "_")
+	for i, s := range parts {
+		if len(s) > 0 {
+			parts[i] = strings.ToUpper(s[:1]) + s[1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+var eventsTemplate = template.Must(template.New("events").Parse(eventsTemplateSource))
+
+const eventsTemplateSource = `// Code generated by eventgen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/monetha/go-ethereum"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = big.NewInt
+	_ = common.Big1
+)
+
+// {{.Type}}EventsABI is the input ABI used to generate the binding from.
+const {{.Type}}EventsABI = {{printf "%q" .ABI}}
+
+// {{.Type}}Events provides typed access to events of {{.Type}} contract.
+type {{.Type}}Events struct {
+	abi      abi.ABI
+	contract *bind.BoundContract
+	filterer *ethereum.ContractLogFilterer
+}
+
+// New{{.Type}}Events creates a new instance of {{.Type}}Events, bound to a specific deployed contract.
+func New{{.Type}}Events(address common.Address, filterer bind.ContractFilterer) (*{{.Type}}Events, error) {
+	parsed, err := abi.JSON(strings.NewReader({{.Type}}EventsABI))
+	if err != nil {
+		return nil, err
+	}
+	return &{{.Type}}Events{
+		abi:      parsed,
+		contract: bind.NewBoundContract(address, parsed, nil, nil, filterer),
+		filterer: ethereum.NewContractLogFilterer(address, parsed, filterer),
+	}, nil
+}
+
+// Decode decodes the log into one of typed events ({{range $i, $e := .Events}}{{if $i}}, {{end}}*{{$.Type}}{{$e.Name}}{{end}}).
+func (e *{{.Type}}Events) Decode(log types.Log) (interface{}, error) {
+	if len(log.Topics) == 0 {
+		return nil, errors.New("anonymous log can't be decoded")
+	}
+
+	switch log.Topics[0] {
+{{- range .Events}}
+	case e.abi.Events["{{.RawName}}"].Id():
+		return e.Decode{{.Name}}(log)
+{{- end}}
+	}
+
+	return nil, errors.New("unknown event")
+}
+
+// Filter retrieves all logs of the events with the given names (all events of the contract if no names specified)
+// and decodes them into typed events.
+func (e *{{.Type}}Events) Filter(opts *bind.FilterOpts, names ...string) ([]interface{}, error) {
+	if len(names) == 0 {
+		names = {{.Type}}EventNames
+	}
+
+	logs, sub, err := e.filterer.FilterLogs(opts, names)
+	if err != nil {
+		return nil, err
+	}
+	ls, err := ethereum.ReadLogs(logs, sub)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]interface{}, 0, len(ls))
+	for _, log := range ls {
+		ev, err := e.Decode(log)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, ev)
+	}
+	return res, nil
+}
+
+// Watch subscribes to the events with the given names (all events of the contract if no names specified),
+// delivering decoded typed events to the sink.
+func (e *{{.Type}}Events) Watch(opts *bind.WatchOpts, sink chan<- interface{}, names ...string) (event.Subscription, error) {
+	if len(names) == 0 {
+		names = {{.Type}}EventNames
+	}
+
+	logs, sub, err := e.filterer.WatchLogs(opts, names)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				ev, err := e.Decode(log)
+				if err != nil {
+					return err
+				}
+
+				select {
+				case sink <- ev:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// {{.Type}}EventNames contains names of all events of {{.Type}} contract.
+var {{.Type}}EventNames = []string{ {{- range $i, $e := .Events}}{{if $i}}, {{end}}"{{$e.RawName}}"{{end -}} }
+{{range .Events}}
+// {{$.Type}}{{.Name}} represents a {{.RawName}} event raised by the {{$.Type}} contract.
+type {{$.Type}}{{.Name}} struct {
+{{- range .Fields}}
+	{{.Name}} {{.GoType}}
+{{- end}}
+	Raw types.Log // Blockchain specific contextual infos
+}
+
+// Decode{{.Name}} decodes the log into {{.RawName}} event.
+func (e *{{$.Type}}Events) Decode{{.Name}}(log types.Log) (*{{$.Type}}{{.Name}}, error) {
+	ev := new({{$.Type}}{{.Name}})
+	if err := e.contract.UnpackLog(ev, "{{.RawName}}", log); err != nil {
+		return nil, err
+	}
+	ev.Raw = log
+	return ev, nil
+}
+
+// Filter{{.Name}} retrieves {{.RawName}} events matching the given indexed values (nil means any value).
+func (e *{{$.Type}}Events) Filter{{.Name}}(opts *bind.FilterOpts{{range .Indexed}}, {{.Param}} []{{.GoType}}{{end}}) ([]*{{$.Type}}{{.Name}}, error) {
+{{- range .Indexed}}
+	var {{.Param}}Rule []interface{}
+	for _, item := range {{.Param}} {
+		{{.Param}}Rule = append({{.Param}}Rule, item)
+	}
+{{- end}}
+
+	logs, sub, err := e.filterer.FilterLogs(opts, []string{"{{.RawName}}"}{{range .Indexed}}, {{.Param}}Rule{{end}})
+	if err != nil {
+		return nil, err
+	}
+	ls, err := ethereum.ReadLogs(logs, sub)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*{{$.Type}}{{.Name}}, 0, len(ls))
+	for _, log := range ls {
+		ev, err := e.Decode{{.Name}}(log)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, ev)
+	}
+	return res, nil
+}
+
+// Watch{{.Name}} subscribes to {{.RawName}} events matching the given indexed values (nil means any value).
+func (e *{{$.Type}}Events) Watch{{.Name}}(opts *bind.WatchOpts, sink chan<- *{{$.Type}}{{.Name}}{{range .Indexed}}, {{.Param}} []{{.GoType}}{{end}}) (event.Subscription, error) {
+{{- range .Indexed}}
+	var {{.Param}}Rule []interface{}
+	for _, item := range {{.Param}} {
+		{{.Param}}Rule = append({{.Param}}Rule, item)
+	}
+{{- end}}
+
+	logs, sub, err := e.filterer.WatchLogs(opts, []string{"{{.RawName}}"}{{range .Indexed}}, {{.Param}}Rule{{end}})
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				ev, err := e.Decode{{.Name}}(log)
+				if err != nil {
+					return err
+				}
+
+				select {
+				case sink <- ev:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+{{end}}`
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+const erc20EventsABI = `[
+	{"anonymous":false,"inputs":[{"indexed":true,"name":"from","type":"address"},{"indexed":true,"name":"to","type":"address"},{"indexed":false,"name":"value","type":"uint256"}],"name":"Transfer","type":"event"},
+	{"anonymous":false,"inputs":[{"indexed":true,"name":"owner","type":"address"},{"indexed":true,"name":"spender","type":"address"},{"indexed":false,"name":"value","type":"uint256"}],"name":"Approval","type":"event"},
+	{"anonymous":false,"inputs":[{"indexed":true,"name":"_memo","type":"string"}],"name":"memo_added","type":"event"}
+]`
+
+func TestGenerate(t *testing.T) {
+	code, err := generate([]byte(erc20EventsABI), "token", "token")
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+
+	src := string(code)
+	for _, expected := range []string{
+		"package token",
+		"func NewTokenEvents(address common.Address, filterer bind.ContractFilterer) (*TokenEvents, error)",
+		"type TokenTransfer struct",
+		"func (e *TokenEvents) FilterTransfer(opts *bind.FilterOpts, from []common.Address, to []common.Address) ([]*TokenTransfer, error)",
+		"func (e *TokenEvents) WatchApproval(opts *bind.WatchOpts, sink chan<- *TokenApproval, owner []common.Address, spender []common.Address) (event.Subscription, error)",
+		"func (e *TokenEvents) DecodeMemoAdded(log types.Log) (*TokenMemoAdded, error)",
+		"Memo common.Hash",
+		`var TokenEventNames = []string{"Approval", "Transfer", "memo_added"}`,
+	} {
+		if !strings.Contains(src, expected) {
+			t.Errorf("generated code doesn't contain %q", expected)
+		}
+	}
+}
+
+func TestCapitalise(t *testing.T) {
+	testCases := map[string]string{
+		"":           "",
+		"value":      "Value",
+		"_from":      "From",
+		"memo_added": "MemoAdded",
+		"__to_addr":  "ToAddr",
+	}
+
+	for input, expected := range testCases {
+		if actual := capitalise(input); actual != expected {
+			t.Errorf("capitalise(%q): expected %q, but got %q", input, expected, actual)
+		}
+	}
+}
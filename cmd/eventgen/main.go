@@ -0,0 +1,65 @@
+// Command eventgen generates typed Go bindings for events of a contract ABI. Generated code is built on top of
+// ethereum.ContractLogFilterer, so besides typed Filter/Watch/Decode methods for every event, it allows to
+// filter/watch several events of the contract at once.
+//
+// Usage:
+//
+//	eventgen -abi Token.abi -type Token -pkg token -out token_events.go
+//
+// or from go:generate directive:
+//
+//	//go:generate eventgen -abi Token.abi -type Token -pkg token -out token_events.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+func main() {
+	var (
+		abiFile = flag.String("abi", "", "path to the contract ABI JSON file (- for STDIN)")
+		typ     = flag.String("type", "", "Go type name prefix of generated bindings (required)")
+		pkg     = flag.String("pkg", "", "Go package name of generated code (required)")
+		out     = flag.String("out", "", "output file (default STDOUT)")
+	)
+	flag.Parse()
+
+	if *abiFile == "" || *typ == "" || *pkg == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var (
+		abiJSON []byte
+		err     error
+	)
+	if *abiFile == "-" {
+		abiJSON, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		abiJSON, err = ioutil.ReadFile(*abiFile)
+	}
+	if err != nil {
+		fatalf("reading ABI: %v", err)
+	}
+
+	code, err := generate(abiJSON, *pkg, *typ)
+	if err != nil {
+		fatalf("generating bindings: %v", err)
+	}
+
+	if *out == "" {
+		fmt.Print(string(code))
+		return
+	}
+	if err := ioutil.WriteFile(*out, code, 0600); err != nil {
+		fatalf("writing bindings: %v", err)
+	}
+}
+
+func fatalf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "eventgen: "+format+"\n", args...)
+	os.Exit(1)
+}
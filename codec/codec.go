@@ -0,0 +1,109 @@
+// Package codec encodes blocks, transactions and logs for streaming sinks and checkpoints (e.g. Kafka messages,
+// gRPC payloads, files on disk). Codecs are pluggable: JSON and Protobuf are registered, others can be added with
+// Register and looked up by name, e.g. taken from the configuration of the sink.
+package codec
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/monetha/go-ethereum"
+)
+
+// Codec encodes and decodes blocks, transactions and logs.
+type Codec interface {
+	// Name identifies the codec, e.g. in the configuration or in message headers.
+	Name() string
+	EncodeBlock(b *ethereum.Block) ([]byte, error)
+	DecodeBlock(data []byte) (*ethereum.Block, error)
+	EncodeTransaction(tx *ethereum.Transaction) ([]byte, error)
+	DecodeTransaction(data []byte) (*ethereum.Transaction, error)
+	EncodeLog(log *types.Log) ([]byte, error)
+	DecodeLog(data []byte) (*types.Log, error)
+}
+
+var (
+	mu     sync.RWMutex
+	codecs = make(map[string]Codec)
+)
+
+func init() {
+	Register(JSON)
+	Register(Protobuf)
+}
+
+// Register makes the codec available by its name, the codec with the same name is replaced.
+func Register(c Codec) {
+	mu.Lock()
+	defer mu.Unlock()
+	codecs[c.Name()] = c
+}
+
+// Get returns the registered codec with the name.
+func Get(name string) (Codec, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+	c, ok := codecs[name]
+	if !ok {
+		return nil, fmt.Errorf("codec: unknown codec %q", name)
+	}
+	return c, nil
+}
+
+// Names returns names of registered codecs in alphabetical order.
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	names := make([]string, 0, len(codecs))
+	for name := range codecs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// JSON is the codec of JSON encoding of Go values, it's verbose, but readable.
+var JSON Codec = jsonCodec{}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Name() string { return "json" }
+
+func (jsonCodec) EncodeBlock(b *ethereum.Block) ([]byte, error) {
+	return json.Marshal(b)
+}
+
+func (jsonCodec) DecodeBlock(data []byte) (*ethereum.Block, error) {
+	b := new(ethereum.Block)
+	if err := json.Unmarshal(data, b); err != nil {
+		return nil, fmt.Errorf("codec: %v", err)
+	}
+	return b, nil
+}
+
+func (jsonCodec) EncodeTransaction(tx *ethereum.Transaction) ([]byte, error) {
+	return json.Marshal(tx)
+}
+
+func (jsonCodec) DecodeTransaction(data []byte) (*ethereum.Transaction, error) {
+	tx := new(ethereum.Transaction)
+	if err := json.Unmarshal(data, tx); err != nil {
+		return nil, fmt.Errorf("codec: %v", err)
+	}
+	return tx, nil
+}
+
+func (jsonCodec) EncodeLog(log *types.Log) ([]byte, error) {
+	return json.Marshal(log)
+}
+
+func (jsonCodec) DecodeLog(data []byte) (*types.Log, error) {
+	log := new(types.Log)
+	if err := json.Unmarshal(data, log); err != nil {
+		return nil, fmt.Errorf("codec: %v", err)
+	}
+	return log, nil
+}
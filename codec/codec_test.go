@@ -0,0 +1,151 @@
+package codec
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/monetha/go-ethereum"
+)
+
+func testLog() *types.Log {
+	return &types.Log{
+		Address:     common.HexToAddress("0x1111111111111111111111111111111111111111"),
+		Topics:      []common.Hash{common.HexToHash("0x01"), common.HexToHash("0x02")},
+		Data:        []byte{1, 2, 3},
+		BlockNumber: 100,
+		TxHash:      common.HexToHash("0xaa"),
+		TxIndex:     1,
+		BlockHash:   common.HexToHash("0xbb"),
+		Index:       7,
+		Removed:     true,
+	}
+}
+
+func testTransaction() *ethereum.Transaction {
+	to := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	status := ethereum.TransactionSuccessful
+	return &ethereum.Transaction{
+		Type:                ethereum.BlobTxType,
+		BlockNumber:         big.NewInt(100),
+		From:                common.HexToAddress("0x3333333333333333333333333333333333333333"),
+		GasLimit:            big.NewInt(21000),
+		GasPrice:            big.NewInt(0),
+		GasUsed:             big.NewInt(21000),
+		Hash:                common.HexToHash("0xaa"),
+		Input:               []byte{},
+		Nonce:               5,
+		To:                  &to,
+		TransactionIndex:    1,
+		Value:               new(big.Int).Lsh(big.NewInt(1), 200),
+		Status:              &status,
+		Logs:                []*types.Log{testLog()},
+		BlobVersionedHashes: []common.Hash{common.HexToHash("0x0101")},
+		MaxFeePerBlobGas:    big.NewInt(10),
+		BlobGasUsed:         big.NewInt(131072),
+		BlobGasPrice:        big.NewInt(1),
+	}
+}
+
+func testBlock() *ethereum.Block {
+	failed := ethereum.TransactionFailed
+	contract := common.HexToAddress("0x4444444444444444444444444444444444444444")
+	return &ethereum.Block{
+		Difficulty:  big.NewInt(0),
+		ExtraData:   []byte("extra"),
+		GasLimit:    big.NewInt(30000000),
+		GasUsed:     big.NewInt(42000),
+		Hash:        common.HexToHash("0xbb"),
+		ParentHash:  common.HexToHash("0xcc"),
+		Miner:       common.HexToAddress("0x5555555555555555555555555555555555555555"),
+		Number:      big.NewInt(100),
+		Timestamp:   1700000000,
+		UncleHashes: []common.Hash{common.HexToHash("0xdd")},
+		Transactions: ethereum.Transactions{
+			testTransaction(),
+			{
+				BlockNumber:     big.NewInt(100),
+				Hash:            common.HexToHash("0xee"),
+				Input:           []byte{0x60, 0x80},
+				ContractAddress: &contract,
+				Status:          &failed,
+			},
+		},
+		Uncles: []*ethereum.Uncle{{
+			Difficulty: big.NewInt(1),
+			Hash:       common.HexToHash("0xdd"),
+			Number:     big.NewInt(99),
+			Reward:     big.NewInt(1750000000000000000),
+		}},
+		BlobGasUsed:   big.NewInt(131072),
+		ExcessBlobGas: big.NewInt(0),
+		Withdrawals: ethereum.Withdrawals{
+			{Index: 1, ValidatorIndex: 2, Address: common.HexToAddress("0x66"), Amount: 3},
+		},
+		LogsBloom:       types.BytesToBloom([]byte{0x80, 0x01}),
+		ReceiptsSkipped: true,
+	}
+}
+
+func TestCodecs_RoundTrip(t *testing.T) {
+	for _, name := range Names() {
+		c, err := Get(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		blocks := []*ethereum.Block{
+			testBlock(),
+			{Number: big.NewInt(1), Uncles: []*ethereum.Uncle{}, Withdrawals: ethereum.Withdrawals{}},
+		}
+		for i, b := range blocks {
+			data, err := c.EncodeBlock(b)
+			if err != nil {
+				t.Fatalf("%v: block %v: %v", name, i, err)
+			}
+			decoded, err := c.DecodeBlock(data)
+			if err != nil {
+				t.Fatalf("%v: block %v: %v", name, i, err)
+			}
+			if !reflect.DeepEqual(b, decoded) {
+				t.Errorf("%v: block %v: expected %v, but got %v", name, i, b, decoded)
+			}
+		}
+
+		tx := testTransaction()
+		data, err := c.EncodeTransaction(tx)
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+		decodedTx, err := c.DecodeTransaction(data)
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+		if !reflect.DeepEqual(tx, decodedTx) {
+			t.Errorf("%v: expected %v, but got %v", name, tx, decodedTx)
+		}
+
+		log := testLog()
+		if data, err = c.EncodeLog(log); err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+		decodedLog, err := c.DecodeLog(data)
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+		if !reflect.DeepEqual(log, decodedLog) {
+			t.Errorf("%v: expected %v, but got %v", name, log, decodedLog)
+		}
+	}
+}
+
+func TestGet(t *testing.T) {
+	if c, err := Get("protobuf"); err != nil || c != Protobuf {
+		t.Errorf("expected Protobuf codec, but got %v (%v)", c, err)
+	}
+	if _, err := Get("xml"); err == nil {
+		t.Error("expected error for unknown codec")
+	}
+}
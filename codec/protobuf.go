@@ -0,0 +1,395 @@
+package codec
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/monetha/go-ethereum"
+)
+
+// Protobuf is the codec of compact binary encoding, messages follow the schema in ethereum.proto, so they can be
+// decoded by code generated with protoc in other languages. Unknown fields are skipped on decoding, so fields
+// can be added to the schema without breaking older readers.
+var Protobuf Codec = protobufCodec{}
+
+type protobufCodec struct{}
+
+func (protobufCodec) Name() string { return "protobuf" }
+
+func (protobufCodec) EncodeBlock(b *ethereum.Block) ([]byte, error) {
+	var e encoder
+	encodeBlock(&e, b)
+	return e.buf, nil
+}
+
+func (protobufCodec) DecodeBlock(data []byte) (*ethereum.Block, error) {
+	b := new(ethereum.Block)
+	if err := decodeBlock(&decoder{buf: data}, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (protobufCodec) EncodeTransaction(tx *ethereum.Transaction) ([]byte, error) {
+	var e encoder
+	encodeTransaction(&e, tx)
+	return e.buf, nil
+}
+
+func (protobufCodec) DecodeTransaction(data []byte) (*ethereum.Transaction, error) {
+	tx := new(ethereum.Transaction)
+	if err := decodeTransaction(&decoder{buf: data}, tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+func (protobufCodec) EncodeLog(log *types.Log) ([]byte, error) {
+	var e encoder
+	encodeLog(&e, log)
+	return e.buf, nil
+}
+
+func (protobufCodec) DecodeLog(data []byte) (*types.Log, error) {
+	log := new(types.Log)
+	if err := decodeLog(&decoder{buf: data}, log); err != nil {
+		return nil, err
+	}
+	return log, nil
+}
+
+func encodeBlock(e *encoder, b *ethereum.Block) {
+	e.bigInt(1, b.Difficulty)
+	if b.ExtraData != nil {
+		e.optionalBytes(2, b.ExtraData)
+	}
+	e.bigInt(3, b.GasLimit)
+	e.bigInt(4, b.GasUsed)
+	e.hash(5, b.Hash)
+	e.hash(6, b.ParentHash)
+	e.address(7, b.Miner)
+	e.bigInt(8, b.Number)
+	e.uint(9, b.Timestamp)
+	for _, tx := range b.Transactions {
+		e.message(10, func(e *encoder) { encodeTransaction(e, tx) })
+	}
+	for _, h := range b.UncleHashes {
+		e.optionalBytes(11, h[:])
+	}
+	for _, u := range b.Uncles {
+		e.message(12, func(e *encoder) { encodeUncle(e, u) })
+	}
+	e.bigInt(13, b.BlobGasUsed)
+	e.bigInt(14, b.ExcessBlobGas)
+	for _, w := range b.Withdrawals {
+		e.message(15, func(e *encoder) { encodeWithdrawal(e, w) })
+	}
+	if b.LogsBloom != (types.Bloom{}) {
+		e.bytes(16, b.LogsBloom[:])
+	}
+	e.bool(17, b.ReceiptsSkipped)
+	e.bool(18, b.Uncles != nil)
+	e.bool(19, b.Withdrawals != nil)
+}
+
+func decodeBlock(d *decoder, b *ethereum.Block) error {
+	for {
+		ok, err := d.next()
+		if !ok {
+			return err
+		}
+		switch d.field {
+		case 1:
+			b.Difficulty, err = d.bigInt()
+		case 2:
+			b.ExtraData, err = d.copyBytes()
+		case 3:
+			b.GasLimit, err = d.bigInt()
+		case 4:
+			b.GasUsed, err = d.bigInt()
+		case 5:
+			b.Hash, err = d.hash()
+		case 6:
+			b.ParentHash, err = d.hash()
+		case 7:
+			b.Miner, err = d.address()
+		case 8:
+			b.Number, err = d.bigInt()
+		case 9:
+			b.Timestamp, err = d.uint()
+		case 10:
+			tx := new(ethereum.Transaction)
+			if err = d.message(func(d *decoder) error { return decodeTransaction(d, tx) }); err == nil {
+				b.Transactions = append(b.Transactions, tx)
+			}
+		case 11:
+			var h common.Hash
+			if h, err = d.hash(); err == nil {
+				b.UncleHashes = append(b.UncleHashes, h)
+			}
+		case 12:
+			u := new(ethereum.Uncle)
+			if err = d.message(func(d *decoder) error { return decodeUncle(d, u) }); err == nil {
+				b.Uncles = append(b.Uncles, u)
+			}
+		case 13:
+			b.BlobGasUsed, err = d.bigInt()
+		case 14:
+			b.ExcessBlobGas, err = d.bigInt()
+		case 15:
+			w := new(ethereum.Withdrawal)
+			if err = d.message(func(d *decoder) error { return decodeWithdrawal(d, w) }); err == nil {
+				b.Withdrawals = append(b.Withdrawals, w)
+			}
+		case 16:
+			if err = d.expect(wireBytes); err == nil {
+				if len(d.bytes) != types.BloomByteLength {
+					return fmt.Errorf("codec: invalid logs bloom length %v", len(d.bytes))
+				}
+				b.LogsBloom = types.BytesToBloom(d.bytes)
+			}
+		case 17:
+			var v uint64
+			v, err = d.uint()
+			b.ReceiptsSkipped = v != 0
+		case 18:
+			if _, err = d.uint(); err == nil && b.Uncles == nil {
+				b.Uncles = []*ethereum.Uncle{}
+			}
+		case 19:
+			if _, err = d.uint(); err == nil && b.Withdrawals == nil {
+				b.Withdrawals = ethereum.Withdrawals{}
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func encodeTransaction(e *encoder, tx *ethereum.Transaction) {
+	e.uint(1, uint64(tx.Type))
+	e.bigInt(2, tx.BlockNumber)
+	e.address(3, tx.From)
+	e.bigInt(4, tx.GasLimit)
+	e.bigInt(5, tx.GasPrice)
+	e.bigInt(6, tx.GasUsed)
+	e.hash(7, tx.Hash)
+	if tx.Input != nil {
+		e.optionalBytes(8, tx.Input)
+	}
+	e.uint(9, tx.Nonce)
+	if tx.To != nil {
+		e.optionalBytes(10, tx.To[:])
+	}
+	e.uint(11, tx.TransactionIndex)
+	e.bigInt(12, tx.Value)
+	if tx.ContractAddress != nil {
+		e.optionalBytes(13, tx.ContractAddress[:])
+	}
+	if tx.Status != nil {
+		e.optionalUint(14, uint64(*tx.Status))
+	}
+	for _, log := range tx.Logs {
+		e.message(15, func(e *encoder) { encodeLog(e, log) })
+	}
+	for _, h := range tx.BlobVersionedHashes {
+		e.optionalBytes(16, h[:])
+	}
+	e.bigInt(17, tx.MaxFeePerBlobGas)
+	e.bigInt(18, tx.BlobGasUsed)
+	e.bigInt(19, tx.BlobGasPrice)
+}
+
+func decodeTransaction(d *decoder, tx *ethereum.Transaction) error {
+	for {
+		ok, err := d.next()
+		if !ok {
+			return err
+		}
+		var v uint64
+		switch d.field {
+		case 1:
+			v, err = d.uint()
+			tx.Type = ethereum.TransactionType(v)
+		case 2:
+			tx.BlockNumber, err = d.bigInt()
+		case 3:
+			tx.From, err = d.address()
+		case 4:
+			tx.GasLimit, err = d.bigInt()
+		case 5:
+			tx.GasPrice, err = d.bigInt()
+		case 6:
+			tx.GasUsed, err = d.bigInt()
+		case 7:
+			tx.Hash, err = d.hash()
+		case 8:
+			tx.Input, err = d.copyBytes()
+		case 9:
+			tx.Nonce, err = d.uint()
+		case 10:
+			var a common.Address
+			if a, err = d.address(); err == nil {
+				tx.To = &a
+			}
+		case 11:
+			tx.TransactionIndex, err = d.uint()
+		case 12:
+			tx.Value, err = d.bigInt()
+		case 13:
+			var a common.Address
+			if a, err = d.address(); err == nil {
+				tx.ContractAddress = &a
+			}
+		case 14:
+			if v, err = d.uint(); err == nil {
+				status := ethereum.TransactionStatus(v)
+				tx.Status = &status
+			}
+		case 15:
+			log := new(types.Log)
+			if err = d.message(func(d *decoder) error { return decodeLog(d, log) }); err == nil {
+				tx.Logs = append(tx.Logs, log)
+			}
+		case 16:
+			var h common.Hash
+			if h, err = d.hash(); err == nil {
+				tx.BlobVersionedHashes = append(tx.BlobVersionedHashes, h)
+			}
+		case 17:
+			tx.MaxFeePerBlobGas, err = d.bigInt()
+		case 18:
+			tx.BlobGasUsed, err = d.bigInt()
+		case 19:
+			tx.BlobGasPrice, err = d.bigInt()
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func encodeLog(e *encoder, log *types.Log) {
+	e.address(1, log.Address)
+	for _, topic := range log.Topics {
+		e.optionalBytes(2, topic[:])
+	}
+	e.bytes(3, log.Data)
+	e.uint(4, log.BlockNumber)
+	e.hash(5, log.TxHash)
+	e.uint(6, uint64(log.TxIndex))
+	e.hash(7, log.BlockHash)
+	e.uint(8, uint64(log.Index))
+	e.bool(9, log.Removed)
+}
+
+func decodeLog(d *decoder, log *types.Log) error {
+	for {
+		ok, err := d.next()
+		if !ok {
+			return err
+		}
+		var v uint64
+		switch d.field {
+		case 1:
+			log.Address, err = d.address()
+		case 2:
+			var h common.Hash
+			if h, err = d.hash(); err == nil {
+				log.Topics = append(log.Topics, h)
+			}
+		case 3:
+			log.Data, err = d.copyBytes()
+		case 4:
+			log.BlockNumber, err = d.uint()
+		case 5:
+			log.TxHash, err = d.hash()
+		case 6:
+			v, err = d.uint()
+			log.TxIndex = uint(v)
+		case 7:
+			log.BlockHash, err = d.hash()
+		case 8:
+			v, err = d.uint()
+			log.Index = uint(v)
+		case 9:
+			v, err = d.uint()
+			log.Removed = v != 0
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func encodeUncle(e *encoder, u *ethereum.Uncle) {
+	e.bigInt(1, u.Difficulty)
+	e.bigInt(2, u.GasLimit)
+	e.bigInt(3, u.GasUsed)
+	e.hash(4, u.Hash)
+	e.address(5, u.Miner)
+	e.bigInt(6, u.Number)
+	e.uint(7, u.Timestamp)
+	e.bigInt(8, u.Reward)
+}
+
+func decodeUncle(d *decoder, u *ethereum.Uncle) error {
+	for {
+		ok, err := d.next()
+		if !ok {
+			return err
+		}
+		switch d.field {
+		case 1:
+			u.Difficulty, err = d.bigInt()
+		case 2:
+			u.GasLimit, err = d.bigInt()
+		case 3:
+			u.GasUsed, err = d.bigInt()
+		case 4:
+			u.Hash, err = d.hash()
+		case 5:
+			u.Miner, err = d.address()
+		case 6:
+			u.Number, err = d.bigInt()
+		case 7:
+			u.Timestamp, err = d.uint()
+		case 8:
+			u.Reward, err = d.bigInt()
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func encodeWithdrawal(e *encoder, w *ethereum.Withdrawal) {
+	e.uint(1, w.Index)
+	e.uint(2, w.ValidatorIndex)
+	e.address(3, w.Address)
+	e.uint(4, w.Amount)
+}
+
+func decodeWithdrawal(d *decoder, w *ethereum.Withdrawal) error {
+	for {
+		ok, err := d.next()
+		if !ok {
+			return err
+		}
+		switch d.field {
+		case 1:
+			w.Index, err = d.uint()
+		case 2:
+			w.ValidatorIndex, err = d.uint()
+		case 3:
+			w.Address, err = d.address()
+		case 4:
+			w.Amount, err = d.uint()
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package codec
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestProtobuf_Compact(t *testing.T) {
+	b := testBlock()
+	pb, err := Protobuf.EncodeBlock(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	js, err := JSON.EncodeBlock(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pb)*2 > len(js) {
+		t.Errorf("expected protobuf encoding to be at least twice as small as JSON, but got %v and %v bytes", len(pb), len(js))
+	}
+}
+
+func TestProtobuf_UnknownFields(t *testing.T) {
+	log := testLog()
+	data, err := Protobuf.EncodeLog(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// fields a newer writer might add: varint, bytes, 64-bit and 32-bit ones
+	var e encoder
+	e.uint(100, 42)
+	e.bytes(101, []byte("new"))
+	e.tag(102, 1)
+	e.buf = append(e.buf, make([]byte, 8)...)
+	e.tag(103, 5)
+	e.buf = append(e.buf, make([]byte, 4)...)
+
+	decoded, err := Protobuf.DecodeLog(append(data, e.buf...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(log, decoded) {
+		t.Errorf("expected %v, but got %v", log, decoded)
+	}
+}
+
+func TestProtobuf_Invalid(t *testing.T) {
+	data, err := Protobuf.EncodeBlock(testBlock())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"truncated", data[:len(data)-1]},
+		{"truncated varint", []byte{0x48, 0x80}},
+		{"field number 0", []byte{0x00, 0x01}},
+		{"wrong wire type", []byte{0x28, 0x00}},                   // hash as varint
+		{"short hash", append([]byte{0x2a, 0x02}, 0x01, 0x02)},    // hash of 2 bytes
+		{"short logs bloom", append([]byte{0x82, 0x01, 0x01}, 1)}, // logs bloom of 1 byte
+	}
+	for _, tt := range tests {
+		if _, err := Protobuf.DecodeBlock(tt.data); err == nil {
+			t.Errorf("%v: expected error", tt.name)
+		}
+	}
+}
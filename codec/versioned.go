@@ -0,0 +1,168 @@
+package codec
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/monetha/go-ethereum"
+)
+
+// SchemaVersion is the version of the schema (see ethereum.proto) values are encoded with. The schema only grows:
+// field numbers are never reused, so newer data can be decoded by older code and vice versa; when meaning of a
+// field changes, the version is incremented and the old data is upgraded by a migration.
+const SchemaVersion = 1
+
+// envelopeMagic starts versioned data. It's neither a valid first byte of Protobuf message (field number 0) nor
+// of JSON, so data written before versioning is still recognized (as version 0).
+const envelopeMagic = 0x00
+
+// Migration upgrades value decoded from data of the schema version it's registered for to the next version. The
+// value is *ethereum.Block, *ethereum.Transaction or *types.Log.
+type Migration func(value interface{}) error
+
+var _ Codec = (*Versioned)(nil)
+
+// Versioned is the codec that tags encoded values with codec name and schema version, so long-lived data (e.g.
+// checkpoints, topics of message brokers) can be decoded after upgrading the library:
+//   - data encoded by any registered codec is decoded, so the codec can be changed;
+//   - data of older schema versions is upgraded by migrations;
+//   - data of newer schema versions is decoded as far as it's understood, unknown fields are ignored;
+//   - data without the tag (written before versioning) is decoded by the codec as version 0.
+type Versioned struct {
+	codec      Codec
+	migrations map[uint]Migration
+}
+
+// NewVersioned creates Versioned codec encoding values with the codec.
+func NewVersioned(c Codec) *Versioned {
+	return &Versioned{
+		codec:      c,
+		migrations: make(map[uint]Migration),
+	}
+}
+
+// SetMigration sets the migration upgrading values of the schema version to the next one. Versions without
+// migration are upgraded as is. It's not safe to call concurrently with decoding.
+func (v *Versioned) SetMigration(from uint, m Migration) {
+	v.migrations[from] = m
+}
+
+// Name returns name of the underlying codec.
+func (v *Versioned) Name() string { return v.codec.Name() }
+
+// EncodeBlock implements Codec interface.
+func (v *Versioned) EncodeBlock(b *ethereum.Block) ([]byte, error) {
+	return v.encode(v.codec.EncodeBlock(b))
+}
+
+// DecodeBlock implements Codec interface.
+func (v *Versioned) DecodeBlock(data []byte) (*ethereum.Block, error) {
+	c, version, payload, err := v.open(data)
+	if err != nil {
+		return nil, err
+	}
+	b, err := c.DecodeBlock(payload)
+	if err != nil {
+		return nil, err
+	}
+	return b, v.migrate(version, b)
+}
+
+// EncodeTransaction implements Codec interface.
+func (v *Versioned) EncodeTransaction(tx *ethereum.Transaction) ([]byte, error) {
+	return v.encode(v.codec.EncodeTransaction(tx))
+}
+
+// DecodeTransaction implements Codec interface.
+func (v *Versioned) DecodeTransaction(data []byte) (*ethereum.Transaction, error) {
+	c, version, payload, err := v.open(data)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := c.DecodeTransaction(payload)
+	if err != nil {
+		return nil, err
+	}
+	return tx, v.migrate(version, tx)
+}
+
+// EncodeLog implements Codec interface.
+func (v *Versioned) EncodeLog(log *types.Log) ([]byte, error) {
+	return v.encode(v.codec.EncodeLog(log))
+}
+
+// DecodeLog implements Codec interface.
+func (v *Versioned) DecodeLog(data []byte) (*types.Log, error) {
+	c, version, payload, err := v.open(data)
+	if err != nil {
+		return nil, err
+	}
+	log, err := c.DecodeLog(payload)
+	if err != nil {
+		return nil, err
+	}
+	return log, v.migrate(version, log)
+}
+
+// encode prepends the envelope: magic byte, schema version, codec name.
+func (v *Versioned) encode(payload []byte, err error) ([]byte, error) {
+	if err != nil {
+		return nil, err
+	}
+	name := v.codec.Name()
+	var e encoder
+	e.buf = make([]byte, 0, 1+2+1+len(name)+len(payload))
+	e.buf = append(e.buf, envelopeMagic)
+	e.uvarint(SchemaVersion)
+	e.uvarint(uint64(len(name)))
+	e.buf = append(e.buf, name...)
+	return append(e.buf, payload...), nil
+}
+
+// open returns codec and schema version of the data along with the encoded value.
+func (v *Versioned) open(data []byte) (c Codec, version uint, payload []byte, err error) {
+	name, version, payload, err := ParseEnvelope(data)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	if name == "" || name == v.codec.Name() {
+		return v.codec, version, payload, nil
+	}
+	c, err = Get(name)
+	return c, version, payload, err
+}
+
+// migrate upgrades the value to the current schema version.
+func (v *Versioned) migrate(version uint, value interface{}) error {
+	for ; version < SchemaVersion; version++ {
+		if m := v.migrations[version]; m != nil {
+			if err := m(value); err != nil {
+				return fmt.Errorf("codec: migration from schema version %v: %v", version, err)
+			}
+		}
+	}
+	return nil
+}
+
+// ParseEnvelope returns codec name and schema version the data was encoded with by Versioned codec, along with
+// the encoded value. For data without envelope it returns empty name and version 0.
+func ParseEnvelope(data []byte) (name string, version uint, payload []byte, err error) {
+	if len(data) == 0 || data[0] != envelopeMagic {
+		return "", 0, data, nil
+	}
+	d := decoder{buf: data[1:]}
+	v, err := d.uvarint()
+	if err != nil {
+		return
+	}
+	n, err := d.uvarint()
+	if err != nil {
+		return
+	}
+	if n == 0 || n > uint64(len(d.buf)) {
+		err = errors.New("codec: invalid codec name in envelope")
+		return
+	}
+	return string(d.buf[:n]), uint(v), d.buf[n:], nil
+}
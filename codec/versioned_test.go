@@ -0,0 +1,138 @@
+package codec
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/monetha/go-ethereum"
+)
+
+func TestVersioned_RoundTrip(t *testing.T) {
+	v := NewVersioned(Protobuf)
+	b := testBlock()
+	data, err := v.EncodeBlock(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	name, version, _, err := ParseEnvelope(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "protobuf" || version != SchemaVersion {
+		t.Errorf("expected protobuf codec of version %v, but got %v codec of version %v", SchemaVersion, name, version)
+	}
+
+	decoded, err := v.DecodeBlock(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(b, decoded) {
+		t.Errorf("expected %v, but got %v", b, decoded)
+	}
+
+	// data of other codec is decoded by that codec
+	data, err = NewVersioned(JSON).EncodeBlock(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded, err = v.DecodeBlock(data); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(b, decoded) {
+		t.Errorf("expected %v, but got %v", b, decoded)
+	}
+}
+
+func TestVersioned_Migration(t *testing.T) {
+	v := NewVersioned(Protobuf)
+	var migrated []interface{}
+	v.SetMigration(0, func(value interface{}) error {
+		migrated = append(migrated, value)
+		if tx, ok := value.(*ethereum.Transaction); ok && tx.GasUsed == nil {
+			tx.GasUsed = tx.GasLimit
+		}
+		return nil
+	})
+
+	// data written before versioning
+	tx := testTransaction()
+	tx.GasUsed = nil
+	data, err := Protobuf.EncodeTransaction(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := v.DecodeTransaction(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrated) != 1 || migrated[0] != decoded {
+		t.Fatalf("expected decoded transaction to be migrated, but got %v", migrated)
+	}
+	if decoded.GasUsed == nil || decoded.GasUsed.Cmp(tx.GasLimit) != 0 {
+		t.Errorf("expected gas used %v, but got %v", tx.GasLimit, decoded.GasUsed)
+	}
+
+	// current data isn't migrated
+	if data, err = v.EncodeTransaction(tx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = v.DecodeTransaction(data); err != nil {
+		t.Fatal(err)
+	}
+	if len(migrated) != 1 {
+		t.Errorf("expected 1 migration, but got %v", len(migrated))
+	}
+
+	// failed migration fails decoding
+	v.SetMigration(0, func(interface{}) error { return errors.New("failed") })
+	if data, err = Protobuf.EncodeLog(testLog()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = v.DecodeLog(data); err == nil {
+		t.Error("expected migration error")
+	}
+}
+
+func TestVersioned_NewerVersion(t *testing.T) {
+	log := testLog()
+	payload, err := Protobuf.EncodeLog(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// newer writer added a field
+	e := encoder{buf: []byte{envelopeMagic}}
+	e.uvarint(SchemaVersion + 1)
+	e.uvarint(uint64(len("protobuf")))
+	e.buf = append(e.buf, "protobuf"...)
+	e.buf = append(e.buf, payload...)
+	e.bytes(100, []byte("new"))
+
+	decoded, err := NewVersioned(Protobuf).DecodeLog(e.buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(log, decoded) {
+		t.Errorf("expected %v, but got %v", log, decoded)
+	}
+}
+
+func TestParseEnvelope_Invalid(t *testing.T) {
+	tests := [][]byte{
+		{envelopeMagic},
+		{envelopeMagic, 1},
+		{envelopeMagic, 1, 0},
+		{envelopeMagic, 1, 5, 'j', 's'},
+	}
+	for _, data := range tests {
+		if _, _, _, err := ParseEnvelope(data); err == nil {
+			t.Errorf("%x: expected error", data)
+		}
+	}
+
+	data := []byte{envelopeMagic, 1, 3, 'x', 'm', 'l'}
+	if _, err := NewVersioned(JSON).DecodeLog(data); err == nil {
+		t.Error("expected error for unknown codec")
+	}
+}
@@ -0,0 +1,212 @@
+package codec
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Protobuf wire types, see https://developers.google.com/protocol-buffers/docs/encoding.
+const (
+	wireVarint = 0
+	wireBytes  = 2
+)
+
+var errTruncated = errors.New("codec: truncated message")
+
+// encoder appends fields of protobuf message to buf.
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) uvarint(v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(b[:], v)
+	e.buf = append(e.buf, b[:n]...)
+}
+
+func (e *encoder) tag(field, wireType int) {
+	e.uvarint(uint64(field)<<3 | uint64(wireType))
+}
+
+// uint appends non-zero varint field (proto3 default values aren't encoded).
+func (e *encoder) uint(field int, v uint64) {
+	if v == 0 {
+		return
+	}
+	e.tag(field, wireVarint)
+	e.uvarint(v)
+}
+
+func (e *encoder) bool(field int, v bool) {
+	if v {
+		e.uint(field, 1)
+	}
+}
+
+// optionalUint appends varint field even if it's zero.
+func (e *encoder) optionalUint(field int, v uint64) {
+	e.tag(field, wireVarint)
+	e.uvarint(v)
+}
+
+// bytes appends non-empty bytes field.
+func (e *encoder) bytes(field int, b []byte) {
+	if len(b) == 0 {
+		return
+	}
+	e.optionalBytes(field, b)
+}
+
+// optionalBytes appends bytes field even if it's empty.
+func (e *encoder) optionalBytes(field int, b []byte) {
+	e.tag(field, wireBytes)
+	e.uvarint(uint64(len(b)))
+	e.buf = append(e.buf, b...)
+}
+
+func (e *encoder) hash(field int, h common.Hash) {
+	if h != (common.Hash{}) {
+		e.bytes(field, h[:])
+	}
+}
+
+func (e *encoder) address(field int, a common.Address) {
+	if a != (common.Address{}) {
+		e.bytes(field, a[:])
+	}
+}
+
+// bigInt appends non-nil integer as big-endian bytes, zero is encoded as empty bytes.
+func (e *encoder) bigInt(field int, v *big.Int) {
+	if v != nil {
+		e.optionalBytes(field, v.Bytes())
+	}
+}
+
+// message appends embedded message encoded by fn.
+func (e *encoder) message(field int, fn func(e *encoder)) {
+	var m encoder
+	fn(&m)
+	e.optionalBytes(field, m.buf)
+}
+
+// decoder reads fields of protobuf message.
+type decoder struct {
+	buf []byte
+
+	field    int
+	wireType int
+	varint   uint64
+	bytes    []byte // aliases buf
+}
+
+// next reads the next field, it returns false at the end of the message.
+func (d *decoder) next() (bool, error) {
+	if len(d.buf) == 0 {
+		return false, nil
+	}
+	tag, err := d.uvarint()
+	if err != nil {
+		return false, err
+	}
+	d.field, d.wireType = int(tag>>3), int(tag&7)
+	if d.field == 0 {
+		return false, errors.New("codec: invalid field number 0")
+	}
+	switch d.wireType {
+	case wireVarint:
+		d.varint, err = d.uvarint()
+	case wireBytes:
+		var n uint64
+		if n, err = d.uvarint(); err == nil {
+			if n > uint64(len(d.buf)) {
+				return false, errTruncated
+			}
+			d.bytes, d.buf = d.buf[:n], d.buf[n:]
+		}
+	case 1: // 64-bit
+		err = d.skip(8)
+	case 5: // 32-bit
+		err = d.skip(4)
+	default:
+		err = fmt.Errorf("codec: unsupported wire type %v of field %v", d.wireType, d.field)
+	}
+	return err == nil, err
+}
+
+func (d *decoder) uvarint() (uint64, error) {
+	v, n := binary.Uvarint(d.buf)
+	if n <= 0 {
+		return 0, errTruncated
+	}
+	d.buf = d.buf[n:]
+	return v, nil
+}
+
+func (d *decoder) skip(n int) error {
+	if len(d.buf) < n {
+		return errTruncated
+	}
+	d.buf = d.buf[n:]
+	return nil
+}
+
+// expect checks wire type of the current field.
+func (d *decoder) expect(wireType int) error {
+	if d.wireType != wireType {
+		return fmt.Errorf("codec: unexpected wire type %v of field %v", d.wireType, d.field)
+	}
+	return nil
+}
+
+func (d *decoder) uint() (uint64, error) {
+	return d.varint, d.expect(wireVarint)
+}
+
+func (d *decoder) copyBytes() ([]byte, error) {
+	if err := d.expect(wireBytes); err != nil {
+		return nil, err
+	}
+	return append([]byte{}, d.bytes...), nil
+}
+
+func (d *decoder) bigInt() (*big.Int, error) {
+	if err := d.expect(wireBytes); err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(d.bytes), nil
+}
+
+func (d *decoder) hash() (h common.Hash, err error) {
+	if err = d.expect(wireBytes); err != nil {
+		return
+	}
+	if len(d.bytes) != common.HashLength {
+		return h, fmt.Errorf("codec: field %v: invalid hash length %v", d.field, len(d.bytes))
+	}
+	copy(h[:], d.bytes)
+	return
+}
+
+func (d *decoder) address() (a common.Address, err error) {
+	if err = d.expect(wireBytes); err != nil {
+		return
+	}
+	if len(d.bytes) != common.AddressLength {
+		return a, fmt.Errorf("codec: field %v: invalid address length %v", d.field, len(d.bytes))
+	}
+	copy(a[:], d.bytes)
+	return
+}
+
+// message decodes embedded message with fn.
+func (d *decoder) message(fn func(d *decoder) error) error {
+	if err := d.expect(wireBytes); err != nil {
+		return err
+	}
+	return fn(&decoder{buf: d.bytes})
+}
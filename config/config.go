@@ -0,0 +1,248 @@
+// Package config provides single configuration of all subsystems (backend, Eth, BlockSource, gas estimators),
+// loadable from YAML/JSON files and environment variables.
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/monetha/go-ethereum"
+	"github.com/monetha/go-ethereum/addresset"
+	"github.com/monetha/go-ethereum/backend"
+	"github.com/monetha/go-ethereum/blocksource"
+	"github.com/monetha/go-ethereum/gasestimator"
+	"github.com/monetha/go-ethereum/log"
+	"gopkg.in/yaml.v2"
+)
+
+// Config contains parameters of all subsystems.
+type Config struct {
+	// RPCURL is the URL of Ethereum JSON-RPC endpoint.
+	RPCURL string `json:"rpc_url" yaml:"rpc_url" env:"RPC_URL"`
+	// PrivateKey is hex-encoded private key used to sign transactions.
+	PrivateKey string `json:"private_key" yaml:"private_key" env:"PRIVATE_KEY"`
+	// KeystorePath is the path to encrypted key file, used when PrivateKey is empty.
+	KeystorePath string `json:"keystore_path" yaml:"keystore_path" env:"KEYSTORE_PATH"`
+	// KeystorePassword is the password of encrypted key file.
+	KeystorePassword string `json:"keystore_password" yaml:"keystore_password" env:"KEYSTORE_PASSWORD"`
+	// HandleNonce indicates that nonce of the key address must be handled internally (see backend.NewHandleNonceBackend).
+	HandleNonce bool `json:"handle_nonce" yaml:"handle_nonce" env:"HANDLE_NONCE"`
+	// Gas contains gas settings of transactions.
+	Gas Gas `json:"gas" yaml:"gas" env:"GAS"`
+	// StartBlock is the number of the block from which BlockSource starts the delivery of blocks.
+	StartBlock *big.Int `json:"start_block" yaml:"start_block" env:"START_BLOCK"`
+	// Confirmations is the number of confirmations the block must have to be delivered by BlockSource.
+	Confirmations uint `json:"confirmations" yaml:"confirmations" env:"CONFIRMATIONS"`
+	// WatchAddresses are the addresses the consumer is interested in, BlockSource delivers only their transactions.
+	WatchAddresses []common.Address `json:"watch_addresses" yaml:"watch_addresses" env:"WATCH_ADDRESSES"`
+}
+
+// Gas contains gas settings of transactions.
+type Gas struct {
+	// Price is the gas price in wei. Gas price is retrieved from the backend when it's nil.
+	Price *big.Int `json:"price" yaml:"price" env:"PRICE"`
+	// Limit is the gas limit of transactions. Gas limit is estimated when it's zero.
+	Limit uint64 `json:"limit" yaml:"limit" env:"LIMIT"`
+}
+
+// LoadFile reads configuration from JSON (.json) or YAML (.yaml, .yml) file.
+func LoadFile(path string) (*Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("config: %v", err)
+	}
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		return ParseJSON(data)
+	case ".yaml", ".yml":
+		return ParseYAML(data)
+	default:
+		return nil, fmt.Errorf("config: unsupported file extension %q", ext)
+	}
+}
+
+// ParseJSON parses JSON configuration.
+func ParseJSON(data []byte) (*Config, error) {
+	c := &Config{}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("config: json: %v", err)
+	}
+	return c, nil
+}
+
+// ParseYAML parses YAML configuration.
+func ParseYAML(data []byte) (*Config, error) {
+	c := &Config{}
+	if err := yaml.UnmarshalStrict(data, c); err != nil {
+		return nil, fmt.Errorf("config: yaml: %v", err)
+	}
+	return c, nil
+}
+
+// LoadEnv overrides configuration with values of environment variables. Variable names consist of the given
+// prefix and upper-case field name, e.g. prefix "APP_" gives APP_RPC_URL, APP_GAS_PRICE, APP_WATCH_ADDRESSES.
+// List values are comma-separated.
+func (c *Config) LoadEnv(prefix string) error {
+	if err := loadEnv(c, prefix); err != nil {
+		return fmt.Errorf("config: %v", err)
+	}
+	return nil
+}
+
+// Validate checks that configuration is consistent.
+func (c *Config) Validate() error {
+	if c.RPCURL == "" {
+		return errors.New("config: RPC URL is required")
+	}
+	if c.PrivateKey != "" && c.KeystorePath != "" {
+		return errors.New("config: only one of private key and keystore path must be specified")
+	}
+	if c.HandleNonce && c.PrivateKey == "" && c.KeystorePath == "" {
+		return errors.New("config: key is required to handle nonce")
+	}
+	return nil
+}
+
+// HasKey returns true when either private key or keystore path is specified.
+func (c *Config) HasKey() bool {
+	return c.PrivateKey != "" || c.KeystorePath != ""
+}
+
+// Key returns the key from the private key or the keystore file.
+func (c *Config) Key() (*ethereum.Key, error) {
+	if c.PrivateKey != "" {
+		k, err := ethereum.NewKeyFromPrivateKey(strings.TrimPrefix(c.PrivateKey, "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("config: private key: %v", err)
+		}
+		return k, nil
+	}
+
+	if c.KeystorePath == "" {
+		return nil, errors.New("config: neither private key nor keystore path is specified")
+	}
+
+	keyJSON, err := ioutil.ReadFile(c.KeystorePath)
+	if err != nil {
+		return nil, fmt.Errorf("config: keystore: %v", err)
+	}
+	k, err := keystore.DecryptKey(keyJSON, c.KeystorePassword)
+	if err != nil {
+		return nil, fmt.Errorf("config: keystore: %v", err)
+	}
+
+	return &ethereum.Key{Address: k.Address, PrivateKey: k.PrivateKey}, nil
+}
+
+// NewBackend connects to RPC URL.
+func (c *Config) NewBackend() (backend.Backend, error) {
+	cl, err := ethclient.Dial(c.RPCURL)
+	if err != nil {
+		return nil, fmt.Errorf("config: ethclient.Dial: %v", err)
+	}
+	return cl, nil
+}
+
+// NewEth connects to RPC URL and creates an instance of Eth waiting for the configured number of confirmations.
+// Nonce of the key address is handled internally when HandleNonce is set. Gas price is retrieved from the backend
+// when it's not specified. The returned closer closes the connection and must be called when Eth is not used anymore.
+func (c *Config) NewEth(ctx context.Context, lf log.Fun) (*ethereum.Eth, io.Closer, error) {
+	if err := c.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	opts := []ethereum.Option{ethereum.WithLogger(lf)}
+	if c.HandleNonce {
+		k, err := c.Key()
+		if err != nil {
+			return nil, nil, err
+		}
+		opts = append(opts, ethereum.WithHandleNonce(k.Address))
+	}
+	if c.Confirmations > 0 {
+		opts = append(opts, ethereum.WithConfirmations(c.Confirmations))
+	}
+
+	cl, err := ethclient.Dial(c.RPCURL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("config: ethclient.Dial: %v", err)
+	}
+	closer := ethereum.Closers{closeFunc(cl.Close)}
+
+	e := ethereum.NewEth(cl, opts...)
+
+	if c.Gas.Price != nil {
+		e.SuggestedGasPrice = new(big.Int).Set(c.Gas.Price)
+	} else if err := e.UpdateSuggestedGasPrice(ctx); err != nil {
+		_ = closer.Close()
+		return nil, nil, fmt.Errorf("config: UpdateSuggestedGasPrice: %v", err)
+	}
+
+	return e, closer, nil
+}
+
+// NewSession creates an instance of Eth and session of the configured key. The returned closer closes
+// the connection and must be called when the session is not used anymore.
+func (c *Config) NewSession(ctx context.Context, lf log.Fun) (*ethereum.Session, io.Closer, error) {
+	k, err := c.Key()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	e, closer, err := c.NewEth(ctx, lf)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s := e.NewSession(k.PrivateKey)
+	s.TransactOpts.GasLimit = c.Gas.Limit
+
+	return s, closer, nil
+}
+
+// NewBlockSource creates an instance of BlockSource starting from StartBlock with the configured number
+// of confirmations. Delivered blocks include only transactions of WatchAddresses when they're specified.
+func (c *Config) NewBlockSource() (*blocksource.BlockSource, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	bc := &blocksource.Config{
+		StartBlock:    c.StartBlock,
+		Confirmations: c.Confirmations,
+	}
+	if len(c.WatchAddresses) > 0 {
+		bc.Addresses = addresset.New(c.WatchAddresses)
+	}
+
+	return blocksource.New(c.RPCURL, bc)
+}
+
+// NewGasPriceEstimator creates an instance of GasPriceEstimator.
+func (c *Config) NewGasPriceEstimator(opts ...gasestimator.Option) (*gasestimator.GasPriceEstimator, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	return gasestimator.NewGasPriceEstimator(c.RPCURL, opts...)
+}
+
+// closeFunc adapts Close method without result (e.g. of ethclient.Client) to io.Closer interface.
+type closeFunc func()
+
+// Close implements io.Closer interface.
+func (f closeFunc) Close() error {
+	f()
+	return nil
+}
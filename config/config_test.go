@@ -0,0 +1,157 @@
+package config
+
+import (
+	"context"
+	"math/big"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	addr1 = common.HexToAddress("0x1111111111111111111111111111111111111111")
+	addr2 = common.HexToAddress("0x2222222222222222222222222222222222222222")
+)
+
+func expectedConfig() *Config {
+	return &Config{
+		RPCURL:        "https://ropsten.infura.io",
+		PrivateKey:    "abcdef",
+		HandleNonce:   true,
+		Gas:           Gas{Price: big.NewInt(20000000000), Limit: 100000},
+		StartBlock:    big.NewInt(5000000),
+		Confirmations: 6,
+		WatchAddresses: []common.Address{
+			addr1,
+			addr2,
+		},
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	c, err := ParseJSON([]byte(`{
+		"rpc_url": "https://ropsten.infura.io",
+		"private_key": "abcdef",
+		"handle_nonce": true,
+		"gas": {"price": 20000000000, "limit": 100000},
+		"start_block": 5000000,
+		"confirmations": 6,
+		"watch_addresses": ["0x1111111111111111111111111111111111111111", "0x2222222222222222222222222222222222222222"]
+	}`))
+	if err != nil {
+		t.Fatalf("ParseJSON: %v", err)
+	}
+
+	if expected := expectedConfig(); !reflect.DeepEqual(expected, c) {
+		t.Errorf("expected %+v, but got %+v", expected, c)
+	}
+}
+
+func TestParseYAML(t *testing.T) {
+	c, err := ParseYAML([]byte(`
+rpc_url: https://ropsten.infura.io
+private_key: abcdef
+handle_nonce: true
+gas:
+  price: 20000000000
+  limit: 100000
+start_block: 5000000
+confirmations: 6
+watch_addresses:
+  - "0x1111111111111111111111111111111111111111"
+  - "0x2222222222222222222222222222222222222222"
+`))
+	if err != nil {
+		t.Fatalf("ParseYAML: %v", err)
+	}
+
+	if expected := expectedConfig(); !reflect.DeepEqual(expected, c) {
+		t.Errorf("expected %+v, but got %+v", expected, c)
+	}
+}
+
+func TestConfig_LoadEnv(t *testing.T) {
+	env := map[string]string{
+		"TEST_RPC_URL":         "https://ropsten.infura.io",
+		"TEST_PRIVATE_KEY":     "abcdef",
+		"TEST_HANDLE_NONCE":    "true",
+		"TEST_GAS_PRICE":       "20000000000",
+		"TEST_GAS_LIMIT":       "100000",
+		"TEST_START_BLOCK":     "5000000",
+		"TEST_CONFIRMATIONS":   "6",
+		"TEST_WATCH_ADDRESSES": "0x1111111111111111111111111111111111111111, 0x2222222222222222222222222222222222222222",
+	}
+	for k, v := range env {
+		os.Setenv(k, v)
+		defer os.Unsetenv(k)
+	}
+
+	c := &Config{RPCURL: "http://localhost:8545", Confirmations: 1}
+	if err := c.LoadEnv("TEST_"); err != nil {
+		t.Fatalf("LoadEnv: %v", err)
+	}
+
+	if expected := expectedConfig(); !reflect.DeepEqual(expected, c) {
+		t.Errorf("expected %+v, but got %+v", expected, c)
+	}
+}
+
+func TestConfig_LoadEnv_Invalid(t *testing.T) {
+	os.Setenv("TEST_CONFIRMATIONS", "many")
+	defer os.Unsetenv("TEST_CONFIRMATIONS")
+
+	if err := (&Config{}).LoadEnv("TEST_"); err == nil {
+		t.Errorf("expected error")
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		isValid bool
+	}{
+		{"valid", Config{RPCURL: "http://localhost:8545", PrivateKey: "abcdef", HandleNonce: true}, true},
+		{"no key", Config{RPCURL: "http://localhost:8545"}, true},
+		{"no RPC URL", Config{}, false},
+		{"private key and keystore", Config{RPCURL: "http://localhost:8545", PrivateKey: "abcdef", KeystorePath: "key.json"}, false},
+		{"handle nonce without key", Config{RPCURL: "http://localhost:8545", HandleNonce: true}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.isValid && err != nil {
+				t.Errorf("expected valid config, but got %v", err)
+			}
+			if !tt.isValid && err == nil {
+				t.Errorf("expected error")
+			}
+		})
+	}
+}
+
+func TestConfig_NewEth(t *testing.T) {
+	c := &Config{
+		RPCURL:        "http://localhost:8545",
+		Gas:           Gas{Price: big.NewInt(20000000000)},
+		Confirmations: 6,
+	}
+
+	e, closer, err := c.NewEth(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("NewEth: %v", err)
+	}
+	if err := closer.Close(); err != nil {
+		t.Errorf("Close: %v", err)
+	}
+
+	if e.Confirmations != c.Confirmations {
+		t.Errorf("expected %v confirmations, but got %v", c.Confirmations, e.Confirmations)
+	}
+	if e.SuggestedGasPrice.Cmp(c.Gas.Price) != 0 {
+		t.Errorf("expected gas price %v, but got %v", c.Gas.Price, e.SuggestedGasPrice)
+	}
+}
@@ -0,0 +1,283 @@
+// Package contractinspect detects what is deployed at an address: whether it's a contract, which ERC-165 interfaces
+// it supports, which common standards (ERC-20, ERC-721, ERC-1155) it likely implements and whether it's a proxy.
+// It can be used to guard transfers and to choose decoders of contract data automatically.
+package contractinspect
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// InterfaceID is the ERC-165 interface identifier.
+type InterfaceID [4]byte
+
+// Well-known ERC-165 interface identifiers.
+var (
+	ERC165Interface         = InterfaceID{0x01, 0xff, 0xc9, 0xa7}
+	ERC721Interface         = InterfaceID{0x80, 0xac, 0x58, 0xcd}
+	ERC721MetadataInterface = InterfaceID{0x5b, 0x5e, 0x13, 0x9f}
+	ERC1155Interface        = InterfaceID{0xd9, 0xb6, 0x7a, 0x26}
+	ERC2981Interface        = InterfaceID{0x2a, 0x55, 0x20, 0x5a}
+)
+
+var invalidInterface = InterfaceID{0xff, 0xff, 0xff, 0xff}
+
+// Standard is the token standard.
+type Standard string
+
+// Detected standards.
+const (
+	ERC20   Standard = "ERC-20"
+	ERC721  Standard = "ERC-721"
+	ERC1155 Standard = "ERC-1155"
+)
+
+// ProxyKind is the kind of the proxy contract.
+type ProxyKind string
+
+// Detected kinds of proxies.
+const (
+	// MinimalProxy is EIP-1167 minimal proxy (clone).
+	MinimalProxy ProxyKind = "EIP-1167"
+	// EIP1967Proxy is the proxy storing the implementation address in EIP-1967 slot (transparent and UUPS proxies).
+	EIP1967Proxy ProxyKind = "EIP-1967"
+	// BeaconProxy is EIP-1967 proxy getting the implementation address from the beacon.
+	BeaconProxy ProxyKind = "EIP-1967 beacon"
+)
+
+var (
+	// eip1967ImplementationSlot is bytes32(uint256(keccak256('eip1967.proxy.implementation')) - 1)
+	eip1967ImplementationSlot = common.HexToHash("0x360894a13ba1a3210667c828492db98dca3e2076cc3735a920a3ca505d382bbc")
+	// eip1967BeaconSlot is bytes32(uint256(keccak256('eip1967.proxy.beacon')) - 1)
+	eip1967BeaconSlot = common.HexToHash("0xa3f0ad74e5423aebfd80d3ef4346578335a9a72aeaee59ff6cb3582b35133d50")
+
+	minimalProxyPrefix = common.FromHex("0x363d3d373d3d3d363d73")
+	minimalProxySuffix = common.FromHex("0x5af43d82803e903d91602b57fd5bf3")
+
+	supportsInterfaceSelector = []byte{0x01, 0xff, 0xc9, 0xa7}
+)
+
+// erc20Selectors are selectors of required ERC-20 functions.
+var erc20Selectors = [][]byte{
+	{0x18, 0x16, 0x0d, 0xdd}, // totalSupply()
+	{0x70, 0xa0, 0x82, 0x31}, // balanceOf(address)
+	{0xa9, 0x05, 0x9c, 0xbb}, // transfer(address,uint256)
+	{0x23, 0xb8, 0x72, 0xdd}, // transferFrom(address,address,uint256)
+	{0x09, 0x5e, 0xa7, 0xb3}, // approve(address,uint256)
+	{0xdd, 0x62, 0xed, 0x3e}, // allowance(address,address)
+}
+
+// Caller contains methods of the backend required for inspection. Backends with StorageAt method
+// (like ethclient.Client) allow to detect EIP-1967 proxies.
+type Caller interface {
+	CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error)
+	CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error)
+}
+
+type storageReader interface {
+	StorageAt(ctx context.Context, account common.Address, key common.Hash, blockNumber *big.Int) ([]byte, error)
+}
+
+// Proxy describes the detected proxy.
+type Proxy struct {
+	Kind           ProxyKind
+	Implementation common.Address
+	Beacon         *common.Address // set for beacon proxies only
+}
+
+// Report is the result of inspection of the address.
+type Report struct {
+	Address    common.Address
+	IsContract bool
+	CodeSize   int
+	CodeHash   common.Hash
+	// ERC165 is true if the contract supports ERC-165 interface detection.
+	ERC165 bool
+	// Interfaces holds the well-known interfaces which the contract reported as supported via ERC-165.
+	Interfaces []InterfaceID
+	// Standards holds the detected standards. ERC-20 is detected heuristically by function selectors in the code,
+	// so it's not detected for proxies unless the implementation is inspected.
+	Standards []Standard
+	// Proxy is set when the contract is a proxy.
+	Proxy *Proxy
+}
+
+// Supports returns true if the contract implements the standard.
+func (r *Report) Supports(s Standard) bool {
+	for _, st := range r.Standards {
+		if st == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Inspector inspects addresses.
+type Inspector struct {
+	c Caller
+}
+
+// New creates an instance of Inspector.
+func New(c Caller) *Inspector {
+	return &Inspector{c: c}
+}
+
+// IsContract returns true if there is code at the address.
+func (i *Inspector) IsContract(ctx context.Context, address common.Address) (bool, error) {
+	code, err := i.c.CodeAt(ctx, address, nil)
+	if err != nil {
+		return false, fmt.Errorf("contractinspect: CodeAt(%v): %v", address.Hex(), err)
+	}
+	return len(code) > 0, nil
+}
+
+// SupportsInterface returns true if the contract supports ERC-165 and reports the interface as supported.
+// Contracts without ERC-165 support (including accounts without code) don't support any interface.
+func (i *Inspector) SupportsInterface(ctx context.Context, address common.Address, id InterfaceID) (bool, error) {
+	ok, err := i.supportsERC165(ctx, address)
+	if err != nil || !ok {
+		return false, err
+	}
+	return i.supportsInterface(ctx, address, id)
+}
+
+// Inspect inspects the address. For proxies the implementation is inspected too, to detect standards.
+func (i *Inspector) Inspect(ctx context.Context, address common.Address) (*Report, error) {
+	code, err := i.c.CodeAt(ctx, address, nil)
+	if err != nil {
+		return nil, fmt.Errorf("contractinspect: CodeAt(%v): %v", address.Hex(), err)
+	}
+
+	r := &Report{Address: address, IsContract: len(code) > 0, CodeSize: len(code)}
+	if !r.IsContract {
+		return r, nil
+	}
+	r.CodeHash = crypto.Keccak256Hash(code)
+
+	if r.Proxy, err = i.detectProxy(ctx, address, code); err != nil {
+		return nil, err
+	}
+
+	if r.ERC165, err = i.supportsERC165(ctx, address); err != nil {
+		return nil, err
+	}
+	if r.ERC165 {
+		for _, id := range []InterfaceID{ERC721Interface, ERC721MetadataInterface, ERC1155Interface, ERC2981Interface} {
+			ok, err := i.supportsInterface(ctx, address, id)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				r.Interfaces = append(r.Interfaces, id)
+			}
+		}
+	}
+
+	for _, id := range r.Interfaces {
+		switch id {
+		case ERC721Interface:
+			r.Standards = append(r.Standards, ERC721)
+		case ERC1155Interface:
+			r.Standards = append(r.Standards, ERC1155)
+		}
+	}
+
+	erc20Code := code
+	if r.Proxy != nil {
+		if erc20Code, err = i.c.CodeAt(ctx, r.Proxy.Implementation, nil); err != nil {
+			return nil, fmt.Errorf("contractinspect: CodeAt(%v): %v", r.Proxy.Implementation.Hex(), err)
+		}
+	}
+	if !r.Supports(ERC721) && hasSelectors(erc20Code, erc20Selectors) {
+		r.Standards = append(r.Standards, ERC20)
+	}
+
+	return r, nil
+}
+
+func (i *Inspector) detectProxy(ctx context.Context, address common.Address, code []byte) (*Proxy, error) {
+	if len(code) == len(minimalProxyPrefix)+common.AddressLength+len(minimalProxySuffix) &&
+		bytes.HasPrefix(code, minimalProxyPrefix) && bytes.HasSuffix(code, minimalProxySuffix) {
+		impl := common.BytesToAddress(code[len(minimalProxyPrefix) : len(minimalProxyPrefix)+common.AddressLength])
+		return &Proxy{Kind: MinimalProxy, Implementation: impl}, nil
+	}
+
+	sr, ok := i.c.(storageReader)
+	if !ok {
+		return nil, nil
+	}
+
+	impl, err := i.addressAt(ctx, sr, address, eip1967ImplementationSlot)
+	if err != nil {
+		return nil, err
+	}
+	if impl != (common.Address{}) {
+		return &Proxy{Kind: EIP1967Proxy, Implementation: impl}, nil
+	}
+
+	beacon, err := i.addressAt(ctx, sr, address, eip1967BeaconSlot)
+	if err != nil || beacon == (common.Address{}) {
+		return nil, err
+	}
+	// implementation() of the beacon
+	out, err := i.c.CallContract(ctx, ethereum.CallMsg{To: &beacon, Data: []byte{0x5c, 0x60, 0xda, 0x1b}}, nil)
+	if err != nil || len(out) < 32 {
+		return nil, nil // not a beacon
+	}
+	return &Proxy{Kind: BeaconProxy, Implementation: common.BytesToAddress(out[:32]), Beacon: &beacon}, nil
+}
+
+func (i *Inspector) addressAt(ctx context.Context, sr storageReader, address common.Address, slot common.Hash) (common.Address, error) {
+	value, err := sr.StorageAt(ctx, address, slot, nil)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("contractinspect: StorageAt(%v, %v): %v", address.Hex(), slot.Hex(), err)
+	}
+	return common.BytesToAddress(value), nil
+}
+
+// supportsERC165 detects ERC-165 support as specified by the standard.
+func (i *Inspector) supportsERC165(ctx context.Context, address common.Address) (bool, error) {
+	ok, err := i.supportsInterface(ctx, address, ERC165Interface)
+	if err != nil || !ok {
+		return false, err
+	}
+	ok, err = i.supportsInterface(ctx, address, invalidInterface)
+	return err == nil && !ok, err
+}
+
+// supportsInterface calls supportsInterface(bytes4) function. Reverted calls and malformed results mean
+// the interface isn't supported.
+func (i *Inspector) supportsInterface(ctx context.Context, address common.Address, id InterfaceID) (bool, error) {
+	data := make([]byte, 4+32)
+	copy(data, supportsInterfaceSelector)
+	copy(data[4:], id[:])
+
+	out, err := i.c.CallContract(ctx, ethereum.CallMsg{To: &address, Data: data, Gas: 30000}, nil)
+	if err != nil {
+		if ctx.Err() != nil {
+			return false, ctx.Err()
+		}
+		return false, nil // reverted
+	}
+	if len(out) != 32 {
+		return false, nil
+	}
+	return new(big.Int).SetBytes(out).Cmp(big.NewInt(1)) == 0, nil
+}
+
+// hasSelectors returns true if the code pushes all the selectors (PUSH4 selector), which is how the function
+// dispatcher of compiled contracts looks like.
+func hasSelectors(code []byte, selectors [][]byte) bool {
+	for _, sel := range selectors {
+		if !bytes.Contains(code, append([]byte{0x63}, sel...)) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,147 @@
+package contractinspect
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type callerMock struct {
+	code       map[common.Address][]byte
+	interfaces map[common.Address][]InterfaceID
+	storage    map[common.Hash]common.Hash
+}
+
+func (m *callerMock) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	return m.code[contract], nil
+}
+
+func (m *callerMock) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	ids, ok := m.interfaces[*call.To]
+	if !ok || !bytes.HasPrefix(call.Data, supportsInterfaceSelector) {
+		return nil, errors.New("execution reverted")
+	}
+
+	var id InterfaceID
+	copy(id[:], call.Data[4:8])
+	res := make([]byte, 32)
+	for _, supported := range append(ids, ERC165Interface) {
+		if supported == id {
+			res[31] = 1
+		}
+	}
+	return res, nil
+}
+
+func (m *callerMock) StorageAt(ctx context.Context, account common.Address, key common.Hash, blockNumber *big.Int) ([]byte, error) {
+	v := m.storage[key]
+	return v[:], nil
+}
+
+func erc20Code() []byte {
+	var code []byte
+	for _, sel := range erc20Selectors {
+		code = append(code, 0x63)
+		code = append(code, sel...)
+	}
+	return code
+}
+
+func TestInspector_Inspect(t *testing.T) {
+	var (
+		eoa      = common.HexToAddress("0x1")
+		token    = common.HexToAddress("0x2")
+		nft      = common.HexToAddress("0x3")
+		clone    = common.HexToAddress("0x4")
+		proxy    = common.HexToAddress("0x5")
+		tokenImp = common.HexToAddress("0x6")
+	)
+
+	cloneCode := append(append(append([]byte{}, minimalProxyPrefix...), token.Bytes()...), minimalProxySuffix...)
+	m := &callerMock{
+		code: map[common.Address][]byte{
+			token:    erc20Code(),
+			nft:      {0x60, 0x80},
+			clone:    cloneCode,
+			proxy:    {0x60, 0x80},
+			tokenImp: erc20Code(),
+		},
+		interfaces: map[common.Address][]InterfaceID{
+			nft: {ERC721Interface, ERC721MetadataInterface},
+		},
+	}
+	insp := New(m)
+	ctx := context.Background()
+
+	r, err := insp.Inspect(ctx, eoa)
+	if err != nil {
+		t.Fatalf("Inspect: %v", err)
+	}
+	if r.IsContract || r.Standards != nil {
+		t.Errorf("expected account without code, but got %+v", r)
+	}
+
+	r, err = insp.Inspect(ctx, token)
+	if err != nil {
+		t.Fatalf("Inspect: %v", err)
+	}
+	if !r.IsContract || r.ERC165 || !reflect.DeepEqual(r.Standards, []Standard{ERC20}) {
+		t.Errorf("expected ERC-20 token, but got %+v", r)
+	}
+
+	r, err = insp.Inspect(ctx, nft)
+	if err != nil {
+		t.Fatalf("Inspect: %v", err)
+	}
+	if !r.ERC165 || !reflect.DeepEqual(r.Interfaces, []InterfaceID{ERC721Interface, ERC721MetadataInterface}) || !r.Supports(ERC721) {
+		t.Errorf("expected ERC-721 token, but got %+v", r)
+	}
+
+	r, err = insp.Inspect(ctx, clone)
+	if err != nil {
+		t.Fatalf("Inspect: %v", err)
+	}
+	if r.Proxy == nil || r.Proxy.Kind != MinimalProxy || r.Proxy.Implementation != token || !r.Supports(ERC20) {
+		t.Errorf("expected minimal proxy of ERC-20 token, but got %+v", r)
+	}
+
+	m.storage = map[common.Hash]common.Hash{eip1967ImplementationSlot: tokenImp.Hash()}
+	r, err = insp.Inspect(ctx, proxy)
+	if err != nil {
+		t.Fatalf("Inspect: %v", err)
+	}
+	if r.Proxy == nil || r.Proxy.Kind != EIP1967Proxy || r.Proxy.Implementation != tokenImp || !r.Supports(ERC20) {
+		t.Errorf("expected EIP-1967 proxy of ERC-20 token, but got %+v", r)
+	}
+}
+
+func TestInspector_SupportsInterface(t *testing.T) {
+	nft := common.HexToAddress("0x3")
+	insp := New(&callerMock{interfaces: map[common.Address][]InterfaceID{nft: {ERC1155Interface}}})
+
+	testCases := []struct {
+		address  common.Address
+		id       InterfaceID
+		expected bool
+	}{
+		{nft, ERC1155Interface, true},
+		{nft, ERC721Interface, false},
+		{common.HexToAddress("0x1"), ERC1155Interface, false},
+	}
+
+	for _, tc := range testCases {
+		ok, err := insp.SupportsInterface(context.Background(), tc.address, tc.id)
+		if err != nil {
+			t.Fatalf("SupportsInterface: %v", err)
+		}
+		if ok != tc.expected {
+			t.Errorf("SupportsInterface(%v, %x): expected %v, but got %v", tc.address.Hex(), tc.id, tc.expected, ok)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package ethereum
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// CodeMismatchError is returned by VerifyDeployedCode when the code at the address differs from the expected one.
+type CodeMismatchError struct {
+	Address  common.Address
+	Hash     common.Hash // Keccak256 hash of the deployed code
+	Expected common.Hash
+}
+
+func (e *CodeMismatchError) Error() string {
+	return fmt.Sprintf("deployed code mismatch: code hash of %v is %v, expected %v", e.Address.Hex(), e.Hash.Hex(), e.Expected.Hex())
+}
+
+// ContractAddress returns the address of the contract created with CREATE by the sender (account or contract)
+// with the given nonce.
+func ContractAddress(sender common.Address, nonce uint64) common.Address {
+	return crypto.CreateAddress(sender, nonce)
+}
+
+// Create2Address returns the address of the contract created with CREATE2 by the deployer contract with the given salt
+// and init code (creation code with encoded constructor arguments).
+func Create2Address(deployer common.Address, salt [32]byte, initCode []byte) common.Address {
+	return crypto.CreateAddress2(deployer, salt, crypto.Keccak256(initCode))
+}
+
+// Create2AddressFromHash works like Create2Address, but takes Keccak256 hash of the init code.
+func Create2AddressFromHash(deployer common.Address, salt [32]byte, initCodeHash common.Hash) common.Address {
+	return crypto.CreateAddress2(deployer, salt, initCodeHash.Bytes())
+}
+
+// NextContractAddress returns the address of the contract which the next contract creation transaction of the account
+// will create, based on the pending nonce of the account.
+func (e *Eth) NextContractAddress(ctx context.Context, account common.Address) (common.Address, error) {
+	nonce, err := e.Backend.PendingNonceAt(ctx, account)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("backend PendingNonceAt(%v): %v", account.Hex(), err)
+	}
+	return ContractAddress(account, nonce), nil
+}
+
+// VerifyDeployedCode checks that the runtime code deployed at the address has the expected Keccak256 hash.
+// It returns bind.ErrNoCode if there is no code at the address (e.g. the counterfactual contract isn't deployed yet)
+// and *CodeMismatchError if the code differs.
+func (e *Eth) VerifyDeployedCode(ctx context.Context, address common.Address, expectedRuntimeHash common.Hash) error {
+	code, err := e.Backend.CodeAt(ctx, address, nil)
+	if err != nil {
+		return fmt.Errorf("backend CodeAt(%v): %v", address.Hex(), err)
+	}
+	if len(code) == 0 {
+		return bind.ErrNoCode
+	}
+
+	if hash := crypto.Keccak256Hash(code); hash != expectedRuntimeHash {
+		return &CodeMismatchError{Address: address, Hash: hash, Expected: expectedRuntimeHash}
+	}
+	return nil
+}
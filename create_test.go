@@ -0,0 +1,88 @@
+package ethereum
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/monetha/go-ethereum/backend"
+)
+
+func TestContractAddress(t *testing.T) {
+	sender := common.HexToAddress("0x6ac7ea33f8831ea9dcc53393aaa88b25a785dbf0")
+	testCases := []struct {
+		nonce    uint64
+		expected string
+	}{
+		{0, "0xcd234a471b72ba2f1ccf0a70fcaba648a5eecd8d"},
+		{1, "0x343c43a37d37dff08ae8c4a11544c718abb4fcf8"},
+		{2, "0xf778b86fa74e846c4f0a1fbd1335fe81c00a0c91"},
+	}
+
+	for _, tc := range testCases {
+		if address := ContractAddress(sender, tc.nonce); address != common.HexToAddress(tc.expected) {
+			t.Errorf("nonce %v: expected address %v, but got %v", tc.nonce, tc.expected, address.Hex())
+		}
+	}
+}
+
+func TestCreate2Address(t *testing.T) {
+	// examples of EIP-1014
+	testCases := []struct {
+		deployer string
+		salt     string
+		initCode []byte
+		expected string
+	}{
+		{"0x0000000000000000000000000000000000000000", "0x00", []byte{0x00}, "0x4D1A2e2bB4F88F0250f26Ffff098B0b30B26BF38"},
+		{"0xdeadbeef00000000000000000000000000000000", "0x00", []byte{0x00}, "0xB928f69Bb1D91Cd65274e3c79d8986362984fDA3"},
+		{"0xdeadbeef00000000000000000000000000000000", "0x000000000000000000000000feed000000000000000000000000000000000000", []byte{0x00}, "0xD04116cDd17beBE565EB2422F2497E06cC1C9833"},
+		{"0x00000000000000000000000000000000deadbeef", "0x00000000000000000000000000000000000000000000000000000000cafebabe", common.FromHex("0xdeadbeef"), "0x60f3f640a8508fC6a86d45DF051962668E1e8AC7"},
+	}
+
+	for _, tc := range testCases {
+		deployer := common.HexToAddress(tc.deployer)
+		salt := common.HexToHash(tc.salt)
+
+		if address := Create2Address(deployer, salt, tc.initCode); address != common.HexToAddress(tc.expected) {
+			t.Errorf("expected address %v, but got %v", tc.expected, address.Hex())
+		}
+		if address := Create2AddressFromHash(deployer, salt, crypto.Keccak256Hash(tc.initCode)); address != common.HexToAddress(tc.expected) {
+			t.Errorf("expected address %v from init code hash, but got %v", tc.expected, address.Hex())
+		}
+	}
+}
+
+type codeMock struct {
+	backend.Backend
+	code []byte
+}
+
+func (m codeMock) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	return m.code, nil
+}
+
+func TestEth_VerifyDeployedCode(t *testing.T) {
+	ctx := context.Background()
+	code := []byte{0x00}
+	address := common.HexToAddress("0x1")
+
+	e := New(codeMock{code: code}, nil)
+	if err := e.VerifyDeployedCode(ctx, address, crypto.Keccak256Hash(code)); err != nil {
+		t.Errorf("expected no error, but got %v", err)
+	}
+	err := e.VerifyDeployedCode(ctx, address, common.Hash{})
+	if mismatch, ok := err.(*CodeMismatchError); !ok {
+		t.Errorf("expected code mismatch error, but got %v", err)
+	} else if mismatch.Address != address || mismatch.Hash != crypto.Keccak256Hash(code) || mismatch.Expected != (common.Hash{}) {
+		t.Errorf("unexpected code mismatch error: %+v", mismatch)
+	}
+
+	e = New(codeMock{}, nil)
+	if err := e.VerifyDeployedCode(ctx, address, crypto.Keccak256Hash(code)); err != bind.ErrNoCode {
+		t.Errorf("expected error %v, but got %v", bind.ErrNoCode, err)
+	}
+}
@@ -0,0 +1,138 @@
+// Package cursor provides the position in the chain up to which blocks or logs are processed, so streaming
+// components resume after restart without skipping or reprocessing items, and detect that the position was
+// removed by chain reorganization.
+package cursor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// EndOfBlock is the log index of the cursor at the block which is processed entirely.
+const EndOfBlock = -1
+
+// Cursor is the position of the last processed item: the block, or the log within the block.
+type Cursor struct {
+	BlockNumber uint64      `json:"blockNumber"`
+	BlockHash   common.Hash `json:"blockHash"`
+	LogIndex    int         `json:"logIndex"` // EndOfBlock when the block is processed entirely
+}
+
+// AtBlock returns the cursor at the block which is processed entirely.
+func AtBlock(number uint64, hash common.Hash) Cursor {
+	return Cursor{BlockNumber: number, BlockHash: hash, LogIndex: EndOfBlock}
+}
+
+// FromLog returns the cursor at the log.
+func FromLog(l types.Log) Cursor {
+	return Cursor{BlockNumber: l.BlockNumber, BlockHash: l.BlockHash, LogIndex: int(l.Index)}
+}
+
+// IsZero tells whether the cursor isn't set.
+func (c Cursor) IsZero() bool {
+	return c == Cursor{}
+}
+
+// Compare returns -1, 0 or 1 when the cursor is before, at or after the other one. Hashes aren't compared.
+func (c Cursor) Compare(other Cursor) int {
+	switch {
+	case c.BlockNumber < other.BlockNumber:
+		return -1
+	case c.BlockNumber > other.BlockNumber:
+		return 1
+	}
+
+	i, j := c.logOrder(), other.logOrder()
+	switch {
+	case i < j:
+		return -1
+	case i > j:
+		return 1
+	}
+	return 0
+}
+
+// logOrder orders EndOfBlock after all logs of the block.
+func (c Cursor) logOrder() int {
+	if c.LogIndex == EndOfBlock {
+		return math.MaxInt32
+	}
+	return c.LogIndex
+}
+
+// Processed tells whether the log is at or before the cursor, so it must be skipped on resume.
+func (c Cursor) Processed(l types.Log) bool {
+	return !c.IsZero() && FromLog(l).Compare(c) <= 0
+}
+
+// ResumeBlock returns the number of the block from which processing resumes (e.g. StartBlock of BlockSource or
+// Start of bind.WatchOpts): the next block when the block of the cursor is processed entirely, otherwise the block
+// of the cursor (its processed logs must be skipped, see Processed).
+func (c Cursor) ResumeBlock() *big.Int {
+	n := new(big.Int).SetUint64(c.BlockNumber)
+	if c.LogIndex == EndOfBlock {
+		n.Add(n, big.NewInt(1))
+	}
+	return n
+}
+
+func (c Cursor) String() string {
+	if c.LogIndex == EndOfBlock {
+		return fmt.Sprintf("block %v (%v)", c.BlockNumber, c.BlockHash.Hex())
+	}
+	return fmt.Sprintf("block %v (%v) log %v", c.BlockNumber, c.BlockHash.Hex(), c.LogIndex)
+}
+
+// RPCCaller calls RPC methods, it's implemented by *rpc.Client.
+type RPCCaller interface {
+	CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error
+}
+
+// ReorgError is returned by Validate when the block of the cursor isn't in the chain anymore.
+type ReorgError struct {
+	Cursor    Cursor
+	ChainHash common.Hash // hash of the block with the same number in the chain, zero when there is no such block
+}
+
+func (e *ReorgError) Error() string {
+	if e.ChainHash == (common.Hash{}) {
+		return fmt.Sprintf("cursor: %v is not in the chain", e.Cursor)
+	}
+	return fmt.Sprintf("cursor: %v is not in the chain, block hash is %v", e.Cursor, e.ChainHash.Hex())
+}
+
+// Validate checks that the block of the cursor is still in the chain, otherwise it returns ReorgError, and the
+// processing must resume from an earlier position. The hash reported by the node is compared, as the hash of
+// the header can't be recomputed from the fields known to go-ethereum 1.8 for blocks since London.
+func (c Cursor) Validate(ctx context.Context, rpc RPCCaller) error {
+	var head *struct {
+		Hash common.Hash `json:"hash"`
+	}
+	number := hexutil.EncodeBig(new(big.Int).SetUint64(c.BlockNumber))
+	if err := rpc.CallContext(ctx, &head, "eth_getBlockByNumber", number, false); err != nil {
+		return fmt.Errorf("cursor: eth_getBlockByNumber: %v", err)
+	}
+	if head == nil {
+		return &ReorgError{Cursor: c}
+	}
+	if head.Hash != c.BlockHash {
+		return &ReorgError{Cursor: c, ChainHash: head.Hash}
+	}
+	return nil
+}
+
+// ErrNoCursor is returned by Store when the cursor wasn't saved yet.
+var ErrNoCursor = errors.New("cursor: no saved cursor")
+
+// Store persists the cursor.
+type Store interface {
+	Save(c Cursor) error
+	Load() (Cursor, error)
+}
@@ -0,0 +1,129 @@
+package cursor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestCursor_Compare(t *testing.T) {
+	tests := []struct {
+		a, b     Cursor
+		expected int
+	}{
+		{AtBlock(1, common.Hash{}), AtBlock(2, common.Hash{}), -1},
+		{AtBlock(2, common.Hash{}), AtBlock(2, common.HexToHash("0x1")), 0},
+		{Cursor{BlockNumber: 2, LogIndex: 5}, AtBlock(2, common.Hash{}), -1},
+		{Cursor{BlockNumber: 2, LogIndex: 5}, Cursor{BlockNumber: 2, LogIndex: 3}, 1},
+		{Cursor{BlockNumber: 3, LogIndex: 0}, AtBlock(2, common.Hash{}), 1},
+	}
+
+	for _, tt := range tests {
+		if got := tt.a.Compare(tt.b); got != tt.expected {
+			t.Errorf("%v compared to %v: expected %v, but got %v", tt.a, tt.b, tt.expected, got)
+		}
+	}
+}
+
+func TestCursor_Processed(t *testing.T) {
+	c := Cursor{BlockNumber: 10, LogIndex: 2}
+
+	tests := []struct {
+		log      types.Log
+		expected bool
+	}{
+		{types.Log{BlockNumber: 9, Index: 7}, true},
+		{types.Log{BlockNumber: 10, Index: 2}, true},
+		{types.Log{BlockNumber: 10, Index: 3}, false},
+		{types.Log{BlockNumber: 11, Index: 0}, false},
+	}
+
+	for _, tt := range tests {
+		if got := c.Processed(tt.log); got != tt.expected {
+			t.Errorf("log %v of block %v: expected %v, but got %v", tt.log.Index, tt.log.BlockNumber, tt.expected, got)
+		}
+	}
+
+	if (Cursor{}).Processed(types.Log{}) {
+		t.Errorf("expected no logs to be processed by zero cursor")
+	}
+}
+
+func TestCursor_ResumeBlock(t *testing.T) {
+	if n := AtBlock(10, common.Hash{}).ResumeBlock(); n.Int64() != 11 {
+		t.Errorf("expected to resume from block 11, but got %v", n)
+	}
+	if n := FromLog(types.Log{BlockNumber: 10, Index: 1}).ResumeBlock(); n.Int64() != 10 {
+		t.Errorf("expected to resume from block 10, but got %v", n)
+	}
+}
+
+// blockRPC returns blocks by number as a node does.
+type blockRPC map[string]json.RawMessage
+
+func (r blockRPC) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	if method != "eth_getBlockByNumber" {
+		return fmt.Errorf("unexpected method %v", method)
+	}
+	b, ok := r[args[0].(string)]
+	if !ok {
+		b = json.RawMessage("null")
+	}
+	return json.Unmarshal(b, result)
+}
+
+func TestCursor_Validate(t *testing.T) {
+	// London activation block, its hash can't be computed from the header fields known to go-ethereum 1.8
+	hash := common.HexToHash("0x9b83c12c69edb74f6c8dd5d052765c1adf940e320bd1291696e6fa07829eee71")
+	rpc := blockRPC{"0xc5d488": json.RawMessage(`{"number":"0xc5d488","hash":"` + hash.Hex() + `","baseFeePerGas":"0x3b9aca00"}`)}
+
+	if err := AtBlock(12965000, hash).Validate(context.Background(), rpc); err != nil {
+		t.Errorf("expected no error, but got %v", err)
+	}
+	if err, ok := AtBlock(12965000, common.HexToHash("0x1")).Validate(context.Background(), rpc).(*ReorgError); !ok || err.ChainHash != hash {
+		t.Errorf("expected ReorgError with chain hash, but got %v", err)
+	}
+	if _, ok := AtBlock(12965001, common.HexToHash("0x1")).Validate(context.Background(), rpc).(*ReorgError); !ok {
+		t.Errorf("expected ReorgError for missing block")
+	}
+}
+
+func TestStores(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cursor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	stores := map[string]Store{
+		"memory": NewMemoryStore(),
+		"file":   NewFileStore(filepath.Join(dir, "cursor.json")),
+	}
+
+	for name, s := range stores {
+		t.Run(name, func(t *testing.T) {
+			if _, err := s.Load(); err != ErrNoCursor {
+				t.Errorf("expected ErrNoCursor, but got %v", err)
+			}
+
+			c := Cursor{BlockNumber: 7, BlockHash: common.HexToHash("0x7"), LogIndex: 3}
+			if err := s.Save(c); err != nil {
+				t.Fatalf("Save: %v", err)
+			}
+			loaded, err := s.Load()
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if loaded != c {
+				t.Errorf("expected %v, but got %v", c, loaded)
+			}
+		})
+	}
+}
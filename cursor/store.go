@@ -0,0 +1,89 @@
+package cursor
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+)
+
+// MemoryStore keeps the cursor in memory. It's useful for tests.
+type MemoryStore struct {
+	mu sync.RWMutex
+	c  *Cursor
+}
+
+// NewMemoryStore creates an instance of MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+// Save implements Store interface.
+func (s *MemoryStore) Save(c Cursor) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.c = &c
+	return nil
+}
+
+// Load implements Store interface.
+func (s *MemoryStore) Load() (Cursor, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.c == nil {
+		return Cursor{}, ErrNoCursor
+	}
+	return *s.c, nil
+}
+
+// FileStore keeps the cursor in a JSON file.
+type FileStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileStore creates an instance of FileStore which keeps the cursor in the file at the given path.
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+// Save implements Store interface. Cursor is written to a temporary file first, so it's never left half-written.
+func (s *FileStore) Save(c Cursor) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("cursor: %v", err)
+	}
+
+	tmp := s.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, b, 0600); err != nil {
+		return fmt.Errorf("cursor: %v", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("cursor: %v", err)
+	}
+	return nil
+}
+
+// Load implements Store interface.
+func (s *FileStore) Load() (Cursor, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return Cursor{}, ErrNoCursor
+	}
+	if err != nil {
+		return Cursor{}, fmt.Errorf("cursor: %v", err)
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(b, &c); err != nil {
+		return Cursor{}, fmt.Errorf("cursor: %v: %v", s.path, err)
+	}
+	return c, nil
+}
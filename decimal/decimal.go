@@ -0,0 +1,223 @@
+// Package decimal implements exact decimal amounts of ethers and tokens: the raw integer amount in the smallest
+// units (e.g. wei) paired with the number of decimals and, optionally, the currency symbol.
+//
+//	a, err := decimal.Parse("12.345 MTH", 18)
+//	fmt.Println(a.Value) // 12345000000000000000
+//	fmt.Println(a)       // 12.345 MTH
+package decimal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// EtherDecimals is the number of decimals of ether.
+const EtherDecimals = 18
+
+var (
+	// ErrDecimalsMismatch is returned by arithmetic operations on amounts with different decimals.
+	ErrDecimalsMismatch = errors.New("decimal: decimals mismatch")
+	// ErrPrecisionLoss is returned when the amount can't be represented exactly with the given decimals.
+	ErrPrecisionLoss = errors.New("decimal: precision loss")
+)
+
+// Amount is the amount in the smallest units with decimals metadata. Value is never modified by methods,
+// they return new amounts.
+type Amount struct {
+	Value    *big.Int
+	Decimals uint8
+	Symbol   string // optional
+}
+
+// New creates an amount of value in the smallest units.
+func New(value *big.Int, decimals uint8, symbol string) Amount {
+	if value == nil {
+		value = new(big.Int)
+	}
+	return Amount{Value: value, Decimals: decimals, Symbol: symbol}
+}
+
+// Ether creates an amount of ethers from wei.
+func Ether(wei *big.Int) Amount {
+	return New(wei, EtherDecimals, "ETH")
+}
+
+// Parse parses the decimal string with optional symbol separated by space (e.g. "12.345 MTH" or "-0.5").
+// It returns ErrPrecisionLoss when the string has more fractional digits than decimals.
+func Parse(s string, decimals uint8) (Amount, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 || len(fields) > 2 {
+		return Amount{}, fmt.Errorf("decimal: invalid amount %q", s)
+	}
+
+	var symbol string
+	if len(fields) == 2 {
+		symbol = fields[1]
+	}
+
+	num := fields[0]
+	neg := strings.HasPrefix(num, "-")
+	num = strings.TrimPrefix(strings.TrimPrefix(num, "-"), "+")
+
+	intPart, fracPart := num, ""
+	if i := strings.IndexByte(num, '.'); i >= 0 {
+		intPart, fracPart = num[:i], num[i+1:]
+	}
+	if intPart == "" && fracPart == "" || !isDigits(intPart) || !isDigits(fracPart) {
+		return Amount{}, fmt.Errorf("decimal: invalid amount %q", s)
+	}
+
+	fracPart = strings.TrimRight(fracPart, "0")
+	if len(fracPart) > int(decimals) {
+		return Amount{}, fmt.Errorf("%v: %q has more than %v decimals", ErrPrecisionLoss, s, decimals)
+	}
+
+	value, ok := new(big.Int).SetString("0"+intPart+fracPart+strings.Repeat("0", int(decimals)-len(fracPart)), 10)
+	if !ok {
+		return Amount{}, fmt.Errorf("decimal: invalid amount %q", s)
+	}
+	if neg {
+		value.Neg(value)
+	}
+
+	return Amount{Value: value, Decimals: decimals, Symbol: symbol}, nil
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Text returns the decimal representation of the amount without trailing zeros and symbol, e.g. "12.345".
+func (a Amount) Text() string {
+	v := a.value()
+	s := new(big.Int).Abs(v).String()
+	if d := int(a.Decimals); d > 0 {
+		if len(s) <= d {
+			s = strings.Repeat("0", d-len(s)+1) + s
+		}
+		intPart, fracPart := s[:len(s)-d], strings.TrimRight(s[len(s)-d:], "0")
+		s = intPart
+		if fracPart != "" {
+			s += "." + fracPart
+		}
+	}
+	if v.Sign() < 0 {
+		s = "-" + s
+	}
+	return s
+}
+
+// String returns the decimal representation of the amount followed by the symbol, if any, e.g. "12.345 MTH".
+func (a Amount) String() string {
+	if a.Symbol == "" {
+		return a.Text()
+	}
+	return a.Text() + " " + a.Symbol
+}
+
+func (a Amount) value() *big.Int {
+	if a.Value == nil {
+		return new(big.Int)
+	}
+	return a.Value
+}
+
+// Add returns the sum of amounts. Amounts must have equal decimals.
+func (a Amount) Add(b Amount) (Amount, error) {
+	if a.Decimals != b.Decimals {
+		return Amount{}, ErrDecimalsMismatch
+	}
+	return a.with(new(big.Int).Add(a.value(), b.value())), nil
+}
+
+// Sub returns the difference of amounts. Amounts must have equal decimals.
+func (a Amount) Sub(b Amount) (Amount, error) {
+	if a.Decimals != b.Decimals {
+		return Amount{}, ErrDecimalsMismatch
+	}
+	return a.with(new(big.Int).Sub(a.value(), b.value())), nil
+}
+
+// Mul returns the amount multiplied by the integer.
+func (a Amount) Mul(n *big.Int) Amount {
+	return a.with(new(big.Int).Mul(a.value(), n))
+}
+
+// Cmp compares amounts and returns -1, 0 or +1 (see big.Int.Cmp). Amounts must have equal decimals.
+func (a Amount) Cmp(b Amount) (int, error) {
+	if a.Decimals != b.Decimals {
+		return 0, ErrDecimalsMismatch
+	}
+	return a.value().Cmp(b.value()), nil
+}
+
+// Sign returns -1, 0 or +1 depending on the sign of the amount.
+func (a Amount) Sign() int {
+	return a.value().Sign()
+}
+
+// Rescale returns the same amount with other decimals. It returns ErrPrecisionLoss when the amount
+// can't be represented exactly with fewer decimals.
+func (a Amount) Rescale(decimals uint8) (Amount, error) {
+	v := new(big.Int).Set(a.value())
+	if decimals >= a.Decimals {
+		v.Mul(v, pow10(decimals-a.Decimals))
+	} else {
+		var rem big.Int
+		v.QuoRem(v, pow10(a.Decimals-decimals), &rem)
+		if rem.Sign() != 0 {
+			return Amount{}, fmt.Errorf("%v: %v with %v decimals", ErrPrecisionLoss, a, decimals)
+		}
+	}
+	return Amount{Value: v, Decimals: decimals, Symbol: a.Symbol}, nil
+}
+
+func (a Amount) with(v *big.Int) Amount {
+	return Amount{Value: v, Decimals: a.Decimals, Symbol: a.Symbol}
+}
+
+func pow10(n uint8) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+type jsonAmount struct {
+	Value    string `json:"value"`
+	Decimals uint8  `json:"decimals"`
+	Symbol   string `json:"symbol,omitempty"`
+	Text     string `json:"text"`
+}
+
+// MarshalJSON marshals the amount as an object with the raw value as a decimal string, decimals, symbol and
+// the text representation, e.g. {"value":"12345","decimals":3,"symbol":"MTH","text":"12.345"}.
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonAmount{
+		Value:    a.value().String(),
+		Decimals: a.Decimals,
+		Symbol:   a.Symbol,
+		Text:     a.Text(),
+	})
+}
+
+// UnmarshalJSON unmarshals the amount marshaled by MarshalJSON. The text representation is ignored.
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	var dec jsonAmount
+	if err := json.Unmarshal(data, &dec); err != nil {
+		return err
+	}
+
+	v, ok := new(big.Int).SetString(dec.Value, 10)
+	if !ok {
+		return fmt.Errorf("decimal: invalid value %q", dec.Value)
+	}
+
+	*a = Amount{Value: v, Decimals: dec.Decimals, Symbol: dec.Symbol}
+	return nil
+}
@@ -0,0 +1,137 @@
+package decimal
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		s        string
+		decimals uint8
+		value    string
+		symbol   string
+		err      bool
+	}{
+		{s: "12.345 MTH", decimals: 18, value: "12345000000000000000", symbol: "MTH"},
+		{s: "1", decimals: 18, value: "1000000000000000000"},
+		{s: "0.000000000000000001", decimals: 18, value: "1"},
+		{s: "-0.5", decimals: 2, value: "-50"},
+		{s: ".5", decimals: 1, value: "5"},
+		{s: "5.", decimals: 0, value: "5"},
+		{s: "1.100", decimals: 1, value: "11"},
+		{s: "0", decimals: 0, value: "0"},
+		{s: "1.23", decimals: 1, err: true},
+		{s: "", decimals: 18, err: true},
+		{s: ".", decimals: 18, err: true},
+		{s: "1,5", decimals: 18, err: true},
+		{s: "1e18", decimals: 18, err: true},
+		{s: "1 ETH extra", decimals: 18, err: true},
+	}
+
+	for _, tt := range tests {
+		a, err := Parse(tt.s, tt.decimals)
+		if tt.err {
+			if err == nil {
+				t.Errorf("Parse(%q): expected error, but got %v", tt.s, a)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Parse(%q): unexpected error: %v", tt.s, err)
+			continue
+		}
+		if a.Value.String() != tt.value || a.Symbol != tt.symbol || a.Decimals != tt.decimals {
+			t.Errorf("Parse(%q): expected %v %v, but got %v %v", tt.s, tt.value, tt.symbol, a.Value, a.Symbol)
+		}
+	}
+}
+
+func TestAmount_String(t *testing.T) {
+	tests := []struct {
+		a        Amount
+		expected string
+	}{
+		{New(big.NewInt(12345), 3, "MTH"), "12.345 MTH"},
+		{New(big.NewInt(1), 18, ""), "0.000000000000000001"},
+		{New(big.NewInt(-1500), 3, ""), "-1.5"},
+		{New(big.NewInt(1000), 3, ""), "1"},
+		{New(big.NewInt(42), 0, ""), "42"},
+		{New(nil, 18, "ETH"), "0 ETH"},
+		{Ether(big.NewInt(2e18)), "2 ETH"},
+	}
+
+	for _, tt := range tests {
+		if s := tt.a.String(); s != tt.expected {
+			t.Errorf("expected %q, but got %q", tt.expected, s)
+		}
+	}
+}
+
+func TestAmount_Arithmetic(t *testing.T) {
+	a := New(big.NewInt(150), 2, "MTH")
+	b := New(big.NewInt(25), 2, "MTH")
+
+	sum, err := a.Add(b)
+	if err != nil || sum.String() != "1.75 MTH" {
+		t.Errorf("expected sum 1.75 MTH, but got %v (%v)", sum, err)
+	}
+	diff, err := b.Sub(a)
+	if err != nil || diff.String() != "-1.25 MTH" {
+		t.Errorf("expected difference -1.25 MTH, but got %v (%v)", diff, err)
+	}
+	if p := a.Mul(big.NewInt(3)); p.String() != "4.5 MTH" {
+		t.Errorf("expected product 4.5 MTH, but got %v", p)
+	}
+	if a.Value.Int64() != 150 {
+		t.Errorf("expected operand to be unchanged, but got %v", a.Value)
+	}
+	if c, err := a.Cmp(b); err != nil || c != 1 {
+		t.Errorf("expected 1, but got %v (%v)", c, err)
+	}
+
+	other := New(big.NewInt(1), 3, "")
+	if _, err := a.Add(other); err != ErrDecimalsMismatch {
+		t.Errorf("expected error %v, but got %v", ErrDecimalsMismatch, err)
+	}
+	if _, err := a.Cmp(other); err != ErrDecimalsMismatch {
+		t.Errorf("expected error %v, but got %v", ErrDecimalsMismatch, err)
+	}
+}
+
+func TestAmount_Rescale(t *testing.T) {
+	a := New(big.NewInt(150), 2, "")
+
+	r, err := a.Rescale(4)
+	if err != nil || r.Value.Int64() != 15000 || r.String() != "1.5" {
+		t.Errorf("expected 15000 with 4 decimals, but got %v (%v)", r.Value, err)
+	}
+	r, err = a.Rescale(1)
+	if err != nil || r.Value.Int64() != 15 {
+		t.Errorf("expected 15 with 1 decimal, but got %v (%v)", r.Value, err)
+	}
+	if _, err := a.Rescale(0); err == nil {
+		t.Error("expected precision loss error, but got nil")
+	}
+}
+
+func TestAmount_JSON(t *testing.T) {
+	a := New(big.NewInt(12345), 3, "MTH")
+
+	data, err := json.Marshal(a)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	if expected := `{"value":"12345","decimals":3,"symbol":"MTH","text":"12.345"}`; string(data) != expected {
+		t.Errorf("expected %v, but got %v", expected, string(data))
+	}
+
+	var b Amount
+	if err := json.Unmarshal(data, &b); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if c, err := a.Cmp(b); err != nil || c != 0 || b.Symbol != a.Symbol {
+		t.Errorf("expected %v, but got %v", a, b)
+	}
+}
@@ -0,0 +1,39 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// WaitDeployed waits until the contract creation transaction is successfully mined, verifies that the code exists
+// at the contract address and returns the address.
+func (e *Eth) WaitDeployed(ctx context.Context, tx *types.Transaction) (common.Address, error) {
+	if tx.To() != nil {
+		return common.Address{}, errors.New("tx is not contract creation")
+	}
+
+	tr, err := e.WaitForTxReceipt(ctx, tx.Hash())
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	if tr.ContractAddress == (common.Address{}) {
+		return common.Address{}, errors.New("zero address")
+	}
+
+	code, err := e.Backend.CodeAt(ctx, tr.ContractAddress, nil)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("backend CodeAt(%v): %v", tr.ContractAddress.Hex(), err)
+	}
+	if len(code) == 0 {
+		return common.Address{}, bind.ErrNoCodeAfterDeploy
+	}
+
+	e.Log("Contract deployed", "address", tr.ContractAddress.Hex())
+	return tr.ContractAddress, nil
+}
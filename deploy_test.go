@@ -0,0 +1,52 @@
+package ethereum
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/monetha/go-ethereum/backend"
+)
+
+// stopContractCode is creation code of the contract which has single STOP instruction as its code.
+var stopContractCode = hexutil.MustDecode("0x6001600c60003960016000f300")
+
+func TestEth_WaitDeployed(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	from := crypto.PubkeyToAddress(key.PublicKey)
+
+	sim := backend.NewSimulatedBackendExtended(core.GenesisAlloc{from: {Balance: ether}}, 10000000)
+	sim.Commit()
+
+	e := New(backend.NewAutoCommitBackend(sim), nil)
+	e.SuggestedGasPrice = big.NewInt(1)
+	s := e.NewSession(key)
+
+	ctx := context.TODO()
+	rawTx := types.NewContractCreation(0, new(big.Int), 100000, e.SuggestedGasPrice, stopContractCode)
+	tx, err := s.TransactOpts.Signer(types.HomesteadSigner{}, from, rawTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Backend.SendTransaction(ctx, tx); err != nil {
+		t.Fatal(err)
+	}
+
+	address, err := e.WaitDeployed(ctx, tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := crypto.CreateAddress(from, 0); address != expected {
+		t.Errorf("expected contract address %v, but got %v", expected.Hex(), address.Hex())
+	}
+
+	if _, err := e.WaitDeployed(ctx, types.NewTransaction(1, common.Address{}, nil, 21000, e.SuggestedGasPrice, nil)); err == nil {
+		t.Errorf("expected error for transaction which is not contract creation")
+	}
+}
@@ -0,0 +1,60 @@
+package ethereum
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ecies"
+)
+
+// Encrypt encrypts the message for the owner of the public key using ECIES over secp256k1
+// (the scheme of devp2p handshake: ECDH, NIST SP 800-56 concatenation KDF, AES-128-CTR and HMAC-SHA-256).
+// The result can be decrypted by Key.Decrypt of the owner.
+func Encrypt(pub *ecdsa.PublicKey, message []byte) ([]byte, error) {
+	ct, err := ecies.Encrypt(rand.Reader, ecies.ImportECDSAPublic(pub), message, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("ecies: encrypting message: %v", err)
+	}
+	return ct, nil
+}
+
+// PublicKeyFromString parses hex-string representation of public key, as returned by Key.PublicKeyString
+// (64 bytes without 0x04 prefix, the prefixed form is accepted too).
+func PublicKeyFromString(hexkey string) (*ecdsa.PublicKey, error) {
+	b, err := hex.DecodeString(hexkey)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 64 {
+		b = append([]byte{0x04}, b...)
+	}
+	return crypto.UnmarshalPubkey(b)
+}
+
+// Encrypt encrypts the message for the key itself (e.g. to store secrets only the key owner can read).
+func (k *Key) Encrypt(message []byte) ([]byte, error) {
+	return Encrypt(&k.PrivateKey.PublicKey, message)
+}
+
+// Decrypt decrypts the message encrypted for the public key of the key.
+func (k *Key) Decrypt(ciphertext []byte) ([]byte, error) {
+	m, err := ecies.ImportECDSA(k.PrivateKey).Decrypt(ciphertext, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("ecies: decrypting message: %v", err)
+	}
+	return m, nil
+}
+
+// SharedSecret derives 32 bytes secret shared with the owner of the public key using ECDH (X coordinate of the
+// shared point). Both sides get the same secret, e.g. a.SharedSecret(b.PublicKey) == b.SharedSecret(a.PublicKey).
+// The secret should be passed through KDF before it's used as a symmetric key.
+func (k *Key) SharedSecret(pub *ecdsa.PublicKey) ([]byte, error) {
+	secret, err := ecies.ImportECDSA(k.PrivateKey).GenerateShared(ecies.ImportECDSAPublic(pub), 32, 0)
+	if err != nil {
+		return nil, fmt.Errorf("ecies: generating shared secret: %v", err)
+	}
+	return secret, nil
+}
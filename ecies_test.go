@@ -0,0 +1,66 @@
+package ethereum
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestKey_EncryptDecrypt(t *testing.T) {
+	alice, err := NewKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob, err := NewKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secret := []byte("passport data key")
+
+	bobPub, err := PublicKeyFromString(bob.PublicKeyString())
+	if err != nil {
+		t.Fatalf("PublicKeyFromString: %v", err)
+	}
+	ct, err := Encrypt(bobPub, secret)
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+
+	m, err := bob.Decrypt(ct)
+	if err != nil {
+		t.Fatalf("Decrypt: %v", err)
+	}
+	if !bytes.Equal(m, secret) {
+		t.Errorf("expected message %q, but got %q", secret, m)
+	}
+
+	if _, err := alice.Decrypt(ct); err == nil {
+		t.Errorf("expected error when decrypting with other key")
+	}
+
+	ct, err = alice.Encrypt(secret)
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	if m, err := alice.Decrypt(ct); err != nil || !bytes.Equal(m, secret) {
+		t.Errorf("expected message %q, but got %q, %v", secret, m, err)
+	}
+}
+
+func TestKey_SharedSecret(t *testing.T) {
+	alice, _ := NewKey()
+	bob, _ := NewKey()
+
+	s1, err := alice.SharedSecret(&bob.PrivateKey.PublicKey)
+	if err != nil {
+		t.Fatalf("SharedSecret: %v", err)
+	}
+	s2, err := bob.SharedSecret(&alice.PrivateKey.PublicKey)
+	if err != nil {
+		t.Fatalf("SharedSecret: %v", err)
+	}
+
+	if len(s1) != 32 || !bytes.Equal(s1, s2) {
+		t.Errorf("expected equal 32 bytes secrets, but got %x and %x", s1, s2)
+	}
+}
@@ -0,0 +1,82 @@
+// Package eip712 contains helpers to hash and sign EIP-712 typed structured data.
+package eip712
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/monetha/go-ethereum"
+)
+
+var domainTypeHash = crypto.Keccak256Hash([]byte("EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)"))
+
+// Domain is the EIP-712 domain with name, version, chain ID and verifying contract.
+type Domain struct {
+	Name              string
+	Version           string
+	ChainID           *big.Int
+	VerifyingContract common.Address
+}
+
+// Separator returns the domain separator.
+func (d Domain) Separator() common.Hash {
+	return crypto.Keccak256Hash(
+		domainTypeHash[:],
+		crypto.Keccak256([]byte(d.Name)),
+		crypto.Keccak256([]byte(d.Version)),
+		Word(d.ChainID),
+		d.VerifyingContract.Hash().Bytes(),
+	)
+}
+
+// Hash returns the hash of the struct to be signed in the domain.
+func (d Domain) Hash(structHash common.Hash) common.Hash {
+	return Digest(d.Separator(), structHash)
+}
+
+// Digest returns the hash of the struct to be signed in the domain with the given separator.
+// It's useful when the separator is read from the contract (e.g. DOMAIN_SEPARATOR()).
+func Digest(domainSeparator, structHash common.Hash) common.Hash {
+	return crypto.Keccak256Hash([]byte{0x19, 0x01}, domainSeparator[:], structHash[:])
+}
+
+// Word returns ABI encoding of uint256 value, nil is encoded as zero.
+func Word(v *big.Int) []byte {
+	if v == nil {
+		return make([]byte, common.HashLength)
+	}
+	return common.LeftPadBytes(v.Bytes(), common.HashLength)
+}
+
+// Sign signs the hash with the key and returns 65 bytes signature [R || S || V], where V is 27 or 28,
+// as expected by ecrecover in contracts.
+func Sign(hash common.Hash, key *ethereum.Key) ([]byte, error) {
+	sig, err := crypto.Sign(hash[:], key.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("eip712: signing hash: %v", err)
+	}
+	sig[64] += 27
+	return sig, nil
+}
+
+// Recover returns the address of the account which made the signature of the hash.
+func Recover(hash common.Hash, sig []byte) (common.Address, error) {
+	if len(sig) != 65 {
+		return common.Address{}, fmt.Errorf("eip712: invalid signature length %v", len(sig))
+	}
+	if sig[64] != 27 && sig[64] != 28 {
+		return common.Address{}, fmt.Errorf("eip712: invalid signature V %v", sig[64])
+	}
+
+	recoverable := make([]byte, 65)
+	copy(recoverable, sig)
+	recoverable[64] -= 27
+
+	pub, err := crypto.SigToPub(hash[:], recoverable)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("eip712: recovering signer: %v", err)
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
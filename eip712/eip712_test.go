@@ -0,0 +1,59 @@
+package eip712
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/monetha/go-ethereum"
+)
+
+func TestSignRecover(t *testing.T) {
+	key, err := ethereum.NewKey()
+	if err != nil {
+		t.Fatalf("NewKey: %v", err)
+	}
+
+	domain := Domain{Name: "Test", Version: "1", ChainID: big.NewInt(1), VerifyingContract: common.HexToAddress("0x1234")}
+	hash := domain.Hash(common.HexToHash("0x5678"))
+
+	sig, err := Sign(hash, key)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+
+	signer, err := Recover(hash, sig)
+	if err != nil {
+		t.Fatalf("Recover: %v", err)
+	}
+	if signer != key.Address {
+		t.Errorf("expected signer %v, but got %v", key.Address.Hex(), signer.Hex())
+	}
+}
+
+func TestRecover_InvalidSignature(t *testing.T) {
+	tests := []struct {
+		name string
+		sig  []byte
+	}{
+		{"short", make([]byte, 64)},
+		{"invalid V", make([]byte, 65)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Recover(common.Hash{}, tt.sig); err == nil {
+				t.Errorf("expected error, but got nil")
+			}
+		})
+	}
+}
+
+func TestWord(t *testing.T) {
+	if w := Word(nil); len(w) != 32 || new(big.Int).SetBytes(w).Sign() != 0 {
+		t.Errorf("expected zero word, but got %x", w)
+	}
+	if w := Word(big.NewInt(258)); len(w) != 32 || w[30] != 1 || w[31] != 2 {
+		t.Errorf("expected 258 encoded as word, but got %x", w)
+	}
+}
@@ -3,6 +3,7 @@ package ethereum
 import (
 	"context"
 	"crypto/ecdsa"
+	"errors"
 	"fmt"
 	"math/big"
 	"time"
@@ -11,37 +12,280 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/monetha/go-ethereum/backend"
+	"github.com/monetha/go-ethereum/events"
+	"github.com/monetha/go-ethereum/gasestimator"
 	"github.com/monetha/go-ethereum/log"
+	"github.com/monetha/go-ethereum/tracing"
 )
 
 // Eth simplifies some operations with the Ethereum network
 type Eth struct {
-	Backend           backend.Backend
-	LogFun            log.Fun
+	Backend backend.Backend
+	LogFun  log.Fun
+	// SuggestedGasPrice is the gas price set once (e.g. by UpdateSuggestedGasPrice), it isn't refreshed when
+	// GasPriceEstimator is set.
+	//
+	// Deprecated: use GasPrice, which returns the live gas price of GasPriceEstimator.
 	SuggestedGasPrice *big.Int
+	// GasPriceEstimator is used instead of backend to suggest gas price (optional).
+	GasPriceEstimator GasPriceSuggester
+	// AutoGasPrice indicates that sessions don't fix gas price at creation time, instead the live gas price
+	// of GasPriceEstimator is used at the time of sending every transaction.
+	AutoGasPrice bool
+	// Confirmations is the number of blocks that must be mined on top of transaction block before
+	// WaitForTxReceipt returns receipt. Receipts don't contain block number, so the block number of their logs
+	// is used. Confirmations of transactions without logs are counted from the latest block at the time
+	// the receipt is seen, so they may be waited for longer.
+	Confirmations uint
+	// ChainID is used to sign transactions of sessions according to EIP-155 (optional).
+	ChainID *big.Int
+	// ReceiptsReader is used by WaitForTxReceipts to get many receipts at once (optional).
+	ReceiptsReader TransactionReceiptsReader
+	// GasStrategy decides on gas price of transactions given their urgency (optional).
+	GasStrategy gasestimator.GasStrategy
+	// Urgency is the urgency of transactions passed to GasStrategy.
+	Urgency gasestimator.Urgency
+	// DroppedTxTimeout is the duration after which WaitForTxReceipt returns ErrTxDropped if transaction is
+	// neither pending nor mined. Dropped transactions aren't detected when it's zero.
+	DroppedTxTimeout time.Duration
+	// Events receives TxMined and TxDropped events of transactions waited for (optional).
+	Events *events.Bus
+	// Finality decides whether the block of the transaction is final, WaitForTxReceipt waits for it instead of
+	// Confirmations (optional).
+	Finality FinalityChecker
+	// Heads provides the latest block number to wait for confirmations (optional), otherwise the latest header
+	// is requested from the backend.
+	Heads backend.HeadReader
 }
 
-// New creates new instance of Eth
-func New(b backend.Backend, lf log.Fun) *Eth {
-	return &Eth{
+// txPollInterval is the interval of polling transaction receipts.
+var txPollInterval = 4 * time.Second
+
+// GasPriceSuggester suggests gas price, it's implemented by gasestimator.GasPriceEstimator.
+type GasPriceSuggester interface {
+	SuggestGasPrice() *big.Int
+}
+
+// FinalityChecker tells whether the block is final, it's implemented by finality.Checker.
+type FinalityChecker interface {
+	IsFinal(ctx context.Context, block *big.Int) (bool, error)
+}
+
+// Option configures Eth.
+type Option func(*Eth)
+
+// WithLogger sets the logging function.
+func WithLogger(lf log.Fun) Option {
+	return func(e *Eth) {
+		e.LogFun = lf
+	}
+}
+
+// WithGasPriceEstimator sets the gas price estimator used instead of backend to suggest gas price.
+func WithGasPriceEstimator(est GasPriceSuggester) Option {
+	return func(e *Eth) {
+		e.GasPriceEstimator = est
+	}
+}
+
+// WithAutoGasPrice keeps gas price fresh automatically: GasPrice and Backend.SuggestGasPrice return the live gas
+// price of the estimator and sessions use it for every transaction. SuggestedGasPrice is only initialized from
+// the estimator and isn't refreshed, GasPrice must be used instead.
+func WithAutoGasPrice(est GasPriceSuggester) Option {
+	return func(e *Eth) {
+		e.GasPriceEstimator = est
+		e.AutoGasPrice = true
+		e.SuggestedGasPrice = est.SuggestGasPrice()
+		e.Backend = backend.NewGasPriceBackend(e.Backend, est.SuggestGasPrice)
+	}
+}
+
+// WithGasStrategy makes gas price pluggable: Backend.SuggestGasPrice returns the gas price of the strategy for
+// the given urgency, so sessions and transfers with nil gas price use it at the time of sending every transaction.
+func WithGasStrategy(s gasestimator.GasStrategy, urgency gasestimator.Urgency) Option {
+	return func(e *Eth) {
+		e.GasStrategy = s
+		e.Urgency = urgency
+		e.AutoGasPrice = true
+		e.Backend = backend.NewGasPriceContextBackend(e.Backend, func(ctx context.Context) (*big.Int, error) {
+			return s.GasPrice(ctx, urgency, 0)
+		})
+	}
+}
+
+// WithHandleNonce makes Eth to handle nonce of the given addresses internally (see NewHandleNonceBackend).
+func WithHandleNonce(handleAddresses ...common.Address) Option {
+	return func(e *Eth) {
+		e.Backend = backend.NewHandleNonceBackend(e.Backend, handleAddresses)
+	}
+}
+
+// WithConfirmations sets the number of confirmations WaitForTxReceipt waits for (see Eth.Confirmations).
+func WithConfirmations(confirmations uint) Option {
+	return func(e *Eth) {
+		e.Confirmations = confirmations
+	}
+}
+
+// WithReceiptsReader sets the reader used by WaitForTxReceipts to get many receipts at once (e.g. client.Client).
+func WithReceiptsReader(rr TransactionReceiptsReader) Option {
+	return func(e *Eth) {
+		e.ReceiptsReader = rr
+	}
+}
+
+// WithDroppedTxTimeout sets the duration after which WaitForTxReceipt returns ErrTxDropped for transaction
+// which is neither pending nor mined.
+func WithDroppedTxTimeout(d time.Duration) Option {
+	return func(e *Eth) {
+		e.DroppedTxTimeout = d
+	}
+}
+
+// WithDryRun makes Eth (and its sessions) to simulate and log transactions instead of sending them
+// (see backend.NewDryRunBackend).
+func WithDryRun() Option {
+	return func(e *Eth) {
+		e.Backend = backend.NewDryRunBackend(e.Backend, e.Log)
+	}
+}
+
+// WithExpectedChainID makes Eth to refuse sending transactions unless chain ID of the backend matches the given one
+// (see backend.NewChainIDGuardBackend). Backend must have ChainID method.
+func WithExpectedChainID(chainID *big.Int) Option {
+	return func(e *Eth) {
+		e.Backend = backend.NewChainIDGuardBackend(e.Backend, chainID, nil)
+	}
+}
+
+// WithPrivateRelay makes Eth (and its sessions) to send transactions via the private relay
+// (see backend.NewPrivateTxBackend).
+func WithPrivateRelay(relay *backend.Relay) Option {
+	return func(e *Eth) {
+		e.Backend = backend.NewPrivateTxBackend(e.Backend, relay)
+	}
+}
+
+// WithTracer makes Eth start spans of all backend calls with the given tracer
+// (see backend.NewTracingBackend).
+func WithTracer(tracer tracing.Tracer) Option {
+	return func(e *Eth) {
+		e.Backend = backend.NewTracingBackend(e.Backend, tracer)
+	}
+}
+
+// WithReceiptCache makes Eth cache receipts of transactions which have at least `finality` blocks on top of them,
+// so polling receipts doesn't hit the node every time (see backend.NewReceiptCacheBackend). The head reader set
+// with WithHeads before this option is used to get the latest block number.
+func WithReceiptCache(finality uint64, size int) Option {
+	return func(e *Eth) {
+		e.Backend = backend.NewHeadsReceiptCacheBackend(e.Backend, finality, size, e.Heads)
+	}
+}
+
+// WithFinality sets the checker (e.g. finality.Checker) which decides whether the block of the transaction is final,
+// so WaitForTxReceipt waits for it instead of Confirmations.
+func WithFinality(f FinalityChecker) Option {
+	return func(e *Eth) {
+		e.Finality = f
+	}
+}
+
+// WithHeads sets the head reader (e.g. headtracker.Tracker) which provides the latest block number to wait for
+// confirmations, instead of requesting the latest header from the backend every time.
+func WithHeads(heads backend.HeadReader) Option {
+	return func(e *Eth) {
+		e.Heads = heads
+	}
+}
+
+// WithEvents sets the bus which receives TxMined and TxDropped events.
+func WithEvents(bus *events.Bus) Option {
+	return func(e *Eth) {
+		e.Events = bus
+	}
+}
+
+// WithChainID sets the chain ID used to sign transactions of sessions.
+func WithChainID(chainID *big.Int) Option {
+	return func(e *Eth) {
+		e.ChainID = chainID
+	}
+}
+
+// NewEth creates new instance of Eth configured with the given options
+func NewEth(b backend.Backend, opts ...Option) *Eth {
+	e := &Eth{
 		Backend: b,
-		LogFun:  lf,
 	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
 }
 
-// NewSession creates an instance of Sessionclear
+// New creates new instance of Eth
+func New(b backend.Backend, lf log.Fun) *Eth {
+	return NewEth(b, WithLogger(lf))
+}
+
+// NewSession creates an instance of Sessionclear. Gas price of the session is the current gas price (see GasPrice),
+// or it's left nil when AutoGasPrice is set, so that live gas price is retrieved for every transaction.
 func (e *Eth) NewSession(key *ecdsa.PrivateKey) *Session {
 	transactOpts := bind.NewKeyedTransactor(key)
-	transactOpts.GasPrice = e.SuggestedGasPrice
+	if e.ChainID != nil {
+		transactOpts.Signer = newEIP155SignerFn(key, e.ChainID)
+	}
+	if !e.AutoGasPrice {
+		transactOpts.GasPrice = copyBigInt(e.GasPrice())
+	}
 	return &Session{
 		Eth:          e,
 		TransactOpts: *transactOpts,
 	}
 }
 
-// UpdateSuggestedGasPrice initializes suggested gas price from backend
+func newEIP155SignerFn(key *ecdsa.PrivateKey, chainID *big.Int) bind.SignerFn {
+	keyAddr := crypto.PubkeyToAddress(key.PublicKey)
+	signer := types.NewEIP155Signer(chainID)
+	return func(_ types.Signer, address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+		if address != keyAddr {
+			return nil, errors.New("not authorized to sign this account")
+		}
+		return types.SignTx(tx, signer, key)
+	}
+}
+
+// CheckChainID checks that chain ID of the backend matches the expected one (see WithExpectedChainID). It can be
+// called at startup to fail fast, otherwise the check is done before sending the first transaction.
+func (e *Eth) CheckChainID(ctx context.Context) error {
+	type chainIDChecker interface {
+		CheckChainID(ctx context.Context) error
+	}
+	if c, ok := e.Backend.(chainIDChecker); ok {
+		return c.CheckChainID(ctx)
+	}
+	return nil
+}
+
+// GasPrice returns the live gas price of GasPriceEstimator, or SuggestedGasPrice when estimator isn't set.
+func (e *Eth) GasPrice() *big.Int {
+	if e.GasPriceEstimator != nil {
+		return e.GasPriceEstimator.SuggestGasPrice()
+	}
+	return e.SuggestedGasPrice
+}
+
+// UpdateSuggestedGasPrice initializes suggested gas price from gas price estimator or backend. The value isn't
+// refreshed afterwards, use GasPrice to get the live gas price of GasPriceEstimator.
 func (e *Eth) UpdateSuggestedGasPrice(ctx context.Context) error {
+	if e.GasPriceEstimator != nil {
+		e.SuggestedGasPrice = e.GasPriceEstimator.SuggestGasPrice()
+		return nil
+	}
+
 	gasPrice, err := e.Backend.SuggestGasPrice(ctx)
 	if err != nil {
 		return err
@@ -64,6 +308,10 @@ func (e *Eth) NewHandleNonceBackend(handleAddresses []common.Address) *Eth {
 }
 
 // WaitForTxReceipt waits until the transaction is successfully mined. It returns error if receipt status is not equal to `types.ReceiptStatusSuccessful`.
+// When Confirmations is set and backend provides headers, it also waits until the required number of blocks is mined.
+// When Finality is set, it waits until the block of the transaction is final instead.
+// When DroppedTxTimeout is set, it returns ErrTxDropped if transaction is neither pending nor mined for that duration,
+// other errors of checking whether transaction is pending are retried.
 func (e *Eth) WaitForTxReceipt(ctx context.Context, txHash common.Hash) (tr *types.Receipt, err error) {
 	b := e.Backend
 
@@ -71,33 +319,168 @@ func (e *Eth) WaitForTxReceipt(ctx context.Context, txHash common.Hash) (tr *typ
 	e.Log("Waiting for transaction", "hash", txHashStr)
 
 	defer func() {
-		if err != nil {
+		switch {
+		case err == nil:
+			e.Events.Publish(events.TxMined{Hash: txHash, Receipt: tr})
+		case err == ErrTxDropped:
+			e.Events.Publish(events.TxDropped{Hash: txHash})
+		default:
 			err = fmt.Errorf("waiting for tx(%v): %v", txHashStr, err)
 		}
 	}()
 
-	type commiter interface {
-		Commit()
-	}
-	if sim, ok := b.(commiter); ok {
-		sim.Commit()
+	var missingSince time.Time // when transaction was found to be neither pending nor mined
+	for {
 		tr, err = e.onlySuccessfulReceipt(b.TransactionReceipt(ctx, txHash))
-		return
+		if err == ethereum.NotFound {
+			if e.DroppedTxTimeout != 0 {
+				_, _, err = b.TransactionByHash(ctx, txHash)
+				switch {
+				case err == ethereum.NotFound:
+					if missingSince.IsZero() {
+						missingSince = time.Now()
+					}
+					if time.Since(missingSince) >= e.DroppedTxTimeout {
+						e.Log("Transaction dropped", "hash", txHashStr)
+						return nil, ErrTxDropped
+					}
+				case err != nil:
+					// transient error (e.g. the node is unavailable), checked again on the next poll
+					e.Log("Can't check whether transaction is pending", "hash", txHashStr, "error", err)
+				default:
+					missingSince = time.Time{}
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(txPollInterval):
+			}
+			continue
+		}
+		if err != nil || !e.waitsForConfirmations() {
+			return
+		}
+
+		return e.waitForConfirmations(ctx, tr)
 	}
+}
+
+// waitForConfirmations waits until Confirmations blocks are mined on top of the block of the receipt, or until
+// the block is final according to Finality when it's set, then makes sure the receipt is still there (transaction
+// wasn't removed by chain reorganization). The latest block number is provided by Heads, or by the backend which
+// must implement HeaderByNumber method.
+func (e *Eth) waitForConfirmations(ctx context.Context, tr *types.Receipt) (*types.Receipt, error) {
+	heads := e.Heads
+	if heads == nil {
+		if hr, ok := e.Backend.(headerReader); ok {
+			heads = headerHeads{hr}
+		}
+	}
+
+	var err error
+	switch {
+	case e.Finality != nil:
+		err = e.waitForFinality(ctx, tr, heads)
+	case heads == nil:
+		return nil, errors.New("can't wait for confirmations: backend doesn't provide headers, see WithHeads")
+	default:
+		err = e.waitForBlocks(ctx, tr, heads)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return e.onlySuccessfulReceipt(e.Backend.TransactionReceipt(ctx, tr.TxHash))
+}
+
+// waitForBlocks waits until Confirmations blocks are mined on top of the block of the receipt.
+func (e *Eth) waitForBlocks(ctx context.Context, tr *types.Receipt, heads backend.HeadReader) error {
+	block, err := receiptBlock(ctx, tr, heads)
+	if err != nil {
+		return err
+	}
+	confirmedNumber := new(big.Int).Add(block, new(big.Int).SetUint64(uint64(e.Confirmations)))
+
+	e.Log("Waiting for confirmations", "tx_hash", tr.TxHash.Hex(), "block", block, "confirmations", e.Confirmations)
 
 	for {
+		head, err := heads.BlockNumber(ctx)
+		if err != nil {
+			return err
+		}
+		if head.Cmp(confirmedNumber) >= 0 {
+			return nil
+		}
+
 		select {
 		case <-ctx.Done():
-			return nil, ctx.Err()
-		case <-time.After(4 * time.Second):
+			return ctx.Err()
+		case <-time.After(txPollInterval):
 		}
+	}
+}
 
-		tr, err = e.onlySuccessfulReceipt(b.TransactionReceipt(ctx, txHash))
-		if err == ethereum.NotFound {
-			continue
+// waitForFinality waits until the block of the receipt is final according to Finality.
+func (e *Eth) waitForFinality(ctx context.Context, tr *types.Receipt, heads backend.HeadReader) error {
+	block, err := receiptBlock(ctx, tr, heads)
+	if err != nil {
+		return err
+	}
+
+	e.Log("Waiting for finality", "tx_hash", tr.TxHash.Hex(), "block", block)
+
+	for {
+		final, err := e.Finality.IsFinal(ctx, block)
+		if err != nil {
+			return err
 		}
-		return
+		if final {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(txPollInterval):
+		}
+	}
+}
+
+// receiptBlock returns the block number of the receipt. Receipts don't contain block number, so the block number
+// of the logs is used, or the latest block number if there are no logs (the block of the transaction or a later one).
+func receiptBlock(ctx context.Context, tr *types.Receipt, heads backend.HeadReader) (*big.Int, error) {
+	switch {
+	case len(tr.Logs) > 0:
+		return new(big.Int).SetUint64(tr.Logs[0].BlockNumber), nil
+	case heads != nil:
+		return heads.BlockNumber(ctx)
+	default:
+		return nil, errors.New("block number of the receipt is unknown")
+	}
+}
+
+// waitsForConfirmations tells whether mined transactions must be confirmed.
+func (e *Eth) waitsForConfirmations() bool {
+	return e.Confirmations > 0 || e.Finality != nil
+}
+
+type headerReader interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// headerHeads returns the number of the latest header.
+type headerHeads struct {
+	hr headerReader
+}
+
+func (h headerHeads) BlockNumber(ctx context.Context) (*big.Int, error) {
+	head, err := h.hr.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
 	}
+	return head.Number, nil
 }
 
 func (e *Eth) onlySuccessfulReceipt(tr *types.Receipt, err error) (*types.Receipt, error) {
@@ -126,11 +509,22 @@ type Session struct {
 }
 
 // IsEnoughFunds retrieves current account balance and checks if it's enough funds given gas limit.
-// SetGasPrice needs to be called with non-nil parameter before calling this method.
+// SetGasPrice needs to be called with non-nil parameter before calling this method (unless AutoGasPrice is set),
+// otherwise ErrNoGasPrice is returned.
 func (s *Session) IsEnoughFunds(ctx context.Context, gasLimit int64) (enough bool, minBalance *big.Int, err error) {
+	ctx = s.ctxOrDefault(ctx)
+
 	gasPrice := s.TransactOpts.GasPrice
+	if gasPrice == nil && s.AutoGasPrice {
+		gasPrice, err = s.Backend.SuggestGasPrice(ctx)
+		if err != nil {
+			err = fmt.Errorf("backend SuggestGasPrice: %v", err)
+			return
+		}
+	}
 	if gasPrice == nil {
-		panic("gas price must be non nil")
+		err = ErrNoGasPrice
+		return
 	}
 
 	minBalance = new(big.Int).Mul(big.NewInt(gasLimit), gasPrice)
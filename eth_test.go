@@ -0,0 +1,242 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/monetha/go-ethereum/backend"
+	"github.com/monetha/go-ethereum/events"
+)
+
+type constGasPrice int64
+
+func (p constGasPrice) SuggestGasPrice() *big.Int { return big.NewInt(int64(p)) }
+
+func TestNewEth(t *testing.T) {
+	logged := false
+	e := NewEth(nil,
+		WithLogger(func(msg string, ctx ...interface{}) { logged = true }),
+		WithConfirmations(12),
+		WithChainID(big.NewInt(3)),
+		WithGasPriceEstimator(constGasPrice(5)),
+		WithHandleNonce(common.HexToAddress("0x1111111111111111111111111111111111111111")),
+	)
+
+	e.Log("test")
+	if !logged {
+		t.Errorf("expected logger to be called")
+	}
+	if e.Confirmations != 12 {
+		t.Errorf("expected 12 confirmations, but got %v", e.Confirmations)
+	}
+	if e.ChainID == nil || e.ChainID.Int64() != 3 {
+		t.Errorf("expected chain ID 3, but got %v", e.ChainID)
+	}
+	if _, ok := e.Backend.(*backend.HandleNonceBackend); !ok {
+		t.Errorf("expected backend handling nonce, but got %T", e.Backend)
+	}
+
+	if err := e.UpdateSuggestedGasPrice(context.Background()); err != nil {
+		t.Fatalf("UpdateSuggestedGasPrice: %v", err)
+	}
+	if e.SuggestedGasPrice == nil || e.SuggestedGasPrice.Int64() != 5 {
+		t.Errorf("expected suggested gas price 5, but got %v", e.SuggestedGasPrice)
+	}
+}
+
+type varGasPrice struct{ price *big.Int }
+
+func (p *varGasPrice) SuggestGasPrice() *big.Int { return p.price }
+
+func TestWithAutoGasPrice(t *testing.T) {
+	est := &varGasPrice{price: big.NewInt(1)}
+	e := NewEth(nil, WithAutoGasPrice(est))
+
+	if e.SuggestedGasPrice.Int64() != 1 {
+		t.Errorf("expected suggested gas price 1, but got %v", e.SuggestedGasPrice)
+	}
+
+	est.price = big.NewInt(2)
+
+	if gasPrice := e.GasPrice(); gasPrice.Int64() != 2 {
+		t.Errorf("expected live gas price 2, but got %v", gasPrice)
+	}
+
+	gasPrice, err := e.Backend.SuggestGasPrice(context.Background())
+	if err != nil {
+		t.Fatalf("SuggestGasPrice: %v", err)
+	}
+	if gasPrice.Int64() != 2 {
+		t.Errorf("expected backend to suggest live gas price 2, but got %v", gasPrice)
+	}
+}
+
+type txPoolBackend struct {
+	backend.Backend
+	inPool   bool
+	failures int // number of TransactionByHash calls failing with transient error
+}
+
+func (b *txPoolBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	return nil, ethereum.NotFound
+}
+
+func (b *txPoolBackend) TransactionByHash(ctx context.Context, txHash common.Hash) (*types.Transaction, bool, error) {
+	if b.failures > 0 {
+		b.failures--
+		return nil, false, errors.New("connection refused")
+	}
+	if b.inPool {
+		return &types.Transaction{}, true, nil
+	}
+	return nil, false, ethereum.NotFound
+}
+
+func TestEth_WaitForTxReceipt_Dropped(t *testing.T) {
+	defer func(d time.Duration) { txPollInterval = d }(txPollInterval)
+	txPollInterval = time.Millisecond
+
+	t.Run("dropped transaction", func(t *testing.T) {
+		bus := events.NewBus()
+		sub := bus.Subscribe(1)
+		e := NewEth(&txPoolBackend{}, WithDroppedTxTimeout(10*time.Millisecond), WithEvents(bus))
+		_, err := e.WaitForTxReceipt(context.Background(), common.HexToHash("0x1"))
+		if err != ErrTxDropped {
+			t.Errorf("expected ErrTxDropped, but got %v", err)
+		}
+		select {
+		case ev := <-sub.C:
+			if dropped, ok := ev.(events.TxDropped); !ok || dropped.Hash != common.HexToHash("0x1") {
+				t.Errorf("expected TxDropped event, but got %+v", ev)
+			}
+		default:
+			t.Errorf("expected TxDropped event")
+		}
+	})
+
+	t.Run("transient errors", func(t *testing.T) {
+		e := NewEth(&txPoolBackend{failures: 3}, WithDroppedTxTimeout(10*time.Millisecond))
+		if _, err := e.WaitForTxReceipt(context.Background(), common.HexToHash("0x1")); err != ErrTxDropped {
+			t.Errorf("expected ErrTxDropped after transient errors, but got %v", err)
+		}
+	})
+
+	t.Run("pending transaction", func(t *testing.T) {
+		e := NewEth(&txPoolBackend{inPool: true}, WithDroppedTxTimeout(10*time.Millisecond))
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_, err := e.WaitForTxReceipt(ctx, common.Hash{})
+		if err == nil || err == ErrTxDropped {
+			t.Errorf("expected context error, but got %v", err)
+		}
+	})
+}
+
+type receiptBackend struct {
+	backend.Backend
+	receipt *types.Receipt
+}
+
+func (b *receiptBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	return b.receipt, nil
+}
+
+func TestEth_WaitForTxReceipt_NoHeaders(t *testing.T) {
+	receipt := &types.Receipt{Status: types.ReceiptStatusSuccessful}
+	for _, b := range []backend.Backend{
+		&receiptBackend{receipt: receipt},
+		backend.NewHandleNonceBackend(&receiptBackend{receipt: receipt}, nil),
+	} {
+		e := NewEth(b, WithConfirmations(2))
+		if _, err := e.WaitForTxReceipt(context.Background(), common.HexToHash("0x1")); err == nil {
+			t.Errorf("%T: expected error when confirmations can't be waited for", b)
+		}
+	}
+}
+
+type constHeads int64
+
+func (h constHeads) BlockNumber(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(int64(h)), nil
+}
+
+func TestEth_WaitForTxReceipt_Confirmations(t *testing.T) {
+	defer func(d time.Duration) { txPollInterval = d }(txPollInterval)
+	txPollInterval = time.Millisecond
+
+	tests := []struct {
+		name      string
+		logs      []*types.Log
+		heads     constHeads
+		confirmed bool
+	}{
+		{"confirmed since block of logs", []*types.Log{{BlockNumber: 5}}, 7, true},
+		{"not confirmed since block of logs", []*types.Log{{BlockNumber: 5}}, 6, false},
+		{"no logs", nil, 7, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receipt := &types.Receipt{Status: types.ReceiptStatusSuccessful, Logs: tt.logs}
+			e := NewEth(&receiptBackend{receipt: receipt}, WithConfirmations(2), WithHeads(tt.heads))
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			_, err := e.WaitForTxReceipt(ctx, common.HexToHash("0x1"))
+			if confirmed := err == nil; confirmed != tt.confirmed {
+				t.Errorf("expected confirmed %v, but got error %v", tt.confirmed, err)
+			}
+		})
+	}
+}
+
+// finalAfter considers blocks final after the given number of checks.
+type finalAfter struct {
+	checks int
+	blocks []*big.Int
+}
+
+func (f *finalAfter) IsFinal(ctx context.Context, block *big.Int) (bool, error) {
+	f.blocks = append(f.blocks, block)
+	return len(f.blocks) > f.checks, nil
+}
+
+func TestEth_WaitForTxReceipt_Finality(t *testing.T) {
+	defer func(d time.Duration) { txPollInterval = d }(txPollInterval)
+	txPollInterval = time.Millisecond
+
+	receipt := &types.Receipt{Status: types.ReceiptStatusSuccessful, Logs: []*types.Log{{BlockNumber: 5}}}
+	f := &finalAfter{checks: 2}
+	e := NewEth(&receiptBackend{receipt: receipt}, WithFinality(f))
+
+	tr, err := e.WaitForTxReceipt(context.Background(), common.HexToHash("0x1"))
+	if err != nil {
+		t.Fatalf("WaitForTxReceipt: %v", err)
+	}
+	if tr != receipt {
+		t.Errorf("expected receipt %+v, but got %+v", receipt, tr)
+	}
+	if len(f.blocks) != 3 {
+		t.Errorf("expected finality to be checked 3 times, but got %v", len(f.blocks))
+	}
+	for _, b := range f.blocks {
+		if b.Uint64() != 5 {
+			t.Errorf("expected finality of block 5 to be checked, but got %v", b)
+		}
+	}
+}
+
+func TestSession_IsEnoughFunds_NoGasPrice(t *testing.T) {
+	s := &Session{Eth: NewEth(nil)}
+
+	if _, _, err := s.IsEnoughFunds(context.Background(), 21000); err != ErrNoGasPrice {
+		t.Errorf("expected ErrNoGasPrice, but got %v", err)
+	}
+}
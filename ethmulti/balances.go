@@ -0,0 +1,204 @@
+package ethmulti
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+
+	eth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/monetha/go-ethereum"
+	"github.com/monetha/go-ethereum/decimal"
+)
+
+// DefaultSnapshotConcurrency is the number of balance requests SnapshotBalances makes concurrently on each chain
+// by default.
+const DefaultSnapshotConcurrency = 8
+
+// balanceOfSelector is the selector of ERC-20 balanceOf(address) method.
+var balanceOfSelector = []byte{0x70, 0xa0, 0x82, 0x31}
+
+// Token is ERC-20 token deployed on the chain.
+type Token struct {
+	ChainID  *big.Int
+	Address  common.Address
+	Symbol   string // optional, for reporting
+	Decimals uint8  // for reporting
+}
+
+// Balance is the balance of the account on the chain.
+type Balance struct {
+	ChainID *big.Int
+	Account common.Address
+	Token   *Token   // nil for native currency
+	Amount  *big.Int // in wei or the smallest token units
+}
+
+// Decimal returns the amount with decimals and symbol of the token. Native currency has 18 decimals and no symbol,
+// as it differs between chains.
+func (b *Balance) Decimal() decimal.Amount {
+	if b.Token == nil {
+		return decimal.New(b.Amount, decimal.EtherDecimals, "")
+	}
+	return decimal.New(b.Amount, b.Token.Decimals, b.Token.Symbol)
+}
+
+// BalanceReport holds balances gathered across chains.
+type BalanceReport struct {
+	// Balances are ordered by chain ID, account, and token (native currency first).
+	Balances []*Balance
+	// Errors holds errors of the chains whose balances couldn't be gathered (all or some of them). Errors of failed
+	// requests on the chain are reported as RequestErrors.
+	Errors Errors
+}
+
+// RequestErrors holds errors of failed balance requests on the chain.
+type RequestErrors []error
+
+func (e RequestErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("%v requests failed: %v", len(e), strings.Join(msgs, "; "))
+}
+
+type snapshotConfig struct {
+	concurrency int
+}
+
+// SnapshotOption configures SnapshotBalances.
+type SnapshotOption func(*snapshotConfig)
+
+// WithConcurrency sets the number of balance requests made concurrently on each chain
+// (DefaultSnapshotConcurrency by default).
+func WithConcurrency(n int) SnapshotOption {
+	return func(c *snapshotConfig) {
+		if n > 0 {
+			c.concurrency = n
+		}
+	}
+}
+
+// SnapshotBalances concurrently gathers native balances of the addresses on all chains, and balances of the tokens
+// on their chains. Tokens of unknown chains are reported as errors. Balances of failed requests are omitted
+// from the report, so the report is partial when it has errors. Requests on each chain are limited
+// by WithConcurrency.
+func (m *Manager) SnapshotBalances(ctx context.Context, addresses []common.Address, tokens []*Token, opts ...SnapshotOption) *BalanceReport {
+	cfg := snapshotConfig{concurrency: DefaultSnapshotConcurrency}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	tokensByChain := make(map[string][]*Token)
+	report := &BalanceReport{Errors: make(Errors)}
+	for _, t := range tokens {
+		if _, err := m.Eth(t.ChainID); err != nil {
+			report.Errors[t.ChainID.String()] = err
+			continue
+		}
+		tokensByChain[t.ChainID.String()] = append(tokensByChain[t.ChainID.String()], t)
+	}
+
+	var mu sync.Mutex
+	err := m.ForEach(ctx, func(ctx context.Context, chainID *big.Int, e *ethereum.Eth) error {
+		var (
+			wg       sync.WaitGroup
+			chainMu  sync.Mutex
+			balances []*Balance
+			errs     RequestErrors
+			sem      = make(chan struct{}, cfg.concurrency)
+		)
+		run := func(fn func() (*Balance, error)) {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func() {
+				defer wg.Done()
+				defer func() { <-sem }()
+
+				b, err := fn()
+				chainMu.Lock()
+				defer chainMu.Unlock()
+				if err != nil {
+					errs = append(errs, err)
+					return
+				}
+				balances = append(balances, b)
+			}()
+		}
+
+		for _, account := range addresses {
+			account := account
+			run(func() (*Balance, error) {
+				amount, err := e.Backend.BalanceAt(ctx, account, nil)
+				if err != nil {
+					return nil, fmt.Errorf("BalanceAt(%v): %v", account.Hex(), err)
+				}
+				return &Balance{ChainID: chainID, Account: account, Amount: amount}, nil
+			})
+
+			for _, t := range tokensByChain[chainID.String()] {
+				t := t
+				run(func() (*Balance, error) {
+					amount, err := tokenBalance(ctx, e, t.Address, account)
+					if err != nil {
+						return nil, fmt.Errorf("token %v balanceOf(%v): %v", t.Address.Hex(), account.Hex(), err)
+					}
+					return &Balance{ChainID: chainID, Account: account, Token: t, Amount: amount}, nil
+				})
+			}
+		}
+		wg.Wait()
+
+		mu.Lock()
+		report.Balances = append(report.Balances, balances...)
+		mu.Unlock()
+
+		if len(errs) > 0 {
+			return errs
+		}
+		return nil
+	})
+	if errs, ok := err.(Errors); ok {
+		for id, err := range errs {
+			report.Errors[id] = err
+		}
+	}
+
+	sortBalances(report.Balances)
+	if len(report.Errors) == 0 {
+		report.Errors = nil
+	}
+	return report
+}
+
+func tokenBalance(ctx context.Context, e *ethereum.Eth, token, account common.Address) (*big.Int, error) {
+	data := append(append([]byte(nil), balanceOfSelector...), account.Hash().Bytes()...)
+	out, err := e.Backend.CallContract(ctx, eth.CallMsg{To: &token, Data: data}, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(out) < common.HashLength {
+		return nil, fmt.Errorf("unexpected result length %v", len(out))
+	}
+	return new(big.Int).SetBytes(out[:common.HashLength]), nil
+}
+
+func sortBalances(balances []*Balance) {
+	sort.Slice(balances, func(i, j int) bool {
+		a, b := balances[i], balances[j]
+		if c := a.ChainID.Cmp(b.ChainID); c != 0 {
+			return c < 0
+		}
+		if a.Account != b.Account {
+			return a.Account.Hex() < b.Account.Hex()
+		}
+		if a.Token == nil || b.Token == nil {
+			return a.Token == nil && b.Token != nil
+		}
+		return a.Token.Address.Hex() < b.Token.Address.Hex()
+	})
+}
@@ -0,0 +1,138 @@
+package ethmulti
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	eth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/monetha/go-ethereum"
+	"github.com/monetha/go-ethereum/backend"
+)
+
+type balanceBackend struct {
+	backend.Backend
+	native *big.Int
+	token  *big.Int
+	err    error
+}
+
+func (b *balanceBackend) BalanceAt(ctx context.Context, address common.Address, blockNum *big.Int) (*big.Int, error) {
+	return b.native, b.err
+}
+
+func (b *balanceBackend) CallContract(ctx context.Context, call eth.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	return common.LeftPadBytes(b.token.Bytes(), 32), nil
+}
+
+func TestManager_SnapshotBalances(t *testing.T) {
+	m := New()
+	chains := map[int64]*balanceBackend{
+		1: {native: big.NewInt(100), token: big.NewInt(5)},
+		2: {native: big.NewInt(200), token: big.NewInt(7)},
+		3: {err: errors.New("node is down")},
+	}
+	for id, b := range chains {
+		if err := m.Add(big.NewInt(id), ethereum.NewEth(b)); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+	}
+
+	account := common.HexToAddress("0x01")
+	tokens := []*Token{
+		{ChainID: big.NewInt(2), Address: common.HexToAddress("0xaa"), Symbol: "USDT"},
+		{ChainID: big.NewInt(4), Address: common.HexToAddress("0xbb"), Symbol: "DAI"},
+	}
+
+	report := m.SnapshotBalances(context.Background(), []common.Address{account}, tokens)
+
+	expected := []struct {
+		chainID int64
+		token   bool
+		amount  int64
+	}{
+		{1, false, 100},
+		{2, false, 200},
+		{2, true, 7},
+	}
+	if len(report.Balances) != len(expected) {
+		t.Fatalf("expected %v balances, but got %v", len(expected), len(report.Balances))
+	}
+	for i, e := range expected {
+		b := report.Balances[i]
+		if b.ChainID.Int64() != e.chainID || (b.Token != nil) != e.token || b.Amount.Int64() != e.amount {
+			t.Errorf("balance %v: expected %+v, but got chain %v, token %v, amount %v", i, e, b.ChainID, b.Token, b.Amount)
+		}
+	}
+
+	if len(report.Errors) != 2 || report.Errors["3"] == nil || report.Errors["4"] != ErrUnknownChain {
+		t.Errorf("expected errors of chains 3 and 4, but got %v", report.Errors)
+	}
+}
+
+// concurrencyBackend fails all balance requests and records the maximum number of concurrent ones.
+type concurrencyBackend struct {
+	backend.Backend
+	mu       sync.Mutex
+	inFlight int
+	max      int
+}
+
+func (b *concurrencyBackend) BalanceAt(ctx context.Context, address common.Address, blockNum *big.Int) (*big.Int, error) {
+	b.mu.Lock()
+	b.inFlight++
+	if b.inFlight > b.max {
+		b.max = b.inFlight
+	}
+	b.mu.Unlock()
+
+	time.Sleep(time.Millisecond)
+
+	b.mu.Lock()
+	b.inFlight--
+	b.mu.Unlock()
+	return nil, errors.New("node is down")
+}
+
+func TestManager_SnapshotBalances_Concurrency(t *testing.T) {
+	b := &concurrencyBackend{}
+	m := New()
+	if err := m.Add(big.NewInt(1), ethereum.NewEth(b)); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	addresses := make([]common.Address, 10)
+	for i := range addresses {
+		addresses[i] = common.BigToAddress(big.NewInt(int64(i + 1)))
+	}
+
+	report := m.SnapshotBalances(context.Background(), addresses, nil, WithConcurrency(2))
+	if b.max > 2 {
+		t.Errorf("expected at most 2 concurrent requests, but got %v", b.max)
+	}
+	if errs, ok := report.Errors["1"].(RequestErrors); !ok || len(errs) != len(addresses) {
+		t.Errorf("expected errors of all %v requests, but got %v", len(addresses), report.Errors["1"])
+	}
+}
+
+func TestBalance_Decimal(t *testing.T) {
+	token := &Token{ChainID: big.NewInt(1), Symbol: "MTH", Decimals: 5}
+
+	tests := []struct {
+		balance  *Balance
+		expected string
+	}{
+		{&Balance{Amount: big.NewInt(1500000000000000000)}, "1.5"},
+		{&Balance{Token: token, Amount: big.NewInt(123450)}, "1.2345 MTH"},
+	}
+
+	for _, tt := range tests {
+		if s := tt.balance.Decimal().String(); s != tt.expected {
+			t.Errorf("expected %q, but got %q", tt.expected, s)
+		}
+	}
+}
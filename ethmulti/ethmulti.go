@@ -0,0 +1,147 @@
+// Package ethmulti manages Eth instances of several chains, so cross-chain services configure them once
+// and route operations by chain ID.
+package ethmulti
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/monetha/go-ethereum"
+)
+
+// ErrUnknownChain is returned when there is no Eth for the chain ID.
+var ErrUnknownChain = errors.New("ethmulti: unknown chain")
+
+// Errors holds errors of operations on several chains by chain ID.
+type Errors map[string]error
+
+func (e Errors) Error() string {
+	ids := make([]string, 0, len(e))
+	for id := range e {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	msgs := make([]string, len(ids))
+	for i, id := range ids {
+		msgs[i] = fmt.Sprintf("chain %v: %v", id, e[id])
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Manager holds Eth instances by chain ID. Each instance has its own backend, gas price estimator and
+// nonce handling. It's safe for concurrent use.
+type Manager struct {
+	mu     sync.RWMutex
+	chains map[string]*ethereum.Eth
+}
+
+// New creates an instance of Manager.
+func New() *Manager {
+	return &Manager{chains: make(map[string]*ethereum.Eth)}
+}
+
+// Add adds Eth of the chain. ChainID of Eth is set to the chain ID if it's not set yet, so that sessions sign
+// transactions for the chain (EIP-155).
+func (m *Manager) Add(chainID *big.Int, e *ethereum.Eth) error {
+	if chainID == nil {
+		return errors.New("ethmulti: chain ID must be set")
+	}
+	if e.ChainID != nil && e.ChainID.Cmp(chainID) != 0 {
+		return fmt.Errorf("ethmulti: Eth is configured for chain %v, not %v", e.ChainID, chainID)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := chainID.String()
+	if _, ok := m.chains[key]; ok {
+		return fmt.Errorf("ethmulti: chain %v is already added", chainID)
+	}
+	if e.ChainID == nil {
+		e.ChainID = new(big.Int).Set(chainID)
+	}
+	m.chains[key] = e
+	return nil
+}
+
+// Remove removes Eth of the chain.
+func (m *Manager) Remove(chainID *big.Int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.chains, chainID.String())
+}
+
+// Eth returns Eth of the chain, or ErrUnknownChain.
+func (m *Manager) Eth(chainID *big.Int) (*ethereum.Eth, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	e, ok := m.chains[chainID.String()]
+	if !ok {
+		return nil, ErrUnknownChain
+	}
+	return e, nil
+}
+
+// NewSession creates session of the chain for the key.
+func (m *Manager) NewSession(chainID *big.Int, key *ecdsa.PrivateKey) (*ethereum.Session, error) {
+	e, err := m.Eth(chainID)
+	if err != nil {
+		return nil, err
+	}
+	return e.NewSession(key), nil
+}
+
+// ChainIDs returns IDs of all chains in ascending order.
+func (m *Manager) ChainIDs() []*big.Int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	ids := make([]*big.Int, 0, len(m.chains))
+	for _, e := range m.chains {
+		ids = append(ids, new(big.Int).Set(e.ChainID))
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i].Cmp(ids[j]) < 0 })
+	return ids
+}
+
+// ForEach calls fn for every chain concurrently and waits for all calls to finish. It returns Errors
+// with errors of failed chains, or nil when all calls succeed.
+func (m *Manager) ForEach(ctx context.Context, fn func(ctx context.Context, chainID *big.Int, e *ethereum.Eth) error) error {
+	ids := m.ChainIDs()
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs = make(Errors)
+	)
+	for _, id := range ids {
+		e, err := m.Eth(id)
+		if err != nil {
+			continue // removed concurrently
+		}
+
+		wg.Add(1)
+		go func(id *big.Int, e *ethereum.Eth) {
+			defer wg.Done()
+			if err := fn(ctx, id, e); err != nil {
+				mu.Lock()
+				errs[id.String()] = err
+				mu.Unlock()
+			}
+		}(id, e)
+	}
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package ethmulti
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync/atomic"
+	"testing"
+
+	"github.com/monetha/go-ethereum"
+)
+
+func TestManager(t *testing.T) {
+	m := New()
+
+	for _, id := range []int64{137, 1, 56} {
+		if err := m.Add(big.NewInt(id), &ethereum.Eth{}); err != nil {
+			t.Fatalf("Add(%v): %v", id, err)
+		}
+	}
+	if err := m.Add(big.NewInt(1), &ethereum.Eth{}); err == nil {
+		t.Errorf("expected error when adding chain twice")
+	}
+	if err := m.Add(big.NewInt(10), &ethereum.Eth{ChainID: big.NewInt(11)}); err == nil {
+		t.Errorf("expected error when Eth is configured for other chain")
+	}
+
+	ids := m.ChainIDs()
+	if len(ids) != 3 || ids[0].Int64() != 1 || ids[1].Int64() != 56 || ids[2].Int64() != 137 {
+		t.Errorf("expected chain IDs [1 56 137], but got %v", ids)
+	}
+
+	e, err := m.Eth(big.NewInt(56))
+	if err != nil {
+		t.Fatalf("Eth: %v", err)
+	}
+	if e.ChainID.Int64() != 56 {
+		t.Errorf("expected chain ID of Eth to be set to 56, but got %v", e.ChainID)
+	}
+
+	m.Remove(big.NewInt(56))
+	if _, err := m.Eth(big.NewInt(56)); err != ErrUnknownChain {
+		t.Errorf("expected ErrUnknownChain, but got %v", err)
+	}
+}
+
+func TestManager_ForEach(t *testing.T) {
+	m := New()
+	for _, id := range []int64{1, 2, 3} {
+		if err := m.Add(big.NewInt(id), &ethereum.Eth{}); err != nil {
+			t.Fatalf("Add(%v): %v", id, err)
+		}
+	}
+
+	var calls int32
+	err := m.ForEach(context.Background(), func(ctx context.Context, chainID *big.Int, e *ethereum.Eth) error {
+		atomic.AddInt32(&calls, 1)
+		if chainID.Int64() == 2 {
+			return errors.New("failed")
+		}
+		return nil
+	})
+
+	if calls != 3 {
+		t.Errorf("expected 3 calls, but got %v", calls)
+	}
+	errs, ok := err.(Errors)
+	if !ok || len(errs) != 1 || errs["2"] == nil {
+		t.Errorf("expected error of chain 2, but got %v", err)
+	}
+}
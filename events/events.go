@@ -0,0 +1,185 @@
+// Package events provides the bus of typed events published by components (BlockSource, gas price estimator,
+// Eth waiting for transactions), so that consumers subscribe only to the kinds of events they need instead of
+// reading channels of every component.
+package events
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Kind is the kind of the event.
+type Kind int
+
+const (
+	// KindNewBlock is the kind of NewBlock events.
+	KindNewBlock Kind = iota
+	// KindReorgDetected is the kind of ReorgDetected events.
+	KindReorgDetected
+	// KindTxMined is the kind of TxMined events.
+	KindTxMined
+	// KindTxDropped is the kind of TxDropped events.
+	KindTxDropped
+	// KindGasSpike is the kind of GasSpike events.
+	KindGasSpike
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindNewBlock:
+		return "new_block"
+	case KindReorgDetected:
+		return "reorg_detected"
+	case KindTxMined:
+		return "tx_mined"
+	case KindTxDropped:
+		return "tx_dropped"
+	case KindGasSpike:
+		return "gas_spike"
+	default:
+		return fmt.Sprintf("kind(%d)", int(k))
+	}
+}
+
+// Event is published to the bus.
+type Event interface {
+	Kind() Kind
+}
+
+// NewBlock is published by BlockSource when the block is delivered.
+type NewBlock struct {
+	Number *big.Int
+	Hash   common.Hash
+}
+
+// Kind implements Event interface.
+func (NewBlock) Kind() Kind { return KindNewBlock }
+
+// ReorgDetected is published by BlockSource when parent hash of the block doesn't match the hash of the previous
+// delivered block.
+type ReorgDetected struct {
+	Number   *big.Int    // number of the block
+	Hash     common.Hash // hash of the block
+	PrevHash common.Hash // hash of the previous delivered block, which isn't the parent anymore
+}
+
+// Kind implements Event interface.
+func (ReorgDetected) Kind() Kind { return KindReorgDetected }
+
+// TxMined is published when the transaction is successfully mined (and confirmed, if confirmations are required).
+type TxMined struct {
+	Hash    common.Hash
+	Receipt *types.Receipt
+}
+
+// Kind implements Event interface.
+func (TxMined) Kind() Kind { return KindTxMined }
+
+// TxDropped is published when the transaction is neither pending nor mined for the timeout.
+type TxDropped struct {
+	Hash common.Hash
+}
+
+// Kind implements Event interface.
+func (TxDropped) Kind() Kind { return KindTxDropped }
+
+// GasSpike is published by the gas price estimator when the gas price rises above the threshold.
+type GasSpike struct {
+	Previous *big.Int
+	Current  *big.Int
+}
+
+// Kind implements Event interface.
+func (GasSpike) Kind() Kind { return KindGasSpike }
+
+// DefaultBuffer is the capacity of the channel of the subscription when it's not specified.
+const DefaultBuffer = 64
+
+// Bus delivers published events to subscribers. Publishing never blocks: the event is dropped for the subscriber
+// whose buffer is full. Nil *Bus discards events, so components can publish without checking it. It's safe
+// for concurrent use.
+type Bus struct {
+	mu   sync.RWMutex
+	subs map[*Subscription]struct{}
+}
+
+// NewBus creates an instance of Bus.
+func NewBus() *Bus {
+	return &Bus{subs: make(map[*Subscription]struct{})}
+}
+
+// Subscribe subscribes to events of the given kinds, or to all events when kinds aren't given. Events are
+// delivered on the channel with the given capacity (DefaultBuffer when it's not positive).
+func (b *Bus) Subscribe(buffer int, kinds ...Kind) *Subscription {
+	if buffer <= 0 {
+		buffer = DefaultBuffer
+	}
+	ch := make(chan Event, buffer)
+	s := &Subscription{C: ch, ch: ch, bus: b}
+	if len(kinds) > 0 {
+		s.kinds = make(map[Kind]bool, len(kinds))
+		for _, k := range kinds {
+			s.kinds[k] = true
+		}
+	}
+
+	b.mu.Lock()
+	b.subs[s] = struct{}{}
+	b.mu.Unlock()
+	return s
+}
+
+// Publish delivers the event to subscribers of its kind.
+func (b *Bus) Publish(e Event) {
+	if b == nil || e == nil {
+		return
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for s := range b.subs {
+		if s.kinds != nil && !s.kinds[e.Kind()] {
+			continue
+		}
+		select {
+		case s.ch <- e:
+		default:
+			s.mu.Lock()
+			s.dropped++
+			s.mu.Unlock()
+		}
+	}
+}
+
+// Subscription holds the channel on which subscribed events are delivered.
+type Subscription struct {
+	C       <-chan Event // The channel on which the events are delivered, it's closed by Unsubscribe.
+	ch      chan Event
+	bus     *Bus
+	kinds   map[Kind]bool // nil for all kinds
+	once    sync.Once
+	mu      sync.Mutex
+	dropped uint64
+}
+
+// Unsubscribe stops the delivery of events and closes the channel.
+func (s *Subscription) Unsubscribe() {
+	s.once.Do(func() {
+		s.bus.mu.Lock()
+		delete(s.bus.subs, s)
+		s.bus.mu.Unlock()
+
+		close(s.ch)
+	})
+}
+
+// Dropped returns the number of events dropped because the channel was full.
+func (s *Subscription) Dropped() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.dropped
+}
@@ -0,0 +1,64 @@
+package events
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestBus(t *testing.T) {
+	bus := NewBus()
+	all := bus.Subscribe(0)
+	txs := bus.Subscribe(1, KindTxMined, KindTxDropped)
+
+	bus.Publish(NewBlock{Number: big.NewInt(1)})
+	bus.Publish(TxMined{Hash: common.HexToHash("0x1")})
+	bus.Publish(TxDropped{Hash: common.HexToHash("0x2")}) // txs buffer is full
+
+	for _, expected := range []Kind{KindNewBlock, KindTxMined, KindTxDropped} {
+		if e := <-all.C; e.Kind() != expected {
+			t.Errorf("expected %v, but got %v", expected, e.Kind())
+		}
+	}
+	if e := <-txs.C; e.Kind() != KindTxMined {
+		t.Errorf("expected %v, but got %v", KindTxMined, e.Kind())
+	}
+	if txs.Dropped() != 1 {
+		t.Errorf("expected 1 dropped event, but got %v", txs.Dropped())
+	}
+
+	txs.Unsubscribe()
+	txs.Unsubscribe()
+	if _, ok := <-txs.C; ok {
+		t.Errorf("expected channel to be closed")
+	}
+	bus.Publish(TxMined{})
+	if e := <-all.C; e.Kind() != KindTxMined {
+		t.Errorf("expected %v, but got %v", KindTxMined, e.Kind())
+	}
+}
+
+func TestBus_Nil(t *testing.T) {
+	var bus *Bus
+	bus.Publish(NewBlock{})
+}
+
+func TestKind_String(t *testing.T) {
+	tests := []struct {
+		kind     Kind
+		expected string
+	}{
+		{KindNewBlock, "new_block"},
+		{KindReorgDetected, "reorg_detected"},
+		{KindTxMined, "tx_mined"},
+		{KindTxDropped, "tx_dropped"},
+		{KindGasSpike, "gas_spike"},
+		{Kind(10), "kind(10)"},
+	}
+	for _, tt := range tests {
+		if got := tt.kind.String(); got != tt.expected {
+			t.Errorf("expected %v, but got %v", tt.expected, got)
+		}
+	}
+}
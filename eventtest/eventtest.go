@@ -0,0 +1,169 @@
+// Package eventtest contains assertions on events emitted by transactions, for use in tests:
+//
+//	eventtest.AssertEmitted(t, receipt, tokenABI, "Transfer", from, to, big.NewInt(100))
+//
+// Expected arguments are listed in the order of the event inputs. A nil argument matches any value. Integers can be
+// given as *big.Int or as any Go integer type, values of indexed arguments of dynamic types (string, bytes, arrays)
+// can be given either as the value or as its Keccak256 hash.
+package eventtest
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/monetha/go-ethereum"
+)
+
+// Find returns events with the given name emitted in the receipt, which match the expected arguments.
+func Find(receipt *types.Receipt, contractABI abi.ABI, event string, args ...interface{}) ([]*ethereum.DecodedEvent, error) {
+	if ev, ok := contractABI.Events[event]; ok && len(args) > len(ev.Inputs) {
+		return nil, fmt.Errorf("event %v has %v arguments, but %v expected arguments given", event, len(ev.Inputs), len(args))
+	}
+
+	events, err := ethereum.DecodeEvents(contractABI, receipt, event)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []*ethereum.DecodedEvent
+	for _, e := range events {
+		if matchArgs(e.Args, args) {
+			res = append(res, e)
+		}
+	}
+	return res, nil
+}
+
+// AssertEmitted fails the test when the receipt has no event with the given name and arguments. It returns
+// the first matching event.
+func AssertEmitted(t testing.TB, receipt *types.Receipt, contractABI abi.ABI, event string, args ...interface{}) *ethereum.DecodedEvent {
+	t.Helper()
+
+	found, err := Find(receipt, contractABI, event, args...)
+	if err != nil {
+		t.Fatalf("eventtest: %v", err)
+		return nil
+	}
+	if len(found) == 0 {
+		t.Errorf("expected event %v%v to be emitted, but got %v", event, formatArgs(args), emitted(receipt, contractABI, event))
+		return nil
+	}
+	return found[0]
+}
+
+// AssertNotEmitted fails the test when the receipt has an event with the given name and arguments.
+func AssertNotEmitted(t testing.TB, receipt *types.Receipt, contractABI abi.ABI, event string, args ...interface{}) {
+	t.Helper()
+
+	found, err := Find(receipt, contractABI, event, args...)
+	if err != nil {
+		t.Fatalf("eventtest: %v", err)
+		return
+	}
+	if len(found) != 0 {
+		t.Errorf("expected event %v%v not to be emitted, but got %v", event, formatArgs(args), emitted(receipt, contractABI, event))
+	}
+}
+
+// AssertEmittedCount fails the test when the number of events with the given name and arguments in the receipt
+// isn't equal to the expected one.
+func AssertEmittedCount(t testing.TB, receipt *types.Receipt, contractABI abi.ABI, count int, event string, args ...interface{}) {
+	t.Helper()
+
+	found, err := Find(receipt, contractABI, event, args...)
+	if err != nil {
+		t.Fatalf("eventtest: %v", err)
+		return
+	}
+	if len(found) != count {
+		t.Errorf("expected %v events %v%v, but got %v: %v", count, event, formatArgs(args), len(found), emitted(receipt, contractABI, event))
+	}
+}
+
+func matchArgs(actual, expected []interface{}) bool {
+	for i, exp := range expected {
+		if exp != nil && !equal(actual[i], exp) {
+			return false
+		}
+	}
+	return true
+}
+
+func equal(actual, expected interface{}) bool {
+	if h, ok := actual.(common.Hash); ok {
+		// indexed argument of dynamic type
+		switch exp := expected.(type) {
+		case common.Hash:
+			return h == exp
+		case string:
+			return h == crypto.Keccak256Hash([]byte(exp))
+		case []byte:
+			return h == crypto.Keccak256Hash(exp)
+		}
+	}
+
+	if a, ok := toBig(actual); ok {
+		e, ok := toBig(expected)
+		return ok && a.Cmp(e) == 0
+	}
+
+	return reflect.DeepEqual(actual, expected)
+}
+
+func toBig(v interface{}) (*big.Int, bool) {
+	switch n := v.(type) {
+	case *big.Int:
+		return n, n != nil
+	case big.Int:
+		return &n, true
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return big.NewInt(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return new(big.Int).SetUint64(rv.Uint()), true
+	}
+	return nil, false
+}
+
+func emitted(receipt *types.Receipt, contractABI abi.ABI, event string) string {
+	events, err := ethereum.DecodeEvents(contractABI, receipt, event)
+	if err != nil {
+		return err.Error()
+	}
+	if len(events) == 0 {
+		return "no such events"
+	}
+
+	s := make([]string, len(events))
+	for i, e := range events {
+		s[i] = e.Name + formatArgs(e.Args)
+	}
+	return strings.Join(s, ", ")
+}
+
+func formatArgs(args []interface{}) string {
+	s := make([]string, len(args))
+	for i, arg := range args {
+		switch v := arg.(type) {
+		case nil:
+			s[i] = "*"
+		case common.Address:
+			s[i] = v.Hex()
+		case common.Hash:
+			s[i] = v.Hex()
+		default:
+			s[i] = fmt.Sprint(v)
+		}
+	}
+	return "(" + strings.Join(s, ", ") + ")"
+}
@@ -0,0 +1,120 @@
+package eventtest
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const tokenABI = `[
+	{"anonymous":false,"name":"Transfer","type":"event","inputs":[
+		{"indexed":true,"name":"from","type":"address"},
+		{"indexed":true,"name":"to","type":"address"},
+		{"indexed":false,"name":"value","type":"uint256"}]},
+	{"anonymous":false,"name":"Named","type":"event","inputs":[
+		{"indexed":true,"name":"name","type":"string"},
+		{"indexed":false,"name":"id","type":"uint8"}]}
+]`
+
+type recordingT struct {
+	testing.TB
+	errors []string
+}
+
+func (t *recordingT) Helper() {}
+
+func (t *recordingT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func (t *recordingT) Fatalf(format string, args ...interface{}) {
+	t.Errorf(format, args...)
+}
+
+func TestAssertEmitted(t *testing.T) {
+	parsed, err := abi.JSON(strings.NewReader(tokenABI))
+	if err != nil {
+		t.Fatalf("abi.JSON() error = %v", err)
+	}
+
+	from := common.HexToAddress("0x1")
+	to := common.HexToAddress("0x2")
+	receipt := &types.Receipt{Logs: []*types.Log{
+		{
+			Topics: []common.Hash{parsed.Events["Transfer"].Id(), from.Hash(), to.Hash()},
+			Data:   common.LeftPadBytes(big.NewInt(100).Bytes(), 32),
+		},
+		{
+			Topics: []common.Hash{parsed.Events["Named"].Id(), crypto.Keccak256Hash([]byte("alice"))},
+			Data:   common.LeftPadBytes([]byte{7}, 32),
+		},
+	}}
+
+	tests := []struct {
+		name    string
+		event   string
+		args    []interface{}
+		emitted bool
+		invalid bool
+	}{
+		{name: "all arguments", event: "Transfer", args: []interface{}{from, to, big.NewInt(100)}, emitted: true},
+		{name: "plain integer", event: "Transfer", args: []interface{}{from, to, 100}, emitted: true},
+		{name: "wildcard", event: "Transfer", args: []interface{}{nil, to}, emitted: true},
+		{name: "no arguments", event: "Transfer", emitted: true},
+		{name: "wrong value", event: "Transfer", args: []interface{}{from, to, 101}},
+		{name: "wrong indexed argument", event: "Transfer", args: []interface{}{to, from}},
+		{name: "indexed string", event: "Named", args: []interface{}{"alice", uint8(7)}, emitted: true},
+		{name: "indexed string hash", event: "Named", args: []interface{}{crypto.Keccak256Hash([]byte("alice"))}, emitted: true},
+		{name: "wrong indexed string", event: "Named", args: []interface{}{"bob"}},
+		{name: "too many arguments", event: "Named", args: []interface{}{"alice", 7, 1}, invalid: true},
+		{name: "unknown event", event: "Approval", invalid: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rt := &recordingT{}
+			AssertEmitted(rt, receipt, parsed, tt.event, tt.args...)
+			if failed, expected := len(rt.errors) != 0, !tt.emitted || tt.invalid; failed != expected {
+				t.Errorf("AssertEmitted: expected failed %v, but got %v (%v)", expected, failed, rt.errors)
+			}
+
+			rt = &recordingT{}
+			AssertNotEmitted(rt, receipt, parsed, tt.event, tt.args...)
+			if failed, expected := len(rt.errors) != 0, tt.emitted || tt.invalid; failed != expected {
+				t.Errorf("AssertNotEmitted: expected failed %v, but got %v (%v)", expected, failed, rt.errors)
+			}
+		})
+	}
+}
+
+func TestEqual(t *testing.T) {
+	tests := []struct {
+		actual   interface{}
+		expected interface{}
+		equal    bool
+	}{
+		{big.NewInt(5), 5, true},
+		{big.NewInt(5), uint64(5), true},
+		{big.NewInt(5), big.NewInt(6), false},
+		{uint8(7), 7, true},
+		{common.HexToAddress("0x1"), common.HexToAddress("0x1"), true},
+		{common.HexToAddress("0x1"), common.HexToAddress("0x2"), false},
+		{crypto.Keccak256Hash([]byte("a")), "a", true},
+		{crypto.Keccak256Hash([]byte("a")), []byte("a"), true},
+		{crypto.Keccak256Hash([]byte("a")), "b", false},
+		{true, true, true},
+		{big.NewInt(5), "5", false},
+	}
+
+	for _, tt := range tests {
+		if got := equal(tt.actual, tt.expected); got != tt.equal {
+			t.Errorf("equal(%v, %v): expected %v, but got %v", tt.actual, tt.expected, tt.equal, got)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package export
+
+import (
+	"math/big"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/monetha/go-ethereum"
+)
+
+// BlockColumns are the columns available for blocks, by name.
+var BlockColumns = map[string]func(b *ethereum.Block) string{
+	"number":            func(b *ethereum.Block) string { return bigString(b.Number) },
+	"hash":              func(b *ethereum.Block) string { return b.Hash.Hex() },
+	"timestamp":         func(b *ethereum.Block) string { return strconv.FormatUint(b.Timestamp, 10) },
+	"miner":             func(b *ethereum.Block) string { return b.Miner.Hex() },
+	"difficulty":        func(b *ethereum.Block) string { return bigString(b.Difficulty) },
+	"gas_limit":         func(b *ethereum.Block) string { return bigString(b.GasLimit) },
+	"gas_used":          func(b *ethereum.Block) string { return bigString(b.GasUsed) },
+	"extra_data":        func(b *ethereum.Block) string { return hexutil.Encode(b.ExtraData) },
+	"transaction_count": func(b *ethereum.Block) string { return strconv.Itoa(len(b.Transactions)) },
+	"uncle_count":       func(b *ethereum.Block) string { return strconv.Itoa(len(b.UncleHashes)) },
+	"blob_gas_used":     func(b *ethereum.Block) string { return bigString(b.BlobGasUsed) },
+	"excess_blob_gas":   func(b *ethereum.Block) string { return bigString(b.ExcessBlobGas) },
+	"withdrawal_count":  func(b *ethereum.Block) string { return strconv.Itoa(len(b.Withdrawals)) },
+}
+
+// TransactionColumns are the columns available for transactions, by name.
+var TransactionColumns = map[string]func(b *ethereum.Block, tx *ethereum.Transaction) string{
+	"block_number":    func(b *ethereum.Block, tx *ethereum.Transaction) string { return bigString(b.Number) },
+	"block_timestamp": func(b *ethereum.Block, tx *ethereum.Transaction) string { return strconv.FormatUint(b.Timestamp, 10) },
+	"hash":            func(b *ethereum.Block, tx *ethereum.Transaction) string { return tx.Hash.Hex() },
+	"transaction_index": func(b *ethereum.Block, tx *ethereum.Transaction) string {
+		return strconv.FormatUint(tx.TransactionIndex, 10)
+	},
+	"type":             func(b *ethereum.Block, tx *ethereum.Transaction) string { return strconv.Itoa(int(tx.Type)) },
+	"from":             func(b *ethereum.Block, tx *ethereum.Transaction) string { return tx.From.Hex() },
+	"to":               func(b *ethereum.Block, tx *ethereum.Transaction) string { return addressString(tx.To) },
+	"value":            func(b *ethereum.Block, tx *ethereum.Transaction) string { return bigString(tx.Value) },
+	"nonce":            func(b *ethereum.Block, tx *ethereum.Transaction) string { return strconv.FormatUint(tx.Nonce, 10) },
+	"gas_limit":        func(b *ethereum.Block, tx *ethereum.Transaction) string { return bigString(tx.GasLimit) },
+	"gas_price":        func(b *ethereum.Block, tx *ethereum.Transaction) string { return bigString(tx.GasPrice) },
+	"gas_used":         func(b *ethereum.Block, tx *ethereum.Transaction) string { return bigString(tx.GasUsed) },
+	"input":            func(b *ethereum.Block, tx *ethereum.Transaction) string { return hexutil.Encode(tx.Input) },
+	"contract_address": func(b *ethereum.Block, tx *ethereum.Transaction) string { return addressString(tx.ContractAddress) },
+	"status": func(b *ethereum.Block, tx *ethereum.Transaction) string {
+		if tx.Status == nil {
+			return ""
+		}
+		return strconv.FormatUint(uint64(*tx.Status), 10)
+	},
+	"log_count": func(b *ethereum.Block, tx *ethereum.Transaction) string { return strconv.Itoa(len(tx.Logs)) },
+}
+
+// DefaultBlockColumns are exported when Config.BlockColumns is nil.
+var DefaultBlockColumns = []string{"number", "hash", "timestamp", "miner", "gas_limit", "gas_used", "transaction_count"}
+
+// DefaultTransactionColumns are exported when Config.TransactionColumns is nil.
+var DefaultTransactionColumns = []string{"block_number", "hash", "transaction_index", "from", "to", "value", "nonce",
+	"gas_limit", "gas_price", "gas_used", "status"}
+
+func bigString(v *big.Int) string {
+	if v == nil {
+		return ""
+	}
+	return v.String()
+}
+
+func addressString(a *common.Address) string {
+	if a == nil {
+		return ""
+	}
+	return a.Hex()
+}
@@ -0,0 +1,245 @@
+// Package export streams blocks delivered by BlockSource into files of blocks and transactions, one row per
+// block or transaction, with configurable columns. Files are CSV unless other format is configured
+// (see export/parquet package, built with the parquet build tag) and can be rotated by block range:
+//
+//	e, err := export.New(export.Config{Dir: "data", BlocksPerFile: 10000})
+//	...
+//	err = e.Run(ctx, source.Blocks())
+package export
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/monetha/go-ethereum"
+)
+
+// RowWriter writes rows of the file.
+type RowWriter interface {
+	WriteRow(values []string) error
+	// Close flushes written rows and closes the file.
+	Close() error
+}
+
+// Format is the file format.
+type Format struct {
+	// Extension of file names, including the dot.
+	Extension string
+	// NewWriter creates the file with the given columns.
+	NewWriter func(path string, columns []string) (RowWriter, error)
+}
+
+// CSV is the format of CSV files with the header row.
+var CSV = Format{Extension: ".csv", NewWriter: NewCSVWriter}
+
+// Config contains parameters of Exporter.
+type Config struct {
+	// Dir is the directory of created files.
+	Dir string
+	// Format of created files, CSV is used when NewWriter is nil.
+	Format Format
+	// BlockColumns are names of exported columns of BlockColumns. DefaultBlockColumns are exported when it's nil,
+	// blocks aren't exported when it's empty.
+	BlockColumns []string
+	// TransactionColumns are names of exported columns of TransactionColumns. DefaultTransactionColumns are
+	// exported when it's nil, transactions aren't exported when it's empty.
+	TransactionColumns []string
+	// BlocksPerFile is the number of blocks in the range of one file, e.g. blocks 0-999, 1000-1999 etc. when it's
+	// 1000. Files are named after the range, e.g. blocks_1000-1999.csv. All blocks are written to one file
+	// (e.g. blocks.csv) when it's zero. Existing files are overwritten.
+	BlocksPerFile uint64
+}
+
+// Exporter writes blocks and their transactions to files. It must be used within one goroutine.
+type Exporter struct {
+	cfg         Config
+	blockCols   []func(b *ethereum.Block) string
+	txCols      []func(b *ethereum.Block, tx *ethereum.Transaction) string
+	fileRange   uint64 // index of the block range of open files
+	blocksFile  RowWriter
+	txsFile     RowWriter
+	open        bool
+	blockValues []string
+	txValues    []string
+}
+
+// New creates an instance of Exporter. Files are created when the first block is written.
+func New(cfg Config) (*Exporter, error) {
+	if cfg.Format.NewWriter == nil {
+		cfg.Format = CSV
+	}
+	if cfg.BlockColumns == nil {
+		cfg.BlockColumns = DefaultBlockColumns
+	}
+	if cfg.TransactionColumns == nil {
+		cfg.TransactionColumns = DefaultTransactionColumns
+	}
+
+	e := &Exporter{cfg: cfg}
+	for _, name := range cfg.BlockColumns {
+		col, ok := BlockColumns[name]
+		if !ok {
+			return nil, fmt.Errorf("export: unknown block column %q", name)
+		}
+		e.blockCols = append(e.blockCols, col)
+	}
+	for _, name := range cfg.TransactionColumns {
+		col, ok := TransactionColumns[name]
+		if !ok {
+			return nil, fmt.Errorf("export: unknown transaction column %q", name)
+		}
+		e.txCols = append(e.txCols, col)
+	}
+	e.blockValues = make([]string, len(e.blockCols))
+	e.txValues = make([]string, len(e.txCols))
+
+	return e, nil
+}
+
+// Write writes the block and its transactions, rotating files when the block is out of the range of open files.
+func (e *Exporter) Write(b *ethereum.Block) error {
+	var fileRange uint64
+	if e.cfg.BlocksPerFile > 0 {
+		fileRange = b.Number.Uint64() / e.cfg.BlocksPerFile
+	}
+	if !e.open || fileRange != e.fileRange {
+		if err := e.rotate(fileRange); err != nil {
+			return err
+		}
+	}
+
+	if e.blocksFile != nil {
+		for i, col := range e.blockCols {
+			e.blockValues[i] = col(b)
+		}
+		if err := e.blocksFile.WriteRow(e.blockValues); err != nil {
+			return fmt.Errorf("export: writing block %v: %v", b.Number, err)
+		}
+	}
+
+	if e.txsFile != nil {
+		for _, tx := range b.Transactions {
+			for i, col := range e.txCols {
+				e.txValues[i] = col(b, tx)
+			}
+			if err := e.txsFile.WriteRow(e.txValues); err != nil {
+				return fmt.Errorf("export: writing transaction %v: %v", tx.Hash.Hex(), err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Run writes blocks from the channel until it's closed or the context is done, and closes files.
+func (e *Exporter) Run(ctx context.Context, blocks <-chan *ethereum.Block) (err error) {
+	defer func() {
+		if cerr := e.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case b, ok := <-blocks:
+			if !ok {
+				return nil
+			}
+			if err := e.Write(b); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Close closes open files.
+func (e *Exporter) Close() error {
+	if !e.open {
+		return nil
+	}
+	e.open = false
+
+	var err error
+	for _, f := range []RowWriter{e.blocksFile, e.txsFile} {
+		if f == nil {
+			continue
+		}
+		if cerr := f.Close(); cerr != nil && err == nil {
+			err = fmt.Errorf("export: closing file: %v", cerr)
+		}
+	}
+	e.blocksFile, e.txsFile = nil, nil
+	return err
+}
+
+func (e *Exporter) rotate(fileRange uint64) error {
+	if err := e.Close(); err != nil {
+		return err
+	}
+
+	suffix := e.cfg.Format.Extension
+	if e.cfg.BlocksPerFile > 0 {
+		from := fileRange * e.cfg.BlocksPerFile
+		suffix = fmt.Sprintf("_%v-%v%v", from, from+e.cfg.BlocksPerFile-1, suffix)
+	}
+
+	var err error
+	if len(e.blockCols) > 0 {
+		e.blocksFile, err = e.cfg.Format.NewWriter(filepath.Join(e.cfg.Dir, "blocks"+suffix), e.cfg.BlockColumns)
+		if err != nil {
+			return fmt.Errorf("export: creating blocks file: %v", err)
+		}
+	}
+	if len(e.txCols) > 0 {
+		e.txsFile, err = e.cfg.Format.NewWriter(filepath.Join(e.cfg.Dir, "transactions"+suffix), e.cfg.TransactionColumns)
+		if err != nil {
+			if e.blocksFile != nil {
+				e.blocksFile.Close()
+				e.blocksFile = nil
+			}
+			return fmt.Errorf("export: creating transactions file: %v", err)
+		}
+	}
+
+	e.fileRange = fileRange
+	e.open = true
+	return nil
+}
+
+type csvWriter struct {
+	f *os.File
+	w *csv.Writer
+}
+
+// NewCSVWriter creates the CSV file and writes the header row with column names.
+func NewCSVWriter(path string, columns []string) (RowWriter, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &csvWriter{f: f, w: csv.NewWriter(f)}
+	if err := w.WriteRow(columns); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *csvWriter) WriteRow(values []string) error {
+	return w.w.Write(values)
+}
+
+func (w *csvWriter) Close() error {
+	w.w.Flush()
+	if err := w.w.Error(); err != nil {
+		w.f.Close()
+		return err
+	}
+	return w.f.Close()
+}
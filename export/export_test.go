@@ -0,0 +1,139 @@
+package export
+
+import (
+	"context"
+	"encoding/csv"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/monetha/go-ethereum"
+)
+
+func testBlock(number int64, txs int) *ethereum.Block {
+	b := &ethereum.Block{Number: big.NewInt(number), Hash: common.BigToHash(big.NewInt(number))}
+	for i := 0; i < txs; i++ {
+		to := common.HexToAddress("0x1")
+		b.Transactions = append(b.Transactions, &ethereum.Transaction{
+			BlockNumber:      b.Number,
+			Hash:             common.BigToHash(big.NewInt(number*100 + int64(i))),
+			TransactionIndex: uint64(i),
+			To:               &to,
+			Value:            big.NewInt(int64(i)),
+		})
+	}
+	return b
+}
+
+func readCSV(t *testing.T, path string) [][]string {
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("os.Open() error = %v", err)
+	}
+	defer f.Close()
+
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	return rows
+}
+
+func TestExporter_Run(t *testing.T) {
+	dir, err := ioutil.TempDir("", "export")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	e, err := New(Config{
+		Dir:                dir,
+		BlockColumns:       []string{"number", "transaction_count"},
+		TransactionColumns: []string{"block_number", "transaction_index", "value"},
+		BlocksPerFile:      10,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	blocks := make(chan *ethereum.Block, 3)
+	blocks <- testBlock(8, 1)
+	blocks <- testBlock(9, 0)
+	blocks <- testBlock(10, 2)
+	close(blocks)
+
+	if err := e.Run(context.Background(), blocks); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*"))
+	for i := range files {
+		files[i] = filepath.Base(files[i])
+	}
+	sort.Strings(files)
+	expectedFiles := []string{"blocks_0-9.csv", "blocks_10-19.csv", "transactions_0-9.csv", "transactions_10-19.csv"}
+	if !reflect.DeepEqual(files, expectedFiles) {
+		t.Fatalf("expected files %v, but got %v", expectedFiles, files)
+	}
+
+	tests := []struct {
+		file string
+		rows [][]string
+	}{
+		{"blocks_0-9.csv", [][]string{{"number", "transaction_count"}, {"8", "1"}, {"9", "0"}}},
+		{"blocks_10-19.csv", [][]string{{"number", "transaction_count"}, {"10", "2"}}},
+		{"transactions_0-9.csv", [][]string{{"block_number", "transaction_index", "value"}, {"8", "0", "0"}}},
+		{"transactions_10-19.csv", [][]string{{"block_number", "transaction_index", "value"}, {"10", "0", "0"}, {"10", "1", "1"}}},
+	}
+	for _, tt := range tests {
+		if rows := readCSV(t, filepath.Join(dir, tt.file)); !reflect.DeepEqual(rows, tt.rows) {
+			t.Errorf("%v: expected rows %v, but got %v", tt.file, tt.rows, rows)
+		}
+	}
+}
+
+func TestExporter_SingleFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "export")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	e, err := New(Config{Dir: dir, TransactionColumns: []string{}})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	for _, n := range []int64{1, 100, 10000} {
+		if err := e.Write(testBlock(n, 1)); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	if err := e.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	rows := readCSV(t, filepath.Join(dir, "blocks.csv"))
+	if len(rows) != 4 {
+		t.Errorf("expected 4 rows, but got %v", len(rows))
+	}
+	if !reflect.DeepEqual(rows[0], DefaultBlockColumns) {
+		t.Errorf("expected header %v, but got %v", DefaultBlockColumns, rows[0])
+	}
+	if _, err := os.Stat(filepath.Join(dir, "transactions.csv")); !os.IsNotExist(err) {
+		t.Errorf("expected no transactions file, but got %v", err)
+	}
+}
+
+func TestNew_UnknownColumn(t *testing.T) {
+	if _, err := New(Config{BlockColumns: []string{"number", "foo"}}); err == nil {
+		t.Error("expected error for unknown block column, but got nil")
+	}
+	if _, err := New(Config{TransactionColumns: []string{"bar"}}); err == nil {
+		t.Error("expected error for unknown transaction column, but got nil")
+	}
+}
@@ -0,0 +1,59 @@
+// +build parquet
+
+// Package parquet implements Parquet format of export package. All columns are stored as UTF-8 strings.
+// Parquet writer requires a newer Go than the rest of the library, so github.com/xitongsys/parquet-go isn't
+// locked in glide.lock, and the package is built only with the parquet build tag (go build -tags parquet) after
+// the dependency is installed.
+package parquet
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/monetha/go-ethereum/export"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+// Format is the format of Parquet files.
+var Format = export.Format{Extension: ".parquet", NewWriter: NewWriter}
+
+type parquetWriter struct {
+	f *os.File
+	w *writer.CSVWriter
+}
+
+// NewWriter creates the Parquet file with the given columns.
+func NewWriter(path string, columns []string) (export.RowWriter, error) {
+	md := make([]string, len(columns))
+	for i, name := range columns {
+		md[i] = fmt.Sprintf("name=%v, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY", name)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := writer.NewCSVWriterFromWriter(md, f, 1)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &parquetWriter{f: f, w: w}, nil
+}
+
+func (w *parquetWriter) WriteRow(values []string) error {
+	rec := make([]*string, len(values))
+	for i := range values {
+		rec[i] = &values[i]
+	}
+	return w.w.WriteString(rec)
+}
+
+func (w *parquetWriter) Close() error {
+	if err := w.w.WriteStop(); err != nil {
+		w.f.Close()
+		return err
+	}
+	return w.f.Close()
+}
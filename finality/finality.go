@@ -0,0 +1,97 @@
+// Package finality decides whether a block (and transactions in it) is final according to a policy: the number
+// of confirmations, the finalized or safe block tag, or a chain-specific rule. The same policy can be used
+// by Eth waiting for receipts, by BlockSource and by deposit detection, so they agree on finality.
+package finality
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/monetha/go-ethereum/headtracker"
+)
+
+// Policy decides whether the block with the given number is final given the heads of the chain.
+type Policy interface {
+	IsFinal(block uint64, heads headtracker.Heads) bool
+}
+
+// PolicyFunc is an adapter to use ordinary functions (e.g. chain-specific rules) as Policy.
+type PolicyFunc func(block uint64, heads headtracker.Heads) bool
+
+// IsFinal implements Policy interface.
+func (f PolicyFunc) IsFinal(block uint64, heads headtracker.Heads) bool {
+	return f(block, heads)
+}
+
+// Confirmations returns the policy which considers the block final when at least n blocks are mined on top of it.
+func Confirmations(n uint64) Policy {
+	return PolicyFunc(func(block uint64, heads headtracker.Heads) bool {
+		return atOrBelow(new(big.Int).SetUint64(block+n), heads.Latest)
+	})
+}
+
+// Finalized returns the policy which considers the block final when it's at or below the finalized block.
+// Nothing is final when the node doesn't support the finalized tag, see FirstOf to fall back to confirmations.
+func Finalized() Policy {
+	return PolicyFunc(func(block uint64, heads headtracker.Heads) bool {
+		return atOrBelow(new(big.Int).SetUint64(block), heads.Finalized)
+	})
+}
+
+// Safe returns the policy which considers the block final when it's at or below the safe block.
+func Safe() Policy {
+	return PolicyFunc(func(block uint64, heads headtracker.Heads) bool {
+		return atOrBelow(new(big.Int).SetUint64(block), heads.Safe)
+	})
+}
+
+// FirstOf returns the policy which considers the block final when any of the policies does,
+// e.g. FirstOf(Finalized(), Confirmations(64)) for nodes which may not support the finalized tag.
+func FirstOf(policies ...Policy) Policy {
+	return PolicyFunc(func(block uint64, heads headtracker.Heads) bool {
+		for _, p := range policies {
+			if p.IsFinal(block, heads) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+func atOrBelow(block, head *big.Int) bool {
+	return head != nil && block.Cmp(head) <= 0
+}
+
+// HeadsReader returns the heads of the chain, it's implemented by headtracker.Tracker.
+type HeadsReader interface {
+	Heads() headtracker.Heads
+}
+
+// ErrNoHeads is returned by Checker when the latest head isn't known.
+var ErrNoHeads = errors.New("finality: heads of the chain are unknown")
+
+// Checker checks finality of blocks according to the policy and the heads of the chain. It implements
+// ethereum.FinalityChecker and blocksource.FinalityChecker.
+type Checker struct {
+	policy Policy
+	heads  HeadsReader
+}
+
+// NewChecker creates an instance of Checker.
+func NewChecker(policy Policy, heads HeadsReader) *Checker {
+	return &Checker{policy: policy, heads: heads}
+}
+
+// IsFinal tells whether the block with the given number is final.
+func (c *Checker) IsFinal(ctx context.Context, block *big.Int) (bool, error) {
+	if block == nil || block.Sign() < 0 || !block.IsUint64() {
+		return false, fmt.Errorf("finality: invalid block number %v", block)
+	}
+	heads := c.heads.Heads()
+	if heads.Latest == nil {
+		return false, ErrNoHeads
+	}
+	return c.policy.IsFinal(block.Uint64(), heads), nil
+}
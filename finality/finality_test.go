@@ -0,0 +1,90 @@
+package finality
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/monetha/go-ethereum/headtracker"
+)
+
+func heads(latest, safe, finalized int64) headtracker.Heads {
+	h := headtracker.Heads{Latest: big.NewInt(latest)}
+	if safe >= 0 {
+		h.Safe = big.NewInt(safe)
+	}
+	if finalized >= 0 {
+		h.Finalized = big.NewInt(finalized)
+	}
+	return h
+}
+
+func TestPolicies(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   Policy
+		block    uint64
+		heads    headtracker.Heads
+		expected bool
+	}{
+		{"confirmations reached", Confirmations(10), 90, heads(100, -1, -1), true},
+		{"confirmations not reached", Confirmations(10), 91, heads(100, -1, -1), false},
+		{"zero confirmations", Confirmations(0), 100, heads(100, -1, -1), true},
+		{"finalized", Finalized(), 90, heads(100, 96, 90), true},
+		{"not finalized", Finalized(), 91, heads(100, 96, 90), false},
+		{"finalized tag not supported", Finalized(), 1, heads(100, -1, -1), false},
+		{"safe", Safe(), 96, heads(100, 96, 90), true},
+		{"not safe", Safe(), 97, heads(100, 96, 90), false},
+		{"first of falls back", FirstOf(Finalized(), Confirmations(10)), 90, heads(100, -1, -1), true},
+		{"first of none", FirstOf(Finalized(), Confirmations(10)), 95, heads(100, -1, 90), false},
+		{"func", PolicyFunc(func(block uint64, h headtracker.Heads) bool { return block%2 == 0 }), 2, heads(0, -1, -1), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if final := tt.policy.IsFinal(tt.block, tt.heads); final != tt.expected {
+				t.Errorf("expected %v, but got %v", tt.expected, final)
+			}
+		})
+	}
+}
+
+type headsReader headtracker.Heads
+
+func (h headsReader) Heads() headtracker.Heads { return headtracker.Heads(h) }
+
+func TestChecker_IsFinal(t *testing.T) {
+	c := NewChecker(Finalized(), headsReader(heads(100, 96, 90)))
+
+	final, err := c.IsFinal(context.Background(), big.NewInt(90))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !final {
+		t.Errorf("expected block 90 to be final")
+	}
+
+	final, err = c.IsFinal(context.Background(), big.NewInt(91))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if final {
+		t.Errorf("expected block 91 not to be final")
+	}
+}
+
+func TestChecker_NoHeads(t *testing.T) {
+	c := NewChecker(Confirmations(1), headsReader{})
+	if _, err := c.IsFinal(context.Background(), big.NewInt(1)); err != ErrNoHeads {
+		t.Errorf("expected %v, but got %v", ErrNoHeads, err)
+	}
+}
+
+func TestChecker_InvalidBlock(t *testing.T) {
+	c := NewChecker(Finalized(), headsReader(heads(100, 96, 90)))
+	for _, block := range []*big.Int{nil, big.NewInt(-1)} {
+		if _, err := c.IsFinal(context.Background(), block); err == nil {
+			t.Errorf("expected error for block %v", block)
+		}
+	}
+}
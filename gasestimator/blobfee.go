@@ -0,0 +1,79 @@
+package gasestimator
+
+import "math/big"
+
+// GasPerBlob is the amount of blob gas consumed by a single blob (EIP-4844).
+const GasPerBlob = 1 << 17
+
+// BlobParams holds the network parameters used to calculate the blob base fee.
+type BlobParams struct {
+	// TargetBlobGasPerBlock is the target amount of blob gas per block.
+	TargetBlobGasPerBlock uint64
+	// BlobBaseFeeUpdateFraction controls the maximum rate of change of the blob base fee.
+	BlobBaseFeeUpdateFraction uint64
+	// MinBlobBaseFee is the minimum blob base fee in wei.
+	MinBlobBaseFee uint64
+}
+
+var (
+	// CancunBlobParams are the blob parameters introduced by the Cancun upgrade (EIP-4844).
+	CancunBlobParams = BlobParams{
+		TargetBlobGasPerBlock:     3 * GasPerBlob,
+		BlobBaseFeeUpdateFraction: 3338477,
+		MinBlobBaseFee:            1,
+	}
+
+	// PragueBlobParams are the blob parameters introduced by the Prague upgrade (EIP-7691).
+	PragueBlobParams = BlobParams{
+		TargetBlobGasPerBlock:     6 * GasPerBlob,
+		BlobBaseFeeUpdateFraction: 5007716,
+		MinBlobBaseFee:            1,
+	}
+)
+
+// BlobBaseFee calculates the blob base fee (price per unit of blob gas in wei) of the block with the given excess blob gas.
+func (p BlobParams) BlobBaseFee(excessBlobGas *big.Int) *big.Int {
+	return fakeExponential(
+		new(big.Int).SetUint64(p.MinBlobBaseFee),
+		excessBlobGas,
+		new(big.Int).SetUint64(p.BlobBaseFeeUpdateFraction),
+	)
+}
+
+// NextExcessBlobGas calculates the excess blob gas of the next block given the excess blob gas and blob gas
+// used of the parent block.
+func (p BlobParams) NextExcessBlobGas(parentExcessBlobGas, parentBlobGasUsed *big.Int) *big.Int {
+	excess := new(big.Int).Add(parentExcessBlobGas, parentBlobGasUsed)
+	excess.Sub(excess, new(big.Int).SetUint64(p.TargetBlobGasPerBlock))
+	if excess.Sign() < 0 {
+		return new(big.Int)
+	}
+	return excess
+}
+
+// SuggestBlobGasPrice estimates the blob base fee of the next block given excess blob gas and blob gas used
+// of the latest block (see ethereum.Block ExcessBlobGas and BlobGasUsed fields).
+func (p BlobParams) SuggestBlobGasPrice(excessBlobGas, blobGasUsed *big.Int) *big.Int {
+	return p.BlobBaseFee(p.NextExcessBlobGas(excessBlobGas, blobGasUsed))
+}
+
+// BlobFee returns the fee in wei paid for the given number of blobs at the given blob base fee.
+func BlobFee(blobBaseFee *big.Int, blobs int) *big.Int {
+	return new(big.Int).Mul(blobBaseFee, big.NewInt(int64(blobs)*GasPerBlob))
+}
+
+// fakeExponential approximates factor * e ** (numerator / denominator) using Taylor expansion (EIP-4844).
+func fakeExponential(factor, numerator, denominator *big.Int) *big.Int {
+	var (
+		output = new(big.Int)
+		accum  = new(big.Int).Mul(factor, denominator)
+	)
+	for i := 1; accum.Sign() > 0; i++ {
+		output.Add(output, accum)
+
+		accum.Mul(accum, numerator)
+		accum.Div(accum, denominator)
+		accum.Div(accum, big.NewInt(int64(i)))
+	}
+	return output.Div(output, denominator)
+}
@@ -0,0 +1,42 @@
+package gasestimator
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestBlobParams_BlobBaseFee(t *testing.T) {
+	testCases := []struct {
+		params   BlobParams
+		excess   int64
+		expected int64
+	}{
+		{CancunBlobParams, 0, 1},
+		{CancunBlobParams, 393216, 1},
+		{CancunBlobParams, 3932160, 3},
+		{CancunBlobParams, 100000000, 10203769476395},
+		{PragueBlobParams, 3932160, 2},
+		{PragueBlobParams, 100000000, 470442149},
+	}
+
+	for _, tc := range testCases {
+		fee := tc.params.BlobBaseFee(big.NewInt(tc.excess))
+		if fee.Int64() != tc.expected {
+			t.Errorf("BlobBaseFee(%v): expected %v, but got %v", tc.excess, tc.expected, fee)
+		}
+	}
+}
+
+func TestBlobParams_NextExcessBlobGas(t *testing.T) {
+	p := CancunBlobParams
+
+	excess := p.NextExcessBlobGas(big.NewInt(0), big.NewInt(2*GasPerBlob))
+	if excess.Sign() != 0 {
+		t.Errorf("expected zero excess blob gas when below target, but got %v", excess)
+	}
+
+	excess = p.NextExcessBlobGas(big.NewInt(GasPerBlob), big.NewInt(6*GasPerBlob))
+	if expected := big.NewInt(4 * GasPerBlob); excess.Cmp(expected) != 0 {
+		t.Errorf("expected excess blob gas %v, but got %v", expected, excess)
+	}
+}
@@ -4,11 +4,15 @@ import (
 	"context"
 	"fmt"
 	"math/big"
+	"sync"
+	"time"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/monetha/go-ethereum/blocktag"
 )
 
 // GasLimitEstimator is the gas estimator for the contract methods on the Ethereum network.
@@ -16,32 +20,127 @@ type GasLimitEstimator struct {
 	address    common.Address          // Deployment address of the contract on the Ethereum blockchain
 	abi        abi.ABI                 // Reflect based ABI to access the correct Ethereum methods
 	transactor bind.ContractTransactor // Write interface to interact with the blockchain
+	cache      *gasLimitCache          // Cache of estimates (optional)
+	table      map[string]uint64       // Fixed gas limits of methods (optional)
+	headroom   uint64                  // Percentage added to fixed gas limits
+	rpc        RPCCaller               // RPC client to estimate gas at the block with state overrides (optional)
+	block      string                  // Block to estimate gas at ("pending" when empty)
+	overrides  StateOverrides          // State overrides applied before estimation (optional)
+}
+
+// RPCCaller calls RPC methods, it's implemented by *rpc.Client.
+type RPCCaller interface {
+	CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error
+}
+
+// StateOverrides is the set of accounts whose state is overridden before gas estimation.
+type StateOverrides map[common.Address]OverrideAccount
+
+// OverrideAccount specifies the state of the account to be overridden. Nil fields are not overridden.
+type OverrideAccount struct {
+	Nonce     *hexutil.Uint64             `json:"nonce,omitempty"`
+	Code      *hexutil.Bytes              `json:"code,omitempty"`
+	Balance   *hexutil.Big                `json:"balance,omitempty"`
+	State     map[common.Hash]common.Hash `json:"state,omitempty"`
+	StateDiff map[common.Hash]common.Hash `json:"stateDiff,omitempty"`
+}
+
+// GasLimitOption configures GasLimitEstimator.
+type GasLimitOption func(*GasLimitEstimator)
+
+// WithCache makes GasLimitEstimator to memoize estimates of identical calls (same sender, contract, input and value)
+// for the given duration.
+func WithCache(ttl time.Duration) GasLimitOption {
+	return func(c *GasLimitEstimator) {
+		if ttl > 0 {
+			c.cache = newGasLimitCache(ttl)
+		}
+	}
+}
+
+// WithGasLimitTable sets fixed gas limits of contract methods, increased by the given percentage of headroom.
+// Gas is estimated only for methods which are not in the table.
+func WithGasLimitTable(table map[string]uint64, headroomPercent uint) GasLimitOption {
+	return func(c *GasLimitEstimator) {
+		c.table = make(map[string]uint64, len(table))
+		for method, gasLimit := range table {
+			c.table[method] = gasLimit
+		}
+		c.headroom = uint64(headroomPercent)
+	}
+}
+
+// WithBlockNumber makes GasLimitEstimator to estimate gas at the given block using RPC client instead of pending state.
+// Nil means the latest block, negative numbers are block tags (e.g. ethereum.FinalizedBlockNumber).
+func WithBlockNumber(rpc RPCCaller, number *big.Int) GasLimitOption {
+	return func(c *GasLimitEstimator) {
+		c.rpc = rpc
+		if number == nil {
+			c.block = "latest"
+		} else if tag, ok := blocktag.Name(number); ok {
+			c.block = tag
+		} else {
+			c.block = hexutil.EncodeBig(number)
+		}
+	}
+}
+
+// WithStateOverrides makes GasLimitEstimator to estimate gas using RPC client with the given state overrides.
+func WithStateOverrides(rpc RPCCaller, overrides StateOverrides) GasLimitOption {
+	return func(c *GasLimitEstimator) {
+		c.rpc = rpc
+		c.overrides = overrides
+	}
 }
 
 // NewGasLimitEstimator creates instance of GasLimitEstimator
-func NewGasLimitEstimator(address common.Address, abi abi.ABI, transactor bind.ContractTransactor) *GasLimitEstimator {
-	return &GasLimitEstimator{address: address, abi: abi, transactor: transactor}
+func NewGasLimitEstimator(address common.Address, abi abi.ABI, transactor bind.ContractTransactor, opts ...GasLimitOption) *GasLimitEstimator {
+	c := &GasLimitEstimator{address: address, abi: abi, transactor: transactor}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 // EstimateGas estimates gas limit to call the (paid) contract method with params as input values.
+// If the method is in the gas limit table, its fixed gas limit is returned without estimation.
 func (c *GasLimitEstimator) EstimateGas(opts *bind.TransactOpts, method string, params ...interface{}) (*big.Int, error) {
 	// Otherwise pack up the parameters and invoke the contract
 	input, err := c.abi.Pack(method, params...)
 	if err != nil {
 		return nil, err
 	}
+	if gasLimit, ok := c.fixedGasLimit(method); ok {
+		return gasLimit, nil
+	}
 	return c.estimateGas(opts, &c.address, input)
 }
 
+func (c *GasLimitEstimator) fixedGasLimit(method string) (*big.Int, bool) {
+	gasLimit, ok := c.table[method]
+	if !ok {
+		return nil, false
+	}
+	return new(big.Int).SetUint64(gasLimit + gasLimit*c.headroom/100), true
+}
+
 func (c *GasLimitEstimator) estimateGas(opts *bind.TransactOpts, contract *common.Address, input []byte) (*big.Int, error) {
 	value := opts.Value
 	if value == nil {
 		value = new(big.Int)
 	}
 
+	var cacheKey string
+	if c.cache != nil {
+		cacheKey = gasLimitCacheKey(opts.From, contract, value, input)
+		if gasLimit, ok := c.cache.get(cacheKey); ok {
+			return gasLimit, nil
+		}
+	}
+
 	// Gas estimation cannot succeed without code for method invocations
 	if contract != nil {
-		if code, err := c.transactor.PendingCodeAt(ensureContext(opts.Context), c.address); err != nil {
+		if code, err := c.codeAt(ensureContext(opts.Context)); err != nil {
 			return nil, err
 		} else if len(code) == 0 {
 			return nil, bind.ErrNoCode
@@ -50,14 +149,136 @@ func (c *GasLimitEstimator) estimateGas(opts *bind.TransactOpts, contract *commo
 
 	// If the contract surely has code (or code is not needed), estimate the transaction
 	msg := ethereum.CallMsg{From: opts.From, To: contract, Value: value, Data: input}
-	gasLimit, err := c.transactor.EstimateGas(ensureContext(opts.Context), msg)
+	var gasLimit uint64
+	var err error
+	if c.rpc != nil {
+		gasLimit, err = c.estimateGasRPC(ensureContext(opts.Context), msg)
+	} else {
+		gasLimit, err = c.transactor.EstimateGas(ensureContext(opts.Context), msg)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to estimate gas needed: %v", err)
 	}
 
+	if c.cache != nil {
+		c.cache.set(cacheKey, gasLimit)
+	}
+
 	return new(big.Int).SetUint64(gasLimit), nil
 }
 
+func (c *GasLimitEstimator) estimateGasRPC(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	arg := map[string]interface{}{
+		"from": msg.From,
+	}
+	if msg.To != nil {
+		arg["to"] = msg.To
+	}
+	if len(msg.Data) > 0 {
+		arg["data"] = hexutil.Bytes(msg.Data)
+	}
+	if msg.Value != nil {
+		arg["value"] = (*hexutil.Big)(msg.Value)
+	}
+
+	args := []interface{}{arg, c.blockTag()}
+	if c.overrides != nil {
+		args = append(args, c.overrides)
+	}
+
+	var hex hexutil.Uint64
+	if err := c.rpc.CallContext(ctx, &hex, "eth_estimateGas", args...); err != nil {
+		return 0, err
+	}
+	return uint64(hex), nil
+}
+
+// codeAt returns the code of the contract in the state gas is estimated at: the pending state of the transactor,
+// or the block of RPC client with state overrides applied.
+func (c *GasLimitEstimator) codeAt(ctx context.Context) ([]byte, error) {
+	if c.rpc == nil {
+		return c.transactor.PendingCodeAt(ctx, c.address)
+	}
+	if o, ok := c.overrides[c.address]; ok && o.Code != nil {
+		return *o.Code, nil
+	}
+	var code hexutil.Bytes
+	if err := c.rpc.CallContext(ctx, &code, "eth_getCode", c.address, c.blockTag()); err != nil {
+		return nil, err
+	}
+	return code, nil
+}
+
+// blockTag returns the block gas is estimated at using RPC client.
+func (c *GasLimitEstimator) blockTag() string {
+	if c.block == "" {
+		return "pending"
+	}
+	return c.block
+}
+
+type gasLimitCache struct {
+	ttl       time.Duration
+	mu        sync.Mutex
+	entries   map[string]gasLimitCacheEntry
+	lastSweep time.Time
+}
+
+type gasLimitCacheEntry struct {
+	gasLimit uint64
+	expires  time.Time
+}
+
+func newGasLimitCache(ttl time.Duration) *gasLimitCache {
+	return &gasLimitCache{
+		ttl:       ttl,
+		entries:   make(map[string]gasLimitCacheEntry),
+		lastSweep: time.Now(),
+	}
+}
+
+func gasLimitCacheKey(from common.Address, contract *common.Address, value *big.Int, input []byte) string {
+	key := make([]byte, 0, 2*common.AddressLength+len(input)+32)
+	key = append(key, from.Bytes()...)
+	if contract != nil {
+		key = append(key, contract.Bytes()...)
+	} else {
+		key = append(key, make([]byte, common.AddressLength)...)
+	}
+	key = append(key, common.BigToHash(value).Bytes()...)
+	key = append(key, input...)
+	return string(key)
+}
+
+func (c *gasLimitCache) get(key string) (*big.Int, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok || time.Now().After(e.expires) {
+		return nil, false
+	}
+	return new(big.Int).SetUint64(e.gasLimit), true
+}
+
+func (c *gasLimitCache) set(key string, gasLimit uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(c.lastSweep) > c.ttl {
+		// remove expired entries
+		for k, e := range c.entries {
+			if now.After(e.expires) {
+				delete(c.entries, k)
+			}
+		}
+		c.lastSweep = now
+	}
+
+	c.entries[key] = gasLimitCacheEntry{gasLimit: gasLimit, expires: now.Add(c.ttl)}
+}
+
 func ensureContext(ctx context.Context) context.Context {
 	if ctx == nil {
 		return context.TODO()
@@ -0,0 +1,189 @@
+package gasestimator
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+func TestGasLimitEstimator_Cache(t *testing.T) {
+	contract := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	tr := &countingTransactor{gasLimit: 50000}
+	c := NewGasLimitEstimator(contract, abi.ABI{}, tr, WithCache(50*time.Millisecond))
+
+	opts := &bind.TransactOpts{From: common.HexToAddress("0x2222222222222222222222222222222222222222")}
+	input := []byte{1, 2, 3, 4}
+
+	for i := 0; i < 3; i++ {
+		gasLimit, err := c.estimateGas(opts, &contract, input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if gasLimit.Uint64() != 50000 {
+			t.Errorf("expected gas limit 50000, but got %v", gasLimit)
+		}
+	}
+	if tr.estimates != 1 {
+		t.Errorf("expected 1 EstimateGas call, but got %v", tr.estimates)
+	}
+
+	if _, err := c.estimateGas(opts, &contract, []byte{5}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.estimateGas(&bind.TransactOpts{From: opts.From, Value: big.NewInt(1)}, &contract, input); err != nil {
+		t.Fatal(err)
+	}
+	if tr.estimates != 3 {
+		t.Errorf("expected different calls not to be cached, but got %v EstimateGas calls", tr.estimates)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if _, err := c.estimateGas(opts, &contract, input); err != nil {
+		t.Fatal(err)
+	}
+	if tr.estimates != 4 {
+		t.Errorf("expected expired estimate to be requested again, but got %v EstimateGas calls", tr.estimates)
+	}
+}
+
+type countingTransactor struct {
+	bind.ContractTransactor
+	gasLimit  uint64
+	estimates int
+}
+
+func (t *countingTransactor) PendingCodeAt(ctx context.Context, account common.Address) ([]byte, error) {
+	return []byte{0}, nil
+}
+
+func (t *countingTransactor) EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error) {
+	t.estimates++
+	return t.gasLimit, nil
+}
+
+func TestGasLimitEstimator_fixedGasLimit(t *testing.T) {
+	table := map[string]uint64{"transfer": 60000, "approve": 45000}
+
+	tests := []struct {
+		method   string
+		headroom uint
+		gasLimit uint64
+		ok       bool
+	}{
+		{"transfer", 0, 60000, true},
+		{"transfer", 10, 66000, true},
+		{"approve", 20, 54000, true},
+		{"transferFrom", 10, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%v with %v%% headroom", tt.method, tt.headroom), func(t *testing.T) {
+			c := NewGasLimitEstimator(common.Address{}, abi.ABI{}, nil, WithGasLimitTable(table, tt.headroom))
+			gasLimit, ok := c.fixedGasLimit(tt.method)
+			if ok != tt.ok {
+				t.Fatalf("expected ok %v, but got %v", tt.ok, ok)
+			}
+			if ok && gasLimit.Uint64() != tt.gasLimit {
+				t.Errorf("expected gas limit %v, but got %v", tt.gasLimit, gasLimit)
+			}
+		})
+	}
+}
+
+func TestGasLimitEstimator_EstimateAtBlock(t *testing.T) {
+	contract := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	overrides := StateOverrides{contract: {Balance: (*hexutil.Big)(big.NewInt(1))}}
+
+	tests := []struct {
+		name      string
+		opt       func(rpc RPCCaller) GasLimitOption
+		block     string
+		overrides bool
+	}{
+		{"latest block", func(rpc RPCCaller) GasLimitOption { return WithBlockNumber(rpc, nil) }, "latest", false},
+		{"block number", func(rpc RPCCaller) GasLimitOption { return WithBlockNumber(rpc, big.NewInt(100)) }, "0x64", false},
+		{"block tag", func(rpc RPCCaller) GasLimitOption { return WithBlockNumber(rpc, big.NewInt(-3)) }, "finalized", false},
+		{"pending block with overrides", func(rpc RPCCaller) GasLimitOption { return WithStateOverrides(rpc, overrides) }, "pending", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			caller := &recordingCaller{code: hexutil.Bytes{1}}
+			c := NewGasLimitEstimator(contract, abi.ABI{}, &countingTransactor{}, tt.opt(caller))
+
+			gasLimit, err := c.estimateGas(&bind.TransactOpts{}, &contract, []byte{1})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if gasLimit.Uint64() != 30000 {
+				t.Errorf("expected gas limit 30000, but got %v", gasLimit)
+			}
+
+			if caller.codeBlock != tt.block {
+				t.Errorf("expected code at block %v, but got %v", tt.block, caller.codeBlock)
+			}
+			if caller.method != "eth_estimateGas" {
+				t.Errorf("expected eth_estimateGas, but got %v", caller.method)
+			}
+			expectedArgs := 2
+			if tt.overrides {
+				expectedArgs = 3
+			}
+			if len(caller.args) != expectedArgs {
+				t.Fatalf("expected %v arguments, but got %v", expectedArgs, len(caller.args))
+			}
+			if caller.args[1] != tt.block {
+				t.Errorf("expected block %v, but got %v", tt.block, caller.args[1])
+			}
+		})
+	}
+}
+
+// recordingCaller records arguments of eth_estimateGas, and the block of eth_getCode.
+type recordingCaller struct {
+	method    string
+	args      []interface{}
+	code      hexutil.Bytes
+	codeBlock interface{}
+}
+
+func (c *recordingCaller) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	if method == "eth_getCode" {
+		c.codeBlock = args[1]
+		*result.(*hexutil.Bytes) = c.code
+		return nil
+	}
+	c.method = method
+	c.args = args
+	*result.(*hexutil.Uint64) = 30000
+	return nil
+}
+
+func TestGasLimitEstimator_EstimateAtBlock_NoCode(t *testing.T) {
+	contract := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	code := hexutil.Bytes{1}
+
+	caller := &recordingCaller{}
+	c := NewGasLimitEstimator(contract, abi.ABI{}, &countingTransactor{}, WithBlockNumber(caller, big.NewInt(100)))
+	if _, err := c.estimateGas(&bind.TransactOpts{}, &contract, []byte{1}); err != bind.ErrNoCode {
+		t.Errorf("expected ErrNoCode when contract has no code at the block, but got %v", err)
+	}
+
+	// code of the contract may be overridden
+	caller = &recordingCaller{}
+	c = NewGasLimitEstimator(contract, abi.ABI{}, &countingTransactor{}, WithStateOverrides(caller, StateOverrides{contract: {Code: &code}}))
+	if _, err := c.estimateGas(&bind.TransactOpts{}, &contract, []byte{1}); err != nil {
+		t.Errorf("expected overridden code to be used, but got %v", err)
+	}
+	if caller.codeBlock != nil {
+		t.Errorf("expected code not to be requested, but got request at block %v", caller.codeBlock)
+	}
+}
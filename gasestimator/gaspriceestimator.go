@@ -10,53 +10,211 @@ import (
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/monetha/go-ethereum/events"
+	"github.com/monetha/go-ethereum/health"
+	"github.com/monetha/go-ethereum/metrics"
 )
 
 // GasPriceEstimator is the gas price estimator, it returns cached gas price to allow a timely
 // execution of a transaction.
 type GasPriceEstimator struct {
-	gasPrice       *big.Int
+	gasPrices      []*big.Int // indexed by Priority
 	gasPricer      ethereum.GasPricer
 	updateInterval time.Duration
+	tipBlocks      int
+	tipPercentile  float64
+	maxGasPrice    *big.Int
+	metrics        metrics.Collector
+	store          PriceStore
+	events         *events.Bus
+	spikePercent   int64
+	healthTimeout  time.Duration
+	lastSuccess    time.Time
 	rwMutex        sync.RWMutex
 	wg             sync.WaitGroup
 	closeOnce      sync.Once
 	closed         chan struct{}
+	closeClient    func() // closes the client dialed by the estimator, nil if the client is injected
 }
 
-// NewGasPriceEstimator creates an instance of GasPriceEstimator
-func NewGasPriceEstimator(rawRPCURL string) (*GasPriceEstimator, error) {
+// Option configures GasPriceEstimator.
+type Option func(*GasPriceEstimator)
+
+// WithMetrics sets the collector which receives gas price updates and RPC errors of GasPriceEstimator.
+func WithMetrics(c metrics.Collector) Option {
+	return func(e *GasPriceEstimator) {
+		if c != nil {
+			e.metrics = c
+		}
+	}
+}
+
+// WithHealthTimeout sets the maximum duration since the last successful gas price retrieval after which
+// GasPriceEstimator is considered unhealthy (one minute by default).
+func WithHealthTimeout(d time.Duration) Option {
+	return func(e *GasPriceEstimator) {
+		if d > 0 {
+			e.healthTimeout = d
+		}
+	}
+}
+
+// WithMaxGasPrice caps the gas price suggested by GasPriceEstimator, so a spike of the network fees doesn't drain
+// the accounts.
+func WithMaxGasPrice(maxGasPrice *big.Int) Option {
+	return func(e *GasPriceEstimator) {
+		if maxGasPrice != nil {
+			e.maxGasPrice = new(big.Int).Set(maxGasPrice)
+		}
+	}
+}
+
+// WithGasSpikeEvents sets the bus which receives GasSpike event when the gas price rises at least by the given
+// percentage at once.
+func WithGasSpikeEvents(bus *events.Bus, thresholdPercent int64) Option {
+	return func(e *GasPriceEstimator) {
+		if thresholdPercent > 0 {
+			e.events = bus
+			e.spikePercent = thresholdPercent
+		}
+	}
+}
+
+// NewGasPriceEstimator creates an instance of GasPriceEstimator. It fails when the gas price can't be retrieved,
+// unless the gas price saved to the store is available (see WithPriceStore). The dialed client is closed by Close.
+func NewGasPriceEstimator(rawRPCURL string, opts ...Option) (*GasPriceEstimator, error) {
 	cl, err := ethclient.Dial(rawRPCURL)
 	if err != nil {
 		return nil, fmt.Errorf("gasestimator: ethclient.Dial: %v", err)
 	}
 
-	gasPrice, err := cl.SuggestGasPrice(context.Background())
+	e, err := startGasPriceEstimator(context.Background(), cl, 4*time.Second, opts...)
+	return ownClient(e, err, cl.Close)
+}
+
+// NewGasPriceEstimatorWithClient creates an instance of GasPriceEstimator like NewGasPriceEstimator, but it uses
+// the given RPC client (e.g. returned by client.Client.RPC), so that one connection can be shared with other
+// components. The client isn't closed by Close.
+func NewGasPriceEstimatorWithClient(c *rpc.Client, opts ...Option) (*GasPriceEstimator, error) {
+	return startGasPriceEstimator(context.Background(), ethclient.NewClient(c), 4*time.Second, opts...)
+}
+
+// startGasPriceEstimator warm starts from the saved gas price and refreshes it immediately in the background,
+// otherwise it retrieves the initial gas price.
+func startGasPriceEstimator(ctx context.Context, gasPricer ethereum.GasPricer, updateInterval time.Duration, opts ...Option) (*GasPriceEstimator, error) {
+	estimator := newEstimator(nil, updateInterval, opts...)
+	if saved := estimator.loadSavedPrice(); saved != nil {
+		estimator.gasPricer = gasPricer
+		estimator.warmStart(saved)
+		estimator.runAsync(0)
+		return estimator, nil
+	}
+
+	gasPrice, err := gasPricer.SuggestGasPrice(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("gasestimator: SuggestGasPrice: %v", err)
 	}
 
-	return newGasPriceEstimator(gasPrice, cl, 4*time.Second), nil
+	return newGasPriceEstimator(gasPrice, gasPricer, updateInterval, opts...), nil
+}
+
+// ownClient makes Close of the estimator close the client dialed for it, the client is closed immediately when
+// the estimator isn't created.
+func ownClient(e *GasPriceEstimator, err error, closeClient func()) (*GasPriceEstimator, error) {
+	if err != nil {
+		closeClient()
+		return nil, err
+	}
+	e.closeClient = closeClient
+	return e, nil
+}
+
+func newGasPriceEstimator(initGasPrice *big.Int, gasPricer ethereum.GasPricer, updateInterval time.Duration, opts ...Option) *GasPriceEstimator {
+	estimator := newEstimator(initGasPrice, updateInterval, opts...)
+	estimator.gasPricer = gasPricer
+	estimator.metrics.GasPrice(initGasPrice)
+	estimator.savePrice(initGasPrice, nil)
+	estimator.runAsync(updateInterval)
+
+	return estimator
 }
 
-func newGasPriceEstimator(initGasPrice *big.Int, gasPricer ethereum.GasPricer, updateInterval time.Duration) *GasPriceEstimator {
+func newEstimator(initGasPrice *big.Int, updateInterval time.Duration, opts ...Option) *GasPriceEstimator {
 	estimator := &GasPriceEstimator{
-		gasPrice:       initGasPrice,
-		gasPricer:      gasPricer,
 		updateInterval: updateInterval,
+		metrics:        metrics.Nop,
+		healthTimeout:  time.Minute,
+		lastSuccess:    time.Now(),
+		tipBlocks:      DefaultTipBlocks,
+		tipPercentile:  DefaultTipPercentile,
 		closed:         make(chan struct{}),
 	}
-	estimator.runAsync()
+	if initGasPrice != nil {
+		estimator.gasPrices = priorityPrices(initGasPrice)
+	}
+	for _, opt := range opts {
+		opt(estimator)
+	}
 
 	return estimator
 }
 
-// Close implements io.Closer interface.
+// setGasPrices sets gas prices of all priority tiers.
+func (e *GasPriceEstimator) setGasPrices(prices []*big.Int) {
+	e.metrics.GasPrice(prices[PriorityStandard])
+
+	e.rwMutex.Lock()
+	prev := e.gasPrices
+	e.gasPrices = prices
+	e.lastSuccess = time.Now()
+	e.rwMutex.Unlock()
+
+	e.savePrice(prices[PriorityStandard], prev)
+
+	if e.events != nil && prev != nil {
+		previous, current := prev[PriorityStandard], prices[PriorityStandard]
+		threshold := new(big.Int).Mul(previous, big.NewInt(100+e.spikePercent))
+		if new(big.Int).Mul(current, big.NewInt(100)).Cmp(threshold) >= 0 {
+			e.events.Publish(events.GasSpike{Previous: previous, Current: current})
+		}
+	}
+}
+
+// Status returns the time of the last successful gas price retrieval.
+func (e *GasPriceEstimator) Status() health.Status {
+	e.rwMutex.RLock()
+	defer e.rwMutex.RUnlock()
+	return health.Status{LastSuccess: e.lastSuccess}
+}
+
+// Healthy implements health.Healthier interface.
+func (e *GasPriceEstimator) Healthy(ctx context.Context) error {
+	select {
+	case <-e.closed:
+		return &health.UnhealthyError{Component: "gasestimator", Reason: "closed", Status: e.Status()}
+	default:
+	}
+
+	st := e.Status()
+	if time.Since(st.LastSuccess) > e.healthTimeout {
+		return &health.UnhealthyError{Component: "gasestimator", Reason: "gas price is stale", Status: st}
+	}
+
+	return nil
+}
+
+// Close implements io.Closer interface. It stops updating the gas price and closes the client dialed by
+// NewGasPriceEstimator or NewHeadsGasPriceEstimator, the client passed to the estimator isn't closed.
 func (e *GasPriceEstimator) Close() (err error) {
 	e.closeOnce.Do(func() {
 		close(e.closed)
 
 		e.wg.Wait()
+
+		if e.closeClient != nil {
+			e.closeClient()
+		}
 	})
 
 	return
@@ -65,13 +223,39 @@ func (e *GasPriceEstimator) Close() (err error) {
 // SuggestGasPrice retrieves the currently suggested gas price to allow a timely
 // execution of a transaction.
 func (e *GasPriceEstimator) SuggestGasPrice() (gasPrice *big.Int) {
+	return e.SuggestGasPriceFor(PriorityStandard)
+}
+
+// SuggestGasPriceFor retrieves the currently suggested gas price of the priority tier, so the cost and the speed
+// can be chosen for every transaction. The polled gas price is lowered by 10% for PrioritySlow and raised by 20%
+// for PriorityFast and by 50% for PriorityUrgent. The estimator created with NewHeadsGasPriceEstimator uses
+// priority fees paid in recent blocks at the 10th, 75th and 95th percentiles instead. Unknown priority is treated
+// as PriorityStandard. Gas price doesn't exceed the cap set with WithMaxGasPrice.
+func (e *GasPriceEstimator) SuggestGasPriceFor(priority Priority) (gasPrice *big.Int) {
+	if priority < 0 || priority >= priorityCount {
+		priority = PriorityStandard
+	}
+
 	e.rwMutex.RLock()
-	gasPrice = new(big.Int).Set(e.gasPrice)
+	gasPrice = new(big.Int).Set(e.gasPrices[priority])
 	e.rwMutex.RUnlock()
+
+	if e.maxGasPrice != nil && gasPrice.Cmp(e.maxGasPrice) > 0 {
+		gasPrice.Set(e.maxGasPrice)
+	}
 	return
 }
 
-func (e *GasPriceEstimator) runAsync() {
+// MaxGasPrice returns the cap of suggested gas price (see WithMaxGasPrice), or nil when gas price isn't capped.
+func (e *GasPriceEstimator) MaxGasPrice() *big.Int {
+	if e.maxGasPrice == nil {
+		return nil
+	}
+	return new(big.Int).Set(e.maxGasPrice)
+}
+
+// runAsync polls the gas price, the first time after the given delay.
+func (e *GasPriceEstimator) runAsync(delay time.Duration) {
 	ctx, cancel := context.WithCancel(context.Background())
 	e.cancelOnClose(cancel)
 
@@ -83,21 +267,20 @@ func (e *GasPriceEstimator) runAsync() {
 			select {
 			case <-ctx.Done():
 				return
-			case <-time.After(e.updateInterval):
+			case <-time.After(delay):
 			}
+			delay = e.updateInterval
 
 			newGasPrice, err := e.gasPricer.SuggestGasPrice(ctx)
 			if err != nil {
+				if ctx.Err() != nil {
+					return // closed
+				}
 				log.Printf("gasestimator: SuggestGasPrice: %v", err)
+				e.metrics.RPCError("gasestimator", "eth_gasPrice")
 				continue
 			}
-			curGasPrice := e.SuggestGasPrice()
-
-			if curGasPrice.Cmp(newGasPrice) != 0 {
-				e.rwMutex.Lock()
-				e.gasPrice = newGasPrice
-				e.rwMutex.Unlock()
-			}
+			e.setGasPrices(priorityPrices(newGasPrice))
 		}
 	}()
 }
@@ -2,9 +2,14 @@ package gasestimator
 
 import (
 	"context"
+	"errors"
 	"math/big"
+	"sync"
 	"testing"
 	"time"
+
+	"github.com/monetha/go-ethereum/events"
+	"github.com/monetha/go-ethereum/metrics"
 )
 
 func TestClose(t *testing.T) {
@@ -14,6 +19,28 @@ func TestClose(t *testing.T) {
 	e.Close()
 }
 
+func TestOwnClient(t *testing.T) {
+	closed := 0
+	closeClient := func() { closed++ }
+
+	if _, err := ownClient(nil, errors.New("failed"), closeClient); err == nil {
+		t.Errorf("expected error, but got nil")
+	}
+	if closed != 1 {
+		t.Errorf("expected client to be closed when the estimator isn't created, but it's closed %v times", closed)
+	}
+
+	e, err := ownClient(newGasPriceEstimator(big.NewInt(1), newChanGasPrice(), time.Hour), nil, closeClient)
+	if err != nil {
+		t.Fatalf("expected no error, but got %v", err)
+	}
+	e.Close()
+	e.Close()
+	if closed != 2 {
+		t.Errorf("expected client to be closed once by Close, but it's closed %v times", closed-1)
+	}
+}
+
 func TestGasPriceEstimator_SuggestGasPrice(t *testing.T) {
 	gasPricer := newChanGasPrice()
 	e := newGasPriceEstimator(big.NewInt(1), gasPricer, 1*time.Microsecond)
@@ -40,6 +67,104 @@ func TestGasPriceEstimator_SuggestGasPrice(t *testing.T) {
 	}
 }
 
+func TestGasPriceEstimator_SuggestGasPriceFor(t *testing.T) {
+	e := newGasPriceEstimator(big.NewInt(1000), newChanGasPrice(), time.Hour)
+	defer e.Close()
+
+	tests := []struct {
+		priority Priority
+		expected int64
+	}{
+		{PrioritySlow, 900},
+		{PriorityStandard, 1000},
+		{PriorityFast, 1200},
+		{PriorityUrgent, 1500},
+		{Priority(42), 1000},
+	}
+
+	for _, tt := range tests {
+		if price := e.SuggestGasPriceFor(tt.priority); price.Int64() != tt.expected {
+			t.Errorf("%v: expected gas price %v, but got %v", tt.priority, tt.expected, price)
+		}
+	}
+
+	capped := newGasPriceEstimator(big.NewInt(1000), newChanGasPrice(), time.Hour, WithMaxGasPrice(big.NewInt(1100)))
+	defer capped.Close()
+
+	if price := capped.SuggestGasPriceFor(PriorityUrgent); price.Int64() != 1100 {
+		t.Errorf("expected capped gas price 1100, but got %v", price)
+	}
+	if price := capped.SuggestGasPrice(); price.Int64() != 1000 {
+		t.Errorf("expected gas price 1000, but got %v", price)
+	}
+}
+
+func TestGasPriceEstimator_Metrics(t *testing.T) {
+	gasPricer := newChanGasPrice()
+	m := &gasPriceMetrics{Collector: metrics.Nop}
+	e := newGasPriceEstimator(big.NewInt(1), gasPricer, 1*time.Microsecond, WithMetrics(m))
+	defer e.Close()
+
+	gasPricer.priceCh <- big.NewInt(2)
+	// second update needed to make sure background goroutine reported the first one
+	gasPricer.priceCh <- big.NewInt(3)
+
+	prices := m.Prices()
+	if len(prices) < 2 {
+		t.Fatalf("expected at least 2 reported prices, but got %v", len(prices))
+	}
+	if prices[0].Cmp(big.NewInt(1)) != 0 {
+		t.Errorf("expected initial price 1, but got %v", prices[0])
+	}
+	if prices[1].Cmp(big.NewInt(2)) != 0 {
+		t.Errorf("expected updated price 2, but got %v", prices[1])
+	}
+}
+
+func TestGasPriceEstimator_GasSpikeEvents(t *testing.T) {
+	bus := events.NewBus()
+	sub := bus.Subscribe(0, events.KindGasSpike)
+	defer sub.Unsubscribe()
+
+	gasPricer := newChanGasPrice()
+	e := newGasPriceEstimator(big.NewInt(100), gasPricer, 1*time.Microsecond, WithGasSpikeEvents(bus, 50))
+	defer e.Close()
+
+	gasPricer.priceCh <- big.NewInt(149) // below the threshold
+	gasPricer.priceCh <- big.NewInt(300)
+
+	select {
+	case ev := <-sub.C:
+		spike := ev.(events.GasSpike)
+		if spike.Previous.Int64() != 149 || spike.Current.Int64() != 300 {
+			t.Errorf("expected spike from 149 to 300, but got from %v to %v", spike.Previous, spike.Current)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("expected gas spike event")
+	}
+}
+
+func TestGasPriceEstimator_Healthy(t *testing.T) {
+	gasPricer := newChanGasPrice()
+	e := newGasPriceEstimator(big.NewInt(1), gasPricer, 1*time.Microsecond, WithHealthTimeout(time.Hour))
+
+	if err := e.Healthy(context.Background()); err != nil {
+		t.Errorf("expected healthy estimator, but got %v", err)
+	}
+
+	e.rwMutex.Lock()
+	e.lastSuccess = time.Now().Add(-2 * time.Hour)
+	e.rwMutex.Unlock()
+	if err := e.Healthy(context.Background()); err == nil {
+		t.Errorf("expected stale estimator to be unhealthy")
+	}
+
+	e.Close()
+	if err := e.Healthy(context.Background()); err == nil {
+		t.Errorf("expected closed estimator to be unhealthy")
+	}
+}
+
 var benchPrice *big.Int
 
 func BenchmarkGasPriceEstimator_SuggestGasPrice(b *testing.B) {
@@ -68,3 +193,21 @@ func (p chanGasPrice) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
 		return price, nil
 	}
 }
+
+type gasPriceMetrics struct {
+	metrics.Collector
+	mu     sync.Mutex
+	prices []*big.Int
+}
+
+func (m *gasPriceMetrics) GasPrice(price *big.Int) {
+	m.mu.Lock()
+	m.prices = append(m.prices, price)
+	m.mu.Unlock()
+}
+
+func (m *gasPriceMetrics) Prices() []*big.Int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*big.Int(nil), m.prices...)
+}
@@ -0,0 +1,167 @@
+package gasestimator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Urgency is the level of urgency of the transaction, higher urgency means higher fee.
+type Urgency int
+
+const (
+	// UrgencyLow is for transactions which can wait (e.g. sweeping funds).
+	UrgencyLow Urgency = iota
+	// UrgencyNormal is for transactions which should be mined in a few blocks.
+	UrgencyNormal
+	// UrgencyHigh is for transactions which should be mined in the next block.
+	UrgencyHigh
+)
+
+func (u Urgency) String() string {
+	switch u {
+	case UrgencyLow:
+		return "low"
+	case UrgencyNormal:
+		return "normal"
+	case UrgencyHigh:
+		return "high"
+	default:
+		return fmt.Sprintf("urgency(%d)", int(u))
+	}
+}
+
+// GasStrategy decides on the gas price of the transaction given its urgency and the number of the sending attempt
+// (0 for the first attempt, it's increased every time the transaction is replaced because it's stuck).
+type GasStrategy interface {
+	GasPrice(ctx context.Context, urgency Urgency, attempt int) (*big.Int, error)
+}
+
+// GasStrategyFunc is an adapter to allow the use of ordinary functions as GasStrategy.
+type GasStrategyFunc func(ctx context.Context, urgency Urgency, attempt int) (*big.Int, error)
+
+// GasPrice calls f(ctx, urgency, attempt).
+func (f GasStrategyFunc) GasPrice(ctx context.Context, urgency Urgency, attempt int) (*big.Int, error) {
+	return f(ctx, urgency, attempt)
+}
+
+// urgencyPercent is the percentage of suggested gas price used for the urgency level.
+var urgencyPercent = map[Urgency]int64{
+	UrgencyLow:    90,
+	UrgencyNormal: 100,
+	UrgencyHigh:   125,
+}
+
+// SuggestedStrategy uses the gas price suggested by the node (e.g. ethclient.Client or GasPriceEstimator wrapped
+// with SuggesterGasPricer), which is lowered by 10% for low urgency and raised by 25% for high urgency.
+func SuggestedStrategy(gasPricer ethereum.GasPricer) GasStrategy {
+	return GasStrategyFunc(func(ctx context.Context, urgency Urgency, attempt int) (*big.Int, error) {
+		gasPrice, err := gasPricer.SuggestGasPrice(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("gasestimator: SuggestGasPrice: %v", err)
+		}
+		percent, ok := urgencyPercent[urgency]
+		if !ok {
+			percent = 100
+		}
+		return percentOf(gasPrice, percent), nil
+	})
+}
+
+// FixedStrategy always uses the given gas price.
+func FixedStrategy(gasPrice *big.Int) GasStrategy {
+	return GasStrategyFunc(func(ctx context.Context, urgency Urgency, attempt int) (*big.Int, error) {
+		return new(big.Int).Set(gasPrice), nil
+	})
+}
+
+// DefaultFeeHistoryPercentiles are the reward percentiles used by FeeHistoryStrategy for every urgency level.
+var DefaultFeeHistoryPercentiles = map[Urgency]float64{
+	UrgencyLow:    10,
+	UrgencyNormal: 50,
+	UrgencyHigh:   90,
+}
+
+// FeeHistoryStrategy uses eth_feeHistory of the last `blocks` blocks: gas price is the base fee of the next block
+// plus median of priority fees paid at the percentile of the urgency level (see DefaultFeeHistoryPercentiles,
+// which is used when percentiles is nil).
+func FeeHistoryStrategy(rpc RPCCaller, blocks int, percentiles map[Urgency]float64) GasStrategy {
+	if percentiles == nil {
+		percentiles = DefaultFeeHistoryPercentiles
+	}
+	return GasStrategyFunc(func(ctx context.Context, urgency Urgency, attempt int) (*big.Int, error) {
+		percentile, ok := percentiles[urgency]
+		if !ok {
+			percentile = 50
+		}
+
+		var res struct {
+			BaseFeePerGas []*hexutil.Big   `json:"baseFeePerGas"`
+			Reward        [][]*hexutil.Big `json:"reward"`
+		}
+		if err := rpc.CallContext(ctx, &res, "eth_feeHistory", hexutil.Uint(blocks), "latest", []float64{percentile}); err != nil {
+			return nil, fmt.Errorf("gasestimator: eth_feeHistory: %v", err)
+		}
+		if len(res.BaseFeePerGas) == 0 {
+			return nil, errors.New("gasestimator: eth_feeHistory: no base fee")
+		}
+
+		var rewards []*big.Int
+		for _, r := range res.Reward {
+			if len(r) > 0 && r[0] != nil {
+				rewards = append(rewards, r[0].ToInt())
+			}
+		}
+		tip := new(big.Int)
+		if len(rewards) > 0 {
+			sort.Slice(rewards, func(i, j int) bool { return rewards[i].Cmp(rewards[j]) < 0 })
+			tip = rewards[len(rewards)/2]
+		}
+
+		// the last base fee is the one of the next block
+		baseFee := res.BaseFeePerGas[len(res.BaseFeePerGas)-1].ToInt()
+		return new(big.Int).Add(baseFee, tip), nil
+	})
+}
+
+// BumpOnRetryStrategy raises the gas price of the inner strategy by `percent` for every retry attempt,
+// compounding. Nodes require at least 10% bump to replace pending transaction.
+func BumpOnRetryStrategy(inner GasStrategy, percent int64) GasStrategy {
+	return GasStrategyFunc(func(ctx context.Context, urgency Urgency, attempt int) (*big.Int, error) {
+		gasPrice, err := inner.GasPrice(ctx, urgency, attempt)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < attempt; i++ {
+			bumped := percentOf(gasPrice, 100+percent)
+			if bumped.Cmp(gasPrice) <= 0 {
+				bumped.Add(gasPrice, big.NewInt(1))
+			}
+			gasPrice = bumped
+		}
+		return gasPrice, nil
+	})
+}
+
+// SuggesterGasPricer adapts GasPriceEstimator (or another cached gas price suggester) to ethereum.GasPricer,
+// so it can be used with SuggestedStrategy.
+type SuggesterGasPricer struct {
+	Suggester interface {
+		SuggestGasPrice() *big.Int
+	}
+}
+
+// SuggestGasPrice implements ethereum.GasPricer interface.
+func (p SuggesterGasPricer) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return p.Suggester.SuggestGasPrice(), nil
+}
+
+func percentOf(v *big.Int, percent int64) *big.Int {
+	res := new(big.Int).Mul(v, big.NewInt(percent))
+	return res.Div(res, big.NewInt(100))
+}
@@ -0,0 +1,61 @@
+package gasestimator
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+)
+
+type fixedGasPricer struct{ gasPrice *big.Int }
+
+func (p fixedGasPricer) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return new(big.Int).Set(p.gasPrice), nil
+}
+
+type feeHistoryCaller struct {
+	response string
+	method   string
+}
+
+func (c *feeHistoryCaller) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	c.method = method
+	return json.Unmarshal([]byte(c.response), result)
+}
+
+func TestGasStrategies(t *testing.T) {
+	feeHistory := &feeHistoryCaller{response: `{"baseFeePerGas":["0x64","0x6e"],"reward":[["0x5"],["0x1"],["0x3"]]}`}
+
+	tests := []struct {
+		name     string
+		strategy GasStrategy
+		urgency  Urgency
+		attempt  int
+		expected int64
+	}{
+		{"suggested normal", SuggestedStrategy(fixedGasPricer{big.NewInt(100)}), UrgencyNormal, 0, 100},
+		{"suggested low", SuggestedStrategy(fixedGasPricer{big.NewInt(100)}), UrgencyLow, 0, 90},
+		{"suggested high", SuggestedStrategy(fixedGasPricer{big.NewInt(100)}), UrgencyHigh, 0, 125},
+		{"fixed", FixedStrategy(big.NewInt(42)), UrgencyHigh, 3, 42},
+		{"fee history", FeeHistoryStrategy(feeHistory, 3, nil), UrgencyNormal, 0, 113},
+		{"bump first attempt", BumpOnRetryStrategy(FixedStrategy(big.NewInt(100)), 10), UrgencyNormal, 0, 100},
+		{"bump second retry", BumpOnRetryStrategy(FixedStrategy(big.NewInt(100)), 10), UrgencyNormal, 2, 121},
+		{"bump small price", BumpOnRetryStrategy(FixedStrategy(big.NewInt(1)), 10), UrgencyNormal, 2, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gasPrice, err := tt.strategy.GasPrice(context.Background(), tt.urgency, tt.attempt)
+			if err != nil {
+				t.Fatalf("GasPrice: %v", err)
+			}
+			if gasPrice.Int64() != tt.expected {
+				t.Errorf("expected gas price %v, but got %v", tt.expected, gasPrice)
+			}
+		})
+	}
+
+	if feeHistory.method != "eth_feeHistory" {
+		t.Errorf("expected eth_feeHistory to be called, but got %v", feeHistory.method)
+	}
+}
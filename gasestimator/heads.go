@@ -0,0 +1,314 @@
+package gasestimator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// DefaultTipBlocks is the number of recent blocks whose priority fees are used by the estimator created with
+	// NewHeadsGasPriceEstimator.
+	DefaultTipBlocks = 20
+	// DefaultTipPercentile is the percentile of priority fees paid in a block used by the estimator created with
+	// NewHeadsGasPriceEstimator.
+	DefaultTipPercentile = 50
+)
+
+// EIP-1559 parameters
+const (
+	elasticityMultiplier     = 2
+	baseFeeChangeDenominator = 8
+)
+
+// HeadSubscriber subscribes to new heads and calls RPC methods, it's implemented by *rpc.Client connected
+// with WebSocket or IPC.
+type HeadSubscriber interface {
+	RPCCaller
+	EthSubscribe(ctx context.Context, channel interface{}, args ...interface{}) (*rpc.ClientSubscription, error)
+}
+
+// WithTipHistory sets the number of recent blocks and the percentile of priority fees paid in every block
+// used by the estimator created with NewHeadsGasPriceEstimator (see DefaultTipBlocks and DefaultTipPercentile).
+func WithTipHistory(blocks int, percentile float64) Option {
+	return func(e *GasPriceEstimator) {
+		if blocks > 0 {
+			e.tipBlocks = blocks
+		}
+		if percentile >= 0 && percentile <= 100 {
+			e.tipPercentile = percentile
+		}
+	}
+}
+
+// NewHeadsGasPriceEstimator creates an instance of GasPriceEstimator which updates the gas price on every new
+// block received with newHeads subscription, instead of polling eth_gasPrice, so it reacts to base fee spikes
+// without delay. The gas price is the base fee of the next block (calculated from the head) plus the median of
+// priority fees paid in recent blocks at the configured percentile (see WithTipHistory). eth_gasPrice is used
+// for chains without EIP-1559. The endpoint must support subscriptions (WebSocket or IPC), the subscription
+// is renewed after it fails. The dialed client is closed by Close.
+func NewHeadsGasPriceEstimator(rawRPCURL string, opts ...Option) (*GasPriceEstimator, error) {
+	cl, err := rpc.Dial(rawRPCURL)
+	if err != nil {
+		return nil, fmt.Errorf("gasestimator: rpc.Dial: %v", err)
+	}
+
+	e, err := newHeadsGasPriceEstimator(context.Background(), cl, subscribeHeads(cl), 4*time.Second, opts...)
+	return ownClient(e, err, cl.Close)
+}
+
+// NewHeadsGasPriceEstimatorWithClient creates an instance of GasPriceEstimator like NewHeadsGasPriceEstimator,
+// but it uses the given client (e.g. *rpc.Client returned by client.Client.RPC), so that one connection can be
+// shared with other components. The client isn't closed by Close.
+func NewHeadsGasPriceEstimatorWithClient(s HeadSubscriber, opts ...Option) (*GasPriceEstimator, error) {
+	return newHeadsGasPriceEstimator(context.Background(), s, subscribeHeads(s), 4*time.Second, opts...)
+}
+
+// headsSubscribeFunc subscribes to new heads.
+type headsSubscribeFunc func(ctx context.Context, ch chan<- *rpcHead) (ethereum.Subscription, error)
+
+func subscribeHeads(s HeadSubscriber) headsSubscribeFunc {
+	return func(ctx context.Context, ch chan<- *rpcHead) (ethereum.Subscription, error) {
+		return s.EthSubscribe(ctx, ch, "newHeads")
+	}
+}
+
+func newHeadsGasPriceEstimator(ctx context.Context, caller RPCCaller, subscribe headsSubscribeFunc, resubscribeDelay time.Duration, opts ...Option) (*GasPriceEstimator, error) {
+	e := newEstimator(nil, resubscribeDelay, opts...)
+	h := newHeadFees(caller, e.tipBlocks, e.tipPercentile)
+
+	if saved := e.loadSavedPrice(); saved != nil {
+		e.warmStart(saved)
+		e.runHeads(subscribe, h, false)
+		return e, nil
+	}
+
+	prices, err := h.init(ctx)
+	if err != nil {
+		return nil, err
+	}
+	e.gasPrices = prices
+	e.metrics.GasPrice(prices[PriorityStandard])
+	e.savePrice(prices[PriorityStandard], nil)
+
+	e.runHeads(subscribe, h, true)
+	return e, nil
+}
+
+// runHeads updates the gas price on every new head, fees of recent blocks are retrieved first unless h is initialized.
+func (e *GasPriceEstimator) runHeads(subscribe headsSubscribeFunc, h *headFees, initialized bool) {
+	ctx, cancel := context.WithCancel(context.Background())
+	e.cancelOnClose(cancel)
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+
+		if !initialized {
+			prices, err := h.init(ctx)
+			switch {
+			case ctx.Err() != nil:
+				return // closed
+			case err != nil:
+				log.Printf("%v", err)
+				e.metrics.RPCError("gasestimator", "eth_gasPrice")
+			default:
+				e.setGasPrices(prices)
+			}
+		}
+
+		for {
+			ch := make(chan *rpcHead, 16)
+			sub, err := subscribe(ctx, ch)
+			if err == nil {
+				err = e.consumeHeads(ctx, sub, ch, h)
+				sub.Unsubscribe()
+			}
+			if ctx.Err() != nil {
+				return // closed
+			}
+			log.Printf("gasestimator: newHeads subscription: %v", err)
+			e.metrics.RPCError("gasestimator", "eth_subscribe")
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(e.updateInterval):
+			}
+		}
+	}()
+}
+
+// consumeHeads updates the gas price on every head until the subscription fails or the context is done.
+func (e *GasPriceEstimator) consumeHeads(ctx context.Context, sub ethereum.Subscription, ch <-chan *rpcHead, h *headFees) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-sub.Err():
+			if err == nil {
+				err = errors.New("subscription closed")
+			}
+			return err
+		case head := <-ch:
+			prices, method, err := h.update(ctx, head)
+			if err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				log.Printf("gasestimator: %v: %v", method, err)
+				e.metrics.RPCError("gasestimator", method)
+				continue
+			}
+			e.setGasPrices(prices)
+		}
+	}
+}
+
+type rpcHead struct {
+	Number   *hexutil.Big   `json:"number"`
+	BaseFee  *hexutil.Big   `json:"baseFeePerGas"`
+	GasUsed  hexutil.Uint64 `json:"gasUsed"`
+	GasLimit hexutil.Uint64 `json:"gasLimit"`
+}
+
+// headFees tracks the base fee of the next block and priority fees of recent blocks at percentiles of all priority
+// tiers. It's used by one goroutine.
+type headFees struct {
+	rpc         RPCCaller
+	blocks      int
+	percentiles []float64    // indexed by Priority
+	tips        [][]*big.Int // indexed by Priority, of recent blocks, oldest first
+	nextBaseFee *big.Int     // nil for chains without EIP-1559
+}
+
+func newHeadFees(rpc RPCCaller, blocks int, percentile float64) *headFees {
+	return &headFees{
+		rpc:         rpc,
+		blocks:      blocks,
+		percentiles: tipPercentiles(percentile),
+		tips:        make([][]*big.Int, priorityCount),
+	}
+}
+
+// init fills fees of recent blocks and returns gas prices of all priority tiers.
+func (h *headFees) init(ctx context.Context) ([]*big.Int, error) {
+	var res struct {
+		BaseFeePerGas []*hexutil.Big   `json:"baseFeePerGas"`
+		Reward        [][]*hexutil.Big `json:"reward"`
+	}
+	err := h.rpc.CallContext(ctx, &res, "eth_feeHistory", hexutil.Uint(h.blocks), "latest", h.percentiles)
+	if err != nil || len(res.BaseFeePerGas) == 0 || res.BaseFeePerGas[len(res.BaseFeePerGas)-1] == nil {
+		// chain without EIP-1559
+		return h.legacyGasPrices(ctx)
+	}
+
+	for _, r := range res.Reward {
+		h.addTips(r)
+	}
+	// the last base fee is the one of the next block
+	h.nextBaseFee = res.BaseFeePerGas[len(res.BaseFeePerGas)-1].ToInt()
+	return h.gasPrices(), nil
+}
+
+// update adds the head and returns gas prices of all priority tiers, or the failed RPC method and its error.
+func (h *headFees) update(ctx context.Context, head *rpcHead) ([]*big.Int, string, error) {
+	if head == nil || head.BaseFee == nil || head.Number == nil {
+		h.nextBaseFee = nil
+		prices, err := h.legacyGasPrices(ctx)
+		return prices, "eth_gasPrice", err
+	}
+	h.nextBaseFee = NextBaseFee(head.BaseFee.ToInt(), uint64(head.GasUsed), uint64(head.GasLimit))
+
+	var res struct {
+		Reward [][]*hexutil.Big `json:"reward"`
+	}
+	err := h.rpc.CallContext(ctx, &res, "eth_feeHistory", hexutil.Uint(1), head.Number, h.percentiles)
+	if err != nil {
+		// base fee is still updated, priority fees of older blocks are used
+		return h.gasPrices(), "eth_feeHistory", err
+	}
+	if len(res.Reward) > 0 {
+		h.addTips(res.Reward[0])
+	}
+
+	return h.gasPrices(), "", nil
+}
+
+// legacyGasPrices returns eth_gasPrice multiplied for all priority tiers.
+func (h *headFees) legacyGasPrices(ctx context.Context) ([]*big.Int, error) {
+	var gasPrice hexutil.Big
+	if err := h.rpc.CallContext(ctx, &gasPrice, "eth_gasPrice"); err != nil {
+		return nil, fmt.Errorf("gasestimator: eth_gasPrice: %v", err)
+	}
+	return priorityPrices(gasPrice.ToInt()), nil
+}
+
+// addTips adds priority fees paid in a block at percentiles of all priority tiers.
+func (h *headFees) addTips(reward []*hexutil.Big) {
+	for p := range h.tips {
+		if p >= len(reward) || reward[p] == nil {
+			continue
+		}
+		tips := append(h.tips[p], reward[p].ToInt())
+		if len(tips) > h.blocks {
+			tips = append([]*big.Int(nil), tips[len(tips)-h.blocks:]...)
+		}
+		h.tips[p] = tips
+	}
+}
+
+// gasPrices returns the base fee of the next block plus the median of recent priority fees for every priority tier.
+func (h *headFees) gasPrices() []*big.Int {
+	prices := make([]*big.Int, len(h.tips))
+	for p, tips := range h.tips {
+		tip := new(big.Int)
+		if len(tips) > 0 {
+			sorted := append([]*big.Int(nil), tips...)
+			sort.Slice(sorted, func(i, j int) bool { return sorted[i].Cmp(sorted[j]) < 0 })
+			tip = sorted[len(sorted)/2]
+		}
+		prices[p] = new(big.Int).Add(h.nextBaseFee, tip)
+	}
+	return prices
+}
+
+// NextBaseFee calculates the base fee of the next block given the base fee, gas used and gas limit of the parent
+// block (EIP-1559).
+func NextBaseFee(baseFee *big.Int, gasUsed, gasLimit uint64) *big.Int {
+	target := gasLimit / elasticityMultiplier
+	if target == 0 || gasUsed == target {
+		return new(big.Int).Set(baseFee)
+	}
+
+	var delta uint64
+	if gasUsed > target {
+		delta = gasUsed - target
+	} else {
+		delta = target - gasUsed
+	}
+	change := new(big.Int).Mul(baseFee, new(big.Int).SetUint64(delta))
+	change.Div(change, new(big.Int).SetUint64(target))
+	change.Div(change, big.NewInt(baseFeeChangeDenominator))
+
+	if gasUsed > target {
+		if change.Sign() == 0 {
+			change.SetInt64(1)
+		}
+		return change.Add(baseFee, change)
+	}
+
+	next := change.Sub(baseFee, change)
+	if next.Sign() < 0 {
+		next.SetInt64(0)
+	}
+	return next
+}
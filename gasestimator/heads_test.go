@@ -0,0 +1,139 @@
+package gasestimator
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/monetha/go-ethereum/metrics"
+)
+
+type methodCaller map[string]string
+
+func (c methodCaller) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	return json.Unmarshal([]byte(c[method]), result)
+}
+
+type chanSubscription struct{ err chan error }
+
+func (s chanSubscription) Unsubscribe()      {}
+func (s chanSubscription) Err() <-chan error { return s.err }
+
+func TestNextBaseFee(t *testing.T) {
+	tests := []struct {
+		name     string
+		gasUsed  uint64
+		expected int64
+	}{
+		{"target", 15000000, 1000000000},
+		{"full", 30000000, 1125000000},
+		{"empty", 0, 875000000},
+		{"above target", 22500000, 1062500000},
+	}
+
+	for _, tt := range tests {
+		if fee := NextBaseFee(big.NewInt(1000000000), tt.gasUsed, 30000000); fee.Int64() != tt.expected {
+			t.Errorf("%v: expected base fee %v, but got %v", tt.name, tt.expected, fee)
+		}
+	}
+
+	if fee := NextBaseFee(big.NewInt(7), 15000001, 30000000); fee.Int64() != 8 {
+		t.Errorf("expected base fee to increase at least by 1, but got %v", fee)
+	}
+}
+
+func TestHeadFees(t *testing.T) {
+	caller := methodCaller{"eth_feeHistory": `{"baseFeePerGas":["0x64","0x64","0x64","0x6e"],"reward":[["0x1","0x5","0x7","0x9"],["0x0","0x1","0x2","0x3"],["0x1","0x3","0x4","0x8"]]}`}
+	h := newHeadFees(caller, 3, 50)
+
+	prices, err := h.init(context.Background())
+	if err != nil {
+		t.Fatalf("init: unexpected error: %v", err)
+	}
+	if gasPrice := prices[PriorityStandard]; gasPrice.Int64() != 113 {
+		t.Errorf("expected gas price 113, but got %v", gasPrice)
+	}
+
+	caller["eth_feeHistory"] = `{"reward":[["0x2","0x9","0xa","0xf"]]}`
+	head := &rpcHead{Number: (*hexutil.Big)(big.NewInt(10)), BaseFee: (*hexutil.Big)(big.NewInt(800)), GasUsed: 30, GasLimit: 30}
+	prices, _, err = h.update(context.Background(), head)
+	if err != nil {
+		t.Fatalf("update: unexpected error: %v", err)
+	}
+	// next base fee is 900, standard tips are 1, 3 and 9
+	for p, expected := range []int64{901, 903, 904, 908} {
+		if gasPrice := prices[p]; gasPrice.Int64() != expected {
+			t.Errorf("%v: expected gas price %v, but got %v", Priority(p), expected, gasPrice)
+		}
+	}
+
+	caller["eth_gasPrice"] = `"0x64"`
+	prices, _, err = h.update(context.Background(), &rpcHead{Number: (*hexutil.Big)(big.NewInt(11))})
+	if err != nil {
+		t.Fatalf("update: unexpected error: %v", err)
+	}
+	for p, expected := range []int64{90, 100, 120, 150} {
+		if gasPrice := prices[p]; gasPrice.Int64() != expected {
+			t.Errorf("%v: expected legacy gas price %v, but got %v", Priority(p), expected, gasPrice)
+		}
+	}
+}
+
+func TestTipPercentiles(t *testing.T) {
+	tests := []struct {
+		standard float64
+		expected []float64
+	}{
+		{50, []float64{10, 50, 75, 95}},
+		{5, []float64{5, 5, 75, 95}},
+		{80, []float64{10, 80, 80, 95}},
+		{100, []float64{10, 100, 100, 100}},
+	}
+
+	for _, tt := range tests {
+		if percentiles := tipPercentiles(tt.standard); !reflect.DeepEqual(percentiles, tt.expected) {
+			t.Errorf("%v: expected percentiles %v, but got %v", tt.standard, tt.expected, percentiles)
+		}
+	}
+}
+
+func TestHeadsGasPriceEstimator(t *testing.T) {
+	caller := methodCaller{"eth_feeHistory": `{"baseFeePerGas":["0x64"],"reward":[]}`}
+	heads := make(chan chan<- *rpcHead, 1)
+	subscribe := func(ctx context.Context, ch chan<- *rpcHead) (ethereum.Subscription, error) {
+		heads <- ch
+		return chanSubscription{err: make(chan error)}, nil
+	}
+
+	m := &gasPriceMetrics{Collector: metrics.Nop}
+	e, err := newHeadsGasPriceEstimator(context.Background(), caller, subscribe, time.Millisecond, WithMetrics(m))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer e.Close()
+
+	if gasPrice := e.SuggestGasPrice(); gasPrice.Int64() != 100 {
+		t.Errorf("expected initial gas price 100, but got %v", gasPrice)
+	}
+
+	caller["eth_feeHistory"] = `{"reward":[["0x0"]]}`
+	ch := <-heads
+	ch <- &rpcHead{Number: (*hexutil.Big)(big.NewInt(1)), BaseFee: (*hexutil.Big)(big.NewInt(800)), GasUsed: 30, GasLimit: 30}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for e.SuggestGasPrice().Int64() != 900 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected gas price 900, but got %v", e.SuggestGasPrice())
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if prices := m.Prices(); len(prices) != 2 || prices[1].Int64() != 900 {
+		t.Errorf("expected gas prices [100 900] in metrics, but got %v", prices)
+	}
+}
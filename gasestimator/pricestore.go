@@ -0,0 +1,158 @@
+package gasestimator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+)
+
+// ErrNoSavedPrice is returned by PriceStore when gas price wasn't saved yet.
+var ErrNoSavedPrice = errors.New("gasestimator: no saved gas price")
+
+// SavedPrice is the last known gas price persisted by GasPriceEstimator.
+type SavedPrice struct {
+	GasPrice *big.Int  `json:"gasPrice"`
+	SavedAt  time.Time `json:"savedAt"`
+}
+
+// PriceStore persists the last known gas price, so GasPriceEstimator can start serving it immediately on restart
+// (see WithPriceStore).
+type PriceStore interface {
+	Save(p SavedPrice) error
+	Load() (SavedPrice, error)
+}
+
+// WithPriceStore makes GasPriceEstimator save every new gas price to the store and warm start from the saved gas
+// price: it's served immediately while the gas price is refreshed in the background, so the estimator is created
+// even when the node is temporarily unavailable. The estimator stays unhealthy until the saved gas price is
+// refreshed when it's older than the health timeout.
+func WithPriceStore(s PriceStore) Option {
+	return func(e *GasPriceEstimator) {
+		e.store = s
+	}
+}
+
+// MemoryPriceStore keeps the gas price in memory. It's useful for tests.
+type MemoryPriceStore struct {
+	mu    sync.RWMutex
+	price *SavedPrice
+}
+
+// NewMemoryPriceStore creates an instance of MemoryPriceStore.
+func NewMemoryPriceStore() *MemoryPriceStore {
+	return &MemoryPriceStore{}
+}
+
+// Save implements PriceStore interface.
+func (s *MemoryPriceStore) Save(p SavedPrice) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p.GasPrice = new(big.Int).Set(p.GasPrice)
+	s.price = &p
+	return nil
+}
+
+// Load implements PriceStore interface.
+func (s *MemoryPriceStore) Load() (SavedPrice, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.price == nil {
+		return SavedPrice{}, ErrNoSavedPrice
+	}
+	p := *s.price
+	p.GasPrice = new(big.Int).Set(p.GasPrice)
+	return p, nil
+}
+
+// FilePriceStore keeps the gas price in a JSON file.
+type FilePriceStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFilePriceStore creates an instance of FilePriceStore which keeps the gas price in the file at the given path.
+func NewFilePriceStore(path string) *FilePriceStore {
+	return &FilePriceStore{path: path}
+}
+
+// Save implements PriceStore interface. Gas price is written to a temporary file first, so it's never left
+// half-written.
+func (s *FilePriceStore) Save(p SavedPrice) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, err := json.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("gasestimator: %v", err)
+	}
+
+	tmp := s.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, b, 0600); err != nil {
+		return fmt.Errorf("gasestimator: %v", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("gasestimator: %v", err)
+	}
+	return nil
+}
+
+// Load implements PriceStore interface.
+func (s *FilePriceStore) Load() (SavedPrice, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return SavedPrice{}, ErrNoSavedPrice
+	}
+	if err != nil {
+		return SavedPrice{}, fmt.Errorf("gasestimator: %v", err)
+	}
+
+	var p SavedPrice
+	if err := json.Unmarshal(b, &p); err != nil {
+		return SavedPrice{}, fmt.Errorf("gasestimator: %v: %v", s.path, err)
+	}
+	if p.GasPrice == nil {
+		return SavedPrice{}, ErrNoSavedPrice
+	}
+	return p, nil
+}
+
+// loadSavedPrice returns the saved gas price, or nil when the store isn't set or gas price can't be loaded.
+func (e *GasPriceEstimator) loadSavedPrice() *SavedPrice {
+	if e.store == nil {
+		return nil
+	}
+	p, err := e.store.Load()
+	if err != nil {
+		if err != ErrNoSavedPrice {
+			log.Printf("gasestimator: loading saved gas price: %v", err)
+		}
+		return nil
+	}
+	return &p
+}
+
+// warmStart serves the saved gas price.
+func (e *GasPriceEstimator) warmStart(p *SavedPrice) {
+	e.gasPrices = priorityPrices(p.GasPrice)
+	e.lastSuccess = p.SavedAt
+	e.metrics.GasPrice(p.GasPrice)
+}
+
+// savePrice saves the gas price when it's changed.
+func (e *GasPriceEstimator) savePrice(gasPrice *big.Int, prev []*big.Int) {
+	if e.store == nil || (prev != nil && prev[PriorityStandard].Cmp(gasPrice) == 0) {
+		return
+	}
+	if err := e.store.Save(SavedPrice{GasPrice: gasPrice, SavedAt: time.Now()}); err != nil {
+		log.Printf("gasestimator: saving gas price: %v", err)
+	}
+}
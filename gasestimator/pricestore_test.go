@@ -0,0 +1,91 @@
+package gasestimator
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFilePriceStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gasestimator")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s := NewFilePriceStore(filepath.Join(dir, "gasprice.json"))
+	if _, err := s.Load(); err != ErrNoSavedPrice {
+		t.Fatalf("expected error %v, but got %v", ErrNoSavedPrice, err)
+	}
+
+	savedAt := time.Date(2019, 5, 1, 12, 0, 0, 0, time.UTC)
+	if err := s.Save(SavedPrice{GasPrice: big.NewInt(1000000000), SavedAt: savedAt}); err != nil {
+		t.Fatalf("Save: unexpected error: %v", err)
+	}
+
+	p, err := s.Load()
+	if err != nil {
+		t.Fatalf("Load: unexpected error: %v", err)
+	}
+	if p.GasPrice.Int64() != 1000000000 || !p.SavedAt.Equal(savedAt) {
+		t.Errorf("expected gas price 1000000000 saved at %v, but got %v saved at %v", savedAt, p.GasPrice, p.SavedAt)
+	}
+}
+
+type errGasPricer struct{}
+
+func (errGasPricer) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestGasPriceEstimator_WarmStart(t *testing.T) {
+	if _, err := startGasPriceEstimator(context.Background(), errGasPricer{}, time.Hour); err == nil {
+		t.Fatalf("expected error without saved gas price")
+	}
+
+	store := NewMemoryPriceStore()
+	if err := store.Save(SavedPrice{GasPrice: big.NewInt(7), SavedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+
+	e, err := startGasPriceEstimator(context.Background(), errGasPricer{}, time.Hour, WithPriceStore(store))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if price := e.SuggestGasPrice(); price.Int64() != 7 {
+		t.Errorf("expected saved gas price 7, but got %v", price)
+	}
+	if err := e.Healthy(context.Background()); err != nil {
+		t.Errorf("expected healthy estimator, but got %v", err)
+	}
+	e.Close()
+
+	gasPricer := newChanGasPrice()
+	e, err = startGasPriceEstimator(context.Background(), gasPricer, time.Hour, WithPriceStore(store))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer e.Close()
+
+	// gas price is refreshed immediately, not after the update interval, and saved
+	gasPricer.priceCh <- big.NewInt(9)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if p, err := store.Load(); err == nil && p.GasPrice.Int64() == 9 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected saved gas price 9")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if price := e.SuggestGasPrice(); price.Int64() != 9 {
+		t.Errorf("expected gas price 9, but got %v", price)
+	}
+}
@@ -0,0 +1,82 @@
+package gasestimator
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// Priority is the priority tier of the transaction used with SuggestGasPriceFor, higher priority means higher
+// gas price and faster inclusion.
+type Priority int
+
+const (
+	// PrioritySlow is for transactions which can wait (e.g. sweeping funds).
+	PrioritySlow Priority = iota
+	// PriorityStandard is the gas price returned by SuggestGasPrice.
+	PriorityStandard
+	// PriorityFast is for transactions which should be mined in the next few blocks.
+	PriorityFast
+	// PriorityUrgent is for transactions which should be mined in the next block even when the base fee spikes.
+	PriorityUrgent
+
+	priorityCount = iota
+)
+
+func (p Priority) String() string {
+	switch p {
+	case PrioritySlow:
+		return "slow"
+	case PriorityStandard:
+		return "standard"
+	case PriorityFast:
+		return "fast"
+	case PriorityUrgent:
+		return "urgent"
+	default:
+		return fmt.Sprintf("priority(%d)", int(p))
+	}
+}
+
+// priorityPercent is the percentage of the polled gas price used for the priority tier.
+var priorityPercent = [priorityCount]int64{
+	PrioritySlow:     90,
+	PriorityStandard: 100,
+	PriorityFast:     120,
+	PriorityUrgent:   150,
+}
+
+// priorityTipPercentile is the percentile of priority fees used for the priority tier by the estimator created with
+// NewHeadsGasPriceEstimator, the percentile of PriorityStandard is set with WithTipHistory.
+var priorityTipPercentile = [priorityCount]float64{
+	PrioritySlow:   10,
+	PriorityFast:   75,
+	PriorityUrgent: 95,
+}
+
+// priorityPrices returns gas prices of all priority tiers given the standard gas price.
+func priorityPrices(gasPrice *big.Int) []*big.Int {
+	prices := make([]*big.Int, priorityCount)
+	for p, percent := range priorityPercent {
+		prices[p] = percentOf(gasPrice, percent)
+	}
+	return prices
+}
+
+// tipPercentiles returns percentiles of priority fees of all priority tiers given the standard percentile.
+// eth_feeHistory requires percentiles in ascending order, so other tiers never cross the standard one.
+func tipPercentiles(standard float64) []float64 {
+	percentiles := make([]float64, priorityCount)
+	for p, percentile := range priorityTipPercentile {
+		switch {
+		case Priority(p) < PriorityStandard:
+			percentile = math.Min(percentile, standard)
+		case Priority(p) > PriorityStandard:
+			percentile = math.Max(percentile, standard)
+		default:
+			percentile = standard
+		}
+		percentiles[p] = percentile
+	}
+	return percentiles
+}
@@ -0,0 +1,117 @@
+package gasestimator
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// DefaultMinBumpPercent is the minimum gas price bump required by geth to replace pending transaction
+// with the same nonce.
+const DefaultMinBumpPercent = 10
+
+var (
+	// ErrMaxAttempts is returned by ReplacementPolicy when the transaction must not be replaced anymore.
+	ErrMaxAttempts = errors.New("gasestimator: max replacement attempts reached")
+	// ErrGasPriceCap is returned by ReplacementPolicy when the bumped gas price would exceed the cap.
+	ErrGasPriceCap = errors.New("gasestimator: gas price cap reached")
+)
+
+// Escalation is the curve of gas price bumps of replacement transactions.
+type Escalation int
+
+const (
+	// EscalationLinear raises the initial gas price by BumpPercent for every attempt.
+	EscalationLinear Escalation = iota
+	// EscalationExponential raises the gas price of the previous attempt by BumpPercent, compounding.
+	EscalationExponential
+)
+
+func (e Escalation) String() string {
+	switch e {
+	case EscalationLinear:
+		return "linear"
+	case EscalationExponential:
+		return "exponential"
+	default:
+		return fmt.Sprintf("escalation(%d)", int(e))
+	}
+}
+
+// ReplacementPolicy decides on the gas price of the transaction which replaces the stuck one with the same nonce.
+type ReplacementPolicy struct {
+	// MinBumpPercent is the minimum raise of the gas price of the previous attempt required by the node to accept
+	// the replacement (DefaultMinBumpPercent when it's 0).
+	MinBumpPercent int64
+	// BumpPercent is the raise of the gas price for every attempt according to Escalation (MinBumpPercent when
+	// it's lower).
+	BumpPercent int64
+	// Escalation is the curve of gas price bumps.
+	Escalation Escalation
+	// MaxAttempts is the maximum number of replacements, 0 means no limit.
+	MaxAttempts int
+	// MaxGasPrice caps the gas price of replacements (e.g. GasPriceEstimator.MaxGasPrice), nil means no cap.
+	MaxGasPrice *big.Int
+}
+
+// DefaultReplacementPolicy bumps the gas price exponentially by 13% up to 10 times without the cap.
+var DefaultReplacementPolicy = ReplacementPolicy{
+	MinBumpPercent: DefaultMinBumpPercent,
+	BumpPercent:    13,
+	Escalation:     EscalationExponential,
+	MaxAttempts:    10,
+}
+
+// GasPrice returns the gas price of the replacement attempt (starting from 1) given the gas price of the original
+// transaction and the one of the previous attempt. The gas price is raised to satisfy the replacement rule
+// of the node, and it's lowered to MaxGasPrice unless the rule can't be satisfied within the cap, in which case
+// ErrGasPriceCap is returned. ErrMaxAttempts is returned when the attempt exceeds MaxAttempts.
+func (p ReplacementPolicy) GasPrice(initial, prev *big.Int, attempt int) (*big.Int, error) {
+	if p.MaxAttempts > 0 && attempt > p.MaxAttempts {
+		return nil, ErrMaxAttempts
+	}
+
+	minBump := p.MinBumpPercent
+	if minBump <= 0 {
+		minBump = DefaultMinBumpPercent
+	}
+	bump := p.BumpPercent
+	if bump < minBump {
+		bump = minBump
+	}
+
+	var gasPrice *big.Int
+	switch p.Escalation {
+	case EscalationExponential:
+		gasPrice = new(big.Int).Set(initial)
+		for i := 0; i < attempt; i++ {
+			gasPrice = percentOf(gasPrice, 100+bump)
+		}
+	default:
+		gasPrice = percentOf(initial, 100+bump*int64(attempt))
+	}
+
+	minGasPrice := ceilPercentOf(prev, 100+minBump)
+	if minGasPrice.Cmp(prev) <= 0 {
+		minGasPrice.Add(prev, big.NewInt(1))
+	}
+	if gasPrice.Cmp(minGasPrice) < 0 {
+		gasPrice = minGasPrice
+	}
+
+	if p.MaxGasPrice != nil && gasPrice.Cmp(p.MaxGasPrice) > 0 {
+		if minGasPrice.Cmp(p.MaxGasPrice) > 0 {
+			return nil, ErrGasPriceCap
+		}
+		gasPrice = new(big.Int).Set(p.MaxGasPrice)
+	}
+
+	return gasPrice, nil
+}
+
+// ceilPercentOf returns the percentage of the value rounded up, so it satisfies the replacement rule of the node.
+func ceilPercentOf(v *big.Int, percent int64) *big.Int {
+	res := new(big.Int).Mul(v, big.NewInt(percent))
+	res.Add(res, big.NewInt(99))
+	return res.Div(res, big.NewInt(100))
+}
@@ -0,0 +1,43 @@
+package gasestimator
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestReplacementPolicy_GasPrice(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      ReplacementPolicy
+		prev        int64
+		attempt     int
+		expected    int64
+		expectedErr error
+	}{
+		{"linear", ReplacementPolicy{BumpPercent: 20}, 1200, 2, 1400, nil},
+		{"exponential", ReplacementPolicy{BumpPercent: 20, Escalation: EscalationExponential}, 1200, 2, 1440, nil},
+		{"min bump of previous attempt", ReplacementPolicy{BumpPercent: 10}, 1500, 2, 1650, nil},
+		{"min bump rounded up", ReplacementPolicy{}, 1001, 1, 1102, nil},
+		{"bump below min bump", ReplacementPolicy{MinBumpPercent: 25, BumpPercent: 5}, 1000, 1, 1250, nil},
+		{"capped", ReplacementPolicy{BumpPercent: 50, MaxGasPrice: big.NewInt(1300)}, 1000, 1, 1300, nil},
+		{"cap below min bump", ReplacementPolicy{MaxGasPrice: big.NewInt(1300)}, 1250, 2, 0, ErrGasPriceCap},
+		{"max attempts", ReplacementPolicy{MaxAttempts: 3}, 1000, 4, 0, ErrMaxAttempts},
+	}
+
+	for _, tt := range tests {
+		gasPrice, err := tt.policy.GasPrice(big.NewInt(1000), big.NewInt(tt.prev), tt.attempt)
+		if tt.expectedErr != nil {
+			if err != tt.expectedErr {
+				t.Errorf("%v: expected error %v, but got %v", tt.name, tt.expectedErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%v: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if gasPrice.Int64() != tt.expected {
+			t.Errorf("%v: expected gas price %v, but got %v", tt.name, tt.expected, gasPrice)
+		}
+	}
+}
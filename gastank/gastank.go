@@ -0,0 +1,237 @@
+// Package gastank watches balances of managed hot wallets, alerts when they drop below thresholds and
+// optionally refills them from the treasury account, so that wallets sending transactions don't run out of gas.
+package gastank
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/big"
+	"sync"
+	"time"
+
+	eth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/monetha/go-ethereum"
+)
+
+// DefaultInterval is used when Config.Interval is zero.
+const DefaultInterval = time.Minute
+
+// DefaultPendingTimeout is used when Config.PendingTimeout is zero.
+const DefaultPendingTimeout = 10 * time.Minute
+
+// BalanceReader reads balances of accounts (e.g. backend.Backend).
+type BalanceReader interface {
+	BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error)
+}
+
+// Wallet is the managed account to watch.
+type Wallet struct {
+	Address common.Address
+	// Threshold is the balance in wei below which it's low.
+	Threshold *big.Int
+	// RefillTo is the balance in wei the wallet is refilled to when it's low. The wallet isn't refilled when it's nil.
+	RefillTo *big.Int
+}
+
+// Status is the result of checking the balance of the wallet.
+type Status struct {
+	Wallet *Wallet
+	// Balance is the current balance in wei, nil when it couldn't be retrieved.
+	Balance *big.Int
+	// Low indicates that the balance is below the threshold.
+	Low bool
+	// Tx is the refill transaction sent from the treasury, or the one which is still pending.
+	Tx *types.Transaction
+	// Err is the error of retrieving the balance or sending refill transaction, *ethereum.SpendingLimitError
+	// when the refill exceeds Config.SpendingPolicy.
+	Err error
+}
+
+// Config configures Watcher.
+type Config struct {
+	// Interval is the interval of checking balances, DefaultInterval is used when it's zero.
+	Interval time.Duration
+	// Treasury is the session of the account which refills low wallets (optional). Only one refill transaction
+	// per wallet is pending at a time.
+	Treasury *ethereum.Session
+	// PendingTimeout is the duration after which the pending refill transaction which isn't known by the node
+	// anymore (dropped or replaced) is forgotten, so that the wallet is refilled again. DefaultPendingTimeout
+	// is used when it's zero.
+	PendingTimeout time.Duration
+	// SpendingPolicy limits the amount sent from the treasury (optional).
+	SpendingPolicy *ethereum.SpendingPolicy
+	// OnLow is called (from the goroutine of Watcher) with the status of every low wallet or failed check (optional).
+	OnLow func(*Status)
+}
+
+// Watcher periodically checks balances of the wallets.
+type Watcher struct {
+	balances BalanceReader
+	wallets  []*Wallet
+	cfg      Config
+	treasury *ethereum.Session
+
+	mu      sync.Mutex
+	pending map[*Wallet]*pendingTx // refill transactions which aren't mined yet
+
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+// New creates an instance of Watcher. Balances aren't checked in background until Start is called,
+// Check can be used instead to check them on demand.
+func New(balances BalanceReader, wallets []*Wallet, cfg *Config) *Watcher {
+	w := &Watcher{
+		balances: balances,
+		wallets:  wallets,
+		pending:  make(map[*Wallet]*pendingTx),
+		closed:   make(chan struct{}),
+	}
+	if cfg != nil {
+		w.cfg = *cfg
+	}
+	if w.cfg.Interval == 0 {
+		w.cfg.Interval = DefaultInterval
+	}
+	if w.cfg.PendingTimeout == 0 {
+		w.cfg.PendingTimeout = DefaultPendingTimeout
+	}
+	if w.treasury = w.cfg.Treasury; w.treasury != nil && w.cfg.SpendingPolicy != nil {
+		w.treasury = w.treasury.WithSpendingPolicy(w.cfg.SpendingPolicy)
+	}
+	return w
+}
+
+// Start checks balances every Config.Interval in background until Close is called.
+func (w *Watcher) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancelOnClose(cancel)
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+
+		for {
+			for _, st := range w.Check(ctx) {
+				if ctx.Err() != nil {
+					return // closed
+				}
+				if st.Err != nil {
+					log.Printf("gastank: wallet %v: %v", st.Wallet.Address.Hex(), st.Err)
+				}
+				if (st.Low || st.Err != nil) && w.cfg.OnLow != nil {
+					w.cfg.OnLow(st)
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(w.cfg.Interval):
+			}
+		}
+	}()
+}
+
+// Check checks balances of all wallets once and refills low ones when Config.Treasury is set.
+func (w *Watcher) Check(ctx context.Context) []*Status {
+	statuses := make([]*Status, 0, len(w.wallets))
+	for _, wallet := range w.wallets {
+		statuses = append(statuses, w.check(ctx, wallet))
+	}
+	return statuses
+}
+
+func (w *Watcher) check(ctx context.Context, wallet *Wallet) *Status {
+	st := &Status{Wallet: wallet}
+
+	st.Balance, st.Err = w.balances.BalanceAt(ctx, wallet.Address, nil)
+	if st.Err != nil {
+		st.Err = fmt.Errorf("gastank: BalanceAt: %v", st.Err)
+		return st
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	st.Low = st.Balance.Cmp(wallet.Threshold) < 0
+	if !st.Low {
+		delete(w.pending, wallet)
+		return st
+	}
+
+	if p := w.pending[wallet]; p != nil {
+		_, err := w.treasury.Backend.TransactionReceipt(ctx, p.tx.Hash())
+		if err != nil && !w.dropped(ctx, p) {
+			st.Tx = p.tx // refill transaction isn't mined yet (or its receipt can't be retrieved)
+			return st
+		}
+		delete(w.pending, wallet)
+
+		if err == nil {
+			// the balance could be read before the refill transaction was mined, so it's read again
+			st.Balance, st.Err = w.balances.BalanceAt(ctx, wallet.Address, nil)
+			if st.Err != nil {
+				st.Err = fmt.Errorf("gastank: BalanceAt: %v", st.Err)
+				return st
+			}
+			st.Low = st.Balance.Cmp(wallet.Threshold) < 0
+			if !st.Low {
+				return st
+			}
+		}
+		// refill transaction is dropped, or it's mined, but the balance is low again
+	}
+
+	if w.treasury == nil || wallet.RefillTo == nil || wallet.RefillTo.Cmp(st.Balance) <= 0 {
+		return st
+	}
+
+	amount := new(big.Int).Sub(wallet.RefillTo, st.Balance)
+	st.Tx, st.Err = w.treasury.WithValue(amount).Transfer(ctx, wallet.Address, nil)
+	if st.Err != nil {
+		return st
+	}
+	w.pending[wallet] = &pendingTx{tx: st.Tx, sent: time.Now()}
+	w.treasury.Log("Wallet refilled", "wallet", wallet.Address.Hex(), "balance", st.Balance, "amount", amount, "tx_hash", st.Tx.Hash().Hex())
+	return st
+}
+
+type pendingTx struct {
+	tx   *types.Transaction
+	sent time.Time
+}
+
+// dropped tells whether the refill transaction isn't known by the node after Config.PendingTimeout, i.e. it was
+// evicted from the transaction pool or replaced by another transaction with the same nonce.
+func (w *Watcher) dropped(ctx context.Context, p *pendingTx) bool {
+	if time.Since(p.sent) < w.cfg.PendingTimeout {
+		return false
+	}
+	_, _, err := w.treasury.Backend.TransactionByHash(ctx, p.tx.Hash())
+	return err == eth.NotFound
+}
+
+// Close stops checking balances in background.
+func (w *Watcher) Close() error {
+	w.closeOnce.Do(func() {
+		close(w.closed)
+
+		w.wg.Wait()
+	})
+	return nil
+}
+
+func (w *Watcher) cancelOnClose(cancel context.CancelFunc) {
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		defer cancel()
+
+		<-w.closed
+	}()
+}
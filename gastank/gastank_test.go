@@ -0,0 +1,257 @@
+package gastank
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	eth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/monetha/go-ethereum"
+	"github.com/monetha/go-ethereum/backend"
+)
+
+var (
+	walletAddress   = common.HexToAddress("0x1111111111111111111111111111111111111111")
+	treasuryAddress = common.HexToAddress("0x2222222222222222222222222222222222222222")
+)
+
+// balanceBackend returns the balance set and records sent transactions, which are never mined.
+type balanceBackend struct {
+	backend.Backend
+
+	mu      sync.Mutex
+	balance *big.Int
+	dropped bool // sent transactions aren't known by the node
+	sent    []*types.Transaction
+}
+
+func (b *balanceBackend) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.balance, nil
+}
+
+func (b *balanceBackend) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return 0, nil
+}
+
+func (b *balanceBackend) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(1), nil
+}
+
+func (b *balanceBackend) EstimateGas(ctx context.Context, call eth.CallMsg) (uint64, error) {
+	return 21000, nil
+}
+
+func (b *balanceBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.sent = append(b.sent, tx)
+	return nil
+}
+
+func (b *balanceBackend) TransactionByHash(ctx context.Context, txHash common.Hash) (*types.Transaction, bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.dropped {
+		return nil, false, eth.NotFound
+	}
+	return nil, true, nil
+}
+
+func (b *balanceBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	return nil, eth.NotFound
+}
+
+func (b *balanceBackend) sentTxs() []*types.Transaction {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]*types.Transaction(nil), b.sent...)
+}
+
+func treasury(b *balanceBackend) *ethereum.Session {
+	return &ethereum.Session{
+		Eth: ethereum.New(b, nil),
+		TransactOpts: bind.TransactOpts{
+			From: treasuryAddress,
+			Signer: func(signer types.Signer, address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+				return tx, nil
+			},
+		},
+	}
+}
+
+func TestWatcher_Check(t *testing.T) {
+	tests := []struct {
+		name     string
+		balance  int64
+		treasury bool
+		low      bool
+		refill   int64 // 0 when not refilled
+	}{
+		{"enough balance", 1000, true, false, 0},
+		{"low balance", 999, false, true, 0},
+		{"low balance refilled", 400, true, true, 4600},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &balanceBackend{balance: big.NewInt(tt.balance)}
+			cfg := &Config{}
+			if tt.treasury {
+				cfg.Treasury = treasury(b)
+			}
+			w := New(b, []*Wallet{{Address: walletAddress, Threshold: big.NewInt(1000), RefillTo: big.NewInt(5000)}}, cfg)
+			defer w.Close()
+
+			st := w.Check(context.Background())[0]
+			if st.Err != nil {
+				t.Fatalf("unexpected error: %v", st.Err)
+			}
+			if st.Low != tt.low {
+				t.Errorf("expected low %v, but got %v", tt.low, st.Low)
+			}
+
+			sent := b.sentTxs()
+			if tt.refill == 0 {
+				if len(sent) != 0 {
+					t.Errorf("expected no refill, but got %v transactions", len(sent))
+				}
+				return
+			}
+			if len(sent) != 1 {
+				t.Fatalf("expected one refill transaction, but got %v", len(sent))
+			}
+			if tx := sent[0]; *tx.To() != walletAddress || tx.Value().Int64() != tt.refill {
+				t.Errorf("expected refill of %v wei to %v, but got %v wei to %v", tt.refill, walletAddress.Hex(), tx.Value(), tx.To().Hex())
+			}
+		})
+	}
+}
+
+func TestWatcher_Check_Pending(t *testing.T) {
+	b := &balanceBackend{balance: big.NewInt(0)}
+	w := New(b, []*Wallet{{Address: walletAddress, Threshold: big.NewInt(1000), RefillTo: big.NewInt(5000)}}, &Config{Treasury: treasury(b)})
+	defer w.Close()
+
+	first := w.Check(context.Background())[0]
+	second := w.Check(context.Background())[0]
+	if n := len(b.sentTxs()); n != 1 {
+		t.Fatalf("expected one refill transaction while it's pending, but got %v", n)
+	}
+	if second.Tx != first.Tx {
+		t.Errorf("expected pending refill transaction to be reported")
+	}
+}
+
+// minedBackend mines sent transactions, the balance is returned from reads one by one (the last one is repeated).
+type minedBackend struct {
+	*balanceBackend
+	reads []*big.Int
+}
+
+func (b *minedBackend) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	balance := b.reads[0]
+	if len(b.reads) > 1 {
+		b.reads = b.reads[1:]
+	}
+	return balance, nil
+}
+
+func (b *minedBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	return &types.Receipt{Status: types.ReceiptStatusSuccessful}, nil
+}
+
+func TestWatcher_Check_Mined(t *testing.T) {
+	// the balance read before the refill transaction is mined is stale
+	b := &minedBackend{balanceBackend: &balanceBackend{}, reads: []*big.Int{big.NewInt(0), big.NewInt(0), big.NewInt(5000)}}
+	tr := &ethereum.Session{Eth: ethereum.New(b, nil), TransactOpts: treasury(b.balanceBackend).TransactOpts}
+	w := New(b, []*Wallet{{Address: walletAddress, Threshold: big.NewInt(1000), RefillTo: big.NewInt(5000)}}, &Config{Treasury: tr})
+	defer w.Close()
+
+	w.Check(context.Background())
+	st := w.Check(context.Background())[0]
+	if st.Err != nil {
+		t.Fatalf("unexpected error: %v", st.Err)
+	}
+	if st.Low || st.Balance.Int64() != 5000 {
+		t.Errorf("expected refilled balance, but got %v", st.Balance)
+	}
+	if n := len(b.sentTxs()); n != 1 {
+		t.Errorf("expected one refill transaction, but got %v", n)
+	}
+}
+
+func TestWatcher_Check_SpendingLimit(t *testing.T) {
+	b := &balanceBackend{balance: big.NewInt(0)}
+	w := New(b, []*Wallet{{Address: walletAddress, Threshold: big.NewInt(1000), RefillTo: big.NewInt(5000)}}, &Config{
+		Treasury:       treasury(b),
+		SpendingPolicy: ethereum.NewSpendingPolicy(big.NewInt(1000), nil, time.Hour),
+	})
+	defer w.Close()
+
+	st := w.Check(context.Background())[0]
+	if _, ok := st.Err.(*ethereum.SpendingLimitError); !ok {
+		t.Errorf("expected *ethereum.SpendingLimitError, but got %v", st.Err)
+	}
+	if n := len(b.sentTxs()); n != 0 {
+		t.Errorf("expected no refill, but got %v transactions", n)
+	}
+}
+
+func TestWatcher_Start(t *testing.T) {
+	b := &balanceBackend{balance: big.NewInt(0)}
+	low := make(chan *Status, 1)
+	w := New(b, []*Wallet{{Address: walletAddress, Threshold: big.NewInt(1000)}}, &Config{
+		Interval: time.Millisecond,
+		OnLow: func(st *Status) {
+			select {
+			case low <- st:
+			default:
+			}
+		},
+	})
+	w.Start()
+	defer w.Close()
+
+	select {
+	case st := <-low:
+		if st.Wallet.Address != walletAddress || st.Balance.Sign() != 0 {
+			t.Errorf("expected low balance of %v, but got %+v", walletAddress.Hex(), st)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("expected low balance alert")
+	}
+}
+
+func TestWatcher_Check_PendingTimeout(t *testing.T) {
+	tests := []struct {
+		name    string
+		dropped bool
+		sent    int
+	}{
+		{"pending", false, 1},
+		{"dropped", true, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &balanceBackend{balance: big.NewInt(0), dropped: tt.dropped}
+			w := New(b, []*Wallet{{Address: walletAddress, Threshold: big.NewInt(1000), RefillTo: big.NewInt(5000)}}, &Config{Treasury: treasury(b), PendingTimeout: time.Nanosecond})
+			defer w.Close()
+
+			w.Check(context.Background())
+			w.Check(context.Background())
+			if n := len(b.sentTxs()); n != tt.sent {
+				t.Errorf("expected %v refill transactions, but got %v", tt.sent, n)
+			}
+		})
+	}
+}
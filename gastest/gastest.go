@@ -0,0 +1,167 @@
+// Package gastest records gas used by named operations against the simulated backend and compares it with
+// stored baselines, so that tests catch unexpected gas regressions of contract-integration code.
+//
+//	h := gastest.New(sim)
+//	err := h.Run("deploy", func(b backend.SimulatedBackend) error { ... })
+//	...
+//	h.Check(t, "testdata/gas.json", 0.05)
+//
+// Baselines are (re)written instead of compared when UpdateEnv environment variable is set.
+package gastest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/monetha/go-ethereum/backend"
+)
+
+// UpdateEnv is the environment variable which makes Check write the baselines file.
+const UpdateEnv = "GASTEST_UPDATE"
+
+// Harness records gas used by named operations.
+type Harness struct {
+	sim backend.SimulatedBackend
+
+	mu  sync.Mutex
+	gas map[string]uint64
+}
+
+// New creates an instance of Harness for the simulated backend.
+func New(sim backend.SimulatedBackend) *Harness {
+	return &Harness{sim: sim, gas: make(map[string]uint64)}
+}
+
+// Run runs the operation, which sends transactions with the given backend, commits the pending block and adds
+// gas used by all sent transactions to the gas of the operation. Operations must not run concurrently.
+func (h *Harness) Run(name string, fn func(b backend.SimulatedBackend) error) error {
+	rb := &recordingBackend{SimulatedBackend: h.sim}
+	if err := fn(rb); err != nil {
+		return err
+	}
+	h.sim.Commit()
+
+	var total uint64
+	for _, tx := range rb.txs {
+		r, err := h.sim.TransactionReceipt(context.Background(), tx.Hash())
+		if err != nil {
+			return fmt.Errorf("gastest: receipt of transaction %v of %v: %v", tx.Hash().Hex(), name, err)
+		}
+		total += r.GasUsed
+	}
+
+	h.mu.Lock()
+	h.gas[name] += total
+	h.mu.Unlock()
+	return nil
+}
+
+// GasUsed returns gas used by the operations.
+func (h *Harness) GasUsed() map[string]uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	res := make(map[string]uint64, len(h.gas))
+	for name, gas := range h.gas {
+		res[name] = gas
+	}
+	return res
+}
+
+// Check compares gas used by the operations with the baselines stored in the JSON file (operation name to gas).
+// It fails the test when gas of the operation exceeds the baseline by more than tolerance (e.g. 0.05 is 5%),
+// or the operation has no baseline. Gas decreased beyond tolerance is only logged, so that the baseline
+// can be lowered. When UpdateEnv environment variable is set, the file is written instead.
+func (h *Harness) Check(t testing.TB, path string, tolerance float64) {
+	t.Helper()
+
+	used := h.GasUsed()
+
+	if os.Getenv(UpdateEnv) != "" {
+		data, err := json.MarshalIndent(used, "", "  ")
+		if err != nil {
+			t.Fatalf("gastest: %v", err)
+		}
+		if err := ioutil.WriteFile(path, append(data, '\n'), 0644); err != nil {
+			t.Fatalf("gastest: writing baselines: %v", err)
+		}
+		t.Logf("gastest: baselines written to %v", path)
+		return
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("gastest: reading baselines (set %v=1 to create them): %v", UpdateEnv, err)
+	}
+	var baselines map[string]uint64
+	if err := json.Unmarshal(data, &baselines); err != nil {
+		t.Fatalf("gastest: parsing baselines %v: %v", path, err)
+	}
+
+	for _, r := range Compare(used, baselines, tolerance) {
+		switch {
+		case r.Missing:
+			t.Errorf("gastest: %v used %v gas, but has no baseline", r.Name, r.Used)
+		case r.Regression:
+			t.Errorf("gastest: %v used %v gas, baseline is %v (%+.1f%%)", r.Name, r.Used, r.Baseline, r.Change*100)
+		case r.Improvement:
+			t.Logf("gastest: %v used %v gas, baseline is %v (%+.1f%%), consider updating the baseline", r.Name, r.Used, r.Baseline, r.Change*100)
+		}
+	}
+}
+
+// Result is the result of comparison of gas used by the operation with the baseline.
+type Result struct {
+	Name     string
+	Used     uint64
+	Baseline uint64
+	// Change is the relative change of gas used compared to the baseline.
+	Change      float64
+	Missing     bool
+	Regression  bool
+	Improvement bool
+}
+
+// Compare compares gas used by the operations with the baselines, ordered by the operation name.
+func Compare(used, baselines map[string]uint64, tolerance float64) []Result {
+	res := make([]Result, 0, len(used))
+	for name, gas := range used {
+		r := Result{Name: name, Used: gas}
+		baseline, ok := baselines[name]
+		switch {
+		case !ok:
+			r.Missing = true
+		case baseline == 0:
+			r.Baseline = 0
+			r.Regression = gas > 0
+		default:
+			r.Baseline = baseline
+			r.Change = float64(gas)/float64(baseline) - 1
+			r.Regression = r.Change > tolerance
+			r.Improvement = r.Change < -tolerance
+		}
+		res = append(res, r)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res
+}
+
+type recordingBackend struct {
+	backend.SimulatedBackend
+	txs []*types.Transaction
+}
+
+func (b *recordingBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	if err := b.SimulatedBackend.SendTransaction(ctx, tx); err != nil {
+		return err
+	}
+	b.txs = append(b.txs, tx)
+	return nil
+}
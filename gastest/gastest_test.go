@@ -0,0 +1,69 @@
+package gastest
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	used := map[string]uint64{
+		"deploy":   110000,
+		"transfer": 21000,
+		"approve":  40000,
+		"mint":     50000,
+	}
+	baselines := map[string]uint64{
+		"deploy":   100000,
+		"transfer": 21000,
+		"approve":  50000,
+	}
+
+	res := Compare(used, baselines, 0.05)
+	if len(res) != 4 {
+		t.Fatalf("expected 4 results, but got %v", len(res))
+	}
+
+	expected := []struct {
+		name                             string
+		missing, regression, improvement bool
+	}{
+		{"approve", false, false, true},
+		{"deploy", false, true, false},
+		{"mint", true, false, false},
+		{"transfer", false, false, false},
+	}
+	for i, e := range expected {
+		r := res[i]
+		if r.Name != e.name || r.Missing != e.missing || r.Regression != e.regression || r.Improvement != e.improvement {
+			t.Errorf("expected %+v, but got %+v", e, r)
+		}
+	}
+}
+
+func TestHarness_Check(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gastest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "gas.json")
+
+	h := New(nil)
+	h.gas["transfer"] = 21000
+
+	os.Setenv(UpdateEnv, "1")
+	h.Check(t, path, 0)
+	os.Unsetenv(UpdateEnv)
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("expected baselines file to be written: %v", err)
+	}
+	if expected := "{\n  \"transfer\": 21000\n}\n"; string(data) != expected {
+		t.Errorf("expected baselines %q, but got %q", expected, data)
+	}
+
+	h.Check(t, path, 0)
+}
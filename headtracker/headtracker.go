@@ -0,0 +1,295 @@
+// Package headtracker tracks the latest, safe and finalized heads of the chain in one place, so BlockSource,
+// confirmation waiting and caching backends share them instead of each calling eth_blockNumber on its own timer.
+package headtracker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"sync"
+	"time"
+
+	eth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/monetha/go-ethereum"
+	"github.com/monetha/go-ethereum/health"
+)
+
+// DefaultPollInterval is used when Config.PollInterval is zero.
+const DefaultPollInterval = 4 * time.Second
+
+// RPCCaller calls RPC methods, it's implemented by *rpc.Client.
+type RPCCaller interface {
+	CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error
+}
+
+// HeadSubscriber subscribes to new heads and calls RPC methods, it's implemented by *rpc.Client connected
+// with WebSocket or IPC.
+type HeadSubscriber interface {
+	RPCCaller
+	EthSubscribe(ctx context.Context, channel interface{}, args ...interface{}) (*rpc.ClientSubscription, error)
+}
+
+// Config contains parameters of Tracker.
+type Config struct {
+	// PollInterval is the interval of polling heads (DefaultPollInterval when it's zero). When new heads are
+	// received with subscription, only safe and finalized heads are polled.
+	PollInterval time.Duration
+	// Subscribe indicates that the latest head must be updated with newHeads subscription. The caller must
+	// implement HeadSubscriber, the subscription is renewed after it fails.
+	Subscribe bool
+}
+
+// Heads are the numbers of the latest, safe and finalized blocks. Safe and finalized are nil when the node
+// doesn't support these block tags (e.g. before the Merge).
+type Heads struct {
+	Latest    *big.Int
+	Safe      *big.Int
+	Finalized *big.Int
+	UpdatedAt time.Time // time of the last successful update
+}
+
+// Tracker keeps the heads of the chain up to date. It's safe for concurrent use.
+type Tracker struct {
+	rpc       RPCCaller
+	subscribe subscribeFunc
+	interval  time.Duration
+	mu        sync.RWMutex
+	heads     Heads
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+type rpcHead struct {
+	Number *hexutil.Big `json:"number"`
+}
+
+// subscribeFunc subscribes to new heads.
+type subscribeFunc func(ctx context.Context, ch chan<- *rpcHead) (eth.Subscription, error)
+
+// New creates an instance of Tracker. It fails when the latest head can't be retrieved.
+// The caller isn't closed by Close.
+func New(caller RPCCaller, cfg *Config) (*Tracker, error) {
+	if cfg == nil {
+		cfg = &Config{}
+	}
+
+	var subscribe subscribeFunc
+	if cfg.Subscribe {
+		s, ok := caller.(HeadSubscriber)
+		if !ok {
+			return nil, errors.New("headtracker: subscription isn't supported by the caller")
+		}
+		subscribe = func(ctx context.Context, ch chan<- *rpcHead) (eth.Subscription, error) {
+			return s.EthSubscribe(ctx, ch, "newHeads")
+		}
+	}
+
+	return newTracker(context.Background(), caller, subscribe, cfg.PollInterval)
+}
+
+func newTracker(ctx context.Context, caller RPCCaller, subscribe subscribeFunc, interval time.Duration) (*Tracker, error) {
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	t := &Tracker{
+		rpc:       caller,
+		subscribe: subscribe,
+		interval:  interval,
+		closed:    make(chan struct{}),
+	}
+
+	if err := t.poll(ctx, true); err != nil {
+		return nil, err
+	}
+
+	t.runPolling()
+	if subscribe != nil {
+		t.runSubscription()
+	}
+	return t, nil
+}
+
+// Heads returns the last known heads.
+func (t *Tracker) Heads() Heads {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return Heads{
+		Latest:    copyBigInt(t.heads.Latest),
+		Safe:      copyBigInt(t.heads.Safe),
+		Finalized: copyBigInt(t.heads.Finalized),
+		UpdatedAt: t.heads.UpdatedAt,
+	}
+}
+
+// BlockNumber returns the number of the latest block without calling the node, so Tracker can be used
+// instead of client.Client by BlockSource (see blocksource.Config.Heads).
+func (t *Tracker) BlockNumber(ctx context.Context) (*big.Int, error) {
+	return t.Heads().Latest, nil
+}
+
+// Healthy implements health.Healthier interface. Tracker is unhealthy when heads weren't updated
+// for 5 poll intervals.
+func (t *Tracker) Healthy(ctx context.Context) error {
+	h := t.Heads()
+	if time.Since(h.UpdatedAt) > 5*t.interval {
+		return &health.UnhealthyError{Component: "headtracker", Reason: "heads are stale", Status: health.Status{LastSuccess: h.UpdatedAt}}
+	}
+	return nil
+}
+
+// Close implements io.Closer interface.
+func (t *Tracker) Close() error {
+	t.closeOnce.Do(func() {
+		close(t.closed)
+
+		t.wg.Wait()
+	})
+	return nil
+}
+
+// poll updates the safe and finalized heads, and the latest one when it's not updated with subscription
+// (or when all is set).
+func (t *Tracker) poll(ctx context.Context, all bool) error {
+	var latest *big.Int
+	if all || t.subscribe == nil {
+		var n hexutil.Big
+		if err := t.rpc.CallContext(ctx, &n, "eth_blockNumber"); err != nil {
+			return fmt.Errorf("headtracker: eth_blockNumber: %v", err)
+		}
+		latest = n.ToInt()
+	}
+	safe := t.tagNumber(ctx, ethereum.SafeBlockNumber)
+	finalized := t.tagNumber(ctx, ethereum.FinalizedBlockNumber)
+
+	t.mu.Lock()
+	if latest != nil {
+		t.setLatest(latest)
+	}
+	t.heads.Safe = safe
+	t.heads.Finalized = finalized
+	t.heads.UpdatedAt = time.Now()
+	t.mu.Unlock()
+	return nil
+}
+
+// tagNumber returns the number of the block with the tag, or nil when the tag isn't supported.
+func (t *Tracker) tagNumber(ctx context.Context, number *big.Int) *big.Int {
+	tag, _ := ethereum.BlockNumberTag(number)
+
+	var head *rpcHead
+	if err := t.rpc.CallContext(ctx, &head, "eth_getBlockByNumber", tag, false); err != nil || head == nil || head.Number == nil {
+		return nil
+	}
+	return head.Number.ToInt()
+}
+
+// setLatest sets the latest head unless it's older than the known one. It's called with the lock held.
+func (t *Tracker) setLatest(latest *big.Int) {
+	if t.heads.Latest == nil || latest.Cmp(t.heads.Latest) >= 0 {
+		t.heads.Latest = latest
+	}
+}
+
+func (t *Tracker) runPolling() {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.cancelOnClose(cancel)
+
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(t.interval):
+			}
+
+			if err := t.poll(ctx, false); err != nil {
+				if ctx.Err() != nil {
+					return // closed
+				}
+				log.Printf("%v", err)
+			}
+		}
+	}()
+}
+
+func (t *Tracker) runSubscription() {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.cancelOnClose(cancel)
+
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+
+		for {
+			ch := make(chan *rpcHead, 16)
+			sub, err := t.subscribe(ctx, ch)
+			if err == nil {
+				err = t.consumeHeads(ctx, sub, ch)
+				sub.Unsubscribe()
+			}
+			if ctx.Err() != nil {
+				return // closed
+			}
+			log.Printf("headtracker: newHeads subscription: %v", err)
+
+			// the latest head isn't updated meanwhile
+			if err := t.poll(ctx, true); err != nil && ctx.Err() == nil {
+				log.Printf("%v", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(t.interval):
+			}
+		}
+	}()
+}
+
+// consumeHeads updates the latest head until the subscription fails or the context is done.
+func (t *Tracker) consumeHeads(ctx context.Context, sub eth.Subscription, ch <-chan *rpcHead) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-sub.Err():
+			if err == nil {
+				err = errors.New("subscription closed")
+			}
+			return err
+		case head := <-ch:
+			if head == nil || head.Number == nil {
+				continue
+			}
+			t.mu.Lock()
+			t.setLatest(head.Number.ToInt())
+			t.heads.UpdatedAt = time.Now()
+			t.mu.Unlock()
+		}
+	}
+}
+
+func (t *Tracker) cancelOnClose(cancel context.CancelFunc) {
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		defer cancel()
+
+		<-t.closed
+	}()
+}
+
+func copyBigInt(v *big.Int) *big.Int {
+	if v == nil {
+		return nil
+	}
+	return new(big.Int).Set(v)
+}
@@ -0,0 +1,139 @@
+package headtracker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	eth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// nodeCaller responds with block numbers of the latest block and of the blocks with tags, nil for unsupported tags.
+type nodeCaller struct {
+	mu      sync.Mutex
+	numbers map[string]string
+	calls   map[string]int
+}
+
+func newNodeCaller(numbers map[string]string) *nodeCaller {
+	return &nodeCaller{numbers: numbers, calls: make(map[string]int)}
+}
+
+func (c *nodeCaller) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls[method]++
+
+	switch method {
+	case "eth_blockNumber":
+		return json.Unmarshal([]byte(`"`+c.numbers["latest"]+`"`), result)
+	case "eth_getBlockByNumber":
+		n, ok := c.numbers[args[0].(string)]
+		if !ok {
+			return json.Unmarshal([]byte(`null`), result)
+		}
+		return json.Unmarshal([]byte(`{"number":"`+n+`"}`), result)
+	}
+	return errors.New("unexpected method " + method)
+}
+
+func (c *nodeCaller) set(tag, number string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.numbers[tag] = number
+}
+
+func (c *nodeCaller) count(method string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.calls[method]
+}
+
+type chanSubscription struct{ err chan error }
+
+func (s chanSubscription) Unsubscribe()      {}
+func (s chanSubscription) Err() <-chan error { return s.err }
+
+func TestTracker_Polling(t *testing.T) {
+	caller := newNodeCaller(map[string]string{"latest": "0x64", "safe": "0x60", "finalized": "0x5a"})
+	tr, err := newTracker(context.Background(), caller, nil, time.Millisecond)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tr.Close()
+
+	h := tr.Heads()
+	if h.Latest.Int64() != 100 || h.Safe.Int64() != 96 || h.Finalized.Int64() != 90 {
+		t.Errorf("expected heads 100, 96, 90, but got %v, %v, %v", h.Latest, h.Safe, h.Finalized)
+	}
+
+	caller.set("latest", "0x65")
+	deadline := time.Now().Add(time.Second)
+	for {
+		if n, _ := tr.BlockNumber(context.Background()); n.Int64() == 101 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected latest head to be updated")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := tr.Healthy(context.Background()); err != nil {
+		t.Errorf("expected tracker to be healthy, but got %v", err)
+	}
+}
+
+func TestTracker_UnsupportedTags(t *testing.T) {
+	tr, err := newTracker(context.Background(), newNodeCaller(map[string]string{"latest": "0x64"}), nil, time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tr.Close()
+
+	if h := tr.Heads(); h.Latest.Int64() != 100 || h.Safe != nil || h.Finalized != nil {
+		t.Errorf("expected only latest head, but got %+v", h)
+	}
+}
+
+func TestTracker_Subscription(t *testing.T) {
+	caller := newNodeCaller(map[string]string{"latest": "0x64"})
+	heads := make(chan chan<- *rpcHead, 1)
+	subscribe := func(ctx context.Context, ch chan<- *rpcHead) (eth.Subscription, error) {
+		heads <- ch
+		return chanSubscription{err: make(chan error)}, nil
+	}
+
+	tr, err := newTracker(context.Background(), caller, subscribe, time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tr.Close()
+
+	ch := <-heads
+	ch <- &rpcHead{Number: (*hexutil.Big)(big.NewInt(102))}
+	ch <- &rpcHead{Number: (*hexutil.Big)(big.NewInt(101))} // older head is ignored
+	ch <- &rpcHead{Number: (*hexutil.Big)(big.NewInt(103))}
+
+	deadline := time.Now().Add(time.Second)
+	for tr.Heads().Latest.Int64() != 103 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected latest head 103, but got %v", tr.Heads().Latest)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if n := caller.count("eth_blockNumber"); n != 1 {
+		t.Errorf("expected eth_blockNumber to be called once, but got %v", n)
+	}
+}
+
+func TestNew_SubscriptionNotSupported(t *testing.T) {
+	if _, err := New(newNodeCaller(nil), &Config{Subscribe: true}); err == nil {
+		t.Errorf("expected error, but got nil")
+	}
+}
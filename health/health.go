@@ -0,0 +1,62 @@
+// Package health defines health checks of long-running components, which can be wired into readiness and
+// liveness probes.
+package health
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Healthier is implemented by long-running components which can report their health.
+type Healthier interface {
+	// Healthy returns nil when the component is healthy, otherwise it returns an error describing the problem.
+	Healthy(ctx context.Context) error
+}
+
+// Status holds health information of a component.
+type Status struct {
+	// LastSuccess is the time of the last successful RPC call (zero if there was no successful call yet).
+	LastSuccess time.Time
+	// Lag is the number of blocks the component is behind the chain head.
+	Lag uint64
+}
+
+// UnhealthyError is returned by Healthy when the component isn't healthy.
+type UnhealthyError struct {
+	Component string
+	Reason    string
+	Status    Status
+}
+
+func (e *UnhealthyError) Error() string {
+	lastSuccess := "never"
+	if !e.Status.LastSuccess.IsZero() {
+		lastSuccess = e.Status.LastSuccess.Format(time.RFC3339)
+	}
+	return fmt.Sprintf("%v: %v (last successful RPC call: %v, lag: %v blocks)", e.Component, e.Reason, lastSuccess, e.Status.Lag)
+}
+
+// Check checks health of all components, it returns the first error.
+func Check(ctx context.Context, components ...Healthier) error {
+	for _, c := range components {
+		if err := c.Healthy(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Handler returns http.Handler which responds with 200 OK when all components are healthy,
+// otherwise it responds with 503 Service Unavailable and the error text.
+func Handler(components ...Healthier) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := Check(r.Context(), components...); err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("OK"))
+	})
+}
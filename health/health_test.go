@@ -0,0 +1,65 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type healthierFunc func(ctx context.Context) error
+
+func (f healthierFunc) Healthy(ctx context.Context) error { return f(ctx) }
+
+var (
+	healthy   = healthierFunc(func(context.Context) error { return nil })
+	unhealthy = healthierFunc(func(context.Context) error { return errors.New("broken") })
+)
+
+func TestCheck(t *testing.T) {
+	if err := Check(context.Background()); err != nil {
+		t.Errorf("expected no error without components, but got %v", err)
+	}
+	if err := Check(context.Background(), healthy, healthy); err != nil {
+		t.Errorf("expected no error, but got %v", err)
+	}
+	if err := Check(context.Background(), healthy, unhealthy); err == nil || err.Error() != "broken" {
+		t.Errorf("expected error broken, but got %v", err)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		components []Healthier
+		code       int
+	}{
+		{"healthy", []Healthier{healthy}, http.StatusOK},
+		{"unhealthy", []Healthier{healthy, unhealthy}, http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			Handler(tt.components...).ServeHTTP(rec, httptest.NewRequest("GET", "/health", nil))
+			if rec.Code != tt.code {
+				t.Errorf("expected status code %v, but got %v", tt.code, rec.Code)
+			}
+		})
+	}
+}
+
+func TestUnhealthyError_Error(t *testing.T) {
+	err := &UnhealthyError{Component: "blocksource", Reason: "closed", Status: Status{Lag: 5}}
+	if msg := err.Error(); msg != "blocksource: closed (last successful RPC call: never, lag: 5 blocks)" {
+		t.Errorf("unexpected error message: %v", msg)
+	}
+
+	err.Status.LastSuccess = time.Date(2019, 4, 1, 12, 0, 0, 0, time.UTC)
+	if msg := err.Error(); !strings.Contains(msg, "2019-04-01T12:00:00Z") {
+		t.Errorf("expected last success time in error message, but got %v", msg)
+	}
+}
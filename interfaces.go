@@ -4,3 +4,10 @@ import "errors"
 
 // ErrNotFound is returned by API methods if the requested item does not exist.
 var ErrNotFound = errors.New("not found")
+
+// ErrTxDropped is returned by Eth.WaitForTxReceipt when transaction is neither pending nor mined
+// for Eth.DroppedTxTimeout (e.g. it was evicted from the transaction pool).
+var ErrTxDropped = errors.New("transaction dropped")
+
+// ErrNoGasPrice is returned by Session.IsEnoughFunds when the gas price isn't set and AutoGasPrice is disabled.
+var ErrNoGasPrice = errors.New("gas price must be non nil")
@@ -0,0 +1,254 @@
+// Package keyshare splits private keys into Shamir's secret shares for backup, so that any K of N shares
+// reconstruct the key, while fewer shares reveal nothing about it. Arithmetic is done byte-wise in GF(256).
+package keyshare
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/monetha/go-ethereum"
+)
+
+// Share is one share of the private key.
+type Share struct {
+	// Index is the x coordinate of the share (1..255).
+	Index byte
+	// Threshold is the number of shares needed to reconstruct the key.
+	Threshold byte
+	// Address is the address of the key, it's used to verify the reconstructed key.
+	Address common.Address
+	// Data is the share of the private key.
+	Data []byte
+}
+
+type shareJSON struct {
+	Index     byte           `json:"index"`
+	Threshold byte           `json:"threshold"`
+	Address   common.Address `json:"address"`
+	Data      hexutil.Bytes  `json:"data"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (s Share) MarshalJSON() ([]byte, error) {
+	return json.Marshal(shareJSON{Index: s.Index, Threshold: s.Threshold, Address: s.Address, Data: s.Data})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (s *Share) UnmarshalJSON(input []byte) error {
+	var dec shareJSON
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	*s = Share{Index: dec.Index, Threshold: dec.Threshold, Address: dec.Address, Data: dec.Data}
+	return nil
+}
+
+// String returns hex-string representation of the share: index, threshold, address and data.
+func (s Share) String() string {
+	b := make([]byte, 0, 2+common.AddressLength+len(s.Data))
+	b = append(b, s.Index, s.Threshold)
+	b = append(b, s.Address.Bytes()...)
+	b = append(b, s.Data...)
+	return hex.EncodeToString(b)
+}
+
+// ParseShare parses hex-string representation of the share returned by Share.String.
+func ParseShare(s string) (Share, error) {
+	b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		return Share{}, fmt.Errorf("keyshare: invalid share: %v", err)
+	}
+	if len(b) <= 2+common.AddressLength {
+		return Share{}, errors.New("keyshare: share is too short")
+	}
+	if b[1] < 2 {
+		return Share{}, fmt.Errorf("keyshare: invalid threshold %d", b[1])
+	}
+	return Share{
+		Index:     b[0],
+		Threshold: b[1],
+		Address:   common.BytesToAddress(b[2 : 2+common.AddressLength]),
+		Data:      b[2+common.AddressLength:],
+	}, nil
+}
+
+// Split splits the private key into n shares, any threshold of which reconstruct the key.
+func Split(key *ethereum.Key, n, threshold int) ([]Share, error) {
+	secret, err := hex.DecodeString(key.PrivateKeyString())
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := split(secret, n, threshold)
+	if err != nil {
+		return nil, err
+	}
+
+	shares := make([]Share, n)
+	for i := range shares {
+		shares[i] = Share{Index: byte(i + 1), Threshold: byte(threshold), Address: key.Address, Data: data[i]}
+	}
+	return shares, nil
+}
+
+// Combine reconstructs the private key from the shares. It returns error if there are less shares than threshold,
+// any share beyond the threshold doesn't match the reconstructed key, or the reconstructed key doesn't match
+// the address of the shares.
+func Combine(shares []Share) (*ethereum.Key, error) {
+	if len(shares) == 0 {
+		return nil, errors.New("keyshare: no shares")
+	}
+
+	first := shares[0]
+	xs := make([]byte, len(shares))
+	ys := make([][]byte, len(shares))
+	for i, s := range shares {
+		if s.Threshold != first.Threshold || s.Address != first.Address || len(s.Data) != len(first.Data) {
+			return nil, errors.New("keyshare: shares are of different keys")
+		}
+		xs[i], ys[i] = s.Index, s.Data
+	}
+	if first.Threshold < 2 {
+		return nil, fmt.Errorf("keyshare: invalid threshold %d", first.Threshold)
+	}
+	if len(shares) < int(first.Threshold) {
+		return nil, fmt.Errorf("keyshare: %d shares are needed, but got %d", first.Threshold, len(shares))
+	}
+
+	secret, err := combine(xs, ys, int(first.Threshold))
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := ethereum.NewKeyFromPrivateKey(hex.EncodeToString(secret))
+	if err != nil {
+		return nil, fmt.Errorf("keyshare: invalid reconstructed key: %v", err)
+	}
+	if key.Address != first.Address {
+		return nil, errors.New("keyshare: reconstructed key doesn't match address of the shares")
+	}
+	return key, nil
+}
+
+// split returns n shares of the secret, share i has x coordinate i+1.
+func split(secret []byte, n, threshold int) ([][]byte, error) {
+	if threshold < 2 || threshold > n || n > 255 {
+		return nil, fmt.Errorf("keyshare: invalid threshold %d of %d shares", threshold, n)
+	}
+	if len(secret) == 0 {
+		return nil, errors.New("keyshare: empty secret")
+	}
+
+	shares := make([][]byte, n)
+	for i := range shares {
+		shares[i] = make([]byte, len(secret))
+	}
+
+	coeffs := make([]byte, threshold)
+	for b, s := range secret {
+		// random polynomial of degree threshold-1 with the secret byte as the constant term
+		coeffs[0] = s
+		if _, err := rand.Read(coeffs[1:]); err != nil {
+			return nil, fmt.Errorf("keyshare: generating coefficients: %v", err)
+		}
+		for i := range shares {
+			shares[i][b] = evaluate(coeffs, byte(i+1))
+		}
+	}
+
+	return shares, nil
+}
+
+// combine interpolates the polynomials of the first threshold shares at x = 0. The rest of the shares must lie
+// on the same polynomials.
+func combine(xs []byte, ys [][]byte, threshold int) ([]byte, error) {
+	for i, x := range xs {
+		if x == 0 {
+			return nil, errors.New("keyshare: invalid share index 0")
+		}
+		for _, other := range xs[:i] {
+			if x == other {
+				return nil, fmt.Errorf("keyshare: duplicate share index %d", x)
+			}
+		}
+	}
+
+	for k := threshold; k < len(xs); k++ {
+		y := interpolate(xs[:threshold], ys[:threshold], xs[k])
+		for b := range y {
+			if y[b] != ys[k][b] {
+				return nil, fmt.Errorf("keyshare: share %d doesn't match the other shares", xs[k])
+			}
+		}
+	}
+	return interpolate(xs[:threshold], ys[:threshold], 0), nil
+}
+
+// interpolate evaluates the polynomials passing through the points at x.
+func interpolate(xs []byte, ys [][]byte, x byte) []byte {
+	res := make([]byte, len(ys[0]))
+	for b := range res {
+		var s byte
+		for i, xi := range xs {
+			// Lagrange basis polynomial at x: prod (x - xj) / (xi - xj), subtraction is XOR
+			l := byte(1)
+			for j, xj := range xs {
+				if i != j {
+					l = mul(l, div(x^xj, xi^xj))
+				}
+			}
+			s ^= mul(ys[i][b], l)
+		}
+		res[b] = s
+	}
+	return res
+}
+
+// evaluate evaluates the polynomial at x using Horner's method.
+func evaluate(coeffs []byte, x byte) byte {
+	var y byte
+	for i := len(coeffs) - 1; i >= 0; i-- {
+		y = mul(y, x) ^ coeffs[i]
+	}
+	return y
+}
+
+// logTable and expTable are logarithms and exponents of the generator 3 in GF(256) with polynomial
+// x^8 + x^4 + x^3 + x + 1.
+var logTable, expTable = func() (l [256]byte, e [255]byte) {
+	x := byte(1)
+	for i := 0; i < 255; i++ {
+		e[i] = x
+		l[x] = byte(i)
+		// multiply by 3: x*2 ^ x
+		x2 := x << 1
+		if x&0x80 != 0 {
+			x2 ^= 0x1b
+		}
+		x ^= x2
+	}
+	return
+}()
+
+func mul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return expTable[(int(logTable[a])+int(logTable[b]))%255]
+}
+
+func div(a, b byte) byte {
+	if b == 0 {
+		panic("keyshare: division by zero")
+	}
+	if a == 0 {
+		return 0
+	}
+	return expTable[(int(logTable[a])-int(logTable[b])+255)%255]
+}
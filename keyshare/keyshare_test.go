@@ -0,0 +1,152 @@
+package keyshare
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/monetha/go-ethereum"
+)
+
+func TestSplitCombine_Secret(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+
+	shares, err := split(secret, 5, 3)
+	if err != nil {
+		t.Fatalf("split: %v", err)
+	}
+
+	testCases := [][]int{
+		{0, 1, 2},
+		{4, 2, 0},
+		{1, 3, 4},
+	}
+	for _, idx := range testCases {
+		var xs []byte
+		var ys [][]byte
+		for _, i := range idx {
+			xs = append(xs, byte(i+1))
+			ys = append(ys, shares[i])
+		}
+		got, err := combine(xs, ys, 3)
+		if err != nil {
+			t.Fatalf("combine: %v", err)
+		}
+		if !bytes.Equal(got, secret) {
+			t.Errorf("shares %v: expected secret %x, but got %x", idx, secret, got)
+		}
+	}
+
+	got, err := combine([]byte{1, 2}, shares[:2], 2)
+	if err != nil {
+		t.Fatalf("combine: %v", err)
+	}
+	if bytes.Equal(got, secret) {
+		t.Errorf("expected less shares than threshold not to reconstruct the secret")
+	}
+
+	if _, err := combine([]byte{1, 1, 2}, shares[:3], 3); err == nil {
+		t.Errorf("expected error for duplicate indexes")
+	}
+
+	got, err = combine([]byte{1, 2, 3, 4, 5}, shares, 3)
+	if err != nil {
+		t.Fatalf("combine: %v", err)
+	}
+	if !bytes.Equal(got, secret) {
+		t.Errorf("all shares: expected secret %x, but got %x", secret, got)
+	}
+
+	corrupted := append([][]byte{}, shares...)
+	corrupted[4] = append([]byte{shares[4][0] ^ 1}, shares[4][1:]...)
+	if _, err := combine([]byte{1, 2, 3, 4, 5}, corrupted, 3); err == nil {
+		t.Errorf("expected error for share beyond threshold which doesn't match")
+	}
+}
+
+func TestSplit_InvalidThreshold(t *testing.T) {
+	testCases := []struct{ n, threshold int }{
+		{3, 1},
+		{3, 4},
+		{256, 2},
+	}
+	for _, tc := range testCases {
+		if _, err := split([]byte{1}, tc.n, tc.threshold); err == nil {
+			t.Errorf("split(%v, %v): expected error", tc.n, tc.threshold)
+		}
+	}
+}
+
+func TestGF256(t *testing.T) {
+	for a := 1; a < 256; a++ {
+		for b := 1; b < 256; b++ {
+			if got := div(mul(byte(a), byte(b)), byte(b)); got != byte(a) {
+				t.Fatalf("(%v * %v) / %v: expected %v, but got %v", a, b, b, a, got)
+			}
+		}
+	}
+	// known product in AES field
+	if got := mul(0x57, 0x83); got != 0xc1 {
+		t.Errorf("expected 0x57 * 0x83 = 0xc1, but got %#x", got)
+	}
+}
+
+func TestShare_Encoding(t *testing.T) {
+	s := Share{Index: 2, Threshold: 3, Address: common.HexToAddress("0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf"), Data: []byte{1, 2, 3}}
+
+	parsed, err := ParseShare(s.String())
+	if err != nil {
+		t.Fatalf("ParseShare: %v", err)
+	}
+	if parsed.Index != s.Index || parsed.Threshold != s.Threshold || parsed.Address != s.Address || !bytes.Equal(parsed.Data, s.Data) {
+		t.Errorf("expected %+v, but got %+v", s, parsed)
+	}
+
+	b, err := json.Marshal(s)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if expected := `{"index":2,"threshold":3,"address":"0x7e5f4552091a69125d5dfcb7b8c2659029395bdf","data":"0x010203"}`; string(b) != expected {
+		t.Errorf("expected JSON %v, but got %s", expected, b)
+	}
+	var decoded Share
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if decoded.Address != s.Address || !bytes.Equal(decoded.Data, s.Data) {
+		t.Errorf("expected %+v, but got %+v", s, decoded)
+	}
+
+	s.Threshold = 1
+	if _, err := ParseShare(s.String()); err == nil {
+		t.Errorf("expected error for threshold 1")
+	}
+	if _, err := Combine([]Share{s}); err == nil {
+		t.Errorf("expected Combine to fail for threshold 1")
+	}
+}
+
+func TestSplitCombine_Key(t *testing.T) {
+	key, err := ethereum.NewKeyFromPrivateKey("aa22b54c0cb43ee30a014afe5ef3664b1cde299feabca46cd3167a85a57c39f2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	shares, err := Split(key, 3, 2)
+	if err != nil {
+		t.Fatalf("Split: %v", err)
+	}
+
+	restored, err := Combine([]Share{shares[2], shares[0]})
+	if err != nil {
+		t.Fatalf("Combine: %v", err)
+	}
+	if restored.PrivateKeyString() != key.PrivateKeyString() {
+		t.Errorf("expected restored key to be equal to the original one")
+	}
+
+	if _, err := Combine(shares[:1]); err == nil {
+		t.Errorf("expected error for less shares than threshold")
+	}
+}
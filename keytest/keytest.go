@@ -0,0 +1,73 @@
+// +build !js
+
+// Package keytest provides deterministic keys and simulated backends funding them, to reduce boilerplate of tests.
+// Keys are derived from their indexes, so they (and addresses) are the same in every test run. They must never be
+// used outside of tests.
+package keytest
+
+import (
+	"crypto/ecdsa"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/monetha/go-ethereum"
+	"github.com/monetha/go-ethereum/backend"
+)
+
+// Ether is 10^18 wei.
+var Ether = big.NewInt(1000000000000000000)
+
+// GasLimit is the block gas limit of simulated backends.
+const GasLimit = 10000000
+
+// Key returns the deterministic key with the given index. It panics if the derived private key is invalid,
+// which practically never happens.
+func Key(index int) *ethereum.Key {
+	seed := make([]byte, 8)
+	binary.BigEndian.PutUint64(seed, uint64(index))
+
+	pk, err := crypto.ToECDSA(crypto.Keccak256([]byte("monetha/go-ethereum/keytest"), seed))
+	if err != nil {
+		panic(fmt.Sprintf("keytest: invalid key %d: %v", index, err))
+	}
+	return &ethereum.Key{Address: crypto.PubkeyToAddress(pk.PublicKey), PrivateKey: pk}
+}
+
+// Keys returns n deterministic keys with indexes 0..n-1.
+func Keys(n int) []*ethereum.Key {
+	keys := make([]*ethereum.Key, n)
+	for i := range keys {
+		keys[i] = Key(i)
+	}
+	return keys
+}
+
+// PrivateKeys returns ECDSA private keys of the keys, e.g. to create sessions with Eth.NewSession.
+func PrivateKeys(keys []*ethereum.Key) []*ecdsa.PrivateKey {
+	res := make([]*ecdsa.PrivateKey, len(keys))
+	for i, k := range keys {
+		res[i] = k.PrivateKey
+	}
+	return res
+}
+
+// Alloc returns genesis allocation funding every key with the balance.
+func Alloc(keys []*ethereum.Key, balance *big.Int) core.GenesisAlloc {
+	alloc := make(core.GenesisAlloc, len(keys))
+	for _, k := range keys {
+		alloc[k.Address] = core.GenesisAccount{Balance: new(big.Int).Set(balance)}
+	}
+	return alloc
+}
+
+// NewFundedSim creates simulated backend with nAccounts deterministic keys (see Keys), each funded with
+// the balance in the genesis block.
+func NewFundedSim(nAccounts int, balance *big.Int) (*backend.SimulatedBackendExt, []*ethereum.Key) {
+	keys := Keys(nAccounts)
+	sim := backend.NewSimulatedBackendExtended(Alloc(keys, balance), GasLimit)
+	sim.Commit()
+	return sim, keys
+}
@@ -0,0 +1,35 @@
+// +build !js
+
+package keytest
+
+import (
+	"context"
+	"testing"
+)
+
+func TestKeys(t *testing.T) {
+	keys := Keys(3)
+	if len(keys) != 3 {
+		t.Fatalf("expected 3 keys, but got %v", len(keys))
+	}
+	if keys[0].Address == keys[1].Address {
+		t.Errorf("expected different keys")
+	}
+	if Key(1).PrivateKeyString() != keys[1].PrivateKeyString() {
+		t.Errorf("expected keys to be deterministic")
+	}
+}
+
+func TestNewFundedSim(t *testing.T) {
+	sim, keys := NewFundedSim(2, Ether)
+
+	for _, k := range keys {
+		balance, err := sim.BalanceAt(context.Background(), k.Address, nil)
+		if err != nil {
+			t.Fatalf("BalanceAt: %v", err)
+		}
+		if balance.Cmp(Ether) != 0 {
+			t.Errorf("expected balance %v of %v, but got %v", Ether, k.Address.Hex(), balance)
+		}
+	}
+}
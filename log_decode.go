@@ -0,0 +1,100 @@
+package ethereum
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ErrUnknownEvent is returned by DecodeEvent when the log isn't an event of the ABI.
+var ErrUnknownEvent = errors.New("unknown event")
+
+// DecodedEvent is the event decoded from the log.
+type DecodedEvent struct {
+	Name string
+	// Args holds values of the event arguments in the order of the event inputs. Values of indexed arguments
+	// of dynamic types (string, bytes, arrays) are Keccak256 hashes (common.Hash), as only hashes are stored in topics.
+	Args []interface{}
+	Log  types.Log
+}
+
+// DecodeEvent decodes the log using the events of the ABI. Anonymous events aren't decoded.
+func DecodeEvent(contractABI abi.ABI, log types.Log) (*DecodedEvent, error) {
+	if len(log.Topics) == 0 {
+		return nil, ErrUnknownEvent
+	}
+
+	for name, event := range contractABI.Events {
+		if event.Anonymous || event.Id() != log.Topics[0] {
+			continue
+		}
+
+		args, err := decodeEventArgs(event, log)
+		if err != nil {
+			return nil, fmt.Errorf("decoding event %v: %v", name, err)
+		}
+		return &DecodedEvent{Name: name, Args: args, Log: log}, nil
+	}
+
+	return nil, ErrUnknownEvent
+}
+
+func decodeEventArgs(event abi.Event, log types.Log) ([]interface{}, error) {
+	nonIndexed, err := abi.Arguments(event.Inputs.NonIndexed()).UnpackValues(log.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]interface{}, len(event.Inputs))
+	topics := log.Topics[1:]
+	for i, input := range event.Inputs {
+		if !input.Indexed {
+			args[i], nonIndexed = nonIndexed[0], nonIndexed[1:]
+			continue
+		}
+
+		if len(topics) == 0 {
+			return nil, fmt.Errorf("missing topic of indexed argument %v", input.Name)
+		}
+		topic := topics[0]
+		topics = topics[1:]
+
+		switch input.Type.T {
+		case abi.StringTy, abi.BytesTy, abi.SliceTy, abi.ArrayTy, abi.TupleTy:
+			args[i] = topic
+		default:
+			arg := input
+			arg.Indexed = false
+			values, err := abi.Arguments{arg}.UnpackValues(topic.Bytes())
+			if err != nil {
+				return nil, fmt.Errorf("decoding indexed argument %v: %v", input.Name, err)
+			}
+			args[i] = values[0]
+		}
+	}
+
+	return args, nil
+}
+
+// DecodeEvents decodes the logs of the receipt which are events with the given name.
+func DecodeEvents(contractABI abi.ABI, receipt *types.Receipt, name string) ([]*DecodedEvent, error) {
+	event, ok := contractABI.Events[name]
+	if !ok {
+		return nil, fmt.Errorf("event %v not found in ABI", name)
+	}
+
+	var res []*DecodedEvent
+	for _, log := range receipt.Logs {
+		if len(log.Topics) == 0 || log.Topics[0] != event.Id() {
+			continue
+		}
+		args, err := decodeEventArgs(event, *log)
+		if err != nil {
+			return nil, fmt.Errorf("decoding event %v of log %v: %v", name, log.Index, err)
+		}
+		res = append(res, &DecodedEvent{Name: name, Args: args, Log: *log})
+	}
+	return res, nil
+}
@@ -0,0 +1,53 @@
+package ethereum
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const decodeTestABI = `[{"anonymous":false,"name":"Transfer","type":"event","inputs":[
+	{"indexed":true,"name":"from","type":"address"},
+	{"indexed":true,"name":"to","type":"address"},
+	{"indexed":false,"name":"value","type":"uint256"}]}]`
+
+func TestDecodeEvent(t *testing.T) {
+	parsed, err := abi.JSON(strings.NewReader(decodeTestABI))
+	if err != nil {
+		t.Fatalf("abi.JSON() error = %v", err)
+	}
+
+	from := common.HexToAddress("0x1")
+	to := common.HexToAddress("0x2")
+	log := types.Log{
+		Topics: []common.Hash{parsed.Events["Transfer"].Id(), from.Hash(), to.Hash()},
+		Data:   common.LeftPadBytes(big.NewInt(100).Bytes(), 32),
+	}
+
+	e, err := DecodeEvent(parsed, log)
+	if err != nil {
+		t.Fatalf("DecodeEvent() error = %v", err)
+	}
+	if e.Name != "Transfer" {
+		t.Errorf("expected name Transfer, but got %v", e.Name)
+	}
+	if len(e.Args) != 3 || e.Args[0] != from || e.Args[1] != to || e.Args[2].(*big.Int).Int64() != 100 {
+		t.Errorf("expected args [%v %v 100], but got %v", from.Hex(), to.Hex(), e.Args)
+	}
+
+	if _, err := DecodeEvent(parsed, types.Log{}); err != ErrUnknownEvent {
+		t.Errorf("expected error %v, but got %v", ErrUnknownEvent, err)
+	}
+	if _, err := DecodeEvent(parsed, types.Log{Topics: []common.Hash{{1}}}); err != ErrUnknownEvent {
+		t.Errorf("expected error %v, but got %v", ErrUnknownEvent, err)
+	}
+
+	log.Topics = log.Topics[:2]
+	if _, err := DecodeEvent(parsed, log); err == nil {
+		t.Error("expected error for missing topic, but got nil")
+	}
+}
@@ -0,0 +1,146 @@
+package ethereum
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// TransferEventTopic is the topic of ERC-20 (and ERC-721) Transfer(address,address,uint256) event.
+var TransferEventTopic = common.HexToHash("0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef")
+
+// LogExclusion tells whether the log must be excluded from results.
+type LogExclusion func(log *types.Log) bool
+
+// ExcludeAddresses excludes logs emitted by any of the contracts (e.g. a noisy contract).
+func ExcludeAddresses(addresses ...common.Address) LogExclusion {
+	set := make(map[common.Address]struct{}, len(addresses))
+	for _, addr := range addresses {
+		set[addr] = struct{}{}
+	}
+	return func(log *types.Log) bool {
+		_, ok := set[log.Address]
+		return ok
+	}
+}
+
+// ExcludeTopics excludes logs matching the topics, which have the same meaning as ethereum.FilterQuery.Topics:
+// alternatives at each position, empty list matches any topic. Use MakeTopics to build them from values.
+func ExcludeTopics(topics ...[]common.Hash) LogExclusion {
+	return func(log *types.Log) bool {
+		if len(topics) > len(log.Topics) {
+			return false
+		}
+		for i, sub := range topics {
+			match := len(sub) == 0 // empty rule set == wildcard
+			for _, topic := range sub {
+				if log.Topics[i] == topic {
+					match = true
+					break
+				}
+			}
+			if !match {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// ExcludeSelfTransfers excludes ERC-20 and ERC-721 Transfer events where the sender is the recipient.
+func ExcludeSelfTransfers() LogExclusion {
+	return func(log *types.Log) bool {
+		return len(log.Topics) >= 3 && log.Topics[0] == TransferEventTopic && log.Topics[1] == log.Topics[2]
+	}
+}
+
+// ExcludingLogFilterer drops logs matching any of exclusions from results of the wrapped filterer, since
+// eth_getLogs can't express negation. It can be used wherever the filterer is expected, e.g. with
+// NewContractLogFilterer or StreamLogs.
+type ExcludingLogFilterer struct {
+	filterer   ethereum.LogFilterer
+	exclusions []LogExclusion
+}
+
+// ExcludeLogs wraps the filterer to exclude logs matching any of exclusions.
+func ExcludeLogs(filterer ethereum.LogFilterer, exclusions ...LogExclusion) *ExcludingLogFilterer {
+	return &ExcludingLogFilterer{
+		filterer:   filterer,
+		exclusions: exclusions,
+	}
+}
+
+// FilterLogs implements ethereum.LogFilterer.
+func (f *ExcludingLogFilterer) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	logs, err := f.filterer.FilterLogs(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]types.Log, 0, len(logs))
+	for i := range logs {
+		if !f.excluded(&logs[i]) {
+			res = append(res, logs[i])
+		}
+	}
+	return res, nil
+}
+
+// SubscribeFilterLogs implements ethereum.LogFilterer.
+func (f *ExcludingLogFilterer) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	logs := make(chan types.Log, 128)
+	sub, err := f.filterer.SubscribeFilterLogs(ctx, query, logs)
+	if err != nil {
+		return nil, err
+	}
+
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		forward := func(log types.Log) bool {
+			if f.excluded(&log) {
+				return true
+			}
+			select {
+			case ch <- log:
+				return true
+			case <-quit:
+				return false
+			}
+		}
+
+		for {
+			select {
+			case log := <-logs:
+				if !forward(log) {
+					return nil
+				}
+			case err := <-sub.Err():
+				// deliver logs sent before the subscription ended
+				for {
+					select {
+					case log := <-logs:
+						if !forward(log) {
+							return nil
+						}
+					default:
+						return err
+					}
+				}
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+func (f *ExcludingLogFilterer) excluded(log *types.Log) bool {
+	for _, exclude := range f.exclusions {
+		if exclude(log) {
+			return true
+		}
+	}
+	return false
+}
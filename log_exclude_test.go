@@ -0,0 +1,79 @@
+package ethereum
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestExcludingLogFilterer(t *testing.T) {
+	var (
+		token   = common.HexToAddress("0x01")
+		noisy   = common.HexToAddress("0x02")
+		alice   = common.BytesToHash(common.HexToAddress("0xa1").Bytes())
+		bob     = common.BytesToHash(common.HexToAddress("0xb0").Bytes())
+		approve = common.HexToHash("0x8c5be1e5ebec7d5bd14f71427d1e84f3dd0314c0f7b2291e5b200ac8c7c3b925")
+	)
+	logs := SliceLogFilterer{
+		{Address: token, Topics: []common.Hash{TransferEventTopic, alice, bob}, Index: 0},
+		{Address: token, Topics: []common.Hash{TransferEventTopic, alice, alice}, Index: 1},
+		{Address: noisy, Topics: []common.Hash{TransferEventTopic, bob, alice}, Index: 2},
+		{Address: token, Topics: []common.Hash{approve, alice, bob}, Index: 3},
+		{Address: token, Topics: []common.Hash{TransferEventTopic, bob, alice}, Index: 4},
+	}
+
+	tests := []struct {
+		name       string
+		exclusions []LogExclusion
+		indexes    []uint
+	}{
+		{"no exclusions", nil, []uint{0, 1, 2, 3, 4}},
+		{"addresses", []LogExclusion{ExcludeAddresses(noisy)}, []uint{0, 1, 3, 4}},
+		{"self transfers", []LogExclusion{ExcludeSelfTransfers()}, []uint{0, 2, 3, 4}},
+		{"topics", []LogExclusion{ExcludeTopics([]common.Hash{approve})}, []uint{0, 1, 2, 4}},
+		{"topics with wildcard", []LogExclusion{ExcludeTopics(nil, []common.Hash{alice})}, []uint{2, 4}},
+		{"any of", []LogExclusion{ExcludeAddresses(noisy), ExcludeSelfTransfers(), ExcludeTopics(nil, nil, []common.Hash{alice})}, []uint{0, 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := ExcludeLogs(logs, tt.exclusions...)
+
+			res, err := f.FilterLogs(context.Background(), ethereum.FilterQuery{})
+			if err != nil {
+				t.Fatalf("FilterLogs: %v", err)
+			}
+			checkLogIndexes(t, res, tt.indexes)
+
+			ch := make(chan types.Log, len(logs))
+			sub, err := f.SubscribeFilterLogs(context.Background(), ethereum.FilterQuery{}, ch)
+			if err != nil {
+				t.Fatalf("SubscribeFilterLogs: %v", err)
+			}
+			if err := <-sub.Err(); err != nil {
+				t.Fatalf("subscription: %v", err)
+			}
+			close(ch)
+			res = nil
+			for log := range ch {
+				res = append(res, log)
+			}
+			checkLogIndexes(t, res, tt.indexes)
+		})
+	}
+}
+
+func checkLogIndexes(t *testing.T, logs []types.Log, expected []uint) {
+	t.Helper()
+	if len(logs) != len(expected) {
+		t.Fatalf("expected %v logs, but got %v", len(expected), len(logs))
+	}
+	for i, log := range logs {
+		if log.Index != expected[i] {
+			t.Errorf("expected log %v at position %v, but got %v", expected[i], i, log.Index)
+		}
+	}
+}
@@ -2,16 +2,13 @@ package ethereum
 
 import (
 	"context"
-	"fmt"
 	"math/big"
-	"reflect"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/event"
 )
 
@@ -39,15 +36,7 @@ func (c *ContractLogFilterer) FilterLogs(opts *bind.FilterOpts, names []string,
 		opts = new(bind.FilterOpts)
 	}
 
-	var eventNameRule []interface{}
-	for _, name := range names {
-		eventNameRule = append(eventNameRule, c.abi.Events[name].Id())
-	}
-
-	// Append the event selector to the query parameters and construct the topic set
-	query = append([][]interface{}{eventNameRule}, query...)
-
-	topics, err := makeTopics(query...)
+	topics, err := makeEventTopics(c.abi, names, query...)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -84,80 +73,82 @@ func (c *ContractLogFilterer) FilterLogs(opts *bind.FilterOpts, names []string,
 	return logs, sub, nil
 }
 
-// makeTopics converts a filter query argument list into a filter topic set.
-func makeTopics(query ...[]interface{}) ([][]common.Hash, error) {
-	topics := make([][]common.Hash, len(query))
-	for i, filter := range query {
-		for _, rule := range filter {
-			var topic common.Hash
-
-			// Try to generate the topic based on simple types
-			switch rule := rule.(type) {
-			case common.Hash:
-				copy(topic[:], rule[:])
-			case common.Address:
-				copy(topic[common.HashLength-common.AddressLength:], rule[:])
-			case *big.Int:
-				blob := rule.Bytes()
-				copy(topic[common.HashLength-len(blob):], blob)
-			case bool:
-				if rule {
-					topic[common.HashLength-1] = 1
-				}
-			case int8:
-				blob := big.NewInt(int64(rule)).Bytes()
-				copy(topic[common.HashLength-len(blob):], blob)
-			case int16:
-				blob := big.NewInt(int64(rule)).Bytes()
-				copy(topic[common.HashLength-len(blob):], blob)
-			case int32:
-				blob := big.NewInt(int64(rule)).Bytes()
-				copy(topic[common.HashLength-len(blob):], blob)
-			case int64:
-				blob := big.NewInt(rule).Bytes()
-				copy(topic[common.HashLength-len(blob):], blob)
-			case uint8:
-				blob := new(big.Int).SetUint64(uint64(rule)).Bytes()
-				copy(topic[common.HashLength-len(blob):], blob)
-			case uint16:
-				blob := new(big.Int).SetUint64(uint64(rule)).Bytes()
-				copy(topic[common.HashLength-len(blob):], blob)
-			case uint32:
-				blob := new(big.Int).SetUint64(uint64(rule)).Bytes()
-				copy(topic[common.HashLength-len(blob):], blob)
-			case uint64:
-				blob := new(big.Int).SetUint64(rule).Bytes()
-				copy(topic[common.HashLength-len(blob):], blob)
-			case string:
-				hash := crypto.Keccak256Hash([]byte(rule))
-				copy(topic[:], hash[:])
-			case []byte:
-				hash := crypto.Keccak256Hash(rule)
-				copy(topic[:], hash[:])
-
-			default:
-				// Attempt to generate the topic from funky types
-				val := reflect.ValueOf(rule)
-
-				switch {
-				case val.Kind() == reflect.Array && reflect.TypeOf(rule).Elem().Kind() == reflect.Uint8:
-					reflect.Copy(reflect.ValueOf(topic[common.HashLength-val.Len():]), val)
+// WatchLogs subscribes to contract logs for future blocks, returning a
+// subscription object that can be used to tear down the watcher.
+func (c *ContractLogFilterer) WatchLogs(opts *bind.WatchOpts, names []string, query ...[]interface{}) (chan types.Log, event.Subscription, error) {
+	// Don't crash on a lazy user
+	if opts == nil {
+		opts = new(bind.WatchOpts)
+	}
+
+	topics, err := makeEventTopics(c.abi, names, query...)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Start the background filtering
+	logs := make(chan types.Log, 128)
 
+	config := ethereum.FilterQuery{
+		Addresses: []common.Address{c.address},
+		Topics:    topics,
+	}
+	if opts.Start != nil {
+		config.FromBlock = new(big.Int).SetUint64(*opts.Start)
+	}
+
+	sub, err := c.filterer.SubscribeFilterLogs(ensureContext(opts.Context), config, logs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return logs, sub, nil
+}
+
+// makeEventTopics appends the selector of events with the given names to the query parameters
+// and constructs the topic set.
+func makeEventTopics(abi abi.ABI, names []string, query ...[]interface{}) ([][]common.Hash, error) {
+	var eventNameRule []interface{}
+	for _, name := range names {
+		eventNameRule = append(eventNameRule, abi.Events[name].Id())
+	}
+
+	// Append the event selector to the query parameters and construct the topic set
+	query = append([][]interface{}{eventNameRule}, query...)
+
+	return MakeTopics(query...)
+}
+
+// ReadLogs reads all logs delivered by FilterLogs of ContractLogFilterer until the subscription ends.
+func ReadLogs(logs <-chan types.Log, sub event.Subscription) ([]types.Log, error) {
+	defer sub.Unsubscribe()
+
+	var res []types.Log
+	for {
+		select {
+		case log := <-logs:
+			res = append(res, log)
+		case err := <-sub.Err():
+			if err != nil {
+				return nil, err
+			}
+			// producer is done, read what's left in the buffer
+			for {
+				select {
+				case log := <-logs:
+					res = append(res, log)
 				default:
-					return nil, fmt.Errorf("unsupported indexed type: %T", rule)
+					return res, nil
 				}
 			}
-			topics[i] = append(topics[i], topic)
 		}
 	}
-	return topics, nil
 }
 
 // ensureContext is a helper method to ensure a context is not nil, even if the
-// user specified it as such.
+// user specified it as such. Nil context is tolerated for compatibility only, context without
+// cancellation is used instead.
 func ensureContext(ctx context.Context) context.Context {
 	if ctx == nil {
-		return context.TODO()
+		return context.Background()
 	}
 	return ctx
 }
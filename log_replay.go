@@ -0,0 +1,185 @@
+package ethereum
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// LoadLogsJSON loads logs from JSON array of logs in the format returned by eth_getLogs.
+func LoadLogsJSON(r io.Reader) (SliceLogFilterer, error) {
+	var logs []*types.Log
+	if err := json.NewDecoder(r).Decode(&logs); err != nil {
+		return nil, fmt.Errorf("failed to decode logs: %v", err)
+	}
+	return SliceLogFilterer(logs), nil
+}
+
+// CSV columns of logs accepted by LoadLogsCSV. Topics are separated by space, data is hex-encoded.
+const (
+	LogColumnAddress     = "address"
+	LogColumnTopics      = "topics"
+	LogColumnData        = "data"
+	LogColumnBlockNumber = "blockNumber"
+	LogColumnTxHash      = "transactionHash"
+	LogColumnTxIndex     = "transactionIndex"
+	LogColumnBlockHash   = "blockHash"
+	LogColumnLogIndex    = "logIndex"
+	LogColumnRemoved     = "removed"
+)
+
+// LoadLogsCSV loads logs from CSV with a header row. Columns are matched by name (see LogColumn* constants),
+// only address and topics columns are required, missing columns are left zero.
+func LoadLogsCSV(r io.Reader) (SliceLogFilterer, error) {
+	cr := csv.NewReader(r)
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %v", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+	for _, name := range []string{LogColumnAddress, LogColumnTopics} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("CSV column %v is missing", name)
+		}
+	}
+
+	var logs SliceLogFilterer
+	for line := 2; ; line++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV line %v: %v", line, err)
+		}
+
+		log, err := parseLogRecord(columns, record)
+		if err != nil {
+			return nil, fmt.Errorf("CSV line %v: %v", line, err)
+		}
+		logs = append(logs, log)
+	}
+
+	return logs, nil
+}
+
+func parseLogRecord(columns map[string]int, record []string) (*types.Log, error) {
+	field := func(name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	parseUint := func(name string) (uint64, error) {
+		s := field(name)
+		if s == "" {
+			return 0, nil
+		}
+		v, err := strconv.ParseUint(s, 0, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %v: %v", name, err)
+		}
+		return v, nil
+	}
+
+	log := &types.Log{
+		Address:   common.HexToAddress(field(LogColumnAddress)),
+		TxHash:    common.HexToHash(field(LogColumnTxHash)),
+		BlockHash: common.HexToHash(field(LogColumnBlockHash)),
+	}
+	for _, topic := range strings.Fields(field(LogColumnTopics)) {
+		log.Topics = append(log.Topics, common.HexToHash(topic))
+	}
+	if data := field(LogColumnData); data != "" && data != "0x" {
+		b, err := hexutil.Decode(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %v: %v", LogColumnData, err)
+		}
+		log.Data = b
+	}
+
+	var err error
+	if log.BlockNumber, err = parseUint(LogColumnBlockNumber); err != nil {
+		return nil, err
+	}
+	txIndex, err := parseUint(LogColumnTxIndex)
+	if err != nil {
+		return nil, err
+	}
+	log.TxIndex = uint(txIndex)
+	logIndex, err := parseUint(LogColumnLogIndex)
+	if err != nil {
+		return nil, err
+	}
+	log.Index = uint(logIndex)
+	if s := field(LogColumnRemoved); s != "" {
+		if log.Removed, err = strconv.ParseBool(s); err != nil {
+			return nil, fmt.Errorf("invalid %v: %v", LogColumnRemoved, err)
+		}
+	}
+
+	return log, nil
+}
+
+// Select returns logs matching addresses and block range of the query (topics aren't matched).
+func (logs SliceLogFilterer) Select(query ethereum.FilterQuery) SliceLogFilterer {
+	res := make(SliceLogFilterer, 0, len(logs))
+Logs:
+	for _, log := range logs {
+		if log == nil {
+			continue
+		}
+		if query.FromBlock != nil && query.FromBlock.Sign() >= 0 && log.BlockNumber < query.FromBlock.Uint64() {
+			continue
+		}
+		if query.ToBlock != nil && query.ToBlock.Sign() >= 0 && log.BlockNumber > query.ToBlock.Uint64() {
+			continue
+		}
+		if len(query.Addresses) > 0 {
+			for _, addr := range query.Addresses {
+				if log.Address == addr {
+					res = append(res, log)
+					continue Logs
+				}
+			}
+			continue
+		}
+		res = append(res, log)
+	}
+	return res
+}
+
+// replayLogFilterer filters stored logs by addresses and block range in addition to topics.
+type replayLogFilterer struct {
+	logs SliceLogFilterer
+}
+
+func (f replayLogFilterer) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	return f.logs.Select(query).FilterLogs(ctx, query)
+}
+
+func (f replayLogFilterer) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	return f.logs.Select(query).SubscribeFilterLogs(ctx, query, ch)
+}
+
+// NewReplayContractLogFilterer creates an instance of ContractLogFilterer which filters stored logs
+// (e.g. loaded with LoadLogsJSON or LoadLogsCSV) instead of querying the blockchain. Unlike plain SliceLogFilterer,
+// logs are also filtered by the contract address and the block range of filter options, so logs of several
+// contracts can be replayed from a single export.
+func NewReplayContractLogFilterer(address common.Address, abi abi.ABI, logs SliceLogFilterer) *ContractLogFilterer {
+	return NewContractLogFilterer(address, abi, replayLogFilterer{logs: logs})
+}
@@ -0,0 +1,85 @@
+package ethereum
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const testLogsCSV = `address,topics,data,blockNumber,transactionHash,logIndex
+0x0000000000000000000000000000000000000001,0x01 0x02,0x1234,10,0xaa,0
+0x0000000000000000000000000000000000000002,0x01,0x,11,0xbb,1
+0x0000000000000000000000000000000000000001,0x03,,12,0xcc,0
+`
+
+func TestLoadLogsCSV(t *testing.T) {
+	logs, err := LoadLogsCSV(strings.NewReader(testLogsCSV))
+	if err != nil {
+		t.Fatalf("LoadLogsCSV: %v", err)
+	}
+	if len(logs) != 3 {
+		t.Fatalf("expected 3 logs, but got %v", len(logs))
+	}
+
+	l := logs[0]
+	if l.Address != common.HexToAddress("0x01") {
+		t.Errorf("expected address 0x01, but got %v", l.Address.Hex())
+	}
+	if len(l.Topics) != 2 || l.Topics[1] != common.HexToHash("0x02") {
+		t.Errorf("expected topics [0x01 0x02], but got %v", l.Topics)
+	}
+	if len(l.Data) != 2 || l.Data[0] != 0x12 {
+		t.Errorf("expected data 0x1234, but got %x", l.Data)
+	}
+	if l.BlockNumber != 10 {
+		t.Errorf("expected block number 10, but got %v", l.BlockNumber)
+	}
+}
+
+func TestLoadLogsCSV_MissingColumn(t *testing.T) {
+	if _, err := LoadLogsCSV(strings.NewReader("address,data\n")); err == nil {
+		t.Errorf("expected error, but got nil")
+	}
+}
+
+func TestLoadLogsJSON(t *testing.T) {
+	const js = `[{"address":"0x0000000000000000000000000000000000000001","topics":["0x0000000000000000000000000000000000000000000000000000000000000001"],"data":"0x","blockNumber":"0xa","transactionHash":"0x00000000000000000000000000000000000000000000000000000000000000aa","transactionIndex":"0x0","blockHash":"0x00000000000000000000000000000000000000000000000000000000000000bb","logIndex":"0x0","removed":false}]`
+
+	logs, err := LoadLogsJSON(strings.NewReader(js))
+	if err != nil {
+		t.Fatalf("LoadLogsJSON: %v", err)
+	}
+	if len(logs) != 1 || logs[0].BlockNumber != 10 {
+		t.Errorf("expected one log of block 10, but got %v", logs)
+	}
+}
+
+func TestSliceLogFilterer_Select(t *testing.T) {
+	logs, err := LoadLogsCSV(strings.NewReader(testLogsCSV))
+	if err != nil {
+		t.Fatalf("LoadLogsCSV: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		query    ethereum.FilterQuery
+		expected int
+	}{
+		{"all", ethereum.FilterQuery{}, 3},
+		{"address", ethereum.FilterQuery{Addresses: []common.Address{common.HexToAddress("0x01")}}, 2},
+		{"from block", ethereum.FilterQuery{FromBlock: big.NewInt(11)}, 2},
+		{"block range", ethereum.FilterQuery{FromBlock: big.NewInt(11), ToBlock: big.NewInt(11)}, 1},
+		{"address and range", ethereum.FilterQuery{Addresses: []common.Address{common.HexToAddress("0x01")}, ToBlock: big.NewInt(11)}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := len(logs.Select(tt.query)); got != tt.expected {
+				t.Errorf("expected %v logs, but got %v", tt.expected, got)
+			}
+		})
+	}
+}
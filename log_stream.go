@@ -0,0 +1,131 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// DefaultLogStreamBlocks is the number of blocks queried at once by StreamLogs when LogStreamOptions.BlocksPerQuery
+// is zero.
+const DefaultLogStreamBlocks = 10000
+
+// LogStreamOptions configures StreamLogs.
+type LogStreamOptions struct {
+	// BlocksPerQuery is the number of blocks in a single eth_getLogs query (DefaultLogStreamBlocks when it's zero).
+	// The query which fails (e.g. providers limit the number of logs returned) is retried with the range split
+	// in halves, smaller ranges are used for the rest of the stream.
+	BlocksPerQuery uint64
+	// Buffer is the capacity of the channel of logs (128 when it's zero).
+	Buffer int
+}
+
+// StreamLogs filters logs of the query block range in chunks of blocks and delivers them to the channel in order
+// as chunks complete, instead of accumulating all of them, so memory stays bounded when backfilling years of
+// events. Both FromBlock and ToBlock of the query must be set, BlockHash must not. The subscription ends when
+// all logs are delivered, or with the error of the query which failed for a single block, or with the error of ctx
+// when it's done before all logs are delivered.
+func StreamLogs(ctx context.Context, filterer ethereum.LogFilterer, query ethereum.FilterQuery, opts *LogStreamOptions) (chan types.Log, event.Subscription, error) {
+	if query.FromBlock == nil || query.ToBlock == nil || query.FromBlock.Sign() < 0 || query.ToBlock.Sign() < 0 {
+		return nil, nil, errors.New("StreamLogs: FromBlock and ToBlock must be set")
+	}
+	if query.BlockHash != nil {
+		return nil, nil, errors.New("StreamLogs: BlockHash is not supported")
+	}
+	if opts == nil {
+		opts = new(LogStreamOptions)
+	}
+	size := opts.BlocksPerQuery
+	if size == 0 {
+		size = DefaultLogStreamBlocks
+	}
+	buffer := opts.Buffer
+	if buffer == 0 {
+		buffer = 128
+	}
+	from, to := query.FromBlock.Uint64(), query.ToBlock.Uint64()
+
+	logs := make(chan types.Log, buffer)
+	sub := event.NewSubscription(func(quit <-chan struct{}) error {
+		ctx, cancel := context.WithCancel(ensureContext(ctx))
+		defer cancel()
+		go func() {
+			select {
+			case <-quit:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+
+		for start := from; start <= to; {
+			end := to
+			if to-start >= size {
+				end = start + size - 1
+			}
+
+			q := query
+			q.FromBlock = new(big.Int).SetUint64(start)
+			q.ToBlock = new(big.Int).SetUint64(end)
+			chunk, err := filterer.FilterLogs(ctx, q)
+			if err != nil {
+				if ctx.Err() != nil {
+					select {
+					case <-quit:
+						return nil // unsubscribed
+					default:
+						return ctx.Err()
+					}
+				}
+				if end > start {
+					size = (end - start + 1) / 2
+					continue
+				}
+				return fmt.Errorf("failed to filter logs of block %v: %v", start, err)
+			}
+
+			for _, log := range chunk {
+				select {
+				case logs <- log:
+				case <-quit:
+					return nil
+				}
+			}
+			start = end + 1
+		}
+		return nil
+	})
+
+	return logs, sub, nil
+}
+
+// StreamLogs works like FilterLogs, but logs are filtered in chunks of blocks with StreamLogs,
+// `opts.End` must be set.
+func (c *ContractLogFilterer) StreamLogs(opts *bind.FilterOpts, streamOpts *LogStreamOptions, names []string, query ...[]interface{}) (chan types.Log, event.Subscription, error) {
+	// Don't crash on a lazy user
+	if opts == nil {
+		opts = new(bind.FilterOpts)
+	}
+	if opts.End == nil {
+		return nil, nil, errors.New("StreamLogs: end block must be set")
+	}
+
+	topics, err := makeEventTopics(c.abi, names, query...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	config := ethereum.FilterQuery{
+		Addresses: []common.Address{c.address},
+		Topics:    topics,
+		FromBlock: new(big.Int).SetUint64(opts.Start),
+		ToBlock:   new(big.Int).SetUint64(*opts.End),
+	}
+	return StreamLogs(opts.Context, c.filterer, config, streamOpts)
+}
@@ -0,0 +1,98 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// limitedLogFilterer refuses queries of more than maxBlocks blocks and records queried ranges.
+type limitedLogFilterer struct {
+	replayLogFilterer
+	maxBlocks uint64
+	queries   [][2]uint64
+}
+
+func (f *limitedLogFilterer) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	from, to := query.FromBlock.Uint64(), query.ToBlock.Uint64()
+	f.queries = append(f.queries, [2]uint64{from, to})
+	if to-from+1 > f.maxBlocks {
+		return nil, errors.New("query returned more than 10000 results")
+	}
+	return f.replayLogFilterer.FilterLogs(ctx, query)
+}
+
+func TestStreamLogs(t *testing.T) {
+	var logs SliceLogFilterer
+	for n := uint64(0); n < 100; n++ {
+		logs = append(logs, &types.Log{BlockNumber: n})
+	}
+
+	tests := []struct {
+		name      string
+		from, to  int64
+		blocks    uint64
+		maxBlocks uint64
+		queries   int
+	}{
+		{"single query", 0, 99, 100, 100, 1},
+		{"chunks", 10, 59, 20, 100, 3},
+		{"split failed queries", 0, 99, 100, 30, 6}, // 0-99 fails, 0-49 fails, 0-24, 25-49, 50-74, 75-99
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &limitedLogFilterer{replayLogFilterer: replayLogFilterer{logs: logs}, maxBlocks: tt.maxBlocks}
+			query := ethereum.FilterQuery{FromBlock: big.NewInt(tt.from), ToBlock: big.NewInt(tt.to)}
+			ch, sub, err := StreamLogs(context.Background(), f, query, &LogStreamOptions{BlocksPerQuery: tt.blocks})
+			if err != nil {
+				t.Fatalf("StreamLogs: %v", err)
+			}
+
+			res, err := ReadLogs(ch, sub)
+			if err != nil {
+				t.Fatalf("ReadLogs: %v", err)
+			}
+			if len(res) != int(tt.to-tt.from+1) {
+				t.Fatalf("expected %v logs, but got %v", tt.to-tt.from+1, len(res))
+			}
+			for i, log := range res {
+				if log.BlockNumber != uint64(tt.from)+uint64(i) {
+					t.Errorf("expected log of block %v, but got %v", uint64(tt.from)+uint64(i), log.BlockNumber)
+				}
+			}
+			if len(f.queries) != tt.queries {
+				t.Errorf("expected %v queries, but got %v: %v", tt.queries, len(f.queries), f.queries)
+			}
+		})
+	}
+}
+
+func TestStreamLogs_Errors(t *testing.T) {
+	f := &limitedLogFilterer{maxBlocks: 0}
+	ch, sub, err := StreamLogs(context.Background(), f, ethereum.FilterQuery{FromBlock: big.NewInt(0), ToBlock: big.NewInt(3)}, nil)
+	if err != nil {
+		t.Fatalf("StreamLogs: %v", err)
+	}
+	if _, err := ReadLogs(ch, sub); err == nil {
+		t.Errorf("expected error of the query failed for a single block")
+	}
+
+	if _, _, err := StreamLogs(context.Background(), f, ethereum.FilterQuery{FromBlock: big.NewInt(0)}, nil); err == nil {
+		t.Errorf("expected error when ToBlock isn't set")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	ch, sub, err = StreamLogs(ctx, f, ethereum.FilterQuery{FromBlock: big.NewInt(0), ToBlock: big.NewInt(3)}, nil)
+	if err != nil {
+		t.Fatalf("StreamLogs: %v", err)
+	}
+	if _, err := ReadLogs(ch, sub); err != context.Canceled {
+		t.Errorf("expected context.Canceled when context is done, but got %v", err)
+	}
+}
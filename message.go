@@ -0,0 +1,53 @@
+package ethereum
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// TextHash returns the hash of the message prefixed according to EIP-191 (version 0x45), which is signed by
+// personal_sign and eth_sign of wallets:
+//
+//	keccak256("\x19Ethereum Signed Message:\n" + len(message) + message)
+func TextHash(message []byte) common.Hash {
+	return crypto.Keccak256Hash([]byte(fmt.Sprintf("\x19Ethereum Signed Message:\n%d", len(message))), message)
+}
+
+// SignMessage signs the message like personal_sign of wallets and returns 65 bytes signature [R || S || V],
+// where V is 27 or 28.
+func (k *Key) SignMessage(message []byte) ([]byte, error) {
+	hash := TextHash(message)
+	sig, err := crypto.Sign(hash[:], k.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	sig[64] += 27
+	return sig, nil
+}
+
+// RecoverMessageSigner returns the address of the account which signed the message like personal_sign of wallets.
+// V of the signature can be 0/1 or 27/28.
+func RecoverMessageSigner(message []byte, sig []byte) (common.Address, error) {
+	if len(sig) != 65 {
+		return common.Address{}, fmt.Errorf("invalid signature length %v", len(sig))
+	}
+
+	recoverable := make([]byte, 65)
+	copy(recoverable, sig)
+	if recoverable[64] >= 27 {
+		recoverable[64] -= 27
+	}
+	if recoverable[64] > 1 {
+		return common.Address{}, errors.New("invalid signature V")
+	}
+
+	hash := TextHash(message)
+	pub, err := crypto.SigToPub(hash[:], recoverable)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
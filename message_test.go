@@ -0,0 +1,39 @@
+package ethereum
+
+import (
+	"testing"
+)
+
+func TestTextHash(t *testing.T) {
+	if h := TextHash([]byte("hello")).Hex(); h != "0x50b2c43fd39106bafbba0da34fc430e1f91e3c96ea2acee2bc34119f92b37750" {
+		t.Errorf("unexpected hash %v", h)
+	}
+}
+
+func TestKey_SignMessage(t *testing.T) {
+	key, err := NewKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := []byte("hello")
+	sig, err := key.SignMessage(msg)
+	if err != nil {
+		t.Fatalf("SignMessage: %v", err)
+	}
+	if sig[64] != 27 && sig[64] != 28 {
+		t.Errorf("expected V to be 27 or 28, but got %v", sig[64])
+	}
+
+	signer, err := RecoverMessageSigner(msg, sig)
+	if err != nil {
+		t.Fatalf("RecoverMessageSigner: %v", err)
+	}
+	if signer != key.Address {
+		t.Errorf("expected signer %v, but got %v", key.Address.Hex(), signer.Hex())
+	}
+
+	if signer, err := RecoverMessageSigner([]byte("other"), sig); err == nil && signer == key.Address {
+		t.Errorf("expected signature of other message not to match")
+	}
+}
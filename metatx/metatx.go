@@ -0,0 +1,160 @@
+// Package metatx supports EIP-2771 meta-transactions: users sign forward requests off-chain and a relayer
+// submits them through a trusted forwarder contract (OpenZeppelin MinimalForwarder compatible), paying for gas.
+package metatx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/monetha/go-ethereum"
+	"github.com/monetha/go-ethereum/eip712"
+)
+
+var forwardRequestTypeHash = crypto.Keccak256Hash([]byte("ForwardRequest(address from,address to,uint256 value,uint256 gas,uint256 nonce,bytes data)"))
+
+// ErrInvalidSignature is returned when the forward request isn't signed by its From account.
+var ErrInvalidSignature = errors.New("metatx: signature doesn't match request")
+
+// ForwardRequest is the call which the forwarder makes on behalf of From account.
+type ForwardRequest struct {
+	From  common.Address
+	To    common.Address
+	Value *big.Int
+	Gas   *big.Int
+	Nonce *big.Int
+	Data  []byte
+}
+
+// Hash returns EIP-712 hash of the request which must be signed by From account.
+func (r *ForwardRequest) Hash(domain eip712.Domain) common.Hash {
+	return domain.Hash(crypto.Keccak256Hash(
+		forwardRequestTypeHash[:],
+		r.From.Hash().Bytes(),
+		r.To.Hash().Bytes(),
+		eip712.Word(r.Value),
+		eip712.Word(r.Gas),
+		eip712.Word(r.Nonce),
+		crypto.Keccak256(r.Data),
+	))
+}
+
+// Sign signs the request with the key of From account.
+func (r *ForwardRequest) Sign(domain eip712.Domain, key *ethereum.Key) ([]byte, error) {
+	if key.Address != r.From {
+		return nil, fmt.Errorf("metatx: key of %v can't sign request from %v", key.Address.Hex(), r.From.Hex())
+	}
+	return eip712.Sign(r.Hash(domain), key)
+}
+
+// Verify checks that the request is signed by From account.
+func (r *ForwardRequest) Verify(domain eip712.Domain, sig []byte) error {
+	signer, err := eip712.Recover(r.Hash(domain), sig)
+	if err != nil {
+		return err
+	}
+	if signer != r.From {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+const forwarderABI = `[{"inputs":[{"name":"from","type":"address"}],"name":"getNonce","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},{"inputs":[{"components":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"value","type":"uint256"},{"name":"gas","type":"uint256"},{"name":"nonce","type":"uint256"},{"name":"data","type":"bytes"}],"name":"req","type":"tuple"},{"name":"signature","type":"bytes"}],"name":"execute","outputs":[{"name":"","type":"bool"},{"name":"","type":"bytes"}],"stateMutability":"payable","type":"function"}]`
+
+var parsedForwarderABI = ethereum.MustParseABI(forwarderABI)
+
+// Forwarder is the trusted forwarder contract.
+type Forwarder struct {
+	Domain   eip712.Domain
+	contract *bind.BoundContract
+}
+
+// NewForwarder creates an instance of Forwarder bound to the contract at `address`. Name and version of EIP-712
+// domain must match the ones the forwarder was deployed with (e.g. "MinimalForwarder" and "0.0.1").
+func NewForwarder(address common.Address, name, version string, chainID *big.Int, backend bind.ContractBackend) *Forwarder {
+	return &Forwarder{
+		Domain: eip712.Domain{
+			Name:              name,
+			Version:           version,
+			ChainID:           chainID,
+			VerifyingContract: address,
+		},
+		contract: bind.NewBoundContract(address, parsedForwarderABI, backend, backend, backend),
+	}
+}
+
+// Nonce returns the nonce which must be used in the next request from the account.
+func (f *Forwarder) Nonce(ctx context.Context, from common.Address) (*big.Int, error) {
+	var nonce *big.Int
+	if err := f.contract.Call(&bind.CallOpts{Context: ctx}, &nonce, "getNonce", from); err != nil {
+		return nil, fmt.Errorf("metatx: getNonce: %v", err)
+	}
+	return nonce, nil
+}
+
+// NewRequest creates the request from the account with the current forwarder nonce.
+func (f *Forwarder) NewRequest(ctx context.Context, from, to common.Address, value *big.Int, gas uint64, data []byte) (*ForwardRequest, error) {
+	nonce, err := f.Nonce(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		value = new(big.Int)
+	}
+	return &ForwardRequest{
+		From:  from,
+		To:    to,
+		Value: value,
+		Gas:   new(big.Int).SetUint64(gas),
+		Nonce: nonce,
+		Data:  data,
+	}, nil
+}
+
+// Execute submits the signed request to the forwarder. `opts` are used to send the transaction
+// and pay for gas, `opts.Value` is set to the value of the request.
+func (f *Forwarder) Execute(opts *bind.TransactOpts, req *ForwardRequest, sig []byte) (*types.Transaction, error) {
+	execOpts := *opts
+	execOpts.Value = req.Value
+	tx, err := f.contract.Transact(&execOpts, "execute", *req, sig)
+	if err != nil {
+		return nil, fmt.Errorf("metatx: execute: %v", err)
+	}
+	return tx, nil
+}
+
+// Relayer wraps signed requests received from users into transactions sent from the session account.
+type Relayer struct {
+	forwarder *Forwarder
+	session   *ethereum.Session
+}
+
+// NewRelayer creates an instance of Relayer.
+func NewRelayer(forwarder *Forwarder, session *ethereum.Session) *Relayer {
+	return &Relayer{forwarder: forwarder, session: session}
+}
+
+// Relay checks the signature and nonce of the request and submits it to the forwarder.
+func (r *Relayer) Relay(ctx context.Context, req *ForwardRequest, sig []byte) (*types.Transaction, error) {
+	if err := req.Verify(r.forwarder.Domain, sig); err != nil {
+		return nil, err
+	}
+
+	nonce, err := r.forwarder.Nonce(ctx, req.From)
+	if err != nil {
+		return nil, err
+	}
+	if req.Nonce == nil || nonce.Cmp(req.Nonce) != 0 {
+		return nil, fmt.Errorf("metatx: invalid request nonce %v, expected %v", req.Nonce, nonce)
+	}
+
+	r.session.Log("Relaying meta-transaction", "from", req.From.Hex(), "to", req.To.Hex(), "nonce", req.Nonce)
+
+	opts := r.session.WithContext(ctx).TransactOpts
+	return r.forwarder.Execute(&opts, req, sig)
+}
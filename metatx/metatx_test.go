@@ -0,0 +1,42 @@
+package metatx
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/monetha/go-ethereum"
+	"github.com/monetha/go-ethereum/eip712"
+)
+
+func TestForwardRequest_SignVerify(t *testing.T) {
+	key, _ := ethereum.NewKey()
+	otherKey, _ := ethereum.NewKey()
+
+	domain := eip712.Domain{Name: "MinimalForwarder", Version: "0.0.1", ChainID: big.NewInt(1), VerifyingContract: common.HexToAddress("0x1234")}
+	req := &ForwardRequest{
+		From:  key.Address,
+		To:    common.HexToAddress("0x5678"),
+		Value: new(big.Int),
+		Gas:   big.NewInt(100000),
+		Nonce: big.NewInt(0),
+	}
+
+	if _, err := req.Sign(domain, otherKey); err == nil {
+		t.Errorf("expected error when signing with key of other account")
+	}
+
+	sig, err := req.Sign(domain, key)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	if err := req.Verify(domain, sig); err != nil {
+		t.Errorf("expected valid signature, but got %v", err)
+	}
+
+	tampered := *req
+	tampered.Nonce = big.NewInt(1)
+	if err := tampered.Verify(domain, sig); err != ErrInvalidSignature {
+		t.Errorf("expected ErrInvalidSignature, but got %v", err)
+	}
+}
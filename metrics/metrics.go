@@ -0,0 +1,31 @@
+// Package metrics defines instrumentation hooks of long-running components, like blocksource.BlockSource,
+// gasestimator.GasPriceEstimator and backend.HandleNonceBackend.
+package metrics
+
+import "math/big"
+
+// Collector receives metrics of long-running components. Implementations must be safe for concurrent use.
+type Collector interface {
+	// BlockDelivered is called when the block with the given number is delivered to the consumer.
+	BlockDelivered(number *big.Int)
+	// HeadLag is called with the number of blocks the last delivered block is behind the chain head.
+	HeadLag(blocks uint64)
+	// RPCError is called when an RPC call made by the component fails.
+	RPCError(component, method string)
+	// GasPrice is called with the gas price (in wei) each time it's successfully retrieved.
+	GasPrice(price *big.Int)
+	// InFlightTxs is called with the number of sent transactions of the address which aren't known to be mined
+	// each time it changes.
+	InFlightTxs(address string, count int)
+}
+
+// Nop is a Collector which discards all metrics.
+var Nop Collector = nop{}
+
+type nop struct{}
+
+func (nop) BlockDelivered(number *big.Int)        {}
+func (nop) HeadLag(blocks uint64)                 {}
+func (nop) RPCError(component, method string)     {}
+func (nop) GasPrice(price *big.Int)               {}
+func (nop) InFlightTxs(address string, count int) {}
@@ -0,0 +1,147 @@
+// Package prometheus provides Prometheus implementation of metrics.Collector.
+package prometheus
+
+import (
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/monetha/go-ethereum/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// make sure Collector implements metrics.Collector and prometheus.Collector
+var (
+	_ metrics.Collector    = &Collector{}
+	_ prometheus.Collector = &Collector{}
+)
+
+// Collector collects metrics of long-running components and exposes them to Prometheus. It needs to be registered
+// in Prometheus registry, e.g. prometheus.MustRegister(c).
+type Collector struct {
+	blocksDelivered prometheus.Counter
+	lastBlock       prometheus.Gauge
+	headLag         prometheus.Gauge
+	rpcErrors       *prometheus.CounterVec
+	gasPrice        prometheus.Gauge
+	gasPriceAge     prometheus.GaugeFunc
+	inFlightTxs     *prometheus.GaugeVec
+
+	mu               sync.RWMutex
+	gasPriceUpdateAt time.Time
+}
+
+// New creates an instance of Collector. All metric names are prefixed with the given namespace.
+func New(namespace string) *Collector {
+	c := &Collector{
+		blocksDelivered: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "blocksource",
+			Name:      "blocks_delivered_total",
+			Help:      "Number of blocks delivered to the consumer.",
+		}),
+		lastBlock: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "blocksource",
+			Name:      "last_block_number",
+			Help:      "Number of the last delivered block.",
+		}),
+		headLag: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "blocksource",
+			Name:      "head_lag_blocks",
+			Help:      "Number of blocks the last delivered block is behind the chain head.",
+		}),
+		rpcErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "rpc_errors_total",
+			Help:      "Number of failed RPC calls.",
+		}, []string{"component", "method"}),
+		gasPrice: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "gasestimator",
+			Name:      "gas_price_wei",
+			Help:      "Last retrieved gas price in wei.",
+		}),
+		inFlightTxs: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "backend",
+			Name:      "inflight_transactions",
+			Help:      "Number of sent transactions which aren't known to be mined.",
+		}, []string{"address"}),
+	}
+
+	c.gasPriceAge = prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "gasestimator",
+		Name:      "gas_price_age_seconds",
+		Help:      "Number of seconds since gas price was successfully retrieved last time.",
+	}, c.secondsSinceGasPriceUpdate)
+
+	return c
+}
+
+// BlockDelivered implements metrics.Collector.
+func (c *Collector) BlockDelivered(number *big.Int) {
+	c.blocksDelivered.Inc()
+	f, _ := new(big.Float).SetInt(number).Float64()
+	c.lastBlock.Set(f)
+}
+
+// HeadLag implements metrics.Collector.
+func (c *Collector) HeadLag(blocks uint64) {
+	c.headLag.Set(float64(blocks))
+}
+
+// RPCError implements metrics.Collector.
+func (c *Collector) RPCError(component, method string) {
+	c.rpcErrors.WithLabelValues(component, method).Inc()
+}
+
+// GasPrice implements metrics.Collector.
+func (c *Collector) GasPrice(price *big.Int) {
+	f, _ := new(big.Float).SetInt(price).Float64()
+	c.gasPrice.Set(f)
+
+	c.mu.Lock()
+	c.gasPriceUpdateAt = time.Now()
+	c.mu.Unlock()
+}
+
+// InFlightTxs implements metrics.Collector.
+func (c *Collector) InFlightTxs(address string, count int) {
+	c.inFlightTxs.WithLabelValues(address).Set(float64(count))
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.blocksDelivered.Describe(ch)
+	c.lastBlock.Describe(ch)
+	c.headLag.Describe(ch)
+	c.rpcErrors.Describe(ch)
+	c.gasPrice.Describe(ch)
+	c.gasPriceAge.Describe(ch)
+	c.inFlightTxs.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.blocksDelivered.Collect(ch)
+	c.lastBlock.Collect(ch)
+	c.headLag.Collect(ch)
+	c.rpcErrors.Collect(ch)
+	c.gasPrice.Collect(ch)
+	c.gasPriceAge.Collect(ch)
+	c.inFlightTxs.Collect(ch)
+}
+
+func (c *Collector) secondsSinceGasPriceUpdate() float64 {
+	c.mu.RLock()
+	updatedAt := c.gasPriceUpdateAt
+	c.mu.RUnlock()
+
+	if updatedAt.IsZero() {
+		return 0
+	}
+	return time.Since(updatedAt).Seconds()
+}
@@ -0,0 +1,58 @@
+package prometheus
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestCollector(t *testing.T) {
+	c := New("test")
+	reg := prometheus.NewRegistry()
+	if err := reg.Register(c); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+
+	c.BlockDelivered(big.NewInt(10))
+	c.BlockDelivered(big.NewInt(11))
+	c.HeadLag(3)
+	c.RPCError("blocksource", "eth_blockNumber")
+	c.GasPrice(big.NewInt(2000000000))
+	c.InFlightTxs("0x1111111111111111111111111111111111111111", 2)
+
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("Gather: %v", err)
+	}
+
+	values := make(map[string]float64)
+	for _, mf := range mfs {
+		for _, m := range mf.GetMetric() {
+			switch {
+			case m.Counter != nil:
+				values[mf.GetName()] = m.Counter.GetValue()
+			case m.Gauge != nil:
+				values[mf.GetName()] = m.Gauge.GetValue()
+			}
+		}
+	}
+
+	expected := map[string]float64{
+		"test_blocksource_blocks_delivered_total": 2,
+		"test_blocksource_last_block_number":      11,
+		"test_blocksource_head_lag_blocks":        3,
+		"test_rpc_errors_total":                   1,
+		"test_gasestimator_gas_price_wei":         2000000000,
+		"test_backend_inflight_transactions":      2,
+	}
+	for name, value := range expected {
+		if got, ok := values[name]; !ok || got != value {
+			t.Errorf("expected %v to be %v, but got %v", name, value, got)
+		}
+	}
+
+	if _, ok := values["test_gasestimator_gas_price_age_seconds"]; !ok {
+		t.Errorf("expected test_gasestimator_gas_price_age_seconds metric")
+	}
+}
@@ -0,0 +1,178 @@
+package ethereum
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// MultiContractLogFilterer works like ContractLogFilterer, but filters the same set of events across many deployments
+// of the contract (e.g. all pair contracts of a factory).
+type MultiContractLogFilterer struct {
+	addresses []common.Address      // Deployment addresses of the contracts on the Ethereum blockchain
+	abi       abi.ABI               // Reflect based ABI to access the correct Ethereum methods
+	filterer  bind.ContractFilterer // Event filtering to interact with the blockchain
+
+	// MaxAddressesPerQuery limits the number of addresses in a single filter query, since some providers limit it.
+	// Zero value means no limit.
+	MaxAddressesPerQuery int
+}
+
+// NewMultiContractLogFilterer creates a instance of MultiContractLogFilterer.
+func NewMultiContractLogFilterer(addresses []common.Address, abi abi.ABI, filterer bind.ContractFilterer) *MultiContractLogFilterer {
+	return &MultiContractLogFilterer{
+		addresses: addresses,
+		abi:       abi,
+		filterer:  filterer,
+	}
+}
+
+// FilterLogs filters logs of all contracts for past blocks. Logs of all contracts are merged and ordered by
+// block number and log index.
+func (c *MultiContractLogFilterer) FilterLogs(opts *bind.FilterOpts, names []string, query ...[]interface{}) (chan types.Log, event.Subscription, error) {
+	// Don't crash on a lazy user
+	if opts == nil {
+		opts = new(bind.FilterOpts)
+	}
+
+	topics, err := makeEventTopics(c.abi, names, query...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var buff []types.Log
+	for _, addresses := range chunkAddresses(c.addresses, c.MaxAddressesPerQuery) {
+		config := ethereum.FilterQuery{
+			Addresses: addresses,
+			Topics:    topics,
+			FromBlock: new(big.Int).SetUint64(opts.Start),
+		}
+		if opts.End != nil {
+			config.ToBlock = new(big.Int).SetUint64(*opts.End)
+		}
+
+		ls, err := c.filterer.FilterLogs(ensureContext(opts.Context), config)
+		if err != nil {
+			return nil, nil, err
+		}
+		buff = append(buff, ls...)
+	}
+	SortLogs(buff)
+
+	logs := make(chan types.Log, 128)
+	sub := event.NewSubscription(func(quit <-chan struct{}) error {
+		for _, log := range buff {
+			select {
+			case logs <- log:
+			case <-quit:
+				return nil
+			}
+		}
+		return nil
+	})
+
+	return logs, sub, nil
+}
+
+// WatchLogs subscribes to logs of all contracts for future blocks. When addresses are split into several
+// subscriptions (see MaxAddressesPerQuery), logs from different subscriptions are delivered in order of arrival.
+func (c *MultiContractLogFilterer) WatchLogs(opts *bind.WatchOpts, names []string, query ...[]interface{}) (chan types.Log, event.Subscription, error) {
+	// Don't crash on a lazy user
+	if opts == nil {
+		opts = new(bind.WatchOpts)
+	}
+
+	topics, err := makeEventTopics(c.abi, names, query...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	logs := make(chan types.Log, 128)
+
+	var subs []ethereum.Subscription
+	unsubscribeAll := func() {
+		for _, sub := range subs {
+			sub.Unsubscribe()
+		}
+	}
+
+	for _, addresses := range chunkAddresses(c.addresses, c.MaxAddressesPerQuery) {
+		config := ethereum.FilterQuery{
+			Addresses: addresses,
+			Topics:    topics,
+		}
+		if opts.Start != nil {
+			config.FromBlock = new(big.Int).SetUint64(*opts.Start)
+		}
+
+		sub, err := c.filterer.SubscribeFilterLogs(ensureContext(opts.Context), config, logs)
+		if err != nil {
+			unsubscribeAll()
+			return nil, nil, err
+		}
+		subs = append(subs, sub)
+	}
+
+	// merge all subscriptions into one: it fails as soon as any of inner subscriptions fails
+	sub := event.NewSubscription(func(quit <-chan struct{}) error {
+		defer unsubscribeAll()
+
+		errc := make(chan error, len(subs))
+		for _, sub := range subs {
+			go func(sub ethereum.Subscription) {
+				select {
+				case err := <-sub.Err():
+					errc <- err
+				case <-quit:
+				}
+			}(sub)
+		}
+
+		select {
+		case err := <-errc:
+			return err
+		case <-quit:
+			return nil
+		}
+	})
+
+	return logs, sub, nil
+}
+
+// SortLogs sorts logs by block number and index of the log in the block.
+func SortLogs(logs []types.Log) {
+	sort.SliceStable(logs, func(i, j int) bool {
+		li, lj := &logs[i], &logs[j]
+		if li.BlockNumber != lj.BlockNumber {
+			return li.BlockNumber < lj.BlockNumber
+		}
+		return li.Index < lj.Index
+	})
+}
+
+// chunkAddresses splits addresses into chunks of chunkSize. There are no chunks when there are no addresses, as a
+// query without addresses would match logs of all contracts.
+func chunkAddresses(addresses []common.Address, chunkSize int) (chunks [][]common.Address) {
+	if len(addresses) == 0 {
+		return nil
+	}
+	if chunkSize <= 0 || len(addresses) <= chunkSize {
+		return [][]common.Address{addresses}
+	}
+
+	for i := 0; i < len(addresses); i += chunkSize {
+		end := i + chunkSize
+		if end > len(addresses) {
+			end = len(addresses)
+		}
+		chunks = append(chunks, addresses[i:end])
+	}
+
+	return
+}
@@ -0,0 +1,57 @@
+package ethereum
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestSortLogs(t *testing.T) {
+	logs := []types.Log{
+		{BlockNumber: 12, Index: 3},
+		{BlockNumber: 10, Index: 7},
+		{BlockNumber: 12, Index: 1},
+		{BlockNumber: 10, Index: 2},
+	}
+
+	SortLogs(logs)
+
+	expected := []struct{ blockNumber, index uint64 }{{10, 2}, {10, 7}, {12, 1}, {12, 3}}
+	for i, e := range expected {
+		if logs[i].BlockNumber != e.blockNumber || uint64(logs[i].Index) != e.index {
+			t.Errorf("log %v: expected (%v, %v), but got (%v, %v)", i, e.blockNumber, e.index, logs[i].BlockNumber, logs[i].Index)
+		}
+	}
+}
+
+func TestChunkAddresses(t *testing.T) {
+	addresses := make([]common.Address, 5)
+
+	testCases := []struct {
+		chunkSize int
+		expected  []int
+	}{
+		{0, []int{5}},
+		{5, []int{5}},
+		{10, []int{5}},
+		{2, []int{2, 2, 1}},
+		{1, []int{1, 1, 1, 1, 1}},
+	}
+
+	for _, tc := range testCases {
+		chunks := chunkAddresses(addresses, tc.chunkSize)
+		if len(chunks) != len(tc.expected) {
+			t.Errorf("chunkAddresses(%v): expected %v chunks, but got %v", tc.chunkSize, len(tc.expected), len(chunks))
+			continue
+		}
+		for i, l := range tc.expected {
+			if len(chunks[i]) != l {
+				t.Errorf("chunkAddresses(%v): expected length of chunk %v is %v, but got %v", tc.chunkSize, i, l, len(chunks[i]))
+			}
+		}
+	}
+	if chunks := chunkAddresses(nil, 2); len(chunks) != 0 {
+		t.Errorf("chunkAddresses: expected no chunks of empty list, but got %v", len(chunks))
+	}
+}
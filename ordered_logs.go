@@ -0,0 +1,155 @@
+package ethereum
+
+import (
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// FilterAndWatchLogs delivers logs of past blocks (starting from opts.Start) followed by logs of future blocks.
+// Logs are deduplicated by (block hash, log index) and delivered in strictly increasing order of
+// (block number, log index), so there are neither duplicates nor out-of-order logs around the switchover from
+// catch-up to live logs. Logs with Removed flag set (chain re-organization) are delivered as is, and logs of the new
+// chain from the block of the removed log onwards are delivered again.
+func (c *ContractLogFilterer) FilterAndWatchLogs(opts *bind.WatchOpts, names []string, query ...[]interface{}) (chan types.Log, event.Subscription, error) {
+	return filterAndWatchLogs(c, opts, names, query...)
+}
+
+// FilterAndWatchLogs delivers logs of past blocks (starting from opts.Start) followed by logs of future blocks.
+// See ContractLogFilterer.FilterAndWatchLogs for ordering guarantees.
+func (c *MultiContractLogFilterer) FilterAndWatchLogs(opts *bind.WatchOpts, names []string, query ...[]interface{}) (chan types.Log, event.Subscription, error) {
+	return filterAndWatchLogs(c, opts, names, query...)
+}
+
+type filterWatcher interface {
+	FilterLogs(opts *bind.FilterOpts, names []string, query ...[]interface{}) (chan types.Log, event.Subscription, error)
+	WatchLogs(opts *bind.WatchOpts, names []string, query ...[]interface{}) (chan types.Log, event.Subscription, error)
+}
+
+func filterAndWatchLogs(fw filterWatcher, opts *bind.WatchOpts, names []string, query ...[]interface{}) (chan types.Log, event.Subscription, error) {
+	// Don't crash on a lazy user
+	if opts == nil {
+		opts = new(bind.WatchOpts)
+	}
+
+	// subscribe first, so that no logs are missed while past logs are being retrieved
+	liveLogs, liveSub, err := fw.WatchLogs(&bind.WatchOpts{Context: opts.Context}, names, query...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	filterOpts := &bind.FilterOpts{Context: opts.Context}
+	if opts.Start != nil {
+		filterOpts.Start = *opts.Start
+	}
+	pastLogs, pastSub, err := fw.FilterLogs(filterOpts, names, query...)
+	if err != nil {
+		liveSub.Unsubscribe()
+		return nil, nil, err
+	}
+	past, err := ReadLogs(pastLogs, pastSub)
+	if err != nil {
+		liveSub.Unsubscribe()
+		return nil, nil, err
+	}
+	SortLogs(past)
+
+	logs := make(chan types.Log, 128)
+	sub := event.NewSubscription(func(quit <-chan struct{}) error {
+		defer liveSub.Unsubscribe()
+
+		var lc logCoordinator
+		for _, log := range past {
+			if !lc.accept(log) {
+				continue
+			}
+			select {
+			case logs <- log:
+			case <-quit:
+				return nil
+			}
+		}
+
+		for {
+			select {
+			case log := <-liveLogs:
+				if !lc.accept(log) {
+					continue
+				}
+				select {
+				case logs <- log:
+				case err := <-liveSub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-liveSub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	})
+
+	return logs, sub, nil
+}
+
+type logKey struct {
+	blockHash common.Hash
+	index     uint
+}
+
+// logCoordinator decides which logs can be delivered to consumers: it drops duplicated logs and logs
+// which would break strictly increasing order of (block number, log index).
+type logCoordinator struct {
+	delivered bool
+	lastBlock uint64
+	lastIndex uint
+	seen      map[logKey]struct{} // logs delivered for the last block number
+}
+
+func (lc *logCoordinator) accept(log types.Log) bool {
+	if log.Removed {
+		lc.rewind(log.BlockNumber)
+		return true
+	}
+
+	key := logKey{blockHash: log.BlockHash, index: log.Index}
+	if _, ok := lc.seen[key]; ok {
+		return false
+	}
+
+	if lc.delivered {
+		if log.BlockNumber < lc.lastBlock || (log.BlockNumber == lc.lastBlock && log.Index <= lc.lastIndex) {
+			return false
+		}
+	}
+
+	if !lc.delivered || log.BlockNumber != lc.lastBlock {
+		// keep only keys of the latest block, older logs are rejected by ordering anyway
+		lc.seen = make(map[logKey]struct{})
+	}
+	lc.seen[key] = struct{}{}
+
+	lc.delivered = true
+	lc.lastBlock = log.BlockNumber
+	lc.lastIndex = log.Index
+
+	return true
+}
+
+// rewind makes logs from the block onwards acceptable again: the block was removed by chain reorganization,
+// so logs of the new chain replace the delivered ones.
+func (lc *logCoordinator) rewind(block uint64) {
+	if !lc.delivered || block > lc.lastBlock {
+		return
+	}
+	if block == 0 {
+		lc.delivered = false
+	} else {
+		lc.lastBlock = block - 1
+		lc.lastIndex = ^uint(0)
+	}
+	lc.seen = nil
+}
@@ -0,0 +1,41 @@
+package ethereum
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestLogCoordinator_Accept(t *testing.T) {
+	hashA := common.HexToHash("0xa")
+	hashB := common.HexToHash("0xb")
+	hashC := common.HexToHash("0xc")
+	hashD := common.HexToHash("0xd")
+
+	steps := []struct {
+		name     string
+		log      types.Log
+		accepted bool
+	}{
+		{"first log", types.Log{BlockNumber: 10, BlockHash: hashA, Index: 5}, true},
+		{"duplicate", types.Log{BlockNumber: 10, BlockHash: hashA, Index: 5}, false},
+		{"lower index of the same block", types.Log{BlockNumber: 10, BlockHash: hashA, Index: 4}, false},
+		{"higher index of the same block", types.Log{BlockNumber: 10, BlockHash: hashA, Index: 6}, true},
+		{"older block", types.Log{BlockNumber: 9, BlockHash: hashB, Index: 100}, false},
+		{"newer block", types.Log{BlockNumber: 11, BlockHash: hashB, Index: 0}, true},
+		{"duplicate of newer block", types.Log{BlockNumber: 11, BlockHash: hashB, Index: 0}, false},
+		{"removed log", types.Log{BlockNumber: 10, BlockHash: hashA, Index: 5, Removed: true}, true},
+		{"block before the removed one", types.Log{BlockNumber: 9, BlockHash: hashB, Index: 100}, false},
+		{"new chain log of the removed block", types.Log{BlockNumber: 10, BlockHash: hashC, Index: 0}, true},
+		{"new chain log of the next block", types.Log{BlockNumber: 11, BlockHash: hashD, Index: 0}, true},
+		{"duplicate of new chain log", types.Log{BlockNumber: 11, BlockHash: hashD, Index: 0}, false},
+	}
+
+	var lc logCoordinator
+	for _, step := range steps {
+		if accepted := lc.accept(step.log); accepted != step.accepted {
+			t.Errorf("%v: expected accepted=%v, but got %v", step.name, step.accepted, accepted)
+		}
+	}
+}
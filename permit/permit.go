@@ -0,0 +1,137 @@
+// Package permit builds and signs EIP-2612 and DAI-style permits, which allow the spender to use tokens
+// of the owner without a separate approve transaction.
+package permit
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/monetha/go-ethereum"
+	"github.com/monetha/go-ethereum/eip712"
+)
+
+var (
+	permitTypeHash    = crypto.Keccak256Hash([]byte("Permit(address owner,address spender,uint256 value,uint256 nonce,uint256 deadline)"))
+	daiPermitTypeHash = crypto.Keccak256Hash([]byte("Permit(address holder,address spender,uint256 nonce,uint256 expiry,bool allowed)"))
+)
+
+const tokenABI = `[{"constant":true,"inputs":[{"name":"owner","type":"address"}],"name":"nonces","outputs":[{"name":"","type":"uint256"}],"payable":false,"stateMutability":"view","type":"function"},{"constant":true,"inputs":[],"name":"DOMAIN_SEPARATOR","outputs":[{"name":"","type":"bytes32"}],"payable":false,"stateMutability":"view","type":"function"}]`
+
+var parsedTokenABI = ethereum.MustParseABI(tokenABI)
+
+// Signature is the permit signature ready to be passed to permit function of the token.
+type Signature struct {
+	V        uint8
+	R        [32]byte
+	S        [32]byte
+	Nonce    *big.Int
+	Deadline *big.Int
+}
+
+// Token is the token which supports permits.
+type Token struct {
+	Address  common.Address
+	contract *bind.BoundContract
+}
+
+// New creates an instance of Token bound to the contract at `address`.
+func New(address common.Address, caller bind.ContractCaller) *Token {
+	return &Token{
+		Address:  address,
+		contract: bind.NewBoundContract(address, parsedTokenABI, caller, nil, nil),
+	}
+}
+
+// Nonce returns the permit nonce of the owner.
+func (t *Token) Nonce(ctx context.Context, owner common.Address) (*big.Int, error) {
+	var nonce *big.Int
+	if err := t.contract.Call(&bind.CallOpts{Context: ctx}, &nonce, "nonces", owner); err != nil {
+		return nil, fmt.Errorf("permit: nonces: %v", err)
+	}
+	return nonce, nil
+}
+
+// DomainSeparator returns EIP-712 domain separator of the token.
+func (t *Token) DomainSeparator(ctx context.Context) (common.Hash, error) {
+	var separator [32]byte
+	if err := t.contract.Call(&bind.CallOpts{Context: ctx}, &separator, "DOMAIN_SEPARATOR"); err != nil {
+		return common.Hash{}, fmt.Errorf("permit: DOMAIN_SEPARATOR: %v", err)
+	}
+	return separator, nil
+}
+
+// Sign signs EIP-2612 permit allowing `spender` to spend `value` tokens of the key owner until `deadline`
+// (unix timestamp).
+func (t *Token) Sign(ctx context.Context, key *ethereum.Key, spender common.Address, value, deadline *big.Int) (*Signature, error) {
+	separator, nonce, err := t.domainAndNonce(ctx, key.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	structHash := crypto.Keccak256Hash(
+		permitTypeHash[:],
+		key.Address.Hash().Bytes(),
+		spender.Hash().Bytes(),
+		eip712.Word(value),
+		eip712.Word(nonce),
+		eip712.Word(deadline),
+	)
+
+	return sign(eip712.Digest(separator, structHash), key, nonce, deadline)
+}
+
+// SignDAI signs DAI-style permit allowing (or disallowing, when `allowed` is false) `spender` to spend
+// all tokens of the key owner until `expiry` (unix timestamp, zero means no expiry).
+func (t *Token) SignDAI(ctx context.Context, key *ethereum.Key, spender common.Address, expiry *big.Int, allowed bool) (*Signature, error) {
+	separator, nonce, err := t.domainAndNonce(ctx, key.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	var allowedWord int64
+	if allowed {
+		allowedWord = 1
+	}
+	structHash := crypto.Keccak256Hash(
+		daiPermitTypeHash[:],
+		key.Address.Hash().Bytes(),
+		spender.Hash().Bytes(),
+		eip712.Word(nonce),
+		eip712.Word(expiry),
+		eip712.Word(big.NewInt(allowedWord)),
+	)
+
+	return sign(eip712.Digest(separator, structHash), key, nonce, expiry)
+}
+
+func (t *Token) domainAndNonce(ctx context.Context, owner common.Address) (common.Hash, *big.Int, error) {
+	separator, err := t.DomainSeparator(ctx)
+	if err != nil {
+		return common.Hash{}, nil, err
+	}
+	nonce, err := t.Nonce(ctx, owner)
+	if err != nil {
+		return common.Hash{}, nil, err
+	}
+	return separator, nonce, nil
+}
+
+func sign(hash common.Hash, key *ethereum.Key, nonce, deadline *big.Int) (*Signature, error) {
+	sig, err := eip712.Sign(hash, key)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Signature{
+		V:        sig[64],
+		Nonce:    nonce,
+		Deadline: deadline,
+	}
+	copy(res.R[:], sig[:32])
+	copy(res.S[:], sig[32:64])
+	return res, nil
+}
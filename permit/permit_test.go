@@ -0,0 +1,60 @@
+package permit
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"testing"
+
+	eth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/monetha/go-ethereum"
+	"github.com/monetha/go-ethereum/eip712"
+)
+
+type tokenCallerMock struct {
+	nonce     *big.Int
+	separator common.Hash
+}
+
+func (m *tokenCallerMock) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	return []byte{1}, nil
+}
+
+func (m *tokenCallerMock) CallContract(ctx context.Context, call eth.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	if bytes.HasPrefix(call.Data, crypto.Keccak256([]byte("nonces(address)"))[:4]) {
+		return eip712.Word(m.nonce), nil
+	}
+	return m.separator.Bytes(), nil
+}
+
+func TestToken_Sign(t *testing.T) {
+	key, _ := ethereum.NewKey()
+	spender := common.HexToAddress("0x1234")
+	m := &tokenCallerMock{nonce: big.NewInt(5), separator: common.HexToHash("0xabcd")}
+
+	sig, err := New(common.HexToAddress("0x5678"), m).Sign(context.Background(), key, spender, big.NewInt(100), big.NewInt(2000000000))
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	if sig.Nonce.Cmp(m.nonce) != 0 {
+		t.Errorf("expected nonce %v, but got %v", m.nonce, sig.Nonce)
+	}
+
+	structHash := crypto.Keccak256Hash(
+		permitTypeHash[:],
+		key.Address.Hash().Bytes(),
+		spender.Hash().Bytes(),
+		eip712.Word(big.NewInt(100)),
+		eip712.Word(m.nonce),
+		eip712.Word(big.NewInt(2000000000)),
+	)
+	signer, err := eip712.Recover(eip712.Digest(m.separator, structHash), append(append(sig.R[:], sig.S[:]...), sig.V))
+	if err != nil {
+		t.Fatalf("Recover: %v", err)
+	}
+	if signer != key.Address {
+		t.Errorf("expected signer %v, but got %v", key.Address.Hex(), signer.Hex())
+	}
+}
@@ -0,0 +1,119 @@
+// Package progress measures how fast a component (e.g. BlockSource, backfill.Coordinator) catches up with the
+// chain head, so operators know whether it will ever catch up and when.
+package progress
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultWindow is used when the window of NewMeter is zero.
+const DefaultWindow = time.Minute
+
+// Unknown is the ETA when the component doesn't catch up at the current rates, or the rates aren't known yet.
+const Unknown = time.Duration(-1)
+
+// samplesPerWindow limits the number of kept samples.
+const samplesPerWindow = 60
+
+// Progress of catching up with the head.
+type Progress struct {
+	Current   uint64        // the last processed block
+	Head      uint64        // the block to catch up with, e.g. the chain head less confirmations
+	Behind    uint64        // number of blocks the current one is behind the head
+	Rate      float64       // processed blocks per second
+	HeadRate  float64       // blocks per second the head advances
+	ETA       time.Duration // time to catch up, zero when caught up, Unknown when it doesn't catch up
+	UpdatedAt time.Time     // time of the last update, zero when there were no updates
+}
+
+func (p Progress) String() string {
+	eta := "unknown"
+	if p.ETA != Unknown {
+		eta = p.ETA.Round(time.Second).String()
+	}
+	return fmt.Sprintf("block %v of %v, %v blocks behind, %.1f blocks/s (head %.2f blocks/s), ETA %v",
+		p.Current, p.Head, p.Behind, p.Rate, p.HeadRate, eta)
+}
+
+type sample struct {
+	time    time.Time
+	current uint64
+	head    uint64
+}
+
+// Meter computes progress from the current block and the head reported over time. It's safe for concurrent use.
+type Meter struct {
+	window  time.Duration
+	mu      sync.Mutex
+	samples []sample // oldest first, at most one per window/samplesPerWindow
+	last    sample
+}
+
+// NewMeter creates an instance of Meter which computes rates over the window (DefaultWindow when it's zero).
+func NewMeter(window time.Duration) *Meter {
+	if window <= 0 {
+		window = DefaultWindow
+	}
+	return &Meter{window: window}
+}
+
+// Update records the current block and the head.
+func (m *Meter) Update(current, head uint64) {
+	m.update(time.Now(), current, head)
+}
+
+func (m *Meter) update(now time.Time, current, head uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.last = sample{time: now, current: current, head: head}
+	if n := len(m.samples); n == 0 || now.Sub(m.samples[n-1].time) >= m.window/samplesPerWindow {
+		m.samples = append(m.samples, m.last)
+	}
+
+	// keep one sample older than the window, so rates are computed over the whole window
+	i := 0
+	for i < len(m.samples)-1 && now.Sub(m.samples[i+1].time) >= m.window {
+		i++
+	}
+	if i > 0 {
+		m.samples = append(m.samples[:0], m.samples[i:]...)
+	}
+}
+
+// Progress returns the progress as of the last update.
+func (m *Meter) Progress() Progress {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	last := m.last
+	p := Progress{Current: last.current, Head: last.head, ETA: Unknown, UpdatedAt: last.time}
+	if last.head > last.current {
+		p.Behind = last.head - last.current
+	}
+	if len(m.samples) > 0 {
+		first := m.samples[0]
+		if dt := last.time.Sub(first.time).Seconds(); dt > 0 {
+			p.Rate = rate(first.current, last.current, dt)
+			p.HeadRate = rate(first.head, last.head, dt)
+		}
+	}
+
+	switch {
+	case last.time.IsZero():
+	case p.Behind == 0:
+		p.ETA = 0
+	case p.Rate > p.HeadRate:
+		p.ETA = time.Duration(float64(p.Behind) / (p.Rate - p.HeadRate) * float64(time.Second))
+	}
+	return p
+}
+
+func rate(from, to uint64, seconds float64) float64 {
+	if to < from {
+		return 0
+	}
+	return float64(to-from) / seconds
+}
@@ -0,0 +1,62 @@
+package progress
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMeter_Progress(t *testing.T) {
+	m := NewMeter(time.Minute)
+	if p := m.Progress(); p.ETA != Unknown || !p.UpdatedAt.IsZero() {
+		t.Errorf("expected unknown progress, but got %+v", p)
+	}
+
+	start := time.Unix(1700000000, 0)
+	// 10 blocks per second while the head advances 1 block per 10 seconds
+	for i := 0; i <= 30; i++ {
+		m.update(start.Add(time.Duration(i)*time.Second), uint64(1000+10*i), uint64(5000+i/10))
+	}
+	p := m.Progress()
+	if p.Current != 1300 || p.Head != 5003 || p.Behind != 3703 {
+		t.Errorf("unexpected blocks %+v", p)
+	}
+	if p.Rate != 10 || p.HeadRate != 0.1 {
+		t.Errorf("expected rates 10 and 0.1, but got %v and %v", p.Rate, p.HeadRate)
+	}
+	if expected := 374 * time.Second; p.ETA.Round(time.Second) != expected {
+		t.Errorf("expected ETA %v, but got %v", expected, p.ETA)
+	}
+
+	// samples out of the window are dropped
+	for i := 31; i <= 200; i++ {
+		m.update(start.Add(time.Duration(i)*time.Second), uint64(1300+i-30), 5003)
+	}
+	if p := m.Progress(); p.Rate != 1 || p.HeadRate != 0 {
+		t.Errorf("expected rates 1 and 0, but got %v and %v", p.Rate, p.HeadRate)
+	}
+	if n := len(m.samples); n > samplesPerWindow+1 {
+		t.Errorf("expected at most %v samples, but got %v", samplesPerWindow+1, n)
+	}
+}
+
+func TestMeter_ETA(t *testing.T) {
+	tests := []struct {
+		name          string
+		current, head [2]uint64
+		expected      time.Duration
+	}{
+		{"caught up", [2]uint64{10, 20}, [2]uint64{10, 20}, 0},
+		{"falling behind", [2]uint64{10, 20}, [2]uint64{100, 120}, Unknown},
+		{"same rate", [2]uint64{10, 20}, [2]uint64{100, 110}, Unknown},
+		{"catching up", [2]uint64{10, 30}, [2]uint64{100, 110}, 80 * time.Second},
+	}
+	for _, tt := range tests {
+		m := NewMeter(0)
+		start := time.Now()
+		m.update(start, tt.current[0], tt.head[0])
+		m.update(start.Add(10*time.Second), tt.current[1], tt.head[1])
+		if p := m.Progress(); p.ETA != tt.expected {
+			t.Errorf("%v: expected ETA %v, but got %v (%v)", tt.name, tt.expected, p.ETA, p)
+		}
+	}
+}
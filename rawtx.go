@@ -0,0 +1,239 @@
+package ethereum
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/monetha/go-ethereum/sig"
+)
+
+// ErrUnsupportedTxType is returned by DecodeRawTransaction for unknown EIP-2718 transaction types.
+var ErrUnsupportedTxType = errors.New("unsupported transaction type")
+
+type accessTuple struct {
+	Address     common.Address
+	StorageKeys []common.Hash
+}
+
+// rawTypedTx holds fields of EIP-2930, EIP-1559 and EIP-4844 transactions. To is decoded as bytes, because it's
+// empty for contract creation.
+type rawTypedTx struct {
+	ChainID             *big.Int
+	Nonce               uint64
+	GasPrice            *big.Int // EIP-2930 only
+	GasTipCap           *big.Int // EIP-1559 and EIP-4844 only
+	GasFeeCap           *big.Int // EIP-1559 and EIP-4844 only
+	Gas                 uint64
+	To                  []byte
+	Value               *big.Int
+	Data                []byte
+	AccessList          []accessTuple
+	MaxFeePerBlobGas    *big.Int      // EIP-4844 only
+	BlobVersionedHashes []common.Hash // EIP-4844 only
+	V, R, S             *big.Int
+}
+
+type rawAccessListTx struct {
+	ChainID    *big.Int
+	Nonce      uint64
+	GasPrice   *big.Int
+	Gas        uint64
+	To         []byte
+	Value      *big.Int
+	Data       []byte
+	AccessList []accessTuple
+	V, R, S    *big.Int
+}
+
+type rawDynamicFeeTx struct {
+	ChainID    *big.Int
+	Nonce      uint64
+	GasTipCap  *big.Int
+	GasFeeCap  *big.Int
+	Gas        uint64
+	To         []byte
+	Value      *big.Int
+	Data       []byte
+	AccessList []accessTuple
+	V, R, S    *big.Int
+}
+
+type rawBlobTx struct {
+	ChainID             *big.Int
+	Nonce               uint64
+	GasTipCap           *big.Int
+	GasFeeCap           *big.Int
+	Gas                 uint64
+	To                  []byte
+	Value               *big.Int
+	Data                []byte
+	AccessList          []accessTuple
+	MaxFeePerBlobGas    *big.Int
+	BlobVersionedHashes []common.Hash
+	V, R, S             *big.Int
+}
+
+// DecodeRawTransaction decodes the raw transaction in hex (e.g. the argument of eth_sendRawTransaction) and recovers
+// its sender. Legacy, EIP-2930, EIP-1559 and EIP-4844 transactions are supported, blob transactions can be
+// in the network form with blobs. GasPrice of EIP-1559 and EIP-4844 transactions is set to the fee cap,
+// as nodes do for pending transactions.
+func DecodeRawTransaction(raw string) (*Transaction, error) {
+	b, err := hex.DecodeString(strings.TrimPrefix(strings.TrimPrefix(raw, "0x"), "0X"))
+	if err != nil {
+		return nil, fmt.Errorf("decoding raw transaction hex: %v", err)
+	}
+	if len(b) == 0 {
+		return nil, errors.New("empty raw transaction")
+	}
+
+	if b[0] >= 0xc0 {
+		return decodeLegacyTransaction(b)
+	}
+	return decodeTypedTransaction(b)
+}
+
+// EncodeRawTransaction returns the signed transaction in hex, as accepted by eth_sendRawTransaction.
+// Only legacy transactions can be created with types.Transaction of the go-ethereum version in use.
+func EncodeRawTransaction(tx *types.Transaction) (string, error) {
+	b, err := rlp.EncodeToBytes(tx)
+	if err != nil {
+		return "", fmt.Errorf("encoding transaction: %v", err)
+	}
+	return hexutil.Encode(b), nil
+}
+
+func decodeLegacyTransaction(b []byte) (*Transaction, error) {
+	tx := new(types.Transaction)
+	if err := rlp.DecodeBytes(b, tx); err != nil {
+		return nil, fmt.Errorf("decoding legacy transaction: %v", err)
+	}
+
+	var signer types.Signer = types.HomesteadSigner{}
+	if tx.Protected() {
+		signer = types.NewEIP155Signer(tx.ChainId())
+	}
+	from, err := types.Sender(signer, tx)
+	if err != nil {
+		return nil, fmt.Errorf("recovering sender: %v", err)
+	}
+
+	return &Transaction{
+		Type:     LegacyTxType,
+		From:     from,
+		GasLimit: new(big.Int).SetUint64(tx.Gas()),
+		GasPrice: tx.GasPrice(),
+		Hash:     tx.Hash(),
+		Input:    tx.Data(),
+		Nonce:    tx.Nonce(),
+		To:       tx.To(),
+		Value:    tx.Value(),
+	}, nil
+}
+
+func decodeTypedTransaction(b []byte) (*Transaction, error) {
+	txType, payload := TransactionType(b[0]), b[1:]
+
+	if txType == BlobTxType {
+		// network form is [tx_payload_body, blobs, commitments, proofs]
+		content, _, err := rlp.SplitList(payload)
+		if err != nil {
+			return nil, fmt.Errorf("decoding blob transaction: %v", err)
+		}
+		kind, _, rest, err := rlp.Split(content)
+		if err != nil {
+			return nil, fmt.Errorf("decoding blob transaction: %v", err)
+		}
+		if kind == rlp.List {
+			payload = content[:len(content)-len(rest)]
+		}
+	}
+
+	var (
+		tx         rawTypedTx
+		signFields []interface{}
+	)
+	switch txType {
+	case AccessListTxType:
+		var dec rawAccessListTx
+		if err := rlp.DecodeBytes(payload, &dec); err != nil {
+			return nil, fmt.Errorf("decoding access list transaction: %v", err)
+		}
+		tx = rawTypedTx{ChainID: dec.ChainID, Nonce: dec.Nonce, GasPrice: dec.GasPrice, Gas: dec.Gas, To: dec.To,
+			Value: dec.Value, Data: dec.Data, AccessList: dec.AccessList, V: dec.V, R: dec.R, S: dec.S}
+		signFields = []interface{}{tx.ChainID, tx.Nonce, tx.GasPrice, tx.Gas, tx.To, tx.Value, tx.Data, tx.AccessList}
+	case DynamicFeeTxType:
+		var dec rawDynamicFeeTx
+		if err := rlp.DecodeBytes(payload, &dec); err != nil {
+			return nil, fmt.Errorf("decoding dynamic fee transaction: %v", err)
+		}
+		tx = rawTypedTx{ChainID: dec.ChainID, Nonce: dec.Nonce, GasTipCap: dec.GasTipCap, GasFeeCap: dec.GasFeeCap,
+			Gas: dec.Gas, To: dec.To, Value: dec.Value, Data: dec.Data, AccessList: dec.AccessList, V: dec.V, R: dec.R, S: dec.S}
+		signFields = []interface{}{tx.ChainID, tx.Nonce, tx.GasTipCap, tx.GasFeeCap, tx.Gas, tx.To, tx.Value, tx.Data,
+			tx.AccessList}
+	case BlobTxType:
+		var dec rawBlobTx
+		if err := rlp.DecodeBytes(payload, &dec); err != nil {
+			return nil, fmt.Errorf("decoding blob transaction: %v", err)
+		}
+		tx = rawTypedTx{ChainID: dec.ChainID, Nonce: dec.Nonce, GasTipCap: dec.GasTipCap, GasFeeCap: dec.GasFeeCap,
+			Gas: dec.Gas, To: dec.To, Value: dec.Value, Data: dec.Data, AccessList: dec.AccessList,
+			MaxFeePerBlobGas: dec.MaxFeePerBlobGas, BlobVersionedHashes: dec.BlobVersionedHashes, V: dec.V, R: dec.R, S: dec.S}
+		signFields = []interface{}{tx.ChainID, tx.Nonce, tx.GasTipCap, tx.GasFeeCap, tx.Gas, tx.To, tx.Value, tx.Data,
+			tx.AccessList, tx.MaxFeePerBlobGas, tx.BlobVersionedHashes}
+	default:
+		return nil, fmt.Errorf("%v: %v", ErrUnsupportedTxType, txType)
+	}
+
+	var to *common.Address
+	switch len(tx.To) {
+	case 0:
+	case common.AddressLength:
+		a := common.BytesToAddress(tx.To)
+		to = &a
+	default:
+		return nil, fmt.Errorf("invalid recipient length %v", len(tx.To))
+	}
+
+	unsigned, err := rlp.EncodeToBytes(signFields)
+	if err != nil {
+		return nil, fmt.Errorf("encoding transaction for signing: %v", err)
+	}
+	// V of typed transactions is the recovery id
+	if tx.V == nil || tx.V.Cmp(big.NewInt(1)) > 0 {
+		return nil, fmt.Errorf("%v: %v", sig.ErrInvalidV, tx.V)
+	}
+	signature, err := sig.FromVRS(tx.V, tx.R, tx.S)
+	if err != nil {
+		return nil, err
+	}
+	from, err := signature.Recover(crypto.Keccak256Hash([]byte{byte(txType)}, unsigned))
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Transaction{
+		Type:                txType,
+		From:                from,
+		GasLimit:            new(big.Int).SetUint64(tx.Gas),
+		GasPrice:            tx.GasPrice,
+		Hash:                crypto.Keccak256Hash([]byte{byte(txType)}, payload),
+		Input:               tx.Data,
+		Nonce:               tx.Nonce,
+		To:                  to,
+		Value:               tx.Value,
+		BlobVersionedHashes: tx.BlobVersionedHashes,
+		MaxFeePerBlobGas:    tx.MaxFeePerBlobGas,
+	}
+	if res.GasPrice == nil {
+		res.GasPrice = tx.GasFeeCap
+	}
+	return res, nil
+}
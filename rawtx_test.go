@@ -0,0 +1,112 @@
+package ethereum
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// example of EIP-155
+const (
+	eip155RawTx  = "0xf86c098504a817c800825208943535353535353535353535353535353535353535880de0b6b3a76400008025a028ef61340bd939bc2195fe537567866003e1a15d3c71ff63e1590620aa636276a067cbe9d8997f761aecb703304b3800ccf555c9f3dc64214b297fb1966a3b6d83"
+	eip155Sender = "0x9d8A62f656a8d1615C1294fd71e9CFb3E4855A4F"
+	eip155TxHash = "0x33469b22e9f636356c4160a87eb19df52b7412e8eac32a4a55ffe88ea8350788"
+	eip155Key    = "4646464646464646464646464646464646464646464646464646464646464646"
+)
+
+func TestDecodeRawTransaction_Legacy(t *testing.T) {
+	tx, err := DecodeRawTransaction(eip155RawTx)
+	if err != nil {
+		t.Fatalf("DecodeRawTransaction() error = %v", err)
+	}
+
+	if tx.From != common.HexToAddress(eip155Sender) {
+		t.Errorf("expected sender %v, but got %v", eip155Sender, tx.From.Hex())
+	}
+	if tx.Hash != common.HexToHash(eip155TxHash) {
+		t.Errorf("expected hash %v, but got %v", eip155TxHash, tx.Hash.Hex())
+	}
+	if tx.Type != LegacyTxType || tx.Nonce != 9 || tx.GasLimit.Int64() != 21000 || tx.GasPrice.Int64() != 20000000000 {
+		t.Errorf("unexpected transaction: %v", tx)
+	}
+	if tx.Value.String() != "1000000000000000000" || tx.To == nil || *tx.To != common.HexToAddress("0x3535353535353535353535353535353535353535") {
+		t.Errorf("unexpected transaction: %v", tx)
+	}
+}
+
+func TestEncodeRawTransaction(t *testing.T) {
+	tx := new(types.Transaction)
+	if err := rlp.DecodeBytes(hexutil.MustDecode(eip155RawTx), tx); err != nil {
+		t.Fatalf("rlp.DecodeBytes() error = %v", err)
+	}
+
+	raw, err := EncodeRawTransaction(tx)
+	if err != nil {
+		t.Fatalf("EncodeRawTransaction() error = %v", err)
+	}
+	if raw != eip155RawTx {
+		t.Errorf("expected %v, but got %v", eip155RawTx, raw)
+	}
+}
+
+func TestDecodeRawTransaction_DynamicFee(t *testing.T) {
+	key, err := crypto.HexToECDSA(eip155Key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	to := common.HexToAddress("0x3535353535353535353535353535353535353535")
+	fields := []interface{}{
+		big.NewInt(1),           // chain ID
+		uint64(3),               // nonce
+		big.NewInt(2000000000),  // tip cap
+		big.NewInt(50000000000), // fee cap
+		uint64(21000),           // gas
+		to.Bytes(),
+		big.NewInt(7),
+		[]byte{},
+		[]accessTuple{{Address: to, StorageKeys: []common.Hash{{1}}}},
+	}
+
+	unsigned, err := rlp.EncodeToBytes(fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature, err := crypto.Sign(crypto.Keccak256([]byte{byte(DynamicFeeTxType)}, unsigned), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed, err := rlp.EncodeToBytes(append(fields,
+		big.NewInt(int64(signature[64])), new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:64])))
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw := append([]byte{byte(DynamicFeeTxType)}, signed...)
+
+	tx, err := DecodeRawTransaction(hexutil.Encode(raw))
+	if err != nil {
+		t.Fatalf("DecodeRawTransaction() error = %v", err)
+	}
+
+	if tx.From != common.HexToAddress(eip155Sender) {
+		t.Errorf("expected sender %v, but got %v", eip155Sender, tx.From.Hex())
+	}
+	if expected := crypto.Keccak256Hash(raw); tx.Hash != expected {
+		t.Errorf("expected hash %v, but got %v", expected.Hex(), tx.Hash.Hex())
+	}
+	if tx.Type != DynamicFeeTxType || tx.Nonce != 3 || tx.GasPrice.Int64() != 50000000000 || tx.Value.Int64() != 7 {
+		t.Errorf("unexpected transaction: %v", tx)
+	}
+	if tx.To == nil || *tx.To != to {
+		t.Errorf("expected recipient %v, but got %v", to.Hex(), tx.To)
+	}
+
+	raw[0] = 5
+	if _, err := DecodeRawTransaction(hexutil.Encode(raw)); err == nil {
+		t.Error("expected error for unsupported transaction type, but got nil")
+	}
+}
@@ -0,0 +1,97 @@
+package ethereum
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// TransactionReceiptsReader returns receipts of many transactions at once (e.g. client.Client does it in batch
+// requests). Receipt is nil when transaction isn't mined yet.
+type TransactionReceiptsReader interface {
+	TransactionReceipts(ctx context.Context, txHashes []common.Hash) ([]*types.Receipt, error)
+}
+
+// WaitForTxReceipts waits until all the transactions are mined and returns their receipts in the order of hashes.
+// Unlike WaitForTxReceipt, it doesn't return error for failed transactions, receipt status should be checked
+// by the caller. Receipts are polled in one loop, using ReceiptsReader (or backend, if it implements
+// TransactionReceiptsReader) to get receipts of all pending transactions at once.
+// Transient errors of getting receipts are retried on the next poll.
+// On error, already received receipts are returned along with the error.
+func (e *Eth) WaitForTxReceipts(ctx context.Context, txHashes []common.Hash) (receipts []*types.Receipt, err error) {
+	receipts = make([]*types.Receipt, len(txHashes))
+	if len(txHashes) == 0 {
+		return
+	}
+
+	e.Log("Waiting for transactions", "count", len(txHashes))
+
+	pending := make([]int, len(txHashes)) // indexes of transactions which aren't mined yet
+	for i := range pending {
+		pending[i] = i
+	}
+
+	for {
+		pendingHashes := make([]common.Hash, len(pending))
+		for i, idx := range pending {
+			pendingHashes[i] = txHashes[idx]
+		}
+
+		rs, rerr := e.transactionReceipts(ctx, pendingHashes)
+		switch {
+		case rerr != nil:
+			// transient error (e.g. the node is unavailable), receipts are requested again on the next poll
+			e.Log("Can't get transaction receipts", "count", len(pendingHashes), "error", rerr)
+		case len(rs) != len(pendingHashes):
+			return receipts, fmt.Errorf("got %v receipts for %v transactions", len(rs), len(pendingHashes))
+		default:
+			stillPending := pending[:0]
+			for i, idx := range pending {
+				if rs[i] == nil {
+					stillPending = append(stillPending, idx)
+					continue
+				}
+				receipts[idx] = rs[i]
+				e.Log("Transaction mined", "tx_hash", rs[i].TxHash.Hex(), "status", rs[i].Status)
+			}
+			pending = stillPending
+		}
+
+		if len(pending) == 0 {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return receipts, ctx.Err()
+		case <-time.After(txPollInterval):
+		}
+	}
+}
+
+func (e *Eth) transactionReceipts(ctx context.Context, txHashes []common.Hash) ([]*types.Receipt, error) {
+	rr := e.ReceiptsReader
+	if rr == nil {
+		rr, _ = e.Backend.(TransactionReceiptsReader)
+	}
+	if rr != nil {
+		return rr.TransactionReceipts(ctx, txHashes)
+	}
+
+	receipts := make([]*types.Receipt, len(txHashes))
+	for i, txHash := range txHashes {
+		r, err := e.Backend.TransactionReceipt(ctx, txHash)
+		if err == ethereum.NotFound {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("waiting for tx(%v): %v", txHash.Hex(), err)
+		}
+		receipts[i] = r
+	}
+	return receipts, nil
+}
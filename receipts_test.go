@@ -0,0 +1,106 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/monetha/go-ethereum/backend"
+)
+
+func TestEth_WaitForTxReceipts(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	from := crypto.PubkeyToAddress(key.PublicKey)
+
+	sim := backend.NewSimulatedBackendExtended(core.GenesisAlloc{from: {Balance: ether}}, 10000000)
+	sim.Commit()
+
+	e := New(backend.NewAutoCommitBackend(sim), nil)
+	e.SuggestedGasPrice = big.NewInt(1)
+	s := e.NewSession(key)
+
+	key2, _ := crypto.GenerateKey()
+	to := crypto.PubkeyToAddress(key2.PublicKey)
+
+	amount := new(big.Int).Div(ether, big.NewInt(10))
+	results := s.SendBatch(context.TODO(), []PreparedTx{{To: to, Value: amount}, {To: to, Value: amount}})
+
+	var hashes []common.Hash
+	for _, r := range results {
+		if r.Err != nil {
+			t.Fatal(r.Err)
+		}
+		hashes = append(hashes, r.Tx.Hash())
+	}
+
+	receipts, err := e.WaitForTxReceipts(context.TODO(), hashes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(receipts) != len(hashes) {
+		t.Fatalf("expected %v receipts, but got %v", len(hashes), len(receipts))
+	}
+	for i, r := range receipts {
+		if r == nil {
+			t.Fatalf("receipt %v: expected receipt, but got nil", i)
+		}
+		if r.TxHash != hashes[i] {
+			t.Errorf("receipt %v: expected tx hash %v, but got %v", i, hashes[i].Hex(), r.TxHash.Hex())
+		}
+		if r.Status != types.ReceiptStatusSuccessful {
+			t.Errorf("receipt %v: unexpected transaction status: %v", i, r.Status)
+		}
+	}
+}
+
+// receiptsReader returns receipts of transactions, failing the first calls with transient errors.
+type receiptsReader struct {
+	failures int
+	short    bool // return fewer receipts than requested
+}
+
+func (r *receiptsReader) TransactionReceipts(ctx context.Context, txHashes []common.Hash) ([]*types.Receipt, error) {
+	if r.failures > 0 {
+		r.failures--
+		return nil, errors.New("connection refused")
+	}
+	if r.short {
+		return nil, nil
+	}
+	receipts := make([]*types.Receipt, len(txHashes))
+	for i, hash := range txHashes {
+		receipts[i] = &types.Receipt{TxHash: hash}
+	}
+	return receipts, nil
+}
+
+func TestEth_WaitForTxReceipts_ReceiptsReader(t *testing.T) {
+	defer func(d time.Duration) { txPollInterval = d }(txPollInterval)
+	txPollInterval = time.Millisecond
+	hashes := []common.Hash{common.HexToHash("0x1"), common.HexToHash("0x2")}
+
+	t.Run("transient errors", func(t *testing.T) {
+		e := NewEth(nil, WithReceiptsReader(&receiptsReader{failures: 2}))
+		receipts, err := e.WaitForTxReceipts(context.Background(), hashes)
+		if err != nil {
+			t.Fatalf("expected transient errors to be retried, but got %v", err)
+		}
+		if receipts[1] == nil || receipts[1].TxHash != hashes[1] {
+			t.Errorf("expected receipt of %v, but got %+v", hashes[1].Hex(), receipts[1])
+		}
+	})
+
+	t.Run("missing receipts", func(t *testing.T) {
+		e := NewEth(nil, WithReceiptsReader(&receiptsReader{short: true}))
+		if _, err := e.WaitForTxReceipts(context.Background(), hashes); err == nil {
+			t.Errorf("expected error when receipts reader returns fewer receipts")
+		}
+	})
+}
@@ -0,0 +1,228 @@
+package ethereum
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/monetha/go-ethereum/backend"
+	"github.com/monetha/go-ethereum/events"
+	"github.com/monetha/go-ethereum/gasestimator"
+)
+
+// cancelGasLimit is the gas limit of zero value transfer, which cancels the transaction.
+const cancelGasLimit = 21000
+
+// DefaultReplacementInterval is the duration to wait for the transaction to be mined before it's replaced, when
+// ReplacementOptions.Interval isn't set.
+const DefaultReplacementInterval = 3 * time.Minute
+
+// Rebroadcast describes the transaction which replaced the stuck one with the same nonce.
+type Rebroadcast struct {
+	Attempt  int
+	Nonce    uint64
+	Replaced common.Hash
+	Tx       *types.Transaction
+}
+
+// ReplacementOptions configures SendWithReplacement.
+type ReplacementOptions struct {
+	// Policy decides on the gas price of replacements. When its MaxGasPrice isn't set, the cap of GasPriceEstimator
+	// is used (see gasestimator.WithMaxGasPrice).
+	Policy gasestimator.ReplacementPolicy
+	// Interval is the duration to wait for the transaction to be mined before it's replaced
+	// (DefaultReplacementInterval when it's not positive).
+	Interval time.Duration
+	// OnRebroadcast is called after every replacement is sent (optional).
+	OnRebroadcast func(Rebroadcast)
+	// Deadline is the time by which the transaction must be mined (optional). Otherwise, it's cancelled
+	// and DeadlineError is returned.
+	Deadline time.Time
+}
+
+// DeadlineError is returned by SendWithReplacement when none of the sent transactions is mined by
+// ReplacementOptions.Deadline. The transaction is cancelled when Cancelled is true, i.e. the cancellation
+// transaction (zero value transfer to the session account with the same nonce) is mined. Otherwise, Err tells
+// why it isn't cancelled, and the sent transactions may still be mined.
+type DeadlineError struct {
+	Nonce     uint64
+	Sent      []common.Hash      // transactions sent before the deadline
+	Cancel    *types.Transaction // cancellation transaction, nil when it's not sent
+	Cancelled bool
+	Err       error
+}
+
+func (e *DeadlineError) Error() string {
+	if e.Cancelled {
+		return fmt.Sprintf("transaction with nonce %v is not mined by the deadline, cancelled by %v", e.Nonce, e.Cancel.Hash().Hex())
+	}
+	return fmt.Sprintf("transaction with nonce %v is not mined by the deadline, failed to cancel: %v", e.Nonce, e.Err)
+}
+
+// SendWithReplacement sends the transaction from the session account and waits until it's mined. When it's not
+// mined within the interval, it's replaced by the transaction with the same nonce and the gas price bumped
+// according to the policy. After the policy stops replacing (max attempts or the gas price cap is reached), it
+// keeps waiting for any of the sent transactions to be mined. It returns error if receipt status is not equal
+// to `types.ReceiptStatusSuccessful`, and it waits for Confirmations or Finality like WaitForTxReceipt. When the node rejects
+// the replacement as underpriced, the next attempt is made without waiting. When the deadline is set and none
+// of the transactions is mined by it, the nonce is taken by the cancellation transaction with the gas price bumped
+// according to the policy (without the limit of attempts), and DeadlineError is returned.
+func (s *Session) SendWithReplacement(ctx context.Context, tx PreparedTx, opts ReplacementOptions) (*types.Receipt, error) {
+	ctx = s.ctxOrDefault(ctx)
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultReplacementInterval
+	}
+
+	policy := opts.Policy
+	if policy.MaxGasPrice == nil {
+		type maxGasPricer interface {
+			MaxGasPrice() *big.Int
+		}
+		if mp, ok := s.GasPriceEstimator.(maxGasPricer); ok {
+			policy.MaxGasPrice = mp.MaxGasPrice()
+		}
+	}
+
+	txOpts := s.WithContext(ctx).TransactOpts
+	txOpts.Value = copyBigInt(tx.Value)
+	txOpts.GasLimit = tx.GasLimit
+	if tx.GasPrice != nil {
+		txOpts.GasPrice = copyBigInt(tx.GasPrice)
+	}
+
+	tr := s.transferer()
+	signedTx, err := tr.Transfer(&txOpts, tx.To, tx.Data)
+	if err != nil {
+		return nil, err
+	}
+	s.Log("Transaction sent", "hash", signedTx.Hash().Hex(), "nonce", signedTx.Nonce(), "gas_price", signedTx.GasPrice())
+
+	// replacements keep everything but the gas price
+	txOpts.Nonce = new(big.Int).SetUint64(signedTx.Nonce())
+	txOpts.GasLimit = signedTx.Gas()
+	initialGasPrice := signedTx.GasPrice()
+
+	sent := []common.Hash{signedTx.Hash()}
+	last := signedTx
+	replacing := true
+	wait := opts.Interval
+	var cancel *types.Transaction
+	for attempt := 1; ; attempt++ {
+		if !opts.Deadline.IsZero() && cancel == nil {
+			if untilDeadline := time.Until(opts.Deadline); untilDeadline < wait {
+				wait = untilDeadline
+			}
+		}
+		receipt, err := s.waitForAnyReceipt(ctx, sent, wait)
+		wait = opts.Interval
+		if err != nil {
+			if cancel != nil {
+				return nil, &DeadlineError{Nonce: cancel.Nonce(), Sent: sent[:len(sent)-1], Cancel: cancel, Err: err}
+			}
+			return nil, err
+		}
+		if receipt != nil {
+			if cancel != nil && receipt.TxHash == cancel.Hash() {
+				s.Log("Transaction cancelled", "hash", cancel.Hash().Hex(), "nonce", cancel.Nonce())
+				return nil, &DeadlineError{Nonce: cancel.Nonce(), Sent: sent[:len(sent)-1], Cancel: cancel, Cancelled: true}
+			}
+			if receipt, err = s.onlySuccessfulReceipt(receipt, nil); err == nil && s.waitsForConfirmations() {
+				receipt, err = s.waitForConfirmations(ctx, receipt)
+			}
+			if err == nil {
+				s.Events.Publish(events.TxMined{Hash: receipt.TxHash, Receipt: receipt})
+			}
+			return receipt, err
+		}
+		if cancel == nil && !opts.Deadline.IsZero() && !time.Now().Before(opts.Deadline) {
+			cancelPolicy := policy
+			cancelPolicy.MaxAttempts = 0
+			cancel, err = s.sendCancellation(txOpts, cancelPolicy, initialGasPrice, last.GasPrice(), attempt)
+			if err != nil {
+				s.Log("Failed to cancel transaction", "hash", last.Hash().Hex(), "error", err)
+				return nil, &DeadlineError{Nonce: last.Nonce(), Sent: sent, Err: err}
+			}
+			s.Log("Cancelling transaction", "hash", last.Hash().Hex(), "cancel_hash", cancel.Hash().Hex(), "gas_price", cancel.GasPrice())
+			sent = append(sent, cancel.Hash())
+			replacing = false
+			continue
+		}
+		if !replacing {
+			continue
+		}
+
+		gasPrice, err := policy.GasPrice(initialGasPrice, last.GasPrice(), attempt)
+		if err != nil {
+			s.Log("Transaction is not replaced anymore", "hash", last.Hash().Hex(), "reason", err)
+			replacing = false
+			continue
+		}
+		txOpts.GasPrice = gasPrice
+
+		newTx, err := tr.Transfer(&txOpts, tx.To, tx.Data)
+		if err != nil {
+			s.Log("Failed to replace transaction", "hash", last.Hash().Hex(), "gas_price", gasPrice, "error", err)
+			if backend.IsReplacementUnderpriced(err) {
+				wait = 0 // bump more without waiting
+			}
+			continue
+		}
+		s.Log("Transaction replaced", "hash", last.Hash().Hex(), "new_hash", newTx.Hash().Hex(), "gas_price", gasPrice)
+
+		if opts.OnRebroadcast != nil {
+			opts.OnRebroadcast(Rebroadcast{Attempt: attempt, Nonce: newTx.Nonce(), Replaced: last.Hash(), Tx: newTx})
+		}
+		sent = append(sent, newTx.Hash())
+		last = newTx
+	}
+}
+
+// sendCancellation replaces the transaction by zero value transfer to the session account with the gas price
+// bumped according to the policy.
+func (s *Session) sendCancellation(txOpts bind.TransactOpts, policy gasestimator.ReplacementPolicy, initial, prev *big.Int, attempt int) (*types.Transaction, error) {
+	gasPrice, err := policy.GasPrice(initial, prev, attempt)
+	if err != nil {
+		return nil, err
+	}
+
+	txOpts.Value = nil
+	txOpts.GasLimit = cancelGasLimit
+	txOpts.GasPrice = gasPrice
+	return s.transferer().Transfer(&txOpts, txOpts.From, nil)
+}
+
+// waitForAnyReceipt polls receipts of the transactions for the given duration. It returns nil receipt when none
+// of the transactions is mined.
+func (s *Session) waitForAnyReceipt(ctx context.Context, txHashes []common.Hash, d time.Duration) (*types.Receipt, error) {
+	deadline := time.Now().Add(d)
+	for {
+		for _, txHash := range txHashes {
+			tr, err := s.Backend.TransactionReceipt(ctx, txHash)
+			if err == nil {
+				return tr, nil
+			}
+			if err != ethereum.NotFound {
+				return nil, err
+			}
+		}
+
+		wait := time.Until(deadline)
+		if wait <= 0 {
+			return nil, nil
+		}
+		if wait > txPollInterval {
+			wait = txPollInterval
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
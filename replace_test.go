@@ -0,0 +1,178 @@
+package ethereum
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/monetha/go-ethereum/backend"
+	"github.com/monetha/go-ethereum/gasestimator"
+)
+
+// stuckBackend mines the transaction only after it's sent `mineAfter` times.
+type stuckBackend struct {
+	backend.Backend
+	mineAfter int
+	sent      []*types.Transaction
+}
+
+func (b *stuckBackend) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return 7, nil
+}
+
+func (b *stuckBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	b.sent = append(b.sent, tx)
+	return nil
+}
+
+func (b *stuckBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	if len(b.sent) >= b.mineAfter && b.sent[b.mineAfter-1].Hash() == txHash {
+		return &types.Receipt{Status: types.ReceiptStatusSuccessful, TxHash: txHash}, nil
+	}
+	return nil, ethereum.NotFound
+}
+
+type maxGasPriceEstimator struct{ constGasPrice }
+
+func (maxGasPriceEstimator) MaxGasPrice() *big.Int { return big.NewInt(1350) }
+
+func TestSession_SendWithReplacement(t *testing.T) {
+	defer func(d time.Duration) { txPollInterval = d }(txPollInterval)
+	txPollInterval = time.Millisecond
+
+	key, _ := crypto.GenerateKey()
+	to := common.HexToAddress("0x1111111111111111111111111111111111111111")
+
+	b := &stuckBackend{mineAfter: 3}
+	e := NewEth(b, WithGasPriceEstimator(maxGasPriceEstimator{constGasPrice(1000)}))
+	s := e.NewSession(key)
+
+	var rebroadcasts []Rebroadcast
+	tr, err := s.SendWithReplacement(context.Background(), PreparedTx{To: to, GasLimit: 21000}, ReplacementOptions{
+		Policy:        gasestimator.ReplacementPolicy{BumpPercent: 20},
+		Interval:      5 * time.Millisecond,
+		OnRebroadcast: func(r Rebroadcast) { rebroadcasts = append(rebroadcasts, r) },
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(b.sent) != 3 {
+		t.Fatalf("expected 3 sent transactions, but got %v", len(b.sent))
+	}
+	if tr.TxHash != b.sent[2].Hash() {
+		t.Errorf("expected receipt of the last replacement %v, but got %v", b.sent[2].Hash().Hex(), tr.TxHash.Hex())
+	}
+	// the second replacement is capped by the estimator
+	for i, expected := range []int64{1000, 1200, 1350} {
+		tx := b.sent[i]
+		if tx.Nonce() != 7 {
+			t.Errorf("transaction %v: expected nonce 7, but got %v", i, tx.Nonce())
+		}
+		if tx.GasPrice().Int64() != expected {
+			t.Errorf("transaction %v: expected gas price %v, but got %v", i, expected, tx.GasPrice())
+		}
+	}
+
+	if len(rebroadcasts) != 2 {
+		t.Fatalf("expected 2 rebroadcasts, but got %v", len(rebroadcasts))
+	}
+	for i, r := range rebroadcasts {
+		if r.Attempt != i+1 || r.Replaced != b.sent[i].Hash() || r.Tx != b.sent[i+1] {
+			t.Errorf("rebroadcast %v: unexpected %+v", i, r)
+		}
+	}
+}
+
+// slowBackend mines the first sent transaction only after its receipt is requested `polls` times.
+type slowBackend struct {
+	stuckBackend
+	polls int
+}
+
+func (b *slowBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	if b.polls--; b.polls > 0 || b.sent[0].Hash() != txHash {
+		return nil, ethereum.NotFound
+	}
+	return &types.Receipt{Status: types.ReceiptStatusSuccessful, TxHash: txHash}, nil
+}
+
+func TestSession_SendWithReplacement_DefaultInterval(t *testing.T) {
+	defer func(d time.Duration) { txPollInterval = d }(txPollInterval)
+	txPollInterval = time.Millisecond
+
+	key, _ := crypto.GenerateKey()
+	b := &slowBackend{polls: 10}
+	s := NewEth(b, WithGasPriceEstimator(constGasPrice(1000))).NewSession(key)
+
+	if _, err := s.SendWithReplacement(context.Background(), PreparedTx{To: common.HexToAddress("0x1111"), GasLimit: 21000}, ReplacementOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(b.sent) != 1 {
+		t.Errorf("expected transaction not to be replaced within the default interval, but got %v sent transactions", len(b.sent))
+	}
+}
+
+func TestSession_SendWithReplacement_Deadline(t *testing.T) {
+	defer func(d time.Duration) { txPollInterval = d }(txPollInterval)
+	txPollInterval = time.Millisecond
+
+	key, _ := crypto.GenerateKey()
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	to := common.HexToAddress("0x1111111111111111111111111111111111111111")
+
+	tests := []struct {
+		name        string
+		maxGasPrice *big.Int
+		cancelled   bool
+	}{
+		{"cancelled", nil, true},
+		{"cancellation exceeds cap", big.NewInt(1000), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// only the second transaction (cancellation) is mined
+			b := &stuckBackend{mineAfter: 2}
+			s := NewEth(b, WithGasPriceEstimator(constGasPrice(1000))).NewSession(key)
+
+			_, err := s.SendWithReplacement(context.Background(), PreparedTx{To: to, GasLimit: 50000}, ReplacementOptions{
+				Policy:   gasestimator.ReplacementPolicy{MaxGasPrice: tt.maxGasPrice},
+				Interval: time.Hour,
+				Deadline: time.Now().Add(10 * time.Millisecond),
+			})
+			de, ok := err.(*DeadlineError)
+			if !ok {
+				t.Fatalf("expected DeadlineError, but got %v", err)
+			}
+			if de.Cancelled != tt.cancelled {
+				t.Errorf("expected cancelled %v, but got %v (%v)", tt.cancelled, de.Cancelled, err)
+			}
+			if de.Nonce != 7 || len(de.Sent) != 1 || de.Sent[0] != b.sent[0].Hash() {
+				t.Errorf("unexpected error %+v", de)
+			}
+			if !tt.cancelled {
+				if len(b.sent) != 1 {
+					t.Errorf("expected only the original transaction to be sent, but got %v", len(b.sent))
+				}
+				return
+			}
+
+			if len(b.sent) != 2 || de.Cancel != b.sent[1] {
+				t.Fatalf("expected cancellation to be sent")
+			}
+			c := de.Cancel
+			if *c.To() != from || c.Value().Sign() != 0 || c.Gas() != cancelGasLimit || c.Nonce() != 7 {
+				t.Errorf("unexpected cancellation to %v, value %v, gas %v, nonce %v", c.To().Hex(), c.Value(), c.Gas(), c.Nonce())
+			}
+			if c.GasPrice().Int64() != 1100 {
+				t.Errorf("expected cancellation gas price 1100, but got %v", c.GasPrice())
+			}
+		})
+	}
+}
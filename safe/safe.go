@@ -0,0 +1,183 @@
+// Package safe allows to drive Gnosis Safe (multisig) wallets: it builds Safe transaction hashes,
+// collects owner signatures and submits them with execTransaction.
+package safe
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/monetha/go-ethereum"
+	"github.com/monetha/go-ethereum/eip712"
+)
+
+// Operation is the type of the call made by the Safe.
+type Operation uint8
+
+const (
+	// Call is a regular call.
+	Call Operation = 0
+	// DelegateCall is a delegate call.
+	DelegateCall Operation = 1
+)
+
+var (
+	// domainSeparatorTypeHash is used by Safe contracts before v1.3.0.
+	domainSeparatorTypeHash = crypto.Keccak256Hash([]byte("EIP712Domain(address verifyingContract)"))
+	// domainSeparatorChainIDTypeHash is used by Safe contracts since v1.3.0.
+	domainSeparatorChainIDTypeHash = crypto.Keccak256Hash([]byte("EIP712Domain(uint256 chainId,address verifyingContract)"))
+	safeTxTypeHash                 = crypto.Keccak256Hash([]byte("SafeTx(address to,uint256 value,bytes data,uint8 operation,uint256 safeTxGas,uint256 baseGas,uint256 gasPrice,address gasToken,address refundReceiver,uint256 nonce)"))
+)
+
+// Transaction is the transaction executed by the Safe once it's signed by enough owners.
+type Transaction struct {
+	To             common.Address
+	Value          *big.Int
+	Data           []byte
+	Operation      Operation
+	SafeTxGas      *big.Int
+	BaseGas        *big.Int
+	GasPrice       *big.Int
+	GasToken       common.Address
+	RefundReceiver common.Address
+	Nonce          *big.Int
+}
+
+// Hash returns the hash of the transaction which must be signed by the owners of `safe`.
+// `chainID` must be set for Safe contracts v1.3.0 and later, and must be nil for earlier versions.
+func (tx *Transaction) Hash(safe common.Address, chainID *big.Int) common.Hash {
+	var domainSeparator common.Hash
+	if chainID != nil {
+		domainSeparator = crypto.Keccak256Hash(domainSeparatorChainIDTypeHash[:], eip712.Word(chainID), safe.Hash().Bytes())
+	} else {
+		domainSeparator = crypto.Keccak256Hash(domainSeparatorTypeHash[:], safe.Hash().Bytes())
+	}
+
+	structHash := crypto.Keccak256Hash(
+		safeTxTypeHash[:],
+		tx.To.Hash().Bytes(),
+		eip712.Word(tx.Value),
+		crypto.Keccak256(tx.Data),
+		eip712.Word(big.NewInt(int64(tx.Operation))),
+		eip712.Word(tx.SafeTxGas),
+		eip712.Word(tx.BaseGas),
+		eip712.Word(tx.GasPrice),
+		tx.GasToken.Hash().Bytes(),
+		tx.RefundReceiver.Hash().Bytes(),
+		eip712.Word(tx.Nonce),
+	)
+
+	return crypto.Keccak256Hash([]byte{0x19, 0x01}, domainSeparator[:], structHash[:])
+}
+
+// Signature is the signature of Safe transaction hash made by the owner.
+type Signature struct {
+	Owner common.Address
+	// Data is 65 bytes signature [R || S || V], where V is 27 or 28.
+	Data []byte
+}
+
+// Sign signs Safe transaction hash with the owner key.
+func Sign(hash common.Hash, key *ethereum.Key) (*Signature, error) {
+	sig, err := eip712.Sign(hash, key)
+	if err != nil {
+		return nil, err
+	}
+	return &Signature{Owner: key.Address, Data: sig}, nil
+}
+
+// EncodeSignatures returns signatures in the form accepted by execTransaction:
+// signatures are sorted by owner address and concatenated.
+func EncodeSignatures(sigs []*Signature) []byte {
+	sorted := make([]*Signature, len(sigs))
+	copy(sorted, sigs)
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i].Owner[:], sorted[j].Owner[:]) < 0
+	})
+
+	var res []byte
+	for _, sig := range sorted {
+		res = append(res, sig.Data...)
+	}
+	return res
+}
+
+const safeABI = `[{"constant":true,"inputs":[],"name":"nonce","outputs":[{"name":"","type":"uint256"}],"payable":false,"stateMutability":"view","type":"function"},{"constant":true,"inputs":[],"name":"getThreshold","outputs":[{"name":"","type":"uint256"}],"payable":false,"stateMutability":"view","type":"function"},{"constant":true,"inputs":[],"name":"getOwners","outputs":[{"name":"","type":"address[]"}],"payable":false,"stateMutability":"view","type":"function"},{"constant":false,"inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint256"},{"name":"data","type":"bytes"},{"name":"operation","type":"uint8"},{"name":"safeTxGas","type":"uint256"},{"name":"baseGas","type":"uint256"},{"name":"gasPrice","type":"uint256"},{"name":"gasToken","type":"address"},{"name":"refundReceiver","type":"address"},{"name":"signatures","type":"bytes"}],"name":"execTransaction","outputs":[{"name":"success","type":"bool"}],"payable":false,"stateMutability":"nonpayable","type":"function"}]`
+
+var parsedSafeABI = ethereum.MustParseABI(safeABI)
+
+// Safe is the Gnosis Safe contract.
+type Safe struct {
+	Address  common.Address
+	contract *bind.BoundContract
+}
+
+// New creates an instance of Safe bound to the contract at `address`.
+func New(address common.Address, backend bind.ContractBackend) *Safe {
+	return &Safe{
+		Address:  address,
+		contract: bind.NewBoundContract(address, parsedSafeABI, backend, backend, backend),
+	}
+}
+
+// Nonce returns the nonce which must be used for the next Safe transaction.
+func (s *Safe) Nonce(ctx context.Context) (*big.Int, error) {
+	var nonce *big.Int
+	if err := s.contract.Call(&bind.CallOpts{Context: ctx}, &nonce, "nonce"); err != nil {
+		return nil, fmt.Errorf("safe: nonce: %v", err)
+	}
+	return nonce, nil
+}
+
+// Threshold returns the number of owner signatures required to execute a transaction.
+func (s *Safe) Threshold(ctx context.Context) (*big.Int, error) {
+	var threshold *big.Int
+	if err := s.contract.Call(&bind.CallOpts{Context: ctx}, &threshold, "getThreshold"); err != nil {
+		return nil, fmt.Errorf("safe: getThreshold: %v", err)
+	}
+	return threshold, nil
+}
+
+// Owners returns the owners of the Safe.
+func (s *Safe) Owners(ctx context.Context) ([]common.Address, error) {
+	var owners []common.Address
+	if err := s.contract.Call(&bind.CallOpts{Context: ctx}, &owners, "getOwners"); err != nil {
+		return nil, fmt.Errorf("safe: getOwners: %v", err)
+	}
+	return owners, nil
+}
+
+// ExecTransaction submits the Safe transaction signed by the owners. `opts` are used to send
+// the Ethereum transaction, it can be sent by any account.
+func (s *Safe) ExecTransaction(opts *bind.TransactOpts, tx *Transaction, sigs []*Signature) (*types.Transaction, error) {
+	if len(sigs) == 0 {
+		return nil, errors.New("safe: no signatures")
+	}
+
+	return s.contract.Transact(opts, "execTransaction",
+		tx.To,
+		bigOrZero(tx.Value),
+		tx.Data,
+		uint8(tx.Operation),
+		bigOrZero(tx.SafeTxGas),
+		bigOrZero(tx.BaseGas),
+		bigOrZero(tx.GasPrice),
+		tx.GasToken,
+		tx.RefundReceiver,
+		EncodeSignatures(sigs),
+	)
+}
+
+func bigOrZero(v *big.Int) *big.Int {
+	if v == nil {
+		return new(big.Int)
+	}
+	return v
+}
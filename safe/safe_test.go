@@ -0,0 +1,62 @@
+package safe
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/monetha/go-ethereum"
+	"github.com/monetha/go-ethereum/eip712"
+)
+
+func TestEncodeSignatures(t *testing.T) {
+	sigs := []*Signature{
+		{Owner: common.HexToAddress("0x03"), Data: []byte{3}},
+		{Owner: common.HexToAddress("0x01"), Data: []byte{1}},
+		{Owner: common.HexToAddress("0x02"), Data: []byte{2}},
+	}
+
+	encoded := EncodeSignatures(sigs)
+	if expected := []byte{1, 2, 3}; !bytes.Equal(encoded, expected) {
+		t.Errorf("expected signatures %v, but got %v", expected, encoded)
+	}
+	if sigs[0].Owner != common.HexToAddress("0x03") {
+		t.Errorf("expected original signatures to be unchanged")
+	}
+}
+
+func TestSign(t *testing.T) {
+	key, err := ethereum.NewKey()
+	if err != nil {
+		t.Fatalf("NewKey: %v", err)
+	}
+
+	tx := &Transaction{
+		To:    common.HexToAddress("0x1234"),
+		Value: big.NewInt(1000),
+		Nonce: big.NewInt(1),
+	}
+	safeAddress := common.HexToAddress("0x5678")
+
+	hash := tx.Hash(safeAddress, big.NewInt(1))
+	if hash == tx.Hash(safeAddress, nil) {
+		t.Errorf("expected hash to depend on chain ID")
+	}
+
+	sig, err := Sign(hash, key)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	if v := sig.Data[64]; v != 27 && v != 28 {
+		t.Errorf("expected V to be 27 or 28, but got %v", v)
+	}
+
+	addr, err := eip712.Recover(hash, sig.Data)
+	if err != nil {
+		t.Fatalf("Recover: %v", err)
+	}
+	if addr != key.Address {
+		t.Errorf("expected signer %v, but got %v", key.Address.Hex(), addr.Hex())
+	}
+}
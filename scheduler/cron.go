@@ -0,0 +1,148 @@
+package scheduler
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cron is the recurrence by standard 5-field cron expression: minute, hour, day of month, month and day of week.
+// Fields support `*`, values, ranges (`1-5`), lists (`1,15`) and steps (`*/10`, `0-30/5`). Day of week is 0-7,
+// where both 0 and 7 are Sunday. Like in cron, when both day of month and day of week are restricted, the job
+// runs on days matching either of them. Names of months and days aren't supported.
+type Cron struct {
+	minute, hour, dom, month, dow uint64 // bit sets of allowed values
+	domAny, dowAny                bool
+	loc                           *time.Location
+}
+
+// ParseCron parses cron expression, times are matched in the location (UTC when it's nil).
+func ParseCron(expr string, loc *time.Location) (*Cron, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("scheduler: cron expression %q must have 5 fields", expr)
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	c := &Cron{
+		domAny: fields[2] == "*",
+		dowAny: fields[4] == "*",
+		loc:    loc,
+	}
+	var err error
+	if c.minute, err = parseCronField(fields[0], 0, 59); err != nil {
+		return nil, err
+	}
+	if c.hour, err = parseCronField(fields[1], 0, 23); err != nil {
+		return nil, err
+	}
+	if c.dom, err = parseCronField(fields[2], 1, 31); err != nil {
+		return nil, err
+	}
+	if c.month, err = parseCronField(fields[3], 1, 12); err != nil {
+		return nil, err
+	}
+	if c.dow, err = parseCronField(fields[4], 0, 7); err != nil {
+		return nil, err
+	}
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1 // 7 is Sunday
+	}
+	return c, nil
+}
+
+// parseCronField returns the bit set of values allowed by the field.
+func parseCronField(field string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, step := part, 1
+		if i := strings.IndexByte(part, '/'); i >= 0 {
+			var err error
+			rng = part[:i]
+			if step, err = strconv.Atoi(part[i+1:]); err != nil || step <= 0 {
+				return 0, fmt.Errorf("scheduler: invalid cron step in %q", part)
+			}
+		}
+
+		lo, hi := min, max
+		switch {
+		case rng == "*":
+		case strings.IndexByte(rng, '-') >= 0:
+			i := strings.IndexByte(rng, '-')
+			var err1, err2 error
+			lo, err1 = strconv.Atoi(rng[:i])
+			hi, err2 = strconv.Atoi(rng[i+1:])
+			if err1 != nil || err2 != nil {
+				return 0, fmt.Errorf("scheduler: invalid cron range %q", part)
+			}
+		default:
+			v, err := strconv.Atoi(rng)
+			if err != nil {
+				return 0, fmt.Errorf("scheduler: invalid cron value %q", part)
+			}
+			lo = v
+			if step == 1 {
+				hi = v // single value, otherwise value with step means from value to max
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("scheduler: cron value %q out of range %v-%v", part, min, max)
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// Next implements Recurrence interface, the block number is ignored.
+func (c *Cron) Next(block *big.Int, now time.Time) Trigger {
+	return Trigger{Time: c.next(now)}
+}
+
+// next returns the first matching minute after t, or zero time when there is none within 5 years
+// (e.g. February 30).
+func (c *Cron) next(t time.Time) time.Time {
+	t = t.In(c.loc)
+	t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, c.loc).Add(time.Minute)
+
+	for limit := t.AddDate(5, 0, 0); t.Before(limit); {
+		switch {
+		case !has(c.month, int(t.Month())):
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, c.loc)
+		case !c.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, c.loc)
+		case !has(c.hour, t.Hour()):
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, c.loc)
+		case !has(c.minute, t.Minute()):
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (c *Cron) dayMatches(t time.Time) bool {
+	dom := has(c.dom, t.Day())
+	dow := has(c.dow, int(t.Weekday()))
+	switch {
+	case c.domAny && c.dowAny:
+		return true
+	case c.domAny:
+		return dow
+	case c.dowAny:
+		return dom
+	default:
+		return dom || dow
+	}
+}
+
+func has(bits uint64, v int) bool {
+	return bits&(1<<uint(v)) != 0
+}
@@ -0,0 +1,45 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCron_Next(t *testing.T) {
+	// 2020-01-01 is Wednesday
+	now := time.Date(2020, 1, 1, 10, 30, 15, 0, time.UTC)
+	tests := []struct {
+		expr     string
+		expected time.Time
+	}{
+		{"* * * * *", time.Date(2020, 1, 1, 10, 31, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2020, 1, 1, 10, 45, 0, 0, time.UTC)},
+		{"0 0 * * *", time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{"30 9-17 * * 1-5", time.Date(2020, 1, 1, 11, 30, 0, 0, time.UTC)},
+		{"0 12 * * 7", time.Date(2020, 1, 5, 12, 0, 0, 0, time.UTC)},
+		{"0 0 1,15 * *", time.Date(2020, 1, 15, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2020, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 13 * 5", time.Date(2020, 1, 3, 0, 0, 0, 0, time.UTC)}, // 13th or Friday
+		{"0 0 30 2 *", time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			c, err := ParseCron(tt.expr, nil)
+			if err != nil {
+				t.Fatalf("ParseCron: %v", err)
+			}
+			if next := c.Next(nil, now).Time; !next.Equal(tt.expected) {
+				t.Errorf("expected %v, but got %v", tt.expected, next)
+			}
+		})
+	}
+}
+
+func TestParseCron_Invalid(t *testing.T) {
+	for _, expr := range []string{"* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		if _, err := ParseCron(expr, nil); err == nil {
+			t.Errorf("expected error parsing %q", expr)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"time"
+
+	eth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/monetha/go-ethereum"
+)
+
+// ErrDuplicateJob is returned when adding the recurring job with the name which is already used.
+var ErrDuplicateJob = errors.New("scheduler: recurring job already exists")
+
+// Recurrence decides when the recurring job is due next.
+type Recurrence interface {
+	// Next returns the trigger of the run following the one at the given block number (nil when it's unknown)
+	// and time. Zero trigger means the next run can't be decided yet.
+	Next(block *big.Int, now time.Time) Trigger
+}
+
+// EveryBlocks is the recurrence of the job which runs every N blocks.
+type EveryBlocks uint64
+
+// Next implements Recurrence interface.
+func (n EveryBlocks) Next(block *big.Int, now time.Time) Trigger {
+	if block == nil {
+		return Trigger{}
+	}
+	return Trigger{Block: new(big.Int).Add(block, new(big.Int).SetUint64(uint64(n)))}
+}
+
+// Builder creates the transaction of the recurring job when it's due, so that it reflects the current state.
+// Gas price of the session is used unless the builder sets it, nonce is assigned when the transaction is sent.
+type Builder func(ctx context.Context) (ethereum.PreparedTx, error)
+
+// Run is the result of running the recurring job.
+type Run struct {
+	Name string
+	Tx   *types.Transaction // nil when the run failed
+	// Err is the error of building or sending the transaction, ErrNoHeads when the job by blocks can't run
+	// because the latest block number is unknown.
+	Err error
+	// RetryAt is the time the failed run is retried at.
+	RetryAt time.Time
+}
+
+type recurringJob struct {
+	name  string
+	every Recurrence
+	build Builder
+
+	next     Trigger
+	pending  *types.Transaction // transaction of the previous run until it's mined
+	sentAt   time.Time          // time the pending transaction was sent
+	failures int                // consecutive failures
+	retryAt  time.Time
+}
+
+// AddRecurring adds the job which sends the transaction created by `build` whenever it's due according to
+// `every` (e.g. EveryBlocks or cron expression parsed with ParseCron). The first run is due after the first
+// interval. Runs don't overlap: the run which is due while the transaction of the previous one isn't mined
+// is skipped, unless the transaction isn't known by the node after Config.PendingTimeout. Failed runs are retried with backoff (see Config.MinBackoff).
+func (s *Scheduler) AddRecurring(name string, every Recurrence, build Builder) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, r := range s.recurring {
+		if r.name == name {
+			return ErrDuplicateJob
+		}
+	}
+	s.recurring = append(s.recurring, &recurringJob{name: name, every: every, build: build})
+	return nil
+}
+
+// RemoveRecurring removes the recurring job, it returns false when the job doesn't exist.
+func (s *Scheduler) RemoveRecurring(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, r := range s.recurring {
+		if r.name == name {
+			s.recurring = append(s.recurring[:i], s.recurring[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// runRecurring runs recurring jobs which are due.
+func (s *Scheduler) runRecurring(ctx context.Context) ([]*Run, error) {
+	if len(s.recurring) == 0 {
+		return nil, nil
+	}
+
+	block, err := s.blockNumber(ctx)
+	if err == ErrNoHeads {
+		block = nil // only recurrences by time can be used
+	} else if err != nil {
+		return nil, err
+	}
+	now := s.now()
+
+	var runs []*Run
+	for _, r := range s.recurring {
+		if r.pending != nil {
+			if _, err := s.session.Backend.TransactionReceipt(ctx, r.pending.Hash()); err != nil && !s.dropped(ctx, r, now) {
+				continue // previous run isn't mined yet (or its receipt can't be retrieved)
+			}
+			r.pending = nil
+		}
+
+		if r.next.IsZero() {
+			if r.next = r.every.Next(block, now); r.next.IsZero() && block == nil {
+				runs = append(runs, &Run{Name: r.name, Err: ErrNoHeads})
+			}
+			continue
+		}
+		if r.next.Block != nil && block == nil {
+			runs = append(runs, &Run{Name: r.name, Err: ErrNoHeads})
+			continue
+		}
+		if now.Before(r.retryAt) || !r.next.due(block, now) {
+			continue
+		}
+
+		run := &Run{Name: r.name}
+		run.Tx, run.Err = s.runOnce(ctx, r)
+		if run.Err != nil {
+			r.failures++
+			r.retryAt = now.Add(s.backoff(r.failures))
+			run.RetryAt = r.retryAt
+			s.session.Log("Recurring job failed", "job", r.name, "error", run.Err, "retry_at", r.retryAt)
+		} else {
+			r.pending = run.Tx
+			r.sentAt = now
+			r.failures = 0
+			r.retryAt = time.Time{}
+			r.next = r.every.Next(block, now)
+			s.session.Log("Recurring job sent", "job", r.name, "tx_hash", run.Tx.Hash().Hex())
+		}
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
+
+// dropped tells whether the pending transaction of the job isn't known by the node after Config.PendingTimeout,
+// i.e. it was evicted from the transaction pool or replaced by another transaction with the same nonce.
+func (s *Scheduler) dropped(ctx context.Context, r *recurringJob, now time.Time) bool {
+	if now.Sub(r.sentAt) < s.cfg.PendingTimeout {
+		return false
+	}
+	if _, _, err := s.session.Backend.TransactionByHash(ctx, r.pending.Hash()); err != eth.NotFound {
+		return false
+	}
+	s.session.Log("Recurring job transaction dropped", "job", r.name, "tx_hash", r.pending.Hash().Hex())
+	return true
+}
+
+func (s *Scheduler) runOnce(ctx context.Context, r *recurringJob) (*types.Transaction, error) {
+	tx, err := r.build(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res := s.session.SendBatch(ctx, []ethereum.PreparedTx{tx})[0]
+	return res.Tx, res.Err
+}
+
+// backoff returns the delay before the retry after the given number of consecutive failures.
+func (s *Scheduler) backoff(failures int) time.Duration {
+	d := s.cfg.MinBackoff
+	for i := 1; i < failures && d < s.cfg.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > s.cfg.MaxBackoff {
+		d = s.cfg.MaxBackoff
+	}
+	return d
+}
@@ -0,0 +1,194 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/monetha/go-ethereum"
+)
+
+// heads is the latest block number which can be changed by the test.
+type heads struct{ number int64 }
+
+func (h *heads) BlockNumber(ctx context.Context) (*big.Int, error) { return big.NewInt(h.number), nil }
+
+func TestScheduler_Recurring(t *testing.T) {
+	b := &sendingBackend{mined: true}
+	h := &heads{number: 100}
+	var runs []*Run
+	s := New(newTestSession(b), NewMemoryStore(), &Config{Heads: h, OnRun: func(r *Run) { runs = append(runs, r) }})
+
+	builds := 0
+	err := s.AddRecurring("payout", EveryBlocks(10), func(ctx context.Context) (ethereum.PreparedTx, error) {
+		builds++
+		return ethereum.PreparedTx{To: common.HexToAddress("0x1234"), Value: big.NewInt(int64(builds))}, nil
+	})
+	if err != nil {
+		t.Fatalf("AddRecurring: %v", err)
+	}
+	if err := s.AddRecurring("payout", EveryBlocks(1), nil); err != ErrDuplicateJob {
+		t.Errorf("expected ErrDuplicateJob, but got %v", err)
+	}
+
+	for _, number := range []int64{100, 105, 110, 115, 120} {
+		h.number = number
+		if _, err := s.Tick(context.Background()); err != nil {
+			t.Fatalf("Tick: %v", err)
+		}
+	}
+
+	if len(b.sent) != 2 || len(runs) != 2 {
+		t.Fatalf("expected 2 runs at blocks 110 and 120, but got %v transactions and %v runs", len(b.sent), len(runs))
+	}
+	// transaction is built at execution time
+	if b.sent[1].Value().Int64() != 2 {
+		t.Errorf("expected the second transaction to be built at execution time, but got value %v", b.sent[1].Value())
+	}
+
+	if !s.RemoveRecurring("payout") || s.RemoveRecurring("payout") {
+		t.Errorf("expected recurring job to be removed once")
+	}
+}
+
+func TestScheduler_Recurring_NoOverlap(t *testing.T) {
+	b := &sendingBackend{}
+	h := &heads{number: 100}
+	s := New(newTestSession(b), NewMemoryStore(), &Config{Heads: h})
+
+	err := s.AddRecurring("payout", EveryBlocks(1), func(ctx context.Context) (ethereum.PreparedTx, error) {
+		return ethereum.PreparedTx{To: common.HexToAddress("0x1234")}, nil
+	})
+	if err != nil {
+		t.Fatalf("AddRecurring: %v", err)
+	}
+
+	for number := int64(100); number <= 105; number++ {
+		h.number = number
+		if _, err := s.Tick(context.Background()); err != nil {
+			t.Fatalf("Tick: %v", err)
+		}
+	}
+	if len(b.sent) != 1 {
+		t.Fatalf("expected one transaction while it isn't mined, but got %v", len(b.sent))
+	}
+
+	b.mined = true
+	h.number++
+	if _, err := s.Tick(context.Background()); err != nil {
+		t.Fatalf("Tick: %v", err)
+	}
+	if len(b.sent) != 2 {
+		t.Errorf("expected the next run after the transaction is mined, but got %v transactions", len(b.sent))
+	}
+}
+
+func TestScheduler_Recurring_Backoff(t *testing.T) {
+	b := &sendingBackend{sendErr: errors.New("send failed")}
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	var backoffs []time.Duration
+	s := New(newTestSession(b), NewMemoryStore(), &Config{
+		Heads:      &heads{number: 100},
+		MinBackoff: time.Minute,
+		MaxBackoff: 3 * time.Minute,
+		OnRun: func(r *Run) {
+			if r.Err == nil {
+				t.Errorf("expected run to fail")
+			}
+			backoffs = append(backoffs, r.RetryAt.Sub(now))
+		},
+	})
+	s.now = func() time.Time { return now }
+
+	every, err := ParseCron("* * * * *", nil)
+	if err != nil {
+		t.Fatalf("ParseCron: %v", err)
+	}
+	err = s.AddRecurring("payout", every, func(ctx context.Context) (ethereum.PreparedTx, error) {
+		return ethereum.PreparedTx{To: common.HexToAddress("0x1234")}, nil
+	})
+	if err != nil {
+		t.Fatalf("AddRecurring: %v", err)
+	}
+
+	// the first tick decides the first run, then runs are retried at minutes 2, 4, 7 and 10
+	for i := 0; i <= 10; i++ {
+		if _, err := s.Tick(context.Background()); err != nil {
+			t.Fatalf("Tick: %v", err)
+		}
+		now = now.Add(time.Minute)
+	}
+
+	expected := []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute, 3 * time.Minute, 3 * time.Minute}
+	if len(backoffs) != len(expected) {
+		t.Fatalf("expected %v runs, but got %v", len(expected), len(backoffs))
+	}
+	for i, d := range backoffs {
+		if d != expected[i] {
+			t.Errorf("expected backoff %v after failure %v, but got %v", expected[i], i+1, d)
+		}
+	}
+}
+
+func TestScheduler_Recurring_PendingTimeout(t *testing.T) {
+	b := &sendingBackend{}
+	h := &heads{number: 100}
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := New(newTestSession(b), NewMemoryStore(), &Config{Heads: h, PendingTimeout: time.Hour})
+	s.now = func() time.Time { return now }
+
+	err := s.AddRecurring("payout", EveryBlocks(1), func(ctx context.Context) (ethereum.PreparedTx, error) {
+		return ethereum.PreparedTx{To: common.HexToAddress("0x1234")}, nil
+	})
+	if err != nil {
+		t.Fatalf("AddRecurring: %v", err)
+	}
+
+	tick := func() {
+		h.number++
+		now = now.Add(time.Minute)
+		if _, err := s.Tick(context.Background()); err != nil {
+			t.Fatalf("Tick: %v", err)
+		}
+	}
+	tick()
+	tick()
+	if len(b.sent) != 1 {
+		t.Fatalf("expected one transaction, but got %v", len(b.sent))
+	}
+
+	now = now.Add(time.Hour)
+	tick()
+	if len(b.sent) != 1 {
+		t.Fatalf("expected no run while the transaction is pending, but got %v transactions", len(b.sent))
+	}
+
+	b.dropped = true
+	tick()
+	if len(b.sent) != 2 {
+		t.Errorf("expected the next run after the transaction is dropped, but got %v transactions", len(b.sent))
+	}
+}
+
+func TestScheduler_Recurring_NoHeads(t *testing.T) {
+	b := &sendingBackend{}
+	var runs []*Run
+	s := New(newTestSession(b), NewMemoryStore(), &Config{OnRun: func(r *Run) { runs = append(runs, r) }})
+
+	err := s.AddRecurring("payout", EveryBlocks(10), func(ctx context.Context) (ethereum.PreparedTx, error) {
+		return ethereum.PreparedTx{To: common.HexToAddress("0x1234")}, nil
+	})
+	if err != nil {
+		t.Fatalf("AddRecurring: %v", err)
+	}
+
+	if _, err := s.Tick(context.Background()); err != nil {
+		t.Fatalf("Tick: %v", err)
+	}
+	if len(runs) != 1 || runs[0].Name != "payout" || runs[0].Err != ErrNoHeads {
+		t.Errorf("expected run to report ErrNoHeads, but got %+v", runs)
+	}
+}
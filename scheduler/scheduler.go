@@ -0,0 +1,454 @@
+// Package scheduler sends transactions at a future block number or time, e.g. delayed payouts or timed contract
+// calls. Scheduled jobs are persisted, so they survive restarts, and they can be cancelled or postponed before
+// they are due, which makes a dead-man's switch: the transaction is sent unless it's postponed in time.
+//
+// Recurring jobs (every N blocks or by cron expression) build their transactions when they are due, so gas price
+// and nonce are fresh. They live in memory and must be added again after restart.
+package scheduler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/monetha/go-ethereum"
+	"github.com/monetha/go-ethereum/backend"
+)
+
+// Status is the status of the job.
+type Status string
+
+const (
+	// StatusScheduled means the job waits until it's due.
+	StatusScheduled Status = "scheduled"
+	// StatusSending means the transaction of the job is being sent. The job stays in this status when the result
+	// of sending wasn't saved (e.g. the process crashed), it isn't sent again, as it may have been sent already.
+	StatusSending Status = "sending"
+	// StatusSent means the transaction of the job was sent.
+	StatusSent Status = "sent"
+	// StatusFailed means the transaction of the job failed to be sent.
+	StatusFailed Status = "failed"
+	// StatusCancelled means the job was cancelled.
+	StatusCancelled Status = "cancelled"
+)
+
+// Defaults of Config.
+const (
+	DefaultInterval       = 10 * time.Second
+	DefaultMinBackoff     = 30 * time.Second
+	DefaultMaxBackoff     = 30 * time.Minute
+	DefaultPendingTimeout = 10 * time.Minute
+)
+
+var (
+	// ErrNotScheduled is returned when cancelling or postponing the job which isn't scheduled.
+	ErrNotScheduled = errors.New("scheduler: job is not scheduled")
+	// ErrNoTrigger is returned when neither block number nor time of the trigger is set.
+	ErrNoTrigger = errors.New("scheduler: trigger is not set")
+	// ErrNoHeads means the latest block number can't be retrieved (see Config.Heads), so jobs scheduled at
+	// a block number aren't due, while jobs scheduled at time are sent. Recurring jobs by blocks report it
+	// through Config.OnRun.
+	ErrNoHeads = errors.New("scheduler: latest block number is not available")
+)
+
+// Trigger is the moment the job is due: when the block with the number is mined and the time has come.
+// At least one of them must be set.
+type Trigger struct {
+	Block *big.Int  // nil = not triggered by block
+	Time  time.Time // zero = not triggered by time
+}
+
+// IsZero tells whether neither block number nor time is set.
+func (t Trigger) IsZero() bool {
+	return t.Block == nil && t.Time.IsZero()
+}
+
+// due tells whether the trigger is due given the latest block number (nil when it's unknown) and the current time.
+func (t Trigger) due(block *big.Int, now time.Time) bool {
+	if t.Block != nil && (block == nil || block.Cmp(t.Block) < 0) {
+		return false
+	}
+	return t.Time.IsZero() || !now.Before(t.Time)
+}
+
+// Job is the transaction scheduled to be sent.
+type Job struct {
+	ID        string         `json:"id"`
+	To        common.Address `json:"to"`
+	Value     *hexutil.Big   `json:"value"`
+	Data      hexutil.Bytes  `json:"data,omitempty"`
+	GasLimit  hexutil.Uint64 `json:"gasLimit,omitempty"`
+	AtBlock   *hexutil.Big   `json:"atBlock,omitempty"`
+	AtTime    *time.Time     `json:"atTime,omitempty"`
+	CreatedAt time.Time      `json:"createdAt"`
+	Status    Status         `json:"status"`
+	TxHash    *common.Hash   `json:"txHash,omitempty"`
+	Error     string         `json:"error,omitempty"`
+}
+
+func (j *Job) setTrigger(at Trigger) {
+	j.AtBlock = nil
+	if at.Block != nil {
+		j.AtBlock = (*hexutil.Big)(new(big.Int).Set(at.Block))
+	}
+	j.AtTime = nil
+	if !at.Time.IsZero() {
+		t := at.Time
+		j.AtTime = &t
+	}
+}
+
+// due tells whether the job is due given the latest block number (nil when it isn't needed) and the current time.
+func (j *Job) due(block *big.Int, now time.Time) bool {
+	at := Trigger{}
+	if j.AtBlock != nil {
+		at.Block = j.AtBlock.ToInt()
+	}
+	if j.AtTime != nil {
+		at.Time = *j.AtTime
+	}
+	return at.due(block, now)
+}
+
+func (j *Job) tx() ethereum.PreparedTx {
+	return ethereum.PreparedTx{
+		To:       j.To,
+		Value:    new(big.Int).Set(j.Value.ToInt()),
+		Data:     j.Data,
+		GasLimit: uint64(j.GasLimit),
+	}
+}
+
+func (j *Job) clone() *Job {
+	c := *j
+	if j.Value != nil {
+		c.Value = (*hexutil.Big)(new(big.Int).Set(j.Value.ToInt()))
+	}
+	c.Data = append(hexutil.Bytes(nil), j.Data...)
+	if j.AtBlock != nil {
+		c.AtBlock = (*hexutil.Big)(new(big.Int).Set(j.AtBlock.ToInt()))
+	}
+	if j.AtTime != nil {
+		t := *j.AtTime
+		c.AtTime = &t
+	}
+	if j.TxHash != nil {
+		h := *j.TxHash
+		c.TxHash = &h
+	}
+	return &c
+}
+
+// HeadReader returns the number of the latest block, it's implemented by headtracker.Tracker and client.Client.
+type HeadReader interface {
+	BlockNumber(ctx context.Context) (*big.Int, error)
+}
+
+type headerReader interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// Config configures Scheduler.
+type Config struct {
+	// Interval is the interval of checking whether jobs are due, DefaultInterval is used when it's zero.
+	Interval time.Duration
+	// Heads provides the latest block number for jobs scheduled at a block number, otherwise the latest header
+	// is requested from the backend of the session if it supports it (optional).
+	Heads HeadReader
+	// MinBackoff is the delay before the failed run of the recurring job is retried, it's doubled with every
+	// consecutive failure up to MaxBackoff. DefaultMinBackoff and DefaultMaxBackoff are used when they're zero.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// PendingTimeout is the duration after which the pending transaction of the recurring job which isn't known
+	// by the node anymore (dropped or replaced) is forgotten, so that the job runs again. DefaultPendingTimeout
+	// is used when it's zero.
+	PendingTimeout time.Duration
+	// OnRun is called with the result of every run of recurring jobs (optional).
+	OnRun func(*Run)
+}
+
+// Scheduler sends transactions of due jobs from the session account.
+type Scheduler struct {
+	session *ethereum.Session
+	store   Store
+	cfg     Config
+	now     func() time.Time
+
+	mu        sync.Mutex
+	recurring []*recurringJob
+
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+// New creates an instance of Scheduler. Jobs aren't sent in background until Start is called, Tick can be used
+// instead to send due jobs on demand.
+func New(session *ethereum.Session, store Store, cfg *Config) *Scheduler {
+	s := &Scheduler{
+		session: session,
+		store:   store,
+		now:     time.Now,
+		closed:  make(chan struct{}),
+	}
+	if cfg != nil {
+		s.cfg = *cfg
+	}
+	if s.cfg.Interval == 0 {
+		s.cfg.Interval = DefaultInterval
+	}
+	if s.cfg.MinBackoff == 0 {
+		s.cfg.MinBackoff = DefaultMinBackoff
+	}
+	if s.cfg.MaxBackoff == 0 {
+		s.cfg.MaxBackoff = DefaultMaxBackoff
+	}
+	if s.cfg.PendingTimeout == 0 {
+		s.cfg.PendingTimeout = DefaultPendingTimeout
+	}
+	return s
+}
+
+// Schedule saves the job which sends the transaction when it's due.
+func (s *Scheduler) Schedule(tx ethereum.PreparedTx, at Trigger) (*Job, error) {
+	if at.IsZero() {
+		return nil, ErrNoTrigger
+	}
+
+	value := tx.Value
+	if value == nil {
+		value = new(big.Int)
+	}
+
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+	job := &Job{
+		ID:        id,
+		To:        tx.To,
+		Value:     (*hexutil.Big)(new(big.Int).Set(value)),
+		Data:      tx.Data,
+		GasLimit:  hexutil.Uint64(tx.GasLimit),
+		CreatedAt: s.now(),
+		Status:    StatusScheduled,
+	}
+	job.setTrigger(at)
+
+	s.session.Log("Transaction scheduled", "job", job.ID, "to", tx.To.Hex(), "block", at.Block, "time", job.AtTime)
+	if err := s.store.Save(job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// Postpone replaces the trigger of the scheduled job, e.g. to check in with a dead-man's switch.
+func (s *Scheduler) Postpone(id string, at Trigger) (*Job, error) {
+	if at.IsZero() {
+		return nil, ErrNoTrigger
+	}
+	return s.update(id, func(job *Job) {
+		job.setTrigger(at)
+	})
+}
+
+// Cancel cancels the scheduled job, so it's never sent.
+func (s *Scheduler) Cancel(id string) (*Job, error) {
+	return s.update(id, func(job *Job) {
+		job.Status = StatusCancelled
+	})
+}
+
+func (s *Scheduler) update(id string, f func(job *Job)) (*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, err := s.store.Load(id)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status != StatusScheduled {
+		return nil, ErrNotScheduled
+	}
+
+	f(job)
+	if err := s.store.Save(job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// Scheduled returns jobs which wait until they are due.
+func (s *Scheduler) Scheduled() ([]*Job, error) {
+	jobs, err := s.store.List()
+	if err != nil {
+		return nil, err
+	}
+	res := jobs[:0]
+	for _, job := range jobs {
+		if job.Status == StatusScheduled {
+			res = append(res, job)
+		}
+	}
+	return res, nil
+}
+
+// Tick sends transactions of jobs which are due (in the order they were scheduled) and returns the jobs.
+// Then it runs recurring jobs which are due, results of the runs are passed to Config.OnRun. Jobs scheduled
+// at a block number aren't due while the latest block number can't be retrieved, the error is logged.
+func (s *Scheduler) Tick(ctx context.Context) ([]*Job, error) {
+	s.mu.Lock()
+	due, err := s.sendDue(ctx)
+	var runs []*Run
+	if err == nil {
+		runs, err = s.runRecurring(ctx)
+	}
+	s.mu.Unlock()
+
+	if s.cfg.OnRun != nil {
+		for _, run := range runs {
+			s.cfg.OnRun(run)
+		}
+	}
+	return due, err
+}
+
+// sendDue sends transactions of jobs which are due.
+func (s *Scheduler) sendDue(ctx context.Context) ([]*Job, error) {
+	jobs, err := s.Scheduled()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		block          *big.Int
+		blockRequested bool
+	)
+	now := s.now()
+	var due []*Job
+	for _, job := range jobs {
+		if job.AtBlock != nil && !blockRequested {
+			blockRequested = true
+			block, err = s.blockNumber(ctx)
+			if err != nil {
+				if err != ErrNoHeads {
+					s.session.Log("Failed to get block number, jobs at block are postponed", "error", err)
+				}
+				block = nil // jobs at block aren't due until the block number is available
+			}
+		}
+		if job.due(block, now) {
+			due = append(due, job)
+		}
+	}
+	if len(due) == 0 {
+		return nil, nil
+	}
+
+	txs := make([]ethereum.PreparedTx, len(due))
+	for i, job := range due {
+		job.Status = StatusSending
+		if err := s.store.Save(job); err != nil {
+			return nil, err
+		}
+		txs[i] = job.tx()
+	}
+
+	var saveErr error
+	for i, res := range s.session.SendBatch(ctx, txs) {
+		job := due[i]
+		if res.Err != nil {
+			job.Status = StatusFailed
+			job.Error = res.Err.Error()
+		} else {
+			hash := res.Tx.Hash()
+			job.Status = StatusSent
+			job.TxHash = &hash
+		}
+		s.session.Log("Scheduled transaction sent", "job", job.ID, "status", job.Status, "error", job.Error)
+
+		if err := s.store.Save(job); err != nil && saveErr == nil {
+			saveErr = err
+		}
+	}
+	return due, saveErr
+}
+
+// blockNumber returns the latest block number.
+func (s *Scheduler) blockNumber(ctx context.Context) (*big.Int, error) {
+	if s.cfg.Heads != nil {
+		return s.cfg.Heads.BlockNumber(ctx)
+	}
+	hr, ok := s.session.Backend.(headerReader)
+	if !ok {
+		return nil, ErrNoHeads
+	}
+	h, err := hr.HeaderByNumber(ctx, nil)
+	if err == backend.ErrNoHeaders {
+		return nil, ErrNoHeads // decorator of the backend which can't read headers
+	}
+	if err != nil {
+		return nil, err
+	}
+	return h.Number, nil
+}
+
+// Start sends due jobs every Config.Interval in background until Close is called.
+func (s *Scheduler) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancelOnClose(cancel)
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		for {
+			if _, err := s.Tick(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("scheduler: %v", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(s.cfg.Interval):
+			}
+		}
+	}()
+}
+
+// Close stops sending jobs in background.
+func (s *Scheduler) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.closed)
+
+		s.wg.Wait()
+	})
+	return nil
+}
+
+func (s *Scheduler) cancelOnClose(cancel context.CancelFunc) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer cancel()
+
+		<-s.closed
+	}()
+}
+
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("scheduler: %v", err)
+	}
+	return hex.EncodeToString(b), nil
+}
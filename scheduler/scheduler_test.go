@@ -0,0 +1,274 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	eth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/monetha/go-ethereum"
+	"github.com/monetha/go-ethereum/backend"
+)
+
+type sendingBackend struct {
+	backend.Backend
+	sent    []*types.Transaction
+	mined   bool // whether sent transactions are mined
+	dropped bool // whether sent transactions aren't known by the node
+	sendErr error
+}
+
+func (b *sendingBackend) EstimateGas(ctx context.Context, call eth.CallMsg) (uint64, error) {
+	return 21000, nil
+}
+
+func (b *sendingBackend) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return uint64(len(b.sent)), nil
+}
+
+func (b *sendingBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	if b.sendErr != nil {
+		return b.sendErr
+	}
+	b.sent = append(b.sent, tx)
+	return nil
+}
+
+func (b *sendingBackend) TransactionByHash(ctx context.Context, txHash common.Hash) (*types.Transaction, bool, error) {
+	if b.dropped {
+		return nil, false, eth.NotFound
+	}
+	return nil, true, nil
+}
+
+func (b *sendingBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	if !b.mined {
+		return nil, eth.NotFound
+	}
+	return &types.Receipt{Status: types.ReceiptStatusSuccessful}, nil
+}
+
+type constHeads int64
+
+func (h constHeads) BlockNumber(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(int64(h)), nil
+}
+
+type failingHeads struct{}
+
+func (failingHeads) BlockNumber(ctx context.Context) (*big.Int, error) {
+	return nil, errors.New("connection refused")
+}
+
+func newTestSession(b backend.Backend) *ethereum.Session {
+	return &ethereum.Session{
+		Eth: ethereum.NewEth(b),
+		TransactOpts: bind.TransactOpts{
+			GasPrice: big.NewInt(1),
+			Signer: func(signer types.Signer, address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+				return tx, nil
+			},
+		},
+	}
+}
+
+func TestScheduler_Tick(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		at    Trigger
+		heads constHeads
+		now   time.Time
+		sent  bool
+	}{
+		{"block not reached", Trigger{Block: big.NewInt(100)}, 99, start, false},
+		{"block reached", Trigger{Block: big.NewInt(100)}, 100, start, true},
+		{"time not reached", Trigger{Time: start.Add(time.Hour)}, 0, start, false},
+		{"time reached", Trigger{Time: start.Add(time.Hour)}, 0, start.Add(time.Hour), true},
+		{"block reached, time not reached", Trigger{Block: big.NewInt(100), Time: start.Add(time.Hour)}, 100, start, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &sendingBackend{}
+			s := New(newTestSession(b), NewMemoryStore(), &Config{Heads: tt.heads})
+			s.now = func() time.Time { return tt.now }
+
+			job, err := s.Schedule(ethereum.PreparedTx{To: common.HexToAddress("0x1234"), Value: big.NewInt(5)}, tt.at)
+			if err != nil {
+				t.Fatalf("Schedule: %v", err)
+			}
+
+			due, err := s.Tick(context.Background())
+			if err != nil {
+				t.Fatalf("Tick: %v", err)
+			}
+			if sent := len(b.sent) == 1; sent != tt.sent {
+				t.Fatalf("expected sent %v, but got %v transactions", tt.sent, len(b.sent))
+			}
+			if !tt.sent {
+				if len(due) != 0 {
+					t.Errorf("expected no due jobs, but got %v", len(due))
+				}
+				return
+			}
+			if len(due) != 1 || due[0].ID != job.ID || due[0].Status != StatusSent || due[0].TxHash == nil {
+				t.Errorf("expected job %v to be sent, but got %+v", job.ID, due)
+			}
+
+			// sent job isn't sent again
+			if _, err := s.Tick(context.Background()); err != nil {
+				t.Fatalf("Tick: %v", err)
+			}
+			if len(b.sent) != 1 {
+				t.Errorf("expected job to be sent once, but got %v transactions", len(b.sent))
+			}
+		})
+	}
+}
+
+func TestScheduler_Tick_NoHeads(t *testing.T) {
+	b := &sendingBackend{}
+	s := New(newTestSession(b), NewMemoryStore(), nil)
+
+	if _, err := s.Schedule(ethereum.PreparedTx{To: common.HexToAddress("0x1234")}, Trigger{Block: big.NewInt(100)}); err != nil {
+		t.Fatalf("Schedule: %v", err)
+	}
+	job, err := s.Schedule(ethereum.PreparedTx{To: common.HexToAddress("0x1234")}, Trigger{Time: time.Now().Add(-time.Minute)})
+	if err != nil {
+		t.Fatalf("Schedule: %v", err)
+	}
+
+	due, err := s.Tick(context.Background())
+	if err != nil {
+		t.Fatalf("Tick: %v", err)
+	}
+	if len(due) != 1 || due[0].ID != job.ID || len(b.sent) != 1 {
+		t.Errorf("expected only job %v at time to be sent, but got %+v", job.ID, due)
+	}
+}
+
+func TestScheduler_Tick_HeadsError(t *testing.T) {
+	b := &sendingBackend{}
+	s := New(newTestSession(b), NewMemoryStore(), &Config{Heads: failingHeads{}})
+
+	if _, err := s.Schedule(ethereum.PreparedTx{To: common.HexToAddress("0x1234")}, Trigger{Block: big.NewInt(100)}); err != nil {
+		t.Fatalf("Schedule: %v", err)
+	}
+	job, err := s.Schedule(ethereum.PreparedTx{To: common.HexToAddress("0x1234")}, Trigger{Time: time.Now().Add(-time.Minute)})
+	if err != nil {
+		t.Fatalf("Schedule: %v", err)
+	}
+
+	due, err := s.Tick(context.Background())
+	if err != nil {
+		t.Fatalf("Tick: %v", err)
+	}
+	if len(due) != 1 || due[0].ID != job.ID || len(b.sent) != 1 {
+		t.Errorf("expected only job %v at time to be sent, but got %+v", job.ID, due)
+	}
+}
+
+func TestScheduler_Tick_NoHeaders(t *testing.T) {
+	b := &sendingBackend{}
+	s := New(newTestSession(backend.NewHandleNonceBackend(b, nil)), NewMemoryStore(), nil)
+
+	if _, err := s.Schedule(ethereum.PreparedTx{To: common.HexToAddress("0x1234")}, Trigger{Block: big.NewInt(100)}); err != nil {
+		t.Fatalf("Schedule: %v", err)
+	}
+	if due, err := s.Tick(context.Background()); err != nil || len(due) != 0 {
+		t.Errorf("expected job at block not to be due while the block number is unknown, but got %+v, %v", due, err)
+	}
+}
+
+func TestScheduler_CancelAndPostpone(t *testing.T) {
+	b := &sendingBackend{}
+	s := New(newTestSession(b), NewMemoryStore(), &Config{Heads: constHeads(100)})
+	tx := ethereum.PreparedTx{To: common.HexToAddress("0x1234")}
+
+	cancelled, err := s.Schedule(tx, Trigger{Block: big.NewInt(100)})
+	if err != nil {
+		t.Fatalf("Schedule: %v", err)
+	}
+	if _, err := s.Cancel(cancelled.ID); err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+	if _, err := s.Cancel(cancelled.ID); err != ErrNotScheduled {
+		t.Errorf("expected ErrNotScheduled, but got %v", err)
+	}
+
+	postponed, err := s.Schedule(tx, Trigger{Block: big.NewInt(100)})
+	if err != nil {
+		t.Fatalf("Schedule: %v", err)
+	}
+	if _, err := s.Postpone(postponed.ID, Trigger{Block: big.NewInt(101)}); err != nil {
+		t.Fatalf("Postpone: %v", err)
+	}
+
+	if _, err := s.Tick(context.Background()); err != nil {
+		t.Fatalf("Tick: %v", err)
+	}
+	if len(b.sent) != 0 {
+		t.Errorf("expected no transactions, but got %v", len(b.sent))
+	}
+
+	scheduled, err := s.Scheduled()
+	if err != nil {
+		t.Fatalf("Scheduled: %v", err)
+	}
+	if len(scheduled) != 1 || scheduled[0].ID != postponed.ID || scheduled[0].AtBlock.ToInt().Int64() != 101 {
+		t.Errorf("expected postponed job to be scheduled, but got %+v", scheduled)
+	}
+}
+
+func TestScheduler_Schedule_NoTrigger(t *testing.T) {
+	s := New(newTestSession(&sendingBackend{}), NewMemoryStore(), nil)
+	if _, err := s.Schedule(ethereum.PreparedTx{}, Trigger{}); err != ErrNoTrigger {
+		t.Errorf("expected ErrNoTrigger, but got %v", err)
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "scheduler")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+
+	s := New(newTestSession(&sendingBackend{}), store, nil)
+	at := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	job, err := s.Schedule(ethereum.PreparedTx{To: common.HexToAddress("0x1234"), Value: big.NewInt(5), Data: []byte{1, 2}}, Trigger{Block: big.NewInt(7), Time: at})
+	if err != nil {
+		t.Fatalf("Schedule: %v", err)
+	}
+
+	// reopen the store as another process would do
+	store, err = NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	loaded, err := store.Load(job.ID)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if loaded.Value.ToInt().Int64() != 5 || len(loaded.Data) != 2 || loaded.Status != StatusScheduled ||
+		loaded.AtBlock.ToInt().Int64() != 7 || !loaded.AtTime.Equal(at) {
+		t.Errorf("expected job to be loaded unchanged, but got %+v", loaded)
+	}
+
+	if _, err := store.Load("unknown"); err != ErrNotFound {
+		t.Errorf("expected ErrNotFound, but got %v", err)
+	}
+}
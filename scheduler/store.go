@@ -0,0 +1,154 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// ErrNotFound is returned when job with the given ID doesn't exist.
+var ErrNotFound = errors.New("scheduler: job not found")
+
+// Store persists jobs.
+type Store interface {
+	Save(job *Job) error
+	Load(id string) (*Job, error)
+	List() ([]*Job, error)
+}
+
+// MemoryStore keeps jobs in memory. It's useful for tests.
+type MemoryStore struct {
+	mu   sync.RWMutex
+	jobs map[string]*Job
+}
+
+// NewMemoryStore creates an instance of MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{jobs: make(map[string]*Job)}
+}
+
+// Save implements Store interface.
+func (s *MemoryStore) Save(job *Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[job.ID] = job.clone()
+	return nil
+}
+
+// Load implements Store interface.
+func (s *MemoryStore) Load(id string) (*Job, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return job.clone(), nil
+}
+
+// List implements Store interface, jobs are ordered by creation time.
+func (s *MemoryStore) List() ([]*Job, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	res := make([]*Job, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		res = append(res, job.clone())
+	}
+	sortJobs(res)
+	return res, nil
+}
+
+// FileStore keeps every job in a JSON file in the directory.
+type FileStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewFileStore creates an instance of FileStore, the directory is created if it doesn't exist.
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("scheduler: %v", err)
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+// Save implements Store interface. Job is written to a temporary file first, so it's never left half-written.
+func (s *FileStore) Save(job *Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, err := json.MarshalIndent(job, "", "  ")
+	if err != nil {
+		return fmt.Errorf("scheduler: %v", err)
+	}
+
+	path := s.path(job.ID)
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, b, 0600); err != nil {
+		return fmt.Errorf("scheduler: %v", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("scheduler: %v", err)
+	}
+	return nil
+}
+
+// Load implements Store interface.
+func (s *FileStore) Load(id string) (*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.load(s.path(id))
+}
+
+// List implements Store interface, jobs are ordered by creation time.
+func (s *FileStore) List() ([]*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	paths, err := filepath.Glob(filepath.Join(s.dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("scheduler: %v", err)
+	}
+
+	res := make([]*Job, 0, len(paths))
+	for _, path := range paths {
+		job, err := s.load(path)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, job)
+	}
+	sortJobs(res)
+	return res, nil
+}
+
+func (s *FileStore) load(path string) (*Job, error) {
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("scheduler: %v", err)
+	}
+
+	job := new(Job)
+	if err := json.Unmarshal(b, job); err != nil {
+		return nil, fmt.Errorf("scheduler: %v: %v", filepath.Base(path), err)
+	}
+	return job, nil
+}
+
+func (s *FileStore) path(id string) string {
+	return filepath.Join(s.dir, filepath.Base(id)+".json")
+}
+
+func sortJobs(jobs []*Job) {
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].CreatedAt.Before(jobs[j].CreatedAt)
+	})
+}
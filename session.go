@@ -0,0 +1,101 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/monetha/go-ethereum/gasestimator"
+)
+
+// WithValue returns a copy of the session which transfers the given amount of wei with transactions.
+func (s *Session) WithValue(value *big.Int) *Session {
+	c := s.clone()
+	c.TransactOpts.Value = copyBigInt(value)
+	return c
+}
+
+// WithGasLimit returns a copy of the session with the given gas limit of transactions (0 = estimate).
+func (s *Session) WithGasLimit(gasLimit uint64) *Session {
+	c := s.clone()
+	c.TransactOpts.GasLimit = gasLimit
+	return c
+}
+
+// WithGasPrice returns a copy of the session with the given gas price of transactions (nil = suggest).
+func (s *Session) WithGasPrice(gasPrice *big.Int) *Session {
+	c := s.clone()
+	c.TransactOpts.GasPrice = copyBigInt(gasPrice)
+	return c
+}
+
+// WithGasFeeCap returns a copy of the session with the given maximum fee per gas of transactions.
+// Session sends legacy transactions only, so the fee cap is used as gas price.
+func (s *Session) WithGasFeeCap(gasFeeCap *big.Int) *Session {
+	return s.WithGasPrice(gasFeeCap)
+}
+
+// WithGasStrategy returns a copy of the session with the gas price decided by GasStrategy of Eth for the given
+// urgency and sending attempt (e.g. attempt 1 to replace the transaction which is stuck).
+func (s *Session) WithGasStrategy(ctx context.Context, urgency gasestimator.Urgency, attempt int) (*Session, error) {
+	if s.GasStrategy == nil {
+		return nil, errors.New("gas strategy is not set")
+	}
+	gasPrice, err := s.GasStrategy.GasPrice(ctx, urgency, attempt)
+	if err != nil {
+		return nil, err
+	}
+	return s.WithGasPrice(gasPrice), nil
+}
+
+// WithNonce returns a copy of the session with the given nonce of transactions (nil = pending nonce).
+func (s *Session) WithNonce(nonce *big.Int) *Session {
+	c := s.clone()
+	c.TransactOpts.Nonce = copyBigInt(nonce)
+	return c
+}
+
+// WithContext returns a copy of the session which uses the given context for transactions. It's the default
+// context of the session (see Context), e.g. with a deadline of all calls made by the session.
+func (s *Session) WithContext(ctx context.Context) *Session {
+	c := s.clone()
+	c.TransactOpts.Context = ctx
+	return c
+}
+
+// Context returns the default context of the session set with WithContext, or context.Background()
+// when it's not set.
+func (s *Session) Context() context.Context {
+	return ensureContext(s.TransactOpts.Context)
+}
+
+// ctxOrDefault returns ctx, or the default context of the session when ctx is nil.
+// Nil ctx is deprecated and tolerated for compatibility only.
+func (s *Session) ctxOrDefault(ctx context.Context) context.Context {
+	if ctx == nil {
+		return s.Context()
+	}
+	return ctx
+}
+
+// clone returns a copy of the session, the copy doesn't share big integers of TransactOpts with the session.
+func (s *Session) clone() *Session {
+	c := *s
+	c.TransactOpts = copyTransactOpts(s.TransactOpts)
+	return &c
+}
+
+func copyTransactOpts(opts bind.TransactOpts) bind.TransactOpts {
+	opts.Nonce = copyBigInt(opts.Nonce)
+	opts.Value = copyBigInt(opts.Value)
+	opts.GasPrice = copyBigInt(opts.GasPrice)
+	return opts
+}
+
+func copyBigInt(v *big.Int) *big.Int {
+	if v == nil {
+		return nil
+	}
+	return new(big.Int).Set(v)
+}
@@ -0,0 +1,171 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/monetha/go-ethereum/backend"
+	"github.com/monetha/go-ethereum/gasestimator"
+)
+
+func TestSession_With(t *testing.T) {
+	s := &Session{
+		Eth:          &Eth{},
+		TransactOpts: bind.TransactOpts{GasPrice: big.NewInt(1), GasLimit: 21000},
+	}
+
+	value := big.NewInt(100)
+	s1 := s.WithValue(value).WithGasLimit(50000).WithGasFeeCap(big.NewInt(2))
+	value.SetInt64(200)
+
+	if s.TransactOpts.Value != nil || s.TransactOpts.GasLimit != 21000 || s.TransactOpts.GasPrice.Int64() != 1 {
+		t.Errorf("expected original session to be unchanged, but got %+v", s.TransactOpts)
+	}
+	if s1.TransactOpts.Value.Int64() != 100 {
+		t.Errorf("expected value 100, but got %v", s1.TransactOpts.Value)
+	}
+	if s1.TransactOpts.GasLimit != 50000 {
+		t.Errorf("expected gas limit 50000, but got %v", s1.TransactOpts.GasLimit)
+	}
+	if s1.TransactOpts.GasPrice.Int64() != 2 {
+		t.Errorf("expected gas price 2, but got %v", s1.TransactOpts.GasPrice)
+	}
+	if s1.Eth != s.Eth {
+		t.Errorf("expected sessions to share Eth")
+	}
+
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, 1)
+	s2 := s.WithContext(ctx).WithNonce(big.NewInt(7))
+	if s2.TransactOpts.Context != ctx {
+		t.Errorf("expected context to be set")
+	}
+	if s2.TransactOpts.Nonce.Int64() != 7 {
+		t.Errorf("expected nonce 7, but got %v", s2.TransactOpts.Nonce)
+	}
+	if s.TransactOpts.Context != nil || s.TransactOpts.Nonce != nil {
+		t.Errorf("expected original session to be unchanged, but got %+v", s.TransactOpts)
+	}
+}
+
+type ctxBackend struct {
+	backend.Backend
+	ctx context.Context
+}
+
+func (b *ctxBackend) EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error) {
+	b.ctx = ctx
+	return 21000, nil
+}
+
+func (b *ctxBackend) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return 0, errors.New("nonce is not available")
+}
+
+func TestSession_Context(t *testing.T) {
+	b := &ctxBackend{}
+	s := &Session{Eth: NewEth(b)}
+
+	if s.Context() == nil {
+		t.Fatalf("expected default context, but got nil")
+	}
+
+	type ctxKey struct{}
+	sessionCtx := context.WithValue(context.Background(), ctxKey{}, "session")
+	callCtx := context.WithValue(context.Background(), ctxKey{}, "call")
+	s = s.WithContext(sessionCtx)
+
+	if _, err := s.SuggestGasLimit(callCtx, common.Address{}, nil); err != nil {
+		t.Fatalf("SuggestGasLimit: %v", err)
+	}
+	if b.ctx != callCtx {
+		t.Errorf("expected context of the call to be used")
+	}
+
+	if _, err := s.SuggestGasLimit(nil, common.Address{}, nil); err != nil {
+		t.Fatalf("SuggestGasLimit: %v", err)
+	}
+	if b.ctx != sessionCtx {
+		t.Errorf("expected default context of the session to be used")
+	}
+}
+
+func TestSession_WithGasStrategy(t *testing.T) {
+	s := &Session{Eth: &Eth{}}
+	if _, err := s.WithGasStrategy(context.Background(), gasestimator.UrgencyHigh, 0); err == nil {
+		t.Errorf("expected error when gas strategy isn't set")
+	}
+
+	s.GasStrategy = gasestimator.BumpOnRetryStrategy(gasestimator.FixedStrategy(big.NewInt(100)), 10)
+	s1, err := s.WithGasStrategy(context.Background(), gasestimator.UrgencyHigh, 1)
+	if err != nil {
+		t.Fatalf("WithGasStrategy: %v", err)
+	}
+	if s1.TransactOpts.GasPrice.Int64() != 110 {
+		t.Errorf("expected gas price 110, but got %v", s1.TransactOpts.GasPrice)
+	}
+	if s.TransactOpts.GasPrice != nil {
+		t.Errorf("expected original session to be unchanged, but got %v", s.TransactOpts.GasPrice)
+	}
+}
+
+func TestSession_clone_NoAliasing(t *testing.T) {
+	s := &Session{
+		Eth: &Eth{},
+		TransactOpts: bind.TransactOpts{
+			Nonce:    big.NewInt(1),
+			Value:    big.NewInt(2),
+			GasPrice: big.NewInt(3),
+		},
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(opts *bind.TransactOpts)
+	}{
+		{"nonce", func(opts *bind.TransactOpts) { opts.Nonce.SetInt64(10) }},
+		{"value", func(opts *bind.TransactOpts) { opts.Value.SetInt64(20) }},
+		{"gas price", func(opts *bind.TransactOpts) { opts.GasPrice.SetInt64(30) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("mutating %v of clone", tt.name), func(t *testing.T) {
+			c := s.WithGasLimit(1)
+			tt.mutate(&c.TransactOpts)
+			if s.TransactOpts.Nonce.Int64() != 1 || s.TransactOpts.Value.Int64() != 2 || s.TransactOpts.GasPrice.Int64() != 3 {
+				t.Errorf("expected original session to be unchanged, but got %+v", s.TransactOpts)
+			}
+		})
+		t.Run(fmt.Sprintf("mutating %v of original", tt.name), func(t *testing.T) {
+			orig := s.clone()
+			c := orig.WithGasLimit(1)
+			tt.mutate(&orig.TransactOpts)
+			if c.TransactOpts.Nonce.Int64() != 1 || c.TransactOpts.Value.Int64() != 2 || c.TransactOpts.GasPrice.Int64() != 3 {
+				t.Errorf("expected cloned session to be unchanged, but got %+v", c.TransactOpts)
+			}
+		})
+	}
+}
+
+func TestEth_NewSession_NoGasPriceAliasing(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	e := &Eth{SuggestedGasPrice: big.NewInt(5)}
+
+	s := e.NewSession(key)
+	s.TransactOpts.GasPrice.SetInt64(6)
+	if e.SuggestedGasPrice.Int64() != 5 {
+		t.Errorf("expected suggested gas price to be unchanged, but got %v", e.SuggestedGasPrice)
+	}
+
+	e.SuggestedGasPrice.SetInt64(7)
+	if s.TransactOpts.GasPrice.Int64() != 6 {
+		t.Errorf("expected session gas price to be unchanged, but got %v", s.TransactOpts.GasPrice)
+	}
+}
@@ -0,0 +1,179 @@
+// Package sig converts secp256k1 signatures between the forms expected by different consumers: 65 bytes
+// [R || S || V] with V of 0/1 (go-ethereum crypto) or 27/28 (ecrecover in contracts, wallets), (v, r, s)
+// integers with V of 27/28 or EIP-155 V of transactions, and 64 bytes EIP-2098 compact signatures.
+package sig
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	// ErrInvalidLength is returned when the signature is neither 65 bytes nor 64 bytes compact signature.
+	ErrInvalidLength = errors.New("sig: invalid signature length")
+	// ErrInvalidV is returned when V isn't 0/1, 27/28 or EIP-155 V.
+	ErrInvalidV = errors.New("sig: invalid signature V")
+	// ErrHighS is returned when S is in the upper half of the curve order, which isn't valid for compact
+	// signatures and is rejected by contracts following EIP-2.
+	ErrHighS = errors.New("sig: signature S is in the upper half of the curve order")
+)
+
+var (
+	secp256k1N, _  = new(big.Int).SetString("fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141", 16)
+	secp256k1HalfN = new(big.Int).Rsh(secp256k1N, 1)
+)
+
+// Signature is the secp256k1 signature with the recovery id (y parity) of 0 or 1.
+type Signature struct {
+	R          [32]byte
+	S          [32]byte
+	RecoveryID byte
+}
+
+// Parse parses 65 bytes [R || S || V] signature with any V accepted by NormalizeV,
+// or 64 bytes EIP-2098 compact signature.
+func Parse(sig []byte) (*Signature, error) {
+	switch len(sig) {
+	case 64:
+		return FromCompact(sig)
+	case 65:
+		recID, err := NormalizeV(new(big.Int).SetUint64(uint64(sig[64])))
+		if err != nil {
+			return nil, err
+		}
+		s := &Signature{RecoveryID: recID}
+		copy(s.R[:], sig[:32])
+		copy(s.S[:], sig[32:64])
+		return s, nil
+	default:
+		return nil, fmt.Errorf("%v: %v", ErrInvalidLength, len(sig))
+	}
+}
+
+// FromVRS creates the signature from integers, V can be any value accepted by NormalizeV.
+func FromVRS(v, r, s *big.Int) (*Signature, error) {
+	if v == nil || r == nil || s == nil {
+		return nil, errors.New("sig: V, R and S must be set")
+	}
+	if r.Sign() < 0 || r.BitLen() > 256 || s.Sign() < 0 || s.BitLen() > 256 {
+		return nil, errors.New("sig: R and S must be 256-bit unsigned integers")
+	}
+
+	recID, err := NormalizeV(v)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Signature{RecoveryID: recID}
+	copy(res.R[:], common.LeftPadBytes(r.Bytes(), 32))
+	copy(res.S[:], common.LeftPadBytes(s.Bytes(), 32))
+	return res, nil
+}
+
+// FromCompact creates the signature from EIP-2098 compact signature [R || yParity << 255 | S].
+func FromCompact(compact []byte) (*Signature, error) {
+	if len(compact) != 64 {
+		return nil, fmt.Errorf("%v: %v", ErrInvalidLength, len(compact))
+	}
+
+	s := &Signature{RecoveryID: compact[32] >> 7}
+	copy(s.R[:], compact[:32])
+	copy(s.S[:], compact[32:])
+	s.S[0] &= 0x7f
+	return s, nil
+}
+
+// NormalizeV returns the recovery id (0 or 1) of V which is 0/1, 27/28 or EIP-155 V (chainID * 2 + 35/36).
+func NormalizeV(v *big.Int) (byte, error) {
+	switch {
+	case v.Sign() < 0:
+		return 0, fmt.Errorf("%v: %v", ErrInvalidV, v)
+	case v.Cmp(big.NewInt(2)) < 0:
+		return byte(v.Uint64()), nil
+	case v.Cmp(big.NewInt(27)) == 0 || v.Cmp(big.NewInt(28)) == 0:
+		return byte(v.Uint64() - 27), nil
+	case v.Cmp(big.NewInt(35)) >= 0:
+		return byte(new(big.Int).Sub(v, big.NewInt(35)).Bit(0)), nil
+	default:
+		return 0, fmt.Errorf("%v: %v", ErrInvalidV, v)
+	}
+}
+
+// ChainID returns the chain ID of EIP-155 V, or nil when V isn't EIP-155 V.
+func ChainID(v *big.Int) *big.Int {
+	if v == nil || v.Cmp(big.NewInt(35)) < 0 {
+		return nil
+	}
+	return new(big.Int).Rsh(new(big.Int).Sub(v, big.NewInt(35)), 1)
+}
+
+// Bytes returns 65 bytes [R || S || V] signature with V of 0 or 1, as expected by go-ethereum crypto package.
+func (s *Signature) Bytes() []byte {
+	res := make([]byte, 65)
+	copy(res, s.R[:])
+	copy(res[32:], s.S[:])
+	res[64] = s.RecoveryID
+	return res
+}
+
+// EthereumBytes returns 65 bytes [R || S || V] signature with V of 27 or 28, as expected by ecrecover
+// in contracts and produced by wallets.
+func (s *Signature) EthereumBytes() []byte {
+	res := s.Bytes()
+	res[64] += 27
+	return res
+}
+
+// VRS returns V (27 or 28), R and S.
+func (s *Signature) VRS() (v, r, ss *big.Int) {
+	return big.NewInt(int64(s.RecoveryID) + 27), new(big.Int).SetBytes(s.R[:]), new(big.Int).SetBytes(s.S[:])
+}
+
+// EIP155V returns V of the transaction signed for the chain (chainID * 2 + 35 + recovery id).
+func (s *Signature) EIP155V(chainID *big.Int) *big.Int {
+	v := new(big.Int).Lsh(chainID, 1)
+	return v.Add(v, big.NewInt(35+int64(s.RecoveryID)))
+}
+
+// Compact returns 64 bytes EIP-2098 compact signature [R || yParity << 255 | S]. It returns ErrHighS
+// when S is in the upper half of the curve order.
+func (s *Signature) Compact() ([]byte, error) {
+	if s.IsHighS() {
+		return nil, ErrHighS
+	}
+
+	res := make([]byte, 64)
+	copy(res, s.R[:])
+	copy(res[32:], s.S[:])
+	res[32] |= s.RecoveryID << 7
+	return res, nil
+}
+
+// IsHighS returns true when S is in the upper half of the curve order.
+func (s *Signature) IsHighS() bool {
+	return new(big.Int).SetBytes(s.S[:]).Cmp(secp256k1HalfN) > 0
+}
+
+// Normalize returns the equivalent signature with S in the lower half of the curve order (see EIP-2).
+func (s *Signature) Normalize() *Signature {
+	res := *s
+	if s.IsHighS() {
+		lowS := new(big.Int).Sub(secp256k1N, new(big.Int).SetBytes(s.S[:]))
+		copy(res.S[:], common.LeftPadBytes(lowS.Bytes(), 32))
+		res.RecoveryID ^= 1
+	}
+	return &res
+}
+
+// Recover returns the address of the account which signed the hash.
+func (s *Signature) Recover(hash common.Hash) (common.Address, error) {
+	pub, err := crypto.SigToPub(hash[:], s.Bytes())
+	if err != nil {
+		return common.Address{}, fmt.Errorf("sig: recovering signer: %v", err)
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
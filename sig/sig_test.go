@@ -0,0 +1,149 @@
+package sig
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// EIP-2098 test vectors
+var (
+	vectorR         = hexutil.MustDecode("0x68a020a209d3d56c46f38cc50a33f704f4a9a10a59377f8dd762ac66910e9b90")
+	vectorS         = hexutil.MustDecode("0x7e865ad05c4035ab5792787d4a0297a43617ae897930a6fe4d822b8faea52064")
+	vectorCompact   = hexutil.MustDecode("0x68a020a209d3d56c46f38cc50a33f704f4a9a10a59377f8dd762ac66910e9b907e865ad05c4035ab5792787d4a0297a43617ae897930a6fe4d822b8faea52064")
+	vector2R        = hexutil.MustDecode("0x9328da16089fcba9bececa81663203989f2df5fe1faa6291a45381c81bd17f76")
+	vector2S        = hexutil.MustDecode("0x139c6d6b623b42da56557e5e734a43dc83345ddfadec52cbe24d0cc64f550793")
+	vector2Compact  = hexutil.MustDecode("0x9328da16089fcba9bececa81663203989f2df5fe1faa6291a45381c81bd17f76939c6d6b623b42da56557e5e734a43dc83345ddfadec52cbe24d0cc64f550793")
+	vectorSignature = append(append(append([]byte{}, vectorR...), vectorS...), 27)
+)
+
+func TestCompact(t *testing.T) {
+	tests := []struct {
+		r, s    []byte
+		v       byte
+		compact []byte
+	}{
+		{vectorR, vectorS, 27, vectorCompact},
+		{vector2R, vector2S, 28, vector2Compact},
+	}
+
+	for _, tt := range tests {
+		s, err := Parse(append(append(append([]byte{}, tt.r...), tt.s...), tt.v))
+		if err != nil {
+			t.Fatalf("Parse() error = %v", err)
+		}
+
+		compact, err := s.Compact()
+		if err != nil {
+			t.Fatalf("Compact() error = %v", err)
+		}
+		if !bytes.Equal(compact, tt.compact) {
+			t.Errorf("expected compact %x, but got %x", tt.compact, compact)
+		}
+
+		fromCompact, err := Parse(tt.compact)
+		if err != nil {
+			t.Fatalf("Parse() error = %v", err)
+		}
+		if *fromCompact != *s {
+			t.Errorf("expected %+v, but got %+v", s, fromCompact)
+		}
+	}
+}
+
+func TestNormalizeV(t *testing.T) {
+	tests := []struct {
+		v     int64
+		recID byte
+		err   bool
+	}{
+		{v: 0, recID: 0},
+		{v: 1, recID: 1},
+		{v: 27, recID: 0},
+		{v: 28, recID: 1},
+		{v: 37, recID: 0},  // chain 1
+		{v: 38, recID: 1},  // chain 1
+		{v: 309, recID: 0}, // chain 137
+		{v: 310, recID: 1}, // chain 137
+		{v: 2, err: true},
+		{v: 29, err: true},
+		{v: 34, err: true},
+		{v: -1, err: true},
+	}
+
+	for _, tt := range tests {
+		recID, err := NormalizeV(big.NewInt(tt.v))
+		if tt.err {
+			if err == nil {
+				t.Errorf("NormalizeV(%v): expected error, but got %v", tt.v, recID)
+			}
+			continue
+		}
+		if err != nil || recID != tt.recID {
+			t.Errorf("NormalizeV(%v): expected %v, but got %v (%v)", tt.v, tt.recID, recID, err)
+		}
+	}
+}
+
+func TestSignature_Conversions(t *testing.T) {
+	s, err := Parse(vectorSignature)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if b := s.Bytes(); b[64] != 0 || !bytes.Equal(b[:64], vectorSignature[:64]) {
+		t.Errorf("expected V 0, but got %x", b)
+	}
+	if b := s.EthereumBytes(); !bytes.Equal(b, vectorSignature) {
+		t.Errorf("expected %x, but got %x", vectorSignature, b)
+	}
+
+	v, r, ss := s.VRS()
+	if v.Int64() != 27 || !bytes.Equal(r.Bytes(), vectorR) || !bytes.Equal(ss.Bytes(), vectorS) {
+		t.Errorf("unexpected V, R, S: %v %x %x", v, r, ss)
+	}
+
+	v155 := s.EIP155V(big.NewInt(137))
+	if v155.Int64() != 309 {
+		t.Errorf("expected EIP-155 V 309, but got %v", v155)
+	}
+	if chainID := ChainID(v155); chainID == nil || chainID.Int64() != 137 {
+		t.Errorf("expected chain ID 137, but got %v", chainID)
+	}
+	if chainID := ChainID(v); chainID != nil {
+		t.Errorf("expected no chain ID for V 27, but got %v", chainID)
+	}
+
+	fromVRS, err := FromVRS(v155, r, ss)
+	if err != nil {
+		t.Fatalf("FromVRS() error = %v", err)
+	}
+	if *fromVRS != *s {
+		t.Errorf("expected %+v, but got %+v", s, fromVRS)
+	}
+
+	if _, err := Parse(vectorSignature[:63]); err == nil {
+		t.Error("expected error for invalid length, but got nil")
+	}
+}
+
+func TestSignature_Normalize(t *testing.T) {
+	s, _ := Parse(vectorSignature)
+
+	high := *s
+	highS := new(big.Int).Sub(secp256k1N, new(big.Int).SetBytes(s.S[:]))
+	copy(high.S[:], highS.Bytes())
+	high.RecoveryID ^= 1
+
+	if !high.IsHighS() {
+		t.Fatal("expected high S")
+	}
+	if _, err := high.Compact(); err != ErrHighS {
+		t.Errorf("expected error %v, but got %v", ErrHighS, err)
+	}
+	if low := high.Normalize(); *low != *s {
+		t.Errorf("expected %+v, but got %+v", s, low)
+	}
+}
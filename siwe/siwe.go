@@ -0,0 +1,276 @@
+// Package siwe generates, parses and verifies Sign-In with Ethereum messages (EIP-4361), so that services
+// can authenticate wallet users by the signature of the message.
+package siwe
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/monetha/go-ethereum"
+)
+
+var (
+	// ErrInvalidSignature is returned by Verify when the message isn't signed by the address of the message.
+	ErrInvalidSignature = errors.New("siwe: invalid signature")
+	// ErrDomainMismatch is returned by Verify when the domain of the message differs from the expected one.
+	ErrDomainMismatch = errors.New("siwe: domain mismatch")
+	// ErrNonceMismatch is returned by Verify when the nonce of the message differs from the expected one.
+	ErrNonceMismatch = errors.New("siwe: nonce mismatch")
+	// ErrChainIDMismatch is returned by Verify when the chain ID of the message differs from the expected one.
+	ErrChainIDMismatch = errors.New("siwe: chain ID mismatch")
+	// ErrNoExpectedValues is returned by Verify when the expected domain, nonce or chain ID isn't set.
+	ErrNoExpectedValues = errors.New("siwe: expected domain, nonce and chain ID must be set")
+	// ErrExpired is returned by Verify when the message is expired.
+	ErrExpired = errors.New("siwe: message expired")
+	// ErrNotYetValid is returned by Verify when the message isn't valid yet.
+	ErrNotYetValid = errors.New("siwe: message not yet valid")
+)
+
+const (
+	header    = " wants you to sign in with your Ethereum account:"
+	nonceChar = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+)
+
+// Message is the EIP-4361 message.
+type Message struct {
+	Scheme         string // optional, e.g. "https"
+	Domain         string
+	Address        common.Address
+	Statement      string // optional, must not contain new lines
+	URI            string
+	Version        string // "1" if empty
+	ChainID        uint64
+	Nonce          string
+	IssuedAt       time.Time
+	ExpirationTime *time.Time // optional
+	NotBefore      *time.Time // optional
+	RequestID      string     // optional
+	Resources      []string   // optional
+}
+
+// NewNonce returns random alphanumeric nonce of 16 characters.
+func NewNonce() (string, error) {
+	b := make([]byte, 16)
+	max := big.NewInt(int64(len(nonceChar)))
+	for i := range b {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", fmt.Errorf("siwe: generating nonce: %v", err)
+		}
+		b[i] = nonceChar[n.Int64()]
+	}
+	return string(b), nil
+}
+
+// String returns the message in the format of EIP-4361, which is signed by the wallet.
+func (m *Message) String() string {
+	var sb strings.Builder
+
+	if m.Scheme != "" {
+		sb.WriteString(m.Scheme + "://")
+	}
+	sb.WriteString(m.Domain + header + "\n")
+	sb.WriteString(m.Address.Hex() + "\n\n")
+	if m.Statement != "" {
+		sb.WriteString(m.Statement + "\n")
+	}
+	sb.WriteString("\n")
+
+	version := m.Version
+	if version == "" {
+		version = "1"
+	}
+	sb.WriteString("URI: " + m.URI + "\n")
+	sb.WriteString("Version: " + version + "\n")
+	sb.WriteString("Chain ID: " + strconv.FormatUint(m.ChainID, 10) + "\n")
+	sb.WriteString("Nonce: " + m.Nonce + "\n")
+	sb.WriteString("Issued At: " + formatTime(m.IssuedAt))
+	if m.ExpirationTime != nil {
+		sb.WriteString("\nExpiration Time: " + formatTime(*m.ExpirationTime))
+	}
+	if m.NotBefore != nil {
+		sb.WriteString("\nNot Before: " + formatTime(*m.NotBefore))
+	}
+	if m.RequestID != "" {
+		sb.WriteString("\nRequest ID: " + m.RequestID)
+	}
+	if len(m.Resources) > 0 {
+		sb.WriteString("\nResources:")
+		for _, r := range m.Resources {
+			sb.WriteString("\n- " + r)
+		}
+	}
+
+	return sb.String()
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// Sign signs the message with the key like personal_sign of wallets.
+func (m *Message) Sign(key *ethereum.Key) ([]byte, error) {
+	return key.SignMessage([]byte(m.String()))
+}
+
+// Parse parses the message in the format of EIP-4361.
+func Parse(s string) (*Message, error) {
+	lines := strings.Split(s, "\n")
+	next := func() (string, bool) {
+		if len(lines) == 0 {
+			return "", false
+		}
+		l := lines[0]
+		lines = lines[1:]
+		return l, true
+	}
+
+	m := new(Message)
+
+	l, _ := next()
+	if !strings.HasSuffix(l, header) {
+		return nil, errors.New("siwe: invalid message header")
+	}
+	m.Domain = strings.TrimSuffix(l, header)
+	if i := strings.Index(m.Domain, "://"); i >= 0 {
+		m.Scheme, m.Domain = m.Domain[:i], m.Domain[i+3:]
+	}
+	if m.Domain == "" {
+		return nil, errors.New("siwe: empty domain")
+	}
+
+	l, _ = next()
+	if !common.IsHexAddress(l) || common.HexToAddress(l).Hex() != l {
+		return nil, fmt.Errorf("siwe: address %q is not EIP-55 checksummed address", l)
+	}
+	m.Address = common.HexToAddress(l)
+
+	if l, _ = next(); l != "" {
+		return nil, errors.New("siwe: expected empty line after address")
+	}
+	if l, _ = next(); l != "" {
+		m.Statement = l
+		if l, _ = next(); l != "" {
+			return nil, errors.New("siwe: expected empty line after statement")
+		}
+	}
+
+	fields := []struct {
+		tag      string
+		optional bool
+		parse    func(v string) error
+	}{
+		{"URI", false, func(v string) error { m.URI = v; return nil }},
+		{"Version", false, func(v string) error {
+			if v != "1" {
+				return fmt.Errorf("unsupported version %q", v)
+			}
+			m.Version = v
+			return nil
+		}},
+		{"Chain ID", false, func(v string) (err error) { m.ChainID, err = strconv.ParseUint(v, 10, 64); return }},
+		{"Nonce", false, func(v string) error {
+			if len(v) < 8 {
+				return errors.New("nonce must be at least 8 characters")
+			}
+			m.Nonce = v
+			return nil
+		}},
+		{"Issued At", false, func(v string) (err error) { m.IssuedAt, err = time.Parse(time.RFC3339, v); return }},
+		{"Expiration Time", true, func(v string) error {
+			t, err := time.Parse(time.RFC3339, v)
+			m.ExpirationTime = &t
+			return err
+		}},
+		{"Not Before", true, func(v string) error {
+			t, err := time.Parse(time.RFC3339, v)
+			m.NotBefore = &t
+			return err
+		}},
+		{"Request ID", true, func(v string) error { m.RequestID = v; return nil }},
+	}
+
+	for _, f := range fields {
+		if len(lines) == 0 || !strings.HasPrefix(lines[0], f.tag+": ") {
+			if f.optional {
+				continue
+			}
+			return nil, fmt.Errorf("siwe: missing %v", f.tag)
+		}
+		l, _ = next()
+		if err := f.parse(strings.TrimPrefix(l, f.tag+": ")); err != nil {
+			return nil, fmt.Errorf("siwe: invalid %v: %v", f.tag, err)
+		}
+	}
+
+	if len(lines) > 0 && lines[0] == "Resources:" {
+		next()
+		for len(lines) > 0 && strings.HasPrefix(lines[0], "- ") {
+			l, _ = next()
+			m.Resources = append(m.Resources, strings.TrimPrefix(l, "- "))
+		}
+	}
+
+	if len(lines) > 0 {
+		return nil, fmt.Errorf("siwe: unexpected line %q", lines[0])
+	}
+
+	return m, nil
+}
+
+// VerifyOptions holds the expected values of the message. Domain, Nonce and ChainID are required, so that
+// the message signed for another service, session or chain can't be replayed.
+type VerifyOptions struct {
+	Domain  string
+	Nonce   string
+	ChainID uint64
+	// Time is the time of verification (current time if zero).
+	Time time.Time
+}
+
+// Verify parses the signed message and checks the signature and the fields of the message. Only signatures
+// of externally owned accounts are supported (not EIP-1271 signatures of contract wallets).
+func Verify(message string, sig []byte, opts VerifyOptions) (*Message, error) {
+	if opts.Domain == "" || opts.Nonce == "" || opts.ChainID == 0 {
+		return nil, ErrNoExpectedValues
+	}
+
+	m, err := Parse(message)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, err := ethereum.RecoverMessageSigner([]byte(message), sig)
+	if err != nil || signer != m.Address {
+		return nil, ErrInvalidSignature
+	}
+
+	if opts.Domain != m.Domain {
+		return nil, ErrDomainMismatch
+	}
+	if opts.Nonce != m.Nonce {
+		return nil, ErrNonceMismatch
+	}
+	if opts.ChainID != m.ChainID {
+		return nil, ErrChainIDMismatch
+	}
+
+	now := opts.Time
+	if now.IsZero() {
+		now = time.Now()
+	}
+	if m.ExpirationTime != nil && !now.Before(*m.ExpirationTime) {
+		return nil, ErrExpired
+	}
+	if m.NotBefore != nil && now.Before(*m.NotBefore) {
+		return nil, ErrNotYetValid
+	}
+
+	return m, nil
+}
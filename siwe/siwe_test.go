@@ -0,0 +1,155 @@
+package siwe
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/monetha/go-ethereum"
+)
+
+const exampleMessage = `service.org wants you to sign in with your Ethereum account:
+0xe5A12547fe4E872D192E3eCecb76F2Ce1aeA4946
+
+I accept the ServiceOrg Terms of Service: https://service.org/tos
+
+URI: https://service.org/login
+Version: 1
+Chain ID: 1
+Nonce: 32891757
+Issued At: 2021-09-30T16:25:24Z
+Resources:
+- ipfs://bafybeiemxf5abjwjbikoz4mc3a3dla6ual3jsgpdr4cjr3oz3evfyavhwq/
+- https://example.com/my-web2-claim.json`
+
+func TestParse(t *testing.T) {
+	m, err := Parse(exampleMessage)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	expected := &Message{
+		Domain:    "service.org",
+		Address:   common.HexToAddress("0xe5A12547fe4E872D192E3eCecb76F2Ce1aeA4946"),
+		Statement: "I accept the ServiceOrg Terms of Service: https://service.org/tos",
+		URI:       "https://service.org/login",
+		Version:   "1",
+		ChainID:   1,
+		Nonce:     "32891757",
+		IssuedAt:  time.Date(2021, 9, 30, 16, 25, 24, 0, time.UTC),
+		Resources: []string{
+			"ipfs://bafybeiemxf5abjwjbikoz4mc3a3dla6ual3jsgpdr4cjr3oz3evfyavhwq/",
+			"https://example.com/my-web2-claim.json",
+		},
+	}
+	if !reflect.DeepEqual(m, expected) {
+		t.Errorf("expected %+v, but got %+v", expected, m)
+	}
+
+	if s := m.String(); s != exampleMessage {
+		t.Errorf("expected message\n%v\nbut got\n%v", exampleMessage, s)
+	}
+}
+
+func TestParse_Optional(t *testing.T) {
+	exp := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+	m := &Message{
+		Scheme:         "https",
+		Domain:         "example.com:3000",
+		Address:        common.HexToAddress("0xe5A12547fe4E872D192E3eCecb76F2Ce1aeA4946"),
+		URI:            "https://example.com",
+		Version:        "1",
+		ChainID:        137,
+		Nonce:          "abcdefgh12",
+		IssuedAt:       time.Date(2021, 9, 30, 16, 25, 24, 0, time.UTC),
+		ExpirationTime: &exp,
+		RequestID:      "req-1",
+	}
+
+	s := m.String()
+	if !strings.Contains(s, "4946\n\n\nURI:") {
+		t.Errorf("expected two empty lines without statement, but got\n%v", s)
+	}
+
+	parsed, err := Parse(s)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if !reflect.DeepEqual(parsed, m) {
+		t.Errorf("expected %+v, but got %+v", m, parsed)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	testCases := map[string]string{
+		"header":           strings.Replace(exampleMessage, "wants you", "asks you", 1),
+		"address checksum": strings.Replace(exampleMessage, "0xe5A12547fe4E872D192E3eCecb76F2Ce1aeA4946", "0xe5a12547fe4e872d192e3ececb76f2ce1aea4946", 1),
+		"missing nonce":    strings.Replace(exampleMessage, "Nonce: 32891757\n", "", 1),
+		"short nonce":      strings.Replace(exampleMessage, "Nonce: 32891757", "Nonce: 123", 1),
+		"version":          strings.Replace(exampleMessage, "Version: 1", "Version: 2", 1),
+		"trailing line":    exampleMessage + "\nfoo",
+	}
+
+	for name, msg := range testCases {
+		if _, err := Parse(msg); err == nil {
+			t.Errorf("%v: expected error", name)
+		}
+	}
+}
+
+func TestNewNonce(t *testing.T) {
+	n1, err := NewNonce()
+	if err != nil {
+		t.Fatalf("NewNonce: %v", err)
+	}
+	n2, _ := NewNonce()
+	if len(n1) != 16 || n1 == n2 {
+		t.Errorf("expected random nonces of 16 characters, but got %v and %v", n1, n2)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	key, err := ethereum.NewKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issuedAt := time.Date(2021, 9, 30, 16, 25, 24, 0, time.UTC)
+	exp := issuedAt.Add(time.Hour)
+	m := &Message{
+		Domain:         "service.org",
+		Address:        key.Address,
+		URI:            "https://service.org/login",
+		ChainID:        1,
+		Nonce:          "32891757",
+		IssuedAt:       issuedAt,
+		ExpirationTime: &exp,
+	}
+	sig, err := m.Sign(key)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+
+	testCases := []struct {
+		name     string
+		message  string
+		opts     VerifyOptions
+		expected error
+	}{
+		{"valid", m.String(), VerifyOptions{Domain: "service.org", Nonce: "32891757", ChainID: 1, Time: issuedAt}, nil},
+		{"domain", m.String(), VerifyOptions{Domain: "evil.org", Nonce: "32891757", ChainID: 1, Time: issuedAt}, ErrDomainMismatch},
+		{"nonce", m.String(), VerifyOptions{Domain: "service.org", Nonce: "12345678", ChainID: 1, Time: issuedAt}, ErrNonceMismatch},
+		{"chain ID", m.String(), VerifyOptions{Domain: "service.org", Nonce: "32891757", ChainID: 2, Time: issuedAt}, ErrChainIDMismatch},
+		{"no nonce", m.String(), VerifyOptions{Domain: "service.org", ChainID: 1, Time: issuedAt}, ErrNoExpectedValues},
+		{"expired", m.String(), VerifyOptions{Domain: "service.org", Nonce: "32891757", ChainID: 1, Time: exp}, ErrExpired},
+		{"tampered", strings.Replace(m.String(), "Chain ID: 1", "Chain ID: 2", 1), VerifyOptions{Domain: "service.org", Nonce: "32891757", ChainID: 2, Time: issuedAt}, ErrInvalidSignature},
+	}
+
+	for _, tc := range testCases {
+		if _, err := Verify(tc.message, sig, tc.opts); err != tc.expected {
+			t.Errorf("%v: expected error %v, but got %v", tc.name, tc.expected, err)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package ethereum
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ErrNoSpendingWindow is returned by SpendingPolicy when MaxPerWindow is set, but Window isn't.
+var ErrNoSpendingWindow = errors.New("spending policy: window must be positive when limit per window is set")
+
+// SpendingLimitError is returned when the transaction exceeds the limit of SpendingPolicy.
+type SpendingLimitError struct {
+	Limit  *big.Int      // exceeded limit in wei
+	Amount *big.Int      // amount which would be spent in wei
+	Window time.Duration // window of the limit, zero for the limit per transaction
+}
+
+func (e *SpendingLimitError) Error() string {
+	if e.Window == 0 {
+		return fmt.Sprintf("spending limit exceeded: transaction costs %v wei, limit per transaction is %v wei", e.Amount, e.Limit)
+	}
+	return fmt.Sprintf("spending limit exceeded: %v wei would be spent within %v, limit is %v wei", e.Amount, e.Window, e.Limit)
+}
+
+// SpendingPolicy limits the amount of wei spent by transactions (value plus maximum fee, i.e. gas limit × gas price),
+// a safety net for hot wallets. It's safe for concurrent use and can be shared by several sessions.
+type SpendingPolicy struct {
+	// MaxPerTx is the maximum amount spent by one transaction (not limited when nil).
+	MaxPerTx *big.Int
+	// MaxPerWindow is the maximum amount spent by all transactions within Window (not limited when nil).
+	MaxPerWindow *big.Int
+	// Window is the duration of the rolling window of MaxPerWindow, it must be positive when MaxPerWindow is set.
+	Window time.Duration
+
+	mu    sync.Mutex
+	spent []spending
+	now   func() time.Time
+}
+
+type spending struct {
+	from   common.Address
+	nonce  uint64
+	at     time.Time
+	amount *big.Int
+}
+
+// NewSpendingPolicy creates an instance of SpendingPolicy.
+func NewSpendingPolicy(maxPerTx, maxPerWindow *big.Int, window time.Duration) *SpendingPolicy {
+	return &SpendingPolicy{
+		MaxPerTx:     maxPerTx,
+		MaxPerWindow: maxPerWindow,
+		Window:       window,
+	}
+}
+
+// Spend checks that the transaction sent from the account doesn't exceed the limits and records it as spent.
+// The transaction replacing the recorded one (same sender and nonce) isn't counted twice, only the higher cost
+// of them counts, as just one of them can be mined. It returns *SpendingLimitError when limits are exceeded.
+func (p *SpendingPolicy) Spend(from common.Address, tx *types.Transaction) error {
+	return p.spend(from, tx, nil)
+}
+
+// spend works like Spend, but records the transaction only when sign (if not nil) succeeds. The lock is held
+// while signing, so that concurrent transactions can't exceed the limit together.
+func (p *SpendingPolicy) spend(from common.Address, tx *types.Transaction, sign func() error) error {
+	amount := tx.Cost()
+
+	if p.MaxPerTx != nil && amount.Cmp(p.MaxPerTx) > 0 {
+		return &SpendingLimitError{Limit: p.MaxPerTx, Amount: amount}
+	}
+	if p.MaxPerWindow != nil && p.Window <= 0 {
+		return ErrNoSpendingWindow
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.timeNow()
+	p.expire(now)
+
+	replaced := -1
+	total := new(big.Int)
+	for i, s := range p.spent {
+		if s.from == from && s.nonce == tx.Nonce() {
+			replaced = i
+			if s.amount.Cmp(amount) > 0 {
+				amount = s.amount
+			}
+			continue
+		}
+		total.Add(total, s.amount)
+	}
+	total.Add(total, amount)
+	if p.MaxPerWindow != nil && total.Cmp(p.MaxPerWindow) > 0 {
+		return &SpendingLimitError{Limit: p.MaxPerWindow, Amount: total, Window: p.Window}
+	}
+
+	if sign != nil {
+		if err := sign(); err != nil {
+			return err
+		}
+	}
+
+	if replaced >= 0 {
+		p.spent = append(p.spent[:replaced], p.spent[replaced+1:]...)
+	}
+	p.spent = append(p.spent, spending{from: from, nonce: tx.Nonce(), at: now, amount: amount})
+	return nil
+}
+
+// Spent returns the amount spent within the window.
+func (p *SpendingPolicy) Spent() *big.Int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.expire(p.timeNow())
+
+	total := new(big.Int)
+	for _, s := range p.spent {
+		total.Add(total, s.amount)
+	}
+	return total
+}
+
+func (p *SpendingPolicy) expire(now time.Time) {
+	i := 0
+	for i < len(p.spent) && now.Sub(p.spent[i].at) >= p.Window {
+		i++
+	}
+	p.spent = p.spent[i:]
+}
+
+func (p *SpendingPolicy) timeNow() time.Time {
+	if p.now != nil {
+		return p.now()
+	}
+	return time.Now()
+}
+
+// WithSpendingPolicy returns a copy of the session which refuses to sign transactions exceeding limits of the policy.
+// Transactions are recorded as spent when they are signed, so a transaction which failed to be sent still counts,
+// while replacements of the transaction (e.g. by SendWithReplacement) count once.
+func (s *Session) WithSpendingPolicy(p *SpendingPolicy) *Session {
+	c := s.clone()
+	signer := c.TransactOpts.Signer
+	c.TransactOpts.Signer = func(txSigner types.Signer, address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+		var signed *types.Transaction
+		err := p.spend(address, tx, func() (err error) {
+			signed, err = signer(txSigner, address, tx)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		return signed, nil
+	}
+	return c
+}
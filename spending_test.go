@@ -0,0 +1,132 @@
+package ethereum
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestSpendingPolicy_Spend(t *testing.T) {
+	now := time.Unix(1000, 0)
+	p := NewSpendingPolicy(big.NewInt(500), big.NewInt(1000), time.Hour)
+	p.now = func() time.Time { return now }
+
+	nonce := uint64(0)
+	tx := func(value int64) *types.Transaction {
+		nonce++
+		// fee is 21000 × 0 = 0, so cost equals value
+		return types.NewTransaction(nonce, common.Address{}, big.NewInt(value), 21000, new(big.Int), nil)
+	}
+
+	steps := []struct {
+		advance time.Duration
+		value   int64
+		window  time.Duration // window of expected limit error, -1 when no error expected
+	}{
+		{0, 400, -1},
+		{0, 600, 0},                        // exceeds limit per transaction
+		{0, 400, -1},                       // 800 spent
+		{10 * time.Minute, 300, time.Hour}, // 1100 would be spent within window
+		{time.Hour, 300, -1},               // first transaction expired, 700 spent
+	}
+
+	for i, step := range steps {
+		now = now.Add(step.advance)
+		err := p.Spend(common.Address{}, tx(step.value))
+		if step.window < 0 {
+			if err != nil {
+				t.Errorf("step %v: expected no error, but got %v", i, err)
+			}
+			continue
+		}
+		le, ok := err.(*SpendingLimitError)
+		if !ok {
+			t.Errorf("step %v: expected *SpendingLimitError, but got %v", i, err)
+			continue
+		}
+		if le.Window != step.window {
+			t.Errorf("step %v: expected window %v, but got %v", i, step.window, le.Window)
+		}
+	}
+
+	if spent := p.Spent(); spent.Int64() != 300 {
+		t.Errorf("expected 300 spent within window, but got %v", spent)
+	}
+}
+
+func TestSpendingPolicy_Spend_Replacement(t *testing.T) {
+	p := NewSpendingPolicy(nil, big.NewInt(1000), time.Hour)
+	from := common.HexToAddress("0x1")
+
+	for _, value := range []int64{600, 700, 650} {
+		if err := p.Spend(from, types.NewTransaction(1, common.Address{}, big.NewInt(value), 21000, new(big.Int), nil)); err != nil {
+			t.Fatalf("expected replacement of %v wei to be allowed, but got %v", value, err)
+		}
+	}
+	if spent := p.Spent(); spent.Int64() != 700 {
+		t.Errorf("expected replacements to count once, but got %v spent", spent)
+	}
+
+	err := p.Spend(common.HexToAddress("0x2"), types.NewTransaction(1, common.Address{}, big.NewInt(400), 21000, new(big.Int), nil))
+	if _, ok := err.(*SpendingLimitError); !ok {
+		t.Errorf("expected *SpendingLimitError for another sender, but got %v", err)
+	}
+}
+
+func TestSpendingPolicy_Spend_NoWindow(t *testing.T) {
+	p := NewSpendingPolicy(nil, big.NewInt(1000), 0)
+	if err := p.Spend(common.Address{}, types.NewTransaction(0, common.Address{}, big.NewInt(1), 21000, new(big.Int), nil)); err != ErrNoSpendingWindow {
+		t.Errorf("expected error %v, but got %v", ErrNoSpendingWindow, err)
+	}
+}
+
+func TestSession_WithSpendingPolicy(t *testing.T) {
+	signed := 0
+	s := &Session{
+		Eth: &Eth{},
+		TransactOpts: bind.TransactOpts{
+			Signer: func(signer types.Signer, address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+				signed++
+				return tx, nil
+			},
+		},
+	}
+	limited := s.WithSpendingPolicy(NewSpendingPolicy(big.NewInt(100), nil, 0))
+
+	if _, err := limited.TransactOpts.Signer(nil, common.Address{}, types.NewTransaction(0, common.Address{}, big.NewInt(50), 21000, new(big.Int), nil)); err != nil {
+		t.Errorf("expected transaction within limit to be signed, but got %v", err)
+	}
+	if _, err := limited.TransactOpts.Signer(nil, common.Address{}, types.NewTransaction(0, common.Address{}, big.NewInt(150), 21000, new(big.Int), nil)); err == nil {
+		t.Errorf("expected transaction exceeding limit to be refused")
+	}
+	if signed != 1 {
+		t.Errorf("expected 1 signed transaction, but got %v", signed)
+	}
+	if _, err := s.TransactOpts.Signer(nil, common.Address{}, types.NewTransaction(0, common.Address{}, big.NewInt(150), 21000, new(big.Int), nil)); err != nil {
+		t.Errorf("expected original session to be unlimited, but got %v", err)
+	}
+}
+
+func TestSession_WithSpendingPolicy_SigningFailed(t *testing.T) {
+	s := &Session{
+		Eth: &Eth{},
+		TransactOpts: bind.TransactOpts{
+			Signer: func(signer types.Signer, address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+				return nil, errors.New("signing failed")
+			},
+		},
+	}
+	p := NewSpendingPolicy(nil, big.NewInt(1000), time.Hour)
+
+	if _, err := s.WithSpendingPolicy(p).TransactOpts.Signer(nil, common.Address{}, types.NewTransaction(0, common.Address{}, big.NewInt(50), 21000, new(big.Int), nil)); err == nil {
+		t.Errorf("expected signing error")
+	}
+	if spent := p.Spent(); spent.Sign() != 0 {
+		t.Errorf("expected transaction which failed to be signed not to count, but got %v spent", spent)
+	}
+}
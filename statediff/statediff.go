@@ -0,0 +1,149 @@
+// Package statediff compares the state of a contract at two block heights: raw storage slots and public variables
+// (or any view functions) described by the ABI. It helps to debug and audit how the state changed over time.
+// The node must keep the state of both blocks (i.e. it's an archive node unless the blocks are recent).
+package statediff
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// StateReader reads the state of contracts at the given block (e.g. ethclient.Client).
+type StateReader interface {
+	StorageAt(ctx context.Context, account common.Address, key common.Hash, blockNumber *big.Int) ([]byte, error)
+	CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error)
+}
+
+// Variable is the public variable (or view function) of the contract, Args are keys of mappings or indexes
+// of arrays.
+type Variable struct {
+	Name string
+	Args []interface{}
+}
+
+func (v Variable) String() string {
+	if len(v.Args) == 0 {
+		return v.Name
+	}
+	args := make([]string, len(v.Args))
+	for i, arg := range v.Args {
+		args[i] = fmt.Sprint(arg)
+	}
+	return v.Name + "(" + strings.Join(args, ", ") + ")"
+}
+
+// SlotDiff is the change of the storage slot.
+type SlotDiff struct {
+	Slot     common.Hash
+	From, To common.Hash
+}
+
+// VariableDiff is the change of the variable, values are decoded according to the ABI (one value per output).
+type VariableDiff struct {
+	Variable Variable
+	From, To []interface{}
+}
+
+// Report contains changes between two blocks, unchanged slots and variables aren't included.
+type Report struct {
+	Contract  common.Address
+	FromBlock *big.Int
+	ToBlock   *big.Int
+	Slots     []*SlotDiff
+	Variables []*VariableDiff
+}
+
+// Changed tells whether any of the compared slots or variables changed.
+func (r *Report) Changed() bool {
+	return len(r.Slots) > 0 || len(r.Variables) > 0
+}
+
+// Differ compares the state of contracts.
+type Differ struct {
+	r StateReader
+}
+
+// New creates an instance of Differ.
+func New(r StateReader) *Differ {
+	return &Differ{r: r}
+}
+
+// Diff reads the slots and variables of the contract (described by contractABI, which may be empty when there are
+// no variables) at both blocks and reports differences.
+func (d *Differ) Diff(ctx context.Context, contract common.Address, fromBlock, toBlock *big.Int, slots []common.Hash, contractABI abi.ABI, vars []Variable) (*Report, error) {
+	r := &Report{Contract: contract, FromBlock: fromBlock, ToBlock: toBlock}
+
+	for _, slot := range slots {
+		from, err := d.storageAt(ctx, contract, slot, fromBlock)
+		if err != nil {
+			return nil, err
+		}
+		to, err := d.storageAt(ctx, contract, slot, toBlock)
+		if err != nil {
+			return nil, err
+		}
+		if from != to {
+			r.Slots = append(r.Slots, &SlotDiff{Slot: slot, From: from, To: to})
+		}
+	}
+
+	for _, v := range vars {
+		method, ok := contractABI.Methods[v.Name]
+		if !ok {
+			return nil, fmt.Errorf("statediff: method %v not found in ABI", v.Name)
+		}
+		input, err := contractABI.Pack(v.Name, v.Args...)
+		if err != nil {
+			return nil, fmt.Errorf("statediff: %v: %v", v, err)
+		}
+
+		from, err := d.call(ctx, contract, input, fromBlock)
+		if err != nil {
+			return nil, fmt.Errorf("statediff: %v: %v", v, err)
+		}
+		to, err := d.call(ctx, contract, input, toBlock)
+		if err != nil {
+			return nil, fmt.Errorf("statediff: %v: %v", v, err)
+		}
+		if bytes.Equal(from, to) {
+			continue
+		}
+
+		diff := &VariableDiff{Variable: v}
+		if diff.From, err = method.Outputs.UnpackValues(from); err != nil {
+			return nil, fmt.Errorf("statediff: %v at block %v: %v", v, fromBlock, err)
+		}
+		if diff.To, err = method.Outputs.UnpackValues(to); err != nil {
+			return nil, fmt.Errorf("statediff: %v at block %v: %v", v, toBlock, err)
+		}
+		r.Variables = append(r.Variables, diff)
+	}
+
+	return r, nil
+}
+
+func (d *Differ) storageAt(ctx context.Context, contract common.Address, slot common.Hash, block *big.Int) (common.Hash, error) {
+	value, err := d.r.StorageAt(ctx, contract, slot, block)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("statediff: storage slot %v at block %v: %v", slot.Hex(), block, err)
+	}
+	return common.BytesToHash(value), nil
+}
+
+func (d *Differ) call(ctx context.Context, contract common.Address, input []byte, block *big.Int) ([]byte, error) {
+	return d.r.CallContract(ctx, ethereum.CallMsg{To: &contract, Data: input}, block)
+}
+
+// MappingSlot returns the storage slot of the value with the key in the mapping stored at the slot
+// (keccak256(key . slot)), the key must be padded to 32 bytes (e.g. common.BytesToHash(address.Bytes())).
+func MappingSlot(key common.Hash, slot common.Hash) common.Hash {
+	return crypto.Keccak256Hash(key.Bytes(), slot.Bytes())
+}
@@ -0,0 +1,102 @@
+package statediff
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// historyReader returns storage values and call results by block number.
+type historyReader struct {
+	storage map[int64]map[common.Hash]common.Hash
+	calls   map[int64]map[string][]byte // by input
+}
+
+func (r *historyReader) StorageAt(ctx context.Context, account common.Address, key common.Hash, blockNumber *big.Int) ([]byte, error) {
+	v := r.storage[blockNumber.Int64()][key]
+	return v.Bytes(), nil
+}
+
+func (r *historyReader) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	return r.calls[blockNumber.Int64()][string(call.Data)], nil
+}
+
+func TestDiffer_Slots(t *testing.T) {
+	slot0, slot1, slot2 := common.HexToHash("0x0"), common.HexToHash("0x1"), common.HexToHash("0x2")
+	r := &historyReader{storage: map[int64]map[common.Hash]common.Hash{
+		10: {slot0: common.HexToHash("0x5"), slot1: common.HexToHash("0x7")},
+		20: {slot0: common.HexToHash("0x6"), slot1: common.HexToHash("0x7"), slot2: common.HexToHash("0x1")},
+	}}
+
+	report, err := New(r).Diff(context.Background(), common.Address{}, big.NewInt(10), big.NewInt(20), []common.Hash{slot0, slot1, slot2}, abi.ABI{}, nil)
+	if err != nil {
+		t.Fatalf("Diff: %v", err)
+	}
+	if !report.Changed() {
+		t.Fatalf("expected changes")
+	}
+
+	expected := []SlotDiff{
+		{Slot: slot0, From: common.HexToHash("0x5"), To: common.HexToHash("0x6")},
+		{Slot: slot2, From: common.Hash{}, To: common.HexToHash("0x1")},
+	}
+	if len(report.Slots) != len(expected) {
+		t.Fatalf("expected %v changed slots, but got %v", len(expected), len(report.Slots))
+	}
+	for i, e := range expected {
+		if *report.Slots[i] != e {
+			t.Errorf("expected slot diff %+v, but got %+v", e, *report.Slots[i])
+		}
+	}
+}
+
+const testABI = `[{"constant":true,"inputs":[],"name":"owner","outputs":[{"name":"","type":"address"}],"payable":false,"stateMutability":"view","type":"function"},{"constant":true,"inputs":[{"name":"","type":"address"}],"name":"balances","outputs":[{"name":"","type":"uint256"}],"payable":false,"stateMutability":"view","type":"function"}]`
+
+func TestDiffer_Variables(t *testing.T) {
+	parsed, err := abi.JSON(strings.NewReader(testABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	holder := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	ownerInput, _ := parsed.Pack("owner")
+	balanceInput, _ := parsed.Pack("balances", holder)
+
+	word := func(v int64) []byte { return common.BigToHash(big.NewInt(v)).Bytes() }
+	r := &historyReader{calls: map[int64]map[string][]byte{
+		10: {string(ownerInput): word(1), string(balanceInput): word(100)},
+		20: {string(ownerInput): word(1), string(balanceInput): word(150)},
+	}}
+
+	vars := []Variable{{Name: "owner"}, {Name: "balances", Args: []interface{}{holder}}}
+	report, err := New(r).Diff(context.Background(), common.Address{}, big.NewInt(10), big.NewInt(20), nil, parsed, vars)
+	if err != nil {
+		t.Fatalf("Diff: %v", err)
+	}
+
+	if len(report.Variables) != 1 {
+		t.Fatalf("expected one changed variable, but got %v", len(report.Variables))
+	}
+	diff := report.Variables[0]
+	if diff.Variable.Name != "balances" {
+		t.Errorf("expected balances to change, but got %v", diff.Variable)
+	}
+	if from, to := diff.From[0].(*big.Int), diff.To[0].(*big.Int); from.Int64() != 100 || to.Int64() != 150 {
+		t.Errorf("expected balance to change from 100 to 150, but got %v to %v", from, to)
+	}
+
+	if _, err := New(r).Diff(context.Background(), common.Address{}, big.NewInt(10), big.NewInt(20), nil, parsed, []Variable{{Name: "unknown"}}); err == nil {
+		t.Errorf("expected error for unknown variable")
+	}
+}
+
+func TestVariable_String(t *testing.T) {
+	v := Variable{Name: "allowance", Args: []interface{}{1, 2}}
+	if s := v.String(); s != "allowance(1, 2)" {
+		t.Errorf("expected allowance(1, 2), but got %v", s)
+	}
+}
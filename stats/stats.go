@@ -0,0 +1,146 @@
+// Package stats computes rolling chain statistics from the blocks delivered by BlockSource.
+package stats
+
+import (
+	"context"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/monetha/go-ethereum"
+)
+
+// DefaultWindow is the number of recent blocks statistics are computed over when window isn't set.
+const DefaultWindow = 100
+
+// Snapshot holds statistics of recent blocks.
+type Snapshot struct {
+	Blocks         int           // number of blocks in the window
+	LatestBlock    *big.Int      // number of the latest block, nil when there are no blocks
+	AvgBlockTime   time.Duration // average time between blocks
+	GasUtilization float64       // gas used divided by gas limit, from 0 to 1
+	MedianGasPrice *big.Int      // median gas price of transactions, nil when there are no transactions
+	AvgTxCount     float64       // average number of transactions per block
+}
+
+type blockStats struct {
+	number    *big.Int
+	timestamp uint64
+	gasUsed   *big.Int
+	gasLimit  *big.Int
+	gasPrices []*big.Int
+}
+
+// Aggregator collects statistics of the recent blocks. It's safe for concurrent use.
+type Aggregator struct {
+	window int
+	mu     sync.RWMutex
+	blocks []blockStats // ordered by block number
+}
+
+// New creates an instance of Aggregator which keeps statistics of `window` recent blocks
+// (DefaultWindow when it's not positive).
+func New(window int) *Aggregator {
+	if window <= 0 {
+		window = DefaultWindow
+	}
+	return &Aggregator{window: window}
+}
+
+// Add adds the block to statistics. Blocks with numbers lower than or equal to the latest added block
+// (e.g. after reorganization) replace the blocks from that number on.
+func (a *Aggregator) Add(b *ethereum.Block) {
+	if b == nil || b.Number == nil {
+		return
+	}
+
+	bs := blockStats{
+		number:    b.Number,
+		timestamp: b.Timestamp,
+		gasUsed:   b.GasUsed,
+		gasLimit:  b.GasLimit,
+	}
+	for _, tx := range b.Transactions {
+		if tx != nil && tx.GasPrice != nil {
+			bs.gasPrices = append(bs.gasPrices, tx.GasPrice)
+		}
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	// drop blocks replaced by reorganization
+	i := len(a.blocks)
+	for i > 0 && a.blocks[i-1].number.Cmp(b.Number) >= 0 {
+		i--
+	}
+	a.blocks = append(a.blocks[:i], bs)
+
+	if len(a.blocks) > a.window {
+		a.blocks = append([]blockStats(nil), a.blocks[len(a.blocks)-a.window:]...)
+	}
+}
+
+// Run adds blocks from the channel (e.g. BlockSource.C) until the channel is closed or the context is done.
+func (a *Aggregator) Run(ctx context.Context, blocks <-chan *ethereum.Block) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case b, ok := <-blocks:
+			if !ok {
+				return
+			}
+			a.Add(b)
+		}
+	}
+}
+
+// Snapshot returns statistics of the recent blocks.
+func (a *Aggregator) Snapshot() Snapshot {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	s := Snapshot{Blocks: len(a.blocks)}
+	if len(a.blocks) == 0 {
+		return s
+	}
+
+	first, last := a.blocks[0], a.blocks[len(a.blocks)-1]
+	s.LatestBlock = new(big.Int).Set(last.number)
+
+	if n := new(big.Int).Sub(last.number, first.number); n.Sign() > 0 && last.timestamp > first.timestamp {
+		s.AvgBlockTime = time.Duration(last.timestamp-first.timestamp) * time.Second / time.Duration(n.Int64())
+	}
+
+	gasUsed, gasLimit := new(big.Int), new(big.Int)
+	var gasPrices []*big.Int
+	txCount := 0
+	for _, bs := range a.blocks {
+		if bs.gasUsed != nil && bs.gasLimit != nil {
+			gasUsed.Add(gasUsed, bs.gasUsed)
+			gasLimit.Add(gasLimit, bs.gasLimit)
+		}
+		gasPrices = append(gasPrices, bs.gasPrices...)
+		txCount += len(bs.gasPrices)
+	}
+
+	if gasLimit.Sign() > 0 {
+		s.GasUtilization, _ = new(big.Float).Quo(new(big.Float).SetInt(gasUsed), new(big.Float).SetInt(gasLimit)).Float64()
+	}
+	s.AvgTxCount = float64(txCount) / float64(len(a.blocks))
+
+	if len(gasPrices) > 0 {
+		sort.Slice(gasPrices, func(i, j int) bool { return gasPrices[i].Cmp(gasPrices[j]) < 0 })
+		mid := len(gasPrices) / 2
+		if len(gasPrices)%2 == 1 {
+			s.MedianGasPrice = new(big.Int).Set(gasPrices[mid])
+		} else {
+			s.MedianGasPrice = new(big.Int).Add(gasPrices[mid-1], gasPrices[mid])
+			s.MedianGasPrice.Rsh(s.MedianGasPrice, 1)
+		}
+	}
+
+	return s
+}
@@ -0,0 +1,71 @@
+package stats
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/monetha/go-ethereum"
+)
+
+func block(number int64, timestamp uint64, gasUsed int64, gasPrices ...int64) *ethereum.Block {
+	b := &ethereum.Block{
+		Number:    big.NewInt(number),
+		Timestamp: timestamp,
+		GasUsed:   big.NewInt(gasUsed),
+		GasLimit:  big.NewInt(100),
+	}
+	for _, gp := range gasPrices {
+		b.Transactions = append(b.Transactions, &ethereum.Transaction{GasPrice: big.NewInt(gp)})
+	}
+	return b
+}
+
+func TestAggregator_Snapshot(t *testing.T) {
+	a := New(3)
+
+	if s := a.Snapshot(); s.Blocks != 0 || s.LatestBlock != nil {
+		t.Errorf("expected empty snapshot, but got %+v", s)
+	}
+
+	a.Add(block(1, 100, 10, 1))
+	a.Add(block(2, 112, 50, 5, 3))
+	a.Add(block(3, 124, 30, 2))
+	a.Add(block(4, 136, 70, 4, 8, 6))
+
+	s := a.Snapshot()
+	if s.Blocks != 3 {
+		t.Errorf("expected 3 blocks in the window, but got %v", s.Blocks)
+	}
+	if s.LatestBlock.Int64() != 4 {
+		t.Errorf("expected latest block 4, but got %v", s.LatestBlock)
+	}
+	if s.AvgBlockTime != 12*time.Second {
+		t.Errorf("expected average block time 12s, but got %v", s.AvgBlockTime)
+	}
+	if s.GasUtilization != 0.5 {
+		t.Errorf("expected gas utilization 0.5, but got %v", s.GasUtilization)
+	}
+	if s.MedianGasPrice.Int64() != 4 {
+		t.Errorf("expected median gas price 4, but got %v", s.MedianGasPrice)
+	}
+	if s.AvgTxCount != 2 {
+		t.Errorf("expected 2 transactions per block, but got %v", s.AvgTxCount)
+	}
+}
+
+func TestAggregator_Reorg(t *testing.T) {
+	a := New(10)
+	a.Add(block(1, 100, 10))
+	a.Add(block(2, 112, 10))
+	a.Add(block(3, 124, 10))
+	a.Add(block(2, 114, 90))
+
+	s := a.Snapshot()
+	if s.Blocks != 2 || s.LatestBlock.Int64() != 2 {
+		t.Errorf("expected blocks 1 and 2 after reorganization, but got %v blocks up to %v", s.Blocks, s.LatestBlock)
+	}
+	if s.GasUtilization != 0.5 {
+		t.Errorf("expected gas utilization 0.5, but got %v", s.GasUtilization)
+	}
+}
@@ -0,0 +1,77 @@
+package ethereum
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ErrContractRecipient is returned by Transferer.Payout when the recipient is a contract, but contract
+// recipients are not allowed.
+var ErrContractRecipient = errors.New("recipient is a contract")
+
+const erc20TransferABI = `[{"constant":false,"inputs":[{"name":"_to","type":"address"},{"name":"_value","type":"uint256"}],"name":"transfer","outputs":[{"name":"","type":"bool"}],"payable":false,"stateMutability":"nonpayable","type":"function"},{"constant":false,"inputs":[{"name":"_spender","type":"address"},{"name":"_value","type":"uint256"}],"name":"approve","outputs":[{"name":"","type":"bool"}],"payable":false,"stateMutability":"nonpayable","type":"function"}]`
+
+var erc20ABI = MustParseABI(erc20TransferABI)
+
+// MustParseABI parses the JSON ABI and panics on error, it's meant for initializing ABIs of packages from constants.
+func MustParseABI(s string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(s))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+// TransferToken transfers `amount` of ERC-20 `token` to `to` account. `opts.Value` is ignored.
+func (t Transferer) TransferToken(opts *bind.TransactOpts, token common.Address, to common.Address, amount *big.Int) (*types.Transaction, error) {
+	input, err := erc20ABI.Pack("transfer", to, amount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack ERC-20 transfer: %v", err)
+	}
+
+	tokenOpts := *opts
+	tokenOpts.Value = nil
+	return t.Transfer(&tokenOpts, token, input)
+}
+
+// ApproveToken allows `spender` to spend `amount` of ERC-20 `token` of the sender. `opts.Value` is ignored.
+func (t Transferer) ApproveToken(opts *bind.TransactOpts, token common.Address, spender common.Address, amount *big.Int) (*types.Transaction, error) {
+	input, err := erc20ABI.Pack("approve", spender, amount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack ERC-20 approve: %v", err)
+	}
+
+	tokenOpts := *opts
+	tokenOpts.Value = nil
+	return t.Transfer(&tokenOpts, token, input)
+}
+
+// Payout transfers `amount` of ERC-20 `token` to `to` account, or ethers when `token` is nil.
+// Unless `allowContractRecipient` is set, it returns ErrContractRecipient when the recipient is a contract,
+// which may lock transferred funds.
+func (t Transferer) Payout(opts *bind.TransactOpts, token *common.Address, to common.Address, amount *big.Int, allowContractRecipient bool) (*types.Transaction, error) {
+	if !allowContractRecipient {
+		code, err := t.ContractTransactor.PendingCodeAt(ensureContext(opts.Context), to)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve recipient code: %v", err)
+		}
+		if len(code) > 0 {
+			return nil, ErrContractRecipient
+		}
+	}
+
+	if token != nil {
+		return t.TransferToken(opts, *token, to, amount)
+	}
+
+	ethOpts := *opts
+	ethOpts.Value = amount
+	return t.Transfer(&ethOpts, to, nil)
+}
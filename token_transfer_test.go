@@ -0,0 +1,91 @@
+package ethereum
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/monetha/go-ethereum/backend"
+)
+
+func TestTransferer_TransferToken(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	auth := bind.NewKeyedTransactor(key)
+
+	sim := backend.NewSimulatedBackendExtended(core.GenesisAlloc{auth.From: {Balance: ether}}, 10000000)
+	sim.Commit()
+
+	// contract which accepts any call plays the role of the token
+	e := New(backend.NewAutoCommitBackend(sim), nil)
+	rawTx := types.NewContractCreation(0, new(big.Int), 100000, big.NewInt(1), stopContractCode)
+	deployTx, err := auth.Signer(types.HomesteadSigner{}, auth.From, rawTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Backend.SendTransaction(context.TODO(), deployTx); err != nil {
+		t.Fatal(err)
+	}
+	token, err := e.WaitDeployed(context.TODO(), deployTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tr := Transferer{e.Backend}
+	to := common.HexToAddress("0x1111111111111111111111111111111111111111")
+
+	tx, err := tr.TransferToken(auth, token, to, big.NewInt(1000))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedInput := hexutil.MustDecode("0xa9059cbb" +
+		"0000000000000000000000001111111111111111111111111111111111111111" +
+		"00000000000000000000000000000000000000000000000000000000000003e8")
+	if !bytes.Equal(expectedInput, tx.Data()) {
+		t.Errorf("expected input %x, but got %x", expectedInput, tx.Data())
+	}
+	if *tx.To() != token {
+		t.Errorf("expected transaction to token %v, but got %v", token.Hex(), tx.To().Hex())
+	}
+
+	t.Run("payout to contract is refused", func(t *testing.T) {
+		if _, err := tr.Payout(auth, nil, token, big.NewInt(1), false); err != ErrContractRecipient {
+			t.Errorf("expected ErrContractRecipient, but got %v", err)
+		}
+	})
+
+	t.Run("payout to contract is allowed", func(t *testing.T) {
+		if _, err := tr.Payout(auth, nil, token, big.NewInt(1), true); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("token payout", func(t *testing.T) {
+		tx, err := tr.Payout(auth, &token, to, big.NewInt(1000), false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(expectedInput, tx.Data()) {
+			t.Errorf("expected input %x, but got %x", expectedInput, tx.Data())
+		}
+	})
+	t.Run("approve", func(t *testing.T) {
+		tx, err := tr.ApproveToken(auth, token, to, big.NewInt(1000))
+		if err != nil {
+			t.Fatal(err)
+		}
+		expectedInput := hexutil.MustDecode("0x095ea7b3" +
+			"0000000000000000000000001111111111111111111111111111111111111111" +
+			"00000000000000000000000000000000000000000000000000000000000003e8")
+		if !bytes.Equal(expectedInput, tx.Data()) {
+			t.Errorf("expected input %x, but got %x", expectedInput, tx.Data())
+		}
+	})
+}
@@ -0,0 +1,152 @@
+package ethereum
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ErrHashedTopic is returned by ParseTopic when decoding into a string or a byte slice: indexed values of dynamic
+// types are stored as Keccak-256 hashes, so they can't be decoded.
+var ErrHashedTopic = errors.New("indexed value of dynamic type is hashed")
+
+// MakeTopics converts a filter query argument list into a filter topic set, e.g. for ethereum.FilterQuery.
+// Every argument list is a set of alternatives for the topic at that position (empty list matches any value),
+// values are converted with Topic.
+func MakeTopics(query ...[]interface{}) ([][]common.Hash, error) {
+	topics := make([][]common.Hash, len(query))
+	for i, filter := range query {
+		for _, rule := range filter {
+			topic, err := Topic(rule)
+			if err != nil {
+				return nil, err
+			}
+			topics[i] = append(topics[i], topic)
+		}
+	}
+	return topics, nil
+}
+
+// Topic converts the value of an indexed event parameter to the topic: addresses, hashes, booleans and integers
+// (including *big.Int, negative values are in two's complement) are padded to 32 bytes, fixed byte arrays
+// (bytesN) are right-padded, strings and byte slices are hashed with Keccak-256.
+func Topic(rule interface{}) (common.Hash, error) {
+	var topic common.Hash
+
+	switch rule := rule.(type) {
+	case common.Hash:
+		copy(topic[:], rule[:])
+	case common.Address:
+		copy(topic[common.HashLength-common.AddressLength:], rule[:])
+	case *big.Int:
+		if rule == nil {
+			return topic, errors.New("nil *big.Int indexed value")
+		}
+		return intTopic(rule), nil
+	case bool:
+		if rule {
+			topic[common.HashLength-1] = 1
+		}
+	case int8:
+		return intTopic(big.NewInt(int64(rule))), nil
+	case int16:
+		return intTopic(big.NewInt(int64(rule))), nil
+	case int32:
+		return intTopic(big.NewInt(int64(rule))), nil
+	case int64:
+		return intTopic(big.NewInt(rule)), nil
+	case uint8:
+		return intTopic(new(big.Int).SetUint64(uint64(rule))), nil
+	case uint16:
+		return intTopic(new(big.Int).SetUint64(uint64(rule))), nil
+	case uint32:
+		return intTopic(new(big.Int).SetUint64(uint64(rule))), nil
+	case uint64:
+		return intTopic(new(big.Int).SetUint64(rule)), nil
+	case string:
+		topic = crypto.Keccak256Hash([]byte(rule))
+	case []byte:
+		topic = crypto.Keccak256Hash(rule)
+
+	default:
+		// Attempt to generate the topic from funky types
+		val := reflect.ValueOf(rule)
+
+		switch {
+		case val.Kind() == reflect.Array && val.Type().Elem().Kind() == reflect.Uint8 && val.Len() <= common.HashLength:
+			reflect.Copy(reflect.ValueOf(topic[:val.Len()]), val)
+
+		default:
+			return topic, fmt.Errorf("unsupported indexed type: %T", rule)
+		}
+	}
+	return topic, nil
+}
+
+// tt256 is 2^256, negative integers are encoded as tt256 + value.
+var tt256 = new(big.Int).Lsh(big.NewInt(1), 256)
+
+func intTopic(v *big.Int) common.Hash {
+	if v.Sign() < 0 {
+		v = new(big.Int).Add(tt256, v)
+	}
+	return common.BigToHash(v)
+}
+
+// ParseTopic decodes the topic into the value of the indexed event parameter pointed to by `out`:
+// *common.Address, *common.Hash, *bool, pointers to integers (including *big.Int, interpreted as unsigned,
+// see ParseSignedTopic) and pointers to fixed byte arrays. It returns ErrHashedTopic for *string and *[]byte.
+func ParseTopic(topic common.Hash, out interface{}) error {
+	switch out := out.(type) {
+	case *common.Hash:
+		*out = topic
+	case *common.Address:
+		*out = common.BytesToAddress(topic[common.HashLength-common.AddressLength:])
+	case *big.Int:
+		out.SetBytes(topic[:])
+	case *bool:
+		*out = topic[common.HashLength-1] == 1
+	case *int8, *int16, *int32, *int64:
+		v := ParseSignedTopic(topic)
+		rv := reflect.ValueOf(out).Elem()
+		if !v.IsInt64() || rv.OverflowInt(v.Int64()) {
+			return fmt.Errorf("topic %v overflows %v", topic.Hex(), rv.Type())
+		}
+		rv.SetInt(v.Int64())
+	case *uint8, *uint16, *uint32, *uint64:
+		v := new(big.Int).SetBytes(topic[:])
+		rv := reflect.ValueOf(out).Elem()
+		if !v.IsUint64() || rv.OverflowUint(v.Uint64()) {
+			return fmt.Errorf("topic %v overflows %v", topic.Hex(), rv.Type())
+		}
+		rv.SetUint(v.Uint64())
+	case *string, *[]byte:
+		return ErrHashedTopic
+
+	default:
+		val := reflect.ValueOf(out)
+
+		switch {
+		case val.Kind() == reflect.Ptr && val.Elem().Kind() == reflect.Array && val.Elem().Type().Elem().Kind() == reflect.Uint8 &&
+			val.Elem().Len() <= common.HashLength:
+			reflect.Copy(val.Elem(), reflect.ValueOf(topic[:val.Elem().Len()]))
+
+		default:
+			return fmt.Errorf("unsupported indexed type: %T", out)
+		}
+	}
+	return nil
+}
+
+// ParseSignedTopic decodes the topic of the indexed signed integer (two's complement).
+func ParseSignedTopic(topic common.Hash) *big.Int {
+	v := new(big.Int).SetBytes(topic[:])
+	if topic[0]&0x80 != 0 {
+		v.Sub(v, tt256)
+	}
+	return v
+}
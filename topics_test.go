@@ -0,0 +1,121 @@
+package ethereum
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestTopic(t *testing.T) {
+	addr := common.HexToAddress("0x8d12A197cB00D4747a1fe03395095ce2A5CC6819")
+
+	tests := []struct {
+		name  string
+		value interface{}
+		topic common.Hash
+	}{
+		{"address", addr, common.HexToHash("0x0000000000000000000000008d12a197cb00d4747a1fe03395095ce2a5cc6819")},
+		{"bool", true, common.HexToHash("0x01")},
+		{"uint64", uint64(1000), common.HexToHash("0x03e8")},
+		{"big.Int", big.NewInt(1000), common.HexToHash("0x03e8")},
+		{"negative int8", int8(-1), common.HexToHash("0xffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff")},
+		{"negative big.Int", big.NewInt(-2), common.HexToHash("0xfffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffe")},
+		{"bytes4", [4]byte{1, 2, 3, 4}, common.HexToHash("0x0102030400000000000000000000000000000000000000000000000000000000")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			topic, err := Topic(tt.value)
+			if err != nil {
+				t.Fatalf("Topic: %v", err)
+			}
+			if topic != tt.topic {
+				t.Errorf("expected topic %v, but got %v", tt.topic.Hex(), topic.Hex())
+			}
+		})
+	}
+
+	if _, err := Topic(1.5); err == nil {
+		t.Errorf("expected error for unsupported type, but got nil")
+	}
+}
+
+func TestMakeTopics(t *testing.T) {
+	topics, err := MakeTopics(nil, []interface{}{uint8(1), uint8(2)})
+	if err != nil {
+		t.Fatalf("MakeTopics: %v", err)
+	}
+
+	expected := [][]common.Hash{nil, {common.HexToHash("0x01"), common.HexToHash("0x02")}}
+	if !reflect.DeepEqual(topics, expected) {
+		t.Errorf("expected topics %v, but got %v", expected, topics)
+	}
+}
+
+func TestParseTopic(t *testing.T) {
+	addr := common.HexToAddress("0x8d12A197cB00D4747a1fe03395095ce2A5CC6819")
+
+	tests := []struct {
+		name  string
+		value interface{}
+		out   interface{}
+	}{
+		{"address", addr, new(common.Address)},
+		{"bool", true, new(bool)},
+		{"uint16", uint16(65535), new(uint16)},
+		{"int32", int32(-100000), new(int32)},
+		{"int64", int64(-1), new(int64)},
+		{"bytes4", [4]byte{1, 2, 3, 4}, new([4]byte)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			topic, err := Topic(tt.value)
+			if err != nil {
+				t.Fatalf("Topic: %v", err)
+			}
+			if err := ParseTopic(topic, tt.out); err != nil {
+				t.Fatalf("ParseTopic: %v", err)
+			}
+			if got := reflect.ValueOf(tt.out).Elem().Interface(); got != tt.value {
+				t.Errorf("expected value %v, but got %v", tt.value, got)
+			}
+		})
+	}
+
+	t.Run("big.Int", func(t *testing.T) {
+		v := new(big.Int)
+		if err := ParseTopic(common.HexToHash("0x03e8"), v); err != nil {
+			t.Fatalf("ParseTopic: %v", err)
+		}
+		if v.Int64() != 1000 {
+			t.Errorf("expected value 1000, but got %v", v)
+		}
+
+		topic, _ := Topic(big.NewInt(-2))
+		if v := ParseSignedTopic(topic); v.Int64() != -2 {
+			t.Errorf("expected signed value -2, but got %v", v)
+		}
+	})
+
+	t.Run("overflow", func(t *testing.T) {
+		var v uint8
+		if err := ParseTopic(common.HexToHash("0x0100"), &v); err == nil {
+			t.Errorf("expected overflow error, but got nil")
+		}
+		var i int8
+		topic, _ := Topic(int64(-129))
+		if err := ParseTopic(topic, &i); err == nil {
+			t.Errorf("expected overflow error, but got nil")
+		}
+	})
+
+	t.Run("hashed", func(t *testing.T) {
+		var s string
+		if err := ParseTopic(common.Hash{}, &s); err != ErrHashedTopic {
+			t.Errorf("expected error %v, but got %v", ErrHashedTopic, err)
+		}
+	})
+}
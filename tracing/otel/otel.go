@@ -0,0 +1,72 @@
+// +build otel
+
+// Package otel provides OpenTelemetry implementation of tracing.Tracer. OpenTelemetry requires a newer Go than the
+// rest of the library, so go.opentelemetry.io/otel isn't locked in glide.lock, and the package is built only with
+// the otel build tag (go build -tags otel) after the dependency is installed.
+package otel
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/monetha/go-ethereum/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// make sure Tracer implements tracing.Tracer
+var _ tracing.Tracer = &Tracer{}
+
+// Tracer starts OpenTelemetry spans of the client kind.
+type Tracer struct {
+	t trace.Tracer
+}
+
+// New creates an instance of Tracer, e.g. New(otel.Tracer("github.com/monetha/go-ethereum")).
+func New(t trace.Tracer) *Tracer {
+	return &Tracer{t: t}
+}
+
+// Start implements tracing.Tracer.
+func (t *Tracer) Start(ctx context.Context, name string) (context.Context, tracing.Span) {
+	ctx, s := t.t.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient))
+	return ctx, span{s: s}
+}
+
+type span struct {
+	s trace.Span
+}
+
+func (s span) SetAttribute(key string, value interface{}) {
+	s.s.SetAttributes(toAttribute(key, value))
+}
+
+func (s span) End(err error) {
+	if err != nil {
+		s.s.RecordError(err)
+		s.s.SetStatus(codes.Error, err.Error())
+	}
+	s.s.End()
+}
+
+func toAttribute(key string, value interface{}) attribute.KeyValue {
+	switch v := value.(type) {
+	case string:
+		return attribute.String(key, v)
+	case bool:
+		return attribute.Bool(key, v)
+	case int:
+		return attribute.Int(key, v)
+	case int64:
+		return attribute.Int64(key, v)
+	case uint64:
+		return attribute.Int64(key, int64(v))
+	case uint:
+		return attribute.Int64(key, int64(v))
+	case fmt.Stringer:
+		return attribute.String(key, v.String())
+	default:
+		return attribute.String(key, fmt.Sprint(v))
+	}
+}
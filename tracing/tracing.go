@@ -0,0 +1,44 @@
+// Package tracing defines tracing hooks of calls to the Ethereum node, so that distributed traces of services
+// using this package include chain interactions (see backend.NewTracingBackend and client.Client.SetTracer).
+package tracing
+
+import "context"
+
+// Attribute keys of spans.
+const (
+	// AttrRPCMethod is the name of the RPC (or backend) method.
+	AttrRPCMethod = "rpc.method"
+	// AttrBlockNumber is the block number the call is made at.
+	AttrBlockNumber = "eth.block_number"
+	// AttrTxHash is the hash of the transaction.
+	AttrTxHash = "eth.tx_hash"
+	// AttrAddress is the address of the account or contract.
+	AttrAddress = "eth.address"
+	// AttrBatchSize is the number of requests in the batch.
+	AttrBatchSize = "rpc.batch_size"
+)
+
+// Tracer starts spans. Implementations must be safe for concurrent use.
+type Tracer interface {
+	// Start starts the span with the given name as a child of the span in the context (if any), and returns
+	// the context holding the new span.
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is the traced operation.
+type Span interface {
+	// SetAttribute sets the attribute of the span. Value is a string, integer, bool or fmt.Stringer
+	// (e.g. *big.Int, common.Hash).
+	SetAttribute(key string, value interface{})
+	// End finishes the span, the error (if not nil) marks the span as failed.
+	End(err error)
+}
+
+// Nop is a Tracer which doesn't trace anything.
+var Nop Tracer = nop{}
+
+type nop struct{}
+
+func (nop) Start(ctx context.Context, name string) (context.Context, Span) { return ctx, nop{} }
+func (nop) SetAttribute(key string, value interface{})                     {}
+func (nop) End(err error)                                                  {}
@@ -1,6 +1,7 @@
 package ethereum
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"math/big"
@@ -9,15 +10,23 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/monetha/go-ethereum/backend"
 )
 
-// Transferer allows to make ethers transfer between accounts.
+// Transferer allows to make ethers transfer between accounts. Calls are made with `opts.Context`, Session methods
+// (e.g. Session.Transfer) take the context explicitly.
 type Transferer struct {
 	ContractTransactor bind.ContractTransactor
 }
 
 // SuggestGasLimit returns suggested gas limit to make transfer.
 func (t Transferer) SuggestGasLimit(opts *bind.TransactOpts, to common.Address, input []byte) (gasLimit *big.Int, err error) {
+	return t.SuggestGasLimitTo(opts, &to, input)
+}
+
+// SuggestGasLimitTo works like SuggestGasLimit, but `to` can be nil to estimate contract creation,
+// in which case `input` is the contract creation code.
+func (t Transferer) SuggestGasLimitTo(opts *bind.TransactOpts, to *common.Address, input []byte) (gasLimit *big.Int, err error) {
 	ct := t.ContractTransactor
 
 	// Ensure a valid value field and resolve the account nonce
@@ -27,7 +36,7 @@ func (t Transferer) SuggestGasLimit(opts *bind.TransactOpts, to common.Address,
 	}
 
 	// estimate the transaction
-	msg := ethereum.CallMsg{From: opts.From, To: &to, Value: value, Data: input}
+	msg := ethereum.CallMsg{From: opts.From, To: to, Value: value, Data: input}
 	var gl uint64
 	gl, err = ct.EstimateGas(ensureContext(opts.Context), msg)
 	if err != nil {
@@ -38,6 +47,9 @@ func (t Transferer) SuggestGasLimit(opts *bind.TransactOpts, to common.Address,
 }
 
 // Transfer transfers ethers to `to` account. `input` is optional and can be set to nil.
+// The transaction which is already known to the node is considered to be sent. When another transaction with
+// the same nonce is pending, the error can be checked with backend.IsReplacementUnderpriced to retry with
+// higher gas price.
 func (t Transferer) Transfer(opts *bind.TransactOpts, to common.Address, input []byte) (*types.Transaction, error) {
 	ct := t.ContractTransactor
 
@@ -78,12 +90,123 @@ func (t Transferer) Transfer(opts *bind.TransactOpts, to common.Address, input [
 	if opts.Signer == nil {
 		return nil, errors.New("no signer to authorize the transaction with")
 	}
+	if err := t.checkChainID(ensureContext(opts.Context)); err != nil {
+		return nil, err
+	}
 	signedTx, err := opts.Signer(types.HomesteadSigner{}, opts.From, rawTx)
 	if err != nil {
 		return nil, err
 	}
-	if err := ct.SendTransaction(ensureContext(opts.Context), signedTx); err != nil {
+	if err := ct.SendTransaction(ensureContext(opts.Context), signedTx); err != nil && !backend.IsAlreadyKnown(err) {
 		return nil, err
 	}
 	return signedTx, nil
 }
+
+// checkChainID checks chain ID before the transaction is signed, when ContractTransactor guards it
+// (e.g. backend.ChainIDGuardBackend).
+func (t Transferer) checkChainID(ctx context.Context) error {
+	if c, ok := t.ContractTransactor.(chainIDChecker); ok {
+		return c.CheckChainID(ctx)
+	}
+	return nil
+}
+
+type chainIDChecker interface {
+	CheckChainID(ctx context.Context) error
+}
+
+// TransferAll transfers the entire balance of `opts.From` account to `to` account, minus the fee of the transfer
+// (gas limit × gas price), so the account is emptied. `ContractTransactor` must be able to read balances
+// (e.g. backend.Backend). Gas limit is estimated when `opts.GasLimit` is 0, gas price is suggested when
+// `opts.GasPrice` is nil, `opts.Value` is ignored.
+func (t Transferer) TransferAll(opts *bind.TransactOpts, to common.Address) (*types.Transaction, error) {
+	ct := t.ContractTransactor
+
+	br, ok := ct.(balanceReader)
+	if !ok {
+		return nil, errors.New("contract transactor can't read balance")
+	}
+
+	ctx := ensureContext(opts.Context)
+
+	var err error
+	gasPrice := opts.GasPrice
+	if gasPrice == nil {
+		gasPrice, err = ct.SuggestGasPrice(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to suggest gas price: %v", err)
+		}
+	}
+	gasLimit := opts.GasLimit
+	if gasLimit == 0 {
+		gasLimit, err = ct.EstimateGas(ctx, ethereum.CallMsg{From: opts.From, To: &to, Value: new(big.Int)})
+		if err != nil {
+			return nil, fmt.Errorf("failed to estimate gas needed: %v", err)
+		}
+	}
+
+	balance, err := br.BalanceAt(ctx, opts.From, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve account balance: %v", err)
+	}
+
+	fee := new(big.Int).Mul(new(big.Int).SetUint64(gasLimit), gasPrice)
+	value := new(big.Int).Sub(balance, fee)
+	if value.Sign() <= 0 {
+		return nil, fmt.Errorf("balance %v is not enough to pay transfer fee %v", balance, fee)
+	}
+
+	allOpts := *opts
+	allOpts.Value = value
+	allOpts.GasPrice = gasPrice
+	allOpts.GasLimit = gasLimit
+	return t.Transfer(&allOpts, to, nil)
+}
+
+// SuggestGasLimit returns suggested gas limit to transfer ethers from the session account to `to` account.
+func (s *Session) SuggestGasLimit(ctx context.Context, to common.Address, input []byte) (*big.Int, error) {
+	opts := s.WithContext(s.ctxOrDefault(ctx)).TransactOpts
+	return s.transferer().SuggestGasLimit(&opts, to, input)
+}
+
+// Transfer transfers ethers from the session account to `to` account like Transferer.Transfer.
+func (s *Session) Transfer(ctx context.Context, to common.Address, input []byte) (*types.Transaction, error) {
+	opts := s.WithContext(s.ctxOrDefault(ctx)).TransactOpts
+	return s.transferer().Transfer(&opts, to, input)
+}
+
+// TransferAll transfers the entire balance of the session account to `to` account like Transferer.TransferAll.
+func (s *Session) TransferAll(ctx context.Context, to common.Address) (*types.Transaction, error) {
+	opts := s.WithContext(s.ctxOrDefault(ctx)).TransactOpts
+	return s.transferer().TransferAll(&opts, to)
+}
+
+// TransferToken transfers `amount` of ERC-20 `token` from the session account to `to` account like
+// Transferer.TransferToken.
+func (s *Session) TransferToken(ctx context.Context, token common.Address, to common.Address, amount *big.Int) (*types.Transaction, error) {
+	opts := s.WithContext(s.ctxOrDefault(ctx)).TransactOpts
+	return s.transferer().TransferToken(&opts, token, to, amount)
+}
+
+// ApproveToken allows `spender` to spend `amount` of ERC-20 `token` of the session account like
+// Transferer.ApproveToken.
+func (s *Session) ApproveToken(ctx context.Context, token common.Address, spender common.Address, amount *big.Int) (*types.Transaction, error) {
+	opts := s.WithContext(s.ctxOrDefault(ctx)).TransactOpts
+	return s.transferer().ApproveToken(&opts, token, spender, amount)
+}
+
+// Payout transfers `amount` of ERC-20 `token` (or ethers when `token` is nil) from the session account to `to`
+// account like Transferer.Payout.
+func (s *Session) Payout(ctx context.Context, token *common.Address, to common.Address, amount *big.Int, allowContractRecipient bool) (*types.Transaction, error) {
+	opts := s.WithContext(s.ctxOrDefault(ctx)).TransactOpts
+	return s.transferer().Payout(&opts, token, to, amount, allowContractRecipient)
+}
+
+func (s *Session) transferer() Transferer {
+	return Transferer{ContractTransactor: s.Backend}
+}
+
+type balanceReader interface {
+	BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error)
+}
@@ -7,9 +7,11 @@ import (
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/monetha/go-ethereum/backend"
 )
 
 var ether = big.NewInt(1000000000000000000) // 1 ether in wei
@@ -86,3 +88,109 @@ func TestTransferer_SuggestGasLimit(t *testing.T) {
 		t.Errorf("expected gas limit is %v, but got %v", expectedGasLimit, gasLimit)
 	}
 }
+
+func TestTransferer_TransferAll(t *testing.T) {
+	// Generate a new random account and a funded simulator
+	key, _ := crypto.GenerateKey()
+	auth := bind.NewKeyedTransactor(key)
+
+	alloc := core.GenesisAlloc{auth.From: {Balance: ether}}
+	sim := backends.NewSimulatedBackend(alloc, 10000000)
+	sim.Commit()
+
+	tr := Transferer{sim}
+
+	key2, _ := crypto.GenerateKey()
+	to := crypto.PubkeyToAddress(key2.PublicKey)
+
+	auth.GasPrice = big.NewInt(10)
+	tx, err := tr.TransferAll(auth, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sim.Commit()
+
+	ctx := context.TODO()
+	trr, err := sim.TransactionReceipt(ctx, tx.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if trr.Status != types.ReceiptStatusSuccessful {
+		t.Errorf("unexpected transaction status: %v", trr.Status)
+	}
+
+	fromBalance, err := sim.BalanceAt(ctx, auth.From, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fromBalance.Sign() != 0 {
+		t.Errorf("expected empty balance after TransferAll, but got %v", fromBalance)
+	}
+
+	expectedBalance := new(big.Int).Sub(ether, new(big.Int).Mul(big.NewInt(21000), auth.GasPrice))
+	toBalance, err := sim.BalanceAt(ctx, to, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expectedBalance.Cmp(toBalance) != 0 {
+		t.Errorf("expected balance after TransferAll is %v, but got %v", expectedBalance, toBalance)
+	}
+
+	if _, err := tr.TransferAll(auth, to); err == nil {
+		t.Errorf("expected error when transferring from empty account")
+	}
+}
+
+func TestTransferer_SuggestGasLimitTo_ContractCreation(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	auth := bind.NewKeyedTransactor(key)
+
+	alloc := core.GenesisAlloc{auth.From: {Balance: ether}}
+	sim := backends.NewSimulatedBackend(alloc, 10000000)
+	sim.Commit()
+
+	tr := Transferer{sim}
+
+	gasLimit, err := tr.SuggestGasLimitTo(auth, nil, stopContractCode)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// contract creation costs at least 53000 gas
+	if minGasLimit := big.NewInt(53000); gasLimit.Cmp(minGasLimit) < 0 {
+		t.Errorf("expected gas limit of contract creation to be at least %v, but got %v", minGasLimit, gasLimit)
+	}
+}
+
+type nonceBackend struct {
+	backend.Backend
+}
+
+func (b nonceBackend) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return 0, nil
+}
+
+func TestTransferer_Transfer_ChainIDGuard(t *testing.T) {
+	signed := false
+	opts := &bind.TransactOpts{
+		GasPrice: big.NewInt(1),
+		GasLimit: 21000,
+		Signer: func(signer types.Signer, address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			signed = true
+			return tx, nil
+		},
+	}
+
+	b := backend.NewChainIDGuardBackend(nonceBackend{}, big.NewInt(3), func(ctx context.Context) (*big.Int, error) {
+		return big.NewInt(1), nil
+	})
+
+	tr := Transferer{b}
+	_, err := tr.Transfer(opts, common.HexToAddress("0x1"), nil)
+	if e, ok := err.(*backend.ChainIDMismatchError); !ok || e.Actual.Int64() != 1 || e.Expected.Int64() != 3 {
+		t.Errorf("expected chain ID mismatch, but got %v", err)
+	}
+	if signed {
+		t.Errorf("expected transaction not to be signed")
+	}
+}
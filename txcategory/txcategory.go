@@ -0,0 +1,185 @@
+// Package txcategory classifies transactions delivered by BlockSource as ether transfers, ERC-20 transfers,
+// contract deployments, contract calls or failed transactions. Calls of well-known methods are annotated
+// with the method signature and the protocol it belongs to.
+package txcategory
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/monetha/go-ethereum"
+)
+
+// Category is the category of the transaction.
+type Category string
+
+const (
+	// EtherTransfer is the transfer of ethers without call data.
+	EtherTransfer Category = "ether_transfer"
+	// TokenTransfer is the call of ERC-20 transfer or transferFrom method.
+	TokenTransfer Category = "token_transfer"
+	// ContractDeployment is the transaction creating a contract.
+	ContractDeployment Category = "contract_deployment"
+	// ContractCall is the call of a contract method other than a token transfer.
+	ContractCall Category = "contract_call"
+	// Failed is the transaction whose execution failed.
+	Failed Category = "failed"
+)
+
+// Signature is the known method.
+type Signature struct {
+	Method   string // e.g. "transfer(address,uint256)"
+	Protocol string // e.g. "ERC-20"
+}
+
+// Result is the classification of the transaction.
+type Result struct {
+	Category Category
+	// Signature of the called method, nil when the method isn't known or the transaction isn't a call.
+	Signature *Signature
+	// Token is the address of the transferred token, for token transfers only.
+	Token *common.Address
+	// From is the sender of ethers or tokens (the owner of tokens in case of transferFrom).
+	From common.Address
+	// To is the recipient of ethers or tokens, the created contract for deployments, the called contract for calls.
+	To *common.Address
+	// Amount of transferred ethers or tokens.
+	Amount *big.Int
+}
+
+var (
+	transferSelector     = selector("transfer(address,uint256)")
+	transferFromSelector = selector("transferFrom(address,address,uint256)")
+)
+
+// KnownSignatures are the signatures the classifier is created with.
+var KnownSignatures = []Signature{
+	{"transfer(address,uint256)", "ERC-20"},
+	{"transferFrom(address,address,uint256)", "ERC-20"},
+	{"approve(address,uint256)", "ERC-20"},
+	{"safeTransferFrom(address,address,uint256)", "ERC-721"},
+	{"safeTransferFrom(address,address,uint256,bytes)", "ERC-721"},
+	{"setApprovalForAll(address,bool)", "ERC-721"},
+	{"safeTransferFrom(address,address,uint256,uint256,bytes)", "ERC-1155"},
+	{"safeBatchTransferFrom(address,address,uint256[],uint256[],bytes)", "ERC-1155"},
+	{"deposit()", "WETH"},
+	{"withdraw(uint256)", "WETH"},
+	{"swapExactTokensForTokens(uint256,uint256,address[],address,uint256)", "Uniswap V2"},
+	{"swapTokensForExactTokens(uint256,uint256,address[],address,uint256)", "Uniswap V2"},
+	{"swapExactETHForTokens(uint256,address[],address,uint256)", "Uniswap V2"},
+	{"swapExactTokensForETH(uint256,uint256,address[],address,uint256)", "Uniswap V2"},
+	{"addLiquidity(address,address,uint256,uint256,uint256,uint256,address,uint256)", "Uniswap V2"},
+	{"removeLiquidity(address,address,uint256,uint256,uint256,address,uint256)", "Uniswap V2"},
+	{"exactInputSingle((address,address,uint24,address,uint256,uint256,uint256,uint160))", "Uniswap V3"},
+	{"exactInput((bytes,address,uint256,uint256,uint256))", "Uniswap V3"},
+	{"multicall(bytes[])", "Uniswap V3"},
+	{"execTransaction(address,uint256,bytes,uint8,uint256,uint256,uint256,address,address,bytes)", "Gnosis Safe"},
+	{"aggregate((address,bytes)[])", "Multicall"},
+}
+
+// Classifier classifies transactions. It's safe for concurrent use once signatures are registered.
+type Classifier struct {
+	signatures map[[4]byte]Signature
+}
+
+// New creates an instance of Classifier which knows KnownSignatures.
+func New() *Classifier {
+	c := &Classifier{signatures: make(map[[4]byte]Signature, len(KnownSignatures))}
+	for _, sig := range KnownSignatures {
+		c.Register(sig)
+	}
+	return c
+}
+
+// Register adds the signature to known ones, replacing the signature with the same selector.
+// It must not be called concurrently with Classify.
+func (c *Classifier) Register(sig Signature) {
+	c.signatures[selector(sig.Method)] = sig
+}
+
+// Classify returns the category of the transaction. Transactions are considered successful unless
+// their status is known to be failed.
+func (c *Classifier) Classify(tx *ethereum.Transaction) *Result {
+	r := &Result{From: tx.From, To: tx.To, Amount: tx.Value}
+
+	if tx.To != nil && len(tx.Input) >= 4 {
+		var sel [4]byte
+		copy(sel[:], tx.Input)
+		if sig, ok := c.signatures[sel]; ok {
+			r.Signature = &sig
+		}
+	}
+
+	switch {
+	case tx.Status != nil && *tx.Status == ethereum.TransactionFailed:
+		r.Category = Failed
+	case tx.To == nil:
+		r.Category = ContractDeployment
+		r.To = tx.ContractAddress
+	case len(tx.Input) == 0:
+		r.Category = EtherTransfer
+	case decodeTokenTransfer(tx, r):
+		r.Category = TokenTransfer
+	default:
+		r.Category = ContractCall
+	}
+
+	return r
+}
+
+// ClassifyBlock classifies all transactions of the block.
+func (c *Classifier) ClassifyBlock(b *ethereum.Block) []*Result {
+	res := make([]*Result, len(b.Transactions))
+	for i, tx := range b.Transactions {
+		res[i] = c.Classify(tx)
+	}
+	return res
+}
+
+// decodeTokenTransfer fills the result with the token transfer of ERC-20 transfer or transferFrom call.
+func decodeTokenTransfer(tx *ethereum.Transaction, r *Result) bool {
+	if len(tx.Input) < 4 {
+		return false
+	}
+	var sel [4]byte
+	copy(sel[:], tx.Input)
+	args := tx.Input[4:]
+
+	var from, to common.Address
+	var amount []byte
+	switch {
+	case sel == transferSelector && len(args) == 2*32 && isAddress(args[:32]):
+		from = tx.From
+		to = common.BytesToAddress(args[:32])
+		amount = args[32:64]
+	case sel == transferFromSelector && len(args) == 3*32 && isAddress(args[:32]) && isAddress(args[32:64]):
+		from = common.BytesToAddress(args[:32])
+		to = common.BytesToAddress(args[32:64])
+		amount = args[64:96]
+	default:
+		return false
+	}
+
+	token := *tx.To
+	r.Token = &token
+	r.From = from
+	r.To = &to
+	r.Amount = new(big.Int).SetBytes(amount)
+	return true
+}
+
+// isAddress checks that the ABI-encoded word holds an address.
+func isAddress(word []byte) bool {
+	for _, b := range word[:12] {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func selector(method string) (sel [4]byte) {
+	copy(sel[:], crypto.Keccak256([]byte(method)))
+	return
+}
@@ -0,0 +1,154 @@
+package txcategory
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/monetha/go-ethereum"
+)
+
+func TestClassifier_Classify(t *testing.T) {
+	sender := common.HexToAddress("0x1")
+	token := common.HexToAddress("0x2")
+	recipient := common.HexToAddress("0x3")
+	owner := common.HexToAddress("0x4")
+	created := common.HexToAddress("0x5")
+	failed := ethereum.TransactionFailed
+	successful := ethereum.TransactionSuccessful
+
+	word := func(b []byte) string { return common.Bytes2Hex(common.LeftPadBytes(b, 32)) }
+	transferInput := hexutil.MustDecode("0xa9059cbb" + word(recipient.Bytes()) + word(big.NewInt(100).Bytes()))
+	transferFromInput := hexutil.MustDecode("0x23b872dd" + word(owner.Bytes()) + word(recipient.Bytes()) + word(big.NewInt(7).Bytes()))
+
+	tests := []struct {
+		name     string
+		tx       *ethereum.Transaction
+		category Category
+		protocol string
+		tokenTo  *common.Address
+		from     common.Address
+		amount   int64
+	}{
+		{
+			name:     "ether transfer",
+			tx:       &ethereum.Transaction{From: sender, To: &recipient, Value: big.NewInt(10), Status: &successful},
+			category: EtherTransfer,
+			from:     sender,
+			amount:   10,
+		},
+		{
+			name:     "token transfer",
+			tx:       &ethereum.Transaction{From: sender, To: &token, Value: big.NewInt(0), Input: transferInput},
+			category: TokenTransfer,
+			protocol: "ERC-20",
+			tokenTo:  &recipient,
+			from:     sender,
+			amount:   100,
+		},
+		{
+			name:     "token transferFrom",
+			tx:       &ethereum.Transaction{From: sender, To: &token, Value: big.NewInt(0), Input: transferFromInput},
+			category: TokenTransfer,
+			protocol: "ERC-20",
+			tokenTo:  &recipient,
+			from:     owner,
+			amount:   7,
+		},
+		{
+			name:     "malformed transfer",
+			tx:       &ethereum.Transaction{From: sender, To: &token, Value: big.NewInt(0), Input: transferInput[:40]},
+			category: ContractCall,
+			protocol: "ERC-20",
+			from:     sender,
+		},
+		{
+			name:     "contract deployment",
+			tx:       &ethereum.Transaction{From: sender, Value: big.NewInt(0), Input: []byte{0x60, 0x80}, ContractAddress: &created},
+			category: ContractDeployment,
+			from:     sender,
+		},
+		{
+			name:     "known contract call",
+			tx:       &ethereum.Transaction{From: sender, To: &token, Value: big.NewInt(1), Input: hexutil.MustDecode("0xd0e30db0")},
+			category: ContractCall,
+			protocol: "WETH",
+			from:     sender,
+			amount:   1,
+		},
+		{
+			name:     "unknown contract call",
+			tx:       &ethereum.Transaction{From: sender, To: &token, Value: big.NewInt(0), Input: []byte{1, 2, 3, 4}},
+			category: ContractCall,
+			from:     sender,
+		},
+		{
+			name:     "input shorter than selector",
+			tx:       &ethereum.Transaction{From: sender, To: &token, Value: big.NewInt(0), Input: []byte{0xa9}},
+			category: ContractCall,
+			from:     sender,
+		},
+		{
+			name:     "failed token transfer",
+			tx:       &ethereum.Transaction{From: sender, To: &token, Value: big.NewInt(0), Input: transferInput, Status: &failed},
+			category: Failed,
+			protocol: "ERC-20",
+			from:     sender,
+		},
+	}
+
+	c := New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := c.Classify(tt.tx)
+			if r.Category != tt.category {
+				t.Errorf("expected category %v, but got %v", tt.category, r.Category)
+			}
+
+			var protocol string
+			if r.Signature != nil {
+				protocol = r.Signature.Protocol
+			}
+			if protocol != tt.protocol {
+				t.Errorf("expected protocol %q, but got %q", tt.protocol, protocol)
+			}
+
+			if r.From != tt.from {
+				t.Errorf("expected from %v, but got %v", tt.from.Hex(), r.From.Hex())
+			}
+
+			if tt.category == TokenTransfer {
+				if r.Token == nil || *r.Token != token {
+					t.Errorf("expected token %v, but got %v", token.Hex(), r.Token)
+				}
+				if r.To == nil || *r.To != *tt.tokenTo {
+					t.Errorf("expected recipient %v, but got %v", tt.tokenTo.Hex(), r.To)
+				}
+			}
+			if tt.category == ContractDeployment && (r.To == nil || *r.To != created) {
+				t.Errorf("expected created contract %v, but got %v", created.Hex(), r.To)
+			}
+
+			if tt.amount != 0 && (r.Amount == nil || r.Amount.Int64() != tt.amount) {
+				t.Errorf("expected amount %v, but got %v", tt.amount, r.Amount)
+			}
+		})
+	}
+}
+
+func TestClassifier_Register(t *testing.T) {
+	to := common.HexToAddress("0x1")
+	c := New()
+	c.Register(Signature{Method: "ping()", Protocol: "Custom"})
+
+	r := c.Classify(&ethereum.Transaction{To: &to, Input: selectorBytes("ping()")})
+	if r.Signature == nil || r.Signature.Protocol != "Custom" {
+		t.Errorf("expected protocol Custom, but got %v", r.Signature)
+	}
+}
+
+func selectorBytes(method string) []byte {
+	sel := selector(method)
+	return sel[:]
+}
@@ -0,0 +1,78 @@
+package ethereum
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	byzantiumBlock      = big.NewInt(4370000)
+	constantinopleBlock = big.NewInt(7280000)
+
+	frontierBlockReward       = big.NewInt(5e+18) // Block reward in wei for successfully mining a block
+	byzantiumBlockReward      = big.NewInt(3e+18) // Block reward in wei for successfully mining a block upward from Byzantium
+	constantinopleBlockReward = big.NewInt(2e+18) // Block reward in wei for successfully mining a block upward from Constantinople
+
+	eight = big.NewInt(8)
+)
+
+// Uncle holds information about uncle (ommer) block.
+type Uncle struct {
+	Difficulty *big.Int
+	GasLimit   *big.Int
+	GasUsed    *big.Int
+	Hash       common.Hash
+	Miner      common.Address
+	Number     *big.Int
+	Timestamp  uint64
+	Reward     *big.Int // estimated reward of the uncle miner, nil if block including the uncle is unknown
+}
+
+func (u *Uncle) String() string {
+	return fmt.Sprintf(`
+	Uncle(#%v)
+	Difficulty:      %v
+	GasLimit:        %v
+	GasUsed:         %v
+	Hash:            %x
+	Miner:           %x
+	Timestamp:       %v
+	Reward:          %v
+`,
+		u.Number,
+		u.Difficulty,
+		u.GasLimit,
+		u.GasUsed,
+		u.Hash[:],
+		u.Miner[:],
+		u.Timestamp,
+		u.Reward,
+	)
+}
+
+// EstimateUncleReward estimates the reward (in wei) of the miner of uncle block with number `uncleNumber`
+// included in block with number `blockNumber`. The estimation uses block rewards schedule of the Ethereum mainnet
+// (Frontier, Byzantium and Constantinople block rewards) and doesn't take into account transaction fees.
+func EstimateUncleReward(uncleNumber, blockNumber *big.Int) *big.Int {
+	// reward = (uncleNumber + 8 - blockNumber) * blockReward / 8
+	r := new(big.Int).Add(uncleNumber, eight)
+	r.Sub(r, blockNumber)
+	if r.Sign() <= 0 {
+		return new(big.Int)
+	}
+	r.Mul(r, mainnetBlockReward(blockNumber))
+	return r.Div(r, eight)
+}
+
+func mainnetBlockReward(blockNumber *big.Int) *big.Int {
+	switch {
+	case blockNumber.Cmp(constantinopleBlock) >= 0:
+		return constantinopleBlockReward
+	case blockNumber.Cmp(byzantiumBlock) >= 0:
+		return byzantiumBlockReward
+	default:
+		return frontierBlockReward
+	}
+}
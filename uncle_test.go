@@ -0,0 +1,30 @@
+package ethereum
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+func TestEstimateUncleReward(t *testing.T) {
+	testCases := []struct {
+		uncleNumber int64
+		blockNumber int64
+		expected    string
+	}{
+		{1000, 1001, "4375000000000000000"},
+		{1000, 1007, "625000000000000000"},
+		{1000, 1008, "0"},
+		{4370000, 4370001, "2625000000000000000"},
+		{7280000, 7280002, "1500000000000000000"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("EstimateUncleReward(%v, %v)", tc.uncleNumber, tc.blockNumber), func(t *testing.T) {
+			reward := EstimateUncleReward(big.NewInt(tc.uncleNumber), big.NewInt(tc.blockNumber))
+			if reward.String() != tc.expected {
+				t.Errorf("expected reward %v, but got %v", tc.expected, reward)
+			}
+		})
+	}
+}
@@ -0,0 +1,270 @@
+// Package vcr records JSON-RPC responses of the Ethereum node to fixture files and replays them, so that tests of
+// code using client.Client, ethclient.Client or backends on top of it run deterministically without network access.
+//
+// Tests record fixtures once against a live node and replay them afterwards:
+//
+//	tr, err := vcr.New("testdata/block.json", vcr.ModeFromEnv("VCR_RECORD"), nil)
+//	...
+//	defer tr.Save()
+//	rc, err := rpc.DialHTTPWithClient(nodeURL, tr.Client())
+//	c := client.NewClient(rc)
+package vcr
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sync"
+)
+
+// Mode is the mode of Transport.
+type Mode int
+
+const (
+	// ModeReplay replays recorded responses, requests are never sent to the node.
+	ModeReplay Mode = iota
+	// ModeRecord sends requests to the node and records responses.
+	ModeRecord
+)
+
+// ModeFromEnv returns ModeRecord if the environment variable with the given name is not empty, otherwise ModeReplay.
+func ModeFromEnv(name string) Mode {
+	if os.Getenv(name) != "" {
+		return ModeRecord
+	}
+	return ModeReplay
+}
+
+// Interaction is the recorded JSON-RPC request (single or batch) and its response.
+type Interaction struct {
+	Request  json.RawMessage `json:"request"`
+	Response json.RawMessage `json:"response"`
+}
+
+// Transport is http.RoundTripper which records or replays JSON-RPC interactions. Requests are matched ignoring
+// JSON-RPC ids, identical requests are replayed in the order they were recorded. It's safe for concurrent use,
+// but concurrent identical requests may be replayed in different order.
+type Transport struct {
+	path  string
+	mode  Mode
+	inner http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []Interaction
+	keys         []string
+	used         []bool
+}
+
+// New creates an instance of Transport with the fixture file at the given path. In ModeReplay the fixture file is
+// loaded, in ModeRecord requests are sent with the inner round tripper (http.DefaultTransport, if nil) and
+// the fixture file is written by Save.
+func New(path string, mode Mode, inner http.RoundTripper) (*Transport, error) {
+	if inner == nil {
+		inner = http.DefaultTransport
+	}
+	t := &Transport{path: path, mode: mode, inner: inner}
+	if mode == ModeRecord {
+		return t, nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("vcr: failed to read fixture: %v", err)
+	}
+	var interactions []Interaction
+	if err := json.Unmarshal(data, &interactions); err != nil {
+		return nil, fmt.Errorf("vcr: failed to parse fixture %v: %v", path, err)
+	}
+	for _, i := range interactions {
+		key, err := requestKey(i.Request)
+		if err != nil {
+			return nil, fmt.Errorf("vcr: invalid request in fixture %v: %v", path, err)
+		}
+		t.add(i, key)
+	}
+	return t, nil
+}
+
+// Client returns HTTP client using the transport.
+func (t *Transport) Client() *http.Client {
+	return &http.Client{Transport: t}
+}
+
+// Save writes recorded interactions to the fixture file. It does nothing in ModeReplay.
+func (t *Transport) Save() error {
+	if t.mode != ModeRecord {
+		return nil
+	}
+
+	t.mu.Lock()
+	data, err := json.MarshalIndent(t.interactions, "", "  ")
+	t.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("vcr: failed to marshal interactions: %v", err)
+	}
+
+	if err := ioutil.WriteFile(t.path, data, 0644); err != nil {
+		return fmt.Errorf("vcr: failed to write fixture: %v", err)
+	}
+	return nil
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	key, err := requestKey(body)
+	if err != nil {
+		return nil, fmt.Errorf("vcr: invalid JSON-RPC request: %v", err)
+	}
+
+	if t.mode == ModeRecord {
+		return t.record(req, body, key)
+	}
+	return t.replay(req, body, key)
+}
+
+func (t *Transport) record(req *http.Request, body []byte, key string) (*http.Response, error) {
+	out := new(http.Request)
+	*out = *req
+	out.Body = ioutil.NopCloser(bytes.NewReader(body))
+	out.ContentLength = int64(len(body))
+
+	resp, err := t.inner.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+	if resp.StatusCode == http.StatusOK {
+		t.mu.Lock()
+		t.add(Interaction{Request: json.RawMessage(body), Response: json.RawMessage(respBody)}, key)
+		t.mu.Unlock()
+	}
+
+	return resp, nil
+}
+
+func (t *Transport) replay(req *http.Request, body []byte, key string) (*http.Response, error) {
+	t.mu.Lock()
+	found := -1
+	for i, k := range t.keys {
+		if k != key {
+			continue
+		}
+		found = i
+		if !t.used[i] {
+			break
+		}
+	}
+	if found < 0 {
+		t.mu.Unlock()
+		return nil, fmt.Errorf("vcr: no recorded response for request %s", body)
+	}
+	t.used[found] = true
+	recorded := t.interactions[found]
+	t.mu.Unlock()
+
+	respBody, err := withRequestIDs(recorded, body)
+	if err != nil {
+		return nil, fmt.Errorf("vcr: invalid recorded response: %v", err)
+	}
+
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          ioutil.NopCloser(bytes.NewReader(respBody)),
+		ContentLength: int64(len(respBody)),
+		Request:       req,
+	}, nil
+}
+
+// add appends the interaction; it must be called with the lock held.
+func (t *Transport) add(i Interaction, key string) {
+	t.interactions = append(t.interactions, i)
+	t.keys = append(t.keys, key)
+	t.used = append(t.used, false)
+}
+
+// requestKey returns the request (or batch of requests) without ids in canonical form.
+func requestKey(body []byte) (string, error) {
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return "", err
+	}
+
+	switch r := v.(type) {
+	case map[string]interface{}:
+		delete(r, "id")
+	case []interface{}:
+		for _, e := range r {
+			if m, ok := e.(map[string]interface{}); ok {
+				delete(m, "id")
+			}
+		}
+	default:
+		return "", errors.New("request must be an object or an array")
+	}
+
+	key, err := json.Marshal(v) // keys of maps are sorted
+	return string(key), err
+}
+
+// withRequestIDs replaces ids of the recorded response with the ids of the corresponding requests.
+func withRequestIDs(recorded Interaction, request []byte) ([]byte, error) {
+	var req map[string]json.RawMessage
+	if err := json.Unmarshal(request, &req); err == nil {
+		var resp map[string]json.RawMessage
+		if err := json.Unmarshal(recorded.Response, &resp); err != nil {
+			return nil, err
+		}
+		resp["id"] = req["id"]
+		return json.Marshal(resp)
+	}
+
+	var reqs, recordedReqs, resps []map[string]json.RawMessage
+	if err := json.Unmarshal(request, &reqs); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(recorded.Request, &recordedReqs); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(recorded.Response, &resps); err != nil {
+		return nil, err
+	}
+
+	// responses of the batch may come in any order, so they are matched to requests by recorded ids
+	index := make(map[string]int, len(recordedReqs))
+	for i, r := range recordedReqs {
+		index[string(r["id"])] = i
+	}
+	for _, resp := range resps {
+		i, ok := index[string(resp["id"])]
+		if !ok || i >= len(reqs) {
+			return nil, fmt.Errorf("unexpected response id %s", resp["id"])
+		}
+		resp["id"] = reqs[i]["id"]
+	}
+	return json.Marshal(resps)
+}
@@ -0,0 +1,101 @@
+package vcr
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTransport_RecordReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vcr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "fixture.json")
+
+	blockNumber := 10
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]json.RawMessage
+		body, _ := ioutil.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &req); err != nil {
+			// batch: respond in reverse order
+			var reqs []map[string]json.RawMessage
+			_ = json.Unmarshal(body, &reqs)
+			var resps []string
+			for i := len(reqs) - 1; i >= 0; i-- {
+				resps = append(resps, `{"jsonrpc":"2.0","id":`+string(reqs[i]["id"])+`,"result":`+string(reqs[i]["params"])+`}`)
+			}
+			_, _ = w.Write([]byte("[" + strings.Join(resps, ",") + "]"))
+			return
+		}
+		blockNumber++
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":` + string(req["id"]) + `,"result":` + jsonInt(blockNumber) + `}`))
+	}))
+
+	rec, err := New(path, ModeRecord, nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	c := rec.Client()
+	if got := post(t, c, srv.URL, `{"jsonrpc":"2.0","id":1,"method":"eth_blockNumber","params":[]}`); !strings.Contains(got, `"result":11`) {
+		t.Fatalf("unexpected response %v", got)
+	}
+	post(t, c, srv.URL, `{"jsonrpc":"2.0","id":2,"method":"eth_blockNumber","params":[]}`)
+	post(t, c, srv.URL, `[{"jsonrpc":"2.0","id":3,"method":"m","params":["a"]},{"jsonrpc":"2.0","id":4,"method":"m","params":["b"]}]`)
+	if err := rec.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	srv.Close()
+
+	rep, err := New(path, ModeReplay, nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	c = rep.Client()
+
+	testCases := []struct {
+		request  string
+		expected string
+	}{
+		{`{"jsonrpc":"2.0","id":7,"method":"eth_blockNumber","params":[]}`, `{"id":7,"jsonrpc":"2.0","result":11}`},
+		{`{"jsonrpc":"2.0","id":8,"method":"eth_blockNumber","params":[]}`, `{"id":8,"jsonrpc":"2.0","result":12}`},
+		{`{"jsonrpc":"2.0","id":9,"method":"eth_blockNumber","params":[]}`, `{"id":9,"jsonrpc":"2.0","result":12}`},
+		{
+			`[{"jsonrpc":"2.0","id":5,"method":"m","params":["a"]},{"jsonrpc":"2.0","id":6,"method":"m","params":["b"]}]`,
+			`[{"id":6,"jsonrpc":"2.0","result":["b"]},{"id":5,"jsonrpc":"2.0","result":["a"]}]`,
+		},
+	}
+	for _, tc := range testCases {
+		if got := post(t, c, srv.URL, tc.request); got != tc.expected {
+			t.Errorf("expected response %v, but got %v", tc.expected, got)
+		}
+	}
+
+	if _, err := c.Post(srv.URL, "application/json", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"eth_chainId"}`)); err == nil {
+		t.Errorf("expected error for request which wasn't recorded")
+	}
+}
+
+func post(t *testing.T, c *http.Client, url, body string) string {
+	resp, err := c.Post(url, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Post: %v", err)
+	}
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	return string(b)
+}
+
+func jsonInt(i int) string {
+	b, _ := json.Marshal(i)
+	return string(b)
+}